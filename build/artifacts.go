@@ -0,0 +1,11 @@
+// Package build embeds the ABIs and the bytecode compiled from the contracts,
+// the inputs of the generation of the bindings by tools/abigen.
+package build
+
+import "embed"
+
+// Artifacts holds the <Contract>.abi and <Contract>.bin files, under the
+// directory of the compiled source, e.g. controller/Controller.abi.
+//
+//go:embed */*.abi */*.bin */*/*.abi */*/*.bin */*/*/*.abi */*/*/*.bin
+var Artifacts embed.FS
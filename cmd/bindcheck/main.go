@@ -0,0 +1,65 @@
+// Command bindcheck reports the uses of go-ethereum symbols removed or
+// deprecated in later releases in the generated bindings, and the bindings to
+// regenerate before upgrading go-ethereum.
+//
+// Usage:
+//
+//	bindcheck [-strict] [dir ...]
+//
+// The directories default to pkg/bindings. With -strict, it exits with status
+// 1 when a deprecated symbol is used.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tokencard/contracts/v2/pkg/bindcheck"
+)
+
+func main() {
+	strict := flag.Bool("strict", false, "exit with status 1 when a deprecated symbol is used")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-strict] [dir ...]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"pkg/bindings"}
+	}
+
+	var findings []bindcheck.Finding
+	for _, dir := range dirs {
+		f, err := bindcheck.Check(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bindcheck: %v\n", err)
+			os.Exit(2)
+		}
+		findings = append(findings, f...)
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	regenerate := bindcheck.Regenerate(findings)
+	files := make([]string, 0, len(regenerate))
+	for file := range regenerate {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	fmt.Printf("\n%d bindings to regenerate:\n", len(files))
+	for _, file := range files {
+		fmt.Printf("  %s (%s)\n", file, strings.Join(regenerate[file], ", "))
+	}
+	if *strict {
+		os.Exit(1)
+	}
+}
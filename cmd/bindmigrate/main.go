@@ -0,0 +1,143 @@
+// Command bindmigrate reports the uses of the generated bindings of
+// pkg/bindings in a Go codebase with the high-level clients replacing them,
+// and rewrites the uses where it is safe.
+//
+// Usage:
+//
+//	bindmigrate [-w | -i] [dir ...]
+//
+// The directories default to the current one. With -w, the rewritable uses
+// are rewritten in place. With -i, the changes of each file are shown and the
+// file is only rewritten once confirmed. The uses left are to be migrated by
+// hand, following their hint.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tokencard/contracts/v2/pkg/bindmigrate"
+)
+
+func main() {
+	write := flag.Bool("w", false, "rewrite the rewritable uses in place")
+	interactive := flag.Bool("i", false, "confirm the rewrite of each file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-w | -i] [dir ...]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *write && *interactive {
+		fmt.Fprintln(os.Stderr, "bindmigrate: -w and -i are exclusive")
+		os.Exit(2)
+	}
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	var suggestions []bindmigrate.Suggestion
+	for _, dir := range dirs {
+		s, err := bindmigrate.Scan(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bindmigrate: %v\n", err)
+			os.Exit(2)
+		}
+		suggestions = append(suggestions, s...)
+	}
+	for _, s := range suggestions {
+		fmt.Println(s)
+	}
+	if !*write && !*interactive {
+		return
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	rewritten := 0
+	for _, path := range rewritableFiles(suggestions) {
+		src, changed, err := bindmigrate.Rewrite(path, suggestions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bindmigrate: %v\n", err)
+			os.Exit(2)
+		}
+		if !changed {
+			continue
+		}
+		if *interactive {
+			ok, err := confirm(in, path, src)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "bindmigrate: %v\n", err)
+				os.Exit(2)
+			}
+			if !ok {
+				continue
+			}
+		}
+		if err := ioutil.WriteFile(path, src, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "bindmigrate: %v\n", err)
+			os.Exit(2)
+		}
+		rewritten++
+	}
+	fmt.Printf("\n%d files rewritten, build them to check the migration\n", rewritten)
+}
+
+// rewritableFiles returns the files with a rewritable use, sorted.
+func rewritableFiles(suggestions []bindmigrate.Suggestion) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, s := range suggestions {
+		if s.Rewrite && !seen[s.Pos.Filename] {
+			seen[s.Pos.Filename] = true
+			files = append(files, s.Pos.Filename)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// confirm prints the lines of the file at path changed by the rewrite to src
+// and asks whether to write it.
+func confirm(in *bufio.Reader, path string, src []byte) (bool, error) {
+	old, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("\n%s:\n", path)
+	printChanges(os.Stdout, old, src)
+	fmt.Print("rewrite? [y/N] ")
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// printChanges prints the lines differing between old and new. The rewrite
+// only renames identifiers, so that the lines of both match one to one unless
+// gofmt realigned the file, in which case all the differing lines are shown.
+func printChanges(w io.Writer, old, new []byte) {
+	a := bytes.Split(old, []byte("\n"))
+	b := bytes.Split(new, []byte("\n"))
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y []byte
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if !bytes.Equal(x, y) {
+			fmt.Fprintf(w, "  %d:\n  - %s\n  + %s\n", i+1, strings.TrimSpace(string(x)), strings.TrimSpace(string(y)))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Command monolith-operator runs the monolithd instances declared by the
+// Monolith resources of a Kubernetes cluster, see package operator.
+//
+// Usage:
+//
+//	monolith-operator -leader-elect
+package main
+
+import (
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/tokencard/contracts/v2/pkg/operator"
+	"github.com/tokencard/contracts/v2/pkg/operator/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+func main() {
+	metricsAddress := flag.String("metrics-address", ":8080", "address the metrics are served on, 0 to disable them")
+	probeAddress := flag.String("probe-address", ":8081", "address the health probes are served on")
+	leaderElect := flag.Bool("leader-elect", false, "elect a leader among the replicas of the operator")
+	namespace := flag.String("leader-election-namespace", "", "namespace of the leader election lease, the namespace of the pod when empty")
+	flag.Parse()
+
+	handler := slog.NewJSONHandler(os.Stderr, nil)
+	ctrl.SetLogger(logr.FromSlogHandler(handler))
+
+	scheme := runtime.NewScheme()
+	err := clientgoscheme.AddToScheme(scheme)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = v1alpha1.AddToScheme(scheme)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:                  scheme,
+		Metrics:                 metricsserver.Options{BindAddress: *metricsAddress},
+		HealthProbeBindAddress:  *probeAddress,
+		LeaderElection:          *leaderElect,
+		LeaderElectionID:        "monolith-operator.monolith.tokencard.io",
+		LeaderElectionNamespace: *namespace,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := &operator.Reconciler{
+		Client: mgr.GetClient(),
+		Scheme: scheme,
+		Logger: slog.New(handler).With("module", "operator"),
+	}
+	err = r.SetupWithManager(mgr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = mgr.AddHealthzCheck("healthz", healthz.Ping)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = mgr.AddReadyzCheck("readyz", healthz.Ping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/airdrop"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+)
+
+// runAirdrop assigns a wallet to each owner of a CSV or JSON recipient list,
+// journaling the outcomes to resume an interrupted airdrop, and prints the
+// reconciliation of the journal with the chain as JSON.
+func runAirdrop(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("airdrop", flag.ContinueOnError)
+	journal := fs.String("journal", "", "file the outcome of each recipient is appended to, the recipient file with .journal.jsonl appended by default")
+	deploy := fs.Bool("deploy", false, "deploy the wallets of the recipients beyond the cached wallets instead of refusing to start")
+	reconcile := fs.Bool("reconcile", false, "only print the reconciliation of the journal with the chain")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return invalid(errors.New("usage: airdrop [-journal file] [-deploy] [-reconcile] <recipients.csv|recipients.json>"))
+	}
+	recipients, err := airdrop.ReadRecipientsFile(fs.Arg(0))
+	if err != nil {
+		return invalid(err)
+	}
+	if *journal == "" {
+		*journal = fs.Arg(0) + ".journal.jsonl"
+	}
+	deployer, err := e.cfg.contract("wallet_deployer")
+	if err != nil {
+		return err
+	}
+	cache, err := e.cfg.contract("wallet_cache")
+	if err != nil {
+		return err
+	}
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	p, err := provision.New(deployer, cache, e.backend, e.client, opts, provision.NewMemoryAssignments())
+	if err != nil {
+		return err
+	}
+	a := airdrop.New(p, *journal)
+
+	var report *airdrop.Report
+	if *reconcile {
+		report, err = a.Reconcile(ctx, recipients)
+	} else {
+		if !*deploy {
+			err = a.CheckSupply(ctx, recipients)
+			if errors.Cause(err) == airdrop.ErrSupply {
+				return rejectedf("%v, refill the cache or pass -deploy", err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		a.Progress = func(done, total int, r airdrop.Result) {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s failed: %s\n", done, total, r.Owner.Hex(), r.Error)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s assigned %s\n", done, total, r.Owner.Hex(), r.Wallet.Hex())
+		}
+		report, err = a.Run(ctx, recipients)
+	}
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(report)
+	if err != nil {
+		return err
+	}
+	if !report.Complete() {
+		return errors.Errorf("%d of %d recipients have their wallet, run the airdrop again", report.Assigned, report.Recipients)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/backfill"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/pseudonym"
+)
+
+func runBackfill(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := fs.Uint64("from", 0, "first block to scan, usually the deployment block of the contract")
+	to := fs.Uint64("to", 0, "last block to scan, the current head when zero")
+	rangeSize := fs.Uint64("range", backfill.DefaultRangeSize, "number of blocks filtered at once")
+	events := fs.String("events", "", "comma separated names of the events to scan, all when empty")
+	checkpoint := fs.String("checkpoint", "", "file recording the progress, the backfill resumes from it")
+	out := fs.String("out", "", "file the events are appended to as JSON lines, stdout when empty")
+	store := fs.String("store", "", "event store the events are committed to along with the progress, exactly once, instead of -checkpoint and -out")
+	keyEnv := fs.String("pseudonym-key-env", "", "environment variable holding the hex encoded key replacing the addresses and transaction hashes written by pseudonyms, see reidentify")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*checkpoint == "") == (*store == "") || (*store != "" && (*out != "" || *keyEnv != "")) {
+		return invalid(errors.New("usage: backfill (-checkpoint file [-out file] [-pseudonym-key-env name] | -store file) [-from block] [-to block] [-range blocks] [-events names] <contract>"))
+	}
+	var key *pseudonym.Key
+	if *keyEnv != "" {
+		key, err = pseudonym.ParseKey(os.Getenv(*keyEnv))
+		if err != nil {
+			return invalid(errors.Wrap(err, *keyEnv))
+		}
+	}
+
+	name := fs.Arg(0)
+	address, err := e.cfg.contract(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := contractABI(name)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New()
+	logger.SetHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	contract := indexer.Contract{Name: name, Address: address, ABI: parsed}
+
+	if *store != "" {
+		s, err := indexer.OpenFileStore(*store)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		b := backfill.New(e.logs, backfill.NewStoreCheckpoint(s), nil, contract)
+		return runBackfillWith(ctx, b, *from, *to, *rangeSize, *events, logger)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errors.Wrap(err, "opening output")
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+
+	b := backfill.New(e.logs, backfill.NewFileCheckpoint(*checkpoint), indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+		for _, ev := range events {
+			if key != nil {
+				ev = key.Event(ev)
+			}
+			err := enc.Encode(eventLine{
+				Block:  ev.BlockNumber,
+				TxHash: ev.TxHash,
+				Index:  ev.LogIndex,
+				Event:  ev.Name,
+				Args:   ev.Args,
+			})
+			if err != nil {
+				return errors.Wrap(err, "writing events")
+			}
+		}
+		return nil
+	}), contract)
+	return runBackfillWith(ctx, b, *from, *to, *rangeSize, *events, logger)
+}
+
+func runBackfillWith(ctx context.Context, b *backfill.Backfill, from, to, rangeSize uint64, events string, logger log.Logger) error {
+	b.StartBlock = from
+	b.EndBlock = to
+	b.RangeSize = rangeSize
+	if events != "" {
+		b.Events = strings.Split(events, ",")
+	}
+	b.Logger = logger
+	return b.Run(ctx)
+}
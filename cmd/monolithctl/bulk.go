@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bulk"
+)
+
+// runBulkTransfer sends the transfers of a token listed in a CSV file from the
+// account, in chunks journaled to resume an interrupted batch, and prints the
+// verification of the journal against the chain as JSON.
+func runBulkTransfer(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("bulk-transfer", flag.ContinueOnError)
+	token := fs.String("token", "", "address of the transferred token")
+	journal := fs.String("journal", "", "file each transaction is appended to, the transfer file with .journal.jsonl appended by default")
+	chunk := fs.Int("chunk", bulk.DefaultChunkSize, "number of transactions sent together")
+	allowContracts := fs.Bool("allow-contracts", false, "accept the contracts as receivers, the tokens are lost if they can not move them")
+	verify := fs.Bool("verify", false, "only print the verification of the journal against the chain")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *token == "" || *chunk <= 0 {
+		return invalid(errors.New("usage: bulk-transfer -token address [-journal file] [-chunk n] [-allow-contracts] [-verify] <transfers.csv>"))
+	}
+	address, err := parseAddress(*token)
+	if err != nil {
+		return errors.Wrap(err, "-token")
+	}
+	transfers, err := bulk.ReadTransfersFile(fs.Arg(0))
+	if err != nil {
+		return invalid(err)
+	}
+	if *journal == "" {
+		*journal = fs.Arg(0) + ".journal.jsonl"
+	}
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	b := bulk.New(address, e.backend, e.client, opts, *journal)
+	b.ChunkSize = *chunk
+	b.AllowContracts = *allowContracts
+
+	var report *bulk.Report
+	if *verify {
+		report, err = b.Verify(ctx, transfers)
+	} else {
+		rejected, err := b.Preflight(ctx, transfers)
+		for _, r := range rejected {
+			fmt.Fprintf(os.Stderr, "%s rejected: %s\n", r.To.Hex(), r.Reason)
+		}
+		if errors.Cause(err) == bulk.ErrBalance {
+			return rejectedf("%v", err)
+		}
+		if err != nil {
+			return err
+		}
+		if len(rejected) > 0 {
+			return rejectedf("%d receivers rejected by the preflight, fix the transfer file", len(rejected))
+		}
+		b.Progress = func(done, total int, r bulk.Result) {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s failed: %s\n", done, total, r.To.Hex(), r.Error)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s received %s in %s\n", done, total, r.To.Hex(), r.Amount, r.TxHash.Hex())
+		}
+		report, err = b.Run(ctx, transfers)
+	}
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(report)
+	if err != nil {
+		return err
+	}
+	if !report.Complete() {
+		return errors.Errorf("%d of %d transfers verified, run the batch again", report.Verified, report.Transfers)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// config is the JSON configuration file of monolithctl:
+//
+//	{
+//	  "rpc_url": "http://localhost:8545",
+//	  "chain_id": 1,
+//	  "keystore_dir": "/secrets/keystore",
+//	  "account": "0x...",
+//	  "password_env": "MONOLITHCTL_PASSWORD",
+//	  "gas_strategy": "standard",
+//	  "method_defaults_file": "/etc/monolith/methods.json",
+//	  "audit_file": "/var/log/monolith/console.jsonl",
+//	  "contracts": {
+//	    "controller": "0x...",
+//	    "licence": "0x..."
+//	  }
+//	}
+//
+// A single keystore_file can be set instead of keystore_dir and account, or
+// transactions can be signed on a Ledger or Trezor with:
+//
+//	"hardware_wallet": {"kind": "ledger", "path": "m/44'/60'/0'/0/0"}
+//
+// Hardware wallets are only supported by the builds made with cgo. The
+// rpc_url may also be the path of the IPC endpoint of a local node, its Unix
+// socket or its named pipe on Windows, \\.\pipe\geth.ipc.
+//
+// The owner-restricted methods of contracts owned by a Gnosis Safe are called
+// through the safe command, with the address of the Safe in:
+//
+//	"safe": "0x..."
+//
+// The commands refuse to run when the node of rpc_url is not on the network
+// of chain_id, and check it again before sending each transaction. Without
+// chain_id, the transactions are kept on the network of the node when the
+// command started.
+//
+// The addresses of the contracts on the network of the node can be read from
+// a registry file, see pkg/registry, the addresses under contracts overriding
+// them. The registry must have the network of the node:
+//
+//	"registry_file": "/etc/monolith/networks.yaml"
+//
+// The audit-fleet command audits the contracts of every network of the
+// registry, connecting to the nodes of networks by chain ID:
+//
+//	"networks": {"1": "https://mainnet.example", "3": "https://ropsten.example"}
+//
+// The audit_file records the commands run from the console, it defaults to
+// ~/.monolithctl_audit.jsonl.
+//
+// The recipients of the claims, ownership transfers, sweeps and Safe
+// proposals are checked against the configured addresses and the recipients
+// of the last 90 days, kept in address_book_file, which defaults to
+// ~/.monolithctl_addresses.jsonl. A recipient sharing lookalike_min_shared (6
+// by default) of its first and last hex characters with one of them must be
+// typed again in full.
+//
+// The recipients and the accounts given to the commands may be ENS names,
+// resolved with the registry of the ens_registry contract, or the ENS
+// registry of mainnet when it is not configured. The resolved address is
+// shown before it is used, and the owners are shown with their primary name.
+type config struct {
+	RPCURL             string                    `json:"rpc_url"`
+	ChainID            uint64                    `json:"chain_id"`
+	KeystoreFile       string                    `json:"keystore_file"`
+	KeystoreDir        string                    `json:"keystore_dir"`
+	Account            common.Address            `json:"account"`
+	PasswordEnv        string                    `json:"password_env"`
+	GasStrategy        string                    `json:"gas_strategy"`
+	MethodDefaultsFile string                    `json:"method_defaults_file"`
+	AuditFile          string                    `json:"audit_file"`
+	AddressBookFile    string                    `json:"address_book_file"`
+	LookalikeMinShared int                       `json:"lookalike_min_shared"`
+	Safe               common.Address            `json:"safe"`
+	RegistryFile       string                    `json:"registry_file"`
+	Networks           map[string]string         `json:"networks"`
+	Contracts          map[string]common.Address `json:"contracts"`
+	HardwareWallet     struct {
+		Kind string `json:"kind"`
+		Path string `json:"path"`
+	} `json:"hardware_wallet"`
+}
+
+const defaultPasswordEnv = "MONOLITHCTL_PASSWORD"
+
+func defaultConfigPath() string {
+	if p := os.Getenv("MONOLITHCTL_CONFIG"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "monolithctl.json"
+	}
+	return filepath.Join(home, ".monolithctl.json")
+}
+
+func defaultAuditPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "monolithctl_audit.jsonl"
+	}
+	return filepath.Join(home, ".monolithctl_audit.jsonl")
+}
+
+func defaultAddressBookPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "monolithctl_addresses.jsonl"
+	}
+	return filepath.Join(home, ".monolithctl_addresses.jsonl")
+}
+
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, invalid(errors.Wrap(err, "opening configuration file"))
+	}
+	defer f.Close()
+
+	cfg := &config{}
+	err = json.NewDecoder(f).Decode(cfg)
+	if err != nil {
+		return nil, invalid(errors.Wrapf(err, "decoding configuration file %s", path))
+	}
+
+	if cfg.RPCURL == "" {
+		return nil, invalid(errors.New("rpc_url is not set in the configuration file"))
+	}
+	if cfg.PasswordEnv == "" {
+		cfg.PasswordEnv = defaultPasswordEnv
+	}
+	if cfg.AuditFile == "" {
+		cfg.AuditFile = defaultAuditPath()
+	}
+	if cfg.AddressBookFile == "" {
+		cfg.AddressBookFile = defaultAddressBookPath()
+	}
+	return cfg, nil
+}
+
+// contract returns the configured address of the named contract.
+func (c *config) contract(name string) (common.Address, error) {
+	a, ok := c.Contracts[name]
+	if !ok {
+		return common.Address{}, invalidf("address of contract %q is not configured", name)
+	}
+	return a, nil
+}
+
+// auditLogger returns a logger appending JSON records to the audit file, and
+// the function closing the file.
+func (c *config) auditLogger() (log.Logger, func() error, error) {
+	f, err := os.OpenFile(c.AuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "opening audit file")
+	}
+	logger := log.New()
+	logger.SetHandler(log.StreamHandler(f, log.JSONFormat()))
+	return logger, f.Close, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/conformance"
+)
+
+func runConformance(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	vectors := fs.String("vectors", "", "JSON file of the vectors, the published ones by default")
+	export := fs.Bool("export", false, "print the vectors instead of running them")
+	verbose := fs.Bool("v", false, "print the passed vectors too")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: conformance [-vectors file] [-export] [-v] [program [args...]]")
+		fmt.Fprintln(os.Stderr, "\nThe program reads a vector per line of its standard input and writes its outcome")
+		fmt.Fprintln(os.Stderr, "per line of its standard output. Without a program, the vectors are run against")
+		fmt.Fprintln(os.Stderr, "this build.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	suite := conformance.Default()
+	if *vectors != "" {
+		var err error
+		suite, err = conformance.Load(*vectors)
+		if err != nil {
+			return err
+		}
+	}
+	if *export {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(suite)
+	}
+
+	impl := conformance.Implementation(conformance.Reference)
+	if fs.NArg() > 0 {
+		p, err := conformance.StartProcess(ctx, fs.Arg(0), fs.Args()[1:]...)
+		if err != nil {
+			return err
+		}
+		defer p.Close()
+		impl = p.Run
+	}
+
+	results, err := conformance.Run(suite, impl)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if *verbose || !r.Passed {
+			fmt.Println(r)
+		}
+	}
+	failed := conformance.Failed(results)
+	fmt.Printf("%d of %d vectors passed\n", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		return errors.Errorf("%d vectors failed", len(failed))
+	}
+	return nil
+}
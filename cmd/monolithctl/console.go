@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/script"
+	"go.starlark.net/starlark"
+)
+
+const consolePrompt = "monolith> "
+
+func init() {
+	// Registered here as runConsole refers to the commands map.
+	commands["console"] = command{"run commands interactively or from a script", runConsole}
+}
+
+// runConsole reads commands from a script, or from the standard input when no
+// script is given, and runs them against a single connection:
+//
+//	# rotate the licence DAO
+//	roles 0x...
+//	set-licence-amount 10
+//
+// Lines hold a command and its arguments as on the command line, quoted with
+// single or double quotes when they contain spaces. Every transaction sent from
+// the console is simulated first, whatever the method defaults, and the
+// commands are recorded in the audit file of the configuration. A script stops
+// at the first failing command.
+//
+// The scripts ending in .star are Starlark programs calling the commands as
+// functions, with the same simulation and audit, see package script and
+// consoleGlobals for the functions returning the state of the contracts:
+//
+//	for account in ["0x...", "0x..."]:
+//	    if controller_roles(account)["admin"]:
+//	        print(account, "is still an admin")
+func runConsole(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("console", flag.ContinueOnError)
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return invalid(errors.New("usage: console [script]"))
+	}
+
+	audit, closeAudit, err := e.cfg.auditLogger()
+	if err != nil {
+		return err
+	}
+	defer closeAudit()
+
+	e.backend.SetDryRun(true)
+	e.backend.SetLogger(audit.New("module", "txmgr"))
+
+	c := &console{env: e, audit: audit.New("module", "console")}
+
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return errors.Wrap(err, "opening script")
+		}
+		defer f.Close()
+		if strings.HasSuffix(fs.Arg(0), ".star") {
+			return c.runStarlark(ctx, fs.Arg(0), f)
+		}
+		return c.run(ctx, f, nil, true)
+	}
+
+	var prompt io.Writer
+	if isTerminal(os.Stdin) {
+		prompt = os.Stdout
+	}
+	return c.run(ctx, os.Stdin, prompt, false)
+}
+
+type console struct {
+	env   *env
+	audit log.Logger
+}
+
+// run executes the commands read from r, writing a prompt before each line when
+// prompt is not nil. It returns the first error when stopOnError is set.
+func (c *console) run(ctx context.Context, r io.Reader, prompt io.Writer, stopOnError bool) error {
+	s := bufio.NewScanner(r)
+	for line := 1; ; line++ {
+		if prompt != nil {
+			fmt.Fprint(prompt, consolePrompt)
+		}
+		if !s.Scan() {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := c.exec(ctx, s.Text())
+		if err == errExit {
+			return nil
+		}
+		if err != nil {
+			if stopOnError {
+				return errors.Wrapf(err, "line %d", line)
+			}
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+	if prompt != nil {
+		fmt.Fprintln(prompt)
+	}
+	return s.Err()
+}
+
+var errExit = errors.New("exit")
+
+// exec runs a single console line.
+func (c *console) exec(ctx context.Context, line string) error {
+	args, err := splitLine(line)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return errExit
+	case "help":
+		c.help()
+		return nil
+	case "console":
+		return errors.New("console cannot be nested")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return errors.Errorf("unknown command %q, type help for the list of commands", args[0])
+	}
+	return c.command(ctx, args[0], cmd, args[1:])
+}
+
+// command runs a command of the console, recording it in the audit file.
+func (c *console) command(ctx context.Context, name string, cmd command, args []string) error {
+	line := strings.Join(append([]string{name}, args...), " ")
+	c.audit.Info("Running command", "user", username(), "command", line)
+	err := cmd.run(ctx, c.env, args)
+	if err != nil {
+		c.audit.Warn("Command failed", "command", name, "err", err)
+		return err
+	}
+	return nil
+}
+
+// runStarlark runs the Starlark script read from r.
+func (c *console) runStarlark(ctx context.Context, filename string, r io.Reader) error {
+	s := &script.Script{
+		Commands: make(map[string]script.Command, len(commands)),
+		Globals:  c.globals(),
+	}
+	for name, cmd := range commands {
+		if name == "console" {
+			continue
+		}
+		name, cmd := name, cmd
+		s.Commands[name] = func(ctx context.Context, args []string) error {
+			return c.command(ctx, name, cmd, args)
+		}
+	}
+	c.audit.Info("Running script", "user", username(), "script", filename)
+	err := s.Exec(ctx, filename, r)
+	if err != nil {
+		c.audit.Warn("Script failed", "script", filename, "err", err)
+	}
+	return err
+}
+
+// globals returns the functions of the Starlark scripts returning the state
+// of the chain and of the configured contracts:
+//
+//	block_number()            the number of the head of the chain
+//	balance(address)          the ether balance of an address in wei
+//	contract_address(name)    the address of a configured contract
+//	controller_roles(address) the roles of an address, {"owner": ..., "admin": ..., "controller": ...}
+//	licence_amount()          the scaled licence amount of the licence
+//
+// The addresses are hex or ENS names.
+func (c *console) globals() starlark.StringDict {
+	e := c.env
+	address := func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (common.Address, error) {
+		var s string
+		err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &s)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return e.resolveAddress(script.Context(thread), s)
+	}
+	return starlark.StringDict{
+		"block_number": starlark.NewBuiltin("block_number", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0)
+			if err != nil {
+				return nil, err
+			}
+			head, err := e.client.HeaderByNumber(script.Context(thread), nil)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting latest block")
+			}
+			return starlark.MakeBigInt(head.Number), nil
+		}),
+		"balance": starlark.NewBuiltin("balance", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			account, err := address(thread, fn, args, kwargs)
+			if err != nil {
+				return nil, err
+			}
+			balance, err := e.client.BalanceAt(script.Context(thread), account, nil)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting balance of %s", account.Hex())
+			}
+			return starlark.MakeBigInt(balance), nil
+		}),
+		"contract_address": starlark.NewBuiltin("contract_address", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var name string
+			err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &name)
+			if err != nil {
+				return nil, err
+			}
+			a, err := e.cfg.contract(name)
+			if err != nil {
+				return nil, err
+			}
+			return starlark.String(a.Hex()), nil
+		}),
+		"controller_roles": starlark.NewBuiltin("controller_roles", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			account, err := address(thread, fn, args, kwargs)
+			if err != nil {
+				return nil, err
+			}
+			a, err := e.cfg.contract("controller")
+			if err != nil {
+				return nil, err
+			}
+			controller, err := bindings.NewAccessClient(a, e.backend)
+			if err != nil {
+				return nil, err
+			}
+			r, err := controller.Roles(script.Context(thread), account)
+			if err != nil {
+				return nil, err
+			}
+			roles := starlark.NewDict(3)
+			roles.SetKey(starlark.String("owner"), starlark.Bool(r.Owner))
+			roles.SetKey(starlark.String("admin"), starlark.Bool(r.Admin))
+			roles.SetKey(starlark.String("controller"), starlark.Bool(r.Controller))
+			return roles, nil
+		}),
+		"licence_amount": starlark.NewBuiltin("licence_amount", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0)
+			if err != nil {
+				return nil, err
+			}
+			a, err := e.cfg.contract("licence")
+			if err != nil {
+				return nil, err
+			}
+			licence, err := bindings.NewLicenceCaller(a, e.backend)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := licence.LicenceAmountScaled(&bind.CallOpts{Context: script.Context(thread)})
+			if err != nil {
+				return nil, errors.Wrap(err, "getting licence amount")
+			}
+			return starlark.MakeBigInt(amount), nil
+		}),
+	}
+}
+
+func (c *console) help() {
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		if n != "console" {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Printf("  %-20s %s\n", n, commands[n].summary)
+	}
+	fmt.Printf("  %-20s %s\n", "exit", "leave the console")
+}
+
+// splitLine splits a line into words separated by spaces. Words can be quoted
+// with single or double quotes, a # outside quotes starts a comment.
+func splitLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == '#':
+			if inWord {
+				words = append(words, word.String())
+			}
+			return words, nil
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func username() string {
+	u, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+	return u.Username
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/units"
+)
+
+func contractNames() string {
+	names := make([]string, 0, len(bindings.ContractABIs))
+	for n := range bindings.ContractABIs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func contractABI(name string) (abi.ABI, error) {
+	a, ok := bindings.ContractABIs[name]
+	if !ok {
+		return abi.ABI{}, invalidf("unknown contract %q, expected one of %s", name, contractNames())
+	}
+	return abi.JSON(strings.NewReader(a))
+}
+
+// namehash returns the ENS node of the given name.
+func namehash(name string) [32]byte {
+	node := common.Hash{}
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], label[:])
+	}
+	return node
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, invalidf("%q is not a valid address", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	a, err := units.ParseUint(s)
+	if err != nil {
+		return nil, invalidf("%q is not a valid amount", s)
+	}
+	return a, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// ensNodes are the ENS names the contracts use to locate each other.
+type ensNodes struct {
+	controller     *string
+	licence        *string
+	oracle         *string
+	tokenWhitelist *string
+	walletCache    *string
+	walletDeployer *string
+}
+
+func addENSFlags(fs *flag.FlagSet) ensNodes {
+	return ensNodes{
+		controller:     fs.String("controller-node", "controller.tokencard.eth", "ENS name of the controller contract"),
+		licence:        fs.String("licence-node", "licence.tokencard.eth", "ENS name of the licence contract"),
+		oracle:         fs.String("oracle-node", "oracle.tokencard.eth", "ENS name of the oracle contract"),
+		tokenWhitelist: fs.String("token-whitelist-node", "token-whitelist.tokencard.eth", "ENS name of the token whitelist contract"),
+		walletCache:    fs.String("wallet-cache-node", "wallet-cache.v3.tokencard.eth", "ENS name of the wallet cache contract"),
+		walletDeployer: fs.String("wallet-deployer-node", "wallet-deployer.v3.tokencard.eth", "ENS name of the wallet deployer contract"),
+	}
+}
+
+func runDeploy(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return invalidf("usage: deploy <contract> [flags], contract is one of %s", contractNames())
+	}
+	name := args[0]
+
+	fs := flag.NewFlagSet("deploy "+name, flag.ContinueOnError)
+	nodes := addENSFlags(fs)
+	owner := fs.String("owner", "", "owner of the controller contract")
+	burner := fs.String("burner", "", "address of the TKN contract allowed to burn")
+	resolver := fs.String("resolver", "", "address of the Oraclize address resolver")
+	stablecoin := fs.String("stablecoin", "", "address of the stablecoin token")
+	licenceAmount := fs.String("licence-amount", "10", "licence amount scaled by 1000")
+	float := fs.String("float", "", "address of the crypto float")
+	holder := fs.String("holder", "", "address of the token holder contract")
+	tkn := fs.String("tkn", "", "address of the TKN contract")
+	spendLimit := fs.String("default-spend-limit", "", "default spend limit of cached wallets, in wei")
+	err := parseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	ens, err := e.cfg.contract("ens_registry")
+	if err != nil {
+		return err
+	}
+
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var address common.Address
+	var tx *types.Transaction
+
+	switch name {
+	case "controller":
+		a, err := parseAddress(*owner)
+		if err != nil {
+			return errors.Wrap(err, "-owner")
+		}
+		address, tx, _, err = bindings.DeployController(opts, e.backend, a)
+		if err != nil {
+			return err
+		}
+	case "holder":
+		b, err := parseAddress(*burner)
+		if err != nil {
+			return errors.Wrap(err, "-burner")
+		}
+		address, tx, _, err = bindings.DeployHolder(opts, e.backend, b, ens, namehash(*nodes.tokenWhitelist), namehash(*nodes.controller))
+		if err != nil {
+			return err
+		}
+	case "licence":
+		amount, err := parseAmount(*licenceAmount)
+		if err != nil {
+			return errors.Wrap(err, "-licence-amount")
+		}
+		addrs, err := parseAddresses(map[string]string{"-float": *float, "-holder": *holder, "-tkn": *tkn})
+		if err != nil {
+			return err
+		}
+		address, tx, _, err = bindings.DeployLicence(opts, e.backend, amount, addrs["-float"], addrs["-holder"], addrs["-tkn"], ens, namehash(*nodes.controller))
+		if err != nil {
+			return err
+		}
+	case "oracle":
+		r, err := parseAddress(*resolver)
+		if err != nil {
+			return errors.Wrap(err, "-resolver")
+		}
+		address, tx, _, err = bindings.DeployOracle(opts, e.backend, r, ens, namehash(*nodes.controller), namehash(*nodes.tokenWhitelist))
+		if err != nil {
+			return err
+		}
+	case "token_whitelist":
+		s, err := parseAddress(*stablecoin)
+		if err != nil {
+			return errors.Wrap(err, "-stablecoin")
+		}
+		address, tx, _, err = bindings.DeployTokenWhitelist(opts, e.backend, ens, namehash(*nodes.oracle), namehash(*nodes.controller), s)
+		if err != nil {
+			return err
+		}
+	case "wallet_cache":
+		limit, err := parseAmount(*spendLimit)
+		if err != nil {
+			return errors.Wrap(err, "-default-spend-limit")
+		}
+		address, tx, _, err = bindings.DeployWalletCache(opts, e.backend, ens, limit, namehash(*nodes.controller), namehash(*nodes.licence), namehash(*nodes.tokenWhitelist), namehash(*nodes.walletDeployer))
+		if err != nil {
+			return err
+		}
+	case "wallet_deployer":
+		address, tx, _, err = bindings.DeployWalletDeployer(opts, e.backend, ens, namehash(*nodes.controller), namehash(*nodes.walletCache))
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("cannot deploy %q, expected one of controller, holder, licence, oracle, token_whitelist, wallet_cache, wallet_deployer", name)
+	}
+
+	_, err = e.wait(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s deployed at %s\n", name, address.Hex())
+	return nil
+}
+
+func parseAddresses(flags map[string]string) (map[string]common.Address, error) {
+	r := make(map[string]common.Address, len(flags))
+	for f, v := range flags {
+		a, err := parseAddress(v)
+		if err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+		r[f] = a
+	}
+	return r, nil
+}
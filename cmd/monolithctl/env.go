@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/gas"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+	"github.com/tokencard/contracts/v2/pkg/logfilter"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+)
+
+// env holds the connections shared by the commands.
+type env struct {
+	cfg    *config
+	client *ethclient.Client
+	// logs filters the logs of large block ranges in chunks.
+	logs    *logfilter.Backend
+	backend *txmgr.Manager
+	chainID *big.Int
+	// hardware is the hardware wallet opened by transactOpts.
+	hardware *signer.HardwareWallet
+}
+
+func newEnv(ctx context.Context, cfg *config) (*env, error) {
+	client, err := ethclient.DialContext(ctx, cfg.RPCURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to %s", cfg.RPCURL)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrap(err, "getting chain ID")
+	}
+
+	if cfg.ChainID != 0 {
+		err = registry.CheckChain(ctx, client, new(big.Int).SetUint64(cfg.ChainID))
+		if err != nil {
+			client.Close()
+			return nil, rejectedf("%v, check rpc_url", err)
+		}
+	}
+
+	if cfg.RegistryFile != "" {
+		reg, err := registry.LoadFile(cfg.RegistryFile)
+		if err != nil {
+			client.Close()
+			return nil, invalid(err)
+		}
+		err = reg.CheckNetwork(chainID)
+		if err != nil {
+			client.Close()
+			return nil, rejectedf("%v, check rpc_url", err)
+		}
+		contracts := reg.Network(chainID)
+		for name, a := range cfg.Contracts {
+			contracts[name] = a
+		}
+		cfg.Contracts = contracts
+	}
+
+	strategy, err := gas.ParseStrategy(cfg.GasStrategy)
+	if err != nil {
+		client.Close()
+		return nil, invalid(err)
+	}
+	oracle := gas.NewOracle(gas.NewNodeSource(client), strategy)
+
+	methods := txmgr.NewRegistry()
+	if cfg.MethodDefaultsFile != "" {
+		methods, err = txmgr.LoadRegistryFile(cfg.MethodDefaultsFile)
+		if err != nil {
+			client.Close()
+			return nil, invalid(err)
+		}
+	}
+
+	// The transactions are only sent to the network of the node when the
+	// command started.
+	logs := logfilter.NewBackend(client, client)
+	guard := registry.NewGuard(logs, client, chainID)
+	return &env{
+		cfg:     cfg,
+		client:  client,
+		logs:    logs,
+		backend: txmgr.NewWithRegistry(gas.NewBackend(guard, oracle), methods),
+		chainID: chainID,
+	}, nil
+}
+
+func (e *env) close() {
+	if e.hardware != nil {
+		e.hardware.Close()
+	}
+	e.client.Close()
+}
+
+// transactOpts unlocks the configured operator account and returns options
+// signing with its key.
+func (e *env) transactOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	var opts *bind.TransactOpts
+	switch {
+	case e.cfg.HardwareWallet.Kind != "":
+		w, err := e.hardwareWallet()
+		if err != nil {
+			return nil, err
+		}
+		opts = w.TransactOpts(e.chainID)
+	case e.cfg.KeystoreDir != "":
+		var err error
+		opts, err = keys.Open(e.cfg.KeystoreDir).TransactOpts(e.cfg.Account, os.Getenv(e.cfg.PasswordEnv), e.chainID)
+		if err != nil {
+			return nil, err
+		}
+	case e.cfg.KeystoreFile != "":
+		key, err := signer.DecryptKeyFile(e.cfg.KeystoreFile, os.Getenv(e.cfg.PasswordEnv))
+		if err != nil {
+			return nil, err
+		}
+		opts = signer.NewTransactOpts(key, e.chainID)
+	default:
+		return nil, errors.New("none of hardware_wallet, keystore_dir and keystore_file is set in the configuration file")
+	}
+	opts.Context = ctx
+	return opts, nil
+}
+
+// hardwareWallet opens the configured hardware wallet once, asking for the PIN
+// and passphrase of a locked Trezor on the terminal.
+func (e *env) hardwareWallet() (*signer.HardwareWallet, error) {
+	if e.hardware != nil {
+		return e.hardware, nil
+	}
+	path, err := signer.ParseDerivationPath(e.cfg.HardwareWallet.Path)
+	if err != nil {
+		return nil, invalid(errors.Wrap(err, "hardware_wallet"))
+	}
+	w, err := signer.OpenHardwareWallet(signer.HardwareConfig{
+		Kind:       e.cfg.HardwareWallet.Kind,
+		Path:       path,
+		PIN:        prompt("Enter the PIN using the layout shown on the device (7 8 9 / 4 5 6 / 1 2 3): "),
+		Passphrase: prompt("Enter the passphrase of the device: "),
+		Confirm: func(tx *types.Transaction) {
+			fmt.Fprintf(os.Stderr, "Confirm transaction %d on the device...\n", tx.Nonce())
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "signing with %s\n", w.Address().Hex())
+	e.hardware = w
+	return w, nil
+}
+
+// prompt returns a function reading a line from the terminal after printing msg.
+func prompt(msg string) func() (string, error) {
+	return func() (string, error) {
+		fmt.Fprint(os.Stderr, msg)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return "", errors.Wrap(err, "reading the terminal")
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
+// wait prints the hash of the transaction and waits for it to be mined and
+// buried under the number of confirmations configured for the method called.
+func (e *env) wait(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	fmt.Printf("transaction %s sent\n", tx.Hash().Hex())
+	r, err := e.backend.Wait(ctx, e.client, tx)
+	if err != nil {
+		return r, err
+	}
+	fmt.Printf("transaction mined in block %s, gas used %d\n", r.BlockNumber, r.GasUsed)
+	return r, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/rotation"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+)
+
+// failureClass is the class of the failure of a command, which sets the exit
+// status of monolithctl. The statuses are stable, scripts may branch on them:
+//
+//	0  success
+//	1  failure     any failure of another class
+//	2  validation  invalid command line, argument or configuration
+//	3  rpc         the node could not be reached or failed a request
+//	4  revert      a transaction or its simulation reverted
+//	5  policy      refused by a policy: transaction rate limit, unconfirmed
+//	               recipient, signer not authorized, aborted rotation, or
+//	               contracts failing the ownership policy or the roster
+type failureClass int
+
+const (
+	classFailure    failureClass = 1
+	classValidation failureClass = 2
+	classRPC        failureClass = 3
+	classRevert     failureClass = 4
+	classPolicy     failureClass = 5
+)
+
+func (c failureClass) String() string {
+	switch c {
+	case classValidation:
+		return "validation"
+	case classRPC:
+		return "rpc"
+	case classRevert:
+		return "revert"
+	case classPolicy:
+		return "policy"
+	default:
+		return "failure"
+	}
+}
+
+// classified is an error of a known class.
+type classified struct {
+	class failureClass
+	err   error
+}
+
+func (c *classified) Error() string {
+	return c.err.Error()
+}
+
+// Cause returns the classified error, for errors.Cause.
+func (c *classified) Cause() error {
+	return c.err
+}
+
+// invalid marks err as a validation error.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classified{class: classValidation, err: err}
+}
+
+// invalidf returns a validation error formatted like errors.Errorf.
+func invalidf(format string, args ...interface{}) error {
+	return invalid(errors.Errorf(format, args...))
+}
+
+// rejectedf returns a policy rejection formatted like errors.Errorf.
+func rejectedf(format string, args ...interface{}) error {
+	return &classified{class: classPolicy, err: errors.Errorf(format, args...)}
+}
+
+// parseFlags parses the flags of a command, the failures are validation
+// errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	return invalid(fs.Parse(args))
+}
+
+// revertMessages are parts of the errors of the nodes estimating the gas of a
+// reverting transaction, and of the dry runs of txmgr needing more gas than
+// the limit of the transaction.
+var revertMessages = []string{
+	"execution reverted",
+	"always failing transaction",
+	"gas required exceeds allowance",
+	"dry run failed: transaction needs",
+}
+
+// classify returns the class of err, marked where it was returned or derived
+// from its causes. The node reports reverts as errors of its own, they are
+// recognized by their message unless the node could not be reached: a dry
+// run failing to reach the node is an RPC failure, not a revert.
+func classify(err error) failureClass {
+	for e := err; e != nil; e = unwrap(e) {
+		if c, ok := e.(*classified); ok {
+			return c.class
+		}
+		switch e {
+		case txmgr.ErrReverted:
+			return classRevert
+		case txmgr.ErrRateLimited, signer.ErrNotAuthorized, rotation.ErrAborted:
+			return classPolicy
+		}
+		switch e.(type) {
+		case *txmgr.RevertError:
+			return classRevert
+		case *url.Error, net.Error:
+			return classRPC
+		}
+	}
+	for _, m := range revertMessages {
+		if strings.Contains(err.Error(), m) {
+			return classRevert
+		}
+	}
+	for e := err; e != nil; e = unwrap(e) {
+		if _, ok := e.(rpcError); ok {
+			return classRPC
+		}
+	}
+	return classFailure
+}
+
+// rpcError is implemented by the errors returned by the node.
+type rpcError interface {
+	ErrorCode() int
+}
+
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Cause() error }:
+		return e.Cause()
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// errorLine is the JSON representation of a failure printed with
+// -error-format json.
+type errorLine struct {
+	Command string `json:"command"`
+	Class   string `json:"class"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// reportError prints the failure of the command to w in the given format and
+// returns the exit status.
+func reportError(w io.Writer, format, command string, err error) int {
+	class := classify(err)
+	if format == "json" {
+		json.NewEncoder(w).Encode(errorLine{
+			Command: command,
+			Class:   class.String(),
+			Code:    int(class),
+			Message: err.Error(),
+		})
+	} else {
+		fmt.Fprintf(w, "%s: %v\n", command, err)
+	}
+	return int(class)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"math/big"
+	"os"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/watch"
+)
+
+// eventLine is the JSON representation of a decoded event printed by the events command.
+type eventLine struct {
+	Block   uint64                 `json:"block"`
+	TxHash  common.Hash            `json:"tx_hash"`
+	Index   uint                   `json:"log_index"`
+	Event   string                 `json:"event"`
+	Args    map[string]interface{} `json:"args"`
+	Removed bool                   `json:"removed,omitempty"`
+}
+
+func runEvents(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("events", flag.ContinueOnError)
+	from := fs.Uint64("from", 0, "first block to print events from")
+	follow := fs.Bool("follow", false, "keep printing new events as they are emitted, reconnecting when the connection drops (requires a websocket or IPC endpoint)")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return invalid(errors.New("usage: events [-from block] [-follow] <contract>"))
+	}
+
+	name := fs.Arg(0)
+	address, err := e.cfg.contract(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := contractABI(name)
+	if err != nil {
+		return err
+	}
+
+	head, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting latest block")
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(*from),
+		ToBlock:   head.Number,
+		Addresses: []common.Address{address},
+	}
+
+	logs, err := e.logs.FilterLogs(ctx, query)
+	if err != nil {
+		return errors.Wrap(err, "filtering logs")
+	}
+	for _, l := range logs {
+		err = printEvent(enc, parsed, l)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	f, err := watch.New(ctx, watch.DialURL(e.cfg.RPCURL))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logger := log.New()
+	logger.SetHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	f.Logger = logger
+
+	query.FromBlock = new(big.Int).Add(head.Number, big.NewInt(1))
+	query.ToBlock = nil
+	ch := make(chan types.Log)
+	sub, err := f.SubscribeFilterLogs(ctx, query, ch)
+	if err != nil {
+		return errors.Wrap(err, "subscribing to logs")
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case l := <-ch:
+			err = printEvent(enc, parsed, l)
+			if err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			return errors.Wrap(err, "log subscription failed")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func printEvent(enc *json.Encoder, parsed abi.ABI, l types.Log) error {
+	name, values, err := indexer.Decode(parsed, l)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(eventLine{
+		Block:   l.BlockNumber,
+		TxHash:  l.TxHash,
+		Index:   l.Index,
+		Event:   name,
+		Args:    values,
+		Removed: l.Removed,
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/audit"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+)
+
+func runAuditFleet(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("audit-fleet", flag.ContinueOnError)
+	rosterFile := fs.String("roster", "roster.yaml", "the approved owners and controller roles")
+	dir := fs.String("out", "audit", "the directory of the signed reports")
+	interval := fs.Duration("interval", 0, "audit periodically, once when zero")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return invalid(errors.New("usage: audit-fleet [-roster file] [-out dir] [-interval duration]"))
+	}
+	if e.cfg.RegistryFile == "" {
+		return invalid(errors.New("registry_file is not set in the configuration file"))
+	}
+
+	reg, err := registry.LoadFile(e.cfg.RegistryFile)
+	if err != nil {
+		return err
+	}
+	roster, err := audit.LoadRosterFile(*rosterFile)
+	if err != nil {
+		return err
+	}
+	backends, err := e.networkBackends(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	a := &audit.Auditor{
+		Registry: reg,
+		Backends: backends,
+		Roster:   roster,
+		Sign:     e.signHash,
+		Dir:      *dir,
+		Interval: *interval,
+		ErrorLog: log.New(os.Stderr, "audit: ", log.LstdFlags),
+	}
+	if *interval > 0 {
+		return a.Run(ctx)
+	}
+
+	r, err := a.Check(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range r.Changes {
+		fmt.Println(c)
+	}
+	if !r.Compliant() {
+		return rejectedf("the contracts of the report of %s do not comply with the roster", r.Time.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// networkBackends connects to the nodes of the networks of the registry, the
+// node of rpc_url serving its own network.
+func (e *env) networkBackends(ctx context.Context, reg *registry.Registry) (map[uint64]bind.ContractBackend, error) {
+	for id := range e.cfg.Networks {
+		_, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("%q of networks is not a valid chain ID", id)
+		}
+	}
+
+	backends := make(map[uint64]bind.ContractBackend)
+	for _, chainID := range reg.ChainIDs() {
+		url, ok := e.cfg.Networks[chainID.String()]
+		if !ok {
+			if chainID.Cmp(e.chainID) == 0 {
+				backends[chainID.Uint64()] = e.client
+			}
+			continue
+		}
+		client, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			return nil, errors.Wrapf(err, "connecting to %s", url)
+		}
+		backends[chainID.Uint64()] = client
+	}
+	return backends, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+)
+
+const keysUsage = `usage: keys list
+       keys new
+       keys import <keyfile>
+       keys rotate [address]
+       keys retire <address>
+       keys passwd [-new-password-env name] [address]`
+
+// runKeys manages the accounts of the configured keystore directory. The
+// passphrase is read from the password_env variable of the configuration.
+func runKeys(ctx context.Context, e *env, args []string) error {
+	if e.cfg.KeystoreDir == "" {
+		return invalid(errors.New("keystore_dir is not set in the configuration file"))
+	}
+	if len(args) == 0 {
+		return invalid(errors.New(keysUsage))
+	}
+	ks := keys.Open(e.cfg.KeystoreDir)
+	passphrase := os.Getenv(e.cfg.PasswordEnv)
+
+	// account returns the address given as argument, the configured account otherwise.
+	account := func(args []string) (common.Address, error) {
+		switch len(args) {
+		case 0:
+			if e.cfg.Account == (common.Address{}) {
+				return common.Address{}, invalid(errors.New("account is not set in the configuration file"))
+			}
+			return e.cfg.Account, nil
+		case 1:
+			return parseAddress(args[0])
+		default:
+			return common.Address{}, invalid(errors.New(keysUsage))
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		for _, a := range ks.Accounts() {
+			marker := " "
+			if a == e.cfg.Account {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, a.Hex())
+		}
+		return nil
+
+	case "new":
+		a, err := ks.Create(passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created account %s\n", a.Hex())
+		return nil
+
+	case "import":
+		if len(args) != 2 {
+			return invalid(errors.New(keysUsage))
+		}
+		a, err := ks.Import(args[1], passphrase, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported account %s\n", a.Hex())
+		return nil
+
+	case "rotate":
+		old, err := account(args[1:])
+		if err != nil {
+			return err
+		}
+		a, err := ks.Rotate(old, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created account %s replacing %s\n", a.Hex(), old.Hex())
+		fmt.Printf("transfer the roles of %s, e.g. with rotate-controller, update the configuration and run: keys retire %s\n", old.Hex(), old.Hex())
+		return nil
+
+	case "retire":
+		if len(args) != 2 {
+			return invalid(errors.New(keysUsage))
+		}
+		a, err := parseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		if a == e.cfg.Account {
+			return errors.Errorf("%s is the configured account", a.Hex())
+		}
+		err = ks.Retire(a, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("deleted account %s\n", a.Hex())
+		return nil
+
+	case "passwd":
+		fs := flag.NewFlagSet("keys passwd", flag.ContinueOnError)
+		newPasswordEnv := fs.String("new-password-env", "MONOLITHCTL_NEW_PASSWORD", "environment variable holding the new passphrase")
+		err := parseFlags(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		a, err := account(fs.Args())
+		if err != nil {
+			return err
+		}
+		newPassphrase := os.Getenv(*newPasswordEnv)
+		if newPassphrase == "" {
+			return errors.Errorf("%s is not set", *newPasswordEnv)
+		}
+		err = ks.ChangePassphrase(a, passphrase, newPassphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("updated the passphrase of account %s\n", a.Hex())
+		return nil
+	}
+	return invalid(errors.New(keysUsage))
+}
@@ -0,0 +1,148 @@
+// Command monolithctl is an operator tool wrapping the contract bindings.
+//
+// Usage:
+//
+//	monolithctl [-config path] <command> [flags] [args]
+//
+// The configuration file holds the RPC endpoint, the keystore used to sign
+// transactions and the addresses of the deployed contracts, see config.go.
+//
+// The exit status tells the class of a failure, see errors.go: 2 for an
+// invalid command line, argument or configuration, 3 when the node failed, 4
+// when a transaction reverted, 5 when a policy refused the command and 1 for
+// any other failure. With -error-format json, the failure is printed to
+// stderr as a JSON object with its command, class, code and message:
+//
+//	{"command":"claim","class":"revert","code":4,"message":"..."}
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"deploy":              {"deploy a contract", runDeploy},
+	"set-licence-amount":  {"update the licence amount (licence DAO only)", runSetLicenceAmount},
+	"claim":               {"claim assets held by a contract", runClaim},
+	"owner":               {"print the owner of a contract", runOwner},
+	"transfer-ownership":  {"transfer the ownership of a contract (owner only)", runTransferOwnership},
+	"audit-ownership":     {"check the owners of the configured contracts", runAuditOwnership},
+	"audit-fleet":         {"write a signed audit of the contracts of every network", runAuditFleet},
+	"roles":               {"print the controller roles of an address", runRoles},
+	"rotate-controller":   {"move the controller role to a new key, with rollback (admin only)", runRotateController},
+	"events":              {"print and optionally follow the events of a contract", runEvents},
+	"backfill":            {"scan the history of a contract in block ranges, resuming from a checkpoint", runBackfill},
+	"replay":              {"compare the events of contracts returned by two providers", runReplay},
+	"reconcile":           {"converge the contracts to a desired state spec", runReconcile},
+	"keys":                {"manage the accounts of the keystore directory", runKeys},
+	"safe":                {"propose, sign and execute calls from the owner Safe", runSafe},
+	"sweep":               {"propose the sweeps of the tokens held by the contracts to cold storage", runSweep},
+	"conformance":         {"check a decoder against the published test vectors", runConformance},
+	"airdrop":             {"assign a wallet to each owner of a recipient list, resuming from a journal (controller only)", runAirdrop},
+	"bulk-transfer":       {"send the transfers of a token listed in a file, resuming from a journal", runBulkTransfer},
+	"migrate-wallets":     {"migrate the wallets to a new wallet deployer, resuming from a journal (controller of the new deployer only)", runMigrateWallets},
+	"reidentify":          {"find the addresses behind the pseudonyms of an export (key holders only)", runReidentify},
+	"snapshot":            {"dump the state of the contracts at a block, for audits and migrations", runSnapshot},
+	"snapshot-diff":       {"report the changes between two blocks or snapshots, for the accounting", runSnapshotDiff},
+	"licence-what-if":     {"report the impact of a proposed licence amount on the fees, before set-licence-amount", runLicenceWhatIf},
+	"proof-bundle":        {"write a signed bundle of the receipts of payouts and their proofs, for the auditors", runProofBundle},
+	"verify-proof-bundle": {"verify the receipts of a proof bundle against the blocks of the node", runVerifyProofBundle},
+}
+
+// offline are the commands that do not connect to the node, only the
+// configuration of their env is set.
+var offline = map[string]bool{
+	"keys":        true,
+	"conformance": true,
+	"reidentify":  true,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [-config path] <command> [flags] [args]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", n, commands[n].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+// usageError exits on an invalid command line, printing the usage after err
+// in the text format.
+func usageError(format string, err error) {
+	if format == "json" {
+		os.Exit(reportError(os.Stderr, format, flag.Arg(0), err))
+	}
+	fmt.Fprintf(os.Stderr, "%v\n\n", err)
+	usage()
+	os.Exit(int(classify(err)))
+}
+
+func main() {
+	configPath := flag.String("config", defaultConfigPath(), "path to the configuration file")
+	errorFormat := flag.String("error-format", "text", "format of the failures printed to stderr, text or json")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *errorFormat != "text" && *errorFormat != "json" {
+		usageError("text", invalidf("unknown error format %q, expected text or json", *errorFormat))
+	}
+
+	if flag.NArg() == 0 {
+		usageError(*errorFormat, invalidf("missing command"))
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usageError(*errorFormat, invalidf("unknown command %q", flag.Arg(0)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	err := run(ctx, *configPath, cmd, offline[flag.Arg(0)], flag.Args()[1:])
+	if err != nil {
+		os.Exit(reportError(os.Stderr, *errorFormat, flag.Arg(0), err))
+	}
+}
+
+func run(ctx context.Context, configPath string, cmd command, offline bool, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if offline {
+		return cmd.run(ctx, &env{cfg: cfg}, args)
+	}
+
+	e, err := newEnv(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer e.close()
+
+	return cmd.run(ctx, e, args)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/migration"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+)
+
+// runMigrateWallets migrates the wallets of the configured wallet deployer to
+// a new one, after simulating every migration, resuming an interrupted
+// migration, and prints the verification of the new wallets as JSON.
+func runMigrateWallets(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("migrate-wallets", flag.ContinueOnError)
+	deployer := fs.String("deployer", "", "address of the new wallet deployer")
+	cache := fs.String("cache", "", "address of the wallet cache of the new wallet deployer")
+	from := fs.Uint64("from", 0, "first block searched for the wallets, usually the deployment block of the wallet deployer")
+	snapshotFile := fs.String("snapshot", "", "snapshot listing the wallets to migrate, taken at the head by default")
+	journal := fs.String("journal", "migrate-wallets.journal.jsonl", "file each migration is appended to")
+	dryRun := fs.Bool("dry-run", false, "only print the simulation of the migrations")
+	verify := fs.Bool("verify", false, "only print the verification of the new wallets")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *deployer == "" || *cache == "" || (*dryRun && *verify) {
+		return invalid(errors.New("usage: migrate-wallets -deployer address -cache address [-from block] [-snapshot file] [-journal file] [-dry-run | -verify]"))
+	}
+	deployerAddress, err := parseAddress(*deployer)
+	if err != nil {
+		return errors.Wrap(err, "-deployer")
+	}
+	cacheAddress, err := parseAddress(*cache)
+	if err != nil {
+		return errors.Wrap(err, "-cache")
+	}
+	if deployerAddress == e.cfg.Contracts["wallet_deployer"] {
+		return invalidf("the new wallet deployer is the configured one")
+	}
+
+	var s *snapshot.Snapshot
+	if *snapshotFile != "" {
+		s, err = snapshot.ReadJSONFile(*snapshotFile)
+	} else {
+		s, err = snapshot.Take(ctx, e.logs, snapshot.Contracts{WalletDeployer: e.cfg.Contracts["wallet_deployer"]}, snapshot.Options{StartBlock: *from})
+	}
+	if err != nil {
+		return err
+	}
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	m, err := migration.New(deployerAddress, cacheAddress, e.backend, e.client, opts, *journal)
+	if err != nil {
+		return err
+	}
+	steps, err := m.Plan(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	var report *migration.Report
+	if *verify {
+		report, err = m.Verify(ctx, steps)
+	} else {
+		d, err := m.DryRun(ctx, steps)
+		if err != nil {
+			return err
+		}
+		for _, r := range d.Rejected {
+			fmt.Fprintf(os.Stderr, "%s rejected: %s\n", r.OldWallet.Hex(), r.Reason)
+		}
+		if *dryRun {
+			return enc.Encode(d)
+		}
+		if len(d.Rejected) > 0 {
+			return rejectedf("%d wallets rejected by the dry run, see -dry-run", len(d.Rejected))
+		}
+		m.Progress = func(done, total int, r migration.Result) {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s failed: %s\n", done, total, r.OldWallet.Hex(), r.Error)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s migrated to %s in %s\n", done, total, r.OldWallet.Hex(), r.Wallet.Hex(), r.TxHash.Hex())
+		}
+		report, err = m.Run(ctx, steps)
+	}
+	if err != nil {
+		return err
+	}
+	err = enc.Encode(report)
+	if err != nil {
+		return err
+	}
+	if !report.Complete() {
+		return errors.Errorf("%d of %d wallets verified, run the migration again", report.Verified, report.Wallets)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/names"
+)
+
+// resolver returns the resolver of the names of the ens_registry contract,
+// the ENS registry of mainnet when it is not configured.
+func (e *env) resolver() (*names.Resolver, error) {
+	registry, ok := e.cfg.Contracts["ens_registry"]
+	if !ok {
+		registry = names.MainnetRegistry
+	}
+	return names.NewResolver(registry, e.backend)
+}
+
+// resolveAddress parses an address, or resolves an ENS name to its address
+// and shows it to check that it is the expected one.
+func (e *env) resolveAddress(ctx context.Context, s string) (common.Address, error) {
+	if !names.IsName(s) {
+		return parseAddress(s)
+	}
+	r, err := e.resolver()
+	if err != nil {
+		return common.Address{}, err
+	}
+	a, err := r.Resolve(ctx, s)
+	if errors.Cause(err) == names.ErrNotFound {
+		return common.Address{}, invalid(err)
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	fmt.Fprintf(os.Stderr, "%s resolves to %s\n", s, a.Hex())
+	return a, nil
+}
+
+// addressName returns the address followed by its primary ENS name, if it has
+// one.
+func (e *env) addressName(ctx context.Context, a common.Address) string {
+	r, err := e.resolver()
+	if err != nil {
+		return a.Hex()
+	}
+	name, err := r.Lookup(ctx, a)
+	if err != nil {
+		return a.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", a.Hex(), name)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/ownable"
+)
+
+// addressList is a flag set once per address.
+type addressList []common.Address
+
+func (l *addressList) String() string {
+	s := make([]string, len(*l))
+	for i, a := range *l {
+		s[i] = a.Hex()
+	}
+	return strings.Join(s, ",")
+}
+
+func (l *addressList) Set(s string) error {
+	a, err := parseAddress(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, a)
+	return nil
+}
+
+func runTransferOwnership(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("transfer-ownership", flag.ContinueOnError)
+	lock := fs.Bool("lock", false, "make the ownership no longer transferable, for good")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return invalid(errors.New("usage: transfer-ownership [-lock] <contract> <to>"))
+	}
+
+	name := fs.Arg(0)
+	to, err := e.resolveAddress(ctx, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	address, err := e.cfg.contract(name)
+	if err != nil {
+		return err
+	}
+	err = e.checkRecipient(to, "new owner")
+	if err != nil {
+		return err
+	}
+	contract, err := ownable.Bind(name, address, e.backend)
+	if err != nil {
+		return err
+	}
+
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := ownable.Transfer(opts, contract, to, !*lock)
+	if err != nil {
+		return err
+	}
+	_, err = e.wait(ctx, tx)
+	return err
+}
+
+func runAuditOwnership(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("audit-ownership", flag.ContinueOnError)
+	var owners addressList
+	fs.Var(&owners, "owner", "an expected owner, repeated for each owner, any owner when not set")
+	locked := fs.Bool("locked", false, "require the ownership to be no longer transferable")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return invalid(errors.New("usage: audit-ownership [-owner address]... [-locked]"))
+	}
+
+	entries := ownable.Audit(ctx, e.backend, e.cfg.Contracts, ownable.Policy{Owners: owners, Locked: *locked})
+	if len(entries) == 0 {
+		return errors.Errorf("none of the contracts %s is configured", strings.Join(ownable.Names(), ", "))
+	}
+	failed := 0
+	for _, entry := range entries {
+		status := "ok"
+		switch {
+		case entry.Err != "":
+			status = "error: " + entry.Err
+		case len(entry.Problems) > 0:
+			status = strings.Join(entry.Problems, ", ")
+		}
+		if !entry.OK() {
+			failed++
+		}
+		fmt.Printf("%-12s %s owner=%s transferable=%t %s\n", entry.Name, entry.Address.Hex(), entry.Owner.Hex(), entry.Transferable, status)
+	}
+	if failed > 0 {
+		return rejectedf("%d of %d contracts fail the ownership policy", failed, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/proof"
+)
+
+func runProofBundle(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("proof-bundle", flag.ContinueOnError)
+	payouts := fs.Bool("payouts", false, "bundle the transfers of the licence fees to the token holder between -from and -to")
+	from := fs.Uint64("from", 0, "first block of the payouts")
+	to := fs.Uint64("to", 0, "last block of the payouts, the current head when zero")
+	out := fs.String("out", "", "file the signed bundle is written to, stdout when empty")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *payouts == (fs.NArg() != 0) {
+		return invalid(errors.New("usage: proof-bundle [-out file] (-payouts [-from block] [-to block] | tx-hash...)"))
+	}
+
+	var txHashes []common.Hash
+	for _, arg := range fs.Args() {
+		b, err := hexutil.Decode(arg)
+		if err != nil || len(b) != common.HashLength {
+			return invalidf("%q is not a transaction hash", arg)
+		}
+		txHashes = append(txHashes, common.BytesToHash(b))
+	}
+	if *payouts {
+		txHashes, err = e.payouts(ctx, *from, *to)
+		if err != nil {
+			return err
+		}
+		if len(txHashes) == 0 {
+			return rejectedf("no payouts between blocks %d and %d", *from, *to)
+		}
+	}
+
+	b, err := proof.Gather(ctx, e.client, txHashes...)
+	if err != nil {
+		return err
+	}
+	for _, entry := range b.Entries {
+		if entry.ProofErr != "" {
+			fmt.Fprintf(os.Stderr, "no proof of transaction %s: %s\n", entry.TxHash.Hex(), entry.ProofErr)
+		}
+	}
+	err = b.Sign(e.signHash)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding bundle")
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(*out, data, 0644), "writing bundle")
+}
+
+// payouts returns the transactions of the TransferredToTokenHolder events of
+// the licence between the blocks, in order.
+func (e *env) payouts(ctx context.Context, from, to uint64) ([]common.Hash, error) {
+	address, err := e.cfg.contract("licence")
+	if err != nil {
+		return nil, err
+	}
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		Addresses: []common.Address{address},
+		Topics:    [][]common.Hash{{bindings.LicenceTransferredToTokenHolderTopic}},
+	}
+	if to != 0 {
+		query.ToBlock = new(big.Int).SetUint64(to)
+	}
+	logs, err := e.logs.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering payouts")
+	}
+	var txHashes []common.Hash
+	seen := make(map[common.Hash]bool)
+	for _, l := range logs {
+		if seen[l.TxHash] {
+			continue
+		}
+		seen[l.TxHash] = true
+		txHashes = append(txHashes, l.TxHash)
+	}
+	return txHashes, nil
+}
+
+func runVerifyProofBundle(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("verify-proof-bundle", flag.ContinueOnError)
+	signer := fs.String("signer", "", "address expected to have signed the bundle")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || !common.IsHexAddress(*signer) {
+		return invalid(errors.New("usage: verify-proof-bundle -signer address file"))
+	}
+
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return errors.Wrap(err, "reading bundle")
+	}
+	b := &proof.Bundle{}
+	err = json.Unmarshal(data, b)
+	if err != nil {
+		return invalid(errors.Wrapf(err, "decoding bundle %s", fs.Arg(0)))
+	}
+	err = b.Verify(common.HexToAddress(*signer))
+	if err != nil {
+		return rejectedf("%v", err)
+	}
+	// The blocks are checked with the node of rpc_url, which should be the
+	// node of the auditor.
+	err = b.CheckCanonical(ctx, e.client)
+	if err != nil {
+		return rejectedf("%v", err)
+	}
+	fmt.Printf("%d receipts verified in blocks of the chain\n", len(b.Entries))
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/ownable"
+)
+
+func runOwner(ctx context.Context, e *env, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return invalid(errors.New("usage: owner <contract> [address]"))
+	}
+	name := args[0]
+	if _, ok := ownable.Contracts[name]; !ok {
+		return errors.Errorf("contract %q is not ownable", name)
+	}
+
+	var address common.Address
+	var err error
+	if len(args) == 2 {
+		address, err = e.resolveAddress(ctx, args[1])
+	} else {
+		address, err = e.cfg.contract(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	contract, err := ownable.Bind(name, address, e.backend)
+	if err != nil {
+		return err
+	}
+	s, err := ownable.Inspect(&bind.CallOpts{Context: ctx}, contract)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("owner:        %s\n", e.addressName(ctx, s.Owner))
+	fmt.Printf("transferable: %t\n", s.Transferable)
+	return nil
+}
+
+func runRoles(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return invalid(errors.New("usage: roles <address>"))
+	}
+	account, err := e.resolveAddress(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	address, err := e.cfg.contract("controller")
+	if err != nil {
+		return err
+	}
+	controller, err := bindings.NewAccessClient(address, e.backend)
+	if err != nil {
+		return err
+	}
+	r, err := controller.Roles(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("owner:      %t\n", r.Owner)
+	fmt.Printf("admin:      %t\n", r.Admin)
+	fmt.Printf("controller: %t\n", r.Controller)
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/lookalike"
+)
+
+// recentCounterparties is how long the recipients are remembered for.
+const recentCounterparties = 90 * 24 * time.Hour
+
+// checkRecipient guards the addresses pasted by the operator against address
+// poisoning. When the recipient resembles a configured or recent address
+// without being it, the operator must type it in full to go on. The recipient
+// is then remembered under label.
+func (e *env) checkRecipient(to common.Address, label string) error {
+	book := lookalike.NewBook(e.cfg.AddressBookFile)
+	known, err := book.Recent(time.Now().Add(-recentCounterparties))
+	if err != nil {
+		return err
+	}
+	for name, a := range e.cfg.Contracts {
+		known = append(known, lookalike.Counterparty{Address: a, Label: "contract " + name})
+	}
+	if e.cfg.Account != (common.Address{}) {
+		known = append(known, lookalike.Counterparty{Address: e.cfg.Account, Label: "account"})
+	}
+	if e.cfg.Safe != (common.Address{}) {
+		known = append(known, lookalike.Counterparty{Address: e.cfg.Safe, Label: "safe"})
+	}
+
+	matches := lookalike.Check(to, known, e.cfg.LookalikeMinShared)
+	if len(matches) > 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: %s looks like a known address, it may be an address poisoning attempt:\n", to.Hex())
+		for _, m := range matches {
+			fmt.Fprintf(os.Stderr, "  %s (%s), same first %d and last %d characters\n", m.Address.Hex(), m.Label, m.Prefix, m.Suffix)
+		}
+		typed, err := prompt("Type the full address of the " + label + " to confirm it: ")()
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(typed, to.Hex()) {
+			return rejectedf("%s not confirmed", label)
+		}
+	}
+	return book.Add(lookalike.Counterparty{Address: to, Label: label, LastSeen: time.Now().UTC()})
+}
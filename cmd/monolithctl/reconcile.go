@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/reconcile"
+)
+
+func runReconcile(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "send the planned transactions instead of only printing them")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return invalid(errors.New("usage: reconcile [-apply] <spec.yaml>"))
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return errors.Wrap(err, "opening spec")
+	}
+	defer f.Close()
+	spec, err := reconcile.LoadSpec(f)
+	if err != nil {
+		return err
+	}
+
+	r, err := reconcile.New(e.backend, reconcile.Contracts{
+		Licence:        e.cfg.Contracts["licence"],
+		Controller:     e.cfg.Contracts["controller"],
+		TokenWhitelist: e.cfg.Contracts["token_whitelist"],
+	})
+	if err != nil {
+		return err
+	}
+
+	actions, err := r.Plan(&bind.CallOpts{Context: ctx}, spec)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		fmt.Println("contracts match the spec, nothing to do")
+		return nil
+	}
+	for i, a := range actions {
+		fmt.Printf("%d. %s\n", i+1, a)
+	}
+	if !*apply {
+		return nil
+	}
+
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range actions {
+		txs, err := r.Apply(opts, []reconcile.Action{a})
+		if err != nil {
+			return err
+		}
+		_, err = e.wait(ctx, txs[0])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
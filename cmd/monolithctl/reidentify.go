@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/pseudonym"
+)
+
+// runReidentify prints the addresses behind pseudonyms written by backfill
+// -pseudonym-key-env. The pseudonyms can not be reversed, they are looked up
+// among the pseudonyms of the addresses of the exports made without the key,
+// which the key holder keeps.
+func runReidentify(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reidentify", flag.ContinueOnError)
+	keyEnv := fs.String("key-env", "", "environment variable holding the hex encoded key of the pseudonyms")
+	from := fs.String("from", "", "comma separated exports of events as JSON lines, written without the key, holding the addresses")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *keyEnv == "" || *from == "" || fs.NArg() == 0 {
+		return invalid(errors.New("usage: reidentify -key-env name -from files <pseudonym>..."))
+	}
+	key, err := pseudonym.ParseKey(os.Getenv(*keyEnv))
+	if err != nil {
+		return invalid(errors.Wrap(err, *keyEnv))
+	}
+	var pseudonyms []common.Address
+	for _, p := range fs.Args() {
+		if !common.IsHexAddress(p) {
+			return invalidf("invalid pseudonym %q", p)
+		}
+		pseudonyms = append(pseudonyms, common.HexToAddress(p))
+	}
+
+	index := key.NewIndex()
+	for _, path := range strings.Split(*from, ",") {
+		err := indexExport(index, path)
+		if err != nil {
+			return err
+		}
+	}
+	missing := 0
+	for _, p := range pseudonyms {
+		a, ok := index.Reidentify(p)
+		if !ok {
+			missing++
+			continue
+		}
+		fmt.Printf("%s %s\n", p.Hex(), a.Hex())
+	}
+	if missing > 0 {
+		return errors.Errorf("%d pseudonyms are not among the addresses of the exports", missing)
+	}
+	return nil
+}
+
+// indexExport records the pseudonyms of the addresses of the arguments of the
+// events of an export.
+func indexExport(index *pseudonym.Index, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening export")
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		var line eventLine
+		err := json.Unmarshal(s.Bytes(), &line)
+		if err != nil {
+			return invalidf("%s line %d: %v", path, n, err)
+		}
+		index.AddValue(line.Args)
+	}
+	return errors.Wrapf(s.Err(), "reading %s", path)
+}
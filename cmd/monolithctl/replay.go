@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/backfill"
+	"github.com/tokencard/contracts/v2/pkg/failover"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logfilter"
+	"github.com/tokencard/contracts/v2/pkg/replay"
+)
+
+func runReplay(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	against := fs.String("against", "", "RPC URL of the provider compared with the configured one")
+	from := fs.Uint64("from", 0, "first block to compare, usually the deployment block of the contract")
+	to := fs.Uint64("to", 0, "last block to compare, the lowest head of the providers when zero")
+	confirmations := fs.Uint64("confirmations", 12, "blocks left out below the heads when -to is zero")
+	rangeSize := fs.Uint64("range", backfill.DefaultRangeSize, "number of blocks filtered at once")
+	events := fs.String("events", "", "comma separated names of the events to compare, all when empty")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 || *against == "" {
+		return invalid(errors.New("usage: replay -against url [-from block] [-to block] [-confirmations blocks] [-range blocks] [-events names] <contract> [contract ...]"))
+	}
+
+	var contracts []indexer.Contract
+	for _, name := range fs.Args() {
+		address, err := e.cfg.contract(name)
+		if err != nil {
+			return err
+		}
+		parsed, err := contractABI(name)
+		if err != nil {
+			return err
+		}
+		contracts = append(contracts, indexer.Contract{Name: name, Address: address, ABI: parsed})
+	}
+
+	client, err := ethclient.DialContext(ctx, *against)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to %s", failover.Name(*against))
+	}
+	defer client.Close()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "getting chain ID of %s", failover.Name(*against))
+	}
+	if chainID.Cmp(e.chainID) != 0 {
+		return invalidf("%s is on chain %s, the configured provider on chain %s", failover.Name(*against), chainID, e.chainID)
+	}
+
+	logger := log.New()
+	logger.SetHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+
+	opts := replay.Options{
+		StartBlock:    *from,
+		EndBlock:      *to,
+		Confirmations: *confirmations,
+		RangeSize:     *rangeSize,
+		Logger:        logger,
+	}
+	if *events != "" {
+		opts.Events = strings.Split(*events, ",")
+	}
+	a := replay.Source{Name: failover.Name(e.cfg.RPCURL), Backend: e.logs}
+	b := replay.Source{Name: failover.Name(*against), Backend: logfilter.NewBackend(client, client)}
+	if a.Name == b.Name {
+		// Two keys of the same provider, compared nonetheless.
+		a.Name, b.Name = "configured", "against"
+	}
+	report, err := replay.Check(ctx, a, b, opts, contracts...)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range report.Differences {
+		fmt.Println(d)
+	}
+	fmt.Printf("blocks %d to %d: %d events from %s, %d from %s, %d differences\n",
+		report.StartBlock, report.EndBlock, report.Events[a.Name], a.Name, report.Events[b.Name], b.Name, len(report.Differences))
+	if len(report.Differences) > 0 {
+		return errors.Errorf("the providers returned %d different events", len(report.Differences))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+	"github.com/tokencard/contracts/v2/pkg/rotation"
+)
+
+// runRotateController moves the controller role from an old key to a new
+// one, e.g. created by keys rotate, as the guided workflow of pkg/rotation.
+// The configured account must be an admin or the owner of the controller.
+func runRotateController(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("rotate-controller", flag.ContinueOnError)
+	monolithdConfig := fs.String("monolithd-config", "", "the monolithd configuration to switch to the new account, left untouched when empty")
+	token := fs.String("canary-token", "", "the token approved by the canary signed with the new key of the keystore, no canary when empty")
+	yes := fs.Bool("yes", false, "run the steps without asking for confirmation")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return invalid(errors.New("usage: rotate-controller [-monolithd-config file] [-canary-token address] [-yes] <old> <new>"))
+	}
+	old, err := parseAddress(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	next, err := parseAddress(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	err = e.checkRecipient(next, "new controller")
+	if err != nil {
+		return err
+	}
+	address, err := e.cfg.contract("controller")
+	if err != nil {
+		return err
+	}
+	controller, err := bindings.NewController(address, e.backend)
+	if err != nil {
+		return err
+	}
+	admin, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	r := &rotation.Controller{
+		Controller: controller,
+		Receipts:   e.client,
+		Admin:      admin,
+		Old:        old,
+		New:        next,
+		ConfigFile: *monolithdConfig,
+	}
+	if *token != "" {
+		tokenAddress, err := parseAddress(*token)
+		if err != nil {
+			return err
+		}
+		if e.cfg.KeystoreDir == "" {
+			return errors.New("the canary requires keystore_dir to be set in the configuration file")
+		}
+		opts, err := keys.Open(e.cfg.KeystoreDir).TransactOpts(next, os.Getenv(e.cfg.PasswordEnv), e.chainID)
+		if err != nil {
+			return err
+		}
+		r.Canary = &canary.Canary{Backend: e.backend, Receipts: e.client, Opts: opts, Token: tokenAddress}
+	}
+
+	steps := r.Steps()
+	fmt.Println("rotation steps:")
+	for i, s := range steps {
+		fmt.Printf("  %d. %s\n", i+1, s.Name)
+	}
+	var confirm func(rotation.Step) (bool, error)
+	if !*yes {
+		confirm = func(s rotation.Step) (bool, error) {
+			answer, err := prompt(fmt.Sprintf("%s? [y/N] ", s.Name))()
+			if err != nil {
+				return false, err
+			}
+			return strings.EqualFold(answer, "y"), nil
+		}
+	}
+	err = rotation.Run(ctx, steps, confirm, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s replaced %s as a controller, once monolithd runs with it run: keys retire %s\n", next.Hex(), old.Hex(), old.Hex())
+	return nil
+}
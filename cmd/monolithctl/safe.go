@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+	"github.com/tokencard/contracts/v2/pkg/safe"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+const safeUsage = `usage: safe propose [-out file] <contract> <method> [args...]
+       safe sign <file>
+       safe status <file>
+       safe exec <file>`
+
+// runSafe calls the owner-restricted methods of contracts owned by the
+// configured Safe. A proposal file is created, signed by enough owners, each
+// with their own configuration, then submitted by any account.
+func runSafe(ctx context.Context, e *env, args []string) error {
+	if e.cfg.Safe == (common.Address{}) {
+		return invalid(errors.New("safe is not set in the configuration file"))
+	}
+	if len(args) == 0 {
+		return invalid(errors.New(safeUsage))
+	}
+	s, err := safe.New(e.cfg.Safe, e.backend)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "propose":
+		return safePropose(ctx, e, s, args[1:])
+
+	case "sign":
+		if len(args) != 2 {
+			return invalid(errors.New(safeUsage))
+		}
+		p, err := safe.LoadProposal(args[1])
+		if err != nil {
+			return err
+		}
+		owner, err := p.Sign(e.signHash)
+		if err != nil {
+			return err
+		}
+		err = p.Save(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("signed by %s, %d signature(s)\n", owner.Hex(), len(p.Signatures))
+		return nil
+
+	case "status":
+		if len(args) != 2 {
+			return invalid(errors.New(safeUsage))
+		}
+		p, err := safe.LoadProposal(args[1])
+		if err != nil {
+			return err
+		}
+		threshold, err := s.Threshold(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("safe transaction %s, nonce %s\n", p.Transaction.Hash().Hex(), (*big.Int)(p.Transaction.Nonce))
+		fmt.Printf("call to %s: %s\n", p.Transaction.To.Hex(), p.Transaction.Data)
+		fmt.Printf("%d of %d signatures\n", len(p.Signatures), threshold)
+		for _, sig := range p.Signatures {
+			fmt.Printf("  %s\n", sig.Signer.Hex())
+		}
+		return nil
+
+	case "exec":
+		if len(args) != 2 {
+			return invalid(errors.New(safeUsage))
+		}
+		p, err := safe.LoadProposal(args[1])
+		if err != nil {
+			return err
+		}
+		opts, err := e.transactOpts(ctx)
+		if err != nil {
+			return err
+		}
+		tx, err := s.Exec(opts, p)
+		if err != nil {
+			return err
+		}
+		_, err = e.wait(ctx, tx)
+		return err
+
+	default:
+		return invalid(errors.New(safeUsage))
+	}
+}
+
+func safePropose(ctx context.Context, e *env, s *safe.Safe, args []string) error {
+	fs := flag.NewFlagSet("safe propose", flag.ContinueOnError)
+	out := fs.String("out", "", "proposal file, safe-<nonce>.json by default")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return invalid(errors.New(safeUsage))
+	}
+
+	name, methodName := fs.Arg(0), fs.Arg(1)
+	address, err := e.cfg.contract(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := contractABI(name)
+	if err != nil {
+		return err
+	}
+	method, ok := parsed.Methods[methodName]
+	if !ok || method.Const {
+		return invalidf("contract %q has no method %q sending transactions", name, methodName)
+	}
+	if len(fs.Args()[2:]) != len(method.Inputs) {
+		return invalidf("%s expects %d argument(s): %s", methodName, len(method.Inputs), method.Sig())
+	}
+	var values []interface{}
+	for i, input := range method.Inputs {
+		v, err := parseArg(input.Type, fs.Arg(2+i))
+		if err != nil {
+			return invalid(errors.Wrapf(err, "argument %s", input.Name))
+		}
+		if a, ok := v.(common.Address); ok {
+			err = e.checkRecipient(a, "argument "+input.Name+" of "+methodName)
+			if err != nil {
+				return err
+			}
+		}
+		values = append(values, v)
+	}
+	data, err := parsed.Pack(methodName, values...)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.Propose(ctx, e.chainID, address, data)
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("safe-%s.json", (*big.Int)(p.Transaction.Nonce))
+	}
+	err = p.Save(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("proposed safe transaction %s in %s\n", p.Transaction.Hash().Hex(), path)
+	return nil
+}
+
+// parseArg converts a command line argument to the Go value packing an ABI type.
+func parseArg(t abi.Type, s string) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		return parseAddress(s)
+	case abi.BoolTy:
+		return strconv.ParseBool(s)
+	case abi.StringTy:
+		return s, nil
+	case abi.BytesTy:
+		return hexutil.Decode(s)
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != t.Size {
+			return nil, errors.Errorf("%q is not %d bytes long", s, t.Size)
+		}
+		v := reflect.New(t.Type).Elem()
+		reflect.Copy(v, reflect.ValueOf(b))
+		return v.Interface(), nil
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, errors.Errorf("%q is not a valid integer", s)
+		}
+		if t.Type == reflect.TypeOf(n) {
+			return n, nil
+		}
+		v := reflect.New(t.Type).Elem()
+		if t.T == abi.UintTy {
+			if n.Sign() < 0 || !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+				return nil, errors.Errorf("%q overflows %s", s, t)
+			}
+			v.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || v.OverflowInt(n.Int64()) {
+				return nil, errors.Errorf("%q overflows %s", s, t)
+			}
+			v.SetInt(n.Int64())
+		}
+		return v.Interface(), nil
+	default:
+		return nil, errors.Errorf("arguments of type %s are not supported", t)
+	}
+}
+
+// signHash signs a hash, of a Safe transaction or an audit report, with the
+// configured keystore account. Hardware wallets cannot sign arbitrary hashes.
+func (e *env) signHash(hash []byte) ([]byte, error) {
+	switch {
+	case e.cfg.HardwareWallet.Kind != "":
+		return nil, errors.New("hardware wallets cannot sign arbitrary hashes")
+	case e.cfg.KeystoreDir != "":
+		return keys.Open(e.cfg.KeystoreDir).SignHash(e.cfg.Account, os.Getenv(e.cfg.PasswordEnv), hash)
+	case e.cfg.KeystoreFile != "":
+		key, err := signer.DecryptKeyFile(e.cfg.KeystoreFile, os.Getenv(e.cfg.PasswordEnv))
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Sign(hash, key)
+	default:
+		return nil, errors.New("neither keystore_dir nor keystore_file is set in the configuration file")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+)
+
+func runSnapshot(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	block := fs.Uint64("block", 0, "block of the snapshot, the current head when zero")
+	from := fs.Uint64("from", 0, "first block searched for the wallets, usually the deployment block of the wallet deployer")
+	format := fs.String("format", "json", "format of the snapshot, json or csv (the wallets only)")
+	out := fs.String("out", "", "file the snapshot is written to, stdout when empty")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || (*format != "json" && *format != "csv") {
+		return invalid(errors.New("usage: snapshot [-block block] [-from block] [-format json|csv] [-out file]"))
+	}
+
+	s, err := snapshot.Take(ctx, e.logs, snapshotContracts(e), snapshot.Options{StartBlock: *from, Block: *block})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return errors.Wrap(err, "creating output")
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "csv" {
+		return snapshot.WriteCSV(w, s)
+	}
+	return snapshot.WriteJSON(w, s)
+}
+
+// snapshotContracts returns the configured contracts of the snapshots.
+func snapshotContracts(e *env) snapshot.Contracts {
+	return snapshot.Contracts{
+		Licence:        e.cfg.Contracts["licence"],
+		TokenWhitelist: e.cfg.Contracts["token_whitelist"],
+		WalletCache:    e.cfg.Contracts["wallet_cache"],
+		WalletDeployer: e.cfg.Contracts["wallet_deployer"],
+	}
+}
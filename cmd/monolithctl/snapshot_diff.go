@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+)
+
+func runSnapshotDiff(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("snapshot-diff", flag.ContinueOnError)
+	from := fs.Uint64("from", 0, "first block searched for the wallets of the snapshots taken, usually the deployment block of the wallet deployer")
+	format := fs.String("format", "json", "format of the diff, json or csv")
+	out := fs.String("out", "", "file the diff is written to, stdout when empty")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 || (*format != "json" && *format != "csv") {
+		return invalid(errors.New("usage: snapshot-diff [-from block] [-format json|csv] [-out file] <block|snapshot file> <block|snapshot file>"))
+	}
+
+	var snapshots [2]*snapshot.Snapshot
+	for i, arg := range fs.Args() {
+		snapshots[i], err = loadSnapshot(ctx, e, arg, *from)
+		if err != nil {
+			return err
+		}
+	}
+	d, err := snapshot.Compare(snapshots[0], snapshots[1])
+	if err != nil {
+		return invalid(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return errors.Wrap(err, "creating output")
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "csv" {
+		return snapshot.WriteDiffCSV(w, d)
+	}
+	return snapshot.WriteDiffJSON(w, d)
+}
+
+// loadSnapshot takes the snapshot of the block arg, or reads it from the file
+// arg when it is not a number.
+func loadSnapshot(ctx context.Context, e *env, arg string, from uint64) (*snapshot.Snapshot, error) {
+	block, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return snapshot.ReadJSONFile(arg)
+	}
+	return snapshot.Take(ctx, e.logs, snapshotContracts(e), snapshot.Options{StartBlock: from, Block: block})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/safe"
+	"github.com/tokencard/contracts/v2/pkg/sweep"
+)
+
+// reserves is a flag set once per contract, as name=amount.
+type reserves map[string]*big.Int
+
+func (r reserves) String() string {
+	s := make([]string, 0, len(r))
+	for name, amount := range r {
+		s = append(s, name+"="+amount.String())
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func (r reserves) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return errors.Errorf("%q is not name=amount", s)
+	}
+	amount, err := parseAmount(s[i+1:])
+	if err != nil {
+		return err
+	}
+	r[s[:i]] = amount
+	return nil
+}
+
+// runSweep plans the sweeps of a token held by the claimable contracts to cold
+// storage, and writes a Safe proposal for each of them, to be signed offline
+// with safe sign and executed with safe exec. The Safe must be an admin of the
+// controller for the claims to succeed.
+func runSweep(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	token := fs.String("token", "", "address of the swept token")
+	min := fs.String("min", "0", "smallest amount worth a sweep, in base units")
+	dir := fs.String("out-dir", ".", "directory of the proposal files")
+	dryRun := fs.Bool("dry-run", false, "only print the planned sweeps")
+	r := reserves{}
+	fs.Var(r, "reserve", "amount left in a contract for its projected claims, as name=amount, repeatable")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || *token == "" {
+		return invalid(errors.New("usage: sweep -token address [-reserve name=amount]... [-min amount] [-out-dir dir] [-dry-run] <cold-storage> [contract...]"))
+	}
+	if e.cfg.Safe == (common.Address{}) && !*dryRun {
+		return invalid(errors.New("safe is not set in the configuration file"))
+	}
+
+	p := sweep.Planner{Reserves: r}
+	p.Token, err = parseAddress(*token)
+	if err != nil {
+		return errors.Wrap(err, "-token")
+	}
+	p.Minimum, err = parseAmount(*min)
+	if err != nil {
+		return errors.Wrap(err, "-min")
+	}
+	p.ColdStorage, err = e.resolveAddress(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	names := fs.Args()[1:]
+	if len(names) == 0 {
+		for name := range claimable {
+			if _, ok := e.cfg.Contracts[name]; ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+	var contracts []sweep.Contract
+	for _, name := range names {
+		if !claimable[name] {
+			return errors.Errorf("contract %q does not support claiming", name)
+		}
+		address, err := e.cfg.contract(name)
+		if err != nil {
+			return err
+		}
+		contracts = append(contracts, sweep.Contract{Name: name, Address: address})
+	}
+
+	err = e.checkRecipient(p.ColdStorage, "cold storage")
+	if err != nil {
+		return err
+	}
+	sweeps, err := p.Plan(ctx, e.backend, contracts...)
+	if err != nil {
+		return err
+	}
+	if len(sweeps) == 0 {
+		fmt.Println("nothing to sweep")
+		return nil
+	}
+	for _, s := range sweeps {
+		fmt.Printf("%s: balance %s, reserve %s, sweep %s\n", s.Contract, s.Balance, s.Reserve, s.Amount)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	sf, err := safe.New(e.cfg.Safe, e.backend)
+	if err != nil {
+		return err
+	}
+	for i, s := range sweeps {
+		prop, err := sf.Propose(ctx, e.chainID, s.Address, s.Data)
+		if err != nil {
+			return err
+		}
+		// The proposals are executed in order, each one at the next nonce.
+		nonce := new(big.Int).Add((*big.Int)(prop.Transaction.Nonce), big.NewInt(int64(i)))
+		prop.Transaction.Nonce = (*hexutil.Big)(nonce)
+		path := filepath.Join(*dir, fmt.Sprintf("safe-%s-sweep-%s.json", nonce, s.Contract))
+		err = prop.Save(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("proposed safe transaction %s in %s\n", prop.Transaction.Hash().Hex(), path)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+func runSetLicenceAmount(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return invalid(errors.New("usage: set-licence-amount <amount scaled by 1000>"))
+	}
+	amount, err := parseAmount(args[0])
+	if err != nil {
+		return err
+	}
+
+	address, err := e.cfg.contract("licence")
+	if err != nil {
+		return err
+	}
+	licence, err := bindings.NewLicence(address, e.backend)
+	if err != nil {
+		return err
+	}
+
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := licence.UpdateLicenceAmount(opts, amount)
+	if err != nil {
+		return err
+	}
+	_, err = e.wait(ctx, tx)
+	return err
+}
+
+// claimable are the contracts implementing claim(address _to, address _asset, uint _amount).
+var claimable = map[string]bool{
+	"controller":      true,
+	"licence":         true,
+	"oracle":          true,
+	"token_whitelist": true,
+}
+
+func runClaim(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("claim", flag.ContinueOnError)
+	asset := fs.String("asset", common.Address{}.Hex(), "address of the claimed token, the zero address for ETH")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return invalid(errors.New("usage: claim [-asset address] <contract> <to> <amount>"))
+	}
+
+	name := fs.Arg(0)
+	if !claimable[name] {
+		return errors.Errorf("contract %q does not support claiming", name)
+	}
+	to, err := e.resolveAddress(ctx, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	amount, err := parseAmount(fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	assetAddress, err := parseAddress(*asset)
+	if err != nil {
+		return errors.Wrap(err, "-asset")
+	}
+	err = e.checkRecipient(to, "claim recipient")
+	if err != nil {
+		return err
+	}
+
+	address, err := e.cfg.contract(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := contractABI(name)
+	if err != nil {
+		return err
+	}
+	contract := bind.NewBoundContract(address, parsed, e.backend, e.backend, e.backend)
+
+	opts, err := e.transactOpts(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := contract.Transact(opts, "claim", to, assetAddress, amount)
+	if err != nil {
+		return err
+	}
+	_, err = e.wait(ctx, tx)
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/analytics"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+func runLicenceWhatIf(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("licence-what-if", flag.ContinueOnError)
+	from := fs.Uint64("from", 0, "first block filtered for the loads, usually the deployment block of the licence")
+	store := fs.String("store", "", "event store of the licence events, e.g. written by backfill, instead of filtering the node")
+	months := fs.Int("months", 0, "months of loads the monthly volume is averaged over, 3 when zero")
+	horizon := fs.Int("horizon", 0, "months the fees are projected over, 12 when zero")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *months < 0 || *horizon < 0 {
+		return invalid(errors.New("usage: licence-what-if [-from block | -store file] [-months n] [-horizon n] <proposed amount scaled by 1000>"))
+	}
+	proposed, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+	if err != nil || proposed < analytics.MinLicenceAmount || proposed > analytics.MaxLicenceAmount {
+		return invalidf("%q is not a licence amount between %d and %d", fs.Arg(0), analytics.MinLicenceAmount, analytics.MaxLicenceAmount)
+	}
+
+	address, err := e.cfg.contract("licence")
+	if err != nil {
+		return err
+	}
+	licence, err := bindings.NewLicenceCaller(address, e.client)
+	if err != nil {
+		return err
+	}
+
+	var events indexer.Store
+	if *store != "" {
+		s, err := indexer.OpenFileStore(*store)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		events = s
+	} else {
+		events, err = e.licenceLoads(ctx, address, *from)
+		if err != nil {
+			return err
+		}
+	}
+
+	impact, err := analytics.LicenceWhatIf(ctx, events, analytics.NewNodeTimes(e.client), licence, proposed, analytics.WhatIfOptions{Months: *months, Horizon: *horizon})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(impact)
+}
+
+// licenceLoads returns a store of the events of the loads of the licence
+// mined since the block.
+func (e *env) licenceLoads(ctx context.Context, address common.Address, from uint64) (indexer.Store, error) {
+	parsed, err := contractABI("licence")
+	if err != nil {
+		return nil, err
+	}
+	head, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting latest block")
+	}
+	logs, err := e.logs.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   head.Number,
+		Addresses: []common.Address{address},
+		Topics:    [][]common.Hash{{bindings.LicenceTransferredToTokenHolderTopic, bindings.LicenceTransferredToCryptoFloatTopic}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering loads")
+	}
+	contract := indexer.Contract{Name: "licence", Address: address, ABI: parsed}
+	events := make([]indexer.Event, 0, len(logs))
+	for _, l := range logs {
+		ev, err := indexer.NewEvent(contract, l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	s := indexer.NewMemoryStore()
+	err = s.Append(head.Number.Uint64(), events)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
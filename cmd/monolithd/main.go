@@ -0,0 +1,61 @@
+// Command monolithd is the service layer exposing the contracts to clients that
+// cannot use the Go bindings directly.
+//
+// Usage:
+//
+//	monolithd -config monolithd.json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tokencard/contracts/v2/pkg/monolith"
+)
+
+func main() {
+	configPath := flag.String("config", "monolithd.json", "path to the configuration file")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	err := run(ctx, *configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, configPath string) error {
+	cfg, err := monolith.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	m := monolith.New(cfg)
+	err = m.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	reloader := newReloader(configPath, m)
+	go func() {
+		err := reloader.run(ctx, cfg.WatchConfig)
+		if err != nil && err != context.Canceled {
+			log.Printf("configuration reload disabled: %v", err)
+		}
+	}()
+
+	return m.Serve(ctx)
+}
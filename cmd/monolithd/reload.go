@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/monolith"
+	"gopkg.in/fsnotify.v1"
+)
+
+// reloadDebounce groups the file events of a single configuration update,
+// Kubernetes swaps the whole ConfigMap directory at once.
+const reloadDebounce = 500 * time.Millisecond
+
+// reloader re-reads the configuration file on SIGHUP, or when the files next
+// to it change, and reloads the monolith with it. The changes which are not
+// reloaded are logged and ignored until the next restart.
+type reloader struct {
+	path     string
+	monolith *monolith.Monolith
+	logger   *log.Logger
+}
+
+func newReloader(path string, m *monolith.Monolith) *reloader {
+	return &reloader{
+		path:     path,
+		monolith: m,
+		logger:   log.New(os.Stderr, "reload: ", log.LstdFlags),
+	}
+}
+
+// run reloads the configuration until the context is cancelled.
+func (r *reloader) run(ctx context.Context, watch bool) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var changes <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if watch {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			return errors.Wrap(err, "creating configuration watcher")
+		}
+		defer w.Close()
+		err = w.Add(filepath.Dir(r.path))
+		if err != nil {
+			return errors.Wrap(err, "watching configuration directory")
+		}
+		changes, watchErrors = w.Events, w.Errors
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-hup:
+			r.reload()
+		case <-changes:
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			r.reload()
+		case err := <-watchErrors:
+			r.logger.Printf("watching configuration: %v", err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *reloader) reload() {
+	cfg, err := monolith.LoadConfig(r.path)
+	if err != nil {
+		r.logger.Printf("keeping the current configuration: %v", err)
+		return
+	}
+
+	ignored, err := r.monolith.Reload(cfg)
+	for _, setting := range ignored {
+		r.logger.Printf("changing %s requires a restart, ignoring the change", setting)
+	}
+	if err != nil {
+		r.logger.Printf("%v", err)
+	}
+	r.logger.Printf("configuration reloaded from %s", r.path)
+}
@@ -0,0 +1,128 @@
+// Package access restricts who can connect to the listening services: CIDR
+// allowlists filter the connections by source address before any byte is
+// read, and mutual TLS authenticates both the server and its clients with
+// certificates.
+package access
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Allowlist accepts the connections from a set of networks. An empty
+// allowlist accepts every connection.
+type Allowlist struct {
+	mu   sync.RWMutex
+	nets []*net.IPNet
+}
+
+// NewAllowlist creates an allowlist of CIDR blocks, e.g. "10.0.0.0/8". Single
+// addresses are accepted as the block of that address only.
+func NewAllowlist(cidrs ...string) (*Allowlist, error) {
+	a := &Allowlist{}
+	err := a.Set(cidrs...)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Set replaces the allowed networks. Established connections are not closed.
+func (a *Allowlist) Set(cidrs ...string) error {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nets = nets
+	return nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if !strings.Contains(c, "/") {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, errors.Errorf("%q is neither a CIDR block nor an IP address", c)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %q", c)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Allows reports whether ip belongs to one of the allowed networks.
+func (a *Allowlist) Allows(ip net.IP) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.nets) == 0 {
+		return true
+	}
+	for _, n := range a.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAddr reports whether the IP of a TCP address is allowed.
+func (a *Allowlist) AllowsAddr(addr net.Addr) bool {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		return a.Allows(addr.IP)
+	case *net.UDPAddr:
+		return a.Allows(addr.IP)
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && a.Allows(ip)
+}
+
+// Listener returns a listener closing the connections from addresses which are
+// not allowed as soon as they are accepted. rejected, when not nil, is called
+// with the address of each rejected connection.
+func (a *Allowlist) Listener(l net.Listener, rejected func(addr net.Addr)) net.Listener {
+	return &listener{Listener: l, allowlist: a, rejected: rejected}
+}
+
+type listener struct {
+	net.Listener
+	allowlist *Allowlist
+	rejected  func(addr net.Addr)
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.allowlist.AllowsAddr(conn.RemoteAddr()) {
+			return conn, nil
+		}
+		if l.rejected != nil {
+			l.rejected(conn.RemoteAddr())
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,208 @@
+// Package airdrop assigns wallets to the owners of a recipient list, read
+// from a CSV or JSON file, with the WalletDeployer:
+//
+//	recipients, err := airdrop.ReadRecipientsFile("owners.csv")
+//	...
+//	a := airdrop.New(provisioner, "owners.journal.jsonl")
+//	err = a.CheckSupply(ctx, recipients)
+//	...
+//	report, err := a.Run(ctx, recipients)
+//
+// The outcome of each recipient is appended to a journal, running the
+// airdrop again resumes it: the recipients assigned a wallet are skipped,
+// the failed ones retried. The report reconciles the journal with the
+// wallets deployed on the chain.
+package airdrop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+)
+
+// ErrSupply is the cause of the error of CheckSupply when the cache does not
+// hold a wallet for each recipient.
+var ErrSupply = errors.New("not enough cached wallets")
+
+// Result is the outcome of a recipient, a line of the journal.
+type Result struct {
+	Owner  common.Address `json:"owner"`
+	Wallet common.Address `json:"wallet,omitempty"`
+	TxHash common.Hash    `json:"tx_hash,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Airdrop assigns the wallets of a recipient list.
+type Airdrop struct {
+	provisioner *provision.Provisioner
+	journal     string
+
+	// Progress is called after each recipient with the number of
+	// recipients done out of the total, the skipped ones included.
+	Progress func(done, total int, r Result)
+}
+
+// New returns an airdrop assigning the wallets with p and journaling the
+// outcomes in the journal file, created when missing.
+func New(p *provision.Provisioner, journal string) *Airdrop {
+	return &Airdrop{provisioner: p, journal: journal}
+}
+
+// done returns the recipients the journal records as assigned.
+func (a *Airdrop) done() (map[common.Address]Result, error) {
+	done := make(map[common.Address]Result)
+	f, err := os.Open(a.journal)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal")
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		var r Result
+		err := json.Unmarshal(s.Bytes(), &r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "journal line %d", n)
+		}
+		if r.Error == "" {
+			done[r.Owner] = r
+		}
+	}
+	return done, errors.Wrap(s.Err(), "reading journal")
+}
+
+// pending returns the recipients not assigned yet.
+func (a *Airdrop) pending(recipients []common.Address) ([]common.Address, map[common.Address]Result, error) {
+	done, err := a.done()
+	if err != nil {
+		return nil, nil, err
+	}
+	var pending []common.Address
+	for _, r := range recipients {
+		if _, ok := done[r]; !ok {
+			pending = append(pending, r)
+		}
+	}
+	return pending, done, nil
+}
+
+// CheckSupply checks that the cache holds a wallet for each recipient not
+// assigned yet. The recipients beyond the cached wallets would get a wallet
+// deployed by their assignment, at a much higher gas cost.
+func (a *Airdrop) CheckSupply(ctx context.Context, recipients []common.Address) error {
+	pending, _, err := a.pending(recipients)
+	if err != nil {
+		return err
+	}
+	cached, err := a.provisioner.Cached(ctx)
+	if err != nil {
+		return err
+	}
+	if cached < len(pending) {
+		return errors.Wrapf(ErrSupply, "%d cached for %d recipients", cached, len(pending))
+	}
+	return nil
+}
+
+// Run assigns a wallet to each recipient not assigned one by a previous run,
+// journaling the outcomes, and returns the report of the airdrop. A failed
+// recipient does not stop the airdrop, it is retried by the next run.
+func (a *Airdrop) Run(ctx context.Context, recipients []common.Address) (*Report, error) {
+	pending, done, err := a.pending(recipients)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(a.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal")
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+
+	count := len(recipients) - len(pending)
+	for _, owner := range pending {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		r := Result{Owner: owner}
+		assignment, err := a.provisioner.AssignWallet(ctx, owner)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Wallet, r.TxHash = assignment.Wallet, assignment.TxHash
+			done[owner] = r
+		}
+		err = enc.Encode(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "writing journal")
+		}
+		count++
+		if a.Progress != nil {
+			a.Progress(count, len(recipients), r)
+		}
+	}
+	return a.reconcile(ctx, recipients, done)
+}
+
+// Report reconciles the journal of an airdrop with the chain.
+type Report struct {
+	Recipients int `json:"recipients"`
+	// Assigned is the number of recipients whose journaled wallet is the
+	// one deployed for them.
+	Assigned int `json:"assigned"`
+	// Missing are the recipients without a wallet, to airdrop again.
+	Missing []common.Address `json:"missing,omitempty"`
+	// Mismatched are the recipients whose wallet on the chain is not the
+	// journaled one.
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+// Mismatch is a recipient whose journaled wallet is not its wallet on the
+// chain.
+type Mismatch struct {
+	Owner     common.Address `json:"owner"`
+	Journaled common.Address `json:"journaled"`
+	Deployed  common.Address `json:"deployed"`
+}
+
+// Complete tells whether every recipient has its journaled wallet.
+func (r *Report) Complete() bool {
+	return r.Assigned == r.Recipients
+}
+
+// Reconcile returns the report of the airdrop of the recipients, from its
+// journal and the chain, without assigning any wallet.
+func (a *Airdrop) Reconcile(ctx context.Context, recipients []common.Address) (*Report, error) {
+	done, err := a.done()
+	if err != nil {
+		return nil, err
+	}
+	return a.reconcile(ctx, recipients, done)
+}
+
+func (a *Airdrop) reconcile(ctx context.Context, recipients []common.Address, done map[common.Address]Result) (*Report, error) {
+	report := &Report{Recipients: len(recipients)}
+	for _, owner := range recipients {
+		deployed, err := a.provisioner.DeployedWallet(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := done[owner]
+		switch {
+		case !ok && deployed == (common.Address{}):
+			report.Missing = append(report.Missing, owner)
+		case !ok || r.Wallet != deployed:
+			report.Mismatched = append(report.Mismatched, Mismatch{Owner: owner, Journaled: r.Wallet, Deployed: deployed})
+		default:
+			report.Assigned++
+		}
+	}
+	return report, nil
+}
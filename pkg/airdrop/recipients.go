@@ -0,0 +1,134 @@
+package airdrop
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Formats of the recipient lists.
+const (
+	// CSV lists an address in the first column of each row, after an
+	// optional header row whose first column is not an address.
+	CSV = "csv"
+	// JSON lists the addresses in an array, of strings or of objects with
+	// an address field.
+	JSON = "json"
+)
+
+// ReadRecipientsFile reads the recipients of a file whose format is told by
+// its extension, .csv or .json.
+func ReadRecipientsFile(path string) ([]common.Address, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening recipients")
+	}
+	defer f.Close()
+	recipients, err := ReadRecipients(f, format)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return recipients, nil
+}
+
+// ReadRecipients reads a list of recipients in the given format. The list is
+// rejected as a whole when an address is invalid, has a wrong checksum, is
+// the zero address or is listed twice, so that a typo does not drop a
+// recipient or send it twice.
+func ReadRecipients(r io.Reader, format string) ([]common.Address, error) {
+	var entries []string
+	switch format {
+	case CSV:
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CSV")
+		}
+		for i, row := range rows {
+			if i == 0 && len(row) > 0 && !strings.HasPrefix(strings.TrimSpace(row[0]), "0x") {
+				// The header.
+				entries = append(entries, "")
+				continue
+			}
+			if len(row) == 0 {
+				return nil, errors.Errorf("line %d: no address", i+1)
+			}
+			entries = append(entries, row[0])
+		}
+	case JSON:
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading JSON")
+		}
+		var raw []json.RawMessage
+		err = json.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding JSON")
+		}
+		for i, m := range raw {
+			var s string
+			if bytes.HasPrefix(bytes.TrimSpace(m), []byte("{")) {
+				var o struct {
+					Address string `json:"address"`
+				}
+				err = json.Unmarshal(m, &o)
+				s = o.Address
+			} else {
+				err = json.Unmarshal(m, &s)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "entry %d", i+1)
+			}
+			entries = append(entries, s)
+		}
+	default:
+		return nil, errors.Errorf("unknown recipient format %q, expected %s or %s", format, CSV, JSON)
+	}
+
+	unit := "line"
+	if format == JSON {
+		unit = "entry"
+	}
+	var recipients []common.Address
+	seen := make(map[common.Address]int)
+	for i, s := range entries {
+		if i == 0 && s == "" && format == CSV {
+			continue
+		}
+		a, err := parseRecipient(strings.TrimSpace(s))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %d", unit, i+1)
+		}
+		if first, ok := seen[a]; ok {
+			return nil, errors.Errorf("%s %d: %s already listed on %s %d", unit, i+1, a.Hex(), unit, first)
+		}
+		seen[a] = i + 1
+		recipients = append(recipients, a)
+	}
+	return recipients, nil
+}
+
+// parseRecipient parses an address, checking its checksum when it has one:
+// an address in mixed case must be in its checksum case.
+func parseRecipient(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, errors.Errorf("%q is not an address", s)
+	}
+	a := common.HexToAddress(s)
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) && "0x"+hexPart != a.Hex() {
+		return common.Address{}, errors.Errorf("%s has an invalid checksum", s)
+	}
+	if a == (common.Address{}) {
+		return common.Address{}, errors.New("the zero address can not be a recipient")
+	}
+	return a, nil
+}
@@ -0,0 +1,271 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// DefaultInterval is the evaluation interval of rules without one.
+const DefaultInterval = time.Minute
+
+// State is the state of an alert.
+type State string
+
+// States of the alerts.
+const (
+	Firing   State = "firing"
+	Resolved State = "resolved"
+)
+
+// Alert is raised by a rule.
+type Alert struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Summary  string   `json:"summary,omitempty"`
+	State    State    `json:"state"`
+	// Value is the compared value of a metric rule.
+	Value float64 `json:"value"`
+	// Event is the event matched by an event rule.
+	Event *indexer.Event `json:"event,omitempty"`
+	Time  time.Time      `json:"time"`
+}
+
+// Resolved reports whether the alert ends a previously firing alert, or
+// retracts the alert of an event removed by a reorganization.
+func (a Alert) Resolved() bool {
+	return a.State == Resolved
+}
+
+// Engine evaluates the metric rules every interval and the event rules for
+// the events it handles. It implements indexer.Handler so that it can be
+// attached to an indexer.
+type Engine struct {
+	registry metrics.Registry
+	logger   logging.Logger
+
+	mu        sync.Mutex
+	rules     *Rules
+	notifiers map[string]Notifier
+	states    map[string]*ruleState
+}
+
+type ruleState struct {
+	last    float64
+	holding bool
+	since   time.Time
+	firing  bool
+}
+
+// NewEngine creates an engine evaluating the metric rules over registry and
+// sending the alerts to the notifiers of the receivers. The registry can be
+// nil when there are no metric rules.
+func NewEngine(rules *Rules, notifiers map[string]Notifier, registry metrics.Registry, logger logging.Logger) *Engine {
+	return &Engine{
+		registry:  registry,
+		logger:    logging.Or(logger),
+		rules:     rules,
+		notifiers: notifiers,
+		states:    make(map[string]*ruleState),
+	}
+}
+
+// SetRules replaces the rules and notifiers while the engine is running. The
+// state of the metric rules kept under the same name is preserved.
+func (e *Engine) SetRules(rules *Rules, notifiers map[string]Notifier) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = rules
+	e.notifiers = notifiers
+	for name := range e.states {
+		if !hasRule(rules, name) {
+			delete(e.states, name)
+		}
+	}
+}
+
+// Run evaluates the metric rules every interval until the context is cancelled.
+func (e *Engine) Run(ctx context.Context) error {
+	for {
+		e.Evaluate(ctx, time.Now())
+		select {
+		case <-time.After(e.interval()):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *Engine) interval() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rules.Interval > 0 {
+		return time.Duration(e.rules.Interval)
+	}
+	return DefaultInterval
+}
+
+// Evaluate evaluates the metric rules once at the given time and sends the
+// alerts whose state changed. Failed notifications are logged.
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	var alerts []Alert
+	for _, rule := range e.rules.Rules {
+		if rule.Event != nil || e.registry == nil {
+			continue
+		}
+		a, ok := e.evaluate(rule, now)
+		if ok {
+			alerts = append(alerts, a)
+		}
+	}
+	routes, notifiers := e.rules.Routes, e.notifiers
+	e.mu.Unlock()
+
+	for _, a := range alerts {
+		e.notify(ctx, routes, notifiers, a)
+	}
+}
+
+// evaluate updates the state of a metric rule, returning an alert when it
+// starts or stops firing.
+func (e *Engine) evaluate(rule Rule, now time.Time) (Alert, bool) {
+	st, ok := e.states[rule.Name]
+	if !ok {
+		st = &ruleState{}
+		e.states[rule.Name] = st
+	}
+
+	v := metricValue(e.registry, rule.Metric, rule.Field)
+	if rule.Increase {
+		v, st.last = v-st.last, v
+	}
+
+	a := Alert{Rule: rule.Name, Severity: rule.Severity, Summary: rule.Summary, Value: v, Time: now}
+	if comparisons[rule.Op](v, rule.Threshold) {
+		if !st.holding {
+			st.holding = true
+			st.since = now
+		}
+		if st.firing || now.Sub(st.since) < time.Duration(rule.For) {
+			return Alert{}, false
+		}
+		st.firing = true
+		a.State = Firing
+		return a, true
+	}
+
+	st.holding = false
+	if !st.firing {
+		return Alert{}, false
+	}
+	st.firing = false
+	a.State = Resolved
+	return a, true
+}
+
+// HandleEvents implements indexer.Handler, raising an alert for every event
+// matched by an event rule. The alert of an event removed by a
+// reorganization is resolved.
+func (e *Engine) HandleEvents(ctx context.Context, events []indexer.Event) error {
+	e.mu.Lock()
+	var alerts []Alert
+	for _, rule := range e.rules.Rules {
+		if rule.Event == nil {
+			continue
+		}
+		q := indexer.Query{Contract: rule.Event.Contract, Name: rule.Event.Name, Args: rule.Event.Args}
+		for _, ev := range events {
+			if !q.Matches(ev) {
+				continue
+			}
+			state := Firing
+			if ev.Removed {
+				state = Resolved
+			}
+			alerts = append(alerts, Alert{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Summary:  rule.Summary,
+				State:    state,
+				Event:    formatEvent(ev),
+				Time:     time.Now(),
+			})
+		}
+	}
+	routes, notifiers := e.rules.Routes, e.notifiers
+	e.mu.Unlock()
+
+	for _, a := range alerts {
+		e.notify(ctx, routes, notifiers, a)
+	}
+	return nil
+}
+
+// Send routes an alert raised outside of the rules to the receivers. It fails
+// when no receiver is routed the alert or when a notification fails.
+func (e *Engine) Send(ctx context.Context, a Alert) error {
+	e.mu.Lock()
+	routes, notifiers := e.rules.Routes, e.notifiers
+	e.mu.Unlock()
+
+	sent, err := e.notify(ctx, routes, notifiers, a)
+	if err != nil {
+		return err
+	}
+	if sent == 0 {
+		return errors.Errorf("no receiver for %s alert %s", a.Severity, a.Rule)
+	}
+	return nil
+}
+
+// notify sends an alert to the receivers of the matching routes, returning
+// the number of notifications sent and the first failure.
+func (e *Engine) notify(ctx context.Context, routes []Route, notifiers map[string]Notifier, a Alert) (int, error) {
+	var sent int
+	var failed error
+	for _, r := range routes {
+		if !r.matches(a) {
+			continue
+		}
+		n, ok := notifiers[r.Receiver]
+		if !ok {
+			continue
+		}
+		err := n.Notify(ctx, a)
+		if err != nil {
+			e.logger.Error("Sending alert failed", "rule", a.Rule, "receiver", r.Receiver, "err", err)
+			if failed == nil {
+				failed = errors.Wrapf(err, "sending alert to %s", r.Receiver)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, failed
+}
+
+// formatEvent returns a copy of the event with its arguments formatted by
+// indexer.FormatArg.
+func formatEvent(ev indexer.Event) *indexer.Event {
+	args := make(map[string]interface{}, len(ev.Args))
+	for k, v := range ev.Args {
+		args[k] = indexer.FormatArg(v)
+	}
+	ev.Args = args
+	return &ev
+}
+
+func hasRule(rules *Rules, name string) bool {
+	for _, r := range rules.Rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
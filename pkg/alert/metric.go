@@ -0,0 +1,82 @@
+package alert
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// comparisons are the ops of the metric rules.
+var comparisons = map[string]func(v, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	"==": func(v, t float64) bool { return v == t },
+	"!=": func(v, t float64) bool { return v != t },
+}
+
+// fields are the values of the histograms, meters and timers rules compare.
+var fields = map[string]bool{
+	"count": true,
+	"mean":  true,
+	"min":   true,
+	"max":   true,
+	"p50":   true,
+	"p95":   true,
+	"p99":   true,
+	"rate1": true,
+}
+
+var percentiles = map[string]float64{
+	"p50": 0.5,
+	"p95": 0.95,
+	"p99": 0.99,
+}
+
+// metricValue returns the value of the named metric. Metrics are registered
+// when first updated, a missing metric has a zero value.
+func metricValue(r metrics.Registry, name, field string) float64 {
+	switch m := r.Get(name).(type) {
+	case metrics.Counter:
+		return float64(m.Count())
+	case metrics.Gauge:
+		return float64(m.Value())
+	case metrics.GaugeFloat64:
+		return m.Value()
+	case metrics.Meter:
+		if field == "rate1" {
+			return m.Rate1()
+		}
+		return float64(m.Count())
+	case metrics.Histogram:
+		return sampleValue(m.Snapshot(), field)
+	case metrics.Timer:
+		if field == "rate1" {
+			return m.Rate1()
+		}
+		return sampleValue(m.Snapshot(), field)
+	}
+	return 0
+}
+
+// sample is the part of histograms and timers the rules can compare.
+type sample interface {
+	Count() int64
+	Mean() float64
+	Min() int64
+	Max() int64
+	Percentile(float64) float64
+}
+
+func sampleValue(s sample, field string) float64 {
+	switch field {
+	case "mean":
+		return s.Mean()
+	case "min":
+		return float64(s.Min())
+	case "max":
+		return float64(s.Max())
+	case "p50", "p95", "p99":
+		return s.Percentile(percentiles[field])
+	}
+	return float64(s.Count())
+}
@@ -0,0 +1,127 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/webhook"
+)
+
+// Notifier delivers alerts to a receiver.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, a Alert) error
+
+// Notify implements Notifier.
+func (f NotifierFunc) Notify(ctx context.Context, a Alert) error {
+	return f(ctx, a)
+}
+
+// LogNotifier writes alerts to a logger, critical alerts at the error level.
+type LogNotifier struct {
+	Logger logging.Logger
+}
+
+// Notify implements Notifier.
+func (l LogNotifier) Notify(ctx context.Context, a Alert) error {
+	keyvals := []interface{}{"rule", a.Rule, "severity", a.Severity, "summary", a.Summary}
+	if a.Event != nil {
+		keyvals = append(keyvals, "contract", a.Event.Contract, "event", a.Event.Name, "tx", a.Event.TxHash)
+	} else {
+		keyvals = append(keyvals, "value", a.Value)
+	}
+	switch {
+	case a.Resolved():
+		l.Logger.Info("Alert resolved", keyvals...)
+	case a.Severity == Critical:
+		l.Logger.Error("Alert firing", keyvals...)
+	case a.Severity == Warning:
+		l.Logger.Warn("Alert firing", keyvals...)
+	default:
+		l.Logger.Info("Alert firing", keyvals...)
+	}
+	return nil
+}
+
+// WebhookNotifier posts alerts as JSON, signed like the event deliveries of
+// the webhook package.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// Notify implements Notifier.
+func (w WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return errors.Wrap(err, "encoding alert")
+	}
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(w.Secret, timestamp, body))
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "posting alert")
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// Notifiers creates the notifiers of the receivers of the rules, reading the
+// webhook secrets with getenv.
+func (r *Rules) Notifiers(logger logging.Logger, getenv func(string) string) map[string]Notifier {
+	notifiers := make(map[string]Notifier, len(r.Receivers))
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, rc := range r.Receivers {
+		var n []Notifier
+		if rc.Log {
+			n = append(n, LogNotifier{Logger: logging.Or(logger)})
+		}
+		if rc.Webhook != nil {
+			n = append(n, WebhookNotifier{URL: rc.Webhook.URL, Secret: getenv(rc.Webhook.SecretEnv), Client: client})
+		}
+		notifiers[rc.Name] = multiNotifier(n)
+	}
+	return notifiers
+}
+
+// multiNotifier notifies each of its notifiers, returning the first error.
+type multiNotifier []Notifier
+
+func (m multiNotifier) Notify(ctx context.Context, a Alert) error {
+	var first error
+	for _, n := range m {
+		err := n.Notify(ctx, a)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,257 @@
+// Package alert evaluates declarative alert rules over the metrics of the
+// telemetry package and the contract events of the indexer, and routes the
+// alerts to receivers by severity.
+//
+// Metric rules compare a metric with a threshold every evaluation interval,
+// they fire once the condition held for the duration of the rule and are
+// resolved when it no longer holds. Event rules fire for every indexed event
+// they match.
+package alert
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	"gopkg.in/yaml.v2"
+)
+
+// Severity is the importance of an alert.
+type Severity string
+
+// Severities of the alerts.
+const (
+	Info     Severity = "info"
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Rules is the YAML rules file:
+//
+//	interval: 30s
+//	receivers:
+//	- name: oncall
+//	  webhook: {url: "https://pager/hooks", secret_env: PAGER_SECRET}
+//	- name: log
+//	  log: true
+//	routes:
+//	- receiver: oncall
+//	  severities: [critical]
+//	- receiver: log
+//	rules:
+//	- name: licence-dao-changed
+//	  severity: critical
+//	  summary: the licence DAO was changed
+//	  event: {contract: licence, name: UpdatedLicenceDAO}
+//	- name: transactions-reverted
+//	  severity: warning
+//	  metric: tx/licence/updateLicenceAmount/reverted
+//	  increase: true
+//	  op: ">"
+//	  threshold: 0
+//	- name: slow-rpc
+//	  severity: warning
+//	  metric: rpc/call
+//	  field: p95
+//	  op: ">"
+//	  threshold: 2e9
+//	  for: 5m
+//
+// Alerts are sent to the receivers of every route they match, a route without
+// severities or rules matches every alert.
+type Rules struct {
+	Interval  txmgr.Duration `yaml:"interval"`
+	Receivers []Receiver     `yaml:"receivers"`
+	Routes    []Route        `yaml:"routes"`
+	Rules     []Rule         `yaml:"rules"`
+}
+
+// Receiver is a destination of alerts.
+type Receiver struct {
+	Name string `yaml:"name"`
+	// Log writes the alerts to the logger of the engine.
+	Log     bool `yaml:"log"`
+	Webhook *struct {
+		URL string `yaml:"url"`
+		// SecretEnv names the environment variable holding the signing secret.
+		SecretEnv string `yaml:"secret_env"`
+	} `yaml:"webhook"`
+}
+
+// Route sends the matching alerts to a receiver.
+type Route struct {
+	Receiver   string     `yaml:"receiver"`
+	Severities []Severity `yaml:"severities"`
+	Rules      []string   `yaml:"rules"`
+}
+
+func (r Route) matches(a Alert) bool {
+	if len(r.Severities) > 0 && !containsSeverity(r.Severities, a.Severity) {
+		return false
+	}
+	if len(r.Rules) > 0 && !containsString(r.Rules, a.Rule) {
+		return false
+	}
+	return true
+}
+
+// Rule is an alert rule, either over a metric or over contract events.
+type Rule struct {
+	Name     string   `yaml:"name"`
+	Severity Severity `yaml:"severity"`
+	Summary  string   `yaml:"summary"`
+
+	// Metric is the name of the metric in the registry.
+	Metric string `yaml:"metric"`
+	// Field is the value compared for histograms, meters and timers: count,
+	// mean, min, max, p50, p95, p99 or rate1. Counters and gauges are compared
+	// by value.
+	Field string `yaml:"field"`
+	// Increase compares the change of the value since the previous evaluation.
+	Increase  bool    `yaml:"increase"`
+	Op        string  `yaml:"op"`
+	Threshold float64 `yaml:"threshold"`
+	// For is how long the condition must hold before the rule fires.
+	For txmgr.Duration `yaml:"for"`
+
+	Event *EventMatch `yaml:"event"`
+}
+
+// EventMatch selects indexed events by contract, name and arguments. Empty
+// fields match any event.
+type EventMatch struct {
+	Contract string            `yaml:"contract"`
+	Name     string            `yaml:"name"`
+	Args     map[string]string `yaml:"args"`
+}
+
+// LoadRules reads rules from YAML.
+func LoadRules(r io.Reader) (*Rules, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading rules")
+	}
+	rules := &Rules{}
+	err = yaml.UnmarshalStrict(b, rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding rules")
+	}
+	err = rules.validate()
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadRulesFile reads rules from a YAML file.
+func LoadRulesFile(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening rules file")
+	}
+	defer f.Close()
+	return LoadRules(f)
+}
+
+// MetricRules reports whether any rule is over a metric.
+func (r *Rules) MetricRules() bool {
+	for _, rule := range r.Rules {
+		if rule.Event == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// EventRules reports whether any rule is over contract events.
+func (r *Rules) EventRules() bool {
+	for _, rule := range r.Rules {
+		if rule.Event != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rules) validate() error {
+	receivers := make(map[string]bool)
+	for _, rc := range r.Receivers {
+		if rc.Name == "" {
+			return errors.New("receiver without a name")
+		}
+		if receivers[rc.Name] {
+			return errors.Errorf("duplicate receiver %q", rc.Name)
+		}
+		if !rc.Log && rc.Webhook == nil {
+			return errors.Errorf("receiver %q has neither log nor webhook set", rc.Name)
+		}
+		if rc.Webhook != nil && rc.Webhook.URL == "" {
+			return errors.Errorf("webhook url of receiver %q is not set", rc.Name)
+		}
+		receivers[rc.Name] = true
+	}
+
+	for _, rt := range r.Routes {
+		if !receivers[rt.Receiver] {
+			return errors.Errorf("route to unknown receiver %q", rt.Receiver)
+		}
+		for _, s := range rt.Severities {
+			if !s.valid() {
+				return errors.Errorf("unknown severity %q in route to %q", s, rt.Receiver)
+			}
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, rule := range r.Rules {
+		if rule.Name == "" {
+			return errors.New("rule without a name")
+		}
+		if names[rule.Name] {
+			return errors.Errorf("duplicate rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if !rule.Severity.valid() {
+			return errors.Errorf("unknown severity %q of rule %q", rule.Severity, rule.Name)
+		}
+		switch {
+		case rule.Event != nil && rule.Metric != "":
+			return errors.Errorf("rule %q has both a metric and an event", rule.Name)
+		case rule.Event != nil:
+		case rule.Metric == "":
+			return errors.Errorf("rule %q has neither a metric nor an event", rule.Name)
+		default:
+			if _, ok := comparisons[rule.Op]; !ok {
+				return errors.Errorf("unknown op %q of rule %q", rule.Op, rule.Name)
+			}
+			if rule.Field != "" && !fields[rule.Field] {
+				return errors.Errorf("unknown field %q of rule %q", rule.Field, rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (s Severity) valid() bool {
+	return s == Info || s == Warning || s == Critical
+}
+
+func containsSeverity(list []Severity, s Severity) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+// Package analytics evaluates the licence program from the indexed events:
+// how many of the wallets paying the licence fee keep paying it month after
+// month, how much each monthly cohort of wallets paid, and how far the
+// wallets go in the participation funnel.
+//
+// The wallets are grouped in cohorts by the month of their first licence fee,
+// the TransferredToTokenHolder events of the Licence. A wallet is retained n
+// months later when it paid a fee during the n-th month after its cohort.
+//
+// The referrers of the wallets are not recorded on the chain. Given the
+// Attribution of the wallets to their referrer, ComputeReferrers evaluates
+// each referrer by the wallets it brought in which went on to pay a fee.
+//
+// Before the licence amount is updated, LicenceWhatIf reports how the fees of
+// the recent loads would have changed with the proposed amount, and projects
+// both over the coming months.
+package analytics
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// FeeEvent is the event of the licence fees, emitted by the Licence contract
+// indexed as "licence".
+const FeeEvent = "TransferredToTokenHolder"
+
+// monthLayout formats the months of the cohorts.
+const monthLayout = "2006-01"
+
+// BlockTimes returns the time blocks were mined at.
+type BlockTimes interface {
+	BlockTime(ctx context.Context, number uint64) (time.Time, error)
+}
+
+// HeaderBackend is the part of the node API used by NodeTimes.
+type HeaderBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// NodeTimes are the BlockTimes read from the headers of the node, which are
+// kept in memory: each block is only read once.
+type NodeTimes struct {
+	backend HeaderBackend
+
+	mu    sync.Mutex
+	times map[uint64]time.Time
+}
+
+// NewNodeTimes returns the block times of the node.
+func NewNodeTimes(backend HeaderBackend) *NodeTimes {
+	return &NodeTimes{backend: backend, times: make(map[uint64]time.Time)}
+}
+
+// BlockTime implements BlockTimes.
+func (n *NodeTimes) BlockTime(ctx context.Context, number uint64) (time.Time, error) {
+	n.mu.Lock()
+	t, ok := n.times[number]
+	n.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+	h, err := n.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "getting header of block %d", number)
+	}
+	t = time.Unix(int64(h.Time), 0).UTC()
+	n.mu.Lock()
+	n.times[number] = t
+	n.mu.Unlock()
+	return t, nil
+}
+
+// Report is the evaluation of the licence program.
+type Report struct {
+	// Cohorts are the monthly cohorts of wallets, oldest first.
+	Cohorts []Cohort `json:"cohorts"`
+	// Funnel are the stages of the participation of the wallets, each one
+	// reached by part of the wallets of the previous one.
+	Funnel []Stage `json:"funnel"`
+}
+
+// Cohort are the wallets which paid their first licence fee in a month.
+type Cohort struct {
+	// Month is the month of the cohort, formatted as 2006-01.
+	Month   string `json:"month"`
+	Wallets int    `json:"wallets"`
+	// Retention is the share of the wallets of the cohort which paid a fee
+	// in each month since the month of the cohort, which is first, up to
+	// the month of the report or MaxMonths months.
+	Retention []float64 `json:"retention"`
+	// Fees are the fees paid by the wallets of the cohort since it started,
+	// by asset, in its base unit. The zero address is ether.
+	Fees map[string]string `json:"fees"`
+	// FeesPerWallet are Fees divided by the number of wallets.
+	FeesPerWallet map[string]string `json:"fees_per_wallet"`
+}
+
+// Stage is a stage of the participation funnel.
+type Stage struct {
+	Name    string `json:"name"`
+	Wallets int    `json:"wallets"`
+}
+
+// Names of the stages of the funnel.
+const (
+	// StagePaid are the wallets which paid a licence fee.
+	StagePaid = "paid"
+	// StageReturned are the wallets which paid fees in several months.
+	StageReturned = "returned"
+	// StageActive are the returned wallets which paid a fee in the month
+	// of the report.
+	StageActive = "active"
+)
+
+// Options are the options of Compute.
+type Options struct {
+	// Now is the time of the report, the current month is the last one
+	// of the retention of the cohorts. The current time when zero.
+	Now time.Time
+	// MaxMonths caps the number of months of the retention of the cohorts,
+	// unlimited when not positive.
+	MaxMonths int
+}
+
+// wallet is the activity of a wallet.
+type wallet struct {
+	cohort string
+	// months are the months the wallet paid a fee in.
+	months map[string]bool
+}
+
+// Compute evaluates the program from the licence fees stored by the indexer.
+func Compute(ctx context.Context, store indexer.Store, times BlockTimes, opts Options) (*Report, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+
+	events, err := store.Events(indexer.Query{Contract: "licence", Name: FeeEvent})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading licence fees")
+	}
+
+	wallets := make(map[string]*wallet)
+	fees := make(map[string]map[string]*big.Int)
+	for _, e := range events {
+		t, err := times.BlockTime(ctx, e.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		month := t.Format(monthLayout)
+		from, ok := indexer.FormatArg(e.Args["_from"]).(string)
+		if !ok {
+			return nil, errors.Errorf("%s event in transaction %s has no _from", FeeEvent, e.TxHash.Hex())
+		}
+		asset, _ := indexer.FormatArg(e.Args["_asset"]).(string)
+		amount, ok := new(big.Int).SetString(fmtArg(e.Args["_amount"]), 10)
+		if !ok {
+			return nil, errors.Errorf("%s event in transaction %s has an invalid _amount", FeeEvent, e.TxHash.Hex())
+		}
+
+		w := wallets[from]
+		if w == nil {
+			// The events are in chain order, the first fee of a wallet
+			// sets its cohort.
+			w = &wallet{cohort: month, months: make(map[string]bool)}
+			wallets[from] = w
+		}
+		w.months[month] = true
+		if fees[w.cohort] == nil {
+			fees[w.cohort] = make(map[string]*big.Int)
+		}
+		if fees[w.cohort][asset] == nil {
+			fees[w.cohort][asset] = new(big.Int)
+		}
+		fees[w.cohort][asset].Add(fees[w.cohort][asset], amount)
+	}
+
+	byCohort := make(map[string][]*wallet)
+	for _, w := range wallets {
+		byCohort[w.cohort] = append(byCohort[w.cohort], w)
+	}
+	report := &Report{Cohorts: []Cohort{}}
+	current := now.Format(monthLayout)
+	for month, members := range byCohort {
+		start, _ := time.Parse(monthLayout, month)
+		c := Cohort{
+			Month:         month,
+			Wallets:       len(members),
+			Fees:          make(map[string]string),
+			FeesPerWallet: make(map[string]string),
+		}
+		for n := 0; opts.MaxMonths <= 0 || n < opts.MaxMonths; n++ {
+			m := start.AddDate(0, n, 0).Format(monthLayout)
+			if m > current {
+				break
+			}
+			retained := 0
+			for _, w := range members {
+				if w.months[m] {
+					retained++
+				}
+			}
+			c.Retention = append(c.Retention, float64(retained)/float64(len(members)))
+		}
+		for asset, total := range fees[month] {
+			c.Fees[asset] = total.String()
+			c.FeesPerWallet[asset] = new(big.Int).Div(total, big.NewInt(int64(len(members)))).String()
+		}
+		report.Cohorts = append(report.Cohorts, c)
+	}
+	sort.Slice(report.Cohorts, func(a, b int) bool { return report.Cohorts[a].Month < report.Cohorts[b].Month })
+
+	var returned, active int
+	for _, w := range wallets {
+		if len(w.months) < 2 {
+			continue
+		}
+		returned++
+		if w.months[current] {
+			active++
+		}
+	}
+	report.Funnel = []Stage{
+		{Name: StagePaid, Wallets: len(wallets)},
+		{Name: StageReturned, Wallets: returned},
+		{Name: StageActive, Wallets: active},
+	}
+	return report, nil
+}
+
+// fmtArg returns an integer argument formatted in decimal, whether it was
+// stored decoded or formatted.
+func fmtArg(v interface{}) string {
+	s, _ := indexer.FormatArg(v).(string)
+	return s
+}
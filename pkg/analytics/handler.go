@@ -0,0 +1,129 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler serves the Report of the events of the store:
+//
+//	GET /analytics?months=12    the cohorts, their retention and the funnel
+//
+// The months parameter caps the months of the retention of the cohorts. The
+// cohorts are a table of one row per month, and each retention a series
+// ready to be charted.
+func NewHandler(store indexer.Store, times BlockTimes) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("%s %s not allowed", req.Method, req.URL.Path))
+			return
+		}
+		var opts Options
+		if m := req.URL.Query().Get("months"); m != "" {
+			n, err := strconv.Atoi(m)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, errors.Errorf("invalid months %q", m))
+				return
+			}
+			opts.MaxMonths = n
+		}
+		report, err := Compute(req.Context(), store, times, opts)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	})
+}
+
+// NewReferrersHandler serves the statistics of the referrers of the
+// attribution, computed from the events of the store:
+//
+//	GET /analytics/referrers                as JSON
+//	GET /analytics/referrers?format=csv     as CSV, see WriteReferrersCSV
+func NewReferrersHandler(store indexer.Store, attribution Attribution) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("%s %s not allowed", req.Method, req.URL.Path))
+			return
+		}
+		format := req.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			writeError(w, http.StatusBadRequest, errors.Errorf("invalid format %q", format))
+			return
+		}
+		referrers, err := ComputeReferrers(store, attribution)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			WriteReferrersCSV(w, referrers)
+			return
+		}
+		writeJSON(w, http.StatusOK, referrers)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// NewLicenceWhatIfHandler serves the impact of a proposed licence amount on
+// the fees, computed from the loads of the store:
+//
+//	GET /analytics/licence-amount?proposed=5&months=3&horizon=12
+//
+// The months and horizon parameters are those of WhatIfOptions.
+func NewLicenceWhatIfHandler(store indexer.Store, times BlockTimes, licence LicenceAmountReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("%s %s not allowed", req.Method, req.URL.Path))
+			return
+		}
+		query := req.URL.Query()
+		proposed, err := strconv.ParseUint(query.Get("proposed"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Errorf("invalid proposed amount %q", query.Get("proposed")))
+			return
+		}
+		var opts WhatIfOptions
+		for name, v := range map[string]*int{"months": &opts.Months, "horizon": &opts.Horizon} {
+			s := query.Get(name)
+			if s == "" {
+				continue
+			}
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, errors.Errorf("invalid %s %q", name, s))
+				return
+			}
+			*v = n
+		}
+		impact, err := LicenceWhatIf(req.Context(), store, times, licence, proposed, opts)
+		if errors.Cause(err) == ErrLicenceAmountOutOfRange {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, impact)
+	})
+}
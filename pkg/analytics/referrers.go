@@ -0,0 +1,195 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Attribution maps the wallets to the referrer which brought their owner in.
+// The referrals are not recorded on the chain, the attribution is kept by the
+// onboarding and read from a CSV file of two columns, with a header:
+//
+//	wallet,referrer
+//	0x...,partner-a
+type Attribution map[common.Address]string
+
+// ReadAttribution reads the attribution of the wallets from CSV.
+func ReadAttribution(r io.Reader) (Attribution, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("attribution is empty")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading attribution header")
+	}
+	if strings.ToLower(header[0]) != "wallet" || strings.ToLower(header[1]) != "referrer" {
+		return nil, errors.Errorf("attribution header is %q, want wallet,referrer", strings.Join(header, ","))
+	}
+	a := make(Attribution)
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading attribution")
+		}
+		if !common.IsHexAddress(record[0]) {
+			return nil, errors.Errorf("line %d: invalid wallet %q", line, record[0])
+		}
+		wallet := common.HexToAddress(record[0])
+		if record[1] == "" {
+			return nil, errors.Errorf("line %d: wallet %s has no referrer", line, wallet.Hex())
+		}
+		if r, ok := a[wallet]; ok && r != record[1] {
+			return nil, errors.Errorf("line %d: wallet %s is attributed to both %s and %s", line, wallet.Hex(), r, record[1])
+		}
+		a[wallet] = record[1]
+	}
+}
+
+// ReadAttributionFile reads the attribution of the CSV file at path.
+func ReadAttributionFile(path string) (Attribution, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening attribution")
+	}
+	defer f.Close()
+	a, err := ReadAttribution(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	return a, nil
+}
+
+// Referrer are the statistics of the wallets attributed to a referrer.
+type Referrer struct {
+	Referrer string `json:"referrer"`
+	// Wallets is the number of wallets attributed to the referrer.
+	Wallets int `json:"wallets"`
+	// Activated is the number of them which paid a licence fee, loading
+	// their card.
+	Activated int `json:"activated"`
+	// Conversion is the share of the wallets activated.
+	Conversion float64 `json:"conversion"`
+	// Fees are the licence fees paid by the wallets, by asset, in its base
+	// unit. The zero address is ether.
+	Fees map[string]string `json:"fees"`
+}
+
+// ComputeReferrers returns the statistics of each referrer of the attribution,
+// sorted by referrer, from the licence fees stored by the indexer.
+func ComputeReferrers(store indexer.Store, attribution Attribution) ([]Referrer, error) {
+	events, err := store.Events(indexer.Query{Contract: "licence", Name: FeeEvent})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading licence fees")
+	}
+
+	type referrer struct {
+		wallets   int
+		activated map[common.Address]bool
+		fees      map[string]*big.Int
+	}
+	referrers := make(map[string]*referrer)
+	for _, name := range attribution {
+		if referrers[name] == nil {
+			referrers[name] = &referrer{activated: make(map[common.Address]bool), fees: make(map[string]*big.Int)}
+		}
+		referrers[name].wallets++
+	}
+	for _, e := range events {
+		from, ok := indexer.FormatArg(e.Args["_from"]).(string)
+		if !ok {
+			return nil, errors.Errorf("%s event in transaction %s has no _from", FeeEvent, e.TxHash.Hex())
+		}
+		wallet := common.HexToAddress(from)
+		name, ok := attribution[wallet]
+		if !ok {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(fmtArg(e.Args["_amount"]), 10)
+		if !ok {
+			return nil, errors.Errorf("%s event in transaction %s has an invalid _amount", FeeEvent, e.TxHash.Hex())
+		}
+		asset, _ := indexer.FormatArg(e.Args["_asset"]).(string)
+		r := referrers[name]
+		r.activated[wallet] = true
+		if r.fees[asset] == nil {
+			r.fees[asset] = new(big.Int)
+		}
+		r.fees[asset].Add(r.fees[asset], amount)
+	}
+
+	stats := make([]Referrer, 0, len(referrers))
+	for name, r := range referrers {
+		s := Referrer{
+			Referrer:   name,
+			Wallets:    r.wallets,
+			Activated:  len(r.activated),
+			Conversion: float64(len(r.activated)) / float64(r.wallets),
+			Fees:       make(map[string]string, len(r.fees)),
+		}
+		for asset, total := range r.fees {
+			s.Fees[asset] = total.String()
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Referrer < stats[b].Referrer })
+	return stats, nil
+}
+
+// WriteReferrersCSV writes the statistics of the referrers as CSV, with a
+// column of fees for each asset:
+//
+//	referrer,wallets,activated,conversion,fees_0x0000000000000000000000000000000000000000
+//	partner-a,10,4,0.4,40000000000000000
+func WriteReferrersCSV(w io.Writer, referrers []Referrer) error {
+	assets := make(map[string]bool)
+	for _, r := range referrers {
+		for asset := range r.Fees {
+			assets[asset] = true
+		}
+	}
+	columns := make([]string, 0, len(assets))
+	for asset := range assets {
+		columns = append(columns, asset)
+	}
+	sort.Strings(columns)
+
+	cw := csv.NewWriter(w)
+	header := []string{"referrer", "wallets", "activated", "conversion"}
+	for _, asset := range columns {
+		header = append(header, "fees_"+asset)
+	}
+	cw.Write(header)
+	for _, r := range referrers {
+		record := []string{
+			r.Referrer,
+			strconv.Itoa(r.Wallets),
+			strconv.Itoa(r.Activated),
+			strconv.FormatFloat(r.Conversion, 'f', -1, 64),
+		}
+		for _, asset := range columns {
+			fees := r.Fees[asset]
+			if fees == "" {
+				fees = "0"
+			}
+			record = append(record, fees)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "writing referrers")
+}
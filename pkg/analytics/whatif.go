@@ -0,0 +1,248 @@
+package analytics
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// FloatEvent is the event of the amounts loaded net of the licence fee,
+// emitted by the Licence contract indexed as "licence".
+const FloatEvent = "TransferredToCryptoFloat"
+
+// Bounds of the licence amount, the MIN_AMOUNT_SCALE and MAX_AMOUNT_SCALE of
+// the Licence contract. The fee of a load of x is x * amount / (amount + 1000).
+const (
+	MinLicenceAmount = 1
+	MaxLicenceAmount = 1000
+)
+
+// ErrLicenceAmountOutOfRange is returned for a licence amount the Licence
+// contract would reject.
+var ErrLicenceAmountOutOfRange = errors.New("licence amount out of range")
+
+// LicenceAmountReader reads the current licence amount, *bindings.LicenceCaller
+// implements it.
+type LicenceAmountReader interface {
+	LicenceAmountScaled(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// WhatIfOptions are the options of LicenceWhatIf.
+type WhatIfOptions struct {
+	// Now is the end of the history of the loads, the current time when
+	// zero.
+	Now time.Time
+	// Months are the months of history of the loads the monthly volume is
+	// averaged over, 3 when not positive.
+	Months int
+	// Horizon are the months the fees are projected over, 12 when not
+	// positive.
+	Horizon int
+}
+
+const (
+	defaultWhatIfMonths  = 3
+	defaultWhatIfHorizon = 12
+)
+
+// LicenceImpact is the impact of a change of the licence amount on the fees
+// paid to the token holder, had the loads of the history been made with the
+// proposed amount.
+type LicenceImpact struct {
+	CurrentAmount  uint64 `json:"current_amount"`
+	ProposedAmount uint64 `json:"proposed_amount"`
+	// From and To bound the history of the loads.
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Months  int       `json:"months"`
+	Horizon int       `json:"horizon"`
+	Loads   int       `json:"loads"`
+	// Assets are the impacts on the fees of each asset loaded, in the order
+	// of their address. The zero address is ether.
+	Assets []AssetImpact `json:"assets"`
+}
+
+// AssetImpact is the impact of a change of the licence amount on the fees of
+// an asset, in its base unit.
+type AssetImpact struct {
+	Asset string `json:"asset"`
+	Loads int    `json:"loads"`
+	// Loaded are the amounts loaded during the history, fees included.
+	Loaded string `json:"loaded"`
+	// Fees are the fees paid during the history, with the amounts in force
+	// when the loads were made.
+	Fees string `json:"fees"`
+	// CurrentFees and ProposedFees are the fees of the loads of the history
+	// with the current and the proposed amounts, Change their difference.
+	CurrentFees  string `json:"current_fees"`
+	ProposedFees string `json:"proposed_fees"`
+	Change       string `json:"change"`
+	// ProjectedCurrent and ProjectedProposed are the fees over the horizon
+	// at the monthly volume of the history, with the current and the
+	// proposed amounts, ProjectedChange their difference.
+	ProjectedCurrent  string `json:"projected_current"`
+	ProjectedProposed string `json:"projected_proposed"`
+	ProjectedChange   string `json:"projected_change"`
+}
+
+// load is a load of the Licence, the fee and the amount net of the fee of
+// its transaction.
+type load struct {
+	asset string
+	fee   *big.Int
+	float *big.Int
+}
+
+// LicenceWhatIf computes the impact of the proposed licence amount from the
+// loads stored by the indexer, before it is submitted with
+// updateLicenceAmount. The loads of TKN, which pay no fee, are left out.
+func LicenceWhatIf(ctx context.Context, store indexer.Store, times BlockTimes, licence LicenceAmountReader, proposed uint64, opts WhatIfOptions) (*LicenceImpact, error) {
+	if proposed < MinLicenceAmount || proposed > MaxLicenceAmount {
+		return nil, errors.Wrapf(ErrLicenceAmountOutOfRange, "%d is not between %d and %d", proposed, MinLicenceAmount, MaxLicenceAmount)
+	}
+	if opts.Months <= 0 {
+		opts.Months = defaultWhatIfMonths
+	}
+	if opts.Horizon <= 0 {
+		opts.Horizon = defaultWhatIfHorizon
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+	from := now.AddDate(0, -opts.Months, 0)
+
+	current, err := licence.LicenceAmountScaled(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "calling licenceAmountScaled")
+	}
+
+	loads, err := licenceLoads(ctx, store, times, from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &LicenceImpact{
+		CurrentAmount:  current.Uint64(),
+		ProposedAmount: proposed,
+		From:           from,
+		To:             now,
+		Months:         opts.Months,
+		Horizon:        opts.Horizon,
+		Assets:         []AssetImpact{},
+	}
+	type totals struct {
+		loads                  int
+		loaded, fees, cur, new *big.Int
+	}
+	byAsset := make(map[string]*totals)
+	for _, l := range loads {
+		if l.fee == nil {
+			continue
+		}
+		t := byAsset[l.asset]
+		if t == nil {
+			t = &totals{loaded: new(big.Int), fees: new(big.Int), cur: new(big.Int), new: new(big.Int)}
+			byAsset[l.asset] = t
+		}
+		loaded := new(big.Int).Set(l.fee)
+		if l.float != nil {
+			loaded.Add(loaded, l.float)
+		}
+		t.loads++
+		t.loaded.Add(t.loaded, loaded)
+		t.fees.Add(t.fees, l.fee)
+		t.cur.Add(t.cur, licenceFee(loaded, current))
+		t.new.Add(t.new, licenceFee(loaded, new(big.Int).SetUint64(proposed)))
+		impact.Loads++
+	}
+
+	months, horizon := big.NewInt(int64(opts.Months)), big.NewInt(int64(opts.Horizon))
+	project := func(fees *big.Int) *big.Int {
+		p := new(big.Int).Mul(fees, horizon)
+		return p.Div(p, months)
+	}
+	for asset, t := range byAsset {
+		projectedCur, projectedNew := project(t.cur), project(t.new)
+		impact.Assets = append(impact.Assets, AssetImpact{
+			Asset:             asset,
+			Loads:             t.loads,
+			Loaded:            t.loaded.String(),
+			Fees:              t.fees.String(),
+			CurrentFees:       t.cur.String(),
+			ProposedFees:      t.new.String(),
+			Change:            new(big.Int).Sub(t.new, t.cur).String(),
+			ProjectedCurrent:  projectedCur.String(),
+			ProjectedProposed: projectedNew.String(),
+			ProjectedChange:   new(big.Int).Sub(projectedNew, projectedCur).String(),
+		})
+	}
+	sort.Slice(impact.Assets, func(a, b int) bool { return impact.Assets[a].Asset < impact.Assets[b].Asset })
+	return impact, nil
+}
+
+// licenceLoads returns the loads of the Licence mined between from and to,
+// pairing the fee and the net amount of each asset of a transaction.
+func licenceLoads(ctx context.Context, store indexer.Store, times BlockTimes, from, to time.Time) ([]*load, error) {
+	var events []indexer.Event
+	for _, name := range []string{FeeEvent, FloatEvent} {
+		e, err := store.Events(indexer.Query{Contract: "licence", Name: name})
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s events", name)
+		}
+		events = append(events, e...)
+	}
+
+	type key struct {
+		tx    common.Hash
+		asset string
+	}
+	loads := make(map[key]*load)
+	var order []key
+	for _, e := range events {
+		t, err := times.BlockTime(ctx, e.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		asset, _ := indexer.FormatArg(e.Args["_asset"]).(string)
+		amount, ok := new(big.Int).SetString(fmtArg(e.Args["_amount"]), 10)
+		if !ok {
+			return nil, errors.Errorf("%s event in transaction %s has an invalid _amount", e.Name, e.TxHash.Hex())
+		}
+		k := key{tx: e.TxHash, asset: asset}
+		l := loads[k]
+		if l == nil {
+			l = &load{asset: asset}
+			loads[k] = l
+			order = append(order, k)
+		}
+		if e.Name == FeeEvent {
+			l.fee = amount
+		} else {
+			l.float = amount
+		}
+	}
+	r := make([]*load, len(order))
+	for i, k := range order {
+		r[i] = loads[k]
+	}
+	return r, nil
+}
+
+// licenceFee returns the fee of a load of amount with the licence amount, as
+// computed by Licence.load.
+func licenceFee(amount, licenceAmount *big.Int) *big.Int {
+	net := new(big.Int).Mul(amount, big.NewInt(MaxLicenceAmount))
+	net.Div(net, new(big.Int).Add(licenceAmount, big.NewInt(MaxLicenceAmount)))
+	return net.Sub(amount, net)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// authenticator checks the bearer token of requests against a set of API keys.
+type authenticator struct {
+	keys [][]byte
+}
+
+func newAuthenticator(keys []string) *authenticator {
+	a := &authenticator{}
+	for _, k := range keys {
+		if k != "" {
+			a.keys = append(a.keys, []byte(k))
+		}
+	}
+	return a
+}
+
+// require rejects requests without a valid "Authorization: Bearer <key>" header.
+func (a *authenticator) require(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.valid(r.Header.Get("Authorization")) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API key"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (a *authenticator) valid(header string) bool {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	token := []byte(strings.TrimPrefix(header, prefix))
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(token, k) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/units"
+)
+
+// LicenceResponse is the body of GET /licence.
+type LicenceResponse struct {
+	AmountScaled      string         `json:"amount_scaled"`
+	CryptoFloat       common.Address `json:"crypto_float"`
+	FloatLocked       bool           `json:"float_locked"`
+	TokenHolder       common.Address `json:"token_holder"`
+	HolderLocked      bool           `json:"holder_locked"`
+	LicenceDAO        common.Address `json:"licence_dao"`
+	LicenceDAOLocked  bool           `json:"licence_dao_locked"`
+	TKNContract       common.Address `json:"tkn_contract"`
+	TKNContractLocked bool           `json:"tkn_contract_locked"`
+}
+
+// TokenResponse is the body of GET /tokens/{address}.
+type TokenResponse struct {
+	Address    common.Address `json:"address"`
+	Symbol     string         `json:"symbol"`
+	Magnitude  string         `json:"magnitude"`
+	Rate       string         `json:"rate"`
+	Available  bool           `json:"available"`
+	Loadable   bool           `json:"loadable"`
+	Redeemable bool           `json:"redeemable"`
+	LastUpdate string         `json:"last_update"`
+}
+
+// RolesResponse is the body of GET /controller/{address}.
+type RolesResponse struct {
+	Address    common.Address `json:"address"`
+	Owner      bool           `json:"owner"`
+	Admin      bool           `json:"admin"`
+	Controller bool           `json:"controller"`
+}
+
+// UpdateLicenceAmountRequest is the body of POST /licence/amount.
+type UpdateLicenceAmountRequest struct {
+	Amount string `json:"amount"`
+}
+
+// TransactionResponse is returned by the mutating routes once the transaction is sent.
+type TransactionResponse struct {
+	TxHash common.Hash `json:"tx_hash"`
+}
+
+func callOpts(r *http.Request) *bind.CallOpts {
+	return &bind.CallOpts{Context: r.Context()}
+}
+
+func (s *Server) handleLicence(w http.ResponseWriter, r *http.Request) {
+	opts := callOpts(r)
+	res := LicenceResponse{}
+
+	amount, err := s.licence.LicenceAmountScaled(opts)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting licence amount"))
+		return
+	}
+	res.AmountScaled = amount.String()
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"crypto float", func() (err error) { res.CryptoFloat, err = s.licence.CryptoFloat(opts); return }},
+		{"float lock", func() (err error) { res.FloatLocked, err = s.licence.FloatLocked(opts); return }},
+		{"token holder", func() (err error) { res.TokenHolder, err = s.licence.TokenHolder(opts); return }},
+		{"holder lock", func() (err error) { res.HolderLocked, err = s.licence.HolderLocked(opts); return }},
+		{"licence DAO", func() (err error) { res.LicenceDAO, err = s.licence.LicenceDAO(opts); return }},
+		{"licence DAO lock", func() (err error) { res.LicenceDAOLocked, err = s.licence.LicenceDAOLocked(opts); return }},
+		{"TKN contract", func() (err error) { res.TKNContract, err = s.licence.TknContractAddress(opts); return }},
+		{"TKN contract lock", func() (err error) { res.TKNContractLocked, err = s.licence.TknContractAddressLocked(opts); return }},
+	}
+	for _, c := range calls {
+		err = c.call()
+		if err != nil {
+			writeError(w, http.StatusBadGateway, errors.Wrapf(err, "getting %s", c.name))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := s.tokenWhitelist.TokenAddressArray(callOpts(r))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting token addresses"))
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	address, ok := addressParam(w, r, "/tokens/")
+	if !ok {
+		return
+	}
+
+	symbol, magnitude, rate, available, loadable, redeemable, lastUpdate, err := s.tokenWhitelist.GetTokenInfo(callOpts(r), address)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting token info"))
+		return
+	}
+	if !available {
+		writeError(w, http.StatusNotFound, errors.Errorf("token %s is not whitelisted", address.Hex()))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TokenResponse{
+		Address:    address,
+		Symbol:     symbol,
+		Magnitude:  magnitude.String(),
+		Rate:       rate.String(),
+		Available:  available,
+		Loadable:   loadable,
+		Redeemable: redeemable,
+		LastUpdate: lastUpdate.String(),
+	})
+}
+
+func (s *Server) handleRoles(w http.ResponseWriter, r *http.Request) {
+	address, ok := addressParam(w, r, "/controller/")
+	if !ok {
+		return
+	}
+	opts := callOpts(r)
+
+	owner, err := s.controller.Owner(opts)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting controller owner"))
+		return
+	}
+	isAdmin, err := s.controller.IsAdmin(opts, address)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting admin role"))
+		return
+	}
+	isController, err := s.controller.IsController(opts, address)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "getting controller role"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RolesResponse{
+		Address:    address,
+		Owner:      owner == address,
+		Admin:      isAdmin,
+		Controller: isController,
+	})
+}
+
+func (s *Server) handleUpdateLicenceAmount(w http.ResponseWriter, r *http.Request) {
+	if s.session == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no signing account configured"))
+		return
+	}
+
+	var req UpdateLicenceAmountRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+		return
+	}
+	amount, err := units.ParseUint(req.Amount)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Errorf("%q is not a valid amount", req.Amount))
+		return
+	}
+
+	tx, err := s.licence.UpdateLicenceAmount(s.session.Transact(r.Context()), amount)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, errors.Wrap(err, "updating licence amount"))
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, TransactionResponse{TxHash: tx.Hash()})
+}
+
+// addressParam parses the address following prefix in the request path.
+func addressParam(w http.ResponseWriter, r *http.Request, prefix string) (common.Address, bool) {
+	s := strings.TrimPrefix(r.URL.Path, prefix)
+	if !common.IsHexAddress(s) {
+		writeError(w, http.StatusBadRequest, errors.Errorf("%q is not a valid address", s))
+		return common.Address{}, false
+	}
+	return common.HexToAddress(s), true
+}
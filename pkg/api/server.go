@@ -0,0 +1,121 @@
+// Package api implements an HTTP/REST service exposing the state of the
+// deployed contracts to clients that cannot use the Go bindings directly.
+//
+// Routes:
+//
+//	GET  /licence                 licence configuration
+//	GET  /tokens                  addresses of the whitelisted tokens
+//	GET  /tokens/{address}        whitelist entry of a token
+//	GET  /controller/{address}    controller roles of an address
+//	POST /licence/amount          update the licence amount (authenticated)
+//	GET  /metrics                 Prometheus metrics, when a registry is configured
+//
+// All responses are JSON, integers are encoded as decimal strings to avoid
+// precision loss in JavaScript clients.
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+)
+
+// Config holds the configuration of the API server.
+type Config struct {
+	Licence        common.Address
+	TokenWhitelist common.Address
+	Controller     common.Address
+
+	// APIKeys are the bearer tokens accepted by the mutating routes.
+	APIKeys []string
+
+	// TransactOpts sign the transactions sent by the mutating routes. The
+	// mutating routes are disabled when nil.
+	TransactOpts *bind.TransactOpts
+	// Signer signs the transactions of the mutating routes with the context
+	// of their request, in place of the Signer of TransactOpts, when not nil.
+	Signer session.SignerFunc
+
+	// Metrics is the registry served on /metrics, the route is disabled when nil.
+	Metrics metrics.Registry
+}
+
+// Server is an http.Handler serving the API.
+type Server struct {
+	licence        *bindings.Licence
+	tokenWhitelist *bindings.TokenWhitelistCaller
+	controller     *bindings.ControllerCaller
+	session        *session.Session
+	mux            *http.ServeMux
+}
+
+// New creates a new API server using backend to interact with the contracts.
+func New(backend bind.ContractBackend, cfg Config) (*Server, error) {
+	licence, err := bindings.NewLicence(cfg.Licence, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding licence contract")
+	}
+	tokenWhitelist, err := bindings.NewTokenWhitelistCaller(cfg.TokenWhitelist, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding token whitelist contract")
+	}
+	controller, err := bindings.NewControllerCaller(cfg.Controller, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding controller contract")
+	}
+
+	s := &Server{
+		licence:        licence,
+		tokenWhitelist: tokenWhitelist,
+		controller:     controller,
+		mux:            http.NewServeMux(),
+	}
+
+	if cfg.TransactOpts != nil {
+		s.session = session.New(cfg.TransactOpts, nil)
+		s.session.SignWith(cfg.Signer)
+	}
+
+	auth := newAuthenticator(cfg.APIKeys)
+
+	s.mux.Handle("/licence", get(s.handleLicence))
+	s.mux.Handle("/licence/amount", post(auth.require(s.handleUpdateLicenceAmount)))
+	s.mux.Handle("/tokens", get(s.handleTokens))
+	s.mux.Handle("/tokens/", get(s.handleToken))
+	s.mux.Handle("/controller/", get(s.handleRoles))
+	if cfg.Metrics != nil {
+		s.mux.Handle("/metrics", get(telemetry.Handler(cfg.Metrics).ServeHTTP))
+	}
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func get(h http.HandlerFunc) http.Handler {
+	return method(http.MethodGet, h)
+}
+
+func post(h http.HandlerFunc) http.Handler {
+	return method(http.MethodPost, h)
+}
+
+func method(m string, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != m {
+			w.Header().Set("Allow", m)
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		h(w, r)
+	})
+}
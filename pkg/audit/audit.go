@@ -0,0 +1,141 @@
+// Package audit checks the ownership and the controller roles of the
+// contracts of every network of a registry against an approved roster, and
+// produces signed compliance reports listing the changes since the previous
+// report:
+//
+//	roster, err := audit.LoadRosterFile("roster.yaml")
+//	...
+//	a := &audit.Auditor{
+//		Registry: reg,
+//		Backends: map[uint64]bind.ContractBackend{1: mainnet},
+//		Roster:   roster,
+//		Sign:     signing.KeySigner(key),
+//		Dir:      "/var/lib/monolith/audit",
+//		Interval: time.Hour,
+//	}
+//	go a.Run(ctx)
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/ownable"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+)
+
+// Roles are the admins and controllers of a controller contract.
+type Roles struct {
+	Address     common.Address   `json:"address"`
+	Admins      []common.Address `json:"admins"`
+	Controllers []common.Address `json:"controllers"`
+	// Problems are the differences with the roster.
+	Problems []string `json:"problems,omitempty"`
+	Err      string   `json:"error,omitempty"`
+}
+
+// OK tells whether the roles match the roster.
+func (r *Roles) OK() bool {
+	return r.Err == "" && len(r.Problems) == 0
+}
+
+// Network is the audit of the contracts of a network.
+type Network struct {
+	ChainID    uint64          `json:"chain_id"`
+	Ownership  []ownable.Entry `json:"ownership"`
+	Controller *Roles          `json:"controller,omitempty"`
+	Err        string          `json:"error,omitempty"`
+}
+
+// OK tells whether the contracts of the network comply with the roster.
+func (n *Network) OK() bool {
+	if n.Err != "" || (n.Controller != nil && !n.Controller.OK()) {
+		return false
+	}
+	for _, e := range n.Ownership {
+		if !e.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Audit checks the contracts of every network of reg against the roster,
+// reading them with the backend of the chain ID. The networks are sorted by
+// chain ID.
+func Audit(ctx context.Context, reg *registry.Registry, backends map[uint64]bind.ContractBackend, roster *Roster) []Network {
+	var networks []Network
+	for _, chainID := range reg.ChainIDs() {
+		n := Network{ChainID: chainID.Uint64()}
+		backend, ok := backends[n.ChainID]
+		if !ok {
+			n.Err = fmt.Sprintf("no backend for chain %d", n.ChainID)
+			networks = append(networks, n)
+			continue
+		}
+		contracts := reg.Network(chainID)
+		n.Ownership = ownable.Audit(ctx, backend, contracts, ownable.Policy{Owners: roster.Owners, Locked: roster.Locked})
+		if a, ok := contracts["controller"]; ok {
+			n.Controller = auditRoles(ctx, a, backend, roster)
+		}
+		networks = append(networks, n)
+	}
+	return networks
+}
+
+func auditRoles(ctx context.Context, address common.Address, backend bind.ContractBackend, roster *Roster) *Roles {
+	r := &Roles{Address: address}
+	c, err := bindings.NewAccessClient(address, backend)
+	if err == nil {
+		r.Admins, r.Controllers, err = c.Members(ctx)
+	}
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.Problems = append(compare("admin", r.Admins, roster.Admins), compare("controller", r.Controllers, roster.Controllers)...)
+	return r
+}
+
+// compare returns the differences between the accounts holding a role and the
+// approved ones.
+func compare(role string, actual, approved []common.Address) []string {
+	var problems []string
+	for _, a := range actual {
+		if !contains(approved, a) {
+			problems = append(problems, fmt.Sprintf("%s %s is not approved", role, a.Hex()))
+		}
+	}
+	for _, a := range approved {
+		if !contains(actual, a) {
+			problems = append(problems, fmt.Sprintf("approved %s %s is missing", role, a.Hex()))
+		}
+	}
+	return problems
+}
+
+func contains(accounts []common.Address, a common.Address) bool {
+	for _, b := range accounts {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRoles returns the admins and controllers of the controller at address,
+// sorted. The controller does not enumerate its roles, they are replayed from
+// its events and checked against the role counts of the contract.
+//
+// Deprecated: Use the Members method of the AccessClient of
+// pkg/monolith/v1. ReadRoles is kept until the next major version.
+func ReadRoles(ctx context.Context, address common.Address, backend bind.ContractBackend) (admins, controllers []common.Address, err error) {
+	c, err := bindings.NewAccessClient(address, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.Members(ctx)
+}
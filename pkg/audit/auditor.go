@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// reportLayout names the report files after their time, so that they sort
+// chronologically.
+const reportLayout = "20060102T150405Z"
+
+// Auditor periodically audits the contracts and writes a signed report to
+// Dir, listing the changes since the latest report of the directory.
+type Auditor struct {
+	Registry *registry.Registry
+	// Backends are the backends of the networks of the registry, by chain ID.
+	Backends map[uint64]bind.ContractBackend
+	Roster   *Roster
+	Sign     signing.SignHashFunc
+	Dir      string
+	Interval time.Duration
+	// ErrorLog receives the errors of failed audits, they are discarded when nil.
+	ErrorLog *log.Logger
+}
+
+// Run audits the contracts every Interval until the context is cancelled.
+func (a *Auditor) Run(ctx context.Context) error {
+	t := time.NewTicker(a.Interval)
+	defer t.Stop()
+	for {
+		_, err := a.Check(ctx)
+		if err != nil && a.ErrorLog != nil {
+			a.ErrorLog.Printf("audit failed: %v", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check audits the contracts once and writes the report. The previous report
+// must have been signed by the same key.
+func (a *Auditor) Check(ctx context.Context) (*Report, error) {
+	prev, err := LatestReport(a.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Time:     time.Now().UTC().Truncate(time.Second),
+		Networks: Audit(ctx, a.Registry, a.Backends, a.Roster),
+	}
+	if prev != nil {
+		r.Changes = Diff(prev.Networks, r.Networks)
+	}
+	err = r.Sign(a.Sign)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil {
+		signer, err := r.Signer()
+		if err != nil {
+			return nil, err
+		}
+		err = prev.Verify(signer)
+		if err != nil {
+			return nil, errors.Wrap(err, "verifying previous report")
+		}
+	}
+
+	err = WriteReport(a.Dir, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WriteReport writes the report to a file of dir named after its time.
+func WriteReport(dir string, r *Report) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding report")
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return errors.Wrap(err, "creating report directory")
+	}
+	path := filepath.Join(dir, "audit-"+r.Time.UTC().Format(reportLayout)+".json")
+	return errors.Wrapf(ioutil.WriteFile(path, b, 0644), "writing %s", path)
+}
+
+// LoadReport reads a report written by WriteReport.
+func LoadReport(path string) (*Report, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading report")
+	}
+	var r Report
+	err = json.Unmarshal(b, &r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding report %s", path)
+	}
+	return &r, nil
+}
+
+// LatestReport reads the latest report of dir, it returns nil when there is
+// none.
+func LatestReport(dir string) (*Report, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading report directory")
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasPrefix(info.Name(), "audit-") && strings.HasSuffix(info.Name(), ".json") {
+			names = append(names, info.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+	return LoadReport(filepath.Join(dir, names[len(names)-1]))
+}
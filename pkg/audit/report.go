@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// Report is a signed compliance report of the contracts of every network.
+type Report struct {
+	Time     time.Time `json:"time"`
+	Networks []Network `json:"networks"`
+	// Changes are the changes since the previous report, if any.
+	Changes []Change `json:"changes,omitempty"`
+	// Signature is the signature of the hash of the report, see Hash.
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// Compliant tells whether all the contracts comply with the roster.
+func (r *Report) Compliant() bool {
+	for i := range r.Networks {
+		if !r.Networks[i].OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns the keccak256 hash of the JSON encoding of the report without
+// its signature.
+func (r *Report) Hash() (common.Hash, error) {
+	unsigned := *r
+	unsigned.Signature = nil
+	b, err := json.Marshal(unsigned)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "encoding report")
+	}
+	return crypto.Keccak256Hash(b), nil
+}
+
+// Sign signs the hash of the report.
+func (r *Report) Sign(signHash signing.SignHashFunc) error {
+	hash, err := r.Hash()
+	if err != nil {
+		return err
+	}
+	r.Signature, err = signing.SignHash(hash, signHash)
+	return errors.Wrap(err, "signing report")
+}
+
+// Signer returns the address which signed the report.
+func (r *Report) Signer() (common.Address, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return signing.RecoverHash(hash, r.Signature)
+}
+
+// Verify checks that the report was signed by signer.
+func (r *Report) Verify(signer common.Address) error {
+	recovered, err := r.Signer()
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return errors.Wrapf(signing.ErrInvalidSignature, "report signed by %s, not %s", recovered.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// Change is a change of a contract between two reports.
+type Change struct {
+	ChainID  uint64 `json:"chain_id"`
+	Contract string `json:"contract"`
+	Field    string `json:"field"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("chain %d %s %s: %q -> %q", c.ChainID, c.Contract, c.Field, c.Old, c.New)
+}
+
+// Diff returns the changes of the contracts from the networks of prev to
+// those of next, sorted by chain ID, contract and field.
+func Diff(prev, next []Network) []Change {
+	before, after := fields(prev), fields(next)
+	keys := make(map[field]bool, len(after))
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	var changes []Change
+	for k := range keys {
+		if before[k] != after[k] {
+			changes = append(changes, Change{ChainID: k.chainID, Contract: k.contract, Field: k.name, Old: before[k], New: after[k]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		if a.Contract != b.Contract {
+			return a.Contract < b.Contract
+		}
+		return a.Field < b.Field
+	})
+	return changes
+}
+
+type field struct {
+	chainID  uint64
+	contract string
+	name     string
+}
+
+// fields flattens the audited state of the networks to compare them.
+func fields(networks []Network) map[field]string {
+	m := make(map[field]string)
+	for _, n := range networks {
+		if n.Err != "" {
+			m[field{n.ChainID, "", "error"}] = n.Err
+		}
+		for _, e := range n.Ownership {
+			if e.Err != "" {
+				m[field{n.ChainID, e.Name, "error"}] = e.Err
+				continue
+			}
+			m[field{n.ChainID, e.Name, "address"}] = e.Address.Hex()
+			m[field{n.ChainID, e.Name, "owner"}] = e.Owner.Hex()
+			m[field{n.ChainID, e.Name, "transferable"}] = strconv.FormatBool(e.Transferable)
+		}
+		if r := n.Controller; r != nil {
+			if r.Err != "" {
+				m[field{n.ChainID, "controller", "roles error"}] = r.Err
+				continue
+			}
+			m[field{n.ChainID, "controller", "admins"}] = join(r.Admins)
+			m[field{n.ChainID, "controller", "controllers"}] = join(r.Controllers)
+		}
+	}
+	return m
+}
+
+func join(accounts []common.Address) string {
+	s := make([]string, len(accounts))
+	for i, a := range accounts {
+		s[i] = a.Hex()
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Roster is the approved ownership and controller roles of the contracts, on
+// every network:
+//
+//	owners: ["0x..."]
+//	locked: true
+//	admins: ["0x..."]
+//	controllers: ["0x..."]
+//
+// The owners must own the ownable contracts, which must no longer be
+// transferable when locked is set. The admins and controllers of the
+// controller must be exactly the listed ones.
+type Roster struct {
+	Owners      []common.Address
+	Locked      bool
+	Admins      []common.Address
+	Controllers []common.Address
+}
+
+type rosterFile struct {
+	Owners      []string `yaml:"owners"`
+	Locked      bool     `yaml:"locked"`
+	Admins      []string `yaml:"admins"`
+	Controllers []string `yaml:"controllers"`
+}
+
+// LoadRoster reads a roster from YAML or JSON.
+func LoadRoster(r io.Reader) (*Roster, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading roster")
+	}
+	var f rosterFile
+	err = yaml.UnmarshalStrict(b, &f)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding roster")
+	}
+
+	roster := &Roster{Locked: f.Locked}
+	for _, l := range []struct {
+		field string
+		in    []string
+		out   *[]common.Address
+	}{
+		{"owners", f.Owners, &roster.Owners},
+		{"admins", f.Admins, &roster.Admins},
+		{"controllers", f.Controllers, &roster.Controllers},
+	} {
+		for _, a := range l.in {
+			if !common.IsHexAddress(a) {
+				return nil, errors.Errorf("address %q of %s is not valid", a, l.field)
+			}
+			*l.out = append(*l.out, common.HexToAddress(a))
+		}
+	}
+	return roster, nil
+}
+
+// LoadRosterFile reads a roster from a YAML or JSON file.
+func LoadRosterFile(path string) (*Roster, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening roster file")
+	}
+	defer f.Close()
+	return LoadRoster(f)
+}
@@ -0,0 +1,213 @@
+// Package backfill scans the history of contracts for their events in
+// bounded block ranges, from their deployment block to the head of the chain.
+// Providers time out or refuse filters spanning the whole history of a busy
+// contract, so each range is filtered separately and a checkpoint is saved
+// after it, from which a crashed backfill resumes.
+//
+// The events of a range are handled before its checkpoint is saved: the
+// events of the range being scanned when a backfill crashes are handled
+// again when it resumes. A checkpoint implementing Committer, such as a
+// StoreCheckpoint, stores the events of a range along with the progress
+// instead, so that they are stored exactly once. The handler is then only
+// notified of the committed events, and not again when it fails.
+package backfill
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// DefaultRangeSize is the number of blocks filtered at once when RangeSize
+// is not set, within the limits of the common providers.
+const DefaultRangeSize = 2000
+
+// Backfill scans the events of a set of contracts over a range of blocks.
+type Backfill struct {
+	backend    indexer.Backend
+	checkpoint Checkpoint
+	handler    indexer.Handler
+	contracts  map[common.Address]indexer.Contract
+
+	// StartBlock is the first block scanned when no checkpoint was saved,
+	// usually the deployment block of the contracts.
+	StartBlock uint64
+	// EndBlock is the last block scanned, the head of the chain when the
+	// backfill starts if zero.
+	EndBlock uint64
+	// RangeSize is the number of blocks filtered at once. A range failing
+	// to be filtered is retried with half as many blocks, down to a single
+	// block, and the smaller size is kept for the next ranges.
+	RangeSize uint64
+	// Events restricts the scan to the events with these names, all the
+	// events are scanned when empty.
+	Events []string
+	// Logger receives the progress of the backfill.
+	Logger logging.Logger
+}
+
+// New creates a backfill handing the events of the contracts to handler and
+// saving its progress to checkpoint. The handler may be nil when the
+// checkpoint is a Committer.
+func New(backend indexer.Backend, checkpoint Checkpoint, handler indexer.Handler, contracts ...indexer.Contract) *Backfill {
+	cs := make(map[common.Address]indexer.Contract, len(contracts))
+	for _, c := range contracts {
+		cs[c.Address] = c
+	}
+	return &Backfill{
+		backend:    backend,
+		checkpoint: checkpoint,
+		handler:    handler,
+		contracts:  cs,
+	}
+}
+
+// Run scans the blocks from the checkpoint, or StartBlock when none was
+// saved, to EndBlock. It returns once all the blocks are scanned, or on the
+// first failure, after which it resumes from the last range saved.
+func (b *Backfill) Run(ctx context.Context) error {
+	topics, err := b.topics()
+	if err != nil {
+		return err
+	}
+
+	end := b.EndBlock
+	if end == 0 {
+		head, err := b.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "getting latest block")
+		}
+		end = head.Number.Uint64()
+	}
+
+	next, ok, err := b.checkpoint.Load()
+	if err != nil {
+		return errors.Wrap(err, "loading checkpoint")
+	}
+	if !ok {
+		next = b.StartBlock
+	}
+
+	size := b.RangeSize
+	if size == 0 {
+		size = DefaultRangeSize
+	}
+
+	addresses := make([]common.Address, 0, len(b.contracts))
+	for a := range b.contracts {
+		addresses = append(addresses, a)
+	}
+
+	for next <= end {
+		to := end
+		if end-next >= size {
+			to = next + size - 1
+		}
+
+		events, err := b.scan(ctx, next, to, addresses, topics)
+		if err != nil && ctx.Err() == nil && to > next {
+			size = (to - next + 1) / 2
+			b.logger().Warn("Filtering blocks failed, retrying with fewer blocks", "from", next, "to", to, "blocks", size, "err", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = b.save(ctx, next, to, events)
+		if err != nil {
+			return err
+		}
+		b.logger().Info("Backfilled blocks", "from", next, "to", to, "events", len(events), "remaining", end-to)
+		next = to + 1
+	}
+	return nil
+}
+
+// save hands the events of the range and saves the checkpoint, or commits
+// them together.
+func (b *Backfill) save(ctx context.Context, from, to uint64, events []indexer.Event) error {
+	if c, ok := b.checkpoint.(Committer); ok {
+		err := c.Commit(to+1, events)
+		if err != nil {
+			return errors.Wrapf(err, "committing events of blocks %d to %d", from, to)
+		}
+		if b.handler != nil && len(events) > 0 {
+			err = b.handler.HandleEvents(ctx, events)
+			if err != nil {
+				b.logger().Error("Handling committed events failed", "from", from, "to", to, "err", err)
+			}
+		}
+		return nil
+	}
+
+	if len(events) > 0 {
+		err := b.handler.HandleEvents(ctx, events)
+		if err != nil {
+			return errors.Wrapf(err, "handling events of blocks %d to %d", from, to)
+		}
+	}
+	err := b.checkpoint.Save(to + 1)
+	if err != nil {
+		return errors.Wrap(err, "saving checkpoint")
+	}
+	return nil
+}
+
+// scan returns the events of the blocks from and to, in chain order.
+func (b *Backfill) scan(ctx context.Context, from, to uint64, addresses []common.Address, topics [][]common.Hash) ([]indexer.Event, error) {
+	logs, err := b.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+		Topics:    topics,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "filtering logs of blocks %d to %d", from, to)
+	}
+
+	events := make([]indexer.Event, 0, len(logs))
+	for _, l := range logs {
+		e, err := indexer.NewEvent(b.contracts[l.Address], l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Position().Before(events[j].Position())
+	})
+	return events, nil
+}
+
+// topics returns the filter of the events scanned, nil for all of them.
+func (b *Backfill) topics() ([][]common.Hash, error) {
+	if len(b.Events) == 0 {
+		return nil, nil
+	}
+	var ids []common.Hash
+	for _, name := range b.Events {
+		found := false
+		for _, c := range b.contracts {
+			e, ok := c.ABI.Events[name]
+			if ok {
+				ids = append(ids, e.ID())
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("no contract emits %s events", name)
+		}
+	}
+	return [][]common.Hash{ids}, nil
+}
+
+func (b *Backfill) logger() logging.Logger {
+	return logging.Or(b.Logger)
+}
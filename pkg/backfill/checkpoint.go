@@ -0,0 +1,151 @@
+package backfill
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Checkpoint persists the progress of a backfill.
+type Checkpoint interface {
+	// Load returns the next block to scan, false when none was saved.
+	Load() (uint64, bool, error)
+	// Save records that the blocks before next were scanned.
+	Save(next uint64) error
+}
+
+// Committer is a Checkpoint storing the events of each range along with the
+// progress, atomically, so that the events of a range are stored exactly
+// once even when the backfill crashes.
+type Committer interface {
+	Checkpoint
+	// Commit stores the events and records that the blocks before next were
+	// scanned, all or nothing.
+	Commit(next uint64, events []indexer.Event) error
+}
+
+// StoreCheckpoint is a Committer appending the events to an indexer store,
+// whose head records the progress. The store must append the events and
+// advance its head atomically, as indexer.FileStore does.
+type StoreCheckpoint struct {
+	store indexer.Store
+}
+
+// NewStoreCheckpoint returns the checkpoint of the events of store.
+func NewStoreCheckpoint(store indexer.Store) *StoreCheckpoint {
+	return &StoreCheckpoint{store: store}
+}
+
+// Load implements Checkpoint.
+func (s *StoreCheckpoint) Load() (uint64, bool, error) {
+	head, ok := s.store.Head()
+	if !ok {
+		return 0, false, nil
+	}
+	return head + 1, true, nil
+}
+
+// Save implements Checkpoint.
+func (s *StoreCheckpoint) Save(next uint64) error {
+	return s.Commit(next, nil)
+}
+
+// Commit implements Committer.
+func (s *StoreCheckpoint) Commit(next uint64, events []indexer.Event) error {
+	if next == 0 {
+		return errors.New("no block scanned")
+	}
+	return s.store.Append(next-1, events)
+}
+
+// MemoryCheckpoint keeps the progress in memory.
+type MemoryCheckpoint struct {
+	mu    sync.Mutex
+	next  uint64
+	saved bool
+}
+
+// Load implements Checkpoint.
+func (m *MemoryCheckpoint) Load() (uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.next, m.saved, nil
+}
+
+// Save implements Checkpoint.
+func (m *MemoryCheckpoint) Save(next uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next, m.saved = next, true
+	return nil
+}
+
+// fileCheckpoint is the content of the file of a FileCheckpoint.
+type fileCheckpoint struct {
+	NextBlock uint64    `json:"next_block"`
+	Time      time.Time `json:"time"`
+}
+
+// FileCheckpoint keeps the progress in a JSON file, which is replaced
+// atomically on each save so that a crash never leaves it truncated.
+type FileCheckpoint struct {
+	path string
+}
+
+// NewFileCheckpoint returns the checkpoint stored in path, which is created
+// by the first save.
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+// Load implements Checkpoint.
+func (f *FileCheckpoint) Load() (uint64, bool, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap(err, "reading checkpoint")
+	}
+	var c fileCheckpoint
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "decoding checkpoint %s", f.path)
+	}
+	return c.NextBlock, true, nil
+}
+
+// Save implements Checkpoint.
+func (f *FileCheckpoint) Save(next uint64) error {
+	data, err := json.Marshal(fileCheckpoint{NextBlock: next, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating checkpoint")
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "writing checkpoint")
+	}
+	err = os.Rename(tmp.Name(), f.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "replacing checkpoint")
+	}
+	return nil
+}
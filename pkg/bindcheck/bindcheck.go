@@ -0,0 +1,159 @@
+// Package bindcheck finds the go-ethereum symbols removed or deprecated in
+// later releases that the generated bindings use, to know which bindings must
+// be regenerated before upgrading go-ethereum.
+//
+// The check is syntactic: the files are parsed, not type checked, so that it
+// runs on bindings which no longer compile against the new release.
+package bindcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Deprecated are the removed or deprecated symbols, by import path and name,
+// with what replaces them.
+var Deprecated = map[string]string{
+	"github.com/ethereum/go-ethereum/accounts/abi.U256":                              "removed, use common/math.U256Bytes",
+	"github.com/ethereum/go-ethereum/accounts/abi.ReadInteger":                       "removed, use Arguments.Unpack",
+	"github.com/ethereum/go-ethereum/accounts/abi/bind.Bind":                         "moved to accounts/abi/abigen",
+	"github.com/ethereum/go-ethereum/accounts/abi/bind.NewKeyedTransactor":           "deprecated, use NewKeyedTransactorWithChainID",
+	"github.com/ethereum/go-ethereum/accounts/abi/bind.NewTransactor":                "deprecated, use NewTransactorWithChainID",
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends.NewSimulatedBackend": "deprecated, use ethclient/simulated.NewBackend",
+}
+
+// Finding is a use of a deprecated symbol.
+type Finding struct {
+	Pos token.Position
+	// Symbol is the symbol as written in the file, e.g. abi.U256.
+	Symbol string
+	// Hint says what replaces the symbol.
+	Hint string
+	// Types are the bindings generated in the file, found from their ABI
+	// constants.
+	Types []string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s is %s", f.Pos, f.Symbol, f.Hint)
+}
+
+// Check walks the Go files under dir, tests excluded, and returns the uses of
+// the deprecated symbols sorted by position.
+func Check(dir string) ([]Finding, error) {
+	fset := token.NewFileSet()
+	var findings []Finding
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", path)
+		}
+		findings = append(findings, checkFile(fset, f)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return findings, nil
+}
+
+func checkFile(fset *token.FileSet, f *ast.File) []Finding {
+	imports := make(map[string]string)
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+
+	var findings []Finding
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		// Package names are left unresolved by the parser, unlike the
+		// local identifiers which may shadow them.
+		if !ok || x.Obj != nil {
+			return true
+		}
+		path, ok := imports[x.Name]
+		if !ok {
+			return true
+		}
+		hint, ok := Deprecated[path+"."+sel.Sel.Name]
+		if !ok {
+			return true
+		}
+		findings = append(findings, Finding{
+			Pos:    fset.Position(sel.Pos()),
+			Symbol: x.Name + "." + sel.Sel.Name,
+			Hint:   hint,
+		})
+		return true
+	})
+
+	types := bindingTypes(f)
+	for i := range findings {
+		findings[i].Types = types
+	}
+	return findings
+}
+
+// bindingTypes returns the types of the bindings generated by abigen in f,
+// which declares a <Type>ABI constant for each.
+func bindingTypes(f *ast.File) []string {
+	var types []string
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, s := range gen.Specs {
+			for _, name := range s.(*ast.ValueSpec).Names {
+				if strings.HasSuffix(name.Name, "ABI") && len(name.Name) > len("ABI") {
+					types = append(types, strings.TrimSuffix(name.Name, "ABI"))
+				}
+			}
+		}
+	}
+	return types
+}
+
+// Regenerate returns the files to regenerate with the binding types they
+// hold, from the findings of Check.
+func Regenerate(findings []Finding) map[string][]string {
+	files := make(map[string][]string)
+	for _, f := range findings {
+		files[f.Pos.Filename] = f.Types
+	}
+	return files
+}
@@ -0,0 +1,281 @@
+package bindings
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Errors of the role checks of the AccessClient.
+var (
+	ErrControllerStopped = errors.New("controller is stopped")
+	ErrNotOwner          = errors.New("not the owner of the controller")
+	ErrNotAdmin          = errors.New("not an admin")
+	ErrNotController     = errors.New("not a controller")
+)
+
+// Roles are the roles of an account in the Controller.
+type Roles struct {
+	Owner      bool `json:"owner"`
+	Admin      bool `json:"admin"`
+	Controller bool `json:"controller"`
+}
+
+// AccessClient is a high-level client of a deployed Controller, the contract
+// granting the admin and controller roles to the accounts operating the other
+// contracts.
+type AccessClient struct {
+	*Controller
+	address common.Address
+}
+
+// NewAccessClient binds the Controller deployed at address.
+func NewAccessClient(address common.Address, backend bind.ContractBackend) (*AccessClient, error) {
+	c, err := NewController(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding controller contract")
+	}
+	return &AccessClient{Controller: c, address: address}, nil
+}
+
+// Address returns the address of the Controller.
+func (c *AccessClient) Address() common.Address {
+	return c.address
+}
+
+// Roles returns the roles of an account. A stopped Controller grants no admin
+// nor controller role.
+func (c *AccessClient) Roles(ctx context.Context, account common.Address) (Roles, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	owner, err := c.Owner(opts)
+	if err != nil {
+		return Roles{}, errors.Wrap(err, "calling owner")
+	}
+	r := Roles{Owner: owner == account}
+	stopped, err := c.IsStopped(opts)
+	if err != nil {
+		return Roles{}, errors.Wrap(err, "calling isStopped")
+	}
+	if stopped {
+		return r, nil
+	}
+	r.Admin, err = c.IsAdmin(opts, account)
+	if err != nil {
+		return Roles{}, errors.Wrap(err, "calling isAdmin")
+	}
+	r.Controller, err = c.IsController(opts, account)
+	if err != nil {
+		return Roles{}, errors.Wrap(err, "calling isController")
+	}
+	return r, nil
+}
+
+// RequireAdmin fails with ErrNotAdmin unless the account is an admin, or with
+// ErrControllerStopped when the Controller is stopped.
+func (c *AccessClient) RequireAdmin(ctx context.Context, account common.Address) error {
+	return c.require(ctx, account, ErrNotAdmin, func(r Roles) bool { return r.Admin })
+}
+
+// RequireController fails with ErrNotController unless the account is a
+// controller, or with ErrControllerStopped when the Controller is stopped.
+func (c *AccessClient) RequireController(ctx context.Context, account common.Address) error {
+	return c.require(ctx, account, ErrNotController, func(r Roles) bool { return r.Controller })
+}
+
+func (c *AccessClient) require(ctx context.Context, account common.Address, missing error, has func(Roles) bool) error {
+	stopped, err := c.IsStopped(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return errors.Wrap(err, "calling isStopped")
+	}
+	if stopped {
+		return ErrControllerStopped
+	}
+	r, err := c.Roles(ctx, account)
+	if err != nil {
+		return err
+	}
+	if !has(r) {
+		return errors.Wrap(missing, account.Hex())
+	}
+	return nil
+}
+
+// Members returns the admins and controllers, sorted. The Controller does not
+// enumerate its roles, they are replayed from its events and checked against
+// its role counts.
+func (c *AccessClient) Members(ctx context.Context) (admins, controllers []common.Address, err error) {
+	filter := &bind.FilterOpts{Context: ctx}
+	call := &bind.CallOpts{Context: ctx}
+
+	var changes []roleChange
+	added, err := c.FilterAddedAdmin(filter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "filtering AddedAdmin events")
+	}
+	defer added.Close()
+	for added.Next() {
+		changes = append(changes, roleChange{block: added.Event.Raw.BlockNumber, index: added.Event.Raw.Index, admin: true, account: added.Event.Admin, added: true})
+	}
+	removed, err := c.FilterRemovedAdmin(filter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "filtering RemovedAdmin events")
+	}
+	defer removed.Close()
+	for removed.Next() {
+		changes = append(changes, roleChange{block: removed.Event.Raw.BlockNumber, index: removed.Event.Raw.Index, admin: true, account: removed.Event.Admin})
+	}
+	addedController, err := c.FilterAddedController(filter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "filtering AddedController events")
+	}
+	defer addedController.Close()
+	for addedController.Next() {
+		changes = append(changes, roleChange{block: addedController.Event.Raw.BlockNumber, index: addedController.Event.Raw.Index, account: addedController.Event.Controller, added: true})
+	}
+	removedController, err := c.FilterRemovedController(filter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "filtering RemovedController events")
+	}
+	defer removedController.Close()
+	for removedController.Next() {
+		changes = append(changes, roleChange{block: removedController.Event.Raw.BlockNumber, index: removedController.Event.Raw.Index, account: removedController.Event.Controller})
+	}
+	for _, it := range []interface{ Error() error }{added, removed, addedController, removedController} {
+		if it.Error() != nil {
+			return nil, nil, errors.Wrap(it.Error(), "reading controller events")
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].block != changes[j].block {
+			return changes[i].block < changes[j].block
+		}
+		return changes[i].index < changes[j].index
+	})
+	adminSet := make(map[common.Address]bool)
+	controllerSet := make(map[common.Address]bool)
+	for _, ch := range changes {
+		set := controllerSet
+		if ch.admin {
+			set = adminSet
+		}
+		if ch.added {
+			set[ch.account] = true
+		} else {
+			delete(set, ch.account)
+		}
+	}
+	admins, controllers = sortedAccounts(adminSet), sortedAccounts(controllerSet)
+
+	adminCount, err := c.AdminCount(call)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "calling adminCount")
+	}
+	controllerCount, err := c.ControllerCount(call)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "calling controllerCount")
+	}
+	if adminCount.Cmp(big.NewInt(int64(len(admins)))) != 0 || controllerCount.Cmp(big.NewInt(int64(len(controllers)))) != 0 {
+		return nil, nil, errors.Errorf("events account for %d admins and %d controllers, the contract has %s and %s", len(admins), len(controllers), adminCount, controllerCount)
+	}
+	return admins, controllers, nil
+}
+
+// roleChange is a role granted or revoked by an event of the Controller.
+type roleChange struct {
+	block   uint64
+	index   uint
+	admin   bool
+	account common.Address
+	added   bool
+}
+
+func sortedAccounts(set map[common.Address]bool) []common.Address {
+	accounts := make([]common.Address, 0, len(set))
+	for a := range set {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i][:], accounts[j][:]) < 0 })
+	return accounts
+}
+
+// GrantAdmin makes an account an admin. It fails without sending the
+// transaction when the Controller is stopped, when the sender is not its
+// owner or when the account already is an admin.
+func (c *AccessClient) GrantAdmin(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	err := c.checkChange(opts, true, account, func(r Roles) bool { return !r.Admin }, "already an admin")
+	if err != nil {
+		return nil, err
+	}
+	return c.AddAdmin(opts, account)
+}
+
+// RevokeAdmin removes an admin. It fails without sending the transaction when
+// the sender is not the owner or when the account is not an admin.
+func (c *AccessClient) RevokeAdmin(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	err := c.checkChange(opts, true, account, func(r Roles) bool { return r.Admin }, ErrNotAdmin.Error())
+	if err != nil {
+		return nil, err
+	}
+	return c.RemoveAdmin(opts, account)
+}
+
+// GrantController makes an account a controller. It fails without sending
+// the transaction when the Controller is stopped, when the sender is neither
+// an admin nor the owner or when the account already is a controller.
+func (c *AccessClient) GrantController(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	err := c.checkChange(opts, false, account, func(r Roles) bool { return !r.Controller }, "already a controller")
+	if err != nil {
+		return nil, err
+	}
+	return c.AddController(opts, account)
+}
+
+// RevokeController removes a controller. It fails without sending the
+// transaction when the sender is neither an admin nor the owner or when the
+// account is not a controller.
+func (c *AccessClient) RevokeController(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	err := c.checkChange(opts, false, account, func(r Roles) bool { return r.Controller }, ErrNotController.Error())
+	if err != nil {
+		return nil, err
+	}
+	return c.RemoveController(opts, account)
+}
+
+// checkChange checks that the sender may change the role of account, only the
+// owner changing the admins, and that valid holds for the roles of account.
+// The roles cannot be read while the Controller is stopped, no change is
+// allowed then.
+func (c *AccessClient) checkChange(opts *bind.TransactOpts, ownerOnly bool, account common.Address, valid func(Roles) bool, invalid string) error {
+	stopped, err := c.IsStopped(&bind.CallOpts{Context: opts.Context})
+	if err != nil {
+		return errors.Wrap(err, "calling isStopped")
+	}
+	if stopped {
+		return ErrControllerStopped
+	}
+	sender, err := c.Roles(opts.Context, opts.From)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ownerOnly && !sender.Owner:
+		return errors.Wrap(ErrNotOwner, opts.From.Hex())
+	case !sender.Owner && !sender.Admin:
+		return errors.Wrapf(ErrNotAdmin, "%s is neither an admin nor the owner", opts.From.Hex())
+	}
+	r, err := c.Roles(opts.Context, account)
+	if err != nil {
+		return err
+	}
+	if !valid(r) {
+		return errors.Errorf("%s is %s", account.Hex(), invalid)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package bindings
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The capability interfaces group the methods the contracts share through
+// the libraries they inherit, so that code such as auditors and reporters can
+// operate over any binding implementing them. The assertions below fail to
+// compile when a regenerated binding no longer conforms.
+
+// OwnableCaller reads the owner of a contract inheriting Ownable.
+type OwnableCaller interface {
+	Owner(opts *bind.CallOpts) (common.Address, error)
+	IsTransferable(opts *bind.CallOpts) (bool, error)
+}
+
+// OwnableTransactor changes the owner of a contract inheriting Ownable.
+type OwnableTransactor interface {
+	TransferOwnership(opts *bind.TransactOpts, _account common.Address, _transferable bool) (*types.Transaction, error)
+	RenounceOwnership(opts *bind.TransactOpts) (*types.Transaction, error)
+}
+
+// ENSResolvableCaller reads the ENS registry a contract inheriting
+// ENSResolvable resolves its dependencies with.
+type ENSResolvableCaller interface {
+	EnsRegistry(opts *bind.CallOpts) (common.Address, error)
+}
+
+// ControllableCaller reads the ENS node of the controller of a contract
+// inheriting Controllable.
+type ControllableCaller interface {
+	ENSResolvableCaller
+	ControllerNode(opts *bind.CallOpts) ([32]byte, error)
+}
+
+// TransferrableTransactor claims the assets held by a contract inheriting
+// Transferrable.
+type TransferrableTransactor interface {
+	Claim(opts *bind.TransactOpts, _to common.Address, _asset common.Address, _amount *big.Int) (*types.Transaction, error)
+}
+
+// ERC20Caller reads the state of an ERC20 token.
+type ERC20Caller interface {
+	BalanceOf(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error)
+	Allowance(opts *bind.CallOpts, arg0 common.Address, arg1 common.Address) (*big.Int, error)
+	TotalSupply(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// ERC20Transactor transfers the tokens of an ERC20 token.
+type ERC20Transactor interface {
+	Transfer(opts *bind.TransactOpts, _to common.Address, _value *big.Int) (*types.Transaction, error)
+	TransferFrom(opts *bind.TransactOpts, _from common.Address, _to common.Address, _value *big.Int) (*types.Transaction, error)
+	Approve(opts *bind.TransactOpts, _spender common.Address, _value *big.Int) (*types.Transaction, error)
+}
+
+// ERC165Caller probes a contract implementing ERC-165 for an interface, see
+// SupportsInterfaces.
+type ERC165Caller interface {
+	SupportsInterface(opts *bind.CallOpts, _interfaceID [4]byte) (bool, error)
+}
+
+var (
+	_ OwnableCaller = (*ControllerCaller)(nil)
+	_ OwnableCaller = (*WalletCaller)(nil)
+
+	_ OwnableTransactor = (*ControllerTransactor)(nil)
+	_ OwnableTransactor = (*WalletTransactor)(nil)
+
+	_ ControllableCaller = (*HolderCaller)(nil)
+	_ ControllableCaller = (*LicenceCaller)(nil)
+	_ ControllableCaller = (*OracleCaller)(nil)
+	_ ControllableCaller = (*TokenWhitelistCaller)(nil)
+	_ ControllableCaller = (*WalletCaller)(nil)
+	_ ControllableCaller = (*WalletCacheCaller)(nil)
+	_ ControllableCaller = (*WalletDeployerCaller)(nil)
+
+	_ TransferrableTransactor = (*ControllerTransactor)(nil)
+	_ TransferrableTransactor = (*LicenceTransactor)(nil)
+	_ TransferrableTransactor = (*OracleTransactor)(nil)
+	_ TransferrableTransactor = (*TokenWhitelistTransactor)(nil)
+
+	_ ERC165Caller = (*WalletCaller)(nil)
+)
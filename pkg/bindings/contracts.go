@@ -0,0 +1,30 @@
+package bindings
+
+import "github.com/ethereum/go-ethereum/accounts/abi"
+
+//go:generate go run ../../tools/abigen -out .
+
+// ContractABIs maps the names used to refer to the contracts in configuration
+// files to their ABI.
+var ContractABIs = map[string]string{
+	"controller":      ControllerABI,
+	"holder":          HolderABI,
+	"licence":         LicenceABI,
+	"oracle":          OracleABI,
+	"token_whitelist": TokenWhitelistABI,
+	"wallet":          WalletABI,
+	"wallet_cache":    WalletCacheABI,
+	"wallet_deployer": WalletDeployerABI,
+}
+
+// ContractParsedABIs maps the names of ContractABIs to their parsed ABI.
+var ContractParsedABIs = map[string]abi.ABI{
+	"controller":      ControllerParsedABI,
+	"holder":          HolderParsedABI,
+	"licence":         LicenceParsedABI,
+	"oracle":          OracleParsedABI,
+	"token_whitelist": TokenWhitelistParsedABI,
+	"wallet":          WalletParsedABI,
+	"wallet_cache":    WalletCacheParsedABI,
+	"wallet_deployer": WalletDeployerParsedABI,
+}
@@ -1763,3 +1763,205 @@ func (_Controller *ControllerFilterer) ParseTransferredOwnership(log types.Log)
 	}
 	return event, nil
 }
+
+// ControllerParsedABI is the parsed ControllerABI.
+var ControllerParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(ControllerABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Controller, the hash of their signature.
+var (
+	// ControllerAddedAdminTopic is the topic of event AddedAdmin(address _sender, address _admin).
+	ControllerAddedAdminTopic = common.HexToHash("0xc58b647b8ba5a8cab2f11f32673636cc1061324240972ed05e8cc005b81a4b7a")
+	// ControllerAddedControllerTopic is the topic of event AddedController(address _sender, address _controller).
+	ControllerAddedControllerTopic = common.HexToHash("0xb890d5abdcd5c2b61ce8bbc2cf6af9b6d7f7451830cbc85037cbdd182c86fe1d")
+	// ControllerClaimedTopic is the topic of event Claimed(address _to, address _asset, uint256 _amount).
+	ControllerClaimedTopic = common.HexToHash("0xf7a40077ff7a04c7e61f6f26fb13774259ddf1b6bce9ecf26a8276cdd3992683")
+	// ControllerLockedOwnershipTopic is the topic of event LockedOwnership(address _locked).
+	ControllerLockedOwnershipTopic = common.HexToHash("0x808639ff9c8e4732d60b6c2330de498035416d229f27a77d259680895efec122")
+	// ControllerRemovedAdminTopic is the topic of event RemovedAdmin(address _sender, address _admin).
+	ControllerRemovedAdminTopic = common.HexToHash("0x787a2e12f4a55b658b8f573c32432ee11a5e8b51677d1e1e937aaf6a0bb5776e")
+	// ControllerRemovedControllerTopic is the topic of event RemovedController(address _sender, address _controller).
+	ControllerRemovedControllerTopic = common.HexToHash("0xb6a283aaede08e15ef55c74e3014e30eb0c0040d4b156cccb77391268ea37394")
+	// ControllerStartedTopic is the topic of event Started(address _sender).
+	ControllerStartedTopic = common.HexToHash("0x27029695aa5f602a4ee81f4c32dfa86e562f200a17966496f3a7c3f2ec0f9417")
+	// ControllerStoppedTopic is the topic of event Stopped(address _sender).
+	ControllerStoppedTopic = common.HexToHash("0x55c4adf1f68f084b809304657594a92ba835ada8d3b5340955bf05746723c05b")
+	// ControllerTransferredOwnershipTopic is the topic of event TransferredOwnership(address _from, address _to).
+	ControllerTransferredOwnershipTopic = common.HexToHash("0x850b3df64837d7d518b45f5aa64d104652c3b80eb5b34a8e3d9eb666cb7cdea5")
+)
+
+// ParseAddedAdminFromReceipt parses the AddedAdmin events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedAdmin(address _sender, address _admin)
+func (_Controller *ControllerFilterer) ParseAddedAdminFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerAddedAdmin, error) {
+	var events []*ControllerAddedAdmin
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerAddedAdminTopic {
+			continue
+		}
+		event, err := _Controller.ParseAddedAdmin(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseAddedControllerFromReceipt parses the AddedController events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedController(address _sender, address _controller)
+func (_Controller *ControllerFilterer) ParseAddedControllerFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerAddedController, error) {
+	var events []*ControllerAddedController
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerAddedControllerTopic {
+			continue
+		}
+		event, err := _Controller.ParseAddedController(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseClaimedFromReceipt parses the Claimed events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Claimed(address _to, address _asset, uint256 _amount)
+func (_Controller *ControllerFilterer) ParseClaimedFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerClaimed, error) {
+	var events []*ControllerClaimed
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerClaimedTopic {
+			continue
+		}
+		event, err := _Controller.ParseClaimed(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseLockedOwnershipFromReceipt parses the LockedOwnership events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event LockedOwnership(address _locked)
+func (_Controller *ControllerFilterer) ParseLockedOwnershipFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerLockedOwnership, error) {
+	var events []*ControllerLockedOwnership
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerLockedOwnershipTopic {
+			continue
+		}
+		event, err := _Controller.ParseLockedOwnership(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedAdminFromReceipt parses the RemovedAdmin events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedAdmin(address _sender, address _admin)
+func (_Controller *ControllerFilterer) ParseRemovedAdminFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerRemovedAdmin, error) {
+	var events []*ControllerRemovedAdmin
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerRemovedAdminTopic {
+			continue
+		}
+		event, err := _Controller.ParseRemovedAdmin(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedControllerFromReceipt parses the RemovedController events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedController(address _sender, address _controller)
+func (_Controller *ControllerFilterer) ParseRemovedControllerFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerRemovedController, error) {
+	var events []*ControllerRemovedController
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerRemovedControllerTopic {
+			continue
+		}
+		event, err := _Controller.ParseRemovedController(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseStartedFromReceipt parses the Started events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Started(address _sender)
+func (_Controller *ControllerFilterer) ParseStartedFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerStarted, error) {
+	var events []*ControllerStarted
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerStartedTopic {
+			continue
+		}
+		event, err := _Controller.ParseStarted(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseStoppedFromReceipt parses the Stopped events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Stopped(address _sender)
+func (_Controller *ControllerFilterer) ParseStoppedFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerStopped, error) {
+	var events []*ControllerStopped
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerStoppedTopic {
+			continue
+		}
+		event, err := _Controller.ParseStopped(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferredOwnershipFromReceipt parses the TransferredOwnership events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event TransferredOwnership(address _from, address _to)
+func (_Controller *ControllerFilterer) ParseTransferredOwnershipFromReceipt(address common.Address, receipt *types.Receipt) ([]*ControllerTransferredOwnership, error) {
+	var events []*ControllerTransferredOwnership
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ControllerTransferredOwnershipTopic {
+			continue
+		}
+		event, err := _Controller.ParseTransferredOwnership(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
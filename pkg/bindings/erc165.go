@@ -0,0 +1,86 @@
+package bindings
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+)
+
+// The IDs of the interfaces probed with SupportsInterfaces.
+var (
+	InterfaceERC165           = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	InterfaceERC721           = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	InterfaceERC721Metadata   = [4]byte{0x5b, 0x5e, 0x13, 0x9f}
+	InterfaceERC721Enumerable = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+	InterfaceERC721Receiver   = [4]byte{0x15, 0x0b, 0x7a, 0x02}
+	InterfaceERC1155          = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+// invalidInterface is the interface ID no ERC-165 contract supports.
+var invalidInterface = [4]byte{0xff, 0xff, 0xff, 0xff}
+
+// Capabilities tells which interfaces a contract supports, by interface ID.
+type Capabilities map[[4]byte]bool
+
+// Supports tells whether the contract supports the interface.
+func (c Capabilities) Supports(id [4]byte) bool {
+	return c[id]
+}
+
+// SupportsInterfaces probes the contract of caller for the interfaces, e.g.
+// before treating an arbitrary address as an ERC-721:
+//
+//	c, err := bindings.NewWalletCaller(address, backend)
+//	...
+//	capabilities, err := bindings.SupportsInterfaces(opts, c, bindings.InterfaceERC721, bindings.InterfaceERC721Metadata)
+//
+// The interfaces are probed in one pass, the calls sent concurrently, along
+// with the probes of ERC-165 itself: a contract not implementing ERC-165, or
+// which answers true for the invalid interface 0xffffffff, supports none of
+// them. A call reverting or an address without code supports none either,
+// only the errors of the node are returned.
+func SupportsInterfaces(opts *bind.CallOpts, caller ERC165Caller, ids ...[4]byte) (Capabilities, error) {
+	probes := append([][4]byte{InterfaceERC165, invalidInterface}, ids...)
+	supported := make([]bool, len(probes))
+	errs := make([]error, len(probes))
+	var wg sync.WaitGroup
+	for i, id := range probes {
+		wg.Add(1)
+		go func(i int, id [4]byte) {
+			defer wg.Done()
+			supported[i], errs[i] = caller.SupportsInterface(opts, id)
+			if notSupported(errs[i]) {
+				supported[i], errs[i] = false, nil
+			}
+		}(i, id)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "probing interface %#x", probes[i])
+		}
+	}
+
+	erc165 := supported[0] && !supported[1]
+	c := make(Capabilities, len(ids))
+	for i, id := range ids {
+		c[id] = erc165 && supported[i+2]
+	}
+	return c, nil
+}
+
+// notSupported tells whether the error of a call to supportsInterface means
+// the contract does not implement it: the call reverted, returned nothing or
+// the address has no code.
+func notSupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == bind.ErrNoCode {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "unmarshall an empty string")
+}
@@ -935,3 +935,100 @@ func (_ENSRegistry *ENSRegistryFilterer) ParseTransfer(log types.Log) (*ENSRegis
 	}
 	return event, nil
 }
+
+// ENSRegistryParsedABI is the parsed ENSRegistryABI.
+var ENSRegistryParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(ENSRegistryABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of ENSRegistry, the hash of their signature.
+var (
+	// ENSRegistryNewOwnerTopic is the topic of event NewOwner(bytes32 indexed node, bytes32 indexed label, address owner).
+	ENSRegistryNewOwnerTopic = common.HexToHash("0xce0457fe73731f824cc272376169235128c118b49d344817417c6d108d155e82")
+	// ENSRegistryNewResolverTopic is the topic of event NewResolver(bytes32 indexed node, address resolver).
+	ENSRegistryNewResolverTopic = common.HexToHash("0x335721b01866dc23fbee8b6b2c7b1e14d6f05c28cd35a2c934239f94095602a0")
+	// ENSRegistryNewTTLTopic is the topic of event NewTTL(bytes32 indexed node, uint64 ttl).
+	ENSRegistryNewTTLTopic = common.HexToHash("0x1d4f9bbfc9cab89d66e1a1562f2233ccbf1308cb4f63de2ead5787adddb8fa68")
+	// ENSRegistryTransferTopic is the topic of event Transfer(bytes32 indexed node, address owner).
+	ENSRegistryTransferTopic = common.HexToHash("0xd4735d920b0f87494915f556dd9b54c8f309026070caea5c737245152564d266")
+)
+
+// ParseNewOwnerFromReceipt parses the NewOwner events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event NewOwner(bytes32 indexed node, bytes32 indexed label, address owner)
+func (_ENSRegistry *ENSRegistryFilterer) ParseNewOwnerFromReceipt(address common.Address, receipt *types.Receipt) ([]*ENSRegistryNewOwner, error) {
+	var events []*ENSRegistryNewOwner
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ENSRegistryNewOwnerTopic {
+			continue
+		}
+		event, err := _ENSRegistry.ParseNewOwner(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseNewResolverFromReceipt parses the NewResolver events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event NewResolver(bytes32 indexed node, address resolver)
+func (_ENSRegistry *ENSRegistryFilterer) ParseNewResolverFromReceipt(address common.Address, receipt *types.Receipt) ([]*ENSRegistryNewResolver, error) {
+	var events []*ENSRegistryNewResolver
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ENSRegistryNewResolverTopic {
+			continue
+		}
+		event, err := _ENSRegistry.ParseNewResolver(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseNewTTLFromReceipt parses the NewTTL events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event NewTTL(bytes32 indexed node, uint64 ttl)
+func (_ENSRegistry *ENSRegistryFilterer) ParseNewTTLFromReceipt(address common.Address, receipt *types.Receipt) ([]*ENSRegistryNewTTL, error) {
+	var events []*ENSRegistryNewTTL
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ENSRegistryNewTTLTopic {
+			continue
+		}
+		event, err := _ENSRegistry.ParseNewTTL(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferFromReceipt parses the Transfer events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Transfer(bytes32 indexed node, address owner)
+func (_ENSRegistry *ENSRegistryFilterer) ParseTransferFromReceipt(address common.Address, receipt *types.Receipt) ([]*ENSRegistryTransfer, error) {
+	var events []*ENSRegistryTransfer
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != ENSRegistryTransferTopic {
+			continue
+		}
+		event, err := _ENSRegistry.ParseTransfer(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
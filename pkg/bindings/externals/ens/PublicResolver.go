@@ -1793,3 +1793,184 @@ func (_PublicResolver *PublicResolverFilterer) ParseTextChanged(log types.Log) (
 	}
 	return event, nil
 }
+
+// PublicResolverParsedABI is the parsed PublicResolverABI.
+var PublicResolverParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(PublicResolverABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of PublicResolver, the hash of their signature.
+var (
+	// PublicResolverABIChangedTopic is the topic of event ABIChanged(bytes32 indexed node, uint256 indexed contentType).
+	PublicResolverABIChangedTopic = common.HexToHash("0xaa121bbeef5f32f5961a2a28966e769023910fc9479059ee3495d4c1a696efe3")
+	// PublicResolverAddrChangedTopic is the topic of event AddrChanged(bytes32 indexed node, address a).
+	PublicResolverAddrChangedTopic = common.HexToHash("0x52d7d861f09ab3d26239d492e8968629f95e9e318cf0b73bfddc441522a15fd2")
+	// PublicResolverAuthorisationChangedTopic is the topic of event AuthorisationChanged(bytes32 indexed node, address indexed owner, address indexed target, bool isAuthorised).
+	PublicResolverAuthorisationChangedTopic = common.HexToHash("0xe1c5610a6e0cbe10764ecd182adcef1ec338dc4e199c99c32ce98f38e12791df")
+	// PublicResolverContenthashChangedTopic is the topic of event ContenthashChanged(bytes32 indexed node, bytes hash).
+	PublicResolverContenthashChangedTopic = common.HexToHash("0xe379c1624ed7e714cc0937528a32359d69d5281337765313dba4e081b72d7578")
+	// PublicResolverInterfaceChangedTopic is the topic of event InterfaceChanged(bytes32 indexed node, bytes4 indexed interfaceID, address implementer).
+	PublicResolverInterfaceChangedTopic = common.HexToHash("0x7c69f06bea0bdef565b709e93a147836b0063ba2dd89f02d0b7e8d931e6a6daa")
+	// PublicResolverNameChangedTopic is the topic of event NameChanged(bytes32 indexed node, string name).
+	PublicResolverNameChangedTopic = common.HexToHash("0xb7d29e911041e8d9b843369e890bcb72c9388692ba48b65ac54e7214c4c348f7")
+	// PublicResolverPubkeyChangedTopic is the topic of event PubkeyChanged(bytes32 indexed node, bytes32 x, bytes32 y).
+	PublicResolverPubkeyChangedTopic = common.HexToHash("0x1d6f5e03d3f63eb58751986629a5439baee5079ff04f345becb66e23eb154e46")
+	// PublicResolverTextChangedTopic is the topic of event TextChanged(bytes32 indexed node, string indexedKey, string key).
+	PublicResolverTextChangedTopic = common.HexToHash("0xd8c9334b1a9c2f9da342a0a2b32629c1a229b6445dad78947f674b44444a7550")
+)
+
+// ParseABIChangedFromReceipt parses the ABIChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event ABIChanged(bytes32 indexed node, uint256 indexed contentType)
+func (_PublicResolver *PublicResolverFilterer) ParseABIChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverABIChanged, error) {
+	var events []*PublicResolverABIChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverABIChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseABIChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseAddrChangedFromReceipt parses the AddrChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddrChanged(bytes32 indexed node, address a)
+func (_PublicResolver *PublicResolverFilterer) ParseAddrChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverAddrChanged, error) {
+	var events []*PublicResolverAddrChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverAddrChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseAddrChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseAuthorisationChangedFromReceipt parses the AuthorisationChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AuthorisationChanged(bytes32 indexed node, address indexed owner, address indexed target, bool isAuthorised)
+func (_PublicResolver *PublicResolverFilterer) ParseAuthorisationChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverAuthorisationChanged, error) {
+	var events []*PublicResolverAuthorisationChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverAuthorisationChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseAuthorisationChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseContenthashChangedFromReceipt parses the ContenthashChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event ContenthashChanged(bytes32 indexed node, bytes hash)
+func (_PublicResolver *PublicResolverFilterer) ParseContenthashChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverContenthashChanged, error) {
+	var events []*PublicResolverContenthashChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverContenthashChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseContenthashChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseInterfaceChangedFromReceipt parses the InterfaceChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event InterfaceChanged(bytes32 indexed node, bytes4 indexed interfaceID, address implementer)
+func (_PublicResolver *PublicResolverFilterer) ParseInterfaceChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverInterfaceChanged, error) {
+	var events []*PublicResolverInterfaceChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverInterfaceChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseInterfaceChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseNameChangedFromReceipt parses the NameChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event NameChanged(bytes32 indexed node, string name)
+func (_PublicResolver *PublicResolverFilterer) ParseNameChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverNameChanged, error) {
+	var events []*PublicResolverNameChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverNameChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseNameChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParsePubkeyChangedFromReceipt parses the PubkeyChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event PubkeyChanged(bytes32 indexed node, bytes32 x, bytes32 y)
+func (_PublicResolver *PublicResolverFilterer) ParsePubkeyChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverPubkeyChanged, error) {
+	var events []*PublicResolverPubkeyChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverPubkeyChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParsePubkeyChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTextChangedFromReceipt parses the TextChanged events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event TextChanged(bytes32 indexed node, string indexedKey, string key)
+func (_PublicResolver *PublicResolverFilterer) ParseTextChangedFromReceipt(address common.Address, receipt *types.Receipt) ([]*PublicResolverTextChanged, error) {
+	var events []*PublicResolverTextChanged
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != PublicResolverTextChangedTopic {
+			continue
+		}
+		event, err := _PublicResolver.ParseTextChanged(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
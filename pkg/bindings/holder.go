@@ -738,3 +738,79 @@ func (_Holder *HolderFilterer) ParseReceived(log types.Log) (*HolderReceived, er
 	}
 	return event, nil
 }
+
+// HolderParsedABI is the parsed HolderABI.
+var HolderParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(HolderABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Holder, the hash of their signature.
+var (
+	// HolderCashAndBurnedTopic is the topic of event CashAndBurned(address _to, address _asset, uint256 _amount).
+	HolderCashAndBurnedTopic = common.HexToHash("0x43e074e3351faae8657cc314cf10440a8e7a87ce5092ee4bf9baf56f73fe6c56")
+	// HolderClaimedTopic is the topic of event Claimed(address _to, address _asset, uint256 _amount).
+	HolderClaimedTopic = common.HexToHash("0xf7a40077ff7a04c7e61f6f26fb13774259ddf1b6bce9ecf26a8276cdd3992683")
+	// HolderReceivedTopic is the topic of event Received(address _from, uint256 _amount).
+	HolderReceivedTopic = common.HexToHash("0x88a5966d370b9919b20f3e2c13ff65706f196a4e32cc2c12bf57088f88525874")
+)
+
+// ParseCashAndBurnedFromReceipt parses the CashAndBurned events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event CashAndBurned(address _to, address _asset, uint256 _amount)
+func (_Holder *HolderFilterer) ParseCashAndBurnedFromReceipt(address common.Address, receipt *types.Receipt) ([]*HolderCashAndBurned, error) {
+	var events []*HolderCashAndBurned
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != HolderCashAndBurnedTopic {
+			continue
+		}
+		event, err := _Holder.ParseCashAndBurned(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseClaimedFromReceipt parses the Claimed events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Claimed(address _to, address _asset, uint256 _amount)
+func (_Holder *HolderFilterer) ParseClaimedFromReceipt(address common.Address, receipt *types.Receipt) ([]*HolderClaimed, error) {
+	var events []*HolderClaimed
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != HolderClaimedTopic {
+			continue
+		}
+		event, err := _Holder.ParseClaimed(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseReceivedFromReceipt parses the Received events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Received(address _from, uint256 _amount)
+func (_Holder *HolderFilterer) ParseReceivedFromReceipt(address common.Address, receipt *types.Receipt) ([]*HolderReceived, error) {
+	var events []*HolderReceived
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != HolderReceivedTopic {
+			continue
+		}
+		event, err := _Holder.ParseReceived(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,221 @@
+package bindings
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// holderAssetsABI is the part of the ERC20 and burner token ABIs used to read
+// the assets of the Holder.
+const holderAssetsABI = `[
+{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"currentSupply","outputs":[{"name":"","type":"uint256"}],"type":"function"}
+]`
+
+// HolderBackend is the backend of a HolderClient, which reads the ETH balance
+// of the Holder.
+type HolderBackend interface {
+	bind.ContractBackend
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+}
+
+// HolderAsset is an amount of a token, the zero address for ETH, held by the
+// Holder or paid out of it.
+type HolderAsset struct {
+	Token  common.Address `json:"token"`
+	Amount *big.Int       `json:"amount"`
+}
+
+// Redemption is a payout of the Holder to a TKN holder burning their TKN.
+type Redemption struct {
+	To          common.Address `json:"to"`
+	Asset       common.Address `json:"asset"`
+	Amount      *big.Int       `json:"amount"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	BlockNumber uint64         `json:"block_number"`
+}
+
+// HolderClient is a high-level client of a deployed Holder, the contract
+// backing TKN with the redeemable tokens of the token whitelist.
+type HolderClient struct {
+	*Holder
+	address   common.Address
+	backend   HolderBackend
+	whitelist *TokenWhitelistClient
+	assets    abi.ABI
+}
+
+// NewHolderClient binds the Holder deployed at address and the token whitelist
+// listing its redeemable tokens.
+func NewHolderClient(address, tokenWhitelist common.Address, backend HolderBackend) (*HolderClient, error) {
+	h, err := NewHolder(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding holder contract")
+	}
+	w, err := NewTokenWhitelistClient(tokenWhitelist, backend)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(holderAssetsABI))
+	if err != nil {
+		return nil, err
+	}
+	return &HolderClient{Holder: h, address: address, backend: backend, whitelist: w, assets: parsed}, nil
+}
+
+// Address returns the address of the Holder.
+func (c *HolderClient) Address() common.Address {
+	return c.address
+}
+
+// Balance returns the balance of the Holder in a token, the zero address for
+// ETH.
+func (c *HolderClient) Balance(ctx context.Context, token common.Address) (*big.Int, error) {
+	if token == ETH {
+		b, err := c.backend.BalanceAt(ctx, c.address, nil)
+		return b, errors.Wrap(err, "getting ETH balance")
+	}
+	b := new(big.Int)
+	err := bind.NewBoundContract(token, c.assets, c.backend, nil, nil).Call(&bind.CallOpts{Context: ctx}, &b, "balanceOf", c.address)
+	return b, errors.Wrapf(err, "getting balance of token %s", token.Hex())
+}
+
+// Redeemable returns the balances of the Holder in the redeemable tokens,
+// which are paid out to the TKN holders burning their TKN.
+func (c *HolderClient) Redeemable(ctx context.Context) ([]HolderAsset, error) {
+	tokens, err := c.whitelist.RedeemableTokens(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting redeemable tokens")
+	}
+	return c.balances(ctx, tokens)
+}
+
+// Claimable returns the balances of the Holder in the tokens, which must not
+// be redeemable, that an admin can claim. Tokens without a balance are left
+// out.
+func (c *HolderClient) Claimable(ctx context.Context, tokens ...common.Address) ([]HolderAsset, error) {
+	err := c.checkNonRedeemable(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := c.balances(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	claimable := balances[:0]
+	for _, b := range balances {
+		if b.Amount.Sign() > 0 {
+			claimable = append(claimable, b)
+		}
+	}
+	return claimable, nil
+}
+
+// Share returns the assets paid out for burning an amount of TKN, as the
+// Holder computes them: each redeemable balance times the amount over the
+// supply of TKN before the burn.
+func (c *HolderClient) Share(ctx context.Context, tkn *big.Int) ([]HolderAsset, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	burner, err := c.Burner(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting burner")
+	}
+	supply := new(big.Int)
+	err = bind.NewBoundContract(burner, c.assets, c.backend, nil, nil).Call(opts, &supply, "currentSupply")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting TKN supply")
+	}
+	if tkn.Cmp(supply) > 0 {
+		return nil, errors.Errorf("%s TKN exceeds the supply of %s", tkn, supply)
+	}
+	redeemable, err := c.Redeemable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range redeemable {
+		a := redeemable[i].Amount.Mul(redeemable[i].Amount, tkn)
+		if supply.Sign() > 0 {
+			a.Div(a, supply)
+		}
+	}
+	return redeemable, nil
+}
+
+// Claim sends the whole balance of the Holder in the tokens to an address.
+// It fails without sending the transaction when a token is redeemable. Only
+// admins may claim.
+func (c *HolderClient) Claim(opts *bind.TransactOpts, to common.Address, tokens ...common.Address) (*types.Transaction, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to claim")
+	}
+	if to == (common.Address{}) {
+		return nil, ErrZeroDestination
+	}
+	err := c.checkNonRedeemable(opts.Context, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return c.NonRedeemableTokenClaim(opts, to, tokens)
+}
+
+// Redemptions returns the payouts of the Holder in the block range, to the
+// given addresses only when there are some.
+func (c *HolderClient) Redemptions(opts *bind.FilterOpts, to ...common.Address) ([]Redemption, error) {
+	it, err := c.FilterCashAndBurned(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering redemptions")
+	}
+	defer it.Close()
+
+	recipients := make(map[common.Address]bool, len(to))
+	for _, a := range to {
+		recipients[a] = true
+	}
+	var redemptions []Redemption
+	for it.Next() {
+		e := it.Event
+		if len(recipients) > 0 && !recipients[e.To] {
+			continue
+		}
+		redemptions = append(redemptions, Redemption{
+			To:          e.To,
+			Asset:       e.Asset,
+			Amount:      e.Amount,
+			TxHash:      e.Raw.TxHash,
+			BlockNumber: e.Raw.BlockNumber,
+		})
+	}
+	return redemptions, errors.Wrap(it.Error(), "filtering redemptions")
+}
+
+func (c *HolderClient) balances(ctx context.Context, tokens []common.Address) ([]HolderAsset, error) {
+	assets := make([]HolderAsset, len(tokens))
+	for i, t := range tokens {
+		b, err := c.Balance(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		assets[i] = HolderAsset{Token: t, Amount: b}
+	}
+	return assets, nil
+}
+
+func (c *HolderClient) checkNonRedeemable(ctx context.Context, tokens []common.Address) error {
+	for _, t := range tokens {
+		redeemable, err := c.whitelist.IsRedeemable(ctx, t)
+		if err != nil {
+			return err
+		}
+		if redeemable {
+			return errors.Errorf("token %s is redeemable, it cannot be claimed", t.Hex())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package internals
+
+import "github.com/tokencard/contracts/v2/pkg/bindings"
+
+var _ bindings.ENSResolvableCaller = (*TokenWhitelistableCaller)(nil)
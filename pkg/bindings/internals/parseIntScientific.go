@@ -188,3 +188,12 @@ func (_ParseIntScientific *ParseIntScientificTransactorRaw) Transfer(opts *bind.
 func (_ParseIntScientific *ParseIntScientificTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
 	return _ParseIntScientific.Contract.contract.Transact(opts, method, params...)
 }
+
+// ParseIntScientificParsedABI is the parsed ParseIntScientificABI.
+var ParseIntScientificParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(ParseIntScientificABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
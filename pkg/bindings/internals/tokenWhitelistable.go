@@ -223,3 +223,12 @@ func (_TokenWhitelistable *TokenWhitelistableSession) TokenWhitelistNode() ([32]
 func (_TokenWhitelistable *TokenWhitelistableCallerSession) TokenWhitelistNode() ([32]byte, error) {
 	return _TokenWhitelistable.Contract.TokenWhitelistNode(&_TokenWhitelistable.CallOpts)
 }
+
+// TokenWhitelistableParsedABI is the parsed TokenWhitelistableABI.
+var TokenWhitelistableParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(TokenWhitelistableABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
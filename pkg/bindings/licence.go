@@ -1829,3 +1829,184 @@ func (_Licence *LicenceFilterer) ParseUpdatedTokenHolder(log types.Log) (*Licenc
 	}
 	return event, nil
 }
+
+// LicenceParsedABI is the parsed LicenceABI.
+var LicenceParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(LicenceABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Licence, the hash of their signature.
+var (
+	// LicenceClaimedTopic is the topic of event Claimed(address _to, address _asset, uint256 _amount).
+	LicenceClaimedTopic = common.HexToHash("0xf7a40077ff7a04c7e61f6f26fb13774259ddf1b6bce9ecf26a8276cdd3992683")
+	// LicenceTransferredToCryptoFloatTopic is the topic of event TransferredToCryptoFloat(address _from, address _to, address _asset, uint256 _amount).
+	LicenceTransferredToCryptoFloatTopic = common.HexToHash("0xc8a7b0bd71097b47b2cad75e4e939d2aeb7fae88110e68f93b83fed08e9d3c38")
+	// LicenceTransferredToTokenHolderTopic is the topic of event TransferredToTokenHolder(address _from, address _to, address _asset, uint256 _amount).
+	LicenceTransferredToTokenHolderTopic = common.HexToHash("0xdd9dfad7b30d6b224e235f89565871419d3dec3b563a4e231f12d2cc97f9acfc")
+	// LicenceUpdatedCryptoFloatTopic is the topic of event UpdatedCryptoFloat(address _newFloat).
+	LicenceUpdatedCryptoFloatTopic = common.HexToHash("0x9af2841b0db134bda87280e2a9cababb156f95023c87023d708a677d61b4b6d8")
+	// LicenceUpdatedLicenceAmountTopic is the topic of event UpdatedLicenceAmount(uint256 _newAmount).
+	LicenceUpdatedLicenceAmountTopic = common.HexToHash("0x587b6068be8c555e2cddc6ad8a56df5e8dfb1533cc063d6703f79c791de15148")
+	// LicenceUpdatedLicenceDAOTopic is the topic of event UpdatedLicenceDAO(address _newDAO).
+	LicenceUpdatedLicenceDAOTopic = common.HexToHash("0xd32c17b277c7e87842861153d758814a267634f4308ec2461f88756df7dd7068")
+	// LicenceUpdatedTKNContractAddressTopic is the topic of event UpdatedTKNContractAddress(address _newTKN).
+	LicenceUpdatedTKNContractAddressTopic = common.HexToHash("0x2aeed92123e61fe64748a447c2ba122c4bfc0201d1ed5149e9ce9ede5adda545")
+	// LicenceUpdatedTokenHolderTopic is the topic of event UpdatedTokenHolder(address _newHolder).
+	LicenceUpdatedTokenHolderTopic = common.HexToHash("0xfa6bae0f250db86534a013b1c7a6c4076aa8f8d1ac248771a1c73f4ba366922a")
+)
+
+// ParseClaimedFromReceipt parses the Claimed events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Claimed(address _to, address _asset, uint256 _amount)
+func (_Licence *LicenceFilterer) ParseClaimedFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceClaimed, error) {
+	var events []*LicenceClaimed
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceClaimedTopic {
+			continue
+		}
+		event, err := _Licence.ParseClaimed(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferredToCryptoFloatFromReceipt parses the TransferredToCryptoFloat events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event TransferredToCryptoFloat(address _from, address _to, address _asset, uint256 _amount)
+func (_Licence *LicenceFilterer) ParseTransferredToCryptoFloatFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceTransferredToCryptoFloat, error) {
+	var events []*LicenceTransferredToCryptoFloat
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceTransferredToCryptoFloatTopic {
+			continue
+		}
+		event, err := _Licence.ParseTransferredToCryptoFloat(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferredToTokenHolderFromReceipt parses the TransferredToTokenHolder events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event TransferredToTokenHolder(address _from, address _to, address _asset, uint256 _amount)
+func (_Licence *LicenceFilterer) ParseTransferredToTokenHolderFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceTransferredToTokenHolder, error) {
+	var events []*LicenceTransferredToTokenHolder
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceTransferredToTokenHolderTopic {
+			continue
+		}
+		event, err := _Licence.ParseTransferredToTokenHolder(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedCryptoFloatFromReceipt parses the UpdatedCryptoFloat events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedCryptoFloat(address _newFloat)
+func (_Licence *LicenceFilterer) ParseUpdatedCryptoFloatFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceUpdatedCryptoFloat, error) {
+	var events []*LicenceUpdatedCryptoFloat
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceUpdatedCryptoFloatTopic {
+			continue
+		}
+		event, err := _Licence.ParseUpdatedCryptoFloat(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedLicenceAmountFromReceipt parses the UpdatedLicenceAmount events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedLicenceAmount(uint256 _newAmount)
+func (_Licence *LicenceFilterer) ParseUpdatedLicenceAmountFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceUpdatedLicenceAmount, error) {
+	var events []*LicenceUpdatedLicenceAmount
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceUpdatedLicenceAmountTopic {
+			continue
+		}
+		event, err := _Licence.ParseUpdatedLicenceAmount(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedLicenceDAOFromReceipt parses the UpdatedLicenceDAO events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedLicenceDAO(address _newDAO)
+func (_Licence *LicenceFilterer) ParseUpdatedLicenceDAOFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceUpdatedLicenceDAO, error) {
+	var events []*LicenceUpdatedLicenceDAO
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceUpdatedLicenceDAOTopic {
+			continue
+		}
+		event, err := _Licence.ParseUpdatedLicenceDAO(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedTKNContractAddressFromReceipt parses the UpdatedTKNContractAddress events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedTKNContractAddress(address _newTKN)
+func (_Licence *LicenceFilterer) ParseUpdatedTKNContractAddressFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceUpdatedTKNContractAddress, error) {
+	var events []*LicenceUpdatedTKNContractAddress
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceUpdatedTKNContractAddressTopic {
+			continue
+		}
+		event, err := _Licence.ParseUpdatedTKNContractAddress(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedTokenHolderFromReceipt parses the UpdatedTokenHolder events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedTokenHolder(address _newHolder)
+func (_Licence *LicenceFilterer) ParseUpdatedTokenHolderFromReceipt(address common.Address, receipt *types.Receipt) ([]*LicenceUpdatedTokenHolder, error) {
+	var events []*LicenceUpdatedTokenHolder
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != LicenceUpdatedTokenHolderTopic {
+			continue
+		}
+		event, err := _Licence.ParseUpdatedTokenHolder(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,134 @@
+package bindings
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/pkg/errors"
+)
+
+// LicenceScale is the MAX_AMOUNT_SCALE of the Licence: the scaled licence
+// amount is in thousandths of the loaded amount.
+const LicenceScale = 1000
+
+// LicenceFee is the licence fee charged on the loads.
+type LicenceFee struct {
+	// Scaled is the licence amount in thousandths of the loaded amount, e.g.
+	// 10 for 1%.
+	Scaled *big.Int `json:"scaled"`
+	// TKN is exempt from the licence fee.
+	TKN common.Address `json:"tkn"`
+}
+
+// Percent returns the fee in percent of the loaded amount, e.g. "1.0".
+func (f LicenceFee) Percent() string {
+	return new(big.Rat).SetFrac(f.Scaled, big.NewInt(LicenceScale/100)).FloatString(1)
+}
+
+// Split splits the amount of asset sent to the Licence as its load does, into
+// the amount loaded to the crypto float and the licence paid to the token
+// holder, rounding the load down.
+func (f LicenceFee) Split(asset common.Address, amount *big.Int) (load, licence *big.Int) {
+	if asset == f.TKN {
+		return new(big.Int).Set(amount), new(big.Int)
+	}
+	load = new(big.Int).Mul(amount, big.NewInt(LicenceScale))
+	load.Div(load, new(big.Int).Add(f.Scaled, big.NewInt(LicenceScale)))
+	return load, new(big.Int).Sub(amount, load)
+}
+
+// Gross returns the smallest amount of asset to send to the Licence for load
+// to be loaded to the crypto float.
+func (f LicenceFee) Gross(asset common.Address, load *big.Int) *big.Int {
+	if asset == f.TKN {
+		return new(big.Int).Set(load)
+	}
+	// The smallest amount such that amount * scale / (fee + scale) >= load.
+	total := new(big.Int).Add(f.Scaled, big.NewInt(LicenceScale))
+	amount := new(big.Int).Mul(load, total)
+	amount.Add(amount, big.NewInt(LicenceScale-1))
+	return amount.Div(amount, big.NewInt(LicenceScale))
+}
+
+// LicenceClient is a high-level client of a deployed Licence.
+type LicenceClient struct {
+	*Licence
+	address common.Address
+}
+
+// NewLicenceClient binds the Licence deployed at address.
+func NewLicenceClient(address common.Address, backend bind.ContractBackend) (*LicenceClient, error) {
+	l, err := NewLicence(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding licence contract")
+	}
+	return &LicenceClient{Licence: l, address: address}, nil
+}
+
+// Address returns the address of the Licence.
+func (c *LicenceClient) Address() common.Address {
+	return c.address
+}
+
+// Fee returns the current licence fee.
+func (c *LicenceClient) Fee(ctx context.Context) (LicenceFee, error) {
+	return c.fee(&bind.CallOpts{Context: ctx})
+}
+
+func (c *LicenceClient) fee(opts *bind.CallOpts) (LicenceFee, error) {
+	scaled, err := c.LicenceAmountScaled(opts)
+	if err != nil {
+		return LicenceFee{}, errors.Wrap(err, "getting licence amount")
+	}
+	tkn, err := c.TknContractAddress(opts)
+	if err != nil {
+		return LicenceFee{}, errors.Wrap(err, "getting TKN contract address")
+	}
+	return LicenceFee{Scaled: scaled, TKN: tkn}, nil
+}
+
+// Split splits an amount of asset to load with the current licence fee, see
+// LicenceFee.Split.
+func (c *LicenceClient) Split(ctx context.Context, asset common.Address, amount *big.Int) (load, licence *big.Int, err error) {
+	f, err := c.Fee(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	load, licence = f.Split(asset, amount)
+	return load, licence, nil
+}
+
+// WatchFee sends the new licence fee to sink each time the licence DAO updates
+// it, until the subscription is unsubscribed or fails.
+func (c *LicenceClient) WatchFee(opts *bind.WatchOpts, sink chan<- LicenceFee) (event.Subscription, error) {
+	updates := make(chan *LicenceUpdatedLicenceAmount)
+	sub, err := c.WatchUpdatedLicenceAmount(opts, updates)
+	if err != nil {
+		return nil, errors.Wrap(err, "watching licence amount updates")
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case u := <-updates:
+				call := &bind.CallOpts{Context: opts.Context, BlockNumber: new(big.Int).SetUint64(u.Raw.BlockNumber)}
+				tkn, err := c.TknContractAddress(call)
+				if err != nil {
+					return errors.Wrap(err, "getting TKN contract address")
+				}
+				select {
+				case sink <- LicenceFee{Scaled: u.NewAmount, TKN: tkn}:
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
@@ -214,3 +214,12 @@ func (_Base64Exporter *Base64ExporterSession) Base64decode(_encoded []byte) ([]b
 func (_Base64Exporter *Base64ExporterCallerSession) Base64decode(_encoded []byte) ([]byte, error) {
 	return _Base64Exporter.Contract.Base64decode(&_Base64Exporter.CallOpts, _encoded)
 }
+
+// Base64ExporterParsedABI is the parsed Base64ExporterABI.
+var Base64ExporterParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(Base64ExporterABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
@@ -896,3 +896,58 @@ func (_BurnerToken *BurnerTokenFilterer) ParseTransfer(log types.Log) (*BurnerTo
 	}
 	return event, nil
 }
+
+// BurnerTokenParsedABI is the parsed BurnerTokenABI.
+var BurnerTokenParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(BurnerTokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of BurnerToken, the hash of their signature.
+var (
+	// BurnerTokenApprovalTopic is the topic of event Approval(address indexed owner, address indexed spender, uint256 value).
+	BurnerTokenApprovalTopic = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	// BurnerTokenTransferTopic is the topic of event Transfer(address indexed from, address indexed to, uint256 value).
+	BurnerTokenTransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+)
+
+// ParseApprovalFromReceipt parses the Approval events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_BurnerToken *BurnerTokenFilterer) ParseApprovalFromReceipt(address common.Address, receipt *types.Receipt) ([]*BurnerTokenApproval, error) {
+	var events []*BurnerTokenApproval
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != BurnerTokenApprovalTopic {
+			continue
+		}
+		event, err := _BurnerToken.ParseApproval(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferFromReceipt parses the Transfer events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
+func (_BurnerToken *BurnerTokenFilterer) ParseTransferFromReceipt(address common.Address, receipt *types.Receipt) ([]*BurnerTokenTransfer, error) {
+	var events []*BurnerTokenTransfer
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != BurnerTokenTransferTopic {
+			continue
+		}
+		event, err := _BurnerToken.ParseTransfer(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -266,3 +266,12 @@ func (_BytesUtilsExporter *BytesUtilsExporterSession) BytesToUint256(_bts []byte
 func (_BytesUtilsExporter *BytesUtilsExporterCallerSession) BytesToUint256(_bts []byte, _from *big.Int) (*big.Int, error) {
 	return _BytesUtilsExporter.Contract.BytesToUint256(&_BytesUtilsExporter.CallOpts, _bts, _from)
 }
+
+// BytesUtilsExporterParsedABI is the parsed BytesUtilsExporterABI.
+var BytesUtilsExporterParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(BytesUtilsExporterABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
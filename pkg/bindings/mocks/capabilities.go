@@ -0,0 +1,15 @@
+package mocks
+
+import "github.com/tokencard/contracts/v2/pkg/bindings"
+
+var (
+	_ bindings.ERC20Caller = (*TokenCaller)(nil)
+	_ bindings.ERC20Caller = (*BurnerTokenCaller)(nil)
+	_ bindings.ERC20Caller = (*NonCompliantTokenCaller)(nil)
+
+	_ bindings.ERC20Transactor = (*TokenTransactor)(nil)
+	_ bindings.ERC20Transactor = (*BurnerTokenTransactor)(nil)
+	_ bindings.ERC20Transactor = (*NonCompliantTokenTransactor)(nil)
+
+	_ bindings.ENSResolvableCaller = (*TokenWhitelistableExporterCaller)(nil)
+)
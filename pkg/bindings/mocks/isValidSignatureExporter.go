@@ -214,3 +214,12 @@ func (_IsValidSignatureExporter *IsValidSignatureExporterSession) IsValidSignatu
 func (_IsValidSignatureExporter *IsValidSignatureExporterCallerSession) IsValidSignature(_data []byte, _signature []byte) ([4]byte, error) {
 	return _IsValidSignatureExporter.Contract.IsValidSignature(&_IsValidSignatureExporter.CallOpts, _data, _signature)
 }
+
+// IsValidSignatureExporterParsedABI is the parsed IsValidSignatureExporterABI.
+var IsValidSignatureExporterParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(IsValidSignatureExporterABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
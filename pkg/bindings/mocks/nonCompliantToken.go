@@ -677,3 +677,58 @@ func (_NonCompliantToken *NonCompliantTokenFilterer) ParseTransfer(log types.Log
 	}
 	return event, nil
 }
+
+// NonCompliantTokenParsedABI is the parsed NonCompliantTokenABI.
+var NonCompliantTokenParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(NonCompliantTokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of NonCompliantToken, the hash of their signature.
+var (
+	// NonCompliantTokenApprovalTopic is the topic of event Approval(address indexed owner, address indexed spender, uint256 value).
+	NonCompliantTokenApprovalTopic = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	// NonCompliantTokenTransferTopic is the topic of event Transfer(address indexed from, address indexed to, uint256 amount).
+	NonCompliantTokenTransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+)
+
+// ParseApprovalFromReceipt parses the Approval events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_NonCompliantToken *NonCompliantTokenFilterer) ParseApprovalFromReceipt(address common.Address, receipt *types.Receipt) ([]*NonCompliantTokenApproval, error) {
+	var events []*NonCompliantTokenApproval
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != NonCompliantTokenApprovalTopic {
+			continue
+		}
+		event, err := _NonCompliantToken.ParseApproval(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferFromReceipt parses the Transfer events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 amount)
+func (_NonCompliantToken *NonCompliantTokenFilterer) ParseTransferFromReceipt(address common.Address, receipt *types.Receipt) ([]*NonCompliantTokenTransfer, error) {
+	var events []*NonCompliantTokenTransfer
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != NonCompliantTokenTransferTopic {
+			continue
+		}
+		event, err := _NonCompliantToken.ParseTransfer(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
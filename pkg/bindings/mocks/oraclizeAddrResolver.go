@@ -214,3 +214,12 @@ func (_OraclizeAddrResolver *OraclizeAddrResolverSession) GetAddress() (common.A
 func (_OraclizeAddrResolver *OraclizeAddrResolverCallerSession) GetAddress() (common.Address, error) {
 	return _OraclizeAddrResolver.Contract.GetAddress(&_OraclizeAddrResolver.CallOpts)
 }
+
+// OraclizeAddrResolverParsedABI is the parsed OraclizeAddrResolverABI.
+var OraclizeAddrResolverParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(OraclizeAddrResolverABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
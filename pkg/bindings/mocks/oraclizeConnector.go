@@ -376,3 +376,12 @@ func (_OraclizeConnector *OraclizeConnectorSession) SetProofType(_proofType [1]b
 func (_OraclizeConnector *OraclizeConnectorTransactorSession) SetProofType(_proofType [1]byte) (*types.Transaction, error) {
 	return _OraclizeConnector.Contract.SetProofType(&_OraclizeConnector.TransactOpts, _proofType)
 }
+
+// OraclizeConnectorParsedABI is the parsed OraclizeConnectorABI.
+var OraclizeConnectorParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(OraclizeConnectorABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
@@ -266,3 +266,12 @@ func (_ParseIntScientificExporter *ParseIntScientificExporterSession) ParseIntSc
 func (_ParseIntScientificExporter *ParseIntScientificExporterCallerSession) ParseIntScientificWei(_a string) (*big.Int, error) {
 	return _ParseIntScientificExporter.Contract.ParseIntScientificWei(&_ParseIntScientificExporter.CallOpts, _a)
 }
+
+// ParseIntScientificExporterParsedABI is the parsed ParseIntScientificExporterABI.
+var ParseIntScientificExporterParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(ParseIntScientificExporterABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
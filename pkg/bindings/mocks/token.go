@@ -677,3 +677,58 @@ func (_Token *TokenFilterer) ParseTransfer(log types.Log) (*TokenTransfer, error
 	}
 	return event, nil
 }
+
+// TokenParsedABI is the parsed TokenABI.
+var TokenParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(TokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Token, the hash of their signature.
+var (
+	// TokenApprovalTopic is the topic of event Approval(address indexed owner, address indexed spender, uint256 value).
+	TokenApprovalTopic = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+	// TokenTransferTopic is the topic of event Transfer(address indexed from, address indexed to, uint256 amount).
+	TokenTransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+)
+
+// ParseApprovalFromReceipt parses the Approval events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+func (_Token *TokenFilterer) ParseApprovalFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenApproval, error) {
+	var events []*TokenApproval
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenApprovalTopic {
+			continue
+		}
+		event, err := _Token.ParseApproval(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferFromReceipt parses the Transfer events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 amount)
+func (_Token *TokenFilterer) ParseTransferFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenTransfer, error) {
+	var events []*TokenTransfer
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenTransferTopic {
+			continue
+		}
+		event, err := _Token.ParseTransfer(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
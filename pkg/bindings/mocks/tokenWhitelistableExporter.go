@@ -501,3 +501,12 @@ func (_TokenWhitelistableExporter *TokenWhitelistableExporterSession) UpdateToke
 func (_TokenWhitelistableExporter *TokenWhitelistableExporterTransactorSession) UpdateTokenRate(_token common.Address, _rate *big.Int, _updateDate *big.Int) (*types.Transaction, error) {
 	return _TokenWhitelistableExporter.Contract.UpdateTokenRate(&_TokenWhitelistableExporter.TransactOpts, _token, _rate, _updateDate)
 }
+
+// TokenWhitelistableExporterParsedABI is the parsed TokenWhitelistableExporterABI.
+var TokenWhitelistableExporterParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(TokenWhitelistableExporterABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
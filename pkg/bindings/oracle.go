@@ -1222,3 +1222,142 @@ func (_Oracle *OracleFilterer) ParseVerifiedProof(log types.Log) (*OracleVerifie
 	}
 	return event, nil
 }
+
+// OracleParsedABI is the parsed OracleABI.
+var OracleParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(OracleABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Oracle, the hash of their signature.
+var (
+	// OracleClaimedTopic is the topic of event Claimed(address _to, address _asset, uint256 _amount).
+	OracleClaimedTopic = common.HexToHash("0xf7a40077ff7a04c7e61f6f26fb13774259ddf1b6bce9ecf26a8276cdd3992683")
+	// OracleFailedUpdateRequestTopic is the topic of event FailedUpdateRequest(string _reason).
+	OracleFailedUpdateRequestTopic = common.HexToHash("0x4eb5629fd8501532aeb93b1b6a5b5b2ae398561e56514ed4b4b0c5ac2d381b6e")
+	// OracleRequestedUpdateTopic is the topic of event RequestedUpdate(string _symbol, bytes32 _queryID).
+	OracleRequestedUpdateTopic = common.HexToHash("0x47737841f636da1ca9f2de10d9bfb96c4251e0b31de72a902d4fd4ac8797bbbe")
+	// OracleSetCryptoComparePublicKeyTopic is the topic of event SetCryptoComparePublicKey(address _sender, bytes _publicKey).
+	OracleSetCryptoComparePublicKeyTopic = common.HexToHash("0xc6b0860ba9f580e9c5b6ba4e0954fe82827096a99d92e8c2d73009539ea8d9fa")
+	// OracleSetGasPriceTopic is the topic of event SetGasPrice(address _sender, uint256 _gasPrice).
+	OracleSetGasPriceTopic = common.HexToHash("0xfbd406825addb09beef160afc17bb80ba28df4a3533dcd23592b82658a1c5ab4")
+	// OracleVerifiedProofTopic is the topic of event VerifiedProof(bytes _publicKey, string _result).
+	OracleVerifiedProofTopic = common.HexToHash("0x0902fdd015aa1e56f7e6026b69c0595e82155dcbd83a83a23b40f9fe96babbd9")
+)
+
+// ParseClaimedFromReceipt parses the Claimed events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Claimed(address _to, address _asset, uint256 _amount)
+func (_Oracle *OracleFilterer) ParseClaimedFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleClaimed, error) {
+	var events []*OracleClaimed
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleClaimedTopic {
+			continue
+		}
+		event, err := _Oracle.ParseClaimed(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseFailedUpdateRequestFromReceipt parses the FailedUpdateRequest events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event FailedUpdateRequest(string _reason)
+func (_Oracle *OracleFilterer) ParseFailedUpdateRequestFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleFailedUpdateRequest, error) {
+	var events []*OracleFailedUpdateRequest
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleFailedUpdateRequestTopic {
+			continue
+		}
+		event, err := _Oracle.ParseFailedUpdateRequest(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRequestedUpdateFromReceipt parses the RequestedUpdate events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RequestedUpdate(string _symbol, bytes32 _queryID)
+func (_Oracle *OracleFilterer) ParseRequestedUpdateFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleRequestedUpdate, error) {
+	var events []*OracleRequestedUpdate
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleRequestedUpdateTopic {
+			continue
+		}
+		event, err := _Oracle.ParseRequestedUpdate(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSetCryptoComparePublicKeyFromReceipt parses the SetCryptoComparePublicKey events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SetCryptoComparePublicKey(address _sender, bytes _publicKey)
+func (_Oracle *OracleFilterer) ParseSetCryptoComparePublicKeyFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleSetCryptoComparePublicKey, error) {
+	var events []*OracleSetCryptoComparePublicKey
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleSetCryptoComparePublicKeyTopic {
+			continue
+		}
+		event, err := _Oracle.ParseSetCryptoComparePublicKey(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSetGasPriceFromReceipt parses the SetGasPrice events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SetGasPrice(address _sender, uint256 _gasPrice)
+func (_Oracle *OracleFilterer) ParseSetGasPriceFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleSetGasPrice, error) {
+	var events []*OracleSetGasPrice
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleSetGasPriceTopic {
+			continue
+		}
+		event, err := _Oracle.ParseSetGasPrice(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseVerifiedProofFromReceipt parses the VerifiedProof events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event VerifiedProof(bytes _publicKey, string _result)
+func (_Oracle *OracleFilterer) ParseVerifiedProofFromReceipt(address common.Address, receipt *types.Receipt) ([]*OracleVerifiedProof, error) {
+	var events []*OracleVerifiedProof
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != OracleVerifiedProofTopic {
+			continue
+		}
+		event, err := _Oracle.ParseVerifiedProof(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,142 @@
+package bindings
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// ErrTokenNotAvailable is returned for the tokens missing from the token
+// whitelist.
+var ErrTokenNotAvailable = errors.New("token is not available")
+
+// TokenRate is the rate of a token of the token whitelist.
+type TokenRate struct {
+	Token  common.Address `json:"token"`
+	Symbol string         `json:"symbol"`
+	// Magnitude is the number of base units of a whole token, 10 to the
+	// power of its decimals.
+	Magnitude *big.Int `json:"magnitude"`
+	// Rate is the value of a whole token in wei.
+	Rate       *big.Int  `json:"rate"`
+	LastUpdate time.Time `json:"last_update"`
+}
+
+// Decimals returns the number of decimals of the token, from its magnitude.
+func (r TokenRate) Decimals() (int, error) {
+	s := r.Magnitude.String()
+	if s[0] != '1' || strings.Trim(s[1:], "0") != "" {
+		return 0, errors.Errorf("magnitude %s of %s is not a power of 10", s, r.Symbol)
+	}
+	return len(s) - 1, nil
+}
+
+// ToEther converts an amount of base units of the token to wei, rounding down
+// as the wallet does.
+func (r TokenRate) ToEther(amount *big.Int) *big.Int {
+	v := new(big.Int).Mul(amount, r.Rate)
+	return v.Div(v, r.Magnitude)
+}
+
+// FromEther converts an amount of wei to base units of the token, rounding
+// down. It returns zero when the token has no rate.
+func (r TokenRate) FromEther(wei *big.Int) *big.Int {
+	if r.Rate.Sign() == 0 {
+		return new(big.Int)
+	}
+	v := new(big.Int).Mul(wei, r.Magnitude)
+	return v.Div(v, r.Rate)
+}
+
+// EtherPerToken returns the rate in ether per whole token, e.g. "0.00001633".
+func (r TokenRate) EtherPerToken() string {
+	return new(big.Rat).SetFrac(r.Rate, big.NewInt(params.Ether)).FloatString(18)
+}
+
+// ParseRate parses a rate in ether per whole token, e.g. "0.00001633", to the
+// wei per whole token stored by the token whitelist.
+func ParseRate(etherPerToken string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(etherPerToken)
+	if !ok || r.Sign() < 0 {
+		return nil, errors.Errorf("%q is not a valid rate", etherPerToken)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(params.Ether))
+	if !r.IsInt() {
+		return nil, errors.Errorf("rate %s has more than 18 decimals", etherPerToken)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// OracleClient is a high-level client of a deployed Oracle, looking up the
+// token rates it updates in the token whitelist.
+type OracleClient struct {
+	*Oracle
+	address   common.Address
+	whitelist *TokenWhitelistClient
+}
+
+// NewOracleClient binds the Oracle deployed at address and the token
+// whitelist it updates.
+func NewOracleClient(address, tokenWhitelist common.Address, backend bind.ContractBackend) (*OracleClient, error) {
+	o, err := NewOracle(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding oracle contract")
+	}
+	w, err := NewTokenWhitelistClient(tokenWhitelist, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleClient{Oracle: o, address: address, whitelist: w}, nil
+}
+
+// Address returns the address of the Oracle.
+func (c *OracleClient) Address() common.Address {
+	return c.address
+}
+
+// Rate returns the rate of a token, failing with ErrTokenNotAvailable when the
+// token is not whitelisted.
+func (c *OracleClient) Rate(ctx context.Context, token common.Address) (TokenRate, error) {
+	t, err := c.whitelist.Token(ctx, token)
+	if err != nil {
+		return TokenRate{}, err
+	}
+	return t.TokenRate, nil
+}
+
+// Rates returns the rates of all the whitelisted tokens.
+func (c *OracleClient) Rates(ctx context.Context) ([]TokenRate, error) {
+	tokens, err := c.whitelist.Tokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]TokenRate, len(tokens))
+	for i, t := range tokens {
+		rates[i] = t.TokenRate
+	}
+	return rates, nil
+}
+
+// UpdateRates queries the rates of the tokens, all the whitelisted ones when
+// none are given, with a callback using up to gasLimit. The query fee is paid
+// with the value of opts. Only controllers may update the rates.
+func (c *OracleClient) UpdateRates(opts *bind.TransactOpts, gasLimit uint64, tokens ...common.Address) (*types.Transaction, error) {
+	limit := new(big.Int).SetUint64(gasLimit)
+	if len(tokens) == 0 {
+		return c.UpdateTokenRates(opts, limit)
+	}
+	return c.UpdateTokenRatesList(opts, limit, tokens)
+}
+
+// SetRate sets the rate of a token in wei per whole token, see ParseRate,
+// bypassing the oracle. Only admins may set the rates.
+func (c *OracleClient) SetRate(opts *bind.TransactOpts, token common.Address, rate *big.Int, at time.Time) (*types.Transaction, error) {
+	return c.whitelist.UpdateTokenRate(opts, token, rate, big.NewInt(at.Unix()))
+}
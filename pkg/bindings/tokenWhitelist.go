@@ -2004,3 +2004,226 @@ func (_TokenWhitelist *TokenWhitelistFilterer) ParseUpdatedTokenRedeemable(log t
 	}
 	return event, nil
 }
+
+// TokenWhitelistParsedABI is the parsed TokenWhitelistABI.
+var TokenWhitelistParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(TokenWhitelistABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of TokenWhitelist, the hash of their signature.
+var (
+	// TokenWhitelistAddedExclusiveMethodTopic is the topic of event AddedExclusiveMethod(address _token, bytes4 _methodId).
+	TokenWhitelistAddedExclusiveMethodTopic = common.HexToHash("0xfb181256b03ef9051c59b29b98e8ef8dc1161e61d9062e1192ddd073806b0876")
+	// TokenWhitelistAddedMethodIdTopic is the topic of event AddedMethodId(bytes4 _methodId).
+	TokenWhitelistAddedMethodIdTopic = common.HexToHash("0xcad8cc4e064e022264c8f21f5293f8b3c267eaa6895ee7c9e0b34689726eae71")
+	// TokenWhitelistAddedTokenTopic is the topic of event AddedToken(address _sender, address _token, string _symbol, uint256 _magnitude, bool _loadable, bool _redeemable).
+	TokenWhitelistAddedTokenTopic = common.HexToHash("0x1802e89da3f6ef84e024e37454c226b1e13bf846ce71cd2a1d24faef9cbf779b")
+	// TokenWhitelistClaimedTopic is the topic of event Claimed(address _to, address _asset, uint256 _amount).
+	TokenWhitelistClaimedTopic = common.HexToHash("0xf7a40077ff7a04c7e61f6f26fb13774259ddf1b6bce9ecf26a8276cdd3992683")
+	// TokenWhitelistRemovedExclusiveMethodTopic is the topic of event RemovedExclusiveMethod(address _token, bytes4 _methodId).
+	TokenWhitelistRemovedExclusiveMethodTopic = common.HexToHash("0xe01bc5ecc4d7ff06fdb26bad9a3601ef089d9e5aa6f7dd03dc713b468eec117a")
+	// TokenWhitelistRemovedMethodIdTopic is the topic of event RemovedMethodId(bytes4 _methodId).
+	TokenWhitelistRemovedMethodIdTopic = common.HexToHash("0x006dd38caa262b48ea0824b897ee1c4f238521632ad2c5d12f3f0225a1378d1d")
+	// TokenWhitelistRemovedTokenTopic is the topic of event RemovedToken(address _sender, address _token).
+	TokenWhitelistRemovedTokenTopic = common.HexToHash("0x703f7e3f084d5b8dcc12fddcfd9a70d65b6b21ec7659e4608dbaf4419ede3ad0")
+	// TokenWhitelistUpdatedTokenLoadableTopic is the topic of event UpdatedTokenLoadable(address _sender, address _token, bool _loadable).
+	TokenWhitelistUpdatedTokenLoadableTopic = common.HexToHash("0x0e086282e8e406857ef1dce65e04a192ad8405e48484524cb2ddbf28e5d84eec")
+	// TokenWhitelistUpdatedTokenRateTopic is the topic of event UpdatedTokenRate(address _sender, address _token, uint256 _rate).
+	TokenWhitelistUpdatedTokenRateTopic = common.HexToHash("0xdb3a4cfb4cd8ac94343ff7440cee8d05ade309056203f0e53ca49b6db8197c7d")
+	// TokenWhitelistUpdatedTokenRedeemableTopic is the topic of event UpdatedTokenRedeemable(address _sender, address _token, bool _redeemable).
+	TokenWhitelistUpdatedTokenRedeemableTopic = common.HexToHash("0xcaa111d70d53608b9c8e3278c634595491de54f572a17a297dedad20f517039d")
+)
+
+// ParseAddedExclusiveMethodFromReceipt parses the AddedExclusiveMethod events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedExclusiveMethod(address _token, bytes4 _methodId)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseAddedExclusiveMethodFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistAddedExclusiveMethod, error) {
+	var events []*TokenWhitelistAddedExclusiveMethod
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistAddedExclusiveMethodTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseAddedExclusiveMethod(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseAddedMethodIdFromReceipt parses the AddedMethodId events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedMethodId(bytes4 _methodId)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseAddedMethodIdFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistAddedMethodId, error) {
+	var events []*TokenWhitelistAddedMethodId
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistAddedMethodIdTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseAddedMethodId(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseAddedTokenFromReceipt parses the AddedToken events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedToken(address _sender, address _token, string _symbol, uint256 _magnitude, bool _loadable, bool _redeemable)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseAddedTokenFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistAddedToken, error) {
+	var events []*TokenWhitelistAddedToken
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistAddedTokenTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseAddedToken(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseClaimedFromReceipt parses the Claimed events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Claimed(address _to, address _asset, uint256 _amount)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseClaimedFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistClaimed, error) {
+	var events []*TokenWhitelistClaimed
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistClaimedTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseClaimed(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedExclusiveMethodFromReceipt parses the RemovedExclusiveMethod events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedExclusiveMethod(address _token, bytes4 _methodId)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseRemovedExclusiveMethodFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistRemovedExclusiveMethod, error) {
+	var events []*TokenWhitelistRemovedExclusiveMethod
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistRemovedExclusiveMethodTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseRemovedExclusiveMethod(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedMethodIdFromReceipt parses the RemovedMethodId events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedMethodId(bytes4 _methodId)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseRemovedMethodIdFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistRemovedMethodId, error) {
+	var events []*TokenWhitelistRemovedMethodId
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistRemovedMethodIdTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseRemovedMethodId(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedTokenFromReceipt parses the RemovedToken events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedToken(address _sender, address _token)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseRemovedTokenFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistRemovedToken, error) {
+	var events []*TokenWhitelistRemovedToken
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistRemovedTokenTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseRemovedToken(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedTokenLoadableFromReceipt parses the UpdatedTokenLoadable events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedTokenLoadable(address _sender, address _token, bool _loadable)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseUpdatedTokenLoadableFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistUpdatedTokenLoadable, error) {
+	var events []*TokenWhitelistUpdatedTokenLoadable
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistUpdatedTokenLoadableTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseUpdatedTokenLoadable(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedTokenRateFromReceipt parses the UpdatedTokenRate events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedTokenRate(address _sender, address _token, uint256 _rate)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseUpdatedTokenRateFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistUpdatedTokenRate, error) {
+	var events []*TokenWhitelistUpdatedTokenRate
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistUpdatedTokenRateTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseUpdatedTokenRate(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedTokenRedeemableFromReceipt parses the UpdatedTokenRedeemable events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedTokenRedeemable(address _sender, address _token, bool _redeemable)
+func (_TokenWhitelist *TokenWhitelistFilterer) ParseUpdatedTokenRedeemableFromReceipt(address common.Address, receipt *types.Receipt) ([]*TokenWhitelistUpdatedTokenRedeemable, error) {
+	var events []*TokenWhitelistUpdatedTokenRedeemable
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != TokenWhitelistUpdatedTokenRedeemableTopic {
+			continue
+		}
+		event, err := _TokenWhitelist.ParseUpdatedTokenRedeemable(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,157 @@
+package bindings
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// WhitelistedToken is a token of the token whitelist.
+type WhitelistedToken struct {
+	TokenRate
+	// Loadable tokens can be loaded to the TokenCard.
+	Loadable bool `json:"loadable"`
+	// Redeemable tokens can be redeemed for TKN at the Holder.
+	Redeemable bool `json:"redeemable"`
+}
+
+// NewToken is a token to add to the token whitelist.
+type NewToken struct {
+	Address    common.Address
+	Symbol     string
+	Decimals   uint8
+	Loadable   bool
+	Redeemable bool
+}
+
+// TokenWhitelistClient is a high-level client of a deployed TokenWhitelist.
+type TokenWhitelistClient struct {
+	*TokenWhitelist
+	address common.Address
+}
+
+// NewTokenWhitelistClient binds the TokenWhitelist deployed at address.
+func NewTokenWhitelistClient(address common.Address, backend bind.ContractBackend) (*TokenWhitelistClient, error) {
+	w, err := NewTokenWhitelist(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding token whitelist contract")
+	}
+	return &TokenWhitelistClient{TokenWhitelist: w, address: address}, nil
+}
+
+// Address returns the address of the TokenWhitelist.
+func (c *TokenWhitelistClient) Address() common.Address {
+	return c.address
+}
+
+// Token returns a whitelisted token, failing with ErrTokenNotAvailable when
+// the token is not whitelisted.
+func (c *TokenWhitelistClient) Token(ctx context.Context, token common.Address) (WhitelistedToken, error) {
+	symbol, magnitude, rate, available, loadable, redeemable, lastUpdate, err := c.GetTokenInfo(&bind.CallOpts{Context: ctx}, token)
+	if err != nil {
+		return WhitelistedToken{}, errors.Wrap(err, "getting token info")
+	}
+	if !available {
+		return WhitelistedToken{}, errors.Wrapf(ErrTokenNotAvailable, "token %s", token.Hex())
+	}
+	return WhitelistedToken{
+		TokenRate: TokenRate{
+			Token:      token,
+			Symbol:     symbol,
+			Magnitude:  magnitude,
+			Rate:       rate,
+			LastUpdate: time.Unix(lastUpdate.Int64(), 0).UTC(),
+		},
+		Loadable:   loadable,
+		Redeemable: redeemable,
+	}, nil
+}
+
+// Tokens returns all the whitelisted tokens.
+func (c *TokenWhitelistClient) Tokens(ctx context.Context) ([]WhitelistedToken, error) {
+	addresses, err := c.TokenAddressArray(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting whitelisted tokens")
+	}
+	tokens := make([]WhitelistedToken, len(addresses))
+	for i, a := range addresses {
+		tokens[i], err = c.Token(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// IsLoadable tells whether the token is whitelisted and loadable.
+func (c *TokenWhitelistClient) IsLoadable(ctx context.Context, token common.Address) (bool, error) {
+	t, err := c.Token(ctx, token)
+	if errors.Cause(err) == ErrTokenNotAvailable {
+		return false, nil
+	}
+	return t.Loadable, err
+}
+
+// IsRedeemable tells whether the token is whitelisted and redeemable.
+func (c *TokenWhitelistClient) IsRedeemable(ctx context.Context, token common.Address) (bool, error) {
+	t, err := c.Token(ctx, token)
+	if errors.Cause(err) == ErrTokenNotAvailable {
+		return false, nil
+	}
+	return t.Redeemable, err
+}
+
+// AddTokens whitelists the tokens, without a rate until the oracle updates
+// them. It fails without sending the transaction when a token is already
+// whitelisted. Only admins may add tokens.
+func (c *TokenWhitelistClient) AddTokens(opts *bind.TransactOpts, tokens ...NewToken) (*types.Transaction, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to add")
+	}
+	var (
+		addresses  = make([]common.Address, len(tokens))
+		symbols    = make([][32]byte, len(tokens))
+		magnitudes = make([]*big.Int, len(tokens))
+		loadable   = make([]bool, len(tokens))
+		redeemable = make([]bool, len(tokens))
+	)
+	for i, t := range tokens {
+		if len(t.Symbol) > 32 {
+			return nil, errors.Errorf("symbol %q is longer than 32 bytes", t.Symbol)
+		}
+		_, err := c.Token(opts.Context, t.Address)
+		if err == nil {
+			return nil, errors.Errorf("token %s is already whitelisted", t.Address.Hex())
+		}
+		if errors.Cause(err) != ErrTokenNotAvailable {
+			return nil, err
+		}
+		addresses[i] = t.Address
+		copy(symbols[i][:], t.Symbol)
+		magnitudes[i] = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Decimals)), nil)
+		loadable[i] = t.Loadable
+		redeemable[i] = t.Redeemable
+	}
+	return c.TokenWhitelist.AddTokens(opts, addresses, symbols, magnitudes, loadable, redeemable, new(big.Int))
+}
+
+// RemoveTokens removes the tokens from the whitelist. It fails without
+// sending the transaction when a token is not whitelisted. Only admins may
+// remove tokens.
+func (c *TokenWhitelistClient) RemoveTokens(opts *bind.TransactOpts, tokens ...common.Address) (*types.Transaction, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to remove")
+	}
+	for _, t := range tokens {
+		_, err := c.Token(opts.Context, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.TokenWhitelist.RemoveTokens(opts, tokens)
+}
@@ -4672,3 +4672,499 @@ func (_Wallet *WalletFilterer) ParseUpdatedAvailableLimit(log types.Log) (*Walle
 	}
 	return event, nil
 }
+
+// WalletParsedABI is the parsed WalletABI.
+var WalletParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(WalletABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of Wallet, the hash of their signature.
+var (
+	// WalletAddedToWhitelistTopic is the topic of event AddedToWhitelist(address _sender, address[] _addresses).
+	WalletAddedToWhitelistTopic = common.HexToHash("0xb2f6cccee7a369e23e293c25aa19bef80af11eb26deba3ea0f2a02783f752e4a")
+	// WalletBulkTransferredTopic is the topic of event BulkTransferred(address _to, address[] _assets).
+	WalletBulkTransferredTopic = common.HexToHash("0xd4f62f23021706247dcffea245d104ae7ddaec7f23acf3d11d7136d5de6a69ad")
+	// WalletCancelledWhitelistAdditionTopic is the topic of event CancelledWhitelistAddition(address _sender, bytes32 _hash).
+	WalletCancelledWhitelistAdditionTopic = common.HexToHash("0x7794eff834d760583543e6e510e717a5e66d2c064e225f4db448343c3e66afcf")
+	// WalletCancelledWhitelistRemovalTopic is the topic of event CancelledWhitelistRemoval(address _sender, bytes32 _hash).
+	WalletCancelledWhitelistRemovalTopic = common.HexToHash("0x13c935eb475aa0f6e931fece83e2ac44569ce2d53460d29a6dedab40b965c8a3")
+	// WalletExecutedRelayedTransactionTopic is the topic of event ExecutedRelayedTransaction(bytes _data, bytes _returndata).
+	WalletExecutedRelayedTransactionTopic = common.HexToHash("0x823dbcf2b7b0f265871963ca65ac033f6b4c71e0d82cd123d2ff23d752dc21c1")
+	// WalletExecutedTransactionTopic is the topic of event ExecutedTransaction(address _destination, uint256 _value, bytes _data, bytes _returndata).
+	WalletExecutedTransactionTopic = common.HexToHash("0xf77753fab406ecfff96d6ff2476c64a838fa9f6d37b1bf190f8546e395e3b613")
+	// WalletIncreasedRelayNonceTopic is the topic of event IncreasedRelayNonce(address _sender, uint256 _currentNonce).
+	WalletIncreasedRelayNonceTopic = common.HexToHash("0xab0423a75986556234aecd171c46ce7f5e45607d8070bf5230f2735b50322bff")
+	// WalletLoadedTokenCardTopic is the topic of event LoadedTokenCard(address _asset, uint256 _amount).
+	WalletLoadedTokenCardTopic = common.HexToHash("0x5f65674bec9af81f71be68674135a0ea3f163fb91984e3893d06da9f6ea2ce8a")
+	// WalletLockedOwnershipTopic is the topic of event LockedOwnership(address _locked).
+	WalletLockedOwnershipTopic = common.HexToHash("0x808639ff9c8e4732d60b6c2330de498035416d229f27a77d259680895efec122")
+	// WalletReceivedTopic is the topic of event Received(address _from, uint256 _amount).
+	WalletReceivedTopic = common.HexToHash("0x88a5966d370b9919b20f3e2c13ff65706f196a4e32cc2c12bf57088f88525874")
+	// WalletRemovedFromWhitelistTopic is the topic of event RemovedFromWhitelist(address _sender, address[] _addresses).
+	WalletRemovedFromWhitelistTopic = common.HexToHash("0xd218c430fa348f4ce67791021b6b89c0c3eacd4ead1d8f5b83c60038ec28249b")
+	// WalletSetGasTopUpLimitTopic is the topic of event SetGasTopUpLimit(address _sender, uint256 _amount).
+	WalletSetGasTopUpLimitTopic = common.HexToHash("0x41ff5d5ce3b7935893a4e7269ec5caae9cca5e3bf0eb4b21d2f443489667112e")
+	// WalletSetLoadLimitTopic is the topic of event SetLoadLimit(address _sender, uint256 _amount).
+	WalletSetLoadLimitTopic = common.HexToHash("0x0b05243483e17c3f3377aee82b7d47e5700b48288695fc08b7ecc2759afa44ef")
+	// WalletSetSpendLimitTopic is the topic of event SetSpendLimit(address _sender, uint256 _amount).
+	WalletSetSpendLimitTopic = common.HexToHash("0x068f112e5ec923d412be64779fe69e0fcbb6784c6617e94cccc8fd348f2e0f21")
+	// WalletSubmittedGasTopUpLimitUpdateTopic is the topic of event SubmittedGasTopUpLimitUpdate(uint256 _amount).
+	WalletSubmittedGasTopUpLimitUpdateTopic = common.HexToHash("0xaf2a77cd04c3cc155588dd3bf67b310ab4fb3b1da3cf6b8d7d4d2aa1d09b794c")
+	// WalletSubmittedLoadLimitUpdateTopic is the topic of event SubmittedLoadLimitUpdate(uint256 _amount).
+	WalletSubmittedLoadLimitUpdateTopic = common.HexToHash("0xc178d379965e5657b6fc57494e392f121a14119215dfb422aad7db4cc03f2d10")
+	// WalletSubmittedSpendLimitUpdateTopic is the topic of event SubmittedSpendLimitUpdate(uint256 _amount).
+	WalletSubmittedSpendLimitUpdateTopic = common.HexToHash("0x4b1b970c8a0fa761e7803ed70c13d7aca71904b13df60fbe03f981da1730da91")
+	// WalletSubmittedWhitelistAdditionTopic is the topic of event SubmittedWhitelistAddition(address[] _addresses, bytes32 _hash).
+	WalletSubmittedWhitelistAdditionTopic = common.HexToHash("0x9c80b3b5f68b3e017766d59e8d09b34efe6462b05c398f35cab9e271d9bc3b9c")
+	// WalletSubmittedWhitelistRemovalTopic is the topic of event SubmittedWhitelistRemoval(address[] _addresses, bytes32 _hash).
+	WalletSubmittedWhitelistRemovalTopic = common.HexToHash("0xfbc0e5ca6c7e4858daf0fdb185ef5186203e74ec9c64737e93c0aeaec596e1d1")
+	// WalletToppedUpGasTopic is the topic of event ToppedUpGas(address _sender, address _owner, uint256 _amount).
+	WalletToppedUpGasTopic = common.HexToHash("0x611b7c0d84fda988026215bef9b3e4d81cbceced7e679be6d5e044b588467c0e")
+	// WalletTransferredTopic is the topic of event Transferred(address _to, address _asset, uint256 _amount).
+	WalletTransferredTopic = common.HexToHash("0xd1ba4ac2e2a11b5101f6cb4d978f514a155b421e8ec396d2d9abaf0bb02917ee")
+	// WalletTransferredOwnershipTopic is the topic of event TransferredOwnership(address _from, address _to).
+	WalletTransferredOwnershipTopic = common.HexToHash("0x850b3df64837d7d518b45f5aa64d104652c3b80eb5b34a8e3d9eb666cb7cdea5")
+	// WalletUpdatedAvailableLimitTopic is the topic of event UpdatedAvailableLimit().
+	WalletUpdatedAvailableLimitTopic = common.HexToHash("0xe93bc25276d408d390778e7a8b926f2f67209c43ed540081b951fe128f0d3cd2")
+)
+
+// ParseAddedToWhitelistFromReceipt parses the AddedToWhitelist events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event AddedToWhitelist(address _sender, address[] _addresses)
+func (_Wallet *WalletFilterer) ParseAddedToWhitelistFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletAddedToWhitelist, error) {
+	var events []*WalletAddedToWhitelist
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletAddedToWhitelistTopic {
+			continue
+		}
+		event, err := _Wallet.ParseAddedToWhitelist(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseBulkTransferredFromReceipt parses the BulkTransferred events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event BulkTransferred(address _to, address[] _assets)
+func (_Wallet *WalletFilterer) ParseBulkTransferredFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletBulkTransferred, error) {
+	var events []*WalletBulkTransferred
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletBulkTransferredTopic {
+			continue
+		}
+		event, err := _Wallet.ParseBulkTransferred(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseCancelledWhitelistAdditionFromReceipt parses the CancelledWhitelistAddition events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event CancelledWhitelistAddition(address _sender, bytes32 _hash)
+func (_Wallet *WalletFilterer) ParseCancelledWhitelistAdditionFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletCancelledWhitelistAddition, error) {
+	var events []*WalletCancelledWhitelistAddition
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletCancelledWhitelistAdditionTopic {
+			continue
+		}
+		event, err := _Wallet.ParseCancelledWhitelistAddition(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseCancelledWhitelistRemovalFromReceipt parses the CancelledWhitelistRemoval events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event CancelledWhitelistRemoval(address _sender, bytes32 _hash)
+func (_Wallet *WalletFilterer) ParseCancelledWhitelistRemovalFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletCancelledWhitelistRemoval, error) {
+	var events []*WalletCancelledWhitelistRemoval
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletCancelledWhitelistRemovalTopic {
+			continue
+		}
+		event, err := _Wallet.ParseCancelledWhitelistRemoval(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseExecutedRelayedTransactionFromReceipt parses the ExecutedRelayedTransaction events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event ExecutedRelayedTransaction(bytes _data, bytes _returndata)
+func (_Wallet *WalletFilterer) ParseExecutedRelayedTransactionFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletExecutedRelayedTransaction, error) {
+	var events []*WalletExecutedRelayedTransaction
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletExecutedRelayedTransactionTopic {
+			continue
+		}
+		event, err := _Wallet.ParseExecutedRelayedTransaction(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseExecutedTransactionFromReceipt parses the ExecutedTransaction events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event ExecutedTransaction(address _destination, uint256 _value, bytes _data, bytes _returndata)
+func (_Wallet *WalletFilterer) ParseExecutedTransactionFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletExecutedTransaction, error) {
+	var events []*WalletExecutedTransaction
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletExecutedTransactionTopic {
+			continue
+		}
+		event, err := _Wallet.ParseExecutedTransaction(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseIncreasedRelayNonceFromReceipt parses the IncreasedRelayNonce events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event IncreasedRelayNonce(address _sender, uint256 _currentNonce)
+func (_Wallet *WalletFilterer) ParseIncreasedRelayNonceFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletIncreasedRelayNonce, error) {
+	var events []*WalletIncreasedRelayNonce
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletIncreasedRelayNonceTopic {
+			continue
+		}
+		event, err := _Wallet.ParseIncreasedRelayNonce(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseLoadedTokenCardFromReceipt parses the LoadedTokenCard events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event LoadedTokenCard(address _asset, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseLoadedTokenCardFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletLoadedTokenCard, error) {
+	var events []*WalletLoadedTokenCard
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletLoadedTokenCardTopic {
+			continue
+		}
+		event, err := _Wallet.ParseLoadedTokenCard(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseLockedOwnershipFromReceipt parses the LockedOwnership events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event LockedOwnership(address _locked)
+func (_Wallet *WalletFilterer) ParseLockedOwnershipFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletLockedOwnership, error) {
+	var events []*WalletLockedOwnership
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletLockedOwnershipTopic {
+			continue
+		}
+		event, err := _Wallet.ParseLockedOwnership(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseReceivedFromReceipt parses the Received events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Received(address _from, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseReceivedFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletReceived, error) {
+	var events []*WalletReceived
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletReceivedTopic {
+			continue
+		}
+		event, err := _Wallet.ParseReceived(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseRemovedFromWhitelistFromReceipt parses the RemovedFromWhitelist events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event RemovedFromWhitelist(address _sender, address[] _addresses)
+func (_Wallet *WalletFilterer) ParseRemovedFromWhitelistFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletRemovedFromWhitelist, error) {
+	var events []*WalletRemovedFromWhitelist
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletRemovedFromWhitelistTopic {
+			continue
+		}
+		event, err := _Wallet.ParseRemovedFromWhitelist(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSetGasTopUpLimitFromReceipt parses the SetGasTopUpLimit events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SetGasTopUpLimit(address _sender, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSetGasTopUpLimitFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSetGasTopUpLimit, error) {
+	var events []*WalletSetGasTopUpLimit
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSetGasTopUpLimitTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSetGasTopUpLimit(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSetLoadLimitFromReceipt parses the SetLoadLimit events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SetLoadLimit(address _sender, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSetLoadLimitFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSetLoadLimit, error) {
+	var events []*WalletSetLoadLimit
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSetLoadLimitTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSetLoadLimit(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSetSpendLimitFromReceipt parses the SetSpendLimit events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SetSpendLimit(address _sender, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSetSpendLimitFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSetSpendLimit, error) {
+	var events []*WalletSetSpendLimit
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSetSpendLimitTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSetSpendLimit(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSubmittedGasTopUpLimitUpdateFromReceipt parses the SubmittedGasTopUpLimitUpdate events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SubmittedGasTopUpLimitUpdate(uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSubmittedGasTopUpLimitUpdateFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSubmittedGasTopUpLimitUpdate, error) {
+	var events []*WalletSubmittedGasTopUpLimitUpdate
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSubmittedGasTopUpLimitUpdateTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSubmittedGasTopUpLimitUpdate(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSubmittedLoadLimitUpdateFromReceipt parses the SubmittedLoadLimitUpdate events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SubmittedLoadLimitUpdate(uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSubmittedLoadLimitUpdateFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSubmittedLoadLimitUpdate, error) {
+	var events []*WalletSubmittedLoadLimitUpdate
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSubmittedLoadLimitUpdateTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSubmittedLoadLimitUpdate(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSubmittedSpendLimitUpdateFromReceipt parses the SubmittedSpendLimitUpdate events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SubmittedSpendLimitUpdate(uint256 _amount)
+func (_Wallet *WalletFilterer) ParseSubmittedSpendLimitUpdateFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSubmittedSpendLimitUpdate, error) {
+	var events []*WalletSubmittedSpendLimitUpdate
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSubmittedSpendLimitUpdateTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSubmittedSpendLimitUpdate(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSubmittedWhitelistAdditionFromReceipt parses the SubmittedWhitelistAddition events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SubmittedWhitelistAddition(address[] _addresses, bytes32 _hash)
+func (_Wallet *WalletFilterer) ParseSubmittedWhitelistAdditionFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSubmittedWhitelistAddition, error) {
+	var events []*WalletSubmittedWhitelistAddition
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSubmittedWhitelistAdditionTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSubmittedWhitelistAddition(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseSubmittedWhitelistRemovalFromReceipt parses the SubmittedWhitelistRemoval events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event SubmittedWhitelistRemoval(address[] _addresses, bytes32 _hash)
+func (_Wallet *WalletFilterer) ParseSubmittedWhitelistRemovalFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletSubmittedWhitelistRemoval, error) {
+	var events []*WalletSubmittedWhitelistRemoval
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletSubmittedWhitelistRemovalTopic {
+			continue
+		}
+		event, err := _Wallet.ParseSubmittedWhitelistRemoval(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseToppedUpGasFromReceipt parses the ToppedUpGas events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event ToppedUpGas(address _sender, address _owner, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseToppedUpGasFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletToppedUpGas, error) {
+	var events []*WalletToppedUpGas
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletToppedUpGasTopic {
+			continue
+		}
+		event, err := _Wallet.ParseToppedUpGas(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferredFromReceipt parses the Transferred events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event Transferred(address _to, address _asset, uint256 _amount)
+func (_Wallet *WalletFilterer) ParseTransferredFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletTransferred, error) {
+	var events []*WalletTransferred
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletTransferredTopic {
+			continue
+		}
+		event, err := _Wallet.ParseTransferred(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseTransferredOwnershipFromReceipt parses the TransferredOwnership events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event TransferredOwnership(address _from, address _to)
+func (_Wallet *WalletFilterer) ParseTransferredOwnershipFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletTransferredOwnership, error) {
+	var events []*WalletTransferredOwnership
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletTransferredOwnershipTopic {
+			continue
+		}
+		event, err := _Wallet.ParseTransferredOwnership(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseUpdatedAvailableLimitFromReceipt parses the UpdatedAvailableLimit events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event UpdatedAvailableLimit()
+func (_Wallet *WalletFilterer) ParseUpdatedAvailableLimitFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletUpdatedAvailableLimit, error) {
+	var events []*WalletUpdatedAvailableLimit
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletUpdatedAvailableLimitTopic {
+			continue
+		}
+		event, err := _Wallet.ParseUpdatedAvailableLimit(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -597,3 +597,37 @@ func (_WalletCache *WalletCacheFilterer) ParseCachedWallet(log types.Log) (*Wall
 	}
 	return event, nil
 }
+
+// WalletCacheParsedABI is the parsed WalletCacheABI.
+var WalletCacheParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(WalletCacheABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of WalletCache, the hash of their signature.
+var (
+	// WalletCacheCachedWalletTopic is the topic of event CachedWallet(address _wallet).
+	WalletCacheCachedWalletTopic = common.HexToHash("0x9ede7876a6b2454072ceeaff4b6b4e6eaa5381db241b850f2a46034136fc2e6e")
+)
+
+// ParseCachedWalletFromReceipt parses the CachedWallet events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event CachedWallet(address _wallet)
+func (_WalletCache *WalletCacheFilterer) ParseCachedWalletFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletCacheCachedWallet, error) {
+	var events []*WalletCacheCachedWallet
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletCacheCachedWalletTopic {
+			continue
+		}
+		event, err := _WalletCache.ParseCachedWallet(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
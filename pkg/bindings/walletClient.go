@@ -0,0 +1,155 @@
+package bindings
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Errors of the guards of WalletClient, matching the checks of the Wallet.
+var (
+	ErrZeroDestination    = errors.New("destination=0")
+	ErrZeroAmount         = errors.New("amount=0")
+	ErrSpendLimitExceeded = errors.New("available<amount")
+)
+
+// ETH is the asset address the Wallet uses for ether.
+var ETH = common.Address{}
+
+// DailyLimit is the state of one of the daily limits of a Wallet: the spend,
+// load and gas top up limits.
+type DailyLimit struct {
+	// Value is the limit, renewed every 24 hours.
+	Value *big.Int `json:"value"`
+	// Available is what is left of the limit until it is renewed.
+	Available *big.Int `json:"available"`
+	// Pending is the value of a submitted limit update awaiting confirmation
+	// by the controller.
+	Pending *big.Int `json:"pending"`
+	// Updateable tells whether the owner has set the limit, which is then
+	// only updated through a submission confirmed by the controller.
+	Updateable bool `json:"updateable"`
+}
+
+// WalletClient is a high-level client of a deployed Wallet, querying its
+// daily limits and checking transfers against them before sending.
+type WalletClient struct {
+	*Wallet
+	address common.Address
+}
+
+// NewWalletClient binds the Wallet deployed at address.
+func NewWalletClient(address common.Address, backend bind.ContractBackend) (*WalletClient, error) {
+	w, err := NewWallet(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet contract")
+	}
+	return &WalletClient{Wallet: w, address: address}, nil
+}
+
+// Address returns the address of the Wallet.
+func (c *WalletClient) Address() common.Address {
+	return c.address
+}
+
+// SpendLimit returns the daily limit of the transfers to addresses which are
+// not whitelisted, in wei.
+func (c *WalletClient) SpendLimit(ctx context.Context) (DailyLimit, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	return dailyLimit("spend", opts, c.SpendLimitValue, c.SpendLimitAvailable, c.SpendLimitPending, c.SpendLimitUpdateable)
+}
+
+// LoadLimit returns the daily limit of the card loads, in stablecoin.
+func (c *WalletClient) LoadLimit(ctx context.Context) (DailyLimit, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	return dailyLimit("load", opts, c.LoadLimitValue, c.LoadLimitAvailable, c.LoadLimitPending, c.LoadLimitUpdateable)
+}
+
+// GasTopUpLimit returns the daily limit of the gas top ups, in wei.
+func (c *WalletClient) GasTopUpLimit(ctx context.Context) (DailyLimit, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	return dailyLimit("gas top up", opts, c.GasTopUpLimitValue, c.GasTopUpLimitAvailable, c.GasTopUpLimitPending, c.GasTopUpLimitUpdateable)
+}
+
+func dailyLimit(name string, opts *bind.CallOpts, value, available, pending func(*bind.CallOpts) (*big.Int, error), updateable func(*bind.CallOpts) (bool, error)) (DailyLimit, error) {
+	var l DailyLimit
+	var err error
+	l.Value, err = value(opts)
+	if err != nil {
+		return DailyLimit{}, errors.Wrapf(err, "getting %s limit value", name)
+	}
+	l.Available, err = available(opts)
+	if err != nil {
+		return DailyLimit{}, errors.Wrapf(err, "getting %s limit available", name)
+	}
+	l.Pending, err = pending(opts)
+	if err != nil {
+		return DailyLimit{}, errors.Wrapf(err, "getting %s limit pending", name)
+	}
+	l.Updateable, err = updateable(opts)
+	if err != nil {
+		return DailyLimit{}, errors.Wrapf(err, "getting %s limit updateable", name)
+	}
+	return l, nil
+}
+
+// IsWhitelisted tells whether transfers to the address bypass the spend
+// limit.
+func (c *WalletClient) IsWhitelisted(ctx context.Context, address common.Address) (bool, error) {
+	whitelisted, err := c.WhitelistMap(&bind.CallOpts{Context: ctx}, address)
+	if err != nil {
+		return false, errors.Wrap(err, "getting whitelist")
+	}
+	return whitelisted, nil
+}
+
+// CheckTransfer checks that the Wallet would transfer amount of asset, ETH
+// or an ERC20 token, to the address: transfers to whitelisted addresses are
+// not limited, the others must fit in the available spend limit once
+// converted to wei.
+func (c *WalletClient) CheckTransfer(ctx context.Context, to, asset common.Address, amount *big.Int) error {
+	if to == (common.Address{}) {
+		return ErrZeroDestination
+	}
+	if amount.Sign() == 0 {
+		return ErrZeroAmount
+	}
+	whitelisted, err := c.IsWhitelisted(ctx, to)
+	if err != nil {
+		return err
+	}
+	if whitelisted {
+		return nil
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	value := amount
+	if asset != ETH {
+		value, err = c.ConvertToEther(opts, asset, amount)
+		if err != nil {
+			return errors.Wrapf(err, "converting %s to ether", asset.Hex())
+		}
+	}
+	available, err := c.SpendLimitAvailable(opts)
+	if err != nil {
+		return errors.Wrap(err, "getting spend limit available")
+	}
+	if available.Cmp(value) < 0 {
+		return errors.Wrapf(ErrSpendLimitExceeded, "%s wei available, %s wei needed", available, value)
+	}
+	return nil
+}
+
+// Transfer transfers amount of asset to the address. It fails without
+// sending the transaction when CheckTransfer does.
+func (c *WalletClient) Transfer(opts *bind.TransactOpts, to, asset common.Address, amount *big.Int) (*types.Transaction, error) {
+	err := c.CheckTransfer(opts.Context, to, asset, amount)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wallet.Transfer(opts, to, asset, amount)
+}
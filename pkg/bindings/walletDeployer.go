@@ -604,3 +604,58 @@ func (_WalletDeployer *WalletDeployerFilterer) ParseMigratedWallet(log types.Log
 	}
 	return event, nil
 }
+
+// WalletDeployerParsedABI is the parsed WalletDeployerABI.
+var WalletDeployerParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(WalletDeployerABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The topics of the events of WalletDeployer, the hash of their signature.
+var (
+	// WalletDeployerDeployedWalletTopic is the topic of event DeployedWallet(address _wallet, address _owner).
+	WalletDeployerDeployedWalletTopic = common.HexToHash("0xc02db5f4164f89d90905928336769906e16d79c4a77342126eb647ca9440d078")
+	// WalletDeployerMigratedWalletTopic is the topic of event MigratedWallet(address _wallet, address _oldWallet, address _owner, uint256 _paid).
+	WalletDeployerMigratedWalletTopic = common.HexToHash("0xc65d6ee9571556236e352151c95c79b6589474ad814195aaac7d5ab8d88ba2dd")
+)
+
+// ParseDeployedWalletFromReceipt parses the DeployedWallet events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event DeployedWallet(address _wallet, address _owner)
+func (_WalletDeployer *WalletDeployerFilterer) ParseDeployedWalletFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletDeployerDeployedWallet, error) {
+	var events []*WalletDeployerDeployedWallet
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletDeployerDeployedWalletTopic {
+			continue
+		}
+		event, err := _WalletDeployer.ParseDeployedWallet(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ParseMigratedWalletFromReceipt parses the MigratedWallet events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: event MigratedWallet(address _wallet, address _oldWallet, address _owner, uint256 _paid)
+func (_WalletDeployer *WalletDeployerFilterer) ParseMigratedWalletFromReceipt(address common.Address, receipt *types.Receipt) ([]*WalletDeployerMigratedWallet, error) {
+	var events []*WalletDeployerMigratedWallet
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != WalletDeployerMigratedWalletTopic {
+			continue
+		}
+		event, err := _WalletDeployer.ParseMigratedWallet(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,288 @@
+// Package bindmigrate finds the uses of the generated bindings of
+// pkg/bindings in a Go codebase and suggests the high-level clients replacing
+// them, rewriting the uses where it is safe.
+//
+// Like bindcheck, the scan is syntactic: the files are parsed, not type
+// checked. A constructor is only rewritten when the client it is replaced with
+// takes the same arguments and embeds the binding, so that the methods called
+// on the result still resolve, and when the type of the binding is not named
+// in the package, so that the result is never assigned to a variable of the
+// binding type. The packages should still be built after a rewrite.
+package bindmigrate
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BindingsPath is the import path of the generated bindings.
+const BindingsPath = "github.com/tokencard/contracts/v2/pkg/bindings"
+
+// client is the high-level client replacing a binding.
+type client struct {
+	name string
+	// caveat says why the constructor of the binding can not be rewritten,
+	// empty when it can.
+	caveat string
+}
+
+// clients are the high-level clients of pkg/bindings, by binding type.
+var clients = map[string]client{
+	"Controller":     {name: "AccessClient"},
+	"Licence":        {name: "LicenceClient"},
+	"TokenWhitelist": {name: "TokenWhitelistClient"},
+	"Wallet":         {name: "WalletClient"},
+	"Oracle":         {name: "OracleClient", caveat: "it also takes the address of the token whitelist"},
+	"Holder":         {name: "HolderClient", caveat: "it also takes the address of the token whitelist and a HolderBackend"},
+}
+
+// The kinds of the symbols of a binding.
+const (
+	constructor = iota
+	bindingType
+	partial
+)
+
+// symbol is a symbol of pkg/bindings with a high-level equivalent.
+type symbol struct {
+	binding string
+	kind    int
+}
+
+// symbols are the symbols of the bindings replaced by the clients, by name.
+var symbols = func() map[string]symbol {
+	s := make(map[string]symbol)
+	for b := range clients {
+		s["New"+b] = symbol{b, constructor}
+		s[b] = symbol{b, bindingType}
+		for _, suffix := range []string{"Caller", "Transactor", "Filterer"} {
+			s["New"+b+suffix] = symbol{b, partial}
+			s[b+suffix] = symbol{b, partial}
+		}
+		for _, suffix := range []string{"Session", "CallerSession", "TransactorSession", "Raw", "CallerRaw", "TransactorRaw"} {
+			s[b+suffix] = symbol{b, partial}
+		}
+	}
+	return s
+}()
+
+// Suggestion is a use of a binding with its high-level replacement.
+type Suggestion struct {
+	Pos token.Position
+	// Symbol is the symbol as written in the file, e.g. bindings.NewWallet.
+	Symbol string
+	// Replacement is the symbol replacing it, e.g. bindings.NewWalletClient.
+	Replacement string
+	// Hint says how to migrate when the use is not rewritten, and the
+	// equivalent of the stable API of pkg/monolith/v1.
+	Hint string
+	// Rewrite tells whether Rewrite replaces the use.
+	Rewrite bool
+}
+
+func (s Suggestion) String() string {
+	action := "rewritable"
+	if !s.Rewrite {
+		action = "manual"
+	}
+	return fmt.Sprintf("%s: %s -> %s (%s): %s", s.Pos, s.Symbol, s.Replacement, action, s.Hint)
+}
+
+// use is a use of a symbol found in a file.
+type use struct {
+	pos    token.Position
+	pkg    string
+	name   string
+	symbol symbol
+}
+
+// Scan walks the Go files under dir, the vendor and testdata directories
+// excluded, and returns the uses of the bindings sorted by position.
+func Scan(dir string) ([]Suggestion, error) {
+	fset := token.NewFileSet()
+	// The uses are grouped by directory, as a type named in any file of a
+	// package prevents the rewrite of the constructors in all of them.
+	uses := make(map[string][]use)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && (info.Name() == "vendor" || info.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", path)
+		}
+		d := filepath.Dir(path)
+		uses[d] = append(uses[d], fileUses(fset, f)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []Suggestion
+	for _, us := range uses {
+		named := make(map[string]token.Position)
+		for _, u := range us {
+			if _, ok := named[u.symbol.binding]; !ok && u.symbol.kind == bindingType {
+				named[u.symbol.binding] = u.pos
+			}
+		}
+		for _, u := range us {
+			suggestions = append(suggestions, suggest(u, named))
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].Pos, suggestions[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return suggestions, nil
+}
+
+func suggest(u use, named map[string]token.Position) Suggestion {
+	c := clients[u.symbol.binding]
+	stable := "monolith." + c.name + " of pkg/monolith/v1"
+	s := Suggestion{
+		Pos:    u.pos,
+		Symbol: u.pkg + "." + u.name,
+	}
+	switch u.symbol.kind {
+	case constructor:
+		s.Replacement = u.pkg + ".New" + c.name
+		stable = "monolith.New" + c.name + " of pkg/monolith/v1"
+		switch pos, ok := named[u.symbol.binding]; {
+		case c.caveat != "":
+			s.Hint = fmt.Sprintf("change the arguments, %s", c.caveat)
+		case ok:
+			s.Hint = fmt.Sprintf("the type %s.%s is named at %s, change it to *%s.%s", u.pkg, u.symbol.binding, pos, u.pkg, c.name)
+		default:
+			s.Hint = "the client embeds the binding, its methods are kept"
+			s.Rewrite = true
+		}
+	case bindingType:
+		s.Replacement = u.pkg + "." + c.name
+		s.Hint = fmt.Sprintf("construct it with %s.New%s", u.pkg, c.name)
+	case partial:
+		s.Replacement = u.pkg + "." + c.name
+		s.Hint = "the client calls, transacts and filters, with the options passed to each method"
+	}
+	s.Hint += ", or use " + stable + " when only the client methods are called"
+	return s
+}
+
+// fileUses returns the uses of the symbols of the bindings in f.
+func fileUses(fset *token.FileSet, f *ast.File) []use {
+	names := bindingsImports(f)
+	if len(names) == 0 {
+		return nil
+	}
+	var uses []use
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		// Package names are left unresolved by the parser, unlike the
+		// local identifiers which may shadow them.
+		if !ok || x.Obj != nil || !names[x.Name] {
+			return true
+		}
+		s, ok := symbols[sel.Sel.Name]
+		if !ok {
+			return true
+		}
+		uses = append(uses, use{
+			pos:    fset.Position(sel.Pos()),
+			pkg:    x.Name,
+			name:   sel.Sel.Name,
+			symbol: s,
+		})
+		return true
+	})
+	return uses
+}
+
+// bindingsImports returns the names pkg/bindings is imported as in f.
+func bindingsImports(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != BindingsPath {
+			continue
+		}
+		name := "bindings"
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// Rewrite returns the source of the file at path with the rewritable
+// suggestions of Scan made, and whether any was made.
+func Rewrite(path string, suggestions []Suggestion) ([]byte, bool, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	offsets := make(map[int]string)
+	for _, s := range suggestions {
+		if s.Rewrite && s.Pos.Filename == path {
+			offsets[s.Pos.Offset] = s.Replacement[strings.LastIndex(s.Replacement, ".")+1:]
+		}
+	}
+	if len(offsets) == 0 {
+		return src, false, nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "parsing %s", path)
+	}
+	changed := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if name, ok := offsets[fset.Position(sel.Pos()).Offset]; ok {
+			sel.Sel.Name = name
+			changed = true
+		}
+		return true
+	})
+	if !changed {
+		return src, false, nil
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, false, errors.Wrapf(err, "formatting %s", path)
+	}
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,320 @@
+// Package blockview returns the effect of a block on the state of the
+// contracts as one document: the events the contracts emitted in the block,
+// the parameters those events changed and the balances they moved. The
+// downstream systems processing the chain block by block read one document
+// per block instead of joining the events, the parameters and the balances
+// themselves.
+//
+// A document is built from the events of an indexer store, only once the
+// store indexed the block, and only from the events of the block which is on
+// the chain of the node while it is built. A block reorganised in the
+// meantime fails with ErrReorganized rather than returning a mix of the
+// events of two blocks, and the document is requested again.
+//
+// The balance effects are derived from the events: the ether and tokens
+// moved by the contracts and the Transfer events of the tokens indexed. A
+// movement reported both by a contract and by the Transfer event of the
+// token in the same transaction is counted once.
+package blockview
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Errors of the building of the documents.
+var (
+	// ErrNotIndexed is the cause of the errors of the blocks the store has
+	// not indexed yet.
+	ErrNotIndexed = errors.New("block not indexed")
+	// ErrReorganized is the cause of the errors of the blocks reorganised
+	// since they were indexed or while their document was built.
+	ErrReorganized = errors.New("block reorganized")
+)
+
+// HeaderBackend is the part of the node API used to read the blocks,
+// *ethclient.Client implements it.
+type HeaderBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Block is the effect of a block on the state of the contracts.
+type Block struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parent_hash"`
+	Time       uint64      `json:"time"`
+	// Events are the events of the block in chain order, with their
+	// arguments formatted by indexer.FormatArg.
+	Events []indexer.Event `json:"events"`
+	// Changes are the parameters of the contracts changed by the events.
+	Changes []Change `json:"changes"`
+	// Balances are the net balance changes of the accounts, sorted by
+	// account and asset.
+	Balances []Balance `json:"balances"`
+}
+
+// Change is a parameter of a contract changed by an event.
+type Change struct {
+	Contract  string         `json:"contract"`
+	Address   common.Address `json:"address"`
+	Parameter string         `json:"parameter"`
+	Event     string         `json:"event"`
+	TxHash    common.Hash    `json:"tx_hash"`
+	LogIndex  uint           `json:"log_index"`
+	// Args are the arguments of the event, the new value of the parameter.
+	Args map[string]interface{} `json:"args"`
+}
+
+// Balance is the net change of the balance of an asset of an account in a
+// block. The asset of ether is the zero address.
+type Balance struct {
+	Account common.Address `json:"account"`
+	Asset   common.Address `json:"asset"`
+	Delta   string         `json:"delta"`
+}
+
+// Parameters are the parameters changed by the events of the contracts, by
+// event name.
+var Parameters = map[string]string{
+	"UpdatedLicenceAmount":      "licence_amount",
+	"UpdatedCryptoFloat":        "crypto_float",
+	"UpdatedTokenHolder":        "token_holder",
+	"UpdatedLicenceDAO":         "licence_dao",
+	"UpdatedTKNContractAddress": "tkn_contract",
+	"UpdatedTokenRate":          "token_rate",
+	"UpdatedTokenLoadable":      "token_loadable",
+	"UpdatedTokenRedeemable":    "token_redeemable",
+	"AddedToken":                "tokens",
+	"RemovedToken":              "tokens",
+	"AddedMethodId":             "method_ids",
+	"RemovedMethodId":           "method_ids",
+	"AddedExclusiveMethod":      "exclusive_methods",
+	"RemovedExclusiveMethod":    "exclusive_methods",
+	"SetGasPrice":               "gas_price",
+	"SetCryptoComparePublicKey": "crypto_compare_public_key",
+	"AddedAdmin":                "admins",
+	"RemovedAdmin":              "admins",
+	"AddedController":           "controllers",
+	"RemovedController":         "controllers",
+	"Started":                   "stopped",
+	"Stopped":                   "stopped",
+	"TransferredOwnership":      "owner",
+	"LockedOwnership":           "ownership_locked",
+	"SetSpendLimit":             "spend_limit",
+	"SetGasTopUpLimit":          "gas_top_up_limit",
+	"SetLoadLimit":              "load_limit",
+	"AddedToWhitelist":          "whitelist",
+	"RemovedFromWhitelist":      "whitelist",
+}
+
+// movement reads the asset moved by an event: the arguments holding the
+// sender, the recipient, the asset and the amount, the emitter standing for
+// an empty one and ether for an empty asset.
+type movement struct {
+	from, to, asset, amount string
+}
+
+// movements are the events of the contracts moving assets. The loads of the
+// TokenCard are the movements of the Licence paying the float and the token
+// holder.
+var movements = map[string]movement{
+	"Received":                 {from: "_from", amount: "_amount"},
+	"Claimed":                  {to: "_to", asset: "_asset", amount: "_amount"},
+	"Transferred":              {to: "_to", asset: "_asset", amount: "_amount"},
+	"CashAndBurned":            {to: "_to", asset: "_asset", amount: "_amount"},
+	"ToppedUpGas":              {to: "_owner", amount: "_amount"},
+	"TransferredToCryptoFloat": {from: "_from", to: "_to", asset: "_asset", amount: "_amount"},
+	"TransferredToTokenHolder": {from: "_from", to: "_to", asset: "_asset", amount: "_amount"},
+}
+
+// transfer is an asset moved in a transaction.
+type transfer struct {
+	tx       common.Hash
+	from, to common.Address
+	asset    common.Address
+	amount   string
+}
+
+// View returns the effect of block number on the contracts indexed in store,
+// reading the block from backend. It fails with ErrNotIndexed until the
+// store indexed the block and with ErrReorganized when the block of the
+// events is no longer on the chain of the node.
+func View(ctx context.Context, store indexer.Store, backend HeaderBackend, number uint64) (*Block, error) {
+	head, ok := store.Head()
+	if !ok || head < number {
+		return nil, errors.Wrapf(ErrNotIndexed, "block %d, the store indexed up to block %d", number, head)
+	}
+	header, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting header of block %d", number)
+	}
+	hash := header.Hash()
+
+	stored, err := store.Events(indexer.Query{FromBlock: number, ToBlock: number})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting events of block %d", number)
+	}
+	b := &Block{
+		Number:     number,
+		Hash:       hash,
+		ParentHash: header.ParentHash,
+		Time:       header.Time,
+		Events:     []indexer.Event{},
+		Changes:    []Change{},
+		Balances:   []Balance{},
+	}
+	var events []indexer.Event
+	for _, e := range stored {
+		if e.BlockNumber != number || e.Removed {
+			continue
+		}
+		if e.BlockHash != hash {
+			return nil, errors.Wrapf(ErrReorganized, "block %d is %s, the store has the events of %s", number, hash.Hex(), e.BlockHash.Hex())
+		}
+		events = append(events, e)
+	}
+
+	for _, e := range events {
+		args := make(map[string]interface{}, len(e.Args))
+		for k, v := range e.Args {
+			args[k] = indexer.FormatArg(v)
+		}
+		if p, ok := Parameters[e.Name]; ok {
+			b.Changes = append(b.Changes, Change{
+				Contract:  e.Contract,
+				Address:   e.Address,
+				Parameter: p,
+				Event:     e.Name,
+				TxHash:    e.TxHash,
+				LogIndex:  e.LogIndex,
+				Args:      args,
+			})
+		}
+		e.Args = args
+		b.Events = append(b.Events, e)
+	}
+	b.Balances = balances(transfers(b.Events))
+
+	// The header is read again: the events are those of the block only if
+	// it is still on the chain.
+	header, err = backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting header of block %d", number)
+	}
+	if header.Hash() != hash {
+		return nil, errors.Wrapf(ErrReorganized, "block %d was reorganized from %s to %s", number, hash.Hex(), header.Hash().Hex())
+	}
+	return b, nil
+}
+
+// transfers returns the assets moved by the events. The movements of the
+// contracts also reported by a Transfer event of the token are dropped.
+func transfers(events []indexer.Event) []transfer {
+	var r []transfer
+	reported := make(map[transfer]int)
+	for _, e := range events {
+		if e.Name != "Transfer" || e.Args["_value"] == nil {
+			continue
+		}
+		t := transfer{
+			tx:     e.TxHash,
+			from:   address(e.Args["_from"]),
+			to:     address(e.Args["_to"]),
+			asset:  e.Address,
+			amount: amount(e.Args["_value"]),
+		}
+		reported[t]++
+		r = append(r, t)
+	}
+	for _, e := range events {
+		m, ok := movements[e.Name]
+		if !ok {
+			continue
+		}
+		t := transfer{
+			tx:     e.TxHash,
+			from:   e.Address,
+			to:     e.Address,
+			amount: amount(e.Args[m.amount]),
+		}
+		if m.from != "" {
+			t.from = address(e.Args[m.from])
+		}
+		if m.to != "" {
+			t.to = address(e.Args[m.to])
+		}
+		if m.asset != "" {
+			t.asset = address(e.Args[m.asset])
+		}
+		if reported[t] > 0 {
+			reported[t]--
+			continue
+		}
+		r = append(r, t)
+	}
+	return r
+}
+
+// balances returns the net balance changes of the transfers.
+func balances(transfers []transfer) []Balance {
+	type key struct{ account, asset common.Address }
+	deltas := make(map[key]*big.Int)
+	add := func(k key, v *big.Int) {
+		d, ok := deltas[k]
+		if !ok {
+			d = new(big.Int)
+			deltas[k] = d
+		}
+		d.Add(d, v)
+	}
+	for _, t := range transfers {
+		v, ok := new(big.Int).SetString(t.amount, 10)
+		if !ok || t.from == t.to {
+			continue
+		}
+		add(key{t.from, t.asset}, new(big.Int).Neg(v))
+		add(key{t.to, t.asset}, v)
+	}
+	r := []Balance{}
+	for k, d := range deltas {
+		if d.Sign() == 0 {
+			continue
+		}
+		r = append(r, Balance{Account: k.account, Asset: k.asset, Delta: d.String()})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Account != r[j].Account {
+			return bytes.Compare(r[i].Account[:], r[j].Account[:]) < 0
+		}
+		return bytes.Compare(r[i].Asset[:], r[j].Asset[:]) < 0
+	})
+	return r
+}
+
+// address reads an address argument, decoded, stored as the raw topic of an
+// indexed argument or formatted as a string.
+func address(v interface{}) common.Address {
+	switch v := v.(type) {
+	case common.Address:
+		return v
+	case common.Hash:
+		return common.BytesToAddress(v.Bytes())
+	case string:
+		return common.HexToAddress(v)
+	}
+	return common.Address{}
+}
+
+// amount reads an integer argument as a decimal string.
+func amount(v interface{}) string {
+	s, _ := indexer.FormatArg(v).(string)
+	return s
+}
@@ -0,0 +1,57 @@
+package blockview
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler serves the documents of the blocks of the store:
+//
+//	GET /blocks/{number}    the Block
+//
+// A block not indexed yet is not found, and a block reorganised while its
+// document was built is a conflict: it is requested again.
+func NewHandler(store indexer.Store, backend HeaderBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("%s %s not allowed", req.Method, req.URL.Path))
+			return
+		}
+		param := strings.TrimPrefix(req.URL.Path, "/blocks/")
+		number, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Errorf("invalid block number %q", param))
+			return
+		}
+		b, err := View(req.Context(), store, backend, number)
+		switch errors.Cause(err) {
+		case nil:
+			writeJSON(w, http.StatusOK, b)
+		case ErrNotIndexed:
+			writeError(w, http.StatusNotFound, err)
+		case ErrReorganized:
+			writeError(w, http.StatusConflict, err)
+		default:
+			writeError(w, http.StatusBadGateway, err)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
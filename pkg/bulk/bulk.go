@@ -0,0 +1,336 @@
+// Package bulk sends the transfers of an ERC20 token to a list of receivers,
+// e.g. to consolidate the tokens held by the treasury:
+//
+//	transfers, err := bulk.ReadTransfersFile("transfers.csv")
+//	...
+//	b := bulk.New(token, backend, client, opts, "transfers.journal.jsonl")
+//	rejected, err := b.Preflight(ctx, transfers)
+//	...
+//	report, err := b.Run(ctx, transfers)
+//
+// The transfers are sent in chunks, the transactions of a chunk together with
+// consecutive nonces and waited for before the next chunk. Each transaction
+// is journaled when it is sent and again once mined, running the batch again
+// resumes it: the transfers mined are skipped, the failed ones sent again and
+// those sent without a receipt yet never sent twice. The report verifies the
+// Transfer event of every journaled transaction on the chain.
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultChunkSize is the default number of transactions sent together.
+const DefaultChunkSize = 20
+
+// ErrBalance is the cause of the error of Preflight when the sender does not
+// hold the total of the transfers left.
+var ErrBalance = errors.New("balance too low")
+
+const tokenABI = `[
+{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}
+]`
+
+var parsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(tokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// The statuses of the results.
+const (
+	Sent   = "sent"
+	Mined  = "mined"
+	Failed = "failed"
+)
+
+// Result is the outcome of a transfer, a line of the journal. The last line
+// of a receiver is its current outcome.
+type Result struct {
+	To          common.Address `json:"to"`
+	Amount      *big.Int       `json:"amount"`
+	Status      string         `json:"status"`
+	TxHash      common.Hash    `json:"tx_hash,omitempty"`
+	BlockNumber uint64         `json:"block_number,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Rejection is a transfer refused by the preflight.
+type Rejection struct {
+	To     common.Address `json:"to"`
+	Reason string         `json:"reason"`
+}
+
+// Batch sends the transfers of a list.
+type Batch struct {
+	token    common.Address
+	contract *bind.BoundContract
+	backend  bind.ContractBackend
+	receipts bind.DeployBackend
+	opts     *bind.TransactOpts
+	journal  string
+
+	// ChunkSize is the number of transactions sent together,
+	// DefaultChunkSize when zero.
+	ChunkSize int
+	// AllowContracts lets the preflight accept the contracts as receivers,
+	// the tokens sent to a contract unable to move them are lost.
+	AllowContracts bool
+	// Progress is called after each transfer mined or failed with the
+	// number of transfers done out of the total, the skipped ones included.
+	Progress func(done, total int, r Result)
+}
+
+// New returns a batch sending the transfers of token with opts through
+// backend, waiting for their receipts from receipts and journaling them in
+// the journal file, created when missing.
+func New(token common.Address, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, journal string) *Batch {
+	return &Batch{
+		token:    token,
+		contract: bind.NewBoundContract(token, parsedABI, backend, backend, backend),
+		backend:  backend,
+		receipts: receipts,
+		opts:     opts,
+		journal:  journal,
+	}
+}
+
+// results returns the current outcome of each receiver of the journal.
+func (b *Batch) results() (map[common.Address]Result, error) {
+	results := make(map[common.Address]Result)
+	f, err := os.Open(b.journal)
+	if os.IsNotExist(err) {
+		return results, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal")
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		var r Result
+		err := json.Unmarshal(s.Bytes(), &r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "journal line %d", n)
+		}
+		results[r.To] = r
+	}
+	return results, errors.Wrap(s.Err(), "reading journal")
+}
+
+// pending returns the transfers to send, those neither mined nor sent.
+func pending(transfers []Transfer, results map[common.Address]Result) []Transfer {
+	var pending []Transfer
+	for _, t := range transfers {
+		if r, ok := results[t.To]; !ok || r.Status == Failed {
+			pending = append(pending, t)
+		}
+	}
+	return pending
+}
+
+// Preflight checks the transfers left to send: it rejects the receivers
+// which are the token or the sender, the contracts unless AllowContracts,
+// and the transfers failing when simulated. The error is caused by
+// ErrBalance when the sender does not hold their total.
+func (b *Batch) Preflight(ctx context.Context, transfers []Transfer) ([]Rejection, error) {
+	results, err := b.results()
+	if err != nil {
+		return nil, err
+	}
+	var rejected []Rejection
+	total := new(big.Int)
+	for _, t := range pending(transfers, results) {
+		total.Add(total, t.Amount)
+		reason, err := b.preflight(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			rejected = append(rejected, Rejection{To: t.To, Reason: reason})
+		}
+	}
+
+	balance := new(big.Int)
+	err = b.contract.Call(&bind.CallOpts{Context: ctx}, &balance, "balanceOf", b.opts.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting balance of sender")
+	}
+	if balance.Cmp(total) < 0 {
+		return rejected, errors.Wrapf(ErrBalance, "%s held for %s to transfer", balance, total)
+	}
+	return rejected, nil
+}
+
+// preflight returns why the transfer is rejected, if it is.
+func (b *Batch) preflight(ctx context.Context, t Transfer) (string, error) {
+	switch t.To {
+	case b.token:
+		return "receiver is the token contract", nil
+	case b.opts.From:
+		return "receiver is the sender", nil
+	}
+	if !b.AllowContracts {
+		code, err := b.backend.CodeAt(ctx, t.To, nil)
+		if err != nil {
+			return "", errors.Wrapf(err, "getting code of %s", t.To.Hex())
+		}
+		if len(code) > 0 {
+			return "receiver is a contract", nil
+		}
+	}
+	data, err := parsedABI.Pack("transfer", t.To, t.Amount)
+	if err != nil {
+		return "", errors.Wrap(err, "packing transfer")
+	}
+	out, err := b.backend.CallContract(ctx, ethereum.CallMsg{From: b.opts.From, To: &b.token, Data: data}, nil)
+	if err != nil {
+		return "transfer fails: " + err.Error(), nil
+	}
+	// The tokens not returning the success of the transfer return nothing.
+	if len(out) > 0 && new(big.Int).SetBytes(out).Sign() == 0 {
+		return "transfer returns false", nil
+	}
+	return "", nil
+}
+
+// Run sends the transfers not done by a previous run, journaling them, and
+// returns the report of the batch. The run stops after the chunk of a failed
+// transfer, the next run sends it again.
+func (b *Batch) Run(ctx context.Context, transfers []Transfer) (*Report, error) {
+	results, err := b.results()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(b.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal")
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	record := func(r Result) error {
+		results[r.To] = r
+		return errors.Wrap(enc.Encode(r), "writing journal")
+	}
+
+	// The transactions sent by an interrupted run are settled first, those
+	// without a receipt yet are left to the next run.
+	for _, r := range results {
+		if r.Status != Sent {
+			continue
+		}
+		receipt, err := b.receipt(ctx, r.TxHash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt == nil {
+			continue
+		}
+		err = record(settle(r, receipt))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	size := b.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	todo := pending(transfers, results)
+	count := len(transfers) - len(todo)
+	for len(todo) > 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		chunk := todo
+		if len(chunk) > size {
+			chunk = chunk[:size]
+		}
+		todo = todo[len(chunk):]
+
+		opts := *b.opts
+		opts.Context = ctx
+		var sent []*types.Transaction
+		var sendErr error
+		for _, t := range chunk {
+			tx, err := b.contract.Transact(&opts, "transfer", t.To, t.Amount)
+			if err != nil {
+				// The transfers already sent are waited for, they are
+				// mined anyway.
+				sendErr = errors.Wrapf(err, "sending transfer to %s", t.To.Hex())
+				break
+			}
+			err = record(Result{To: t.To, Amount: t.Amount, Status: Sent, TxHash: tx.Hash()})
+			if err != nil {
+				return nil, err
+			}
+			sent = append(sent, tx)
+		}
+
+		failed := 0
+		for i, tx := range sent {
+			receipt, err := bind.WaitMined(ctx, b.receipts, tx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "waiting for transaction %s", tx.Hash().Hex())
+			}
+			r := settle(results[chunk[i].To], receipt)
+			if r.Status == Failed {
+				failed++
+			}
+			err = record(r)
+			if err != nil {
+				return nil, err
+			}
+			count++
+			if b.Progress != nil {
+				b.Progress(count, len(transfers), r)
+			}
+		}
+		if sendErr != nil {
+			return nil, sendErr
+		}
+		if failed > 0 {
+			return nil, errors.Errorf("%d transfers of the chunk failed, run the batch again to retry them", failed)
+		}
+	}
+	return b.verify(ctx, transfers, results)
+}
+
+// settle returns the outcome of a transfer sent, from its receipt.
+func settle(r Result, receipt *types.Receipt) Result {
+	r.BlockNumber = receipt.BlockNumber.Uint64()
+	r.Status = Mined
+	r.Error = ""
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		r.Status = Failed
+		r.Error = "transaction reverted"
+	}
+	return r
+}
+
+// receipt returns the receipt of a transaction, nil when it is not mined.
+func (b *Batch) receipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	r, err := b.receipts.TransactionReceipt(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting receipt of transaction %s", hash.Hex())
+	}
+	return r, nil
+}
@@ -0,0 +1,103 @@
+package bulk
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Report verifies the journal of a batch against the chain.
+type Report struct {
+	Transfers int `json:"transfers"`
+	// Verified is the number of transfers whose journaled transaction
+	// holds their Transfer event.
+	Verified int `json:"verified"`
+	// Missing are the transfers not mined, to send again.
+	Missing []Transfer `json:"missing,omitempty"`
+	// Unconfirmed are the transfers sent without a receipt yet, they must
+	// not be sent again before they are mined or dropped by the node.
+	Unconfirmed []Result `json:"unconfirmed,omitempty"`
+	// Mismatched are the transfers whose journaled transaction does not
+	// hold their Transfer event.
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+// Mismatch is a transfer whose journaled transaction does not hold its
+// Transfer event.
+type Mismatch struct {
+	To     common.Address `json:"to"`
+	TxHash common.Hash    `json:"tx_hash"`
+	Reason string         `json:"reason"`
+}
+
+// Complete tells whether every transfer is verified.
+func (r *Report) Complete() bool {
+	return r.Verified == r.Transfers
+}
+
+// Verify returns the report of the batch of the transfers, from its journal
+// and the chain, without sending any transfer.
+func (b *Batch) Verify(ctx context.Context, transfers []Transfer) (*Report, error) {
+	results, err := b.results()
+	if err != nil {
+		return nil, err
+	}
+	return b.verify(ctx, transfers, results)
+}
+
+func (b *Batch) verify(ctx context.Context, transfers []Transfer, results map[common.Address]Result) (*Report, error) {
+	report := &Report{Transfers: len(transfers)}
+	for _, t := range transfers {
+		r, ok := results[t.To]
+		if !ok || r.Status == Failed {
+			report.Missing = append(report.Missing, t)
+			continue
+		}
+		receipt, err := b.receipt(ctx, r.TxHash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt == nil {
+			if r.Status == Sent {
+				report.Unconfirmed = append(report.Unconfirmed, r)
+			} else {
+				// Mined in a block reorganised away.
+				report.Mismatched = append(report.Mismatched, Mismatch{To: t.To, TxHash: r.TxHash, Reason: "transaction not found"})
+			}
+			continue
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			report.Missing = append(report.Missing, t)
+			continue
+		}
+		if reason := b.check(receipt, t); reason != "" {
+			report.Mismatched = append(report.Mismatched, Mismatch{To: t.To, TxHash: r.TxHash, Reason: reason})
+			continue
+		}
+		report.Verified++
+	}
+	return report, nil
+}
+
+// check returns why the receipt does not hold the Transfer event of t, if it
+// does not.
+func (b *Batch) check(receipt *types.Receipt, t Transfer) string {
+	for _, l := range receipt.Logs {
+		if l.Address != b.token || len(l.Topics) != 3 || l.Topics[0] != indexer.TransferTopic {
+			continue
+		}
+		from, to := common.BytesToAddress(l.Topics[1].Bytes()), common.BytesToAddress(l.Topics[2].Bytes())
+		if from != b.opts.From || to != t.To {
+			continue
+		}
+		amount := new(big.Int).SetBytes(l.Data)
+		if amount.Cmp(t.Amount) != 0 {
+			return "transferred " + amount.String() + " instead of " + t.Amount.String()
+		}
+		return ""
+	}
+	return "no Transfer event to the receiver"
+}
@@ -0,0 +1,86 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/units"
+)
+
+// Transfer is a transfer of an amount of the token to a receiver.
+type Transfer struct {
+	To     common.Address `json:"to"`
+	Amount *big.Int       `json:"amount"`
+}
+
+// ReadTransfersFile reads the transfers of a CSV file, see ReadTransfers.
+func ReadTransfersFile(path string) ([]Transfer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening transfers")
+	}
+	defer f.Close()
+	transfers, err := ReadTransfers(f)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return transfers, nil
+}
+
+// ReadTransfers reads a CSV list of transfers, the receiver and the amount in
+// the smallest unit of the token in the first two columns of each row, after
+// an optional header row. The list is rejected as a whole when a receiver is
+// invalid, has a wrong checksum, is the zero address or is listed twice, or
+// an amount is not a positive integer.
+func ReadTransfers(r io.Reader) ([]Transfer, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading CSV")
+	}
+	var transfers []Transfer
+	seen := make(map[common.Address]int)
+	for i, row := range rows {
+		if i == 0 && len(row) > 0 && !strings.HasPrefix(strings.TrimSpace(row[0]), "0x") {
+			// The header.
+			continue
+		}
+		if len(row) < 2 {
+			return nil, errors.Errorf("line %d: expected a receiver and an amount", i+1)
+		}
+		to, err := parseReceiver(strings.TrimSpace(row[0]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		amount, err := units.ParseUint(strings.TrimSpace(row[1]))
+		if err != nil || amount.Sign() == 0 {
+			return nil, errors.Errorf("line %d: %q is not a positive amount", i+1, row[1])
+		}
+		if first, ok := seen[to]; ok {
+			return nil, errors.Errorf("line %d: %s already listed on line %d", i+1, to.Hex(), first)
+		}
+		seen[to] = i + 1
+		transfers = append(transfers, Transfer{To: to, Amount: amount})
+	}
+	return transfers, nil
+}
+
+// parseReceiver parses an address, checking its checksum when it has one.
+func parseReceiver(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, errors.Errorf("%q is not an address", s)
+	}
+	a := common.HexToAddress(s)
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) && "0x"+hexPart != a.Hex() {
+		return common.Address{}, errors.Errorf("%s has an invalid checksum", s)
+	}
+	if a == (common.Address{}) {
+		return common.Address{}, errors.New("the zero address can not be a receiver")
+	}
+	return a, nil
+}
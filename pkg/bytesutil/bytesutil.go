@@ -0,0 +1,64 @@
+// Package bytesutil is a native implementation of the BytesUtils library of
+// the contracts, slicing the same values out of the same bytes and failing
+// on the same inputs, without a call to the chain.
+//
+// The start position is read as a uint256 like the contracts do: negative
+// values wrap around, as they do when passed to the bindings.
+package bytesutil
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/pkg/errors"
+)
+
+// Errors matching the revert reasons of the library.
+var (
+	ErrOutOfRange = errors.New("slicing out of range")
+	ErrOverflow   = errors.New("SafeMath: addition overflow")
+)
+
+// slice returns the n bytes of b starting at from.
+func slice(b []byte, from *big.Int, n int64) ([]byte, error) {
+	start := math.U256(new(big.Int).Set(from))
+	end := new(big.Int).Add(start, big.NewInt(n))
+	if end.BitLen() > 256 {
+		return nil, ErrOverflow
+	}
+	if end.Cmp(big.NewInt(int64(len(b)))) > 0 {
+		return nil, ErrOutOfRange
+	}
+	return b[start.Int64():end.Int64()], nil
+}
+
+// BytesToAddress returns the address in the 20 bytes of b starting at from.
+func BytesToAddress(b []byte, from *big.Int) (common.Address, error) {
+	s, err := slice(b, from, common.AddressLength)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(s), nil
+}
+
+// BytesToBytes4 returns the 4 bytes of b starting at from.
+func BytesToBytes4(b []byte, from *big.Int) ([4]byte, error) {
+	var b4 [4]byte
+	s, err := slice(b, from, 4)
+	if err != nil {
+		return b4, err
+	}
+	copy(b4[:], s)
+	return b4, nil
+}
+
+// BytesToUint256 returns the big endian uint256 in the 32 bytes of b starting
+// at from.
+func BytesToUint256(b []byte, from *big.Int) (*big.Int, error) {
+	s, err := slice(b, from, 32)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(s), nil
+}
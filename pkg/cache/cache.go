@@ -0,0 +1,309 @@
+// Package cache caches the results of the contract calls, so that the same
+// view methods called over and over, such as the owner, the licence amount or
+// the rates of the token whitelist, do not hammer the node.
+//
+// Backend keys the results by contract, sender, call data and block. The
+// results of the calls at a given block are kept for TTL. The results of the
+// calls at the latest block are also dropped when Run sees a new head, and
+// when the indexer hands an event of the contract to HandleEvents, so that a
+// change made by a transaction is seen as soon as it is mined.
+//
+// For example:
+//
+//	calls := cache.NewBackend(client, client)
+//	go calls.Run(ctx)
+//	licence, err := bindings.NewLicence(address, calls)
+//
+// The failed calls are not cached, nor the results of the calls in flight
+// while results are invalidated. The hits, misses and invalidations are
+// recorded in a go-ethereum metrics registry as cache/hits, cache/misses and
+// cache/invalidations, with the number of cached results as cache/entries.
+package cache
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+const (
+	// DefaultTTL is how long a result is cached when TTL is not set.
+	DefaultTTL = 15 * time.Second
+	// DefaultInterval is the delay between two polls of the head of the
+	// chain when Interval is not set.
+	DefaultInterval = 4 * time.Second
+	// DefaultMaxEntries is the number of results cached when MaxEntries is
+	// not set.
+	DefaultMaxEntries = 10000
+)
+
+// latest is the block of the key of the calls at the latest block.
+const latest = -1
+
+// HeaderReader returns the head of the chain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// key identifies the result of a call.
+type key struct {
+	contract common.Address
+	from     common.Address
+	data     string
+	block    int64
+}
+
+type entry struct {
+	out     []byte
+	expires time.Time
+}
+
+// Stats are the counts of the cache since it was created.
+type Stats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Invalidations int64 `json:"invalidations"`
+	Entries       int   `json:"entries"`
+}
+
+// Backend is a bind.ContractBackend caching the results of the contract
+// calls.
+type Backend struct {
+	bind.ContractBackend
+	headers HeaderReader
+
+	// TTL is how long a result is cached.
+	TTL time.Duration
+	// Interval is the delay between two polls of the head made by Run.
+	Interval time.Duration
+	// MaxEntries is the number of results cached, an arbitrary result is
+	// evicted to make room for a new one once the expired ones are.
+	MaxEntries int
+	// Events are the events invalidating the results of their contract, as
+	// contract.Event like the indexer names them, e.g.
+	// "licence.UpdatedLicenceAmount". Every event does when empty.
+	Events []string
+	// Registry receives the metrics of the cache when set.
+	Registry metrics.Registry
+	// Logger receives the invalidations.
+	Logger logging.Logger
+
+	hits, misses, invalidations int64
+
+	mu      sync.Mutex
+	entries map[key]entry
+	head    uint64
+	// generation counts the invalidations, so that the result of a call
+	// made before one is not stored after it.
+	generation uint64
+}
+
+// NewBackend wraps a backend, reading the head of the chain from headers.
+func NewBackend(backend bind.ContractBackend, headers HeaderReader) *Backend {
+	return &Backend{
+		ContractBackend: backend,
+		headers:         headers,
+		TTL:             DefaultTTL,
+		Interval:        DefaultInterval,
+		MaxEntries:      DefaultMaxEntries,
+		entries:         make(map[key]entry),
+	}
+}
+
+// CallContract implements bind.ContractCaller.
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	// The calls creating a contract, or at the pending block, are not
+	// cached.
+	if call.To == nil || (block != nil && block.Sign() < 0) {
+		return b.ContractBackend.CallContract(ctx, call, block)
+	}
+	k := key{contract: *call.To, from: call.From, data: string(call.Data), block: latest}
+	if block != nil {
+		k.block = block.Int64()
+	}
+
+	now := time.Now()
+	b.mu.Lock()
+	e, ok := b.entries[k]
+	generation := b.generation
+	b.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		b.count(&b.hits, "cache/hits")
+		return append([]byte(nil), e.out...), nil
+	}
+	b.count(&b.misses, "cache/misses")
+
+	out, err := b.ContractBackend.CallContract(ctx, call, block)
+	if err != nil {
+		return nil, err
+	}
+	b.store(k, entry{out: append([]byte(nil), out...), expires: now.Add(b.ttl())}, generation)
+	return out, nil
+}
+
+// store caches the result of a call made at the given generation, unless
+// results were invalidated since, as it may predate the invalidation.
+func (b *Backend) store(k key, e entry, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	max := b.MaxEntries
+	if max <= 0 {
+		max = DefaultMaxEntries
+	}
+	if _, ok := b.entries[k]; !ok && len(b.entries) >= max {
+		now := time.Now()
+		for k, e := range b.entries {
+			if !now.Before(e.expires) {
+				delete(b.entries, k)
+			}
+		}
+		for k := range b.entries {
+			if len(b.entries) < max {
+				break
+			}
+			delete(b.entries, k)
+		}
+	}
+	b.entries[k] = e
+	b.gauge()
+}
+
+// Run polls the head of the chain every Interval until the context is done,
+// dropping the results of the calls at the latest block when it changes.
+func (b *Backend) Run(ctx context.Context) {
+	interval := b.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		h, err := b.headers.HeaderByNumber(ctx, nil)
+		if err != nil {
+			b.logger().Debug("Getting the head for the call cache failed", "err", err)
+		} else {
+			b.SetHead(h.Number.Uint64())
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SetHead records the head of the chain, dropping the results of the calls
+// at the latest block when it changed.
+func (b *Backend) SetHead(number uint64) {
+	b.mu.Lock()
+	changed := number != b.head
+	b.head = number
+	b.mu.Unlock()
+	if changed {
+		b.invalidate(func(k key) bool { return k.block == latest })
+	}
+}
+
+// HandleEvents implements indexer.Handler, dropping the results of the calls
+// at the latest block of the contracts which emitted the events.
+func (b *Backend) HandleEvents(ctx context.Context, events []indexer.Event) error {
+	contracts := make(map[common.Address]bool)
+	for _, e := range events {
+		if b.invalidates(e) {
+			contracts[e.Address] = true
+		}
+	}
+	if len(contracts) > 0 {
+		b.invalidate(func(k key) bool { return k.block == latest && contracts[k.contract] })
+	}
+	return nil
+}
+
+func (b *Backend) invalidates(e indexer.Event) bool {
+	if len(b.Events) == 0 {
+		return true
+	}
+	for _, name := range b.Events {
+		if name == e.Contract+"."+e.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate drops the cached results of the calls to a contract.
+func (b *Backend) Invalidate(contract common.Address) {
+	b.invalidate(func(k key) bool { return k.contract == contract })
+}
+
+func (b *Backend) invalidate(match func(k key) bool) {
+	b.mu.Lock()
+	b.generation++
+	n := 0
+	for k := range b.entries {
+		if match(k) {
+			delete(b.entries, k)
+			n++
+		}
+	}
+	b.gauge()
+	b.mu.Unlock()
+	if n > 0 {
+		atomic.AddInt64(&b.invalidations, int64(n))
+		if b.Registry != nil {
+			metrics.GetOrRegisterCounter("cache/invalidations", b.Registry).Inc(int64(n))
+		}
+		b.logger().Debug("Call results invalidated", "results", n)
+	}
+}
+
+// Stats returns the counts of the cache.
+func (b *Backend) Stats() Stats {
+	b.mu.Lock()
+	entries := len(b.entries)
+	b.mu.Unlock()
+	return Stats{
+		Hits:          atomic.LoadInt64(&b.hits),
+		Misses:        atomic.LoadInt64(&b.misses),
+		Invalidations: atomic.LoadInt64(&b.invalidations),
+		Entries:       entries,
+	}
+}
+
+func (b *Backend) count(n *int64, name string) {
+	atomic.AddInt64(n, 1)
+	if b.Registry != nil {
+		metrics.GetOrRegisterCounter(name, b.Registry).Inc(1)
+	}
+}
+
+// gauge records the number of entries, b.mu must be held.
+func (b *Backend) gauge() {
+	if b.Registry != nil {
+		metrics.GetOrRegisterGauge("cache/entries", b.Registry).Update(int64(len(b.entries)))
+	}
+}
+
+func (b *Backend) ttl() time.Duration {
+	if b.TTL <= 0 {
+		return DefaultTTL
+	}
+	return b.TTL
+}
+
+func (b *Backend) logger() logging.Logger {
+	return logging.Or(b.Logger)
+}
@@ -0,0 +1,217 @@
+// Package canary verifies the transaction pipeline end to end before real
+// operations are enabled, e.g. after deploying to a new network or rotating
+// keys.
+//
+// The canary sends a benign transaction, an approval of 1 token unit by the
+// operator to itself, and checks each stage the transaction goes through:
+// it is signed, broadcast, mined, indexed and reported as an alert. The Gate
+// holds back the state changing API requests until a canary has passed.
+package canary
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// TokenABI is the subset of the ERC20 ABI used by the canary transaction,
+// which can be used to index the token.
+const TokenABI = `[{"constant":false,"inputs":[{"name":"_spender","type":"address"},{"name":"_value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_owner","type":"address"},{"indexed":true,"name":"_spender","type":"address"},{"indexed":false,"name":"_value","type":"uint256"}],"name":"Approval","type":"event"}]`
+
+// Steps of the pipeline checked by the canary, in order.
+const (
+	Sign      = "sign"
+	Broadcast = "broadcast"
+	Mine      = "mine"
+	Index     = "index"
+	Alert     = "alert"
+)
+
+// DefaultTimeout is the time a canary waits for its transaction to go
+// through the pipeline when Timeout is not set.
+const DefaultTimeout = 5 * time.Minute
+
+// StepResult is the outcome of a step of the pipeline.
+type StepResult struct {
+	Step     string        `json:"step"`
+	Duration time.Duration `json:"duration"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// Report is the outcome of a canary.
+type Report struct {
+	TxHash common.Hash  `json:"tx_hash,omitempty"`
+	Steps  []StepResult `json:"steps"`
+	Passed bool         `json:"passed"`
+}
+
+// Canary sends the canary transaction with Opts through Backend.
+type Canary struct {
+	Backend  bind.ContractBackend
+	Receipts bind.DeployBackend
+	Opts     *bind.TransactOpts
+	// Token is the ERC20 token approved by the canary transaction.
+	Token common.Address
+	// Indexed reports whether the events of the transaction were indexed,
+	// the index step is skipped when nil.
+	Indexed func(ctx context.Context, tx common.Hash) (bool, error)
+	// Alert reports the passed canary, the alert step is skipped when nil.
+	Alert func(ctx context.Context, r Report) error
+	// Timeout bounds the whole canary, DefaultTimeout when zero.
+	Timeout time.Duration
+	// PollInterval is the delay between two checks of the index.
+	PollInterval time.Duration
+	Logger       logging.Logger
+}
+
+// Run sends the canary transaction and follows it through the pipeline. It
+// returns the report of the steps run and the error of the failed step.
+func (c *Canary) Run(ctx context.Context) (Report, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var r Report
+	step := func(name string, fn func() error) error {
+		start := time.Now()
+		err := fn()
+		res := StepResult{Step: name, Duration: time.Since(start)}
+		if err != nil {
+			res.Err = err.Error()
+			logging.Or(c.Logger).Error("Canary failed", "step", name, "err", err)
+		}
+		r.Steps = append(r.Steps, res)
+		return errors.Wrapf(err, "canary %s", name)
+	}
+
+	tx, err := c.send(ctx, step)
+	if err != nil {
+		return r, err
+	}
+	r.TxHash = tx.Hash()
+
+	err = step(Mine, func() error {
+		receipt, err := bind.WaitMined(ctx, c.Receipts, tx)
+		if err != nil {
+			return err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return errors.Errorf("transaction %s reverted", tx.Hash().Hex())
+		}
+		return nil
+	})
+	if err != nil {
+		return r, err
+	}
+
+	if c.Indexed != nil {
+		err = step(Index, func() error {
+			return c.waitIndexed(ctx, tx.Hash())
+		})
+		if err != nil {
+			return r, err
+		}
+	}
+
+	r.Passed = true
+	if c.Alert != nil {
+		err = step(Alert, func() error {
+			return c.Alert(ctx, r)
+		})
+		if err != nil {
+			r.Passed = false
+			return r, err
+		}
+	}
+	logging.Or(c.Logger).Info("Canary passed", "hash", tx.Hash())
+	return r, nil
+}
+
+// send signs and broadcasts the canary transaction as two steps.
+func (c *Canary) send(ctx context.Context, step func(string, func() error) error) (*types.Transaction, error) {
+	parsed, err := abi.JSON(strings.NewReader(TokenABI))
+	if err != nil {
+		return nil, err
+	}
+	held := unsent{c.Backend}
+	token := bind.NewBoundContract(c.Token, parsed, held, held, held)
+
+	var tx *types.Transaction
+	err = step(Sign, func() error {
+		opts := *c.Opts
+		opts.Context = ctx
+		tx, err = token.Transact(&opts, "approve", opts.From, big.NewInt(1))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = step(Broadcast, func() error {
+		return c.Backend.SendTransaction(ctx, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// unsent is a backend keeping the signed transactions instead of sending
+// them, so that signing and broadcasting are checked separately.
+type unsent struct {
+	bind.ContractBackend
+}
+
+func (unsent) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return nil
+}
+
+// waitIndexed polls Indexed until the transaction is indexed.
+func (c *Canary) waitIndexed(ctx context.Context, hash common.Hash) error {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		ok, err := c.Indexed(ctx, hash)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "transaction %s not indexed", hash.Hex())
+		}
+	}
+}
+
+// Indexed returns an index check looking for the Approval event of the canary
+// transaction in a store where the token is indexed as contract.
+func Indexed(store indexer.Store, contract string) func(ctx context.Context, tx common.Hash) (bool, error) {
+	return func(ctx context.Context, tx common.Hash) (bool, error) {
+		events, err := store.Events(indexer.Query{Contract: contract, Name: "Approval"})
+		if err != nil {
+			return false, err
+		}
+		for _, e := range events {
+			if e.TxHash == tx {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
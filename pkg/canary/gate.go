@@ -0,0 +1,62 @@
+package canary
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Gate holds back the state changing requests of an API until a canary has
+// passed. Read-only requests are always served.
+type Gate struct {
+	mu     sync.Mutex
+	report *Report
+}
+
+// Record stores the report of a canary, opening the gate when it passed.
+func (g *Gate) Record(r Report) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.report = &r
+}
+
+// Open reports whether a canary has passed.
+func (g *Gate) Open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.report != nil && g.report.Passed
+}
+
+// Handler serves the requests with next, rejecting the methods other than
+// GET, HEAD and OPTIONS with 503 Service Unavailable while the gate is closed.
+func (g *Gate) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if !g.Open() {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]string{"error": "operations are disabled until the canary transaction passes"})
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// StatusHandler serves the report of the last canary as JSON, 404 Not Found
+// until a canary has completed.
+func (g *Gate) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		g.mu.Lock()
+		r := g.report
+		g.mu.Unlock()
+		if r == nil {
+			http.Error(w, "no canary has completed", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r)
+	})
+}
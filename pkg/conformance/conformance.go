@@ -0,0 +1,321 @@
+// Package conformance publishes the test vectors of the decoding done by this
+// module, so that alternative clients and data pipelines can check that they
+// decode the contract logs and the meta-transactions as it does.
+//
+// A Vector is an input, a log emitted by one of the contracts or a signed
+// meta-transaction, and its expected Outcome: the JSON output of this module
+// or the class of the error it fails with. The vectors are plain JSON, the
+// published suite is returned by Default, and Run checks an Implementation
+// against them. Implementations in other languages are run as a Process
+// reading the vectors and writing their outcomes as JSON lines:
+//
+//	p, err := conformance.StartProcess(ctx, "./decoder")
+//	...
+//	results, err := conformance.Run(conformance.Default(), p.Run)
+package conformance
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/relayer"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// Version is the version of the vector format.
+const Version = 1
+
+// Kinds of vectors.
+const (
+	// Event vectors decode a log of Contract into an indexer.Event, whose
+	// arguments are formatted with indexer.FormatArg.
+	Event = "event"
+	// TokenChange vectors decode a log of the token whitelist into an
+	// indexer.TokenChange, with no output for the other events.
+	TokenChange = "token_change"
+	// Relay vectors hash a meta-transaction and recover its signer.
+	Relay = "relay"
+)
+
+// Classes of the errors of the outcomes.
+const (
+	ErrorAnonymousLog     = "anonymous_log"
+	ErrorUnknownEvent     = "unknown_event"
+	ErrorMalformedLog     = "malformed_log"
+	ErrorInvalidSignature = "invalid_signature"
+	// ErrorOther is any other error, vectors never expect it.
+	ErrorOther = "other"
+)
+
+// Classify returns the class of an error returned by the decoding, the empty
+// string for nil.
+func Classify(err error) string {
+	switch errors.Cause(err) {
+	case nil:
+		return ""
+	case indexer.ErrAnonymousLog:
+		return ErrorAnonymousLog
+	case indexer.ErrUnknownEvent:
+		return ErrorUnknownEvent
+	case indexer.ErrMalformedLog:
+		return ErrorMalformedLog
+	case signing.ErrInvalidSignature:
+		return ErrorInvalidSignature
+	}
+	return ErrorOther
+}
+
+// Suite is a set of vectors.
+type Suite struct {
+	Version int      `json:"version"`
+	Vectors []Vector `json:"vectors"`
+}
+
+// Vector is an input and its expected outcome.
+type Vector struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Contract names the contract emitting Log, after the names of
+	// bindings.ContractABIs.
+	Contract string     `json:"contract,omitempty"`
+	Log      *types.Log `json:"log,omitempty"`
+	Relay    *MetaTx    `json:"relay,omitempty"`
+	Expect   Outcome    `json:"expect"`
+}
+
+// MetaTx is the input of Relay vectors.
+type MetaTx struct {
+	Wallet    common.Address `json:"wallet"`
+	Nonce     *hexutil.Big   `json:"nonce"`
+	Data      hexutil.Bytes  `json:"data"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// RelayOutput is the output of Relay vectors.
+type RelayOutput struct {
+	Hash   common.Hash    `json:"hash"`
+	Signer common.Address `json:"signer"`
+}
+
+// Outcome is the result of a vector, either an output or the class of an
+// error. The output is compared as JSON, ignoring the formatting.
+type Outcome struct {
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Equal reports whether two outcomes are the same.
+func (o Outcome) Equal(p Outcome) bool {
+	if o.Error != p.Error {
+		return false
+	}
+	if len(o.Output) == 0 || len(p.Output) == 0 {
+		return len(o.Output) == len(p.Output)
+	}
+	a, err := decodeOutput(o.Output)
+	if err != nil {
+		return false
+	}
+	b, err := decodeOutput(p.Output)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func decodeOutput(raw json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	// Numbers are compared as written, large integers would be rounded by
+	// float64.
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	return v, err
+}
+
+func (o Outcome) String() string {
+	if o.Error != "" {
+		return "error " + o.Error
+	}
+	if len(o.Output) == 0 {
+		return "no output"
+	}
+	var b bytes.Buffer
+	if json.Compact(&b, o.Output) != nil {
+		return string(o.Output)
+	}
+	return b.String()
+}
+
+//go:embed vectors.json
+var vectors []byte
+
+// Default returns the published suite.
+func Default() *Suite {
+	var s Suite
+	err := json.Unmarshal(vectors, &s)
+	if err != nil {
+		panic(fmt.Sprintf("decoding the published vectors: %v", err))
+	}
+	return &s
+}
+
+// Load reads a suite from a JSON file.
+func Load(path string) (*Suite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading vectors")
+	}
+	var s Suite
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", path)
+	}
+	if s.Version != Version {
+		return nil, errors.Errorf("%s is version %d of the vectors, expected %d", path, s.Version, Version)
+	}
+	return &s, nil
+}
+
+// Save writes the suite to a JSON file.
+func (s *Suite) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding vectors")
+	}
+	return errors.Wrap(ioutil.WriteFile(path, append(data, '\n'), 0644), "writing vectors")
+}
+
+// Implementation returns the outcome of a vector. Errors are reserved to the
+// failures of the implementation itself, e.g. a crashed process, the errors of
+// the decoding are outcomes.
+type Implementation func(v Vector) (Outcome, error)
+
+// Reference is the implementation of this module.
+func Reference(v Vector) (Outcome, error) {
+	var output interface{}
+	var err error
+	switch v.Kind {
+	case Event, TokenChange:
+		if v.Log == nil {
+			return Outcome{}, errors.Errorf("vector %s has no log", v.Name)
+		}
+		parsed, ok := abis[v.Contract]
+		if !ok {
+			return Outcome{}, errors.Errorf("vector %s of unknown contract %q", v.Name, v.Contract)
+		}
+		var e indexer.Event
+		e, err = indexer.NewEvent(indexer.Contract{Name: v.Contract, Address: v.Log.Address, ABI: parsed}, *v.Log)
+		if err != nil {
+			break
+		}
+		if v.Kind == Event {
+			output = formatted(e)
+			break
+		}
+		var c indexer.TokenChange
+		c, ok, err = indexer.DecodeTokenChange(e)
+		if err == nil && ok {
+			c.Event = formatted(c.Event)
+			output = c
+		}
+	case Relay:
+		if v.Relay == nil || v.Relay.Nonce == nil {
+			return Outcome{}, errors.Errorf("vector %s has no meta-transaction", v.Name)
+		}
+		m := relayer.MetaTx{Wallet: v.Relay.Wallet, Nonce: (*big.Int)(v.Relay.Nonce), Data: v.Relay.Data, Signature: v.Relay.Signature}
+		var signer common.Address
+		signer, err = m.Signer()
+		if err == nil {
+			output = RelayOutput{Hash: relayer.Hash(m.Nonce, m.Data), Signer: signer}
+		}
+	default:
+		return Outcome{}, errors.Errorf("vector %s of unknown kind %q", v.Name, v.Kind)
+	}
+	if err != nil {
+		return Outcome{Error: Classify(err)}, nil
+	}
+	if output == nil {
+		return Outcome{}, nil
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return Outcome{}, errors.Wrapf(err, "encoding output of %s", v.Name)
+	}
+	return Outcome{Output: data}, nil
+}
+
+// abis are the parsed ABIs of the contracts.
+var abis = func() map[string]abi.ABI {
+	parsed := make(map[string]abi.ABI, len(bindings.ContractABIs))
+	for name, a := range bindings.ContractABIs {
+		p, err := abi.JSON(strings.NewReader(a))
+		if err != nil {
+			panic(fmt.Sprintf("parsing %s ABI: %v", name, err))
+		}
+		parsed[name] = p
+	}
+	return parsed
+}()
+
+func formatted(e indexer.Event) indexer.Event {
+	args := make(map[string]interface{}, len(e.Args))
+	for k, v := range e.Args {
+		args[k] = indexer.FormatArg(v)
+	}
+	e.Args = args
+	return e
+}
+
+// Result is the outcome of a vector compared with the expected one.
+type Result struct {
+	Vector   string  `json:"vector"`
+	Passed   bool    `json:"passed"`
+	Expected Outcome `json:"expected"`
+	Got      Outcome `json:"got"`
+}
+
+func (r Result) String() string {
+	if r.Passed {
+		return fmt.Sprintf("PASS %s", r.Vector)
+	}
+	return fmt.Sprintf("FAIL %s: expected %s, got %s", r.Vector, r.Expected, r.Got)
+}
+
+// Run checks an implementation against the vectors of a suite. It stops at the
+// first failure of the implementation.
+func Run(s *Suite, impl Implementation) ([]Result, error) {
+	results := make([]Result, 0, len(s.Vectors))
+	for _, v := range s.Vectors {
+		got, err := impl(v)
+		if err != nil {
+			return results, errors.Wrapf(err, "running vector %s", v.Name)
+		}
+		results = append(results, Result{Vector: v.Name, Passed: v.Expect.Equal(got), Expected: v.Expect, Got: got})
+	}
+	return results, nil
+}
+
+// Failed returns the failed results.
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,77 @@
+package conformance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// Process is an implementation run as a separate program. The program reads
+// one vector per line of its standard input, without its expected outcome,
+// and writes the outcome of each on a line of its standard output, e.g.
+//
+//	{"output":{"hash":"0x…","signer":"0x…"}}
+//	{"error":"invalid_signature"}
+//
+// Its standard error is passed through.
+type Process struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	out    *bufio.Scanner
+	enc    *json.Encoder
+	closed bool
+}
+
+// StartProcess starts the program implementing the vectors.
+func StartProcess(ctx context.Context, name string, args ...string) (*Process, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "opening standard input")
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "opening standard output")
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrapf(err, "starting %s", name)
+	}
+	s := bufio.NewScanner(out)
+	s.Buffer(nil, 16<<20)
+	return &Process{cmd: cmd, in: in, out: s, enc: json.NewEncoder(in)}, nil
+}
+
+// Run sends a vector to the program and reads its outcome.
+func (p *Process) Run(v Vector) (Outcome, error) {
+	v.Expect = Outcome{}
+	err := p.enc.Encode(v)
+	if err != nil {
+		return Outcome{}, errors.Wrap(err, "writing vector")
+	}
+	if !p.out.Scan() {
+		if p.out.Err() != nil {
+			return Outcome{}, errors.Wrap(p.out.Err(), "reading outcome")
+		}
+		return Outcome{}, errors.New("the program exited before writing the outcome")
+	}
+	var o Outcome
+	err = json.Unmarshal(p.out.Bytes(), &o)
+	return o, errors.Wrapf(err, "decoding outcome %q", p.out.Text())
+}
+
+// Close closes the standard input of the program and waits for it to exit.
+func (p *Process) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	p.in.Close()
+	return errors.Wrap(p.cmd.Wait(), "waiting for the program")
+}
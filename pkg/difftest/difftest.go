@@ -0,0 +1,319 @@
+// Package difftest catches the silent behaviour changes introduced when the
+// bindings are regenerated, e.g. by an abigen upgrade changing how calls are
+// encoded, outputs decoded or gas estimated.
+//
+// A Scenario exercises the bindings on a deterministic simulated chain, and
+// Run records everything it sends to and reads from the chain in a Trace:
+// the calls and their outputs, the transactions and their receipts, and the
+// values the scenario records. The same scenario run with the old and with
+// the new bindings must produce the same trace, Compare reports where two
+// traces diverge. The trace of the old bindings is usually saved before the
+// bindings are regenerated:
+//
+//	go test ./test/difftest -update   # with the old bindings
+//	./build.sh
+//	go test ./test/difftest           # compares the new bindings
+//
+// GenerateFixture runs a scenario to record the transactions it sent and the
+// events they emitted as a Fixture instead, see package fixtures.
+package difftest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+// Kinds of trace entries.
+const (
+	Call        = "call"
+	Transaction = "transaction"
+	Receipt     = "receipt"
+	Value       = "value"
+)
+
+// Log is an event emitted by a transaction.
+type Log struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// Entry is an interaction of a scenario with the chain, or a value it recorded.
+type Entry struct {
+	Kind string `json:"kind"`
+	// Name is the name of a recorded value.
+	Name  string          `json:"name,omitempty"`
+	To    *common.Address `json:"to,omitempty"`
+	Value *hexutil.Big    `json:"value,omitempty"`
+	Gas   uint64          `json:"gas,omitempty"`
+	Data  hexutil.Bytes   `json:"data,omitempty"`
+	// Output is the return data of a call.
+	Output hexutil.Bytes `json:"output,omitempty"`
+	// Result is the JSON encoding of a recorded value.
+	Result  json.RawMessage `json:"result,omitempty"`
+	Status  uint64          `json:"status,omitempty"`
+	GasUsed uint64          `json:"gas_used,omitempty"`
+	Logs    []Log           `json:"logs,omitempty"`
+	Err     string          `json:"error,omitempty"`
+}
+
+// Trace is the sequence of entries recorded while running a scenario.
+type Trace []Entry
+
+// LoadTrace reads a trace saved with Save.
+func LoadTrace(path string) (Trace, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading trace")
+	}
+	var t Trace
+	err = json.Unmarshal(b, &t)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding trace %s", path)
+	}
+	return t, nil
+}
+
+// Save writes the trace to path as indented JSON, to keep it reviewable.
+func (t Trace) Save(path string) error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(path, append(b, '\n'), 0644), "writing trace")
+}
+
+// Difference is an entry at which two traces diverge. Old or New is nil when
+// one trace is shorter than the other.
+type Difference struct {
+	Index int
+	Old   *Entry
+	New   *Entry
+}
+
+func (d Difference) String() string {
+	format := func(e *Entry) string {
+		if e == nil {
+			return "<missing>"
+		}
+		b, _ := json.Marshal(e)
+		return string(b)
+	}
+	return fmt.Sprintf("entry %d:\n  old: %s\n  new: %s", d.Index, format(d.Old), format(d.New))
+}
+
+// Compare returns the entries that differ between the old and the new trace.
+func Compare(old, new Trace) []Difference {
+	var diffs []Difference
+	for i := 0; i < len(old) || i < len(new); i++ {
+		var d Difference
+		d.Index = i
+		if i < len(old) {
+			d.Old = &old[i]
+		}
+		if i < len(new) {
+			d.New = &new[i]
+		}
+		if d.Old != nil && d.New != nil && equal(*d.Old, *d.New) {
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+func equal(a, b Entry) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(x) == string(y)
+}
+
+// Scenario exercises the bindings. The bindings must be created with the
+// backend of the Env for their interactions to be recorded.
+type Scenario func(ctx context.Context, env *Env) error
+
+// Env is the chain a scenario runs on.
+type Env struct {
+	// Chain is the simulated chain, its accounts are the same in every run.
+	Chain *testutil.Chain
+	// Backend records the calls and transactions sent through it.
+	Backend bind.ContractBackend
+
+	rec       *recorder
+	contracts map[common.Address]contract
+}
+
+// Commit mines the pending transactions and records their receipts.
+func (e *Env) Commit(ctx context.Context) error {
+	e.Chain.Commit()
+	return e.rec.receipts(ctx, e.Chain)
+}
+
+// Record adds a value to the trace, compared through its JSON encoding so
+// that values decoded by different versions of the bindings can be compared.
+func (e *Env) Record(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s", name)
+	}
+	e.rec.add(Entry{Kind: Value, Name: name, Result: b})
+	return nil
+}
+
+// Keys returns n keys derived from a fixed seed, the same on every call.
+func Keys(n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		seed := crypto.Keccak256([]byte(fmt.Sprintf("difftest %d", i)))
+		key, err := crypto.ToECDSA(seed)
+		if err != nil {
+			panic(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// Run runs the scenario on a new chain created from cfg and returns its
+// trace. The keys of the accounts are set with Keys when cfg.Keys is nil, so
+// that every run starts from the same state. The pending transactions are
+// committed when the scenario returns.
+func Run(ctx context.Context, cfg testutil.Config, s Scenario) (Trace, error) {
+	if cfg.Keys == nil {
+		cfg.Keys = Keys(cfg.NumKeys())
+	}
+	chain, err := testutil.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Close()
+
+	env := newEnv(chain)
+	err = s(ctx, env)
+	if err != nil {
+		return env.rec.trace(), errors.Wrap(err, "running scenario")
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return env.rec.trace(), err
+	}
+	return env.rec.trace(), nil
+}
+
+func newEnv(chain *testutil.Chain) *Env {
+	rec := &recorder{ContractBackend: chain}
+	return &Env{Chain: chain, Backend: rec, rec: rec}
+}
+
+// recorder is a backend recording the calls and transactions.
+type recorder struct {
+	bind.ContractBackend
+
+	mu      sync.Mutex
+	entries Trace
+	pending []*types.Transaction
+	mined   []mined
+}
+
+// mined is a transaction committed and its receipt.
+type mined struct {
+	tx      *types.Transaction
+	receipt *types.Receipt
+}
+
+func (r *recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+func (r *recorder) trace() Trace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(Trace(nil), r.entries...)
+}
+
+// CallContract implements bind.ContractCaller.
+func (r *recorder) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	out, err := r.ContractBackend.CallContract(ctx, call, block)
+	e := Entry{Kind: Call, To: call.To, Data: call.Data, Output: out}
+	if err != nil {
+		e.Err = err.Error()
+	}
+	r.add(e)
+	return out, err
+}
+
+// SendTransaction implements bind.ContractTransactor.
+func (r *recorder) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := r.ContractBackend.SendTransaction(ctx, tx)
+	e := Entry{Kind: Transaction, To: tx.To(), Value: (*hexutil.Big)(tx.Value()), Gas: tx.Gas(), Data: tx.Data()}
+	if err != nil {
+		e.Err = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+	if err == nil {
+		r.pending = append(r.pending, tx)
+	}
+	return err
+}
+
+// receipts records the receipts of the transactions sent since the last call.
+func (r *recorder) receipts(ctx context.Context, chain *testutil.Chain) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tx := range r.pending {
+		receipt, err := chain.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return errors.Wrapf(err, "getting receipt of %s", tx.Hash().Hex())
+		}
+		e := Entry{Kind: Receipt, Status: receipt.Status, GasUsed: receipt.GasUsed}
+		if receipt.ContractAddress != (common.Address{}) {
+			address := receipt.ContractAddress
+			e.To = &address
+		}
+		for _, l := range receipt.Logs {
+			e.Logs = append(e.Logs, Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+		}
+		r.entries = append(r.entries, e)
+		r.mined = append(r.mined, mined{tx: tx, receipt: receipt})
+	}
+	r.pending = nil
+	return nil
+}
+
+// Diff runs a scenario written against the old bindings and its counterpart
+// written against the new bindings, and compares their traces.
+func Diff(ctx context.Context, cfg testutil.Config, old, new Scenario) ([]Difference, error) {
+	before, err := Run(ctx, cfg, old)
+	if err != nil {
+		return nil, errors.Wrap(err, "old bindings")
+	}
+	after, err := Run(ctx, cfg, new)
+	if err != nil {
+		return nil, errors.Wrap(err, "new bindings")
+	}
+	return Compare(before, after), nil
+}
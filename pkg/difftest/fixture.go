@@ -0,0 +1,223 @@
+package difftest
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+// FixtureVersion is the version of the format of the fixtures, incremented
+// when a field is renamed or changes meaning.
+const FixtureVersion = 1
+
+// tknContract is the name of the mock TKN of the chain in the fixtures.
+const tknContract = "tkn"
+
+// Fixture is what the transactions of a scenario did on the chain: the
+// transactions and the events they emitted, decoded as the indexer decodes
+// them, for the tests of the consumers of the events which do not run a chain.
+type Fixture struct {
+	Version  int    `json:"version"`
+	Scenario string `json:"scenario"`
+	// Accounts are the addresses of the deployer followed by the accounts
+	// of the chain.
+	Accounts []common.Address `json:"accounts"`
+	// Contracts are the addresses of the contracts named by the scenario,
+	// and of the mock TKN as "tkn".
+	Contracts    map[string]common.Address `json:"contracts"`
+	Transactions []FixtureTransaction      `json:"transactions"`
+	// Events are the events of the named contracts, with their arguments
+	// formatted by indexer.FormatArg and their indexed addresses as
+	// addresses rather than topics.
+	Events []indexer.Event `json:"events"`
+}
+
+// FixtureTransaction is a transaction of a fixture.
+type FixtureTransaction struct {
+	Hash        common.Hash     `json:"hash"`
+	BlockNumber uint64          `json:"block_number"`
+	BlockHash   common.Hash     `json:"block_hash"`
+	Time        uint64          `json:"time"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to"`
+	// Contract is the name of the contract called, or deployed when To is
+	// nil, and Method the name of the method called, when they are known.
+	Contract string        `json:"contract,omitempty"`
+	Method   string        `json:"method,omitempty"`
+	Value    *hexutil.Big  `json:"value"`
+	Data     hexutil.Bytes `json:"data"`
+	Status   uint64        `json:"status"`
+	GasUsed  uint64        `json:"gas_used"`
+	// ContractAddress is the address of the contract deployed.
+	ContractAddress *common.Address `json:"contract_address,omitempty"`
+}
+
+// LoadFixture reads a fixture saved with Save.
+func LoadFixture(path string) (*Fixture, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading fixture")
+	}
+	f := &Fixture{}
+	err = json.Unmarshal(b, f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding fixture %s", path)
+	}
+	return f, nil
+}
+
+// Save writes the fixture to path as indented JSON, to keep it reviewable.
+func (f *Fixture) Save(path string) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(path, append(b, '\n'), 0644), "writing fixture")
+}
+
+type contract struct {
+	name string
+	abi  abi.ABI
+}
+
+// Name names the contract at address in the fixtures, which decode its
+// transactions and events with its ABI.
+func (e *Env) Name(name string, address common.Address, parsed abi.ABI) {
+	if e.contracts == nil {
+		e.contracts = make(map[common.Address]contract)
+	}
+	e.contracts[address] = contract{name: name, abi: parsed}
+}
+
+// GenerateFixture runs the scenario like Run and returns the fixture of its
+// transactions. The chain being deterministic, the same scenario generates
+// the same fixture.
+func GenerateFixture(ctx context.Context, cfg testutil.Config, name string, s Scenario) (*Fixture, error) {
+	if cfg.Keys == nil {
+		cfg.Keys = Keys(cfg.NumKeys())
+	}
+	chain, err := testutil.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Close()
+
+	env := newEnv(chain)
+	env.Name(tknContract, chain.TKNAddress, mocks.BurnerTokenParsedABI)
+	err = s(ctx, env)
+	if err != nil {
+		return nil, errors.Wrap(err, "running scenario")
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return env.fixture(name)
+}
+
+func (e *Env) fixture(scenario string) (*Fixture, error) {
+	f := &Fixture{
+		Version:      FixtureVersion,
+		Scenario:     scenario,
+		Accounts:     []common.Address{e.Chain.Deployer.Address},
+		Contracts:    make(map[string]common.Address, len(e.contracts)),
+		Transactions: []FixtureTransaction{},
+		Events:       []indexer.Event{},
+	}
+	for _, a := range e.Chain.Accounts {
+		f.Accounts = append(f.Accounts, a.Address)
+	}
+	for address, c := range e.contracts {
+		f.Contracts[c.name] = address
+	}
+
+	e.rec.mu.Lock()
+	defer e.rec.mu.Unlock()
+	for _, m := range e.rec.mined {
+		t, err := e.transaction(m)
+		if err != nil {
+			return nil, err
+		}
+		f.Transactions = append(f.Transactions, t)
+		for _, l := range m.receipt.Logs {
+			c, ok := e.contracts[l.Address]
+			if !ok {
+				continue
+			}
+			ev, err := indexer.NewEvent(indexer.Contract{Name: c.name, Address: l.Address, ABI: c.abi}, *l)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding log %d of transaction %s", l.Index, l.TxHash.Hex())
+			}
+			indexed := make(map[string]bool)
+			for _, in := range c.abi.Events[ev.Name].Inputs {
+				indexed[in.Name] = in.Indexed && in.Type.T == abi.AddressTy
+			}
+			for k, v := range ev.Args {
+				// The indexer keeps the indexed arguments as their
+				// topics, the addresses are restored for the readers
+				// of the fixtures.
+				if h, ok := v.(common.Hash); ok && indexed[k] {
+					v = common.BytesToAddress(h.Bytes())
+				}
+				ev.Args[k] = indexer.FormatArg(v)
+			}
+			f.Events = append(f.Events, ev)
+		}
+	}
+	return f, nil
+}
+
+func (e *Env) transaction(m mined) (FixtureTransaction, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if m.tx.Protected() {
+		signer = types.NewEIP155Signer(m.tx.ChainId())
+	}
+	from, err := types.Sender(signer, m.tx)
+	if err != nil {
+		return FixtureTransaction{}, errors.Wrapf(err, "getting sender of %s", m.tx.Hash().Hex())
+	}
+	header := e.Chain.Simulated.Blockchain().GetHeaderByHash(m.receipt.BlockHash)
+	if header == nil {
+		return FixtureTransaction{}, errors.Errorf("block %s of transaction %s not found", m.receipt.BlockHash.Hex(), m.tx.Hash().Hex())
+	}
+	t := FixtureTransaction{
+		Hash:        m.tx.Hash(),
+		BlockNumber: m.receipt.BlockNumber.Uint64(),
+		BlockHash:   m.receipt.BlockHash,
+		Time:        header.Time,
+		From:        from,
+		To:          m.tx.To(),
+		Value:       (*hexutil.Big)(new(big.Int).Set(m.tx.Value())),
+		Data:        m.tx.Data(),
+		Status:      m.receipt.Status,
+		GasUsed:     m.receipt.GasUsed,
+	}
+	address := m.receipt.ContractAddress
+	if t.To != nil {
+		address = *t.To
+	} else {
+		t.ContractAddress = &address
+	}
+	c, ok := e.contracts[address]
+	if !ok {
+		return t, nil
+	}
+	t.Contract = c.name
+	if t.To != nil && len(t.Data) >= 4 {
+		method, err := c.abi.MethodById(t.Data[:4])
+		if err == nil {
+			t.Method = method.Name
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,94 @@
+package dust
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Books record the dust found, for the accounting.
+type Books interface {
+	Record(f Finding) error
+	// Findings returns the findings recorded so far, in order.
+	Findings() ([]Finding, error)
+}
+
+// MemoryBooks are Books lost on restart.
+type MemoryBooks struct {
+	mu       sync.Mutex
+	findings []Finding
+}
+
+// Record implements Books.
+func (b *MemoryBooks) Record(f Finding) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.findings = append(b.findings, f)
+	return nil
+}
+
+// Findings implements Books.
+func (b *MemoryBooks) Findings() ([]Finding, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Finding(nil), b.findings...), nil
+}
+
+// FileBooks append the findings to a file as JSON lines.
+type FileBooks struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileBooks returns the books stored in path, which is created on the first
+// finding.
+func NewFileBooks(path string) *FileBooks {
+	return &FileBooks{path: path}
+}
+
+// Record implements Books.
+func (b *FileBooks) Record(f Finding) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	file, err := os.OpenFile(b.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening books")
+	}
+	err = json.NewEncoder(file).Encode(f)
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "writing finding")
+	}
+	return errors.Wrap(file.Close(), "writing finding")
+}
+
+// Findings implements Books.
+func (b *FileBooks) Findings() ([]Finding, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	file, err := os.Open(b.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening books")
+	}
+	defer file.Close()
+
+	var findings []Finding
+	s := bufio.NewScanner(file)
+	for line := 1; s.Scan(); line++ {
+		var f Finding
+		err := json.Unmarshal(s.Bytes(), &f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s line %d", b.path, line)
+		}
+		findings = append(findings, f)
+	}
+	return findings, errors.Wrap(s.Err(), "reading books")
+}
@@ -0,0 +1,285 @@
+// Package dust detects the ETH and tokens sent to the contracts which are not
+// meant to hold them, and may be stuck there for good.
+//
+// The ERC20 and ERC721 tokens are found from their Transfer events to the
+// contracts, whatever the token contract, so that unknown tokens are reported
+// too. Plain ETH transfers emit no event, the ETH balances of the contracts
+// are checked instead. The findings are recorded in the books and alerted.
+package dust
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Standards of the assets of the findings.
+const (
+	ETH    = "ETH"
+	ERC20  = "ERC20"
+	ERC721 = "ERC721"
+)
+
+// Finding is an asset received by a contract not expected to hold it.
+type Finding struct {
+	Time     time.Time      `json:"time"`
+	Contract string         `json:"contract"`
+	Address  common.Address `json:"address"`
+	Standard string         `json:"standard"`
+	// Asset is the token contract, the zero address for ETH.
+	Asset common.Address `json:"asset"`
+	// Amount is the amount received, the token ID for ERC721 tokens.
+	Amount *big.Int `json:"amount"`
+	// Balance is the ETH balance of the contract, for ETH findings.
+	Balance     *big.Int       `json:"balance,omitempty"`
+	From        common.Address `json:"from,omitempty"`
+	TxHash      common.Hash    `json:"tx_hash,omitempty"`
+	BlockNumber uint64         `json:"block_number"`
+	// Recoverable is set when the contract can send the asset back with
+	// claim, ERC721 tokens never are.
+	Recoverable bool `json:"recoverable"`
+}
+
+// Contract is a contract watched for dust.
+type Contract struct {
+	Name    string
+	Address common.Address
+	// Claimable is set when the contract implements claim(address _to,
+	// address _asset, uint _amount).
+	Claimable bool
+	// HoldsETH is set when the contract is meant to hold ETH.
+	HoldsETH bool
+	// Tokens are the tokens the contract is meant to hold.
+	Tokens []common.Address
+}
+
+func (c Contract) holds(token common.Address) bool {
+	for _, t := range c.Tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// Backend is the subset of the node API used by the Detector.
+type Backend interface {
+	indexer.Backend
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+}
+
+// Detector periodically looks for the dust received by the contracts since
+// its previous check.
+type Detector struct {
+	Backend   Backend
+	Contracts []Contract
+	// StartBlock is the first block checked when the books are empty.
+	StartBlock uint64
+	Interval   time.Duration
+	Books      Books
+	// Alert is called with the findings of each check, after they are
+	// recorded in the books.
+	Alert func(ctx context.Context, findings []Finding) error
+	// ErrorLog receives the errors of failed checks, they are discarded when nil.
+	ErrorLog *log.Logger
+
+	mu   sync.Mutex
+	next uint64
+	eth  map[common.Address]*big.Int
+	init bool
+}
+
+// Run checks for dust every Interval until the context is cancelled.
+func (d *Detector) Run(ctx context.Context) error {
+	t := time.NewTicker(d.Interval)
+	defer t.Stop()
+	for {
+		_, err := d.Check(ctx)
+		if err != nil && d.ErrorLog != nil {
+			d.ErrorLog.Printf("dust check failed: %v", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check looks for the dust received up to the current head of the chain. The
+// ETH found is the increase of the balances since the previous finding.
+func (d *Detector) Check(ctx context.Context) ([]Finding, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.init {
+		err := d.restore()
+		if err != nil {
+			return nil, err
+		}
+		d.init = true
+	}
+
+	header, err := d.Backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting head of the chain")
+	}
+	head := header.Number.Uint64()
+	if head < d.next {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+
+	findings, err := d.transfers(ctx, head, now)
+	if err != nil {
+		return nil, err
+	}
+	eth, err := d.balances(ctx, head, now)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, eth...)
+
+	for _, f := range findings {
+		err := d.Books.Record(f)
+		if err != nil {
+			return nil, err
+		}
+		if f.Standard == ETH {
+			d.eth[f.Address] = f.Balance
+		}
+	}
+	d.next = head + 1
+	if len(findings) > 0 && d.Alert != nil {
+		err = d.Alert(ctx, findings)
+		if err != nil {
+			return findings, errors.Wrap(err, "alerting dust")
+		}
+	}
+	return findings, nil
+}
+
+// restore resumes from the last block and the ETH balances in the books.
+func (d *Detector) restore() error {
+	d.next = d.StartBlock
+	d.eth = make(map[common.Address]*big.Int)
+	findings, err := d.Books.Findings()
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if f.BlockNumber+1 > d.next {
+			d.next = f.BlockNumber + 1
+		}
+		if f.Standard == ETH {
+			d.eth[f.Address] = f.Balance
+		}
+	}
+	return nil
+}
+
+// transfers returns the unexpected tokens transferred to the contracts.
+func (d *Detector) transfers(ctx context.Context, head uint64, now time.Time) ([]Finding, error) {
+	byAddress := make(map[common.Hash]Contract, len(d.Contracts))
+	var recipients []common.Hash
+	for _, c := range d.Contracts {
+		topic := common.BytesToHash(c.Address.Bytes())
+		byAddress[topic] = c
+		recipients = append(recipients, topic)
+	}
+	logs, err := d.Backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(d.next),
+		ToBlock:   new(big.Int).SetUint64(head),
+		Topics:    [][]common.Hash{{indexer.TransferTopic}, nil, recipients},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering token transfers")
+	}
+
+	var findings []Finding
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		c := byAddress[l.Topics[2]]
+		if c.holds(l.Address) {
+			continue
+		}
+		f, ok := transfer(l)
+		if !ok {
+			continue
+		}
+		f.Time = now
+		f.Contract = c.Name
+		f.Address = c.Address
+		f.Recoverable = c.Claimable && f.Standard == ERC20
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// transfer decodes a Transfer event, it returns false for the events not
+// following either standard.
+func transfer(l types.Log) (Finding, bool) {
+	f := Finding{
+		Asset:       l.Address,
+		From:        common.BytesToAddress(l.Topics[1].Bytes()),
+		TxHash:      l.TxHash,
+		BlockNumber: l.BlockNumber,
+	}
+	switch {
+	case len(l.Topics) == 3 && len(l.Data) == 32:
+		f.Standard = ERC20
+		f.Amount = new(big.Int).SetBytes(l.Data)
+	case len(l.Topics) == 4 && len(l.Data) == 0:
+		f.Standard = ERC721
+		f.Amount = l.Topics[3].Big()
+	default:
+		return Finding{}, false
+	}
+	return f, true
+}
+
+// balances returns the increases of the ETH balances of the contracts not
+// meant to hold ETH.
+func (d *Detector) balances(ctx context.Context, head uint64, now time.Time) ([]Finding, error) {
+	var findings []Finding
+	for _, c := range d.Contracts {
+		if c.HoldsETH {
+			continue
+		}
+		balance, err := d.Backend.BalanceAt(ctx, c.Address, new(big.Int).SetUint64(head))
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting balance of %s", c.Name)
+		}
+		prev, ok := d.eth[c.Address]
+		if !ok {
+			prev = new(big.Int)
+		}
+		if balance.Cmp(prev) <= 0 {
+			// Claimed or spent, the next increase is compared with the
+			// new balance.
+			d.eth[c.Address] = balance
+			continue
+		}
+		findings = append(findings, Finding{
+			Time:        now,
+			Contract:    c.Name,
+			Address:     c.Address,
+			Standard:    ETH,
+			Amount:      new(big.Int).Sub(balance, prev),
+			Balance:     balance,
+			BlockNumber: head,
+			Recoverable: c.Claimable,
+		})
+	}
+	return findings, nil
+}
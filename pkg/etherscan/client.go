@@ -0,0 +1,189 @@
+// Package etherscan reconciles the events of the indexer against the logs
+// served by Etherscan, or any explorer implementing its API, a source of
+// truth independent of the nodes the events were indexed from. The events
+// missing from the index, those the explorer does not know and those whose
+// content differs are reported as discrepancies:
+//
+//	r := etherscan.NewReconciler(etherscan.NewClient(etherscan.DefaultURL, key), idx.Store(), contracts...)
+//	report, err := r.Check(ctx, deployment, head)
+//	for _, d := range report.Discrepancies {
+//		fmt.Println(d)
+//	}
+package etherscan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultURL is the API endpoint of Etherscan on mainnet.
+const DefaultURL = "https://api.etherscan.io/api"
+
+// DefaultPageSize is the number of logs requested at once, the most the API
+// returns.
+const DefaultPageSize = 1000
+
+// Client calls the logs endpoint of the API.
+type Client struct {
+	url    string
+	apiKey string
+
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// PageSize is the number of logs requested at once, DefaultPageSize
+	// when not positive.
+	PageSize int
+}
+
+// NewClient returns a client of the API at url, authenticated by apiKey.
+func NewClient(url, apiKey string) *Client {
+	return &Client{url: url, apiKey: apiKey}
+}
+
+// response is the envelope of the responses of the API. The result is a
+// message instead of the logs when the request failed.
+type response struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// apiLog is a log as returned by the API, with its numbers hex encoded.
+type apiLog struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             string         `json:"data"`
+	BlockNumber      string         `json:"blockNumber"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	LogIndex         string         `json:"logIndex"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex string         `json:"transactionIndex"`
+}
+
+// Logs returns the logs emitted by address from block from to block to, in
+// chain order. The block hashes are zero when the API leaves them out.
+func (c *Client) Logs(ctx context.Context, address common.Address, from, to uint64) ([]types.Log, error) {
+	size := c.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	var logs []types.Log
+	for page := 1; ; page++ {
+		q := url.Values{
+			"module":    {"logs"},
+			"action":    {"getLogs"},
+			"address":   {address.Hex()},
+			"fromBlock": {strconv.FormatUint(from, 10)},
+			"toBlock":   {strconv.FormatUint(to, 10)},
+			"page":      {strconv.Itoa(page)},
+			"offset":    {strconv.Itoa(size)},
+			"apikey":    {c.apiKey},
+		}
+		var result []apiLog
+		err := c.get(ctx, q, &result)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting the logs of %s", address.Hex())
+		}
+		for _, l := range result {
+			log, err := l.log()
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding a log of transaction %s", l.TransactionHash.Hex())
+			}
+			logs = append(logs, log)
+		}
+		if len(result) < size {
+			return logs, nil
+		}
+	}
+}
+
+// get calls the API with the query q and decodes the result into v.
+func (c *Client) get(ctx context.Context, q url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.url+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		// The error holds the URL, which holds the API key.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return errors.Wrap(err, "calling the explorer API")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("explorer API answered %s", resp.Status)
+	}
+	var r response
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return errors.Wrap(err, "decoding the explorer response")
+	}
+	if r.Status != "1" {
+		// An empty result is not an error, though it is reported as one.
+		if strings.HasPrefix(r.Message, "No records found") {
+			return nil
+		}
+		var msg string
+		if json.Unmarshal(r.Result, &msg) != nil {
+			msg = string(r.Result)
+		}
+		return errors.Errorf("explorer API error: %s: %s", r.Message, msg)
+	}
+	return errors.Wrap(json.Unmarshal(r.Result, v), "decoding the explorer result")
+}
+
+func (l apiLog) log() (types.Log, error) {
+	data, err := hexutil.Decode(l.Data)
+	if err != nil {
+		return types.Log{}, errors.Wrap(err, "data")
+	}
+	number, err := decodeUint(l.BlockNumber)
+	if err != nil {
+		return types.Log{}, errors.Wrap(err, "blockNumber")
+	}
+	index, err := decodeUint(l.LogIndex)
+	if err != nil {
+		return types.Log{}, errors.Wrap(err, "logIndex")
+	}
+	txIndex, err := decodeUint(l.TransactionIndex)
+	if err != nil {
+		return types.Log{}, errors.Wrap(err, "transactionIndex")
+	}
+	return types.Log{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        data,
+		BlockNumber: number,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TransactionHash,
+		TxIndex:     uint(txIndex),
+		Index:       uint(index),
+	}, nil
+}
+
+// decodeUint decodes a hex number of the API, which writes zero as "0x" and
+// may pad the numbers with zeros.
+func decodeUint(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, errors.Errorf("%q is not a hex number", s)
+	}
+	if s == "0x" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
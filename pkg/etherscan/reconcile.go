@@ -0,0 +1,232 @@
+package etherscan
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// The kinds of discrepancies.
+const (
+	// Missing is an event known to the explorer which is not indexed.
+	Missing = "missing"
+	// Unknown is an indexed event the explorer does not know.
+	Unknown = "unknown"
+	// Mismatch is an event indexed with another block hash, contract or
+	// arguments than the explorer has.
+	Mismatch = "mismatch"
+)
+
+// Discrepancy is an event on which the index and the explorer disagree.
+type Discrepancy struct {
+	Kind string `json:"kind"`
+	// Event is the indexed event, or the event of the explorer when it is
+	// missing from the index.
+	Event indexer.Event `json:"event"`
+	// Explorer is the event of the explorer for a mismatch.
+	Explorer *indexer.Event `json:"explorer,omitempty"`
+}
+
+func (d Discrepancy) String() string {
+	e := d.Event
+	s := fmt.Sprintf("%s %s.%s at block %d tx %s log %d", d.Kind, e.Contract, e.Name, e.BlockNumber, e.TxHash.Hex(), e.LogIndex)
+	if d.Explorer != nil {
+		s += fmt.Sprintf(", explorer has %s.%s", d.Explorer.Contract, d.Explorer.Name)
+	}
+	return s
+}
+
+// Report is the outcome of a reconciliation.
+type Report struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	// Indexed and Explorer are the number of events of each source.
+	Indexed       int           `json:"indexed"`
+	Explorer      int           `json:"explorer"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Reconciler compares the events of a store with the logs of the explorer,
+// for the same contracts and blocks.
+type Reconciler struct {
+	client    *Client
+	store     indexer.Store
+	contracts []indexer.Contract
+
+	// StartBlock is the first block reconciled by Run.
+	StartBlock uint64
+	// Confirmations are the indexed blocks left out by Run, the explorer
+	// lagging behind the nodes or being on another branch near the head.
+	Confirmations uint64
+	Interval      time.Duration
+	// Alert is called by Run with the discrepancies of each check.
+	Alert func(ctx context.Context, discrepancies []Discrepancy) error
+	// ErrorLog receives the errors of failed checks, they are discarded when nil.
+	ErrorLog *log.Logger
+
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewReconciler returns a reconciler of the events of the contracts stored
+// in store.
+func NewReconciler(client *Client, store indexer.Store, contracts ...indexer.Contract) *Reconciler {
+	return &Reconciler{client: client, store: store, contracts: contracts}
+}
+
+// Check reconciles the events of the blocks from from to to.
+func (r *Reconciler) Check(ctx context.Context, from, to uint64) (*Report, error) {
+	if to < from {
+		return nil, errors.Errorf("end block %d is before start block %d", to, from)
+	}
+	indexed, err := r.store.Events(indexer.Query{FromBlock: from, ToBlock: to})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading indexed events")
+	}
+	var explorer []indexer.Event
+	for _, c := range r.contracts {
+		logs, err := r.client.Logs(ctx, c.Address, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			e, err := indexer.NewEvent(c, l)
+			if err != nil {
+				// The indexer skips the logs it cannot decode as well.
+				continue
+			}
+			explorer = append(explorer, e)
+		}
+	}
+
+	report := &Report{FromBlock: from, ToBlock: to, Explorer: len(explorer)}
+	stored := make(map[key]indexer.Event, len(indexed))
+	for _, e := range indexed {
+		if !r.reconciled(e.Address) {
+			continue
+		}
+		report.Indexed++
+		stored[keyOf(e)] = e
+	}
+	known := make(map[key]bool, len(explorer))
+	for _, e := range explorer {
+		k := keyOf(e)
+		known[k] = true
+		s, ok := stored[k]
+		if !ok {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{Kind: Missing, Event: e})
+			continue
+		}
+		if !same(s, e) {
+			other := e
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{Kind: Mismatch, Event: s, Explorer: &other})
+		}
+	}
+	for k, e := range stored {
+		if !known[k] {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{Kind: Unknown, Event: e})
+		}
+	}
+	sort.SliceStable(report.Discrepancies, func(i, j int) bool {
+		return report.Discrepancies[i].Event.Position().Before(report.Discrepancies[j].Event.Position())
+	})
+	return report, nil
+}
+
+// reconciled tells whether the events of address are reconciled.
+func (r *Reconciler) reconciled(address common.Address) bool {
+	for _, c := range r.contracts {
+		if c.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Run reconciles the blocks indexed since the previous check every Interval
+// until the context is cancelled. The blocks of a failed check are checked
+// again by the next one.
+func (r *Reconciler) Run(ctx context.Context) error {
+	t := time.NewTicker(r.Interval)
+	defer t.Stop()
+	for {
+		err := r.checkIndexed(ctx)
+		if err != nil && r.ErrorLog != nil {
+			r.ErrorLog.Printf("reconciliation failed: %v", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkIndexed reconciles the confirmed blocks indexed since the previous
+// check and alerts their discrepancies.
+func (r *Reconciler) checkIndexed(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	head, ok := r.store.Head()
+	if !ok || head < r.Confirmations {
+		return nil
+	}
+	to := head - r.Confirmations
+	from := r.next
+	if from < r.StartBlock {
+		from = r.StartBlock
+	}
+	if to < from {
+		return nil
+	}
+	report, err := r.Check(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if len(report.Discrepancies) > 0 && r.Alert != nil {
+		err = r.Alert(ctx, report.Discrepancies)
+		if err != nil {
+			return err
+		}
+	}
+	r.next = to + 1
+	return nil
+}
+
+// key identifies a log in the chain. The block hash is left out, the explorer
+// may not return it.
+type key struct {
+	block uint64
+	tx    common.Hash
+	index uint
+}
+
+func keyOf(e indexer.Event) key {
+	return key{block: e.BlockNumber, tx: e.TxHash, index: e.LogIndex}
+}
+
+// same tells whether the indexed event s has the content of the event e of
+// the explorer. The arguments are compared formatted, as the stores persisting
+// the events hold them formatted and decoded from JSON.
+func same(s, e indexer.Event) bool {
+	if e.BlockHash != (common.Hash{}) && s.BlockHash != e.BlockHash {
+		return false
+	}
+	if s.Address != e.Address || s.Name != e.Name || len(s.Args) != len(e.Args) {
+		return false
+	}
+	for k, v := range e.Args {
+		a, ok := s.Args[k]
+		if !ok || fmt.Sprint(indexer.FormatArg(a)) != fmt.Sprint(indexer.FormatArg(v)) {
+			return false
+		}
+	}
+	return true
+}
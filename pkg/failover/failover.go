@@ -0,0 +1,579 @@
+// Package failover spreads the calls to the node across several RPC
+// endpoints, so that a single flaky provider does not take the services down.
+// Backend sends each call to the next healthy endpoint in turn, and fails over
+// to the others when an endpoint returns an error of the connection or of the
+// provider, or does not answer within Timeout. The failed endpoint is skipped
+// until RetryAfter has passed or a health check finds it back in sync.
+//
+// For example:
+//
+//	b, err := failover.DialURLs(ctx, "https://mainnet.infura.io/v3/key", "https://eth-mainnet.alchemyapi.io/v2/key")
+//	go b.Run(ctx)
+//	licence, err := bindings.NewLicence(address, b)
+//
+// The errors of the chain, such as a reverted call, a nonce too low or a
+// receipt not found, are returned as they are: another endpoint would return
+// them too.
+package failover
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+const (
+	// DefaultTimeout is the time an endpoint has to answer a call when
+	// Timeout is not set.
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetryAfter is how long a failed endpoint is skipped when
+	// RetryAfter is not set.
+	DefaultRetryAfter = 30 * time.Second
+	// DefaultInterval is the delay between two health checks when Interval
+	// is not set.
+	DefaultInterval = 15 * time.Second
+	// DefaultMaxLag is the number of blocks an endpoint may lag behind the
+	// most advanced one when MaxLag is not set.
+	DefaultMaxLag = 5
+)
+
+// Node is a connection to an RPC endpoint, *ethclient.Client implements it.
+type Node interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Endpoint is a named connection to an RPC endpoint.
+type Endpoint struct {
+	// Name identifies the endpoint in the logs and the status, without the
+	// secrets of its URL.
+	Name string
+	Node Node
+}
+
+// Status is the health of an endpoint.
+type Status struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Head is the last block number the endpoint reported.
+	Head uint64 `json:"head"`
+	// Error is the last error of the endpoint, empty when it is healthy.
+	Error string `json:"error,omitempty"`
+}
+
+// endpoint is an Endpoint with its health.
+type endpoint struct {
+	Endpoint
+
+	mu        sync.Mutex
+	downUntil time.Time
+	head      uint64
+	err       error
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+// advance records a head the endpoint reported, never moving back, and
+// returns the last head known.
+func (e *endpoint) advance(head uint64) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if head > e.head {
+		e.head = head
+	}
+	return e.head
+}
+
+// behindError is the error of an endpoint whose head is behind the block of a
+// call.
+type behindError struct {
+	head, block uint64
+}
+
+func (e *behindError) Error() string {
+	return fmt.Sprintf("head %d is behind block %d", e.head, e.block)
+}
+
+// Backend is a bind.ContractBackend balancing the calls across endpoints
+// round robin, failing over to the next endpoint on errors and timeouts.
+type Backend struct {
+	endpoints []*endpoint
+	next      uint64
+
+	// Timeout is the time an endpoint has to answer a call before the call
+	// fails over to the next one. The subscriptions are not limited.
+	Timeout time.Duration
+	// RetryAfter is how long a failed endpoint is skipped.
+	RetryAfter time.Duration
+	// Interval is the delay between two health checks made by Run.
+	Interval time.Duration
+	// MaxLag is the number of blocks an endpoint may lag behind the most
+	// advanced one before a health check takes it out of rotation.
+	MaxLag uint64
+	// Logger receives the failed and recovered endpoints.
+	Logger logging.Logger
+}
+
+// New returns a Backend balancing the calls across the endpoints.
+func New(endpoints ...Endpoint) (*Backend, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no RPC endpoint")
+	}
+	b := &Backend{
+		Timeout:    DefaultTimeout,
+		RetryAfter: DefaultRetryAfter,
+		Interval:   DefaultInterval,
+		MaxLag:     DefaultMaxLag,
+	}
+	for _, e := range endpoints {
+		b.endpoints = append(b.endpoints, &endpoint{Endpoint: e})
+	}
+	return b, nil
+}
+
+// DialURLs connects to the nodes at the URLs and returns a Backend balancing
+// the calls across them. The endpoints are named after the host of their URL.
+func DialURLs(ctx context.Context, urls ...string) (*Backend, error) {
+	var endpoints []Endpoint
+	for _, url := range urls {
+		c, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			for _, e := range endpoints {
+				e.Node.(*ethclient.Client).Close()
+			}
+			return nil, errors.Wrapf(err, "connecting to %s", Name(url))
+		}
+		endpoints = append(endpoints, Endpoint{Name: Name(url), Node: c})
+	}
+	return New(endpoints...)
+}
+
+// Name returns the scheme and host of an RPC URL, leaving out the API keys
+// that the providers take in the path or the credentials, or the URL itself
+// for an IPC endpoint.
+func Name(url string) string {
+	i := strings.Index(url, "://")
+	if i < 0 {
+		return url
+	}
+	host := url[i+3:]
+	if j := strings.IndexAny(host, "/?#"); j >= 0 {
+		host = host[:j]
+	}
+	if j := strings.LastIndex(host, "@"); j >= 0 {
+		host = host[j+1:]
+	}
+	return url[:i+3] + host
+}
+
+// Close closes the endpoints which can be closed.
+func (b *Backend) Close() {
+	for _, e := range b.endpoints {
+		if c, ok := e.Node.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
+
+// Status returns the health of the endpoints, in their order.
+func (b *Backend) Status() []Status {
+	now := time.Now()
+	var s []Status
+	for _, e := range b.endpoints {
+		e.mu.Lock()
+		st := Status{Name: e.Name, Healthy: !now.Before(e.downUntil), Head: e.head}
+		if !st.Healthy && e.err != nil {
+			st.Error = e.err.Error()
+		}
+		e.mu.Unlock()
+		s = append(s, st)
+	}
+	return s
+}
+
+// Run checks the health of the endpoints every Interval until the context is
+// done.
+func (b *Backend) Run(ctx context.Context) {
+	interval := b.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		b.Check(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check gets the head of every endpoint, taking out of rotation the endpoints
+// failing to answer or lagging more than MaxLag blocks behind the most
+// advanced one, and putting the others back.
+func (b *Backend) Check(ctx context.Context) {
+	heads := make([]uint64, len(b.endpoints))
+	errs := make([]error, len(b.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range b.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			callCtx, cancel := b.callContext(ctx)
+			defer cancel()
+			h, err := e.Node.HeaderByNumber(callCtx, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			heads[i] = h.Number.Uint64()
+		}(i, e)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	var best uint64
+	for i, h := range heads {
+		if errs[i] == nil && h > best {
+			best = h
+		}
+	}
+	for i, e := range b.endpoints {
+		err := errs[i]
+		if err == nil && heads[i]+b.MaxLag < best {
+			err = errors.Errorf("head %d lags %d blocks behind", heads[i], best-heads[i])
+		}
+		if err != nil {
+			b.fail(e, err)
+			continue
+		}
+		e.mu.Lock()
+		recovered := time.Now().Before(e.downUntil)
+		e.downUntil = time.Time{}
+		e.head = heads[i]
+		e.err = nil
+		e.mu.Unlock()
+		if recovered {
+			b.logger().Info("RPC endpoint recovered", "endpoint", e.Name, "head", heads[i])
+		}
+	}
+}
+
+// fail takes an endpoint out of rotation for RetryAfter.
+func (b *Backend) fail(e *endpoint, err error) {
+	retryAfter := b.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	e.mu.Lock()
+	wasHealthy := !time.Now().Before(e.downUntil)
+	e.downUntil = time.Now().Add(retryAfter)
+	e.err = err
+	e.mu.Unlock()
+	if wasHealthy {
+		b.logger().Warn("RPC endpoint failed, taking it out of rotation", "endpoint", e.Name, "retry_after", retryAfter, "err", err)
+	}
+}
+
+// order returns the endpoints in the order a call tries them: the healthy
+// ones round robin, then the failed ones, as a last resort.
+func (b *Backend) order() []*endpoint {
+	n := len(b.endpoints)
+	start := int((atomic.AddUint64(&b.next, 1) - 1) % uint64(n))
+	now := time.Now()
+	healthy := make([]*endpoint, 0, n)
+	var failed []*endpoint
+	for i := 0; i < n; i++ {
+		e := b.endpoints[(start+i)%n]
+		if e.healthy(now) {
+			healthy = append(healthy, e)
+		} else {
+			failed = append(failed, e)
+		}
+	}
+	return append(healthy, failed...)
+}
+
+func (b *Backend) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// do makes a call on the endpoints in turn until one answers with a result or
+// an error of the chain.
+func (b *Backend) do(ctx context.Context, method string, call func(ctx context.Context, n Node) error) error {
+	return b.doEndpoint(ctx, method, func(ctx context.Context, e *endpoint) error {
+		return call(ctx, e.Node)
+	})
+}
+
+// doEndpoint is do for the calls depending on the state of the endpoint. The
+// endpoints behind the block of a call are skipped without being failed.
+func (b *Backend) doEndpoint(ctx context.Context, method string, call func(ctx context.Context, e *endpoint) error) error {
+	var err error
+	for i, e := range b.order() {
+		callCtx, cancel := b.callContext(ctx)
+		err = call(callCtx, e)
+		cancel()
+		if err == nil || ctx.Err() != nil || isChainError(err) {
+			return err
+		}
+		if _, ok := err.(*behindError); ok {
+			b.logger().Debug("RPC endpoint behind the call, skipping it", "method", method, "endpoint", e.Name, "err", err)
+			continue
+		}
+		b.fail(e, err)
+		if i+1 < len(b.endpoints) {
+			b.logger().Debug("RPC call failed, failing over", "method", method, "endpoint", e.Name, "err", err)
+		}
+	}
+	return errors.Wrapf(err, "%s failed on all the RPC endpoints", method)
+}
+
+// CodeAt implements bind.ContractCaller.
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, block *big.Int) ([]byte, error) {
+	var code []byte
+	err := b.do(ctx, "eth_getCode", func(ctx context.Context, n Node) (err error) {
+		code, err = n.CodeAt(ctx, contract, block)
+		return err
+	})
+	return code, err
+}
+
+// CallContract implements bind.ContractCaller.
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	var out []byte
+	err := b.do(ctx, "eth_call", func(ctx context.Context, n Node) (err error) {
+		out, err = n.CallContract(ctx, call, block)
+		return err
+	})
+	return out, err
+}
+
+// PendingCodeAt implements bind.ContractTransactor.
+func (b *Backend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	var code []byte
+	err := b.do(ctx, "eth_getCode", func(ctx context.Context, n Node) (err error) {
+		code, err = n.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+// PendingNonceAt implements bind.ContractTransactor.
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	var nonce uint64
+	err := b.do(ctx, "eth_getTransactionCount", func(ctx context.Context, n Node) (err error) {
+		nonce, err = n.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+// SuggestGasPrice implements bind.ContractTransactor.
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var price *big.Int
+	err := b.do(ctx, "eth_gasPrice", func(ctx context.Context, n Node) (err error) {
+		price, err = n.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+// EstimateGas implements bind.ContractTransactor.
+func (b *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := b.do(ctx, "eth_estimateGas", func(ctx context.Context, n Node) (err error) {
+		gas, err = n.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction implements bind.ContractTransactor. An endpoint failing
+// may still have broadcast the transaction, the next one then reports it as
+// known, which is a success.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempt := 0
+	return b.do(ctx, "eth_sendRawTransaction", func(ctx context.Context, n Node) error {
+		attempt++
+		err := n.SendTransaction(ctx, tx)
+		if err != nil && attempt > 1 && isKnownTransaction(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// FilterLogs implements bind.ContractFilterer. A query up to a block is only
+// sent to the endpoints which reached that block: a node lagging behind
+// returns the logs of the blocks it has without an error, leaving out the
+// logs of the blocks the caller read the head of from another endpoint.
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := b.doEndpoint(ctx, "eth_getLogs", func(ctx context.Context, e *endpoint) (err error) {
+		if query.BlockHash == nil && query.ToBlock != nil && query.ToBlock.Sign() >= 0 {
+			err = b.reached(ctx, e, query.ToBlock.Uint64())
+			if err != nil {
+				return err
+			}
+		}
+		logs, err = e.Node.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+// reached returns a *behindError when the endpoint has not reached the block,
+// asking the endpoint its head when the last one known is below the block.
+func (b *Backend) reached(ctx context.Context, e *endpoint, block uint64) error {
+	e.mu.Lock()
+	head := e.head
+	e.mu.Unlock()
+	if head >= block {
+		return nil
+	}
+	h, err := e.Node.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head = e.advance(h.Number.Uint64())
+	if head < block {
+		return &behindError{head: head, block: block}
+	}
+	return nil
+}
+
+// SubscribeFilterLogs implements bind.ContractFilterer. The subscription is
+// made on the first endpoint accepting it and ends with its connection, see
+// package watch to resubscribe.
+func (b *Backend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	var err error
+	for _, e := range b.order() {
+		var sub ethereum.Subscription
+		sub, err = e.Node.SubscribeFilterLogs(ctx, query, ch)
+		if err == nil || ctx.Err() != nil {
+			return sub, err
+		}
+		// The HTTP endpoints do not support subscriptions, they are not
+		// failing.
+		if !strings.Contains(err.Error(), "notifications not supported") {
+			b.fail(e, err)
+		}
+	}
+	return nil, errors.Wrap(err, "subscribing on all the RPC endpoints")
+}
+
+// TransactionReceipt implements bind.DeployBackend.
+func (b *Backend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	var r *types.Receipt
+	err := b.do(ctx, "eth_getTransactionReceipt", func(ctx context.Context, n Node) (err error) {
+		r, err = n.TransactionReceipt(ctx, hash)
+		return err
+	})
+	return r, err
+}
+
+// HeaderByNumber returns the header of a block, the latest one when number is
+// nil.
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var h *types.Header
+	err := b.doEndpoint(ctx, "eth_getBlockByNumber", func(ctx context.Context, e *endpoint) (err error) {
+		h, err = e.Node.HeaderByNumber(ctx, number)
+		if err == nil && number == nil {
+			e.advance(h.Number.Uint64())
+		}
+		return err
+	})
+	return h, err
+}
+
+// BalanceAt returns the balance of an account at a block, the latest one
+// when block is nil.
+func (b *Backend) BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error) {
+	var balance *big.Int
+	err := b.do(ctx, "eth_getBalance", func(ctx context.Context, n Node) (err error) {
+		balance, err = n.BalanceAt(ctx, account, block)
+		return err
+	})
+	return balance, err
+}
+
+// ChainID returns the chain ID of the endpoints.
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	var id *big.Int
+	err := b.do(ctx, "eth_chainId", func(ctx context.Context, n Node) (err error) {
+		id, err = n.ChainID(ctx)
+		return err
+	})
+	return id, err
+}
+
+func (b *Backend) logger() logging.Logger {
+	return logging.Or(b.Logger)
+}
+
+// chainErrors are parts of the errors of the nodes which every endpoint
+// returns alike: the call reverted or the transaction is invalid.
+var chainErrors = []string{
+	"execution reverted",
+	"revert",
+	"invalid opcode",
+	"out of gas",
+	"gas required exceeds",
+	"intrinsic gas too low",
+	"insufficient funds",
+	"nonce too low",
+	"nonce too high",
+	"replacement transaction underpriced",
+	"already known",
+	"known transaction",
+	"invalid sender",
+	"exceeds block gas limit",
+}
+
+// isChainError tells whether the error comes from the chain rather than from
+// the endpoint, so that failing over would not help.
+func isChainError(err error) bool {
+	return err == ethereum.NotFound || contains(err, chainErrors)
+}
+
+func isKnownTransaction(err error) bool {
+	return contains(err, []string{"already known", "known transaction"})
+}
+
+func contains(err error, parts []string) bool {
+	msg := strings.ToLower(err.Error())
+	for _, p := range parts {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
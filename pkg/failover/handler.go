@@ -0,0 +1,14 @@
+package failover
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the health of the endpoints of the backend as JSON.
+func Handler(b *Backend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b.Status())
+	})
+}
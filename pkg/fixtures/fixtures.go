@@ -0,0 +1,59 @@
+// Package fixtures holds canned transactions and events of the contracts,
+// generated on the deterministic simulated chain of package difftest, for the
+// tests of the consumers of the events which do not run a chain: frontends,
+// backends and the services written in other languages.
+//
+// The fixtures are the JSON files of the data directory, one per scenario,
+// versioned with the module, see difftest.Fixture for their format. Load
+// reads them from Go, the other languages read the files. They are
+// regenerated when the scenarios or the contracts change:
+//
+//	go test ./test/fixtures -update
+package fixtures
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/difftest"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+// Config is the chain the scenarios run on.
+var Config = testutil.Config{Accounts: 3}
+
+// Dir is the directory of the fixtures, relative to this package.
+const Dir = "data"
+
+//go:embed data/*.json
+var files embed.FS
+
+// Names returns the names of the scenarios, sorted.
+func Names() []string {
+	names := make([]string, 0, len(Scenarios))
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the fixture of the named scenario.
+func Load(name string) (*difftest.Fixture, error) {
+	b, err := files.ReadFile(path.Join(Dir, name+".json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading fixture %s", name)
+	}
+	f := &difftest.Fixture{}
+	err = json.Unmarshal(b, f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding fixture %s", name)
+	}
+	if f.Version != difftest.FixtureVersion {
+		return nil, errors.Errorf("fixture %s has version %d, expected %d", name, f.Version, difftest.FixtureVersion)
+	}
+	return f, nil
+}
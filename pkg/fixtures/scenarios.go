@@ -0,0 +1,112 @@
+package fixtures
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/difftest"
+)
+
+// Scenarios are the scenarios of the fixtures, by name.
+var Scenarios = map[string]difftest.Scenario{
+	"tkn":        tkn,
+	"controller": controller,
+}
+
+// tkn moves TKN between the accounts: transfers, an allowance spent and
+// changed, and a mint.
+func tkn(ctx context.Context, env *difftest.Env) error {
+	t, err := mocks.NewBurnerToken(env.Chain.TKNAddress, env.Backend)
+	if err != nil {
+		return err
+	}
+	deployer := env.Chain.Deployer
+	alice, bob, carol := env.Chain.Accounts[0], env.Chain.Accounts[1], env.Chain.Accounts[2]
+
+	_, err = t.Transfer(alice.Session.TransactOpts(), bob.Address, big.NewInt(1500000000))
+	if err != nil {
+		return err
+	}
+	_, err = t.Approve(bob.Session.TransactOpts(), carol.Address, big.NewInt(500000000))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = t.TransferFrom(carol.Session.TransactOpts(), bob.Address, alice.Address, big.NewInt(200000000))
+	if err != nil {
+		return err
+	}
+	_, err = t.DecreaseApproval(bob.Session.TransactOpts(), carol.Address, big.NewInt(300000000))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = t.Mint(deployer.Session.TransactOpts(), carol.Address, big.NewInt(100000000))
+	if err != nil {
+		return err
+	}
+	return env.Commit(ctx)
+}
+
+// controller deploys a Controller and manages its roles: an admin and two
+// controllers added, a controller removed, and the controller stopped and
+// started again.
+func controller(ctx context.Context, env *difftest.Env) error {
+	owner := env.Chain.Deployer
+	admin, first, second := env.Chain.Accounts[0], env.Chain.Accounts[1], env.Chain.Accounts[2]
+
+	address, _, c, err := bindings.DeployController(owner.Session.TransactOpts(), env.Backend, owner.Address)
+	if err != nil {
+		return err
+	}
+	env.Name("controller", address, bindings.ControllerParsedABI)
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.AddAdmin(owner.Session.TransactOpts(), admin.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.AddController(admin.Session.TransactOpts(), first.Address)
+	if err != nil {
+		return err
+	}
+	_, err = c.AddController(admin.Session.TransactOpts(), second.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.RemoveController(admin.Session.TransactOpts(), first.Address)
+	if err != nil {
+		return err
+	}
+	_, err = c.Stop(admin.Session.TransactOpts())
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.Start(owner.Session.TransactOpts())
+	if err != nil {
+		return err
+	}
+	return env.Commit(ctx)
+}
@@ -0,0 +1,280 @@
+// Package fork runs the bindings against a local node forking mainnet, such
+// as anvil or a hardhat node, to check their behaviour against the contracts
+// actually deployed:
+//
+//	anvil --fork-url $MAINNET_RPC_URL
+//
+// Besides the usual backend, a Node impersonates accounts, the owners of the
+// contracts in particular, and moves the chain forward. It uses the hardhat_
+// and evm_ methods, which anvil supports as well.
+package fork
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/externals/ens"
+)
+
+// ENSRegistry is the address of the ENS registry on mainnet.
+var ENSRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// OwnerBalance is the ether balance in wei ImpersonateOwner tops the owners
+// up to, the owners being often contracts holding no ether.
+var OwnerBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+
+const ownerABI = `[{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+// Node is a forking node.
+type Node struct {
+	*ethclient.Client
+
+	rpc *rpc.Client
+
+	mu sync.Mutex
+	// senders are the impersonated senders of the transactions signed with
+	// the options returned by Impersonate, by the hash of the unsigned
+	// transaction.
+	senders map[common.Hash]common.Address
+	// hashes are the hashes given by the node to the impersonated
+	// transactions, by the hash of the unsigned transaction.
+	hashes map[common.Hash]common.Hash
+}
+
+// Dial connects to the node at url.
+func Dial(ctx context.Context, url string) (*Node, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dialing %s", url)
+	}
+	return NewNode(c), nil
+}
+
+// NewNode returns the node behind c.
+func NewNode(c *rpc.Client) *Node {
+	return &Node{
+		Client:  ethclient.NewClient(c),
+		rpc:     c,
+		senders: make(map[common.Hash]common.Address),
+		hashes:  make(map[common.Hash]common.Hash),
+	}
+}
+
+// Impersonate lets the transactions of account be sent without its key, and
+// returns the options to send them with the bindings. The transactions are
+// sent with eth_sendTransaction, the node giving them another hash than the
+// one of the transaction returned by the bindings; TransactionReceipt and
+// TransactionByHash accept both.
+func (n *Node) Impersonate(ctx context.Context, account common.Address) (*bind.TransactOpts, error) {
+	err := n.rpc.CallContext(ctx, nil, "hardhat_impersonateAccount", account)
+	if err != nil {
+		return nil, errors.Wrapf(err, "impersonating %s", account.Hex())
+	}
+	return &bind.TransactOpts{
+		From: account,
+		Signer: func(_ types.Signer, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != account {
+				return nil, errors.Errorf("not impersonating %s", from.Hex())
+			}
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			n.senders[tx.Hash()] = from
+			return tx, nil
+		},
+		Context: ctx,
+	}, nil
+}
+
+// StopImpersonating reverts Impersonate.
+func (n *Node) StopImpersonating(ctx context.Context, account common.Address) error {
+	err := n.rpc.CallContext(ctx, nil, "hardhat_stopImpersonatingAccount", account)
+	return errors.Wrapf(err, "stopping impersonating %s", account.Hex())
+}
+
+// Owner returns the owner of an ownable contract.
+func (n *Node) Owner(ctx context.Context, contract common.Address) (common.Address, error) {
+	parsed, err := abi.JSON(strings.NewReader(ownerABI))
+	if err != nil {
+		return common.Address{}, err
+	}
+	var owner common.Address
+	err = bind.NewBoundContract(contract, parsed, n, nil, nil).Call(&bind.CallOpts{Context: ctx}, &owner, "owner")
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "getting the owner of %s", contract.Hex())
+	}
+	return owner, nil
+}
+
+// ImpersonateOwner impersonates the owner of an ownable contract, making sure
+// it holds at least OwnerBalance to pay for its transactions.
+func (n *Node) ImpersonateOwner(ctx context.Context, contract common.Address) (*bind.TransactOpts, error) {
+	owner, err := n.Owner(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := n.BalanceAt(ctx, owner, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the balance of %s", owner.Hex())
+	}
+	if balance.Cmp(OwnerBalance) < 0 {
+		err = n.SetBalance(ctx, owner, OwnerBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n.Impersonate(ctx, owner)
+}
+
+// SetBalance sets the ether balance of account in wei.
+func (n *Node) SetBalance(ctx context.Context, account common.Address, balance *big.Int) error {
+	err := n.rpc.CallContext(ctx, nil, "hardhat_setBalance", account, (*hexutil.Big)(balance))
+	return errors.Wrapf(err, "setting the balance of %s", account.Hex())
+}
+
+// Mine mines the given number of blocks.
+func (n *Node) Mine(ctx context.Context, blocks uint64) error {
+	err := n.rpc.CallContext(ctx, nil, "hardhat_mine", hexutil.Uint64(blocks))
+	return errors.Wrapf(err, "mining %d blocks", blocks)
+}
+
+// IncreaseTime moves the time of the chain forward by d and mines a block
+// with the new time.
+func (n *Node) IncreaseTime(ctx context.Context, d time.Duration) error {
+	err := n.rpc.CallContext(ctx, nil, "evm_increaseTime", int64(d/time.Second))
+	if err != nil {
+		return errors.Wrapf(err, "increasing time by %s", d)
+	}
+	err = n.rpc.CallContext(ctx, nil, "evm_mine")
+	return errors.Wrap(err, "mining a block")
+}
+
+// Snapshot saves the state of the chain, restored with Revert.
+func (n *Node) Snapshot(ctx context.Context) (string, error) {
+	var id string
+	err := n.rpc.CallContext(ctx, &id, "evm_snapshot")
+	if err != nil {
+		return "", errors.Wrap(err, "taking a snapshot")
+	}
+	return id, nil
+}
+
+// Revert restores the state saved by Snapshot. A snapshot can only be
+// reverted to once.
+func (n *Node) Revert(ctx context.Context, id string) error {
+	var ok bool
+	err := n.rpc.CallContext(ctx, &ok, "evm_revert", id)
+	if err != nil {
+		return errors.Wrapf(err, "reverting to snapshot %s", id)
+	}
+	if !ok {
+		return errors.Errorf("unknown snapshot %s", id)
+	}
+	return nil
+}
+
+// Resolve returns the address an ENS name resolves to, using the mainnet
+// registry.
+func (n *Node) Resolve(ctx context.Context, name string) (common.Address, error) {
+	registry, err := ens.NewENSRegistry(ENSRegistry, n)
+	if err != nil {
+		return common.Address{}, err
+	}
+	node := namehash(name)
+	opts := &bind.CallOpts{Context: ctx}
+	resolverAddress, err := registry.Resolver(opts, node)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "getting the resolver of %s", name)
+	}
+	if resolverAddress == (common.Address{}) {
+		return common.Address{}, errors.Errorf("%s has no resolver", name)
+	}
+	resolver, err := ens.NewPublicResolver(resolverAddress, n)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, err := resolver.Addr(opts, node)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "resolving %s", name)
+	}
+	return address, nil
+}
+
+// SendTransaction implements bind.ContractTransactor, sending the
+// transactions of the impersonated accounts with eth_sendTransaction.
+func (n *Node) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	n.mu.Lock()
+	from, ok := n.senders[tx.Hash()]
+	n.mu.Unlock()
+	if !ok {
+		return n.Client.SendTransaction(ctx, tx)
+	}
+
+	args := map[string]interface{}{
+		"from":     from,
+		"gas":      hexutil.Uint64(tx.Gas()),
+		"gasPrice": (*hexutil.Big)(tx.GasPrice()),
+		"value":    (*hexutil.Big)(tx.Value()),
+		"data":     hexutil.Bytes(tx.Data()),
+		"nonce":    hexutil.Uint64(tx.Nonce()),
+	}
+	if tx.To() != nil {
+		args["to"] = tx.To()
+	}
+	var hash common.Hash
+	err := n.rpc.CallContext(ctx, &hash, "eth_sendTransaction", args)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.senders, tx.Hash())
+	n.hashes[tx.Hash()] = hash
+	return nil
+}
+
+// hash returns the hash the node knows the transaction by.
+func (n *Node) hash(h common.Hash) common.Hash {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if sent, ok := n.hashes[h]; ok {
+		return sent
+	}
+	return h
+}
+
+// TransactionReceipt implements bind.DeployBackend.
+func (n *Node) TransactionReceipt(ctx context.Context, h common.Hash) (*types.Receipt, error) {
+	return n.Client.TransactionReceipt(ctx, n.hash(h))
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (n *Node) TransactionByHash(ctx context.Context, h common.Hash) (*types.Transaction, bool, error) {
+	return n.Client.TransactionByHash(ctx, n.hash(h))
+}
+
+// namehash returns the ENS node of the given name.
+func namehash(name string) [32]byte {
+	node := common.Hash{}
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], label[:])
+	}
+	return node
+}
@@ -0,0 +1,28 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// Backend wraps a bind.ContractBackend so that the generated bindings take their
+// gas price from an Oracle whenever TransactOpts.GasPrice is left nil.
+//
+// Only the bind.ContractBackend methods are exposed, use the wrapped backend
+// directly for anything else (e.g. bind.WaitMined).
+type Backend struct {
+	bind.ContractBackend
+	oracle *Oracle
+}
+
+// NewBackend returns a backend using the oracle for gas price suggestions.
+func NewBackend(backend bind.ContractBackend, oracle *Oracle) *Backend {
+	return &Backend{ContractBackend: backend, oracle: oracle}
+}
+
+// SuggestGasPrice implements bind.ContractTransactor.
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.oracle.SuggestGasPrice(ctx)
+}
@@ -0,0 +1,114 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultEWMAAlpha is the weight of each new block of the configurations
+	// leaving it out.
+	DefaultEWMAAlpha = 0.2
+	// DefaultEWMAWindow is the number of blocks read when catching up with
+	// the head of the configurations leaving it out.
+	DefaultEWMAWindow = 20
+)
+
+// BlockReader is the subset of ethereum.ChainReader needed by EWMASource.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// EWMASource computes an exponentially weighted moving average of the median gas
+// price paid in recent blocks, scaled up or down depending on the strategy.
+type EWMASource struct {
+	reader BlockReader
+	alpha  float64
+	window uint64
+
+	mu        sync.Mutex
+	average   *big.Float
+	lastBlock *big.Int
+}
+
+// NewEWMASource creates a new source weighting each new block by alpha (0 < alpha <= 1).
+// At most window blocks are read when catching up with the chain head.
+func NewEWMASource(reader BlockReader, alpha float64, window uint64) (*EWMASource, error) {
+	if alpha <= 0 || alpha > 1 {
+		return nil, errors.Errorf("EWMA alpha %v is out of range (0, 1]", alpha)
+	}
+	if window == 0 {
+		return nil, errors.New("EWMA window must be greater than zero")
+	}
+	return &EWMASource{reader: reader, alpha: alpha, window: window}, nil
+}
+
+// GasPrice implements Source.
+func (e *EWMASource) GasPrice(ctx context.Context, strategy Strategy) (*big.Int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	head, err := e.reader.BlockByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting latest block")
+	}
+
+	from := new(big.Int).Sub(head.Number(), new(big.Int).SetUint64(e.window-1))
+	if e.lastBlock != nil && e.lastBlock.Cmp(from) >= 0 {
+		from = new(big.Int).Add(e.lastBlock, big.NewInt(1))
+	}
+	if from.Sign() < 0 {
+		from = big.NewInt(0)
+	}
+
+	for n := from; n.Cmp(head.Number()) <= 0; n = new(big.Int).Add(n, big.NewInt(1)) {
+		block := head
+		if n.Cmp(head.Number()) != 0 {
+			block, err = e.reader.BlockByNumber(ctx, n)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting block %s", n)
+			}
+		}
+		e.add(block)
+		e.lastBlock = n
+	}
+
+	if e.average == nil {
+		return nil, errors.New("no transactions found in recent blocks")
+	}
+
+	price, _ := e.average.Int(nil)
+	return strategy.scale(price), nil
+}
+
+// add folds the median gas price of the block into the moving average.
+func (e *EWMASource) add(block *types.Block) {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return
+	}
+
+	prices := make([]*big.Int, len(txs))
+	for i, tx := range txs {
+		prices[i] = tx.GasPrice()
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	median := new(big.Float).SetInt(prices[len(prices)/2])
+
+	if e.average == nil {
+		e.average = median
+		return
+	}
+
+	// average = alpha * median + (1 - alpha) * average
+	weighted := new(big.Float).Mul(median, big.NewFloat(e.alpha))
+	e.average.Mul(e.average, big.NewFloat(1-e.alpha))
+	e.average.Add(e.average, weighted)
+}
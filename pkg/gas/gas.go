@@ -0,0 +1,106 @@
+// Package gas provides pluggable gas price sources and the strategies used to
+// pick a price from them, so that callers no longer need to hardcode GasPrice
+// in their TransactOpts.
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Strategy describes how quickly a transaction should be mined.
+type Strategy int
+
+const (
+	// Standard targets inclusion within a few blocks.
+	Standard Strategy = iota
+	// Fast targets inclusion in the next block or two.
+	Fast
+	// Slow is for transactions that are not time sensitive.
+	Slow
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Standard:
+		return "standard"
+	case Fast:
+		return "fast"
+	case Slow:
+		return "slow"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// ParseStrategy converts the name of a strategy into a Strategy.
+func ParseStrategy(name string) (Strategy, error) {
+	switch name {
+	case "standard", "":
+		return Standard, nil
+	case "fast":
+		return Fast, nil
+	case "slow":
+		return Slow, nil
+	default:
+		return Standard, errors.Errorf("unknown gas price strategy %q", name)
+	}
+}
+
+// percentage returns the multiplier (in percent) applied by single valued sources.
+func (s Strategy) percentage() int64 {
+	switch s {
+	case Fast:
+		return 125
+	case Slow:
+		return 80
+	default:
+		return 100
+	}
+}
+
+// scale applies the strategy multiplier to a single valued price suggestion.
+func (s Strategy) scale(price *big.Int) *big.Int {
+	r := new(big.Int).Mul(price, big.NewInt(s.percentage()))
+	return r.Div(r, big.NewInt(100))
+}
+
+// Source is a provider of gas prices.
+type Source interface {
+	// GasPrice returns the gas price in wei for the given strategy.
+	GasPrice(ctx context.Context, strategy Strategy) (*big.Int, error)
+}
+
+// Oracle selects a gas price from a Source using a fixed strategy, optionally
+// bounding the result.
+type Oracle struct {
+	Source   Source
+	Strategy Strategy
+
+	// Min and Max bound the returned price when set.
+	Min *big.Int
+	Max *big.Int
+}
+
+// NewOracle creates a new oracle using the given source and strategy.
+func NewOracle(source Source, strategy Strategy) *Oracle {
+	return &Oracle{Source: source, Strategy: strategy}
+}
+
+// SuggestGasPrice returns the gas price in wei that should be used for the next transaction.
+func (o *Oracle) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	price, err := o.Source.GasPrice(ctx, o.Strategy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting %s gas price", o.Strategy)
+	}
+	if o.Min != nil && price.Cmp(o.Min) < 0 {
+		price = new(big.Int).Set(o.Min)
+	}
+	if o.Max != nil && price.Cmp(o.Max) > 0 {
+		price = new(big.Int).Set(o.Max)
+	}
+	return price, nil
+}
@@ -0,0 +1,28 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+)
+
+// NodeSource uses the gas price suggested by an Ethereum node, scaled up or
+// down depending on the strategy.
+type NodeSource struct {
+	pricer ethereum.GasPricer
+}
+
+// NewNodeSource creates a new source backed by the node's eth_gasPrice suggestion.
+func NewNodeSource(pricer ethereum.GasPricer) *NodeSource {
+	return &NodeSource{pricer: pricer}
+}
+
+// GasPrice implements Source.
+func (n *NodeSource) GasPrice(ctx context.Context, strategy Strategy) (*big.Int, error) {
+	price, err := n.pricer.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strategy.scale(price), nil
+}
@@ -0,0 +1,77 @@
+package gas
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultStationURL is the EthGasStation price endpoint.
+const DefaultStationURL = "https://ethgasstation.info/json/ethgasAPI.json"
+
+// stationResponse is the subset of the EthGasStation response used by StationSource.
+// Prices are expressed in tenths of a gwei.
+type stationResponse struct {
+	Fast    float64 `json:"fast"`
+	Average float64 `json:"average"`
+	SafeLow float64 `json:"safeLow"`
+}
+
+// StationSource queries an EthGasStation-style JSON API.
+type StationSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewStationSource creates a new source querying the given URL. If client is nil
+// http.DefaultClient is used.
+func NewStationSource(url string, client *http.Client) *StationSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &StationSource{url: url, client: client}
+}
+
+// GasPrice implements Source.
+func (s *StationSource) GasPrice(ctx context.Context, strategy Strategy) (*big.Int, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "querying gas station")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("gas station returned status %d", res.StatusCode)
+	}
+
+	var sr stationResponse
+	err = json.NewDecoder(res.Body).Decode(&sr)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding gas station response")
+	}
+
+	var tenthsOfGwei float64
+	switch strategy {
+	case Fast:
+		tenthsOfGwei = sr.Fast
+	case Slow:
+		tenthsOfGwei = sr.SafeLow
+	default:
+		tenthsOfGwei = sr.Average
+	}
+	if tenthsOfGwei <= 0 {
+		return nil, errors.Errorf("gas station returned no %s price", strategy)
+	}
+
+	// 1 tenth of a gwei is 10^8 wei.
+	wei, _ := new(big.Float).Mul(big.NewFloat(tenthsOfGwei), big.NewFloat(1e8)).Int(nil)
+	return wei, nil
+}
@@ -0,0 +1,539 @@
+// Package graphql serves the events stored by the indexer through a GraphQL
+// endpoint. The schema is:
+//
+//	type Query {
+//		head: Int
+//		events(contract: String, name: String, fromBlock: Int, toBlock: Int, where: JSON, first: Int, after: String): EventConnection!
+//		tokens(address: String): [Token!]!
+//	}
+//
+//	type EventConnection {
+//		edges: [EventEdge!]!
+//		pageInfo: PageInfo!
+//	}
+//
+//	type EventEdge {
+//		cursor: String!
+//		node: Event!
+//	}
+//
+//	type PageInfo {
+//		hasNextPage: Boolean!
+//		endCursor: String
+//	}
+//
+//	type Event {
+//		contract: String!
+//		address: String!
+//		name: String!
+//		blockNumber: Int!
+//		blockHash: String!
+//		transactionHash: String!
+//		transactionIndex: Int!
+//		logIndex: Int!
+//		removed: Boolean!
+//		args: JSON!
+//	}
+//
+//	type Token {
+//		address: String!
+//		symbol: String!
+//		whitelisted: Boolean!
+//		loadable: Boolean!
+//		redeemable: Boolean!
+//		updatedAt: Int!
+//	}
+//
+// The where argument of events selects the events by the value of their
+// arguments, for instance all the transfers to an address between two blocks:
+//
+//	{
+//		events(name: "Transferred", where: {_to: "0x..."}, fromBlock: 100, toBlock: 200) {
+//			edges { node { transactionHash args } }
+//			pageInfo { hasNextPage endCursor }
+//		}
+//	}
+//
+// The tokens field folds the events of the token whitelist into the current
+// status of each token. Only single query operations are supported, without
+// fragments or directives.
+package graphql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+const (
+	// DefaultPageSize is the number of events returned when first is not set.
+	DefaultPageSize = 100
+	// MaxPageSize is the maximum number of events returned in a page.
+	MaxPageSize = 1000
+)
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response.
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error.
+type Error struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"`
+}
+
+// Handler executes GraphQL queries against the events of an indexer store.
+type Handler struct {
+	store indexer.Store
+}
+
+// NewHandler creates a new GraphQL handler reading from the given store.
+func NewHandler(store indexer.Store) *Handler {
+	return &Handler{store: store}
+}
+
+// ServeHTTP implements http.Handler. Queries are accepted as a JSON body of a
+// POST request or as the query parameter of a GET request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			err := json.Unmarshal([]byte(v), &req.Variables)
+			if err != nil {
+				writeResponse(w, http.StatusBadRequest, Response{Errors: []Error{{Message: "invalid variables: " + err.Error()}}})
+				return
+			}
+		}
+	case http.MethodPost:
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, Response{Errors: []Error{{Message: "invalid request body: " + err.Error()}}})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeResponse(w, http.StatusMethodNotAllowed, Response{Errors: []Error{{Message: "method not allowed"}}})
+		return
+	}
+
+	resp := h.Execute(req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeResponse(w, status, resp)
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Execute executes a GraphQL request. Request errors are returned without data,
+// field errors are returned alongside the fields that could be resolved.
+func (h *Handler) Execute(req Request) Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	vars := doc.variables
+	for k, v := range req.Variables {
+		vars[k] = v
+	}
+
+	e := &executor{store: h.store, variables: vars, seen: make(map[string]bool)}
+	data := e.resolveObject(nil, "Query", doc.selections, e.resolveQueryField)
+	return Response{Data: data, Errors: e.errors}
+}
+
+type executor struct {
+	store     indexer.Store
+	variables map[string]interface{}
+	errors    []Error
+	// seen deduplicates the errors reported for each element of a list.
+	seen map[string]bool
+}
+
+func (e *executor) fail(path []string, format string, args ...interface{}) {
+	err := Error{Message: fmt.Sprintf(format, args...), Path: path}
+	key := strings.Join(path, ".") + "\x00" + err.Message
+	if e.seen[key] {
+		return
+	}
+	e.seen[key] = true
+	e.errors = append(e.errors, err)
+}
+
+// fieldResolver resolves a field of an object. It returns an error message if
+// the field cannot be resolved.
+type fieldResolver func(path []string, f *field) (interface{}, error)
+
+// resolveObject resolves the selected fields of an object of the given type.
+// Fields that cannot be resolved are set to null and reported as errors.
+func (e *executor) resolveObject(path []string, typename string, fields []*field, resolve fieldResolver) object {
+	o := make(object, 0, len(fields))
+	for _, f := range fields {
+		p := append(path[:len(path):len(path)], f.key())
+		var v interface{}
+		if f.name == "__typename" {
+			v = typename
+		} else {
+			var err error
+			v, err = resolve(p, f)
+			if err != nil {
+				e.fail(p, "%s", err)
+				v = nil
+			}
+		}
+		o = append(o, member{f.key(), v})
+	}
+	return o
+}
+
+func unknownField(typename string, f *field) error {
+	return fmt.Errorf("cannot query field %q on type %q", f.name, typename)
+}
+
+func requireSelection(typename string, f *field) error {
+	if len(f.selections) == 0 {
+		return fmt.Errorf("field %q of type %q must have a selection of subfields", f.name, typename)
+	}
+	return nil
+}
+
+func (e *executor) resolveQueryField(path []string, f *field) (interface{}, error) {
+	switch f.name {
+	case "head":
+		if head, ok := e.store.Head(); ok {
+			return head, nil
+		}
+		return nil, nil
+	case "events":
+		return e.resolveEvents(path, f)
+	case "tokens":
+		return e.resolveTokens(path, f)
+	}
+	return nil, unknownField("Query", f)
+}
+
+func (e *executor) resolveEvents(path []string, f *field) (interface{}, error) {
+	err := requireSelection("EventConnection!", f)
+	if err != nil {
+		return nil, err
+	}
+
+	q := indexer.Query{Limit: DefaultPageSize}
+	for name, arg := range f.arguments {
+		v := e.resolveValue(arg)
+		if v == nil {
+			continue
+		}
+		var ok bool
+		switch name {
+		case "contract":
+			q.Contract, ok = v.(string)
+		case "name":
+			q.Name, ok = v.(string)
+		case "fromBlock":
+			q.FromBlock, ok = toUint64(v)
+		case "toBlock":
+			q.ToBlock, ok = toUint64(v)
+		case "where":
+			q.Args, ok = toStringMap(v)
+		case "first":
+			var n uint64
+			n, ok = toUint64(v)
+			ok = ok && n > 0 && n <= MaxPageSize
+			q.Limit = int(n)
+		case "after":
+			var cursor string
+			cursor, ok = v.(string)
+			if ok {
+				q.After, ok = decodeCursor(cursor)
+			}
+		default:
+			return nil, fmt.Errorf("unknown argument %q on field %q", name, f.name)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid value %v for argument %q", v, name)
+		}
+	}
+
+	// An extra event is requested to know if there is a next page.
+	pageSize := q.Limit
+	q.Limit++
+	events, err := e.store.Events(q)
+	if err != nil {
+		return nil, err
+	}
+	hasNextPage := len(events) > pageSize
+	if hasNextPage {
+		events = events[:pageSize]
+	}
+
+	return e.resolveObject(path, "EventConnection", f.selections, func(path []string, f *field) (interface{}, error) {
+		switch f.name {
+		case "edges":
+			err := requireSelection("[EventEdge!]!", f)
+			if err != nil {
+				return nil, err
+			}
+			edges := make([]object, len(events))
+			for i, ev := range events {
+				edges[i] = e.resolveEdge(path, f.selections, ev)
+			}
+			return edges, nil
+		case "pageInfo":
+			err := requireSelection("PageInfo!", f)
+			if err != nil {
+				return nil, err
+			}
+			return e.resolveObject(path, "PageInfo", f.selections, func(path []string, f *field) (interface{}, error) {
+				switch f.name {
+				case "hasNextPage":
+					return hasNextPage, nil
+				case "endCursor":
+					if len(events) == 0 {
+						return nil, nil
+					}
+					return encodeCursor(events[len(events)-1].Position()), nil
+				}
+				return nil, unknownField("PageInfo", f)
+			}), nil
+		}
+		return nil, unknownField("EventConnection", f)
+	}), nil
+}
+
+func (e *executor) resolveEdge(path []string, fields []*field, ev indexer.Event) object {
+	return e.resolveObject(path, "EventEdge", fields, func(path []string, f *field) (interface{}, error) {
+		switch f.name {
+		case "cursor":
+			return encodeCursor(ev.Position()), nil
+		case "node":
+			err := requireSelection("Event!", f)
+			if err != nil {
+				return nil, err
+			}
+			return e.resolveObject(path, "Event", f.selections, func(path []string, f *field) (interface{}, error) {
+				return resolveEventField(ev, f)
+			}), nil
+		}
+		return nil, unknownField("EventEdge", f)
+	})
+}
+
+func resolveEventField(ev indexer.Event, f *field) (interface{}, error) {
+	switch f.name {
+	case "contract":
+		return ev.Contract, nil
+	case "address":
+		return ev.Address.Hex(), nil
+	case "name":
+		return ev.Name, nil
+	case "blockNumber":
+		return ev.BlockNumber, nil
+	case "blockHash":
+		return ev.BlockHash.Hex(), nil
+	case "transactionHash":
+		return ev.TxHash.Hex(), nil
+	case "transactionIndex":
+		return ev.TxIndex, nil
+	case "logIndex":
+		return ev.LogIndex, nil
+	case "removed":
+		return ev.Removed, nil
+	case "args":
+		args := make(map[string]interface{}, len(ev.Args))
+		for k, v := range ev.Args {
+			args[k] = indexer.FormatArg(v)
+		}
+		return args, nil
+	}
+	return nil, unknownField("Event", f)
+}
+
+// tokenStatus is the status of a token derived from the token whitelist events.
+type tokenStatus struct {
+	address     common.Address
+	symbol      string
+	whitelisted bool
+	loadable    bool
+	redeemable  bool
+	updatedAt   uint64
+}
+
+func (e *executor) resolveTokens(path []string, f *field) (interface{}, error) {
+	err := requireSelection("[Token!]!", f)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter *common.Address
+	for name, arg := range f.arguments {
+		v := e.resolveValue(arg)
+		if v == nil {
+			continue
+		}
+		if name != "address" {
+			return nil, fmt.Errorf("unknown argument %q on field %q", name, f.name)
+		}
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid value %v for argument %q", v, name)
+		}
+		a := common.HexToAddress(s)
+		filter = &a
+	}
+
+	events, err := e.store.Events(indexer.Query{Contract: "token_whitelist"})
+	if err != nil {
+		return nil, err
+	}
+
+	var order []common.Address
+	tokens := make(map[common.Address]*tokenStatus)
+	for _, ev := range events {
+		a, ok := indexer.AddressArg(ev, "_token")
+		if !ok || ev.Removed || (filter != nil && a != *filter) {
+			continue
+		}
+		t, ok := tokens[a]
+		if !ok {
+			t = &tokenStatus{address: a}
+			tokens[a] = t
+			order = append(order, a)
+		}
+		switch ev.Name {
+		case "AddedToken":
+			t.whitelisted = true
+			t.symbol, _ = ev.Args["_symbol"].(string)
+			t.loadable, _ = ev.Args["_loadable"].(bool)
+			t.redeemable, _ = ev.Args["_redeemable"].(bool)
+		case "RemovedToken":
+			*t = tokenStatus{address: a, symbol: t.symbol}
+		case "UpdatedTokenLoadable":
+			t.loadable, _ = ev.Args["_loadable"].(bool)
+		case "UpdatedTokenRedeemable":
+			t.redeemable, _ = ev.Args["_redeemable"].(bool)
+		default:
+			continue
+		}
+		t.updatedAt = ev.BlockNumber
+	}
+
+	list := make([]object, len(order))
+	for i, a := range order {
+		t := tokens[a]
+		list[i] = e.resolveObject(path, "Token", f.selections, func(path []string, f *field) (interface{}, error) {
+			switch f.name {
+			case "address":
+				return t.address.Hex(), nil
+			case "symbol":
+				return t.symbol, nil
+			case "whitelisted":
+				return t.whitelisted, nil
+			case "loadable":
+				return t.loadable, nil
+			case "redeemable":
+				return t.redeemable, nil
+			case "updatedAt":
+				return t.updatedAt, nil
+			}
+			return nil, unknownField("Token", f)
+		})
+	}
+	return list, nil
+}
+
+func (e *executor) resolveValue(v value) interface{} {
+	switch v := v.(type) {
+	case variable:
+		return e.variables[string(v)]
+	case []value:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = e.resolveValue(v[i])
+		}
+		return list
+	case map[string]value:
+		obj := make(map[string]interface{}, len(v))
+		for k := range v {
+			obj[k] = e.resolveValue(v[k])
+		}
+		return obj
+	}
+	return v
+}
+
+// toUint64 converts an integer argument, given either as a literal or as a
+// JSON decoded variable.
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return uint64(v), v >= 0
+	case float64:
+		return uint64(v), v >= 0 && v == float64(uint64(v))
+	}
+	return 0, false
+}
+
+// toStringMap converts an object argument to the string values it compares
+// event arguments with.
+func toStringMap(v interface{}) (map[string]string, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	m := make(map[string]string, len(obj))
+	for k, v := range obj {
+		if f, ok := v.(float64); ok && f == float64(int64(f)) {
+			v = int64(f)
+		}
+		m[k] = fmt.Sprint(v)
+	}
+	return m, true
+}
+
+// encodeCursor returns the opaque cursor of an event position.
+func encodeCursor(p indexer.Position) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", p.BlockNumber, p.LogIndex)))
+}
+
+func decodeCursor(cursor string) (*indexer.Position, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, false
+	}
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 2 {
+		return nil, false
+	}
+	block, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	log, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	return &indexer.Position{BlockNumber: block, LogIndex: uint(log)}, true
+}
@@ -0,0 +1,441 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// document is a parsed query operation.
+type document struct {
+	variables  map[string]interface{}
+	selections []*field
+}
+
+// field is a selected field of an object.
+type field struct {
+	alias      string
+	name       string
+	arguments  map[string]value
+	selections []*field
+}
+
+// key returns the name of the field in the response.
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// value is an argument value, resolved against the request variables.
+type value interface{}
+
+// variable is a reference to a request variable.
+type variable string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		// Commas are insignificant in GraphQL.
+		if c == ',' || unicode.IsSpace(rune(c)) {
+			l.pos++
+			continue
+		}
+		break
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("{}()[]:!$=@", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, text: string(c), pos: start}, nil
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		return token{}, fmt.Errorf("fragments are not supported (position %d)", start)
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, text: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		kind := tokenInt
+		for l.pos < len(l.src) {
+			c := l.src[l.pos]
+			if c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-' {
+				kind = tokenFloat
+			} else if !isDigit(c) {
+				break
+			}
+			l.pos++
+		}
+		return token{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string at position %d", start)
+		}
+		l.pos++
+		s, err := strconv.Unquote(l.src[start:l.pos])
+		if err != nil {
+			return token{}, fmt.Errorf("invalid string at position %d", start)
+		}
+		return token{kind: tokenString, text: s, pos: start}, nil
+	}
+	return token{}, fmt.Errorf("unexpected character %q at position %d", c, start)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser is a recursive descent parser of the subset of the GraphQL query
+// language used by the service: a single query operation made of fields with
+// aliases, arguments and variables. Fragments, directives and mutations are
+// not supported.
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+func parse(query string) (*document, error) {
+	p := &parser{lexer: &lexer{src: query}}
+	err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{variables: make(map[string]interface{})}
+	if p.tok.kind == tokenName {
+		if p.tok.text != "query" {
+			return nil, fmt.Errorf("unsupported operation %q", p.tok.text)
+		}
+		err = p.advance()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			err = p.advance()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if p.is("(") {
+			err = p.parseVariableDefinitions(doc.variables)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	doc.selections, err = p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d, only a single operation is supported", p.tok.text, p.tok.pos)
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) is(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.text == punctuator
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.is(punctuator) {
+		return p.unexpected(fmt.Sprintf("%q", punctuator))
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected("a name")
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) unexpected(expected string) error {
+	if p.tok.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of query, expected %s", expected)
+	}
+	return fmt.Errorf("unexpected %q at position %d, expected %s", p.tok.text, p.tok.pos, expected)
+}
+
+// parseVariableDefinitions parses the variable definitions of the operation
+// and records their default values.
+func (p *parser) parseVariableDefinitions(defaults map[string]interface{}) error {
+	err := p.expect("(")
+	if err != nil {
+		return err
+	}
+	for !p.is(")") {
+		err = p.expect("$")
+		if err != nil {
+			return err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		err = p.expect(":")
+		if err != nil {
+			return err
+		}
+		err = p.parseType()
+		if err != nil {
+			return err
+		}
+		if p.is("=") {
+			err = p.advance()
+			if err != nil {
+				return err
+			}
+			v, err := p.parseValue(true)
+			if err != nil {
+				return err
+			}
+			defaults[name] = v
+		}
+	}
+	return p.advance()
+}
+
+func (p *parser) parseType() error {
+	if p.is("[") {
+		err := p.advance()
+		if err != nil {
+			return err
+		}
+		err = p.parseType()
+		if err != nil {
+			return err
+		}
+		err = p.expect("]")
+		if err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.is("!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]*field, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	var fields []*field
+	for !p.is("}") {
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, p.unexpected("a field")
+	}
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (*field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	f := &field{name: name, arguments: make(map[string]value)}
+	if p.is(":") {
+		err = p.advance()
+		if err != nil {
+			return nil, err
+		}
+		f.alias = name
+		f.name, err = p.expectName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.is("(") {
+		err = p.advance()
+		if err != nil {
+			return nil, err
+		}
+		for !p.is(")") {
+			arg, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			err = p.expect(":")
+			if err != nil {
+				return nil, err
+			}
+			f.arguments[arg], err = p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = p.advance()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.is("@") {
+		return nil, fmt.Errorf("directives are not supported (position %d)", p.tok.pos)
+	}
+
+	if p.is("{") {
+		f.selections, err = p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseValue parses an input value. Constant values cannot reference variables.
+func (p *parser) parseValue(constant bool) (value, error) {
+	t := p.tok
+	switch t.kind {
+	case tokenInt:
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q at position %d", t.text, t.pos)
+		}
+		return n, p.advance()
+	case tokenFloat:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q at position %d", t.text, t.pos)
+		}
+		return f, p.advance()
+	case tokenString:
+		return t.text, p.advance()
+	case tokenName:
+		var v value
+		switch t.text {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			// Enum values are resolved as their name.
+			v = t.text
+		}
+		return v, p.advance()
+	case tokenPunctuator:
+		switch t.text {
+		case "$":
+			if constant {
+				return nil, fmt.Errorf("unexpected variable at position %d", t.pos)
+			}
+			err := p.advance()
+			if err != nil {
+				return nil, err
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return variable(name), nil
+		case "[":
+			err := p.advance()
+			if err != nil {
+				return nil, err
+			}
+			list := []value{}
+			for !p.is("]") {
+				v, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			return list, p.advance()
+		case "{":
+			err := p.advance()
+			if err != nil {
+				return nil, err
+			}
+			obj := map[string]value{}
+			for !p.is("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				err = p.expect(":")
+				if err != nil {
+					return nil, err
+				}
+				obj[name], err = p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return obj, p.advance()
+		}
+	}
+	return nil, p.unexpected("a value")
+}
@@ -0,0 +1,282 @@
+// Protocol buffer definitions of the contract operations exposed over gRPC.
+//
+// The service mirrors the caller and transactor surface served over HTTP by
+// pkg/api, so that non-Go services get a strongly typed interface instead of
+// raw JSON-RPC. The Go code is generated by go generate, with protoc,
+// protoc-gen-go and protoc-gen-go-grpc.
+//
+// UpdateLicenceAmount requires an API key, sent in the authorization metadata
+// as "Bearer <key>".
+//
+// Addresses and hashes are 0x prefixed hex strings, integers that may exceed
+// 64 bits are decimal strings.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: contracts.proto
+
+package grpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Contracts_GetLicence_FullMethodName          = "/tokencard.contracts.v1.Contracts/GetLicence"
+	Contracts_UpdateLicenceAmount_FullMethodName = "/tokencard.contracts.v1.Contracts/UpdateLicenceAmount"
+	Contracts_ListTokens_FullMethodName          = "/tokencard.contracts.v1.Contracts/ListTokens"
+	Contracts_GetToken_FullMethodName            = "/tokencard.contracts.v1.Contracts/GetToken"
+	Contracts_GetRoles_FullMethodName            = "/tokencard.contracts.v1.Contracts/GetRoles"
+)
+
+// ContractsClient is the client API for Contracts service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ContractsClient interface {
+	// GetLicence returns the configuration of the licence contract.
+	GetLicence(ctx context.Context, in *GetLicenceRequest, opts ...grpc.CallOption) (*Licence, error)
+	// UpdateLicenceAmount sends a transaction updating the licence amount, the
+	// configured signer must be the licence DAO.
+	UpdateLicenceAmount(ctx context.Context, in *UpdateLicenceAmountRequest, opts ...grpc.CallOption) (*Transaction, error)
+	// ListTokens returns the addresses of the whitelisted tokens.
+	ListTokens(ctx context.Context, in *ListTokensRequest, opts ...grpc.CallOption) (*ListTokensResponse, error)
+	// GetToken returns the whitelist entry of a token, NOT_FOUND if it is not whitelisted.
+	GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error)
+	// GetRoles returns the controller roles of an address.
+	GetRoles(ctx context.Context, in *GetRolesRequest, opts ...grpc.CallOption) (*Roles, error)
+}
+
+type contractsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContractsClient(cc grpc.ClientConnInterface) ContractsClient {
+	return &contractsClient{cc}
+}
+
+func (c *contractsClient) GetLicence(ctx context.Context, in *GetLicenceRequest, opts ...grpc.CallOption) (*Licence, error) {
+	out := new(Licence)
+	err := c.cc.Invoke(ctx, Contracts_GetLicence_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractsClient) UpdateLicenceAmount(ctx context.Context, in *UpdateLicenceAmountRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, Contracts_UpdateLicenceAmount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractsClient) ListTokens(ctx context.Context, in *ListTokensRequest, opts ...grpc.CallOption) (*ListTokensResponse, error) {
+	out := new(ListTokensResponse)
+	err := c.cc.Invoke(ctx, Contracts_ListTokens_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractsClient) GetToken(ctx context.Context, in *GetTokenRequest, opts ...grpc.CallOption) (*Token, error) {
+	out := new(Token)
+	err := c.cc.Invoke(ctx, Contracts_GetToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractsClient) GetRoles(ctx context.Context, in *GetRolesRequest, opts ...grpc.CallOption) (*Roles, error) {
+	out := new(Roles)
+	err := c.cc.Invoke(ctx, Contracts_GetRoles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContractsServer is the server API for Contracts service.
+// All implementations must embed UnimplementedContractsServer
+// for forward compatibility
+type ContractsServer interface {
+	// GetLicence returns the configuration of the licence contract.
+	GetLicence(context.Context, *GetLicenceRequest) (*Licence, error)
+	// UpdateLicenceAmount sends a transaction updating the licence amount, the
+	// configured signer must be the licence DAO.
+	UpdateLicenceAmount(context.Context, *UpdateLicenceAmountRequest) (*Transaction, error)
+	// ListTokens returns the addresses of the whitelisted tokens.
+	ListTokens(context.Context, *ListTokensRequest) (*ListTokensResponse, error)
+	// GetToken returns the whitelist entry of a token, NOT_FOUND if it is not whitelisted.
+	GetToken(context.Context, *GetTokenRequest) (*Token, error)
+	// GetRoles returns the controller roles of an address.
+	GetRoles(context.Context, *GetRolesRequest) (*Roles, error)
+	mustEmbedUnimplementedContractsServer()
+}
+
+// UnimplementedContractsServer must be embedded to have forward compatible implementations.
+type UnimplementedContractsServer struct {
+}
+
+func (UnimplementedContractsServer) GetLicence(context.Context, *GetLicenceRequest) (*Licence, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLicence not implemented")
+}
+func (UnimplementedContractsServer) UpdateLicenceAmount(context.Context, *UpdateLicenceAmountRequest) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLicenceAmount not implemented")
+}
+func (UnimplementedContractsServer) ListTokens(context.Context, *ListTokensRequest) (*ListTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTokens not implemented")
+}
+func (UnimplementedContractsServer) GetToken(context.Context, *GetTokenRequest) (*Token, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
+}
+func (UnimplementedContractsServer) GetRoles(context.Context, *GetRolesRequest) (*Roles, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoles not implemented")
+}
+func (UnimplementedContractsServer) mustEmbedUnimplementedContractsServer() {}
+
+// UnsafeContractsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContractsServer will
+// result in compilation errors.
+type UnsafeContractsServer interface {
+	mustEmbedUnimplementedContractsServer()
+}
+
+func RegisterContractsServer(s grpc.ServiceRegistrar, srv ContractsServer) {
+	s.RegisterService(&Contracts_ServiceDesc, srv)
+}
+
+func _Contracts_GetLicence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLicenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractsServer).GetLicence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Contracts_GetLicence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractsServer).GetLicence(ctx, req.(*GetLicenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Contracts_UpdateLicenceAmount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLicenceAmountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractsServer).UpdateLicenceAmount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Contracts_UpdateLicenceAmount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractsServer).UpdateLicenceAmount(ctx, req.(*UpdateLicenceAmountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Contracts_ListTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractsServer).ListTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Contracts_ListTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractsServer).ListTokens(ctx, req.(*ListTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Contracts_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractsServer).GetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Contracts_GetToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractsServer).GetToken(ctx, req.(*GetTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Contracts_GetRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractsServer).GetRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Contracts_GetRoles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractsServer).GetRoles(ctx, req.(*GetRolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Contracts_ServiceDesc is the grpc.ServiceDesc for Contracts service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Contracts_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tokencard.contracts.v1.Contracts",
+	HandlerType: (*ContractsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLicence",
+			Handler:    _Contracts_GetLicence_Handler,
+		},
+		{
+			MethodName: "UpdateLicenceAmount",
+			Handler:    _Contracts_UpdateLicenceAmount_Handler,
+		},
+		{
+			MethodName: "ListTokens",
+			Handler:    _Contracts_ListTokens_Handler,
+		},
+		{
+			MethodName: "GetToken",
+			Handler:    _Contracts_GetToken_Handler,
+		},
+		{
+			MethodName: "GetRoles",
+			Handler:    _Contracts_GetRoles_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "contracts.proto",
+}
@@ -0,0 +1,288 @@
+// Package grpc serves the contract operations of package api over gRPC, so
+// that the services which are not written in Go use a strongly typed
+// interface generated from contracts.proto rather than the JSON routes.
+//
+// For example:
+//
+//	s, err := grpc.New(backend, grpc.Config{Licence: licence, TokenWhitelist: whitelist, Controller: controller})
+//	srv := grpc.NewServer(s)
+//	err = srv.Serve(listener)
+//
+// The errors of the node are returned with the Unavailable code, the invalid
+// requests with InvalidArgument and the tokens missing from the whitelist
+// with NotFound.
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative contracts.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	"github.com/tokencard/contracts/v2/pkg/units"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Config holds the configuration of the gRPC service.
+type Config struct {
+	Licence        common.Address
+	TokenWhitelist common.Address
+	Controller     common.Address
+
+	// APIKeys are the bearer tokens accepted by the mutating methods.
+	APIKeys []string
+
+	// TransactOpts sign the transactions sent by the mutating methods. The
+	// mutating methods fail with Unavailable when nil.
+	TransactOpts *bind.TransactOpts
+	// Signer signs the transactions of the mutating methods with the context
+	// of their call, in place of the Signer of TransactOpts, when not nil.
+	Signer session.SignerFunc
+
+	// Writable returns why the mutating methods are refused, e.g. the error
+	// of a readonly.Guard, nil when they are served. They are always served
+	// when nil.
+	Writable func() error
+}
+
+// Service implements ContractsServer.
+type Service struct {
+	UnimplementedContractsServer
+
+	licence        *bindings.Licence
+	tokenWhitelist *bindings.TokenWhitelistCaller
+	controller     *bindings.ControllerCaller
+	session        *session.Session
+	keys           [][]byte
+	writable       func() error
+}
+
+// New creates a new service using backend to interact with the contracts.
+func New(backend bind.ContractBackend, cfg Config) (*Service, error) {
+	licence, err := bindings.NewLicence(cfg.Licence, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding licence contract")
+	}
+	tokenWhitelist, err := bindings.NewTokenWhitelistCaller(cfg.TokenWhitelist, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding token whitelist contract")
+	}
+	controller, err := bindings.NewControllerCaller(cfg.Controller, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding controller contract")
+	}
+
+	s := &Service{
+		licence:        licence,
+		tokenWhitelist: tokenWhitelist,
+		controller:     controller,
+		writable:       cfg.Writable,
+	}
+	if cfg.TransactOpts != nil {
+		s.session = session.New(cfg.TransactOpts, nil)
+		s.session.SignWith(cfg.Signer)
+	}
+	for _, k := range cfg.APIKeys {
+		if k != "" {
+			s.keys = append(s.keys, []byte(k))
+		}
+	}
+	return s, nil
+}
+
+// NewServer returns a gRPC server serving the service.
+func NewServer(s *Service, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	RegisterContractsServer(srv, s)
+	return srv
+}
+
+func callOpts(ctx context.Context) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx}
+}
+
+// GetLicence implements ContractsServer.
+func (s *Service) GetLicence(ctx context.Context, _ *GetLicenceRequest) (*Licence, error) {
+	opts := callOpts(ctx)
+	res := &Licence{}
+
+	amount, err := s.licence.LicenceAmountScaled(opts)
+	if err != nil {
+		return nil, nodeError(err, "getting licence amount")
+	}
+	res.AmountScaled = amount.String()
+
+	address := func(get func(*bind.CallOpts) (common.Address, error), field *string) func() error {
+		return func() error {
+			a, err := get(opts)
+			*field = a.Hex()
+			return err
+		}
+	}
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"crypto float", address(s.licence.CryptoFloat, &res.CryptoFloat)},
+		{"float lock", func() (err error) { res.FloatLocked, err = s.licence.FloatLocked(opts); return }},
+		{"token holder", address(s.licence.TokenHolder, &res.TokenHolder)},
+		{"holder lock", func() (err error) { res.HolderLocked, err = s.licence.HolderLocked(opts); return }},
+		{"licence DAO", address(s.licence.LicenceDAO, &res.LicenceDao)},
+		{"licence DAO lock", func() (err error) { res.LicenceDaoLocked, err = s.licence.LicenceDAOLocked(opts); return }},
+		{"TKN contract", address(s.licence.TknContractAddress, &res.TknContract)},
+		{"TKN contract lock", func() (err error) { res.TknContractLocked, err = s.licence.TknContractAddressLocked(opts); return }},
+	}
+	for _, c := range calls {
+		err = c.call()
+		if err != nil {
+			return nil, nodeError(err, "getting "+c.name)
+		}
+	}
+	return res, nil
+}
+
+// ListTokens implements ContractsServer.
+func (s *Service) ListTokens(ctx context.Context, _ *ListTokensRequest) (*ListTokensResponse, error) {
+	tokens, err := s.tokenWhitelist.TokenAddressArray(callOpts(ctx))
+	if err != nil {
+		return nil, nodeError(err, "getting token addresses")
+	}
+	res := &ListTokensResponse{Addresses: make([]string, len(tokens))}
+	for i, t := range tokens {
+		res.Addresses[i] = t.Hex()
+	}
+	return res, nil
+}
+
+// GetToken implements ContractsServer.
+func (s *Service) GetToken(ctx context.Context, req *GetTokenRequest) (*Token, error) {
+	address, err := parseAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, magnitude, rate, available, loadable, redeemable, lastUpdate, err := s.tokenWhitelist.GetTokenInfo(callOpts(ctx), address)
+	if err != nil {
+		return nil, nodeError(err, "getting token info")
+	}
+	if !available {
+		return nil, status.Errorf(codes.NotFound, "token %s is not whitelisted", address.Hex())
+	}
+
+	return &Token{
+		Address:    address.Hex(),
+		Symbol:     symbol,
+		Magnitude:  magnitude.String(),
+		Rate:       rate.String(),
+		Available:  available,
+		Loadable:   loadable,
+		Redeemable: redeemable,
+		LastUpdate: lastUpdate.String(),
+	}, nil
+}
+
+// GetRoles implements ContractsServer.
+func (s *Service) GetRoles(ctx context.Context, req *GetRolesRequest) (*Roles, error) {
+	address, err := parseAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	opts := callOpts(ctx)
+
+	owner, err := s.controller.Owner(opts)
+	if err != nil {
+		return nil, nodeError(err, "getting controller owner")
+	}
+	isAdmin, err := s.controller.IsAdmin(opts, address)
+	if err != nil {
+		return nil, nodeError(err, "getting admin role")
+	}
+	isController, err := s.controller.IsController(opts, address)
+	if err != nil {
+		return nil, nodeError(err, "getting controller role")
+	}
+
+	return &Roles{
+		Address:    address.Hex(),
+		Owner:      owner == address,
+		Admin:      isAdmin,
+		Controller: isController,
+	}, nil
+}
+
+// UpdateLicenceAmount implements ContractsServer.
+func (s *Service) UpdateLicenceAmount(ctx context.Context, req *UpdateLicenceAmountRequest) (*Transaction, error) {
+	err := s.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := units.ParseUint(req.Amount)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%q is not a valid amount", req.Amount)
+	}
+
+	tx, err := s.licence.UpdateLicenceAmount(s.session.Transact(ctx), amount)
+	if err != nil {
+		return nil, nodeError(err, "updating licence amount")
+	}
+	return &Transaction{TxHash: tx.Hash().Hex()}, nil
+}
+
+// authorize checks that a mutating call carries a valid API key and can be
+// served.
+func (s *Service) authorize(ctx context.Context) error {
+	if !s.valid(ctx) {
+		return status.Error(codes.Unauthenticated, "missing or invalid API key")
+	}
+	if s.session == nil {
+		return status.Error(codes.Unavailable, "no signing account configured")
+	}
+	if s.writable != nil {
+		err := s.writable()
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+	}
+	return nil
+}
+
+// valid tells whether the authorization metadata of the call is a valid
+// "Bearer <key>".
+func (s *Service) valid(ctx context.Context) bool {
+	const prefix = "Bearer "
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		if !strings.HasPrefix(header, prefix) {
+			continue
+		}
+		token := []byte(strings.TrimPrefix(header, prefix))
+		for _, k := range s.keys {
+			if subtle.ConstantTimeCompare(token, k) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseAddress parses the address of a request.
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, "%q is not a valid address", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// nodeError returns the error of a call to the node.
+func nodeError(err error, msg string) error {
+	return status.Error(codes.Unavailable, errors.Wrap(err, msg).Error())
+}
@@ -0,0 +1,69 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ERC20ABI is the subset of the ERC20 ABI holding its Transfer and Approval
+// events, to index a token.
+const ERC20ABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"_from","type":"address"},{"indexed":true,"name":"_to","type":"address"},{"indexed":false,"name":"_value","type":"uint256"}],"name":"Transfer","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"_owner","type":"address"},{"indexed":true,"name":"_spender","type":"address"},{"indexed":false,"name":"_value","type":"uint256"}],"name":"Approval","type":"event"}
+]`
+
+// The topics of the events of ERC20ABI. The ERC721 tokens emit the same
+// Transfer and Approval events, with the token ID as a fourth topic.
+var (
+	TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	ApprovalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+// erc20Parties are the address arguments of the ERC20 events.
+var erc20Parties = map[string][2]string{
+	"Transfer": {"_from", "_to"},
+	"Approval": {"_owner", "_spender"},
+}
+
+// Involving returns a hook keeping only the Transfer and Approval events of
+// the token indexed as contract which involve one of the addresses, e.g. the
+// movements of a token from and to the contracts of the program out of all
+// its transfers. The indexed addresses of the events kept are stored as
+// addresses rather than as their raw topics. The events of the other
+// contracts are kept as they are.
+func Involving(contract string, addresses ...common.Address) Hook {
+	involved := make(map[common.Address]bool, len(addresses))
+	for _, a := range addresses {
+		involved[a] = true
+	}
+	return HookFunc(func(ctx context.Context, e Event) (Event, bool, error) {
+		if e.Contract != contract {
+			return e, true, nil
+		}
+		parties, ok := erc20Parties[e.Name]
+		if !ok {
+			return Event{}, false, nil
+		}
+		args := make(map[string]interface{}, len(e.Args))
+		for k, v := range e.Args {
+			args[k] = v
+		}
+		keep := false
+		for _, name := range parties {
+			topic, ok := args[name].(common.Hash)
+			if !ok {
+				continue
+			}
+			a := common.BytesToAddress(topic.Bytes())
+			args[name] = a
+			keep = keep || involved[a]
+		}
+		if !keep {
+			return Event{}, false, nil
+		}
+		e.Args = args
+		return e, true, nil
+	})
+}
@@ -0,0 +1,199 @@
+// Package indexer follows the logs emitted by the contracts and stores them as
+// decoded events that can be queried without going back to the node.
+package indexer
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Errors of the decoding of the logs.
+var (
+	// ErrAnonymousLog is a log without topics, so without an event ID.
+	ErrAnonymousLog = errors.New("anonymous log")
+	// ErrUnknownEvent is a log whose event ID is not in the ABI.
+	ErrUnknownEvent = errors.New("unknown event")
+	// ErrMalformedLog is a log whose data or topics do not match its event.
+	ErrMalformedLog = errors.New("malformed log")
+)
+
+// Contract is a contract whose events are indexed.
+type Contract struct {
+	Name    string
+	Address common.Address
+	ABI     abi.ABI
+}
+
+// Event is a decoded contract event.
+type Event struct {
+	Contract    string         `json:"contract"`
+	Address     common.Address `json:"address"`
+	Name        string         `json:"name"`
+	BlockNumber uint64         `json:"block_number"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	TxIndex     uint           `json:"tx_index"`
+	LogIndex    uint           `json:"log_index"`
+	Removed     bool           `json:"removed,omitempty"`
+	// Confirmations is the finality level the event was handed at: the
+	// number of blocks mined on top of its block the handler required, see
+	// Finality. It is zero for the events handed as soon as indexed.
+	Confirmations uint64                 `json:"confirmations,omitempty"`
+	Args          map[string]interface{} `json:"args"`
+}
+
+// Position is the position of an event in the chain.
+type Position struct {
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// Before reports whether p precedes o.
+func (p Position) Before(o Position) bool {
+	if p.BlockNumber != o.BlockNumber {
+		return p.BlockNumber < o.BlockNumber
+	}
+	return p.LogIndex < o.LogIndex
+}
+
+// Position returns the position of the event in the chain.
+func (e Event) Position() Position {
+	return Position{BlockNumber: e.BlockNumber, LogIndex: e.LogIndex}
+}
+
+// eventKey identifies an event in a block, which is another block with the
+// same number once reorganized.
+type eventKey struct {
+	block common.Hash
+	index uint
+}
+
+func keyOf(e Event) eventKey {
+	return eventKey{block: e.BlockHash, index: e.LogIndex}
+}
+
+// FormatArg converts a decoded event argument to a value with a stable JSON
+// and string representation. Integers are converted to decimal strings as they
+// may not fit in a JSON number.
+func FormatArg(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case [32]byte:
+		return common.Hash(v).Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	}
+	return v
+}
+
+// AddressArg returns the address argument of the event, decoded or formatted
+// by FormatArg as the FileStore and the SegmentStore return it.
+func AddressArg(e Event, name string) (common.Address, bool) {
+	switch v := e.Args[name].(type) {
+	case common.Address:
+		return v, true
+	case string:
+		if common.IsHexAddress(v) {
+			return common.HexToAddress(v), true
+		}
+	}
+	return common.Address{}, false
+}
+
+// BigArg returns the integer argument of the event, decoded or formatted by
+// FormatArg as the FileStore and the SegmentStore return it.
+func BigArg(e Event, name string) (*big.Int, bool) {
+	switch v := e.Args[name].(type) {
+	case *big.Int:
+		return v, true
+	case string:
+		return new(big.Int).SetString(v, 10)
+	}
+	return nil, false
+}
+
+// Decode returns the name and the arguments of the event emitted in the log.
+// Indexed arguments are returned as their raw topic. The errors wrap
+// ErrAnonymousLog, ErrUnknownEvent or ErrMalformedLog.
+func Decode(parsed abi.ABI, l types.Log) (string, map[string]interface{}, error) {
+	if len(l.Topics) == 0 {
+		return "", nil, errors.Wrapf(ErrAnonymousLog, "log %d in transaction %s", l.Index, l.TxHash.Hex())
+	}
+	event, err := parsed.EventByID(l.Topics[0])
+	if err != nil {
+		return "", nil, errors.Wrapf(ErrUnknownEvent, "log %d in transaction %s: %v", l.Index, l.TxHash.Hex(), err)
+	}
+
+	indexed := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed++
+		}
+	}
+	if len(l.Topics)-1 != indexed {
+		return "", nil, errors.Wrapf(ErrMalformedLog, "%s event with %d indexed arguments, %d topics", event.Name, indexed, len(l.Topics)-1)
+	}
+	values := make(map[string]interface{})
+	err = parsed.UnpackIntoMap(values, event.Name, l.Data)
+	if err != nil {
+		return "", nil, errors.Wrapf(ErrMalformedLog, "decoding %s event: %v", event.Name, err)
+	}
+
+	topics := l.Topics[1:]
+	for _, input := range event.Inputs {
+		if !input.Indexed || len(topics) == 0 {
+			continue
+		}
+		values[input.Name] = topics[0]
+		topics = topics[1:]
+	}
+	return event.Name, values, nil
+}
+
+// NewEvent decodes the log emitted by contract c.
+func NewEvent(c Contract, l types.Log) (Event, error) {
+	name, args, err := Decode(c.ABI, l)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Contract:    c.Name,
+		Address:     l.Address,
+		Name:        name,
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		TxIndex:     l.TxIndex,
+		LogIndex:    l.Index,
+		Removed:     l.Removed,
+		Args:        args,
+	}, nil
+}
+
+// ReceiptEvents decodes the events emitted by contract c in the receipt, e.g.
+// the wallets a WalletDeployer deployed. The logs of other contracts are
+// skipped.
+func ReceiptEvents(c Contract, r *types.Receipt) ([]Event, error) {
+	var events []Event
+	for _, l := range r.Logs {
+		if l.Address != c.Address {
+			continue
+		}
+		e, err := NewEvent(c, *l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// fileRecord is a line of the file of a FileStore. Each write is a sequence
+// of removals and events terminated by the head it advances the store to.
+type fileRecord struct {
+	Event   *Event    `json:"event,omitempty"`
+	Removed *eventRef `json:"removed,omitempty"`
+	Head    *uint64   `json:"head,omitempty"`
+}
+
+// eventRef is a removed event.
+type eventRef struct {
+	BlockHash common.Hash `json:"block_hash"`
+	LogIndex  uint        `json:"log_index"`
+}
+
+// FileStore is a Store persisting the events to a file of JSON lines. The
+// events of an Append are written along with the head they advance the store
+// to, in a single write synced to disk, and only count once their head is:
+// the events of a write torn by a crash are dropped when the file is opened
+// again, so that the blocks are indexed again exactly once.
+//
+// The arguments of the events are stored formatted by FormatArg, the events
+// read back from the file hold the formatted values.
+type FileStore struct {
+	memory *MemoryStore
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenFileStore opens the store in path, which is created if it does not
+// exist, and loads its events.
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening store")
+	}
+	s := &FileStore{memory: NewMemoryStore(), file: f}
+	err = s.load()
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "loading store %s", path)
+	}
+	return s, nil
+}
+
+// load reads the complete writes of the file and truncates a torn one.
+func (s *FileStore) load() error {
+	r := bufio.NewReader(s.file)
+	var offset int64
+	var events []Event
+	var removed []Event
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var rec fileRecord
+		if json.Unmarshal(line, &rec) != nil {
+			// A line torn by a crash, the file is truncated below.
+			break
+		}
+		switch {
+		case rec.Event != nil:
+			events = append(events, *rec.Event)
+		case rec.Removed != nil:
+			removed = append(removed, Event{BlockHash: rec.Removed.BlockHash, LogIndex: rec.Removed.LogIndex})
+		case rec.Head != nil:
+			s.memory.Replace(*rec.Head, removed, events)
+			events, removed = nil, nil
+			s.size = offset + int64(len(line))
+		}
+		offset += int64(len(line))
+	}
+	err := s.file.Truncate(s.size)
+	if err != nil {
+		return errors.Wrap(err, "truncating the last write")
+	}
+	_, err = s.file.Seek(s.size, io.SeekStart)
+	return err
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Head implements Store.
+func (s *FileStore) Head() (uint64, bool) {
+	return s.memory.Head()
+}
+
+// Append implements Store, writing the events and the head at once. The
+// stored events hold the formatted arguments.
+func (s *FileStore) Append(head uint64, events []Event) error {
+	return s.Replace(head, nil, events)
+}
+
+// Remove implements Remover, writing the removals and the current head at
+// once.
+func (s *FileStore) Remove(events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	head, _ := s.memory.Head()
+	err := s.write(append(removals(events), fileRecord{Head: &head}))
+	if err != nil {
+		return err
+	}
+	return s.memory.Remove(events)
+}
+
+// Replace implements Replacer, writing the removals, the added events and
+// the head at once.
+func (s *FileStore) Replace(head uint64, removed, added []Event) error {
+	formatted := make([]Event, len(added))
+	records := removals(removed)
+	for i, e := range added {
+		args := make(map[string]interface{}, len(e.Args))
+		for k, v := range e.Args {
+			args[k] = FormatArg(v)
+		}
+		e.Args = args
+		formatted[i] = e
+		records = append(records, fileRecord{Event: &formatted[i]})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.write(append(records, fileRecord{Head: &head}))
+	if err != nil {
+		return err
+	}
+	return s.memory.Replace(head, removed, formatted)
+}
+
+// removals returns the records of the removed events.
+func removals(events []Event) []fileRecord {
+	records := make([]fileRecord, 0, len(events))
+	for _, e := range events {
+		records = append(records, fileRecord{Removed: &eventRef{BlockHash: e.BlockHash, LogIndex: e.LogIndex}})
+	}
+	return records
+}
+
+// Events implements Store.
+func (s *FileStore) Events(q Query) ([]Event, error) {
+	return s.memory.Events(q)
+}
+
+// write appends the records to the file and syncs it, s.mu must be held. A
+// failed write is truncated.
+func (s *FileStore) write(records []fileRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		err := enc.Encode(r)
+		if err != nil {
+			return errors.Wrap(err, "encoding events")
+		}
+	}
+	_, err := s.file.Write(buf.Bytes())
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		s.file.Truncate(s.size)
+		s.file.Seek(s.size, io.SeekStart)
+		return errors.Wrap(err, "writing store")
+	}
+	s.size += int64(buf.Len())
+	return nil
+}
@@ -0,0 +1,146 @@
+package indexer
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Finality is implemented by the handlers requiring the events to be buried
+// under blocks before they are handled, such as accounting, while alerting
+// handles them as soon as they are indexed.
+type Finality interface {
+	// Confirmations returns the finality levels of the handler: the numbers
+	// of blocks which must be mined on top of the block of an event for it
+	// to be handled. The handler receives the events once at each level,
+	// with their Confirmations set to the level. Zero is the level of the
+	// events handed as soon as indexed.
+	Confirmations() []uint64
+}
+
+// WithConfirmations returns a handler handing the events to h at the given
+// finality levels, see Finality.
+func WithConfirmations(h Handler, confirmations ...uint64) Handler {
+	return &confirmed{Handler: h, levels: confirmations}
+}
+
+type confirmed struct {
+	Handler
+	levels []uint64
+}
+
+func (c *confirmed) Confirmations() []uint64 {
+	return c.levels
+}
+
+// levels returns the finality levels of a handler.
+func levels(h Handler) []uint64 {
+	if f, ok := h.(Finality); ok {
+		return f.Confirmations()
+	}
+	return []uint64{0}
+}
+
+// startLevels records the first block to hand at the levels of the
+// handlers which were not handed any block yet, before a synchronisation.
+func (i *Indexer) startLevels() {
+	for _, h := range i.Handlers {
+		for _, l := range levels(h) {
+			if _, ok := i.levels[l]; !ok && l > 0 {
+				i.levels[l] = i.firstBlock(l)
+			}
+		}
+	}
+}
+
+// firstBlock returns the first block to hand at level l. The blocks indexed
+// before are taken as handed, except those which have not reached the level
+// yet: the events indexed by a previous run are not handed again.
+func (i *Indexer) firstBlock(l uint64) uint64 {
+	next := i.StartBlock
+	last, ok := i.store.Head()
+	if ok && last+1 > l && last+1-l > next {
+		next = last + 1 - l
+	}
+	return next
+}
+
+// handle hands the events indexed by a synchronisation to the handlers, the
+// removed events first. The handlers at level zero receive them as they
+// are. The other levels receive the removed and replaced events of the
+// blocks they were already handed, then the stored events of the blocks
+// which reached the level since the last synchronisation.
+func (i *Indexer) handle(ctx context.Context, events []Event) error {
+	head, indexed := i.store.Head()
+	byLevel := make(map[uint64][]Handler)
+	for _, h := range i.Handlers {
+		for _, l := range levels(h) {
+			byLevel[l] = append(byLevel[l], h)
+		}
+	}
+	sorted := make([]uint64, 0, len(byLevel))
+	for l := range byLevel {
+		sorted = append(sorted, l)
+	}
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+
+	for _, l := range sorted {
+		level := events
+		if l > 0 {
+			var err error
+			level, err = i.confirmed(l, head, indexed, events)
+			if err != nil {
+				return err
+			}
+		}
+		if len(level) == 0 {
+			continue
+		}
+		for _, h := range byLevel[l] {
+			err := h.HandleEvents(ctx, level)
+			if err != nil {
+				return errors.Wrap(err, "handling events")
+			}
+		}
+	}
+	return nil
+}
+
+// confirmed returns the events to hand at level l, labelled with it, and
+// records that the blocks up to head minus l were handed at this level.
+func (i *Indexer) confirmed(l, head uint64, indexed bool, events []Event) ([]Event, error) {
+	if !indexed {
+		return nil, nil
+	}
+	next, ok := i.levels[l]
+	if !ok {
+		next = i.firstBlock(l)
+	}
+
+	var level []Event
+	for _, e := range events {
+		if e.BlockNumber < next {
+			e.Confirmations = l
+			level = append(level, e)
+		}
+	}
+	if head >= l && head-l >= next {
+		stored, err := i.store.Events(Query{FromBlock: next, ToBlock: head - l})
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading events of %d confirmations", l)
+		}
+		for _, e := range stored {
+			// A ToBlock of 0 does not bound the query, the head only
+			// reached the level of block 0.
+			if e.BlockNumber > head-l {
+				continue
+			}
+			e.Confirmations = l
+			level = append(level, e)
+		}
+		next = head - l + 1
+	}
+	i.levels[l] = next
+	return level, nil
+}
@@ -0,0 +1,250 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Defaults of a HeadFeed.
+const (
+	// DefaultHeadDepth is the number of head blocks remembered to undo their
+	// events when they are reorganized.
+	DefaultHeadDepth = 16
+	// DefaultHeadBackoff is the delay before subscribing to the heads again
+	// once the subscription failed.
+	DefaultHeadBackoff = time.Second
+)
+
+// HeadBackend is the subset of the node API used by a HeadFeed, which needs a
+// websocket or IPC connection to subscribe to the heads.
+type HeadBackend interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// HeadFeed hands the events of each new head block to its handlers as soon
+// as the node announces the block, without storing them. It is the fast path
+// of the consumers for which latency matters more than durability, such as
+// alerting: an Indexer following the same contracts stores the events of the
+// same blocks behind it.
+//
+// The events of the head blocks replaced by a reorganization are handed
+// again with their Removed flag set, latest first, before the events of the
+// new head. Only the last Depth heads are remembered, the deeper
+// reorganizations are left to the Indexer. The blocks skipped between two
+// heads, while the subscription was down, are filtered along with the new
+// head when they are not deeper than Depth either.
+type HeadFeed struct {
+	backend   HeadBackend
+	contracts map[common.Address]Contract
+
+	// Handlers are called with the events of each head, in chain order. A
+	// failing handler is logged, the events are not handed again.
+	Handlers []Handler
+	// Depth is the number of head blocks remembered to follow the
+	// reorganizations.
+	Depth uint64
+	// Backoff is the delay before subscribing again in Run.
+	Backoff time.Duration
+	// Logger receives the reorganizations and the failures of the feed.
+	Logger logging.Logger
+
+	// recent are the last head blocks handed, by increasing number.
+	recent []headBlock
+}
+
+// headBlock is a head block handed by a HeadFeed.
+type headBlock struct {
+	number uint64
+	hash   common.Hash
+	events []Event
+}
+
+// NewHeadFeed creates a feed of the events the given contracts emit in the
+// head blocks.
+func NewHeadFeed(backend HeadBackend, contracts ...Contract) *HeadFeed {
+	cs := make(map[common.Address]Contract, len(contracts))
+	for _, c := range contracts {
+		cs[c.Address] = c
+	}
+	return &HeadFeed{
+		backend:   backend,
+		contracts: cs,
+		Depth:     DefaultHeadDepth,
+		Backoff:   DefaultHeadBackoff,
+		Logger:    logging.Nop,
+	}
+}
+
+// Head hands the events of a new head block to the handlers, after the
+// removal of the events of the remembered blocks it replaces.
+func (f *HeadFeed) Head(ctx context.Context, h *types.Header) error {
+	number := h.Number.Uint64()
+	removed := f.replaced(number, h.ParentHash)
+
+	// The blocks skipped since the last head are filtered by number, the
+	// head by hash in case the node already moved to another branch.
+	var skipped []Event
+	if n := len(f.recent); n > 0 && f.recent[n-1].number+1 < number {
+		from := f.recent[n-1].number + 1
+		if number-from > f.Depth {
+			from = number - f.Depth
+		}
+		var err error
+		skipped, err = f.filter(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(number - 1),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "filtering logs of blocks %d to %d", from, number-1)
+		}
+	}
+	hash := h.Hash()
+	events, err := f.filter(ctx, ethereum.FilterQuery{BlockHash: &hash})
+	if err != nil {
+		return errors.Wrapf(err, "filtering logs of block %d", number)
+	}
+
+	f.remember(skipped)
+	f.recent = append(f.recent, headBlock{number: number, hash: hash, events: events})
+	if uint64(len(f.recent)) > f.Depth {
+		f.recent = f.recent[uint64(len(f.recent))-f.Depth:]
+	}
+
+	handed := make([]Event, 0, len(removed)+len(skipped)+len(events))
+	handed = append(append(append(handed, removed...), skipped...), events...)
+	if len(handed) == 0 {
+		return nil
+	}
+	for _, handler := range f.Handlers {
+		err := handler.HandleEvents(ctx, handed)
+		if err != nil {
+			f.logger().Error("Handling head events failed", "block", number, "err", err)
+		}
+	}
+	return nil
+}
+
+// replaced forgets the remembered blocks a head of the given number and
+// parent replaces, and returns their events flagged as removed, latest
+// first.
+func (f *HeadFeed) replaced(number uint64, parent common.Hash) []Event {
+	keep := len(f.recent)
+	for keep > 0 && f.recent[keep-1].number >= number {
+		keep--
+	}
+	if keep > 0 && f.recent[keep-1].number+1 == number && f.recent[keep-1].hash != parent {
+		keep--
+	}
+	var removed []Event
+	for n := len(f.recent) - 1; n >= keep; n-- {
+		events := f.recent[n].events
+		for i := len(events) - 1; i >= 0; i-- {
+			e := events[i]
+			e.Removed = true
+			removed = append(removed, e)
+		}
+	}
+	if keep < len(f.recent) {
+		f.logger().Warn("Head reorganized", "from", f.recent[keep].number, "events", len(removed))
+	}
+	f.recent = f.recent[:keep]
+	return removed
+}
+
+// remember records the events of the skipped blocks under their blocks. The
+// skipped blocks without events are not remembered, they have none to undo.
+func (f *HeadFeed) remember(events []Event) {
+	for _, e := range events {
+		n := len(f.recent)
+		if n == 0 || f.recent[n-1].hash != e.BlockHash {
+			f.recent = append(f.recent, headBlock{number: e.BlockNumber, hash: e.BlockHash})
+			n++
+		}
+		f.recent[n-1].events = append(f.recent[n-1].events, e)
+	}
+}
+
+// filter returns the events of the logs matching q, in chain order.
+func (f *HeadFeed) filter(ctx context.Context, q ethereum.FilterQuery) ([]Event, error) {
+	q.Addresses = make([]common.Address, 0, len(f.contracts))
+	for a := range f.contracts {
+		q.Addresses = append(q.Addresses, a)
+	}
+	logs, err := f.backend.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		e, err := NewEvent(f.contracts[l.Address], l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].Position().Before(events[b].Position())
+	})
+	return events, nil
+}
+
+// Run subscribes to the heads and hands their events until the context is
+// cancelled. The failures are logged, the subscription is made again after
+// Backoff once it fails.
+func (f *HeadFeed) Run(ctx context.Context) error {
+	backoff := f.Backoff
+	if backoff <= 0 {
+		backoff = DefaultHeadBackoff
+	}
+	for {
+		err := f.follow(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		f.logger().Error("Head subscription failed", "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// follow hands the events of the heads of a subscription until it fails.
+func (f *HeadFeed) follow(ctx context.Context) error {
+	heads := make(chan *types.Header, 16)
+	sub, err := f.backend.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return errors.Wrap(err, "subscribing to new heads")
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case h := <-heads:
+			err := f.Head(ctx, h)
+			if err != nil && ctx.Err() == nil {
+				f.logger().Error("Following head failed", "block", h.Number, "err", err)
+			}
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *HeadFeed) logger() logging.Logger {
+	return logging.Or(f.Logger)
+}
@@ -0,0 +1,368 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Backend is the subset of the node API used by the indexer.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Handler is notified of the events stored by an Indexer.
+type Handler interface {
+	HandleEvents(ctx context.Context, events []Event) error
+}
+
+// HandlerFunc adapts a function to the Handler interface.
+type HandlerFunc func(ctx context.Context, events []Event) error
+
+// HandleEvents implements Handler.
+func (f HandlerFunc) HandleEvents(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// Hook transforms the events before they are stored. It returns the event to
+// store, which may be modified, and false to drop the event. Hooks must not
+// keep references to the arguments of the events they receive.
+//
+// Hooks are Go code run in the process of the indexer, they are not
+// sandboxed and must be trusted. The indexer only recovers their panics and
+// bounds their time with HookTimeout.
+type Hook interface {
+	Transform(ctx context.Context, e Event) (Event, bool, error)
+}
+
+// HookFunc adapts a function to the Hook interface.
+type HookFunc func(ctx context.Context, e Event) (Event, bool, error)
+
+// Transform implements Hook.
+func (f HookFunc) Transform(ctx context.Context, e Event) (Event, bool, error) {
+	return f(ctx, e)
+}
+
+// Indexer copies the events of a set of contracts into a Store.
+type Indexer struct {
+	backend   Backend
+	store     Store
+	contracts map[common.Address]Contract
+
+	// StartBlock is the first block indexed when the store is empty.
+	StartBlock uint64
+	// ReorgDepth is the number of indexed blocks filtered again by each
+	// synchronisation to follow the reorganizations of the chain. The events
+	// of these blocks which are no longer returned are removed from the
+	// store, which must implement Replacer, and handled again with their
+	// Removed flag set so that the handlers undo them. Zero never filters a
+	// block twice.
+	ReorgDepth uint64
+	// PollInterval is the delay between two synchronisations in Run.
+	PollInterval time.Duration
+	// Hooks are applied in order to each new event before it is stored. A
+	// failing hook fails the synchronisation, which is retried from the
+	// same block.
+	Hooks []Hook
+	// HookTimeout bounds the time each hook takes on an event, the hook
+	// failing once it runs out. A hook ignoring the cancellation of its
+	// context is left running in the background. Unbounded when zero.
+	HookTimeout time.Duration
+	// Handlers are called with the new events once they are stored, or once
+	// they reached the finality levels of the handlers implementing
+	// Finality. Events are not handled again when a handler fails.
+	Handlers []Handler
+	// Logger receives the progress and the failures of the synchronisations.
+	Logger logging.Logger
+
+	// levels are the first blocks not handed yet at each finality level.
+	levels map[uint64]uint64
+}
+
+// New creates a new indexer following the given contracts.
+func New(backend Backend, store Store, contracts ...Contract) *Indexer {
+	cs := make(map[common.Address]Contract, len(contracts))
+	for _, c := range contracts {
+		cs[c.Address] = c
+	}
+	return &Indexer{
+		backend:      backend,
+		store:        store,
+		contracts:    cs,
+		PollInterval: 15 * time.Second,
+		Logger:       logging.Nop,
+		levels:       make(map[uint64]uint64),
+	}
+}
+
+// Store returns the store the events are indexed into.
+func (i *Indexer) Store() Store {
+	return i.store
+}
+
+// Sync indexes the events emitted since the last indexed block up to the
+// current head of the chain.
+func (i *Indexer) Sync(ctx context.Context) error {
+	head, err := i.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting latest block")
+	}
+	to := head.Number.Uint64()
+	i.startLevels()
+
+	from := i.StartBlock
+	last, indexed := i.store.Head()
+	if indexed {
+		from = last + 1
+	}
+	if indexed && i.ReorgDepth > 0 {
+		return i.resync(ctx, from, to)
+	}
+	if from > to {
+		return nil
+	}
+
+	events, err := i.filter(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	events, err = i.transformAll(ctx, events)
+	if err != nil {
+		return err
+	}
+
+	err = i.store.Append(to, events)
+	if err != nil {
+		return errors.Wrap(err, "storing events")
+	}
+	i.logger().Debug("Indexed blocks", "from", from, "to", to, "events", len(events))
+	return i.handle(ctx, events)
+}
+
+// resync indexes the blocks from the next one to the head like Sync,
+// filtering the last ReorgDepth indexed blocks again to remove the events of
+// the blocks reorganized since. A head below the indexed blocks is taken for
+// a lagging node, the blocks above it are left as indexed.
+func (i *Indexer) resync(ctx context.Context, next, to uint64) error {
+	head := to
+	if next-1 > head {
+		head = next - 1
+	}
+	from := i.StartBlock
+	if next > i.ReorgDepth && next-i.ReorgDepth > from {
+		from = next - i.ReorgDepth
+	}
+	replacer, ok := i.store.(Replacer)
+	if !ok {
+		return errors.New("following reorganizations requires a store replacing events")
+	}
+
+	stored, err := i.store.Events(Query{FromBlock: from, ToBlock: to})
+	if err != nil {
+		return errors.Wrap(err, "reading indexed events")
+	}
+	var events []Event
+	if from <= to {
+		events, err = i.filter(ctx, from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	filtered := make(map[eventKey]bool, len(events))
+	for _, e := range events {
+		filtered[keyOf(e)] = true
+	}
+	indexed := make(map[eventKey]bool, len(stored))
+	var removed []Event
+	for _, e := range stored {
+		indexed[keyOf(e)] = true
+		if !filtered[keyOf(e)] {
+			removed = append(removed, e)
+		}
+	}
+	var added []Event
+	for _, e := range events {
+		if !indexed[keyOf(e)] {
+			added = append(added, e)
+		}
+	}
+	added, err = i.transformAll(ctx, added)
+	if err != nil {
+		return err
+	}
+
+	// The removals are stored along with the events replacing them, a
+	// crash cannot leave the store in between.
+	err = replacer.Replace(head, removed, added)
+	if err != nil {
+		return errors.Wrap(err, "storing events")
+	}
+	if len(removed) > 0 {
+		i.logger().Warn("Chain reorganized, events removed", "from", removed[0].BlockNumber, "events", len(removed))
+	}
+	i.logger().Debug("Indexed blocks", "from", next, "to", head, "events", len(added))
+
+	// The removed events are undone latest first, before the events of the
+	// blocks replacing them are handled.
+	compensations := make([]Event, 0, len(removed)+len(added))
+	for n := len(removed) - 1; n >= 0; n-- {
+		e := removed[n]
+		e.Removed = true
+		compensations = append(compensations, e)
+	}
+	return i.handle(ctx, append(compensations, added...))
+}
+
+// filter returns the events emitted in the blocks from and to, in chain
+// order. The logs flagged as removed by the node are skipped.
+func (i *Indexer) filter(ctx context.Context, from, to uint64) ([]Event, error) {
+	addresses := make([]common.Address, 0, len(i.contracts))
+	for a := range i.contracts {
+		addresses = append(addresses, a)
+	}
+
+	logs, err := i.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: addresses,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "filtering logs of blocks %d to %d", from, to)
+	}
+
+	events := make([]Event, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		e, err := NewEvent(i.contracts[l.Address], l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].Position().Before(events[b].Position())
+	})
+	return events, nil
+}
+
+// transformAll applies the hooks to the events, dropping those a hook drops.
+func (i *Indexer) transformAll(ctx context.Context, events []Event) ([]Event, error) {
+	kept := events[:0]
+	for _, e := range events {
+		e, keep, err := i.transform(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
+
+// Lag returns the number of blocks of the chain not indexed yet.
+func (i *Indexer) Lag(ctx context.Context) (uint64, error) {
+	head, err := i.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting latest block")
+	}
+	to := head.Number.Uint64()
+
+	last, ok := i.store.Head()
+	if !ok {
+		if i.StartBlock > to {
+			return 0, nil
+		}
+		return to - i.StartBlock + 1, nil
+	}
+	if last >= to {
+		return 0, nil
+	}
+	return to - last, nil
+}
+
+// transform applies the hooks to an event.
+func (i *Indexer) transform(ctx context.Context, e Event) (Event, bool, error) {
+	for n, h := range i.Hooks {
+		out, keep, err := i.runHook(ctx, h, e)
+		if err != nil {
+			return Event{}, false, errors.Wrapf(err, "hook %d on %s event in transaction %s", n, e.Name, e.TxHash.Hex())
+		}
+		if !keep {
+			return Event{}, false, nil
+		}
+		e = out
+	}
+	return e, true, nil
+}
+
+// Run synchronises the store every PollInterval until the context is cancelled.
+// Failed synchronisations are logged and retried.
+func (i *Indexer) Run(ctx context.Context) error {
+	t := time.NewTicker(i.PollInterval)
+	defer t.Stop()
+	for {
+		err := i.Sync(ctx)
+		if err != nil && ctx.Err() == nil {
+			i.logger().Error("Indexing failed", "err", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (i *Indexer) logger() logging.Logger {
+	return logging.Or(i.Logger)
+}
+
+// hookResult is the outcome of a hook on an event.
+type hookResult struct {
+	e    Event
+	keep bool
+	err  error
+}
+
+// runHook applies a hook to an event, failing when it panics or does not
+// return within HookTimeout.
+func (i *Indexer) runHook(ctx context.Context, h Hook, e Event) (Event, bool, error) {
+	call := func(ctx context.Context) (r hookResult) {
+		defer func() {
+			if p := recover(); p != nil {
+				r = hookResult{err: errors.Errorf("panic: %v", p)}
+			}
+		}()
+		r.e, r.keep, r.err = h.Transform(ctx, e)
+		return r
+	}
+	if i.HookTimeout <= 0 {
+		r := call(ctx)
+		return r.e, r.keep, r.err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.HookTimeout)
+	defer cancel()
+	done := make(chan hookResult, 1)
+	go func() {
+		done <- call(ctx)
+	}()
+	select {
+	case r := <-done:
+		return r.e, r.keep, r.err
+	case <-ctx.Done():
+		return Event{}, false, errors.Wrapf(ctx.Err(), "timed out after %s", i.HookTimeout)
+	}
+}
@@ -0,0 +1,146 @@
+package indexer
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Legacy receives a copy of the events stored by a Mirror, for the systems
+// reading the events of a previous indexer during a migration.
+type Legacy interface {
+	// Write records the events of the blocks up to and including head. The
+	// events removed by a reorganization are written again with their
+	// Removed flag set.
+	Write(head uint64, events []Event) error
+}
+
+// Divergence is reported by a Mirror when the legacy system missed events
+// stored in the new store.
+type Divergence struct {
+	// Head is the last block stored in the new store.
+	Head uint64
+	// Behind is the number of stored events not written to the legacy
+	// system yet, zero once it caught up again.
+	Behind int
+	// Err is the failure of the last write, nil once it caught up.
+	Err error
+}
+
+// Mirror is a Store writing the events appended to another store to a legacy
+// system as well. The store stays the reference: the events are stored before
+// they are written to the legacy system, whose failures do not fail the
+// store. The events it missed are written again with the next events, and the
+// divergence is reported until it caught up.
+type Mirror struct {
+	Store
+	legacy Legacy
+
+	// MaxBehind is the number of events kept for the legacy system while
+	// it fails, the oldest are dropped beyond it and never written. Zero
+	// keeps them all.
+	MaxBehind int
+	// Report is called when a write to the legacy system fails, and once
+	// when it caught up again.
+	Report func(Divergence)
+
+	mu      sync.Mutex
+	pending []Event
+	dropped int
+	failing bool
+}
+
+// NewMirror returns a store appending the events to store and writing them to
+// legacy.
+func NewMirror(store Store, legacy Legacy) *Mirror {
+	return &Mirror{Store: store, legacy: legacy}
+}
+
+// Append implements Store.
+func (m *Mirror) Append(head uint64, events []Event) error {
+	err := m.Store.Append(head, events)
+	if err != nil {
+		return err
+	}
+	m.write(head, events)
+	return nil
+}
+
+// Remove implements Remover when the mirrored store does, writing the events
+// removed to the legacy system with their Removed flag set.
+func (m *Mirror) Remove(events []Event) error {
+	remover, ok := m.Store.(Remover)
+	if !ok {
+		return errors.New("the mirrored store does not remove events")
+	}
+	err := remover.Remove(events)
+	if err != nil {
+		return err
+	}
+	head, _ := m.Store.Head()
+	removed := make([]Event, len(events))
+	for n, e := range events {
+		e.Removed = true
+		removed[n] = e
+	}
+	m.write(head, removed)
+	return nil
+}
+
+// Replace implements Replacer when the mirrored store does, writing the
+// events removed to the legacy system with their Removed flag set before the
+// events added.
+func (m *Mirror) Replace(head uint64, removed, added []Event) error {
+	replacer, ok := m.Store.(Replacer)
+	if !ok {
+		return errors.New("the mirrored store does not replace events")
+	}
+	err := replacer.Replace(head, removed, added)
+	if err != nil {
+		return err
+	}
+	events := make([]Event, 0, len(removed)+len(added))
+	for _, e := range removed {
+		e.Removed = true
+		events = append(events, e)
+	}
+	m.write(head, append(events, added...))
+	return nil
+}
+
+// Divergence returns the divergence of the legacy system from the store.
+func (m *Mirror) Divergence() Divergence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	head, _ := m.Store.Head()
+	return Divergence{Head: head, Behind: len(m.pending) + m.dropped}
+}
+
+func (m *Mirror) write(head uint64, events []Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending = append(m.pending, events...)
+	err := m.legacy.Write(head, m.pending)
+	if err == nil {
+		m.pending = nil
+		if m.failing {
+			m.failing = false
+			m.dropped = 0
+			m.report(Divergence{Head: head})
+		}
+		return
+	}
+	if m.MaxBehind > 0 && len(m.pending) > m.MaxBehind {
+		drop := len(m.pending) - m.MaxBehind
+		m.pending = append([]Event(nil), m.pending[drop:]...)
+		m.dropped += drop
+	}
+	m.failing = true
+	m.report(Divergence{Head: head, Behind: len(m.pending) + m.dropped, Err: errors.Wrap(err, "writing to legacy system")})
+}
+
+func (m *Mirror) report(d Divergence) {
+	if m.Report != nil {
+		m.Report(d)
+	}
+}
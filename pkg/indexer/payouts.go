@@ -0,0 +1,102 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// PayoutEvent is the event of the Licence paying the licence fee of a load to
+// the token holder.
+const PayoutEvent = "TransferredToTokenHolder"
+
+// TransferArg is the argument of the payouts of ERC20 tokens holding the
+// index of the Transfer log which moved the fee, in the transaction of the
+// payout.
+const TransferArg = "_transfer_log_index"
+
+// ReceiptReader reads the receipts of the transactions, *ethclient.Client
+// implements it.
+type ReceiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// LinkPayouts returns a hook linking each payout of an ERC20 token by the
+// Licence indexed as contract to the Transfer log of the token paying the fee
+// to the token holder in the same transaction, stored in the TransferArg of
+// the payout. The payouts of ether and those without a matching Transfer log
+// are stored unlinked. The other events are kept as they are.
+func LinkPayouts(contract string, receipts ReceiptReader) Hook {
+	return HookFunc(func(ctx context.Context, e Event) (Event, bool, error) {
+		if e.Contract != contract || e.Name != PayoutEvent || e.Removed {
+			return e, true, nil
+		}
+		asset, _ := AddressArg(e, "_asset")
+		if asset == (common.Address{}) {
+			return e, true, nil
+		}
+		r, err := receipts.TransactionReceipt(ctx, e.TxHash)
+		if err != nil {
+			return Event{}, false, errors.Wrapf(err, "getting receipt of transaction %s", e.TxHash.Hex())
+		}
+		if r == nil || r.BlockHash != e.BlockHash {
+			// The block was reorganised since it was filtered, the
+			// synchronisation is retried.
+			return Event{}, false, errors.Errorf("transaction %s is no longer in block %s", e.TxHash.Hex(), e.BlockHash.Hex())
+		}
+		index, ok := payoutTransfer(r, e, asset)
+		if !ok {
+			return e, true, nil
+		}
+		args := make(map[string]interface{}, len(e.Args)+1)
+		for k, v := range e.Args {
+			args[k] = v
+		}
+		args[TransferArg] = new(big.Int).SetUint64(uint64(index))
+		e.Args = args
+		return e, true, nil
+	})
+}
+
+// payoutTransfer returns the index of the first Transfer log of the asset
+// before the payout moving its amount from the loader to the token holder.
+func payoutTransfer(r *types.Receipt, e Event, asset common.Address) (uint, bool) {
+	from, _ := AddressArg(e, "_from")
+	to, _ := AddressArg(e, "_to")
+	amount, _ := BigArg(e, "_amount")
+	if amount == nil {
+		return 0, false
+	}
+	for _, l := range r.Logs {
+		if l.Index >= e.LogIndex {
+			break
+		}
+		if l.Address != asset || len(l.Topics) != 3 || l.Topics[0] != TransferTopic {
+			continue
+		}
+		if common.BytesToAddress(l.Topics[1].Bytes()) == from && common.BytesToAddress(l.Topics[2].Bytes()) == to && new(big.Int).SetBytes(l.Data).Cmp(amount) == 0 {
+			return l.Index, true
+		}
+	}
+	return 0, false
+}
+
+// PayoutTransfer returns the index of the Transfer log linked to a payout by
+// LinkPayouts, in the transaction of the payout, and false when the payout is
+// not linked. It reads the payouts as they were indexed and as they are read
+// back from a FileStore or a SegmentStore.
+func PayoutTransfer(e Event) (uint, bool) {
+	switch v := e.Args[TransferArg].(type) {
+	case *big.Int:
+		return uint(v.Uint64()), true
+	case string:
+		i, ok := new(big.Int).SetString(v, 10)
+		if ok {
+			return uint(i.Uint64()), true
+		}
+	}
+	return 0, false
+}
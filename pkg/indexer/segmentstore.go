@@ -0,0 +1,399 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// segmentLog is the name of the commit log of a SegmentStore.
+const segmentLog = "_log.jsonl"
+
+// segment is an immutable file of events of a SegmentStore, along with the
+// statistics pruning the segments a query does not need to read.
+type segment struct {
+	// Seq is the position of the segment in the log.
+	Seq       int    `json:"seq"`
+	File      string `json:"file"`
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	// Names are the events of the segment, as contract.name.
+	Names []string `json:"names"`
+}
+
+// segmentCommit is a line of the log of a SegmentStore.
+type segmentCommit struct {
+	Head    uint64     `json:"head"`
+	Segment *segment   `json:"segment,omitempty"`
+	Removed []eventRef `json:"removed,omitempty"`
+	// Compacted replaces all the previous segments and removals by the
+	// segment of the commit.
+	Compacted bool `json:"compacted,omitempty"`
+}
+
+// SegmentStore is a Store persisting the events to a directory without any
+// database, for the deployments which do not run one. Each Append writes its
+// events to a new immutable segment file, which only counts once committed
+// by a line of the log of the directory: the segments of a write torn by a
+// crash are left out and deleted when the store is opened again.
+//
+// Only the log is kept in memory. A query reads the segments whose block
+// range and events may match it, the others are pruned. The removals are
+// committed as tombstones, Compact rewrites the events left in a single
+// segment.
+//
+// The arguments of the events are stored formatted by FormatArg, the events
+// read back hold the formatted values.
+type SegmentStore struct {
+	dir string
+
+	mu       sync.RWMutex
+	log      *os.File
+	size     int64
+	seq      int
+	head     uint64
+	indexed  bool
+	segments []segment
+	// removed are the log positions of the removals of the events, which
+	// hide the events of the segments committed before them.
+	removed map[eventKey]int
+}
+
+// OpenSegmentStore opens the store of the directory dir, which is created if
+// it does not exist, and loads its log.
+func OpenSegmentStore(dir string) (*SegmentStore, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating store directory")
+	}
+	f, err := os.OpenFile(filepath.Join(dir, segmentLog), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening store log")
+	}
+	s := &SegmentStore{dir: dir, log: f, removed: make(map[eventKey]int)}
+	err = s.load()
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "loading store %s", dir)
+	}
+	return s, nil
+}
+
+// load replays the log, truncates a torn commit and deletes the segments not
+// committed.
+func (s *SegmentStore) load() error {
+	r := bufio.NewReader(s.log)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var c segmentCommit
+		if json.Unmarshal(line, &c) != nil {
+			// A line torn by a crash, the log is truncated below.
+			break
+		}
+		s.apply(c)
+		s.size += int64(len(line))
+	}
+	err := s.log.Truncate(s.size)
+	if err != nil {
+		return errors.Wrap(err, "truncating the last commit")
+	}
+	_, err = s.log.Seek(s.size, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	committed := make(map[string]bool, len(s.segments))
+	for _, seg := range s.segments {
+		committed[seg.File] = true
+	}
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "segment-") && !committed[f.Name()] {
+			os.Remove(filepath.Join(s.dir, f.Name()))
+		}
+	}
+	return nil
+}
+
+// apply applies a commit to the state of the store, s.mu must be held.
+func (s *SegmentStore) apply(c segmentCommit) {
+	s.seq++
+	if c.Compacted {
+		s.segments = nil
+		s.removed = make(map[eventKey]int)
+	}
+	if c.Segment != nil {
+		s.segments = append(s.segments, *c.Segment)
+	}
+	for _, r := range c.Removed {
+		s.removed[eventKey{block: r.BlockHash, index: r.LogIndex}] = s.seq
+	}
+	s.head = c.Head
+	s.indexed = true
+}
+
+// Close closes the log.
+func (s *SegmentStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.log.Close()
+}
+
+// Head implements Store.
+func (s *SegmentStore) Head() (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.head, s.indexed
+}
+
+// Append implements Store, writing the events to a new segment committed
+// along with the head.
+func (s *SegmentStore) Append(head uint64, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := segmentCommit{Head: head}
+	if len(events) > 0 {
+		seg, err := s.writeSegment(events)
+		if err != nil {
+			return err
+		}
+		c.Segment = seg
+	}
+	return s.commit(c)
+}
+
+// Remove implements Remover, committing the tombstones of the events.
+func (s *SegmentStore) Remove(events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := segmentCommit{Head: s.head}
+	for _, e := range events {
+		c.Removed = append(c.Removed, eventRef{BlockHash: e.BlockHash, LogIndex: e.LogIndex})
+	}
+	return s.commit(c)
+}
+
+// Replace implements Replacer, committing the tombstones of the removed
+// events along with the segment of the added events and the head.
+func (s *SegmentStore) Replace(head uint64, removed, added []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := segmentCommit{Head: head}
+	for _, e := range removed {
+		c.Removed = append(c.Removed, eventRef{BlockHash: e.BlockHash, LogIndex: e.LogIndex})
+	}
+	if len(added) > 0 {
+		seg, err := s.writeSegment(added)
+		if err != nil {
+			return err
+		}
+		c.Segment = seg
+	}
+	return s.commit(c)
+}
+
+// Compact rewrites the events left in the store to a single segment, which
+// drops the removed events, and deletes the previous segments.
+func (s *SegmentStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events, err := s.events(Query{})
+	if err != nil {
+		return err
+	}
+	previous := s.segments
+	c := segmentCommit{Head: s.head, Compacted: true}
+	if len(events) > 0 {
+		c.Segment, err = s.writeSegment(events)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.commit(c)
+	if err != nil {
+		return err
+	}
+	for _, seg := range previous {
+		os.Remove(filepath.Join(s.dir, seg.File))
+	}
+	return nil
+}
+
+// writeSegment writes the events to a new segment file synced to disk, s.mu
+// must be held.
+func (s *SegmentStore) writeSegment(events []Event) (*segment, error) {
+	seg := &segment{
+		Seq:       s.seq + 1,
+		File:      fmt.Sprintf("segment-%08d.jsonl", s.seq+1),
+		FromBlock: events[0].BlockNumber,
+		ToBlock:   events[0].BlockNumber,
+	}
+	names := make(map[string]bool)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		args := make(map[string]interface{}, len(e.Args))
+		for k, v := range e.Args {
+			args[k] = FormatArg(v)
+		}
+		e.Args = args
+		err := enc.Encode(e)
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding events")
+		}
+		if e.BlockNumber < seg.FromBlock {
+			seg.FromBlock = e.BlockNumber
+		}
+		if e.BlockNumber > seg.ToBlock {
+			seg.ToBlock = e.BlockNumber
+		}
+		names[e.Contract+"."+e.Name] = true
+	}
+	for n := range names {
+		seg.Names = append(seg.Names, n)
+	}
+	sort.Strings(seg.Names)
+
+	path := filepath.Join(s.dir, seg.File)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating segment")
+	}
+	_, err = f.Write(buf.Bytes())
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, errors.Wrap(err, "writing segment")
+	}
+	return seg, nil
+}
+
+// commit appends a commit to the log and syncs it, s.mu must be held. A
+// failed commit is truncated.
+func (s *SegmentStore) commit(c segmentCommit) error {
+	line, err := json.Marshal(c)
+	if err != nil {
+		return errors.Wrap(err, "encoding commit")
+	}
+	line = append(line, '\n')
+	_, err = s.log.Write(line)
+	if err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		s.log.Truncate(s.size)
+		s.log.Seek(s.size, io.SeekStart)
+		if c.Segment != nil {
+			os.Remove(filepath.Join(s.dir, c.Segment.File))
+		}
+		return errors.Wrap(err, "writing store log")
+	}
+	s.size += int64(len(line))
+	s.apply(c)
+	return nil
+}
+
+// Events implements Store, reading the segments which may hold matching
+// events.
+func (s *SegmentStore) Events(q Query) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events(q)
+}
+
+// events returns the events matching q, s.mu must be held.
+func (s *SegmentStore) events(q Query) ([]Event, error) {
+	var r []Event
+	for _, seg := range s.segments {
+		if !seg.mayMatch(q) {
+			continue
+		}
+		events, err := s.read(seg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			if removed, ok := s.removed[keyOf(e)]; ok && removed > seg.Seq {
+				continue
+			}
+			if q.Matches(e) {
+				r = append(r, e)
+			}
+		}
+	}
+	// The events of reorganized blocks may be in later segments than the
+	// following blocks.
+	sort.SliceStable(r, func(a, b int) bool {
+		return r[a].Position().Before(r[b].Position())
+	})
+	if q.Limit > 0 && len(r) > q.Limit {
+		r = r[:q.Limit]
+	}
+	return r, nil
+}
+
+// mayMatch tells whether the segment may hold events matching q.
+func (seg segment) mayMatch(q Query) bool {
+	if seg.ToBlock < q.FromBlock || (q.ToBlock != 0 && seg.FromBlock > q.ToBlock) {
+		return false
+	}
+	if q.After != nil && seg.ToBlock < q.After.BlockNumber {
+		return false
+	}
+	if q.Contract == "" && q.Name == "" {
+		return true
+	}
+	for _, n := range seg.Names {
+		i := strings.LastIndexByte(n, '.')
+		if (q.Contract == "" || q.Contract == n[:i]) && (q.Name == "" || q.Name == n[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// read reads the events of a segment.
+func (s *SegmentStore) read(seg segment) ([]Event, error) {
+	f, err := os.Open(filepath.Join(s.dir, seg.File))
+	if err != nil {
+		return nil, errors.Wrap(err, "opening segment")
+	}
+	defer f.Close()
+	var events []Event
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading segment %s", seg.File)
+		}
+		events = append(events, e)
+	}
+}
@@ -0,0 +1,174 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Query selects stored events. Zero fields do not restrict the result.
+type Query struct {
+	Contract  string
+	Name      string
+	FromBlock uint64
+	ToBlock   uint64
+	// Args selects the events whose arguments, formatted by FormatArg, are
+	// equal to the given values. Values are compared case insensitively so
+	// that addresses match regardless of their checksum.
+	Args map[string]string
+	// After selects the events following the given position.
+	After *Position
+	// Limit is the maximum number of events returned, 0 means no limit.
+	Limit int
+}
+
+// Matches reports whether the query selects the event.
+func (q Query) Matches(e Event) bool {
+	if q.After != nil && !q.After.Before(e.Position()) {
+		return false
+	}
+	if q.Contract != "" && q.Contract != e.Contract {
+		return false
+	}
+	if q.Name != "" && q.Name != e.Name {
+		return false
+	}
+	if e.BlockNumber < q.FromBlock {
+		return false
+	}
+	if q.ToBlock != 0 && e.BlockNumber > q.ToBlock {
+		return false
+	}
+	for name, want := range q.Args {
+		v, ok := e.Args[name]
+		if !ok || !strings.EqualFold(fmt.Sprint(FormatArg(v)), want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Store persists indexed events.
+type Store interface {
+	// Head returns the last indexed block and whether any block was indexed.
+	Head() (uint64, bool)
+	// Append stores the events of the blocks up to and including head.
+	Append(head uint64, events []Event) error
+	// Events returns the stored events matching the query in chain order.
+	Events(q Query) ([]Event, error)
+}
+
+// Remover is implemented by the stores which can remove the events of the
+// blocks reorganized, as required by an Indexer with a ReorgDepth.
+type Remover interface {
+	// Remove removes the stored events in the same block and at the same
+	// index as the given events.
+	Remove(events []Event) error
+}
+
+// Replacer is implemented by the stores which can replace the events of the
+// blocks reorganized, as required by an Indexer with a ReorgDepth. The
+// removals and the events replacing them are stored at once, so that a crash
+// cannot leave the store without the events of either chain.
+type Replacer interface {
+	// Replace removes the stored events in the same block and at the same
+	// index as the removed events, and stores the added events of the
+	// blocks up to and including head.
+	Replace(head uint64, removed, added []Event) error
+}
+
+// MemoryStore is a Store keeping the events in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	head    uint64
+	indexed bool
+	events  []Event
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Head implements Store.
+func (m *MemoryStore) Head() (uint64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.head, m.indexed
+}
+
+// Append implements Store.
+func (m *MemoryStore) Append(head uint64, events []Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.append(head, events)
+	return nil
+}
+
+// Remove implements Remover.
+func (m *MemoryStore) Remove(events []Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(events)
+	return nil
+}
+
+// Replace implements Replacer, the events removed and added are read back
+// together.
+func (m *MemoryStore) Replace(head uint64, removed, added []Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(removed)
+	m.append(head, added)
+	return nil
+}
+
+// append stores the events, m.mu must be held.
+func (m *MemoryStore) append(head uint64, events []Event) {
+	n := len(m.events)
+	m.events = append(m.events, events...)
+	// The events of reorganized blocks may precede the last ones stored.
+	if n > 0 && len(events) > 0 && events[0].Position().Before(m.events[n-1].Position()) {
+		sort.SliceStable(m.events, func(a, b int) bool {
+			return m.events[a].Position().Before(m.events[b].Position())
+		})
+	}
+	m.head = head
+	m.indexed = true
+}
+
+// remove removes the events, m.mu must be held.
+func (m *MemoryStore) remove(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	removed := make(map[eventKey]bool, len(events))
+	for _, e := range events {
+		removed[keyOf(e)] = true
+	}
+	kept := m.events[:0]
+	for _, e := range m.events {
+		if !removed[keyOf(e)] {
+			kept = append(kept, e)
+		}
+	}
+	m.events = kept
+}
+
+// Events implements Store.
+func (m *MemoryStore) Events(q Query) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var r []Event
+	for _, e := range m.events {
+		if !q.Matches(e) {
+			continue
+		}
+		r = append(r, e)
+		if q.Limit > 0 && len(r) == q.Limit {
+			break
+		}
+	}
+	return r, nil
+}
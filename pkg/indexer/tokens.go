@@ -0,0 +1,76 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// TokenChange is a token added to or removed from the token whitelist.
+type TokenChange struct {
+	Token common.Address `json:"token"`
+	// Added is false when the token was removed, the other fields are then
+	// empty.
+	Added      bool     `json:"added"`
+	Symbol     string   `json:"symbol,omitempty"`
+	Magnitude  *big.Int `json:"magnitude,omitempty"`
+	Loadable   bool     `json:"loadable,omitempty"`
+	Redeemable bool     `json:"redeemable,omitempty"`
+	// Reverted is set when the event of the change was removed by a
+	// reorganization, the change is then to be undone.
+	Reverted bool  `json:"reverted,omitempty"`
+	Event    Event `json:"event"`
+}
+
+// DecodeTokenChange decodes an AddedToken or RemovedToken event of the token
+// whitelist, it returns false for the other events.
+func DecodeTokenChange(e Event) (TokenChange, bool, error) {
+	if e.Name != "AddedToken" && e.Name != "RemovedToken" {
+		return TokenChange{}, false, nil
+	}
+	c := TokenChange{Added: e.Name == "AddedToken", Reverted: e.Removed, Event: e}
+	var ok bool
+	c.Token, ok = AddressArg(e, "_token")
+	if !ok {
+		return TokenChange{}, false, errors.Wrapf(ErrMalformedLog, "%s event without a token", e.Name)
+	}
+	if !c.Added {
+		return c, true, nil
+	}
+	c.Symbol, _ = e.Args["_symbol"].(string)
+	c.Magnitude, ok = BigArg(e, "_magnitude")
+	if !ok {
+		return TokenChange{}, false, errors.Wrapf(ErrMalformedLog, "%s event of %s without a magnitude", e.Name, c.Token.Hex())
+	}
+	c.Loadable, _ = e.Args["_loadable"].(bool)
+	c.Redeemable, _ = e.Args["_redeemable"].(bool)
+	return c, true, nil
+}
+
+// TokenChanges returns a handler calling fn with the tokens added to and
+// removed from the whitelist indexed as contract, in chain order. The
+// changes whose event was removed by a reorganization are passed again with
+// Reverted set, latest first, as the indexer hands them.
+func TokenChanges(contract string, fn func(ctx context.Context, changes []TokenChange) error) Handler {
+	return HandlerFunc(func(ctx context.Context, events []Event) error {
+		var changes []TokenChange
+		for _, e := range events {
+			if e.Contract != contract {
+				continue
+			}
+			c, ok, err := DecodeTokenChange(e)
+			if err != nil {
+				return err
+			}
+			if ok {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return fn(ctx, changes)
+	})
+}
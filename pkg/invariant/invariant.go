@@ -0,0 +1,156 @@
+// Package invariant asserts the invariants of the contracts, the properties
+// which hold at every block unless a contract has a bug or the index of its
+// events is corrupt: the licence amount stays in its range, every
+// whitelisted token is available, the roles at the Controller and the tokens
+// of the TokenWhitelist are those of the indexed events, and the supply of
+// TKN never grows.
+//
+// The Monitor checks the invariants at the last block indexed by the store,
+// reading the state of the contracts at that block so that the events and
+// the state compared are those of the same block. The invariants comparing
+// the state with the indexed events require the events indexed from the
+// deployment of the contracts on.
+//
+// A violation is alerted when it first appears, and again as resolved once
+// it no longer holds.
+package invariant
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Invariant is a property of the contracts holding at every block.
+type Invariant struct {
+	Name string
+	// Check returns the violations of the invariant at the block of opts,
+	// given the events of the store indexed up to that block.
+	Check func(opts *bind.CallOpts, store indexer.Store) ([]string, error)
+}
+
+// Violation is a violated invariant.
+type Violation struct {
+	Invariant   string    `json:"invariant"`
+	Detail      string    `json:"detail"`
+	BlockNumber uint64    `json:"block_number"`
+	Time        time.Time `json:"time"`
+	// Resolved is set when the violation alerted before no longer holds.
+	Resolved bool `json:"resolved,omitempty"`
+}
+
+// Monitor periodically checks the invariants.
+type Monitor struct {
+	Store      indexer.Store
+	Invariants []Invariant
+	Interval   time.Duration
+	// Alert is called with the violations appearing and those resolved
+	// since the previous check.
+	Alert func(ctx context.Context, violations []Violation) error
+	// ErrorLog receives the errors of failed checks, they are discarded when
+	// nil.
+	ErrorLog *log.Logger
+
+	mu     sync.Mutex
+	firing map[violationKey]Violation
+}
+
+type violationKey struct {
+	invariant, detail string
+}
+
+// Run checks the invariants every Interval until the context is cancelled.
+func (m *Monitor) Run(ctx context.Context) error {
+	t := time.NewTicker(m.Interval)
+	defer t.Stop()
+	for {
+		_, err := m.Check(ctx)
+		if err != nil && m.ErrorLog != nil {
+			m.ErrorLog.Printf("invariant check failed: %v", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check checks the invariants at the last block indexed and returns their
+// violations, nothing until a block is indexed. An invariant failing to be
+// checked keeps its violations firing.
+func (m *Monitor) Check(ctx context.Context) ([]Violation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.firing == nil {
+		m.firing = make(map[violationKey]Violation)
+	}
+
+	head, ok := m.Store.Head()
+	if !ok {
+		return nil, nil
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)}
+	now := time.Now().UTC()
+
+	var violations []Violation
+	current := make(map[violationKey]bool)
+	checked := make(map[string]bool)
+	var failed []string
+	for _, inv := range m.Invariants {
+		details, err := inv.Check(opts, m.Store)
+		if err != nil {
+			failed = append(failed, errors.Wrapf(err, "checking %s", inv.Name).Error())
+			continue
+		}
+		checked[inv.Name] = true
+		for _, d := range details {
+			v := Violation{Invariant: inv.Name, Detail: d, BlockNumber: head, Time: now}
+			violations = append(violations, v)
+			current[violationKey{inv.Name, d}] = true
+		}
+	}
+
+	var changes []Violation
+	for _, v := range violations {
+		k := violationKey{v.Invariant, v.Detail}
+		if _, ok := m.firing[k]; !ok {
+			m.firing[k] = v
+			changes = append(changes, v)
+		}
+	}
+	var resolved []Violation
+	for k, v := range m.firing {
+		if checked[k.invariant] && !current[k] {
+			delete(m.firing, k)
+			v.Resolved, v.BlockNumber, v.Time = true, head, now
+			resolved = append(resolved, v)
+		}
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		if resolved[i].Invariant != resolved[j].Invariant {
+			return resolved[i].Invariant < resolved[j].Invariant
+		}
+		return resolved[i].Detail < resolved[j].Detail
+	})
+	changes = append(changes, resolved...)
+
+	if len(changes) > 0 && m.Alert != nil {
+		err := m.Alert(ctx, changes)
+		if err != nil {
+			return violations, errors.Wrap(err, "alerting invariant violations")
+		}
+	}
+	if len(failed) > 0 {
+		return violations, errors.Errorf("%d invariants not checked: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return violations, nil
+}
@@ -0,0 +1,252 @@
+package invariant
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// The range of the licence amount, scaled by 1000, enforced by the Licence.
+var (
+	minLicenceAmount = big.NewInt(1)
+	maxLicenceAmount = big.NewInt(1000)
+)
+
+// LicenceAmount asserts that the licence amount of the Licence indexed as
+// contract is in its range and is the amount of the last UpdatedLicenceAmount
+// event indexed, if any.
+func LicenceAmount(licence *bindings.LicenceCaller, contract string) Invariant {
+	return Invariant{
+		Name: "licence_amount",
+		Check: func(opts *bind.CallOpts, store indexer.Store) ([]string, error) {
+			amount, err := licence.LicenceAmountScaled(opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting licence amount")
+			}
+			var violations []string
+			if amount.Cmp(minLicenceAmount) < 0 || amount.Cmp(maxLicenceAmount) > 0 {
+				violations = append(violations, fmt.Sprintf("licence amount %s is out of range [%s, %s]", amount, minLicenceAmount, maxLicenceAmount))
+			}
+			events, err := store.Events(indexer.Query{Contract: contract, Name: "UpdatedLicenceAmount", ToBlock: opts.BlockNumber.Uint64()})
+			if err != nil {
+				return nil, err
+			}
+			events = kept(events)
+			if len(events) > 0 {
+				last := fmt.Sprint(indexer.FormatArg(events[len(events)-1].Args["_newAmount"]))
+				if last != amount.String() {
+					violations = append(violations, fmt.Sprintf("licence amount is %s, the last indexed update set %s", amount, last))
+				}
+			}
+			return violations, nil
+		},
+	}
+}
+
+// TokenWhitelist asserts that every token of the TokenWhitelist indexed as
+// contract is available, that the redeemable counter is the number of
+// redeemable tokens, and that the tokens are those added and not removed by
+// the indexed events.
+func TokenWhitelist(whitelist *bindings.TokenWhitelistCaller, contract string) Invariant {
+	return Invariant{
+		Name: "token_whitelist",
+		Check: func(opts *bind.CallOpts, store indexer.Store) ([]string, error) {
+			tokens, err := whitelist.TokenAddressArray(opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting tokens")
+			}
+			var violations []string
+			listed := make(map[common.Address]bool, len(tokens))
+			redeemable := int64(0)
+			for _, t := range tokens {
+				listed[t] = true
+				_, _, _, available, _, isRedeemable, _, err := whitelist.GetTokenInfo(opts, t)
+				if err != nil {
+					return nil, errors.Wrapf(err, "getting token %s", t.Hex())
+				}
+				if !available {
+					violations = append(violations, fmt.Sprintf("token %s is listed but not available", t.Hex()))
+				}
+				if isRedeemable {
+					redeemable++
+				}
+			}
+			counter, err := whitelist.RedeemableCounter(opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting redeemable counter")
+			}
+			if counter.Cmp(big.NewInt(redeemable)) != 0 {
+				violations = append(violations, fmt.Sprintf("redeemable counter is %s, %d tokens are redeemable", counter, redeemable))
+			}
+
+			indexed, err := members(store, contract, opts.BlockNumber.Uint64(), "AddedToken", "RemovedToken", "_token")
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, compare("token", listed, indexed)...)
+			return violations, nil
+		},
+	}
+}
+
+// ControllerRoles asserts that the admins and the controllers of the
+// Controller indexed as contract are those added and not removed by the
+// indexed events.
+func ControllerRoles(controller *bindings.ControllerCaller, contract string) Invariant {
+	return Invariant{
+		Name: "controller_roles",
+		Check: func(opts *bind.CallOpts, store indexer.Store) ([]string, error) {
+			var violations []string
+			for _, role := range []struct {
+				name           string
+				added, removed string
+				arg            string
+				count          func(*bind.CallOpts) (*big.Int, error)
+				has            func(*bind.CallOpts, common.Address) (bool, error)
+			}{
+				{"admin", "AddedAdmin", "RemovedAdmin", "_admin", controller.AdminCount, controller.IsAdmin},
+				{"controller", "AddedController", "RemovedController", "_controller", controller.ControllerCount, controller.IsController},
+			} {
+				indexed, err := members(store, contract, opts.BlockNumber.Uint64(), role.added, role.removed, role.arg)
+				if err != nil {
+					return nil, err
+				}
+				count, err := role.count(opts)
+				if err != nil {
+					return nil, errors.Wrapf(err, "getting %s count", role.name)
+				}
+				if count.Cmp(big.NewInt(int64(len(indexed)))) != 0 {
+					violations = append(violations, fmt.Sprintf("%s count is %s, %d %ss are indexed", role.name, count, len(indexed), role.name))
+				}
+				for _, a := range sorted(indexed) {
+					ok, err := role.has(opts, a)
+					if err != nil {
+						return nil, errors.Wrapf(err, "getting %s role of %s", role.name, a.Hex())
+					}
+					if !ok {
+						violations = append(violations, fmt.Sprintf("%s %s is indexed but has not the role", role.name, a.Hex()))
+					}
+				}
+			}
+			return violations, nil
+		},
+	}
+}
+
+const totalSupplyABI = `[{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
+
+var parsedTotalSupplyABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(totalSupplyABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// TKNSupply asserts that the total supply of the TKN token at address never
+// grows, the token being only ever burnt, and that it stays below the supply
+// cap when the cap is not nil.
+func TKNSupply(address common.Address, backend bind.ContractCaller, cap *big.Int) Invariant {
+	token := bind.NewBoundContract(address, parsedTotalSupplyABI, backend, nil, nil)
+	var mu sync.Mutex
+	var last *big.Int
+	return Invariant{
+		Name: "tkn_supply",
+		Check: func(opts *bind.CallOpts, store indexer.Store) ([]string, error) {
+			supply := new(big.Int)
+			err := token.Call(opts, &supply, "totalSupply")
+			if err != nil {
+				return nil, errors.Wrap(err, "getting total supply")
+			}
+			var violations []string
+			if cap != nil && supply.Cmp(cap) > 0 {
+				violations = append(violations, fmt.Sprintf("total supply %s is above the cap %s", supply, cap))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if last != nil && supply.Cmp(last) > 0 {
+				violations = append(violations, fmt.Sprintf("total supply grew from %s to %s", last, supply))
+			} else {
+				last = supply
+			}
+			return violations, nil
+		},
+	}
+}
+
+// members returns the accounts added and not removed by the indexed events of
+// contract up to the block, named by arg.
+func members(store indexer.Store, contract string, block uint64, added, removed, arg string) (map[common.Address]bool, error) {
+	events, err := store.Events(indexer.Query{Contract: contract, ToBlock: block})
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[common.Address]bool)
+	for _, e := range kept(events) {
+		a := address(e.Args[arg])
+		switch e.Name {
+		case added:
+			m[a] = true
+		case removed:
+			delete(m, a)
+		}
+	}
+	return m, nil
+}
+
+// compare returns the differences between the accounts on the chain and the
+// indexed ones.
+func compare(kind string, chain, indexed map[common.Address]bool) []string {
+	var violations []string
+	for _, a := range sorted(chain) {
+		if !indexed[a] {
+			violations = append(violations, fmt.Sprintf("%s %s is not indexed", kind, a.Hex()))
+		}
+	}
+	for _, a := range sorted(indexed) {
+		if !chain[a] {
+			violations = append(violations, fmt.Sprintf("%s %s is indexed but not on the chain", kind, a.Hex()))
+		}
+	}
+	return violations
+}
+
+func sorted(m map[common.Address]bool) []common.Address {
+	r := make([]common.Address, 0, len(m))
+	for a := range m {
+		r = append(r, a)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Hex() < r[j].Hex() })
+	return r
+}
+
+// kept returns the events which were not removed by a reorganization.
+func kept(events []indexer.Event) []indexer.Event {
+	var r []indexer.Event
+	for _, e := range events {
+		if !e.Removed {
+			r = append(r, e)
+		}
+	}
+	return r
+}
+
+// address reads an address argument, decoded or formatted as a string.
+func address(v interface{}) common.Address {
+	switch v := v.(type) {
+	case common.Address:
+		return v
+	case string:
+		return common.HexToAddress(v)
+	}
+	return common.Address{}
+}
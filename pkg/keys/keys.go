@@ -0,0 +1,187 @@
+// Package keys manages the operator accounts held in a directory of encrypted
+// geth keystore (V3) files.
+//
+// The private keys never leave the keystore: transactions are signed by the
+// keystore itself once an account is unlocked with its passphrase, so services
+// only need the keystore directory, an address and a passphrase.
+//
+// For example:
+//
+//	ks := keys.Open("/secrets/keystore")
+//	opts, err := ks.TransactOpts(operator, os.Getenv("MONOLITHD_PASSWORD"), chainID)
+//	tx, err := licence.UpdateLicenceAmount(opts, amount)
+package keys
+
+import (
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+// ErrUnknownAccount is returned for an address without a key file in the keystore.
+var ErrUnknownAccount = errors.New("unknown account")
+
+// Keystore is a directory of encrypted key files.
+type Keystore struct {
+	ks *keystore.KeyStore
+}
+
+// Open opens the keystore in dir, creating the directory when the first
+// account is added. New keys are encrypted with the standard scrypt parameters.
+func Open(dir string) *Keystore {
+	return OpenWithScrypt(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// OpenWithScrypt opens the keystore in dir encrypting new keys with the given
+// scrypt parameters. Use keystore.LightScryptN and keystore.LightScryptP in
+// tests and development only.
+func OpenWithScrypt(dir string, scryptN, scryptP int) *Keystore {
+	return &Keystore{ks: keystore.NewKeyStore(dir, scryptN, scryptP)}
+}
+
+// Accounts returns the addresses of the accounts in the keystore.
+func (k *Keystore) Accounts() []common.Address {
+	accs := k.ks.Accounts()
+	addrs := make([]common.Address, len(accs))
+	for i, a := range accs {
+		addrs[i] = a.Address
+	}
+	return addrs
+}
+
+// Has reports whether the keystore holds the key of address.
+func (k *Keystore) Has(address common.Address) bool {
+	return k.ks.HasAddress(address)
+}
+
+// Path returns the path of the key file of address.
+func (k *Keystore) Path(address common.Address) (string, error) {
+	a, err := k.find(address)
+	if err != nil {
+		return "", err
+	}
+	return a.URL.Path, nil
+}
+
+// Create generates a new account encrypted with passphrase.
+func (k *Keystore) Create(passphrase string) (common.Address, error) {
+	a, err := k.ks.NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "creating account")
+	}
+	return a.Address, nil
+}
+
+// Import adds the key held in a keystore file, decrypted with passphrase and
+// stored encrypted with newPassphrase.
+func (k *Keystore) Import(path, passphrase, newPassphrase string) (common.Address, error) {
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "reading keystore file")
+	}
+	a, err := k.ks.Import(keyJSON, passphrase, newPassphrase)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "importing keystore file %s", path)
+	}
+	return a.Address, nil
+}
+
+// Rotate generates a new account replacing address. The passphrase of address
+// must be given, it also encrypts the new key.
+//
+// The old key is kept so that the roles it holds can be transferred to the new
+// account, it is removed with Retire once that is done.
+func (k *Keystore) Rotate(address common.Address, passphrase string) (common.Address, error) {
+	a, err := k.find(address)
+	if err != nil {
+		return common.Address{}, err
+	}
+	_, err = k.ks.Export(a, passphrase, passphrase)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "unlocking account %s", address.Hex())
+	}
+	return k.Create(passphrase)
+}
+
+// Retire deletes the key file of address.
+func (k *Keystore) Retire(address common.Address, passphrase string) error {
+	a, err := k.find(address)
+	if err != nil {
+		return err
+	}
+	err = k.ks.Delete(a, passphrase)
+	if err != nil {
+		return errors.Wrapf(err, "deleting account %s", address.Hex())
+	}
+	return nil
+}
+
+// ChangePassphrase encrypts the key of address with newPassphrase.
+func (k *Keystore) ChangePassphrase(address common.Address, passphrase, newPassphrase string) error {
+	a, err := k.find(address)
+	if err != nil {
+		return err
+	}
+	err = k.ks.Update(a, passphrase, newPassphrase)
+	if err != nil {
+		return errors.Wrapf(err, "updating account %s", address.Hex())
+	}
+	return nil
+}
+
+// TransactOpts unlocks address and returns options signing EIP-155
+// transactions for the given chain with its key. The account stays unlocked
+// until Lock is called.
+func (k *Keystore) TransactOpts(address common.Address, passphrase string, chainID *big.Int) (*bind.TransactOpts, error) {
+	a, err := k.find(address)
+	if err != nil {
+		return nil, err
+	}
+	err = k.ks.Unlock(a, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unlocking account %s", address.Hex())
+	}
+	return &bind.TransactOpts{
+		From: a.Address,
+		Signer: func(_ types.Signer, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != a.Address {
+				return nil, signer.ErrNotAuthorized
+			}
+			return k.ks.SignTx(a, tx, chainID)
+		},
+	}, nil
+}
+
+// SignHash signs a 32 byte hash with the key of address, returning the
+// signature in the [R || S || V] form with V either 0 or 1.
+func (k *Keystore) SignHash(address common.Address, passphrase string, hash []byte) ([]byte, error) {
+	a, err := k.find(address)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := k.ks.SignHashWithPassphrase(a, passphrase, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing with account %s", address.Hex())
+	}
+	return sig, nil
+}
+
+// Lock removes the decrypted key of address from memory.
+func (k *Keystore) Lock(address common.Address) error {
+	return k.ks.Lock(address)
+}
+
+func (k *Keystore) find(address common.Address) (accounts.Account, error) {
+	a, err := k.ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return accounts.Account{}, errors.Wrapf(ErrUnknownAccount, "%s", address.Hex())
+	}
+	return a, nil
+}
@@ -0,0 +1,199 @@
+// Package legacy writes the indexed events in the schema of the systems of a
+// previous indexer, for the teams migrating from it. An indexer.Mirror writes
+// the events to them alongside the store of the indexer during the
+// transition, see indexer.NewMirror.
+//
+// The events are converted to records by a Schema, naming the field of the
+// legacy record holding each field of the event:
+//
+//	{"event_name": "name", "block": "block_number", "tx": "tx_hash", "amount": "args._amount"}
+//
+// A record is written for each event, a record with the removed field set
+// undoes the record of the event removed by a reorganization. The records
+// written again after a failure may be received twice.
+package legacy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Fields of the events a Schema maps, the arguments are mapped with the
+// argPrefix followed by their name.
+var fields = map[string]func(e indexer.Event) interface{}{
+	"contract":     func(e indexer.Event) interface{} { return e.Contract },
+	"address":      func(e indexer.Event) interface{} { return e.Address.Hex() },
+	"name":         func(e indexer.Event) interface{} { return e.Name },
+	"block_number": func(e indexer.Event) interface{} { return e.BlockNumber },
+	"block_hash":   func(e indexer.Event) interface{} { return e.BlockHash.Hex() },
+	"tx_hash":      func(e indexer.Event) interface{} { return e.TxHash.Hex() },
+	"tx_index":     func(e indexer.Event) interface{} { return e.TxIndex },
+	"log_index":    func(e indexer.Event) interface{} { return e.LogIndex },
+	"removed":      func(e indexer.Event) interface{} { return e.Removed },
+}
+
+const argPrefix = "args."
+
+// Schema maps the fields of the legacy records to the fields of the events.
+// An empty schema writes the events as they are stored.
+type Schema map[string]string
+
+// Validate checks that the schema maps known fields of the events.
+func (s Schema) Validate() error {
+	for _, name := range s.names() {
+		field := s[name]
+		if _, ok := fields[field]; ok {
+			continue
+		}
+		if strings.HasPrefix(field, argPrefix) && len(field) > len(argPrefix) {
+			continue
+		}
+		return errors.Errorf("field %q of the legacy records maps unknown event field %q", name, field)
+	}
+	return nil
+}
+
+// Record returns the legacy record of the event. The arguments missing from
+// the event are null, they are formatted by indexer.FormatArg.
+func (s Schema) Record(e indexer.Event) interface{} {
+	if len(s) == 0 {
+		return e
+	}
+	r := make(map[string]interface{}, len(s))
+	for name, field := range s {
+		if f, ok := fields[field]; ok {
+			r[name] = f(e)
+			continue
+		}
+		r[name] = indexer.FormatArg(e.Args[strings.TrimPrefix(field, argPrefix)])
+	}
+	return r
+}
+
+func (s Schema) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// File is an indexer.Legacy appending the records to a file, one JSON record
+// per line, for the legacy systems consuming a spool file.
+type File struct {
+	schema Schema
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenFile opens the file appending the records of the schema to path.
+func OpenFile(path string, schema Schema) (*File, error) {
+	err := schema.Validate()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening legacy file")
+	}
+	return &File{schema: schema, f: f}, nil
+}
+
+// Write implements indexer.Legacy. The records are written with a single
+// write so that a failure does not leave them half written, nothing is
+// written when there are no events.
+func (f *File) Write(head uint64, events []indexer.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, e := range events {
+		err := enc.Encode(f.schema.Record(e))
+		if err != nil {
+			return errors.Wrapf(err, "encoding record of event %d of block %d", e.LogIndex, e.BlockNumber)
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.f.Write(b.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "writing legacy file")
+	}
+	return f.f.Sync()
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.f.Close()
+}
+
+// Batch is the body of the requests of a Queue.
+type Batch struct {
+	// Head is the last block indexed.
+	Head    uint64        `json:"head"`
+	Records []interface{} `json:"records"`
+}
+
+// Queue is an indexer.Legacy posting the records to the HTTP endpoint of a
+// legacy queue, as a JSON Batch. Any response other than 2xx is an error.
+type Queue struct {
+	URL    string
+	Schema Schema
+	// Client posts the batches, a client timing out after 30 seconds when
+	// nil: the indexer waits for the batches to be posted.
+	Client *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Write implements indexer.Legacy. Nothing is posted when there are no
+// events.
+func (q *Queue) Write(head uint64, events []indexer.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch := Batch{Head: head, Records: make([]interface{}, len(events))}
+	for n, e := range events {
+		batch.Records[n] = q.Schema.Record(e)
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return errors.Wrap(err, "encoding batch")
+	}
+	req, err := http.NewRequest(http.MethodPost, q.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := q.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "posting batch")
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
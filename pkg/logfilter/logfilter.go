@@ -0,0 +1,240 @@
+// Package logfilter splits the log queries spanning more blocks than the
+// providers accept. Infura and Alchemy reject the eth_getLogs calls over
+// about 10000 blocks: Backend filters such ranges in chunks and returns their
+// logs as a single result, so the FilterX methods of the bindings and their
+// iterators keep working over the whole history of a contract.
+//
+// A chunk refused as too large is split in two, and a chunk refused by the
+// rate limit of the provider is retried after a backoff.
+//
+// For example:
+//
+//	client, err := ethclient.Dial(url)
+//	holder, err := bindings.NewHolder(address, logfilter.NewBackend(client, client))
+//	it, err := holder.FilterBurned(&bind.FilterOpts{Start: deploymentBlock})
+package logfilter
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+const (
+	// DefaultMaxBlocks is the number of blocks filtered at once when
+	// MaxBlocks is not set.
+	DefaultMaxBlocks = 10000
+	// DefaultAttempts is the number of times a rate limited chunk is
+	// filtered when Attempts is not set.
+	DefaultAttempts = 5
+	// DefaultBackoff is the delay before the first retry of a rate limited
+	// chunk when Backoff is not set.
+	DefaultBackoff = time.Second
+)
+
+// HeaderReader returns the head of the chain, the end of the queries without
+// a last block.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Backend is a bind.ContractBackend filtering the logs of large block ranges
+// in chunks.
+type Backend struct {
+	bind.ContractBackend
+	headers HeaderReader
+
+	// MaxBlocks is the number of blocks filtered at once. A chunk refused as
+	// too large is split in two, down to a single block, and the smaller
+	// size is kept for the rest of the query.
+	MaxBlocks uint64
+	// Attempts is the number of times a chunk is filtered while the provider
+	// rate limits it.
+	Attempts int
+	// Backoff is the delay before the first retry of a rate limited chunk,
+	// doubled for each retry.
+	Backoff time.Duration
+	// Interval is the minimum delay between two log queries sent to the
+	// provider, they are not spaced when zero.
+	Interval time.Duration
+	// Logger receives the split and retried chunks.
+	Logger logging.Logger
+
+	mu sync.Mutex
+	// next is the earliest time of the next log query.
+	next time.Time
+}
+
+// NewBackend wraps a backend, reading the head of the chain from headers.
+func NewBackend(backend bind.ContractBackend, headers HeaderReader) *Backend {
+	return &Backend{
+		ContractBackend: backend,
+		headers:         headers,
+		MaxBlocks:       DefaultMaxBlocks,
+		Attempts:        DefaultAttempts,
+		Backoff:         DefaultBackoff,
+	}
+}
+
+// HeaderByNumber implements indexer.Backend.
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return b.headers.HeaderByNumber(ctx, number)
+}
+
+// FilterLogs implements bind.ContractFilterer. The logs of the chunks are
+// returned in chain order, as a single query would return them.
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	// Queries of a block hash or of the pending block are sent as they are.
+	if query.BlockHash != nil || (query.FromBlock != nil && query.FromBlock.Sign() < 0) || (query.ToBlock != nil && query.ToBlock.Sign() < 0) {
+		return b.filter(ctx, query)
+	}
+
+	var from uint64
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	var to uint64
+	if query.ToBlock != nil {
+		to = query.ToBlock.Uint64()
+	} else {
+		head, err := b.headers.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting latest block")
+		}
+		to = head.Number.Uint64()
+	}
+	if to < from {
+		return b.filter(ctx, query)
+	}
+
+	size := b.MaxBlocks
+	if size == 0 {
+		size = DefaultMaxBlocks
+	}
+
+	var logs []types.Log
+	for from <= to {
+		end := to
+		if to-from >= size {
+			end = from + size - 1
+		}
+		chunk := query
+		chunk.FromBlock = new(big.Int).SetUint64(from)
+		chunk.ToBlock = new(big.Int).SetUint64(end)
+
+		l, err := b.filter(ctx, chunk)
+		if err != nil && isRangeTooLarge(err) && end > from {
+			size = (end - from + 1) / 2
+			b.logger().Debug("Log query refused, splitting it", "from", from, "to", end, "blocks", size, "err", err)
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "filtering logs of blocks %d to %d", from, end)
+		}
+		logs = append(logs, l...)
+		from = end + 1
+	}
+	return logs, nil
+}
+
+// filter sends a log query, retrying it while the provider rate limits it.
+func (b *Backend) filter(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	backoff := b.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	attempts := b.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		err := b.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		logs, err := b.ContractBackend.FilterLogs(ctx, query)
+		if err == nil || !isRateLimited(err) || attempt >= attempts {
+			return logs, err
+		}
+		b.logger().Warn("Log query rate limited, retrying", "attempt", attempt, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// wait blocks until the next log query may be sent.
+func (b *Backend) wait(ctx context.Context) error {
+	if b.Interval <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	at := b.next
+	if at.Before(now) {
+		at = now
+	}
+	b.next = at.Add(b.Interval)
+	b.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(at)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Backend) logger() logging.Logger {
+	return logging.Or(b.Logger)
+}
+
+// rangeErrors are parts of the errors of the providers refusing a query over
+// too many blocks or returning too many logs.
+var rangeErrors = []string{
+	"query returned more than",
+	"block range",
+	"range is too large",
+	"range too large",
+	"response size exceeded",
+	"query timeout exceeded",
+}
+
+// rateLimitErrors are parts of the errors of the providers refusing a query
+// over their rate limit.
+var rateLimitErrors = []string{
+	"429",
+	"too many requests",
+	"rate limit",
+	"request rate exceeded",
+	"compute units per second",
+}
+
+func isRangeTooLarge(err error) bool {
+	return contains(err, rangeErrors)
+}
+
+func isRateLimited(err error) bool {
+	return contains(err, rateLimitErrors)
+}
+
+func contains(err error, parts []string) bool {
+	msg := strings.ToLower(err.Error())
+	for _, p := range parts {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
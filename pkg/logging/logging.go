@@ -0,0 +1,78 @@
+// Package logging defines the structured logger the client packages report
+// to. Messages are followed by alternating keys and values:
+//
+//	logger.Info("Submitted transaction", "hash", tx.Hash(), "nonce", tx.Nonce())
+//
+// go-ethereum's log.Logger and the standard library's *slog.Logger implement
+// Logger as they are, a zap SugaredLogger is adapted by FromSugared.
+package logging
+
+// Logger is a levelled structured logger.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// Nop is a Logger discarding all the messages, used when no logger is set.
+var Nop Logger = nop{}
+
+type nop struct{}
+
+func (nop) Debug(msg string, keyvals ...interface{}) {}
+func (nop) Info(msg string, keyvals ...interface{})  {}
+func (nop) Warn(msg string, keyvals ...interface{})  {}
+func (nop) Error(msg string, keyvals ...interface{}) {}
+
+// Or returns l, or Nop when l is nil.
+func Or(l Logger) Logger {
+	if l == nil {
+		return Nop
+	}
+	return l
+}
+
+// SugaredLogger is the subset of zap's *SugaredLogger used by FromSugared.
+type SugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// FromSugared adapts a zap SugaredLogger.
+func FromSugared(s SugaredLogger) Logger {
+	return sugared{s}
+}
+
+type sugared struct {
+	s SugaredLogger
+}
+
+func (l sugared) Debug(msg string, keyvals ...interface{}) { l.s.Debugw(msg, keyvals...) }
+func (l sugared) Info(msg string, keyvals ...interface{})  { l.s.Infow(msg, keyvals...) }
+func (l sugared) Warn(msg string, keyvals ...interface{})  { l.s.Warnw(msg, keyvals...) }
+func (l sugared) Error(msg string, keyvals ...interface{}) { l.s.Errorw(msg, keyvals...) }
+
+// With returns a Logger adding keyvals before the key-value pairs of each
+// message of l, such as the name of the module logging:
+//
+//	logger = logging.With(logger, "module", "relayer")
+func With(l Logger, keyvals ...interface{}) Logger {
+	return with{l: Or(l), keyvals: keyvals}
+}
+
+type with struct {
+	l       Logger
+	keyvals []interface{}
+}
+
+func (l with) Debug(msg string, keyvals ...interface{}) { l.l.Debug(msg, l.join(keyvals)...) }
+func (l with) Info(msg string, keyvals ...interface{})  { l.l.Info(msg, l.join(keyvals)...) }
+func (l with) Warn(msg string, keyvals ...interface{})  { l.l.Warn(msg, l.join(keyvals)...) }
+func (l with) Error(msg string, keyvals ...interface{}) { l.l.Error(msg, l.join(keyvals)...) }
+
+func (l with) join(keyvals []interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(l.keyvals)+len(keyvals)), l.keyvals...), keyvals...)
+}
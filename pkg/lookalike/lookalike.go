@@ -0,0 +1,151 @@
+// Package lookalike detects the addresses resembling known counterparties.
+//
+// Address poisoning attacks send dust from a vanity address sharing the first
+// and last characters of a real counterparty, hoping that the operator copies
+// it from the transaction history. Those are the characters people check, so
+// an address sharing them with a known address, without being it, is suspect.
+package lookalike
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// DefaultMinShared is the number of hex characters at the start and the end of
+// two addresses which makes them lookalikes, about one chance in 16 million
+// for unrelated addresses.
+const DefaultMinShared = 6
+
+// Counterparty is a known address.
+type Counterparty struct {
+	Address common.Address `json:"address"`
+	// Label tells where the address comes from, e.g. "claim recipient".
+	Label    string    `json:"label"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Match is a known counterparty resembling an address.
+type Match struct {
+	Counterparty
+	// Prefix and Suffix are the numbers of hex characters shared at the
+	// start and the end of the addresses.
+	Prefix int
+	Suffix int
+}
+
+// Check returns the counterparties sharing at least minShared hex characters,
+// counting those at the start and the end, with address without being it.
+// minShared defaults to DefaultMinShared when not positive.
+func Check(address common.Address, known []Counterparty, minShared int) []Match {
+	if minShared <= 0 {
+		minShared = DefaultMinShared
+	}
+	a := hexOf(address)
+	var matches []Match
+	seen := make(map[common.Address]bool)
+	for _, k := range known {
+		if k.Address == address || seen[k.Address] {
+			continue
+		}
+		b := hexOf(k.Address)
+		prefix, suffix := sharedPrefix(a, b), sharedSuffix(a, b)
+		if prefix+suffix >= minShared {
+			seen[k.Address] = true
+			matches = append(matches, Match{Counterparty: k, Prefix: prefix, Suffix: suffix})
+		}
+	}
+	return matches
+}
+
+func hexOf(a common.Address) string {
+	return strings.ToLower(a.Hex()[2:])
+}
+
+func sharedPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func sharedSuffix(a, b string) int {
+	n := 0
+	for n < len(a) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// Book is a file of the counterparties, as JSON lines.
+type Book struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewBook returns the book stored in path, which is created on the first
+// counterparty added.
+func NewBook(path string) *Book {
+	return &Book{path: path}
+}
+
+// Add records a counterparty.
+func (b *Book) Add(c Counterparty) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := os.OpenFile(b.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening address book")
+	}
+	err = json.NewEncoder(f).Encode(c)
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing address book")
+	}
+	return errors.Wrap(f.Close(), "writing address book")
+}
+
+// Recent returns the counterparties seen since the given time, the latest
+// record of each address only.
+func (b *Book) Recent(since time.Time) ([]Counterparty, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := os.Open(b.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening address book")
+	}
+	defer f.Close()
+
+	index := make(map[common.Address]int)
+	var recent []Counterparty
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		var c Counterparty
+		err := json.Unmarshal(s.Bytes(), &c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s line %d", b.path, line)
+		}
+		if c.LastSeen.Before(since) {
+			continue
+		}
+		if i, ok := index[c.Address]; ok {
+			recent[i] = c
+			continue
+		}
+		index[c.Address] = len(recent)
+		recent = append(recent, c)
+	}
+	return recent, errors.Wrap(s.Err(), "reading address book")
+}
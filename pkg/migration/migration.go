@@ -0,0 +1,322 @@
+// Package migration moves the wallets of a WalletDeployer to a newly deployed
+// one: the security settings of each old wallet, its spend and gas top-up
+// limits and its whitelist, are replayed by migrateWallet onto a wallet of
+// the new WalletCache assigned to the same owner.
+//
+// The wallets to migrate are those of a snapshot of the old deployer, see
+// package snapshot:
+//
+//	s, err := snapshot.Take(ctx, backend, snapshot.Contracts{WalletDeployer: old}, snapshot.Options{StartBlock: deployment})
+//	...
+//	m, err := migration.New(deployer, cache, backend, receipts, opts, "migration.journal.jsonl")
+//	...
+//	plan, err := m.Plan(ctx, s)
+//	...
+//	report, err := m.Run(ctx, plan)
+//
+// The migration is simulated first by DryRun. The outcome of each wallet is
+// appended to a journal. The owners assigned a wallet by the new deployer are
+// skipped, running the migration again resumes it. The report verifies the
+// settings of every new wallet on the chain.
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+)
+
+var parsedABI = bindings.WalletDeployerParsedABI
+
+// Limit is a daily limit of a wallet.
+type Limit struct {
+	// Set tells whether the owner set the limit, which is otherwise the
+	// default of the wallet and left to the default of the new one.
+	Set   bool     `json:"set"`
+	Value *big.Int `json:"value"`
+}
+
+// Step is the migration of a wallet.
+type Step struct {
+	Owner         common.Address `json:"owner"`
+	OldWallet     common.Address `json:"old_wallet"`
+	SpendLimit    Limit          `json:"spend_limit"`
+	GasTopUpLimit Limit          `json:"gas_top_up_limit"`
+	// Whitelist is nil when the owner did not set the whitelist.
+	Whitelist []common.Address `json:"whitelist"`
+}
+
+// Result is the outcome of a step, a line of the journal.
+type Result struct {
+	Owner     common.Address `json:"owner"`
+	OldWallet common.Address `json:"old_wallet"`
+	Wallet    common.Address `json:"wallet,omitempty"`
+	TxHash    common.Hash    `json:"tx_hash,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// Migrator migrates the wallets to a new WalletDeployer. The account of the
+// transactions must be a controller of the new deployer.
+type Migrator struct {
+	deployer *bindings.WalletDeployer
+	contract *bind.BoundContract
+	address  common.Address
+	cache    *bindings.WalletCache
+	backend  bind.ContractBackend
+	receipts bind.DeployBackend
+	opts     *bind.TransactOpts
+	journal  string
+
+	// Progress is called after each step with the number of steps done out
+	// of the total, the skipped ones included.
+	Progress func(done, total int, r Result)
+}
+
+// New returns a migrator to the deployer, whose wallets are cached by cache,
+// sending transactions with opts through backend, waiting for them in
+// receipts and journaling the outcomes in the journal file, created when
+// missing.
+func New(deployer, cache common.Address, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, journal string) (*Migrator, error) {
+	d, err := bindings.NewWalletDeployer(deployer, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet deployer contract")
+	}
+	c, err := bindings.NewWalletCache(cache, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet cache contract")
+	}
+	return &Migrator{deployer: d, contract: bind.NewBoundContract(deployer, parsedABI, backend, backend, backend), address: deployer, cache: c, backend: backend, receipts: receipts, opts: opts, journal: journal}, nil
+}
+
+// Plan reads the settings of the wallets of the snapshot, at the latest
+// block. The wallets are migrated to their current owner.
+func (m *Migrator) Plan(ctx context.Context, s *snapshot.Snapshot) ([]Step, error) {
+	steps := make([]Step, 0, len(s.Wallets))
+	for _, w := range s.Wallets {
+		step, err := ReadStep(ctx, m.backend, w.Address)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ReadStep reads the migration of the wallet from its current settings.
+func ReadStep(ctx context.Context, backend bind.ContractBackend, wallet common.Address) (Step, error) {
+	w, err := bindings.NewWallet(wallet, backend)
+	if err != nil {
+		return Step{}, errors.Wrap(err, "binding wallet contract")
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	s := Step{OldWallet: wallet}
+	s.Owner, err = w.Owner(opts)
+	if err == nil {
+		s.SpendLimit.Set, err = w.SpendLimitUpdateable(opts)
+	}
+	if err == nil {
+		s.SpendLimit.Value, err = w.SpendLimitValue(opts)
+	}
+	if err == nil {
+		s.GasTopUpLimit.Set, err = w.GasTopUpLimitUpdateable(opts)
+	}
+	if err == nil {
+		s.GasTopUpLimit.Value, err = w.GasTopUpLimitValue(opts)
+	}
+	var whitelisted bool
+	if err == nil {
+		whitelisted, err = w.IsSetWhitelist(opts)
+	}
+	if err == nil && whitelisted {
+		s.Whitelist, err = whitelist(opts, w)
+	}
+	if err != nil {
+		return Step{}, errors.Wrapf(err, "reading wallet %s", wallet.Hex())
+	}
+	return s, nil
+}
+
+// whitelist returns the whitelist of a wallet, which has no getter of its
+// length: its array is read until the call out of its range reverts.
+func whitelist(opts *bind.CallOpts, w *bindings.Wallet) ([]common.Address, error) {
+	list := []common.Address{}
+	for i := int64(0); ; i++ {
+		a, err := w.WhitelistArray(opts, big.NewInt(i))
+		if isRevert(err) {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+}
+
+// isRevert tells whether err is that of a call which reverted: the nodes
+// either fail the call or return an empty output.
+func isRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "unmarshall an empty string")
+}
+
+// pending returns the steps whose owner has no wallet of the new deployer.
+func (m *Migrator) pending(ctx context.Context, steps []Step) ([]Step, error) {
+	var pending []Step
+	for _, s := range steps {
+		w, err := m.deployer.DeployedWallets(&bind.CallOpts{Context: ctx}, s.Owner)
+		if err != nil {
+			return nil, errors.Wrap(err, "calling deployedWallets")
+		}
+		if w == (common.Address{}) {
+			pending = append(pending, s)
+		}
+	}
+	return pending, nil
+}
+
+// DryRun is the outcome of the simulation of a migration.
+type DryRun struct {
+	// Migrated is the number of owners with a wallet of the new deployer.
+	Migrated int `json:"migrated"`
+	// Pending are the steps to migrate, accepted by the new deployer.
+	Pending []Step `json:"pending"`
+	// Gas is the gas estimated for the pending steps.
+	Gas uint64 `json:"gas"`
+	// Cached is the number of wallets of the new cache. The migrations
+	// beyond them deploy their wallet, at a much higher gas cost.
+	Cached uint64 `json:"cached"`
+	// Rejected are the steps the new deployer would revert.
+	Rejected []Rejection `json:"rejected,omitempty"`
+}
+
+// Rejection is a step the new deployer would revert.
+type Rejection struct {
+	OldWallet common.Address `json:"old_wallet"`
+	Reason    string         `json:"reason"`
+}
+
+// DryRun simulates the migration of the steps not migrated yet, without
+// sending any transaction. Each step is estimated alone, the estimates of the
+// steps beyond the cached wallets are below their cost.
+func (m *Migrator) DryRun(ctx context.Context, steps []Step) (*DryRun, error) {
+	pending, err := m.pending(ctx, steps)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := m.cache.CachedWalletsCount(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "calling cachedWalletsCount")
+	}
+	d := &DryRun{Migrated: len(steps) - len(pending), Cached: cached.Uint64()}
+	for _, s := range pending {
+		input, err := parsedABI.Pack("migrateWallet", arguments(s)...)
+		if err != nil {
+			return nil, errors.Wrap(err, "packing migrateWallet")
+		}
+		gas, err := m.backend.EstimateGas(ctx, ethereum.CallMsg{From: m.opts.From, To: &m.address, Value: new(big.Int), Data: input})
+		if err != nil {
+			d.Rejected = append(d.Rejected, Rejection{OldWallet: s.OldWallet, Reason: err.Error()})
+			continue
+		}
+		d.Pending = append(d.Pending, s)
+		d.Gas += gas
+	}
+	return d, nil
+}
+
+// Run migrates the wallets whose owner has no wallet of the new deployer,
+// journaling the outcomes, and returns the report of the migration. A failed
+// step does not stop the migration, it is retried by the next run.
+func (m *Migrator) Run(ctx context.Context, steps []Step) (*Report, error) {
+	pending, err := m.pending(ctx, steps)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(m.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal")
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+
+	count := len(steps) - len(pending)
+	for _, s := range pending {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		r := Result{Owner: s.Owner, OldWallet: s.OldWallet}
+		r.Wallet, r.TxHash, err = m.migrate(ctx, s)
+		if err != nil {
+			r.Error = err.Error()
+		}
+		err = enc.Encode(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "writing journal")
+		}
+		count++
+		if m.Progress != nil {
+			m.Progress(count, len(steps), r)
+		}
+	}
+	return m.Verify(ctx, steps)
+}
+
+// migrate sends the migration of a wallet and returns the new wallet.
+func (m *Migrator) migrate(ctx context.Context, s Step) (common.Address, common.Hash, error) {
+	opts, cancel, err := session.NewOptsBuilder(m.opts).Build(ctx)
+	if err != nil {
+		return common.Address{}, common.Hash{}, err
+	}
+	defer cancel()
+	tx, err := m.contract.Transact(opts, "migrateWallet", arguments(s)...)
+	if err != nil {
+		return common.Address{}, common.Hash{}, errors.Wrap(err, "sending migrateWallet")
+	}
+	r, err := bind.WaitMined(ctx, m.receipts, tx)
+	if err != nil {
+		return common.Address{}, tx.Hash(), errors.Wrapf(err, "waiting for transaction %s", tx.Hash().Hex())
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		return common.Address{}, tx.Hash(), errors.Errorf("transaction %s failed", tx.Hash().Hex())
+	}
+	events, err := m.deployer.ParseMigratedWalletFromReceipt(m.address, r)
+	if err != nil {
+		return common.Address{}, tx.Hash(), errors.Wrap(err, "parsing MigratedWallet event")
+	}
+	for _, e := range events {
+		if e.Owner == s.Owner {
+			return e.Wallet, tx.Hash(), nil
+		}
+	}
+	return common.Address{}, tx.Hash(), errors.Errorf("no MigratedWallet event in transaction %s", tx.Hash().Hex())
+}
+
+// arguments returns the arguments of the migrateWallet call of the step.
+func arguments(s Step) []interface{} {
+	whitelist := s.Whitelist
+	if whitelist == nil {
+		whitelist = []common.Address{}
+	}
+	return []interface{}{s.Owner, s.OldWallet, s.SpendLimit.Set, s.GasTopUpLimit.Set, s.Whitelist != nil, zeroIfNil(s.SpendLimit.Value), zeroIfNil(s.GasTopUpLimit.Value), whitelist}
+}
+
+func zeroIfNil(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
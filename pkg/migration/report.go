@@ -0,0 +1,93 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Report verifies the wallets of the new deployer against the steps.
+type Report struct {
+	Wallets int `json:"wallets"`
+	// Verified is the number of owners whose new wallet holds the settings
+	// of their old one.
+	Verified int `json:"verified"`
+	// Missing are the steps whose owner has no wallet of the new deployer,
+	// to migrate again.
+	Missing []Step `json:"missing,omitempty"`
+	// Mismatched are the new wallets whose settings differ from the old
+	// ones.
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+// Mismatch is a new wallet whose settings differ from its old one.
+type Mismatch struct {
+	Owner     common.Address `json:"owner"`
+	OldWallet common.Address `json:"old_wallet"`
+	Wallet    common.Address `json:"wallet"`
+	Reason    string         `json:"reason"`
+}
+
+// Complete tells whether every wallet is verified.
+func (r *Report) Complete() bool {
+	return r.Verified == r.Wallets
+}
+
+// Verify returns the report of the migration of the steps, from the chain,
+// without migrating any wallet.
+func (m *Migrator) Verify(ctx context.Context, steps []Step) (*Report, error) {
+	report := &Report{Wallets: len(steps)}
+	for _, s := range steps {
+		w, err := m.deployer.DeployedWallets(&bind.CallOpts{Context: ctx}, s.Owner)
+		if err != nil {
+			return nil, errors.Wrap(err, "calling deployedWallets")
+		}
+		if w == (common.Address{}) {
+			report.Missing = append(report.Missing, s)
+			continue
+		}
+		migrated, err := ReadStep(ctx, m.backend, w)
+		if err != nil {
+			return nil, err
+		}
+		if reason := compare(s, migrated); reason != "" {
+			report.Mismatched = append(report.Mismatched, Mismatch{Owner: s.Owner, OldWallet: s.OldWallet, Wallet: w, Reason: reason})
+			continue
+		}
+		report.Verified++
+	}
+	return report, nil
+}
+
+// compare returns why the settings of the new wallet differ from the step,
+// empty when they do not. The limits not set by the owner are left to the
+// defaults of the new wallet.
+func compare(s, migrated Step) string {
+	if migrated.Owner != s.Owner {
+		return fmt.Sprintf("owner %s, expected %s", migrated.Owner.Hex(), s.Owner.Hex())
+	}
+	if s.SpendLimit.Set && (!migrated.SpendLimit.Set || migrated.SpendLimit.Value.Cmp(s.SpendLimit.Value) != 0) {
+		return fmt.Sprintf("spend limit %s, expected %s", migrated.SpendLimit.Value, s.SpendLimit.Value)
+	}
+	if s.GasTopUpLimit.Set && (!migrated.GasTopUpLimit.Set || migrated.GasTopUpLimit.Value.Cmp(s.GasTopUpLimit.Value) != 0) {
+		return fmt.Sprintf("gas top up limit %s, expected %s", migrated.GasTopUpLimit.Value, s.GasTopUpLimit.Value)
+	}
+	if s.Whitelist == nil {
+		return ""
+	}
+	if migrated.Whitelist == nil {
+		return "whitelist not set"
+	}
+	if len(migrated.Whitelist) != len(s.Whitelist) {
+		return fmt.Sprintf("%d whitelisted addresses, expected %d", len(migrated.Whitelist), len(s.Whitelist))
+	}
+	for i, a := range s.Whitelist {
+		if migrated.Whitelist[i] != a {
+			return fmt.Sprintf("whitelisted address %d is %s, expected %s", i, migrated.Whitelist[i].Hex(), a.Hex())
+		}
+	}
+	return ""
+}
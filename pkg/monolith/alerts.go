@@ -0,0 +1,39 @@
+package monolith
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// startAlerts evaluates the configured alert rules in the background. Metric
+// rules are evaluated over registry, which is nil when metrics are disabled.
+// The secrets of the notifiers are looked up with getenv.
+func startAlerts(ctx context.Context, cfg *Config, registry metrics.Registry, getenv func(string) string, logger logging.Logger) (*alert.Engine, error) {
+	rules, err := loadAlertRules(cfg, registry)
+	if err != nil {
+		return nil, err
+	}
+	e := alert.NewEngine(rules, rules.Notifiers(logger, getenv), registry, logger)
+	go e.Run(ctx)
+	return e, nil
+}
+
+// loadAlertRules reads the rules file, checking that the metrics and the
+// indexer the rules need are enabled.
+func loadAlertRules(cfg *Config, registry metrics.Registry) (*alert.Rules, error) {
+	rules, err := alert.LoadRulesFile(cfg.Alerts.RulesFile)
+	if err != nil {
+		return nil, err
+	}
+	if rules.MetricRules() && registry == nil {
+		return nil, errors.New("metric alert rules require metrics to be enabled")
+	}
+	if rules.EventRules() && !cfg.Indexer.Enabled {
+		return nil, errors.New("event alert rules require the indexer to be enabled")
+	}
+	return rules, nil
+}
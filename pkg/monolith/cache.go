@@ -0,0 +1,32 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/tokencard/contracts/v2/pkg/cache"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// startCallCache caches the contract calls made through backend, polling the
+// head of the chain in the background to drop the results at the latest block
+// when it changes.
+func startCallCache(ctx context.Context, cfg *Config, backend bind.ContractBackend, headers cache.HeaderReader, registry metrics.Registry, logger logging.Logger) *cache.Backend {
+	c := cache.NewBackend(backend, headers)
+	if cfg.CallCache.TTL > 0 {
+		c.TTL = time.Duration(cfg.CallCache.TTL)
+	}
+	if cfg.CallCache.Interval > 0 {
+		c.Interval = time.Duration(cfg.CallCache.Interval)
+	}
+	if cfg.CallCache.MaxEntries > 0 {
+		c.MaxEntries = cfg.CallCache.MaxEntries
+	}
+	c.Events = cfg.CallCache.Events
+	c.Registry = registry
+	c.Logger = logger
+	go c.Run(ctx)
+	return c
+}
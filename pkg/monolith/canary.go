@@ -0,0 +1,58 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// canaryContract is the name the canary token is indexed under.
+const canaryContract = "canary"
+
+// startCanary runs the canary in the background, returning the gate opened
+// once it passed. The index and alert steps are checked when the indexer and
+// the alerts are enabled.
+func startCanary(ctx context.Context, cfg *Config, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, idx *indexer.Indexer, alerts *alert.Engine, logger logging.Logger) (*canary.Gate, error) {
+	if opts == nil {
+		return nil, errors.New("the canary requires kms, keystore_dir or keystore_file to be set")
+	}
+
+	c := &canary.Canary{
+		Backend:  backend,
+		Receipts: receipts,
+		Opts:     opts,
+		Token:    cfg.Canary.Token,
+		Timeout:  time.Duration(cfg.Canary.Timeout),
+		Logger:   logger,
+	}
+	if idx != nil {
+		c.Indexed = canary.Indexed(idx.Store(), canaryContract)
+	}
+	if alerts != nil {
+		c.Alert = func(ctx context.Context, r canary.Report) error {
+			return alerts.Send(ctx, alert.Alert{
+				Rule:     "canary",
+				Severity: alert.Info,
+				Summary:  "canary transaction " + r.TxHash.Hex() + " passed, operations are enabled",
+				State:    alert.Firing,
+				Time:     time.Now(),
+			})
+		}
+	}
+
+	gate := &canary.Gate{}
+	go func() {
+		r, err := c.Run(ctx)
+		if err != nil {
+			logger.Error("Operations stay disabled", "err", err)
+		}
+		gate.Record(r)
+	}()
+	return gate, nil
+}
@@ -0,0 +1,716 @@
+package monolith
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/access"
+	"github.com/tokencard/contracts/v2/pkg/gas"
+	"github.com/tokencard/contracts/v2/pkg/legacy"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	"github.com/tokencard/contracts/v2/pkg/units"
+	"github.com/tokencard/contracts/v2/pkg/webhook"
+)
+
+// Config is the configuration of a Monolith, read from the JSON configuration
+// file of monolithd. Secrets are read from the environment variables named in
+// the configuration. The log level, the drift
+// spec, the alert rules, the webhook endpoints and the allowed CIDRs are
+// reloaded on SIGHUP, or when the configuration directory changes if
+// watch_config is set:
+//
+//	{
+//	  "watch_config": true,
+//	  "log_level": "info",
+//	  "log_format": "json",
+//	  "listen_address": ":8080",
+//	  "tls": {"cert_file": "/secrets/tls/tls.crt", "key_file": "/secrets/tls/tls.key", "ca_file": "/secrets/tls/clients-ca.crt"},
+//	  "allowed_cidrs": ["10.0.0.0/8", "127.0.0.1"],
+//	  "grpc": {"listen_address": ":9090"},
+//	  "rpc_url": "http://localhost:8545",
+//	  "chain_id": 1,
+//	  "failover": {"urls": ["https://backup.example"], "timeout": "10s", "retry_after": "30s", "interval": "15s", "max_lag": 5},
+//	  "keystore_dir": "/secrets/keystore",
+//	  "account": "0x...",
+//	  "password_env": "MONOLITHD_PASSWORD",
+//	  "api_keys_env": "MONOLITHD_API_KEYS",
+//	  "gas_strategy": "standard",
+//	  "gas": {"source": "station", "station_url": "https://ethgasstation.info/json/ethgasAPI.json", "min_price": "1000000000", "max_price": "200000000000"},
+//	  "max_tx_per_minute": 30,
+//	  "call_cache": {"enabled": true, "ttl": "15s", "interval": "4s", "max_entries": 10000, "events": ["licence.UpdatedLicenceAmount"]},
+//	  "log_filter": {"max_blocks": 10000, "attempts": 5, "backoff": "1s", "interval": "100ms"},
+//	  "metrics": true,
+//	  "tracing": {"enabled": true, "file": "/var/log/monolith/spans.jsonl"},
+//	  "method_defaults_file": "/etc/monolith/methods.json",
+//	  "drift": {"spec_file": "/etc/monolith/spec.yaml", "interval": "5m", "remediate": false},
+//	  "alerts": {"rules_file": "/etc/monolith/alerts.yaml", "confirmations": 0},
+//	  "indexer": {"enabled": true, "start_block": 9000000, "poll_interval": "15s", "reorg_depth": 12, "store_file": "/var/lib/monolith/events.jsonl", "fast_path": true, "tkn_events": true, "link_payouts": true},
+//	  "analytics": {"attribution_file": "/var/lib/monolith/referrers.csv"},
+//	  "slo": {"indexer_lag": {"max_blocks": 3, "target": 0.99, "window": "720h", "interval": "15s"}},
+//	  "webhooks": {
+//	    "endpoints": [
+//	      {"url": "https://crm/hooks", "secret_env": "CRM_WEBHOOK_SECRET", "events": ["controller.TransferredOwnership"]},
+//	      {"url": "https://accounting/hooks", "secret_env": "ACCOUNTING_WEBHOOK_SECRET", "events": ["licence.TransferredToTokenHolder"], "confirmations": 64},
+//	      {"url": "https://partner/hooks", "secret_env": "PARTNER_WEBHOOK_SECRET", "recipient_key": "<base64 X25519 public key>"}
+//	    ],
+//	    "attempts": 5,
+//	    "backoff": "1s",
+//	    "dead_letter_file": "/var/lib/monolith/webhooks.dead.jsonl"
+//	  },
+//	  "relayer": {
+//	    "enabled": true,
+//	    "allowance": "50000000000000000",
+//	    "holder_token": "0x...",
+//	    "ledger_file": "/var/lib/monolith/relayer.fees.jsonl",
+//	    "settle_interval": "15s"
+//	  },
+//	  "canary": {"enabled": true, "token": "0x...", "timeout": "5m"},
+//	  "read_only": {"check_interval": "30s"},
+//	  "dust": {"enabled": true, "books_file": "/var/lib/monolith/dust.jsonl", "start_block": 9000000, "interval": "5m"},
+//	  "invariants": {"enabled": true, "interval": "1m", "tkn_supply_cap": "100000000000000000000000000"},
+//	  "status_page": {
+//	    "enabled": true,
+//	    "interval": "1m",
+//	    "dir": "/var/www/status",
+//	    "s3": {"bucket": "status.example", "region": "eu-west-1", "prefix": "monolith/"},
+//	    "github": {"repo": "example/status", "branch": "gh-pages", "dir": "monolith", "token_env": "STATUS_GITHUB_TOKEN"}
+//	  },
+//	  "reconciliation": {"enabled": true, "api_url": "https://api.etherscan.io/api", "api_key_env": "ETHERSCAN_API_KEY", "start_block": 9000000, "confirmations": 64, "interval": "10m"},
+//	  "provisioning": {
+//	    "enabled": true,
+//	    "target": 20,
+//	    "batch_size": 5,
+//	    "interval": "1m",
+//	    "assignments_file": "/var/lib/monolith/wallets.jsonl"
+//	  },
+//	  "contracts": {
+//	    "controller": "0x...",
+//	    "licence": "0x...",
+//	    "tkn": "0x...",
+//	    "token_whitelist": "0x...",
+//	    "wallet_cache": "0x...",
+//	    "wallet_deployer": "0x..."
+//	  }
+//	}
+//
+// A single keystore_file can be set instead of keystore_dir and account, or
+// transactions can be signed by a secp256k1 key held in AWS KMS or Google
+// Cloud KMS:
+//
+//	"kms": {"provider": "aws", "region": "eu-west-1", "key_id": "alias/monolith-operator"}
+//	"kms": {"provider": "gcp", "key_id": "projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"}
+//
+// AWS credentials are read from the standard AWS_* environment variables, GCP
+// access tokens from the metadata server of the instance.
+//
+// rpc_url is the HTTP or WebSocket URL of the node, or the path of its IPC
+// endpoint: a Unix socket such as /var/lib/geth/geth.ipc, or a named pipe on
+// Windows, \\.\pipe\geth.ipc, written "\\\\.\\pipe\\geth.ipc" in JSON.
+// Windows has no SIGHUP, the configuration is only reloaded there when
+// watch_config is set.
+//
+// With failover urls, the calls to the node are balanced round robin across
+// rpc_url and them. A call failing or not answered within timeout fails over
+// to the next endpoint, and the failed endpoint is skipped for retry_after or
+// until the health check made every interval finds it answering and at most
+// max_lag blocks behind the others, see package failover. The health of the
+// endpoints is served on /rpc.
+//
+// The Monolith does not start when the node is not on the network of
+// chain_id, and checks it again before sending each transaction, see
+// registry.Guard. Without chain_id, the transactions are kept on the network
+// of the node on startup.
+//
+// gas_strategy scales the gas prices of the transactions, fast, standard or
+// slow, from the source of gas.source: the price suggested by the node by
+// default, "ewma" for a moving average of the median prices of the recent
+// blocks or "station" for an EthGasStation-style API, see package gas.
+//
+// max_tx_per_minute caps the transactions sent by each signer, so that a bug
+// cannot drain the gas funds before anyone notices. It is unlimited when zero.
+type Config struct {
+	WatchConfig        bool           `json:"watch_config"`
+	LogLevel           string         `json:"log_level"`
+	LogFormat          string         `json:"log_format"`
+	ListenAddress      string         `json:"listen_address"`
+	RPCURL             string         `json:"rpc_url"`
+	ChainID            uint64         `json:"chain_id"`
+	KeystoreFile       string         `json:"keystore_file"`
+	KeystoreDir        string         `json:"keystore_dir"`
+	Account            common.Address `json:"account"`
+	PasswordEnv        string         `json:"password_env"`
+	APIKeysEnv         string         `json:"api_keys_env"`
+	GasStrategy        string         `json:"gas_strategy"`
+	MaxTxPerMinute     int            `json:"max_tx_per_minute"`
+	MethodDefaultsFile string         `json:"method_defaults_file"`
+	// Gas selects the source of the gas prices, see above. The ewma source
+	// weights each new block by alpha and reads at most window blocks when
+	// catching up with the head, 0.2 and 20 by default. The station source
+	// queries station_url, gas.DefaultStationURL by default. The prices are
+	// bounded by min_price and max_price in wei when set.
+	Gas struct {
+		Source     string  `json:"source"`
+		Alpha      float64 `json:"alpha"`
+		Window     uint64  `json:"window"`
+		StationURL string  `json:"station_url"`
+		MinPrice   string  `json:"min_price"`
+		MaxPrice   string  `json:"max_price"`
+	} `json:"gas"`
+	Drift struct {
+		SpecFile string         `json:"spec_file"`
+		Interval txmgr.Duration `json:"interval"`
+		// Remediate sends the transactions bringing the contracts back to
+		// the spec when they drift, with the operator key.
+		Remediate bool `json:"remediate"`
+	} `json:"drift"`
+	Alerts struct {
+		RulesFile string `json:"rules_file"`
+		// Confirmations are the blocks mined on top of the block of an
+		// event before it is evaluated, none when zero.
+		Confirmations uint64 `json:"confirmations"`
+	} `json:"alerts"`
+	// Indexer indexes the events of the contracts, served on /graphql,
+	// /analytics, /analytics/licence-amount and, block by block, on
+	// /blocks/{number}, see package blockview.
+	Indexer struct {
+		Enabled      bool           `json:"enabled"`
+		StartBlock   uint64         `json:"start_block"`
+		PollInterval txmgr.Duration `json:"poll_interval"`
+		// ReorgDepth is the number of indexed blocks filtered again to remove
+		// the events of the reorganized blocks, none when zero.
+		ReorgDepth uint64 `json:"reorg_depth"`
+		// StoreFile persists the indexed events, which are kept in memory and
+		// indexed again from start_block on each start when empty.
+		StoreFile string `json:"store_file"`
+		// StoreDir persists the indexed events to segment files of a
+		// directory instead, read back only when queried, see
+		// indexer.SegmentStore.
+		StoreDir string `json:"store_dir"`
+		// FastPath hands the events of each new head to the alerts as soon
+		// as the node announces it, before they are indexed. The heads are
+		// subscribed to over rpc_url, which must be a websocket or IPC
+		// endpoint.
+		FastPath bool `json:"fast_path"`
+		// TKNEvents indexes the Transfer and Approval events of the token at
+		// contracts.tkn from and to the configured contracts, as the "tkn"
+		// contract. All the events of the token are filtered to find them.
+		// They are not handed by the fast path.
+		TKNEvents bool `json:"tkn_events"`
+		// LinkPayouts stores in each payout of an ERC20 token to the token
+		// holder the index of the Transfer log which paid it, read from the
+		// receipt of its transaction, see indexer.LinkPayouts.
+		LinkPayouts bool `json:"link_payouts"`
+		// Legacy writes the indexed events to the systems of a previous
+		// indexer as well during a migration, appended to file or posted
+		// to url in their schema, see package legacy:
+		//
+		//	"legacy": {"url": "https://queue.example/events", "schema": {"event_name": "name", "tx": "tx_hash"}}
+		//
+		// The events the legacy systems missed are written again with the
+		// next ones and the divergence is logged until they caught up. At
+		// most max_behind events are kept for them, 10000 by default.
+		Legacy struct {
+			File      string            `json:"file"`
+			URL       string            `json:"url"`
+			Schema    map[string]string `json:"schema"`
+			MaxBehind int               `json:"max_behind"`
+		} `json:"legacy"`
+	} `json:"indexer"`
+	// Analytics serves the statistics of the referrers of the wallets of
+	// attribution_file on /analytics/referrers, see package analytics.
+	Analytics struct {
+		AttributionFile string `json:"attribution_file"`
+	} `json:"analytics"`
+	SLO struct {
+		// IndexerLag is the objective of the number of blocks not indexed yet.
+		IndexerLag struct {
+			MaxBlocks uint64         `json:"max_blocks"`
+			Target    float64        `json:"target"`
+			Window    txmgr.Duration `json:"window"`
+			Interval  txmgr.Duration `json:"interval"`
+		} `json:"indexer_lag"`
+	} `json:"slo"`
+	Webhooks struct {
+		Endpoints []struct {
+			URL       string   `json:"url"`
+			SecretEnv string   `json:"secret_env"`
+			Events    []string `json:"events"`
+			// RecipientKey is the base64 encoded X25519 public key the
+			// deliveries are encrypted to.
+			RecipientKey string `json:"recipient_key"`
+			// Confirmations are the blocks mined on top of the block of an
+			// event before it is delivered, none when zero.
+			Confirmations uint64 `json:"confirmations"`
+		} `json:"endpoints"`
+		Attempts       int            `json:"attempts"`
+		Backoff        txmgr.Duration `json:"backoff"`
+		DeadLetterFile string         `json:"dead_letter_file"`
+	} `json:"webhooks"`
+	// Relayer relays the meta-transactions of the wallet owners with the
+	// operator key. The allowance is the total fee in wei relayed for each
+	// owner, the pending transactions counting at their gas limit,
+	// holder_token restricts the relayer to the holders of a token. The
+	// ledger_file journals the pending transactions as well, which are
+	// settled after a restart.
+	Relayer struct {
+		Enabled        bool           `json:"enabled"`
+		Allowance      string         `json:"allowance"`
+		HolderToken    common.Address `json:"holder_token"`
+		LedgerFile     string         `json:"ledger_file"`
+		SettleInterval txmgr.Duration `json:"settle_interval"`
+	} `json:"relayer"`
+	KMS struct {
+		Provider string `json:"provider"`
+		Region   string `json:"region"`
+		KeyID    string `json:"key_id"`
+		Endpoint string `json:"endpoint"`
+	} `json:"kms"`
+	// Canary sends a 1 token self-approval at startup and holds back the
+	// state changing API requests until it was signed, broadcast, mined,
+	// indexed and alerted on. It is meant to be enabled after deploying to a
+	// new network or rotating keys.
+	Canary struct {
+		Enabled bool           `json:"enabled"`
+		Token   common.Address `json:"token"`
+		Timeout txmgr.Duration `json:"timeout"`
+	} `json:"canary"`
+	// ReadOnly starts the Monolith in read-only mode when the signer of the
+	// operator key cannot be opened and account is set, instead of failing.
+	// The signer is checked again every check_interval, 30s by default, and
+	// the state changing requests are rejected with 503 Service Unavailable
+	// and the reason until it is available, see package readonly. The mode
+	// is served on /read-only.
+	ReadOnly struct {
+		CheckInterval txmgr.Duration `json:"check_interval"`
+	} `json:"read_only"`
+	// Dust watches the configured contracts for the ETH and tokens sent to
+	// them by mistake, recording them in books_file and alerting them when
+	// the alerts are enabled.
+	Dust struct {
+		Enabled    bool           `json:"enabled"`
+		BooksFile  string         `json:"books_file"`
+		StartBlock uint64         `json:"start_block"`
+		Interval   txmgr.Duration `json:"interval"`
+	} `json:"dust"`
+	// Invariants checks the invariants of the configured contracts every
+	// interval at the last indexed block, alerting their violations, see
+	// package invariant. The total supply of contracts.tkn is checked
+	// against tkn_supply_cap, in base units, when set. The indexer must
+	// index the contracts from their deployment on.
+	Invariants struct {
+		Enabled      bool           `json:"enabled"`
+		Interval     txmgr.Duration `json:"interval"`
+		TKNSupplyCap string         `json:"tkn_supply_cap"`
+	} `json:"invariants"`
+	// StatusPage publishes the static status page of the program every
+	// interval to dir, to the S3 bucket, with the credentials of the
+	// standard AWS_* environment variables, and to the branch of the GitHub
+	// repository served by GitHub Pages, see package status.
+	StatusPage struct {
+		Enabled  bool           `json:"enabled"`
+		Interval txmgr.Duration `json:"interval"`
+		Dir      string         `json:"dir"`
+		S3       struct {
+			Bucket   string `json:"bucket"`
+			Region   string `json:"region"`
+			Prefix   string `json:"prefix"`
+			Endpoint string `json:"endpoint"`
+		} `json:"s3"`
+		GitHub struct {
+			Repo     string `json:"repo"`
+			Branch   string `json:"branch"`
+			Dir      string `json:"dir"`
+			TokenEnv string `json:"token_env"`
+		} `json:"github"`
+	} `json:"status_page"`
+	// Reconciliation compares the indexed events with the logs of the
+	// explorer API at api_url every interval, from start_block to the
+	// indexed head minus confirmations, alerting the discrepancies, see
+	// package etherscan. The API key is read from api_key_env.
+	Reconciliation struct {
+		Enabled       bool           `json:"enabled"`
+		APIURL        string         `json:"api_url"`
+		APIKeyEnv     string         `json:"api_key_env"`
+		StartBlock    uint64         `json:"start_block"`
+		Confirmations uint64         `json:"confirmations"`
+		Interval      txmgr.Duration `json:"interval"`
+	} `json:"reconciliation"`
+	// Provisioning keeps target wallets cached, caching at most batch_size
+	// of them every interval, and assigns them to their owners on POST
+	// /wallets with the operator key, which must be a controller.
+	Provisioning struct {
+		Enabled         bool           `json:"enabled"`
+		Target          int            `json:"target"`
+		BatchSize       int            `json:"batch_size"`
+		Interval        txmgr.Duration `json:"interval"`
+		AssignmentsFile string         `json:"assignments_file"`
+	} `json:"provisioning"`
+	// TLS serves the API over TLS, requiring client certificates issued by
+	// the authorities of ca_file when it is set.
+	TLS access.TLSFiles `json:"tls"`
+	// AllowedCIDRs are the networks the API accepts connections from, all
+	// when empty.
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+	// GRPC serves the contract operations of the API over gRPC on
+	// listen_address as well, with the same API keys, TLS and allowed
+	// CIDRs, see package grpc.
+	GRPC struct {
+		ListenAddress string `json:"listen_address"`
+	} `json:"grpc"`
+	// Failover balances the calls to the node across rpc_url and the urls,
+	// see above.
+	Failover struct {
+		URLs       []string       `json:"urls"`
+		Timeout    txmgr.Duration `json:"timeout"`
+		RetryAfter txmgr.Duration `json:"retry_after"`
+		Interval   txmgr.Duration `json:"interval"`
+		MaxLag     uint64         `json:"max_lag"`
+	} `json:"failover"`
+	// CallCache caches the results of the contract calls for ttl, dropping
+	// the results at the latest block when the head polled every interval
+	// changes, or when the indexer sees one of the events of their contract,
+	// see package cache. Every event of the contract invalidates its results
+	// when events is empty.
+	CallCache struct {
+		Enabled    bool           `json:"enabled"`
+		TTL        txmgr.Duration `json:"ttl"`
+		Interval   txmgr.Duration `json:"interval"`
+		MaxEntries int            `json:"max_entries"`
+		Events     []string       `json:"events"`
+	} `json:"call_cache"`
+	// LogFilter sets how the log queries over large block ranges are split
+	// into chunks, see package logfilter.
+	LogFilter struct {
+		MaxBlocks uint64         `json:"max_blocks"`
+		Attempts  int            `json:"attempts"`
+		Backoff   txmgr.Duration `json:"backoff"`
+		Interval  txmgr.Duration `json:"interval"`
+	} `json:"log_filter"`
+	Metrics bool `json:"metrics"`
+	Tracing struct {
+		Enabled bool `json:"enabled"`
+		// File receives the spans, they are written to stderr when empty.
+		File string `json:"file"`
+	} `json:"tracing"`
+	Contracts struct {
+		Controller     common.Address `json:"controller"`
+		Licence        common.Address `json:"licence"`
+		TKN            common.Address `json:"tkn"`
+		TokenWhitelist common.Address `json:"token_whitelist"`
+		WalletCache    common.Address `json:"wallet_cache"`
+		WalletDeployer common.Address `json:"wallet_deployer"`
+	} `json:"contracts"`
+}
+
+// DefaultConfig returns the configuration with the defaults of the settings
+// left out of the configuration file.
+func DefaultConfig() *Config {
+	return &Config{
+		ListenAddress: ":8080",
+		PasswordEnv:   "MONOLITHD_PASSWORD",
+		APIKeysEnv:    "MONOLITHD_API_KEYS",
+	}
+}
+
+// LoadConfig reads and validates the configuration file at path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening configuration file")
+	}
+	defer f.Close()
+
+	cfg := DefaultConfig()
+	err = json.NewDecoder(f).Decode(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding configuration file %s", path)
+	}
+	if cfg.RPCURL == "" {
+		return nil, errors.New("rpc_url is not set in the configuration file")
+	}
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the consistency of the configuration. The rpc_url is only
+// required by LoadConfig, as programs embedding a Monolith may set its Node
+// instead.
+func (c *Config) Validate() error {
+	if c.MaxTxPerMinute < 0 {
+		return errors.New("max_tx_per_minute must not be negative")
+	}
+	err := c.validateGas()
+	if err != nil {
+		return err
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLS.CAFile != "" && c.TLS.CertFile == "" {
+		return errors.New("tls.ca_file requires tls.cert_file and tls.key_file")
+	}
+	_, err = access.NewAllowlist(c.AllowedCIDRs...)
+	if err != nil {
+		return errors.Wrap(err, "allowed_cidrs")
+	}
+	if c.GRPC.ListenAddress != "" && c.GRPC.ListenAddress == c.ListenAddress {
+		return errors.New("grpc.listen_address must differ from listen_address")
+	}
+	if c.Relayer.Allowance != "" {
+		_, err := units.ParseUint(c.Relayer.Allowance)
+		if err != nil {
+			return errors.Errorf("relayer.allowance %q is not a valid amount of wei", c.Relayer.Allowance)
+		}
+	}
+	if c.Provisioning.Enabled && (c.Contracts.WalletCache == (common.Address{}) || c.Contracts.WalletDeployer == (common.Address{})) {
+		return errors.New("provisioning requires contracts.wallet_cache and contracts.wallet_deployer to be set")
+	}
+	if c.Provisioning.Target < 0 {
+		return errors.New("provisioning.target must not be negative")
+	}
+	if c.Drift.Remediate && c.Drift.SpecFile == "" {
+		return errors.New("drift.remediate requires drift.spec_file to be set")
+	}
+	if c.Canary.Enabled && c.Canary.Token == (common.Address{}) {
+		return errors.New("canary.token is not set")
+	}
+	if c.ReadOnly.CheckInterval < 0 {
+		return errors.New("read_only.check_interval must not be negative")
+	}
+	if len(c.Webhooks.Endpoints) > 0 && !c.Indexer.Enabled {
+		return errors.New("webhooks require the indexer to be enabled")
+	}
+	for _, e := range c.Webhooks.Endpoints {
+		if e.RecipientKey == "" {
+			continue
+		}
+		_, err = webhook.ParsePublicKey(e.RecipientKey)
+		if err != nil {
+			return errors.Wrapf(err, "webhook endpoint %s", e.URL)
+		}
+	}
+	for _, u := range c.Failover.URLs {
+		if u == "" {
+			return errors.New("failover.urls must not hold an empty URL")
+		}
+	}
+	if c.CallCache.MaxEntries < 0 {
+		return errors.New("call_cache.max_entries must not be negative")
+	}
+	if c.LogFilter.Attempts < 0 {
+		return errors.New("log_filter.attempts must not be negative")
+	}
+	if c.Indexer.StoreFile != "" && c.Indexer.StoreDir != "" {
+		return errors.New("indexer.store_file and indexer.store_dir are exclusive")
+	}
+	if c.Indexer.FastPath {
+		switch {
+		case !c.Indexer.Enabled:
+			return errors.New("indexer.fast_path requires the indexer to be enabled")
+		case c.RPCURL == "" || strings.HasPrefix(c.RPCURL, "http://") || strings.HasPrefix(c.RPCURL, "https://"):
+			return errors.New("indexer.fast_path requires a websocket or IPC rpc_url")
+		case c.Alerts.Confirmations > 0:
+			return errors.New("indexer.fast_path hands the events before they are confirmed, alerts.confirmations must be zero")
+		}
+	}
+	if c.Indexer.TKNEvents {
+		switch {
+		case !c.Indexer.Enabled:
+			return errors.New("indexer.tkn_events requires the indexer to be enabled")
+		case c.Contracts.TKN == (common.Address{}):
+			return errors.New("indexer.tkn_events requires contracts.tkn to be set")
+		case c.Canary.Enabled && c.Canary.Token == c.Contracts.TKN:
+			return errors.New("indexer.tkn_events can not index the canary token")
+		}
+	}
+	if c.Indexer.LinkPayouts {
+		switch {
+		case !c.Indexer.Enabled:
+			return errors.New("indexer.link_payouts requires the indexer to be enabled")
+		case c.Contracts.Licence == (common.Address{}):
+			return errors.New("indexer.link_payouts requires contracts.licence to be set")
+		}
+	}
+	if l := c.Indexer.Legacy; l.File != "" || l.URL != "" {
+		switch {
+		case !c.Indexer.Enabled:
+			return errors.New("indexer.legacy requires the indexer to be enabled")
+		case l.File != "" && l.URL != "":
+			return errors.New("indexer.legacy.file and indexer.legacy.url are exclusive")
+		case l.MaxBehind < 0:
+			return errors.New("indexer.legacy.max_behind must not be negative")
+		}
+		err := legacy.Schema(l.Schema).Validate()
+		if err != nil {
+			return errors.Wrap(err, "indexer.legacy.schema")
+		}
+	}
+	if c.Invariants.Enabled && !c.Indexer.Enabled {
+		return errors.New("invariants require the indexer to be enabled")
+	}
+	if c.Invariants.TKNSupplyCap != "" {
+		_, err := units.ParseUint(c.Invariants.TKNSupplyCap)
+		switch {
+		case err != nil:
+			return errors.Errorf("invariants.tkn_supply_cap %q is not a valid amount", c.Invariants.TKNSupplyCap)
+		case c.Contracts.TKN == (common.Address{}):
+			return errors.New("invariants.tkn_supply_cap requires contracts.tkn to be set")
+		}
+	}
+	if c.StatusPage.Enabled {
+		p := c.StatusPage
+		switch {
+		case p.Dir == "" && p.S3.Bucket == "" && p.GitHub.Repo == "":
+			return errors.New("status_page requires dir, s3.bucket or github.repo to be set")
+		case p.S3.Bucket != "" && p.S3.Region == "":
+			return errors.New("status_page.s3.region is not set")
+		case p.GitHub.Repo != "" && (p.GitHub.Branch == "" || p.GitHub.TokenEnv == ""):
+			return errors.New("status_page.github requires branch and token_env to be set")
+		}
+	}
+	if c.Reconciliation.Enabled && !c.Indexer.Enabled {
+		return errors.New("reconciliation requires the indexer to be enabled")
+	}
+	if c.Analytics.AttributionFile != "" && !c.Indexer.Enabled {
+		return errors.New("analytics.attribution_file requires the indexer to be enabled")
+	}
+	if c.SLO.IndexerLag.Target > 0 && !c.Indexer.Enabled {
+		return errors.New("the indexer_lag objective requires the indexer to be enabled")
+	}
+	if c.SLO.IndexerLag.Target >= 1 {
+		return errors.New("the target of the indexer_lag objective must be below 1")
+	}
+	return nil
+}
+
+// validateGas checks the gas price settings.
+func (c *Config) validateGas() error {
+	_, err := gas.ParseStrategy(c.GasStrategy)
+	if err != nil {
+		return errors.Wrap(err, "gas_strategy")
+	}
+	g := c.Gas
+	switch g.Source {
+	case "", gasSourceNode, gasSourceStation:
+	case gasSourceEWMA:
+		if len(c.Failover.URLs) > 0 {
+			return errors.New("gas.source ewma reads the blocks of the node, it can not be used with failover.urls")
+		}
+	default:
+		return errors.Errorf("gas.source %q is not node, ewma or station", g.Source)
+	}
+	if g.Alpha < 0 || g.Alpha > 1 {
+		return errors.Errorf("gas.alpha %v is out of range (0, 1]", g.Alpha)
+	}
+	if (g.Alpha != 0 || g.Window != 0) && g.Source != gasSourceEWMA {
+		return errors.New("gas.alpha and gas.window require gas.source ewma")
+	}
+	if g.StationURL != "" {
+		if g.Source != gasSourceStation {
+			return errors.New("gas.station_url requires gas.source station")
+		}
+		u, err := url.Parse(g.StationURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("gas.station_url %q is not an HTTP URL", g.StationURL)
+		}
+	}
+	min, max, err := c.gasPriceBounds()
+	if err != nil {
+		return err
+	}
+	if min != nil && max != nil && min.Cmp(max) > 0 {
+		return errors.New("gas.min_price must not be above gas.max_price")
+	}
+	return nil
+}
+
+// gasPriceBounds returns the bounds of the gas prices, nil when they are not
+// set.
+func (c *Config) gasPriceBounds() (min, max *big.Int, err error) {
+	if c.Gas.MinPrice != "" {
+		min, err = units.ParseUint(c.Gas.MinPrice)
+		if err != nil {
+			return nil, nil, errors.Errorf("gas.min_price %q is not a valid amount of wei", c.Gas.MinPrice)
+		}
+	}
+	if c.Gas.MaxPrice != "" {
+		max, err = units.ParseUint(c.Gas.MaxPrice)
+		if err != nil {
+			return nil, nil, errors.Errorf("gas.max_price %q is not a valid amount of wei", c.Gas.MaxPrice)
+		}
+	}
+	return min, max, nil
+}
+
+// apiKeys returns the comma separated API keys set in the environment.
+func (c *Config) apiKeys(getenv func(string) string) []string {
+	var keys []string
+	for _, k := range strings.Split(getenv(c.APIKeysEnv), ",") {
+		k = strings.TrimSpace(k)
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// restartRequired returns the settings changed in next which are only applied
+// on startup.
+func (c *Config) restartRequired(next *Config) []string {
+	var changed []string
+	check := func(name string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			changed = append(changed, name)
+		}
+	}
+	check("listen_address", c.ListenAddress, next.ListenAddress)
+	check("tls", c.TLS, next.TLS)
+	check("grpc", c.GRPC, next.GRPC)
+	check("rpc_url", c.RPCURL, next.RPCURL)
+	check("chain_id", c.ChainID, next.ChainID)
+	check("failover", c.Failover, next.Failover)
+	check("keystore_file", c.KeystoreFile, next.KeystoreFile)
+	check("keystore_dir", c.KeystoreDir, next.KeystoreDir)
+	check("account", c.Account, next.Account)
+	check("kms", c.KMS, next.KMS)
+	check("relayer", c.Relayer, next.Relayer)
+	check("provisioning", c.Provisioning, next.Provisioning)
+	check("canary", c.Canary, next.Canary)
+	check("read_only", c.ReadOnly, next.ReadOnly)
+	check("reconciliation", c.Reconciliation, next.Reconciliation)
+	check("status_page", c.StatusPage, next.StatusPage)
+	check("invariants", c.Invariants, next.Invariants)
+	check("password_env", c.PasswordEnv, next.PasswordEnv)
+	check("api_keys_env", c.APIKeysEnv, next.APIKeysEnv)
+	check("gas_strategy", c.GasStrategy, next.GasStrategy)
+	check("gas", c.Gas, next.Gas)
+	check("max_tx_per_minute", c.MaxTxPerMinute, next.MaxTxPerMinute)
+	check("metrics", c.Metrics, next.Metrics)
+	check("tracing", c.Tracing, next.Tracing)
+	check("log_filter", c.LogFilter, next.LogFilter)
+	check("call_cache", c.CallCache, next.CallCache)
+	check("method_defaults_file", c.MethodDefaultsFile, next.MethodDefaultsFile)
+	check("drift.interval", c.Drift.Interval, next.Drift.Interval)
+	check("drift.remediate", c.Drift.Remediate, next.Drift.Remediate)
+	check("alerts.confirmations", c.Alerts.Confirmations, next.Alerts.Confirmations)
+	check("indexer", c.Indexer, next.Indexer)
+	check("analytics", c.Analytics, next.Analytics)
+	check("slo", c.SLO, next.SLO)
+	check("contracts", c.Contracts, next.Contracts)
+	check("webhooks.attempts", c.Webhooks.Attempts, next.Webhooks.Attempts)
+	check("webhooks.backoff", c.Webhooks.Backoff, next.Webhooks.Backoff)
+	check("webhooks.dead_letter_file", c.Webhooks.DeadLetterFile, next.Webhooks.DeadLetterFile)
+	// Enabling or disabling a subsystem starts or stops background work.
+	check("drift.spec_file", c.Drift.SpecFile == "", next.Drift.SpecFile == "")
+	check("webhooks.endpoints", len(c.Webhooks.Endpoints) == 0, len(next.Webhooks.Endpoints) == 0)
+	return changed
+}
@@ -0,0 +1,30 @@
+// Package monolith is the composition root of the service layer run by
+// monolithd: the API and the relayer, provisioning, indexer, alerts,
+// webhooks, drift, dust, reconciliation, status page, canary and SLO
+// subsystems enabled in a Config.
+//
+// Programs embed it as a library, serving its handler along their own routes
+// and injecting the dependencies they already hold:
+//
+//	cfg := monolith.DefaultConfig()
+//	cfg.Contracts.Licence = licence
+//	cfg.Indexer.Enabled = true
+//
+//	m := monolith.New(cfg)
+//	m.Node = client
+//	m.Logger = logger
+//	m.Getenv = lookupSecret
+//	if err := m.Start(ctx); err != nil {
+//		return err
+//	}
+//	mux.Handle("/monolith/", http.StripPrefix("/monolith", m.Handler()))
+//
+// The subsystems run until the context given to Start is done. Each Monolith
+// holds all of its state, such as its metrics registry and its loggers, and
+// reads neither the flags nor the signals of the process; the environment is
+// only read through Getenv. The exception is go-ethereum's metrics.Enabled
+// switch, which go-ethereum requires to record any metric: it is set for the
+// whole process by the first Monolith with metrics enabled.
+//
+// The stable clients of the contracts are in the monolith/v1 package.
+package monolith
@@ -0,0 +1,100 @@
+package monolith
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/reconcile"
+)
+
+const defaultDriftInterval = 5 * time.Minute
+
+// startDriftDetector checks the contracts against the configured spec in the
+// background. The drifts are logged, and alerted as warnings when the alerts
+// are enabled. With drift.remediate, the transactions bringing the contracts
+// back to the spec are signed with opts.
+func startDriftDetector(ctx context.Context, cfg *Config, backend bind.ContractBackend, opts *bind.TransactOpts, alerts *alert.Engine, logger logging.Logger) (*reconcile.Detector, error) {
+	if cfg.Drift.Remediate && opts == nil {
+		return nil, errors.New("drift.remediate requires kms, keystore_dir or keystore_file to be set")
+	}
+	spec, err := loadDriftSpec(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := reconcile.New(backend, reconcile.Contracts{
+		Licence:        cfg.Contracts.Licence,
+		Controller:     cfg.Contracts.Controller,
+		TokenWhitelist: cfg.Contracts.TokenWhitelist,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	interval := time.Duration(cfg.Drift.Interval)
+	if interval <= 0 {
+		interval = defaultDriftInterval
+	}
+
+	logAlerter := reconcile.LogAlerter{Logger: logger}
+	d := &reconcile.Detector{
+		Reconciler: r,
+		Spec:       spec,
+		Interval:   interval,
+		Alerter: reconcile.AlerterFunc(func(ctx context.Context, drift reconcile.Drift) error {
+			logAlerter.Alert(ctx, drift)
+			if alerts == nil {
+				return nil
+			}
+			return alerts.Send(ctx, driftAlert(drift))
+		}),
+		Logger: logger,
+	}
+	if cfg.Drift.Remediate {
+		d.Remediate = opts
+	}
+	go d.Run(ctx)
+	return d, nil
+}
+
+// driftAlert returns the alert of a drift, resolved once the contracts match
+// the spec again.
+func driftAlert(d reconcile.Drift) alert.Alert {
+	a := alert.Alert{
+		Rule:     "drift",
+		Severity: alert.Warning,
+		State:    alert.Firing,
+		Time:     d.Time,
+	}
+	if d.Resolved() {
+		a.State = alert.Resolved
+		a.Summary = "contracts match the spec again"
+		return a
+	}
+	actions := make([]string, len(d.Actions))
+	for i, action := range d.Actions {
+		actions[i] = action.String()
+	}
+	verb := "needed"
+	if len(d.Transactions) == len(d.Actions) {
+		verb = "remediated"
+	}
+	a.Summary = fmt.Sprintf("contracts drifted from the spec, %d action(s) %s: %s", len(actions), verb, strings.Join(actions, "; "))
+	return a
+}
+
+func loadDriftSpec(cfg *Config) (*reconcile.Spec, error) {
+	f, err := os.Open(cfg.Drift.SpecFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening drift spec")
+	}
+	defer f.Close()
+	return reconcile.LoadSpec(f)
+}
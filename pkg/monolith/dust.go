@@ -0,0 +1,89 @@
+package monolith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/dust"
+)
+
+const defaultDustInterval = 5 * time.Minute
+
+// startDustDetector watches the configured contracts for dust in the
+// background. None of them is meant to hold ETH or tokens, and all of them
+// can claim ETH and ERC20 tokens back. The findings are logged to output.
+func startDustDetector(ctx context.Context, cfg *Config, backend dust.Backend, alerts *alert.Engine, output io.Writer) *dust.Detector {
+	var contracts []dust.Contract
+	for name, address := range map[string]common.Address{
+		"controller":      cfg.Contracts.Controller,
+		"licence":         cfg.Contracts.Licence,
+		"token_whitelist": cfg.Contracts.TokenWhitelist,
+	} {
+		if address != (common.Address{}) {
+			contracts = append(contracts, dust.Contract{Name: name, Address: address, Claimable: true})
+		}
+	}
+
+	interval := time.Duration(cfg.Dust.Interval)
+	if interval <= 0 {
+		interval = defaultDustInterval
+	}
+	var books dust.Books = &dust.MemoryBooks{}
+	if cfg.Dust.BooksFile != "" {
+		books = dust.NewFileBooks(cfg.Dust.BooksFile)
+	}
+
+	logger := log.New(output, "dust: ", log.LstdFlags)
+	d := &dust.Detector{
+		Backend:    backend,
+		Contracts:  contracts,
+		StartBlock: cfg.Dust.StartBlock,
+		Interval:   interval,
+		Books:      books,
+		Alert: func(ctx context.Context, findings []dust.Finding) error {
+			for _, f := range findings {
+				logger.Printf("%s received %s", f.Contract, describeDust(f))
+				if alerts == nil {
+					continue
+				}
+				err := alerts.Send(ctx, alert.Alert{
+					Rule:     "dust",
+					Severity: dustSeverity(f),
+					Summary:  fmt.Sprintf("%s %s received %s", f.Contract, f.Address.Hex(), describeDust(f)),
+					State:    alert.Firing,
+					Time:     f.Time,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		ErrorLog: logger,
+	}
+	go d.Run(ctx)
+	return d
+}
+
+func describeDust(f dust.Finding) string {
+	switch f.Standard {
+	case dust.ETH:
+		return fmt.Sprintf("%s wei", f.Amount)
+	case dust.ERC721:
+		return fmt.Sprintf("ERC721 token %s of %s in transaction %s", f.Amount, f.Asset.Hex(), f.TxHash.Hex())
+	}
+	return fmt.Sprintf("%s of ERC20 token %s in transaction %s", f.Amount, f.Asset.Hex(), f.TxHash.Hex())
+}
+
+// dustSeverity raises the dust which cannot be claimed back as critical.
+func dustSeverity(f dust.Finding) alert.Severity {
+	if f.Recoverable {
+		return alert.Warning
+	}
+	return alert.Critical
+}
@@ -0,0 +1,38 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/tokencard/contracts/v2/pkg/failover"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// dialFailover connects to rpc_url and the failover URLs, balancing the calls
+// across them and checking their health in the background until the context
+// is done, when they are closed.
+func dialFailover(ctx context.Context, cfg *Config, logger logging.Logger) (*failover.Backend, error) {
+	urls := append([]string{cfg.RPCURL}, cfg.Failover.URLs...)
+	b, err := failover.DialURLs(ctx, urls...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Failover.Timeout > 0 {
+		b.Timeout = time.Duration(cfg.Failover.Timeout)
+	}
+	if cfg.Failover.RetryAfter > 0 {
+		b.RetryAfter = time.Duration(cfg.Failover.RetryAfter)
+	}
+	if cfg.Failover.Interval > 0 {
+		b.Interval = time.Duration(cfg.Failover.Interval)
+	}
+	if cfg.Failover.MaxLag > 0 {
+		b.MaxLag = cfg.Failover.MaxLag
+	}
+	b.Logger = logger
+	go func() {
+		b.Run(ctx)
+		b.Close()
+	}()
+	return b, nil
+}
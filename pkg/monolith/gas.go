@@ -0,0 +1,62 @@
+package monolith
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/gas"
+)
+
+// The sources of the gas prices of gas.source.
+const (
+	gasSourceNode    = "node"
+	gasSourceEWMA    = "ewma"
+	gasSourceStation = "station"
+)
+
+// newGasOracle returns the oracle pricing the transactions with the source
+// and the strategy configured, node is the node the Monolith runs against.
+func newGasOracle(cfg *Config, node Node) (*gas.Oracle, error) {
+	strategy, err := gas.ParseStrategy(cfg.GasStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var source gas.Source
+	switch cfg.Gas.Source {
+	case "", gasSourceNode:
+		source = gas.NewNodeSource(node)
+	case gasSourceEWMA:
+		blocks, ok := node.(gas.BlockReader)
+		if !ok {
+			return nil, errors.New("gas.source ewma requires a node returning its blocks")
+		}
+		alpha, window := cfg.Gas.Alpha, cfg.Gas.Window
+		if alpha == 0 {
+			alpha = gas.DefaultEWMAAlpha
+		}
+		if window == 0 {
+			window = gas.DefaultEWMAWindow
+		}
+		source, err = gas.NewEWMASource(blocks, alpha, window)
+		if err != nil {
+			return nil, err
+		}
+	case gasSourceStation:
+		url := cfg.Gas.StationURL
+		if url == "" {
+			url = gas.DefaultStationURL
+		}
+		source = gas.NewStationSource(url, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return nil, errors.Errorf("gas.source %q is not node, ewma or station", cfg.Gas.Source)
+	}
+
+	o := gas.NewOracle(source, strategy)
+	o.Min, o.Max, err = cfg.gasPriceBounds()
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,58 @@
+package monolith
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/api"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	grpcapi "github.com/tokencard/contracts/v2/pkg/grpc"
+	"github.com/tokencard/contracts/v2/pkg/readonly"
+	"google.golang.org/grpc"
+)
+
+// newGRPCServer returns the gRPC server of the contract operations served by
+// the API. Like the state changing API requests, the mutating calls are
+// refused in read-only mode and until the canary passed.
+func newGRPCServer(cfg *Config, backend bind.ContractBackend, apiCfg api.Config, guard *readonly.Guard, gate *canary.Gate) (*grpc.Server, error) {
+	s, err := grpcapi.New(backend, grpcapi.Config{
+		Licence:        apiCfg.Licence,
+		TokenWhitelist: apiCfg.TokenWhitelist,
+		Controller:     apiCfg.Controller,
+		APIKeys:        apiCfg.APIKeys,
+		TransactOpts:   apiCfg.TransactOpts,
+		Signer:         apiCfg.Signer,
+		Writable: func() error {
+			if guard != nil {
+				err := guard.Err()
+				if err != nil {
+					return err
+				}
+			}
+			if gate != nil && !gate.Open() {
+				return errors.New("operations are disabled until the canary transaction passes")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grpcapi.NewServer(s), nil
+}
+
+// stopGRPC stops the server once the pending calls returned, cancelling them
+// when the context is done first.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
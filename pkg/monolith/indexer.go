@@ -0,0 +1,181 @@
+package monolith
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/legacy"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+const defaultIndexerPollInterval = 15 * time.Second
+
+// defaultLegacyMaxBehind is the number of events kept for the legacy systems
+// while they fail.
+const defaultLegacyMaxBehind = 10000
+
+// tknContract is the name the events of TKN are indexed under.
+const tknContract = "tkn"
+
+// indexedContracts returns the configured contracts whose events are
+// indexed.
+func indexedContracts(cfg *Config) ([]indexer.Contract, error) {
+	addresses := map[string]common.Address{
+		"controller":      cfg.Contracts.Controller,
+		"licence":         cfg.Contracts.Licence,
+		"token_whitelist": cfg.Contracts.TokenWhitelist,
+	}
+
+	var contracts []indexer.Contract
+	for name, address := range addresses {
+		if address == (common.Address{}) {
+			continue
+		}
+		contracts = append(contracts, indexer.Contract{Name: name, Address: address, ABI: bindings.ContractParsedABIs[name]})
+	}
+	if cfg.Canary.Enabled {
+		parsed, err := abi.JSON(strings.NewReader(canary.TokenABI))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing canary token ABI")
+		}
+		contracts = append(contracts, indexer.Contract{Name: canaryContract, Address: cfg.Canary.Token, ABI: parsed})
+	}
+	return contracts, nil
+}
+
+// startIndexer indexes the events of the configured contracts in the
+// background, reading the receipts of the payouts it links from receipts.
+func startIndexer(ctx context.Context, cfg *Config, backend indexer.Backend, receipts indexer.ReceiptReader, logger logging.Logger, handlers ...indexer.Handler) (*indexer.Indexer, error) {
+	contracts, err := indexedContracts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var store indexer.Store = indexer.NewMemoryStore()
+	if cfg.Indexer.StoreFile != "" {
+		f, err := indexer.OpenFileStore(cfg.Indexer.StoreFile)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			f.Close()
+		}()
+		store = f
+	}
+	if cfg.Indexer.StoreDir != "" {
+		d, err := indexer.OpenSegmentStore(cfg.Indexer.StoreDir)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			d.Close()
+		}()
+		store = d
+	}
+
+	if l := cfg.Indexer.Legacy; l.File != "" || l.URL != "" {
+		store, err = mirrorLegacy(ctx, cfg, store, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hooks []indexer.Hook
+	if cfg.Indexer.TKNEvents {
+		parsed, err := abi.JSON(strings.NewReader(indexer.ERC20ABI))
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing ERC20 ABI")
+		}
+		// The movements of TKN are kept when they involve the contracts
+		// indexed, the wallets and the relayer account are not followed.
+		involved := []common.Address{cfg.Contracts.WalletCache, cfg.Contracts.WalletDeployer}
+		for _, c := range contracts {
+			involved = append(involved, c.Address)
+		}
+		contracts = append(contracts, indexer.Contract{Name: tknContract, Address: cfg.Contracts.TKN, ABI: parsed})
+		hooks = append(hooks, indexer.Involving(tknContract, involved...))
+	}
+
+	if cfg.Indexer.LinkPayouts {
+		hooks = append(hooks, indexer.LinkPayouts("licence", receipts))
+	}
+
+	idx := indexer.New(backend, store, contracts...)
+	idx.Hooks = hooks
+	idx.StartBlock = cfg.Indexer.StartBlock
+	idx.ReorgDepth = cfg.Indexer.ReorgDepth
+	idx.Handlers = handlers
+	idx.Logger = logger
+	idx.PollInterval = time.Duration(cfg.Indexer.PollInterval)
+	if idx.PollInterval <= 0 {
+		idx.PollInterval = defaultIndexerPollInterval
+	}
+
+	go idx.Run(ctx)
+	return idx, nil
+}
+
+// mirrorLegacy returns the store writing the events of store to the legacy
+// systems as well, logging their divergence.
+func mirrorLegacy(ctx context.Context, cfg *Config, store indexer.Store, logger logging.Logger) (indexer.Store, error) {
+	l := cfg.Indexer.Legacy
+	var w indexer.Legacy = &legacy.Queue{URL: l.URL, Schema: l.Schema}
+	if l.File != "" {
+		f, err := legacy.OpenFile(l.File, l.Schema)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			f.Close()
+		}()
+		w = f
+	}
+	m := indexer.NewMirror(store, w)
+	m.MaxBehind = l.MaxBehind
+	if m.MaxBehind == 0 {
+		m.MaxBehind = defaultLegacyMaxBehind
+	}
+	m.Report = func(d indexer.Divergence) {
+		if d.Err != nil {
+			logger.Warn("Legacy systems diverged", "head", d.Head, "behind", d.Behind, "err", d.Err)
+			return
+		}
+		logger.Info("Legacy systems caught up", "head", d.Head)
+	}
+	return m, nil
+}
+
+// startHeadFeed hands the events of the new heads to the handlers in the
+// background, over a connection of its own to rpc_url so that the
+// subscription is not held up by the calls of the other subsystems.
+func startHeadFeed(ctx context.Context, cfg *Config, logger logging.Logger, handlers ...indexer.Handler) error {
+	contracts, err := indexedContracts(cfg)
+	if err != nil {
+		return err
+	}
+	c, err := ethclient.DialContext(ctx, cfg.RPCURL)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to %s", cfg.RPCURL)
+	}
+	go func() {
+		<-ctx.Done()
+		c.Close()
+	}()
+
+	feed := indexer.NewHeadFeed(c, contracts...)
+	feed.Handlers = handlers
+	feed.Logger = logger
+	go feed.Run(ctx)
+	return nil
+}
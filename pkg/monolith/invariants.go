@@ -0,0 +1,91 @@
+package monolith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/invariant"
+)
+
+const defaultInvariantsInterval = time.Minute
+
+// startInvariants checks the invariants of the configured contracts in the
+// background, against the events of store. The violations are logged to
+// output and alerted as critical.
+func startInvariants(ctx context.Context, cfg *Config, backend bind.ContractBackend, store indexer.Store, alerts *alert.Engine, output io.Writer) (*invariant.Monitor, error) {
+	var invariants []invariant.Invariant
+	if cfg.Contracts.Licence != (common.Address{}) {
+		c, err := bindings.NewLicenceCaller(cfg.Contracts.Licence, backend)
+		if err != nil {
+			return nil, err
+		}
+		invariants = append(invariants, invariant.LicenceAmount(c, "licence"))
+	}
+	if cfg.Contracts.TokenWhitelist != (common.Address{}) {
+		c, err := bindings.NewTokenWhitelistCaller(cfg.Contracts.TokenWhitelist, backend)
+		if err != nil {
+			return nil, err
+		}
+		invariants = append(invariants, invariant.TokenWhitelist(c, "token_whitelist"))
+	}
+	if cfg.Contracts.Controller != (common.Address{}) {
+		c, err := bindings.NewControllerCaller(cfg.Contracts.Controller, backend)
+		if err != nil {
+			return nil, err
+		}
+		invariants = append(invariants, invariant.ControllerRoles(c, "controller"))
+	}
+	if cfg.Contracts.TKN != (common.Address{}) {
+		var cap *big.Int
+		if cfg.Invariants.TKNSupplyCap != "" {
+			cap, _ = new(big.Int).SetString(cfg.Invariants.TKNSupplyCap, 10)
+		}
+		invariants = append(invariants, invariant.TKNSupply(cfg.Contracts.TKN, backend, cap))
+	}
+
+	interval := time.Duration(cfg.Invariants.Interval)
+	if interval <= 0 {
+		interval = defaultInvariantsInterval
+	}
+	logger := log.New(output, "invariants: ", log.LstdFlags)
+	m := &invariant.Monitor{
+		Store:      store,
+		Invariants: invariants,
+		Interval:   interval,
+		Alert: func(ctx context.Context, violations []invariant.Violation) error {
+			for _, v := range violations {
+				state := alert.Firing
+				if v.Resolved {
+					state = alert.Resolved
+				}
+				logger.Printf("%s %s at block %d: %s", v.Invariant, state, v.BlockNumber, v.Detail)
+				if alerts == nil {
+					continue
+				}
+				err := alerts.Send(ctx, alert.Alert{
+					Rule:     "invariant_" + v.Invariant,
+					Severity: alert.Critical,
+					Summary:  fmt.Sprintf("%s at block %d", v.Detail, v.BlockNumber),
+					State:    state,
+					Time:     v.Time,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		ErrorLog: logger,
+	}
+	go m.Run(ctx)
+	return m, nil
+}
@@ -0,0 +1,42 @@
+package monolith
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// newLogger creates the structured logger of the client packages, writing
+// to output in the configured format.
+func newLogger(cfg *Config, output io.Writer) (log.Logger, error) {
+	h, err := logHandler(cfg, output)
+	if err != nil {
+		return nil, err
+	}
+	logger := log.New()
+	logger.SetHandler(h)
+	return logger, nil
+}
+
+func logHandler(cfg *Config, output io.Writer) (log.Handler, error) {
+	lvl := log.LvlInfo
+	if cfg.LogLevel != "" {
+		var err error
+		lvl, err = log.LvlFromString(cfg.LogLevel)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing log_level")
+		}
+	}
+
+	var format log.Format
+	switch cfg.LogFormat {
+	case "", "logfmt":
+		format = log.LogfmtFormat()
+	case "json":
+		format = log.JSONFormat()
+	default:
+		return nil, errors.Errorf("unknown log_format %q", cfg.LogFormat)
+	}
+	return log.LvlFilterHandler(lvl, log.StreamHandler(output, format)), nil
+}
@@ -0,0 +1,661 @@
+package monolith
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/access"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/analytics"
+	"github.com/tokencard/contracts/v2/pkg/api"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/blockview"
+	"github.com/tokencard/contracts/v2/pkg/cache"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/failover"
+	"github.com/tokencard/contracts/v2/pkg/gas"
+	"github.com/tokencard/contracts/v2/pkg/graphql"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+	"github.com/tokencard/contracts/v2/pkg/logfilter"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+	"github.com/tokencard/contracts/v2/pkg/readonly"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	"github.com/tokencard/contracts/v2/pkg/relayer"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/slo"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	"google.golang.org/grpc"
+)
+
+// receiptInterval is the interval the receipts of the sent transactions are
+// fetched at to record their outcome in the metrics.
+const receiptInterval = 15 * time.Second
+
+// Node is the Ethereum node a Monolith runs against, *ethclient.Client
+// implements it.
+type Node interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Monolith is the service layer of the contracts: the API and the subsystems
+// enabled in its configuration. A Monolith holds all of its state, several
+// of them can run in the same process. The fields set the dependencies of
+// the Monolith, they must not be changed once it is started.
+type Monolith struct {
+	// Node is the node the Monolith runs against, Start dials rpc_url when
+	// it is nil.
+	Node Node
+	// TransactOpts sign the transactions of the operator. Start opens the
+	// key configured in kms, keystore_dir or keystore_file when nil, the
+	// subsystems sending transactions are disabled when none is.
+	TransactOpts *bind.TransactOpts
+	// Getenv looks up the secrets named in the configuration, os.Getenv is
+	// used when nil.
+	Getenv func(key string) string
+	// Logger receives the structured logs of the subsystems. When nil, they
+	// are written to Output in the configured log format, which is then
+	// reloaded with the configuration.
+	Logger logging.Logger
+	// Output receives the logs of the subsystems logging lines of text, and
+	// the structured logs when Logger is nil. os.Stderr is used when nil.
+	Output io.Writer
+
+	cfg        *Config
+	logger     logging.Logger
+	handler    http.Handler
+	grpcServer *grpc.Server
+	allowlist  *access.Allowlist
+
+	mu sync.Mutex
+	// appliers apply the reloadable settings of a new configuration.
+	appliers []func(cfg *Config) error
+}
+
+// New returns the Monolith of a configuration, which is started by Start or
+// Run.
+func New(cfg *Config) *Monolith {
+	return &Monolith{cfg: cfg}
+}
+
+func (m *Monolith) getenv(key string) string {
+	if m.Getenv == nil {
+		return os.Getenv(key)
+	}
+	return m.Getenv(key)
+}
+
+func (m *Monolith) output() io.Writer {
+	if m.Output == nil {
+		return os.Stderr
+	}
+	return m.Output
+}
+
+// onReload registers a function applying the reloadable settings.
+func (m *Monolith) onReload(fn func(cfg *Config) error) {
+	m.appliers = append(m.appliers, fn)
+}
+
+// Start starts the subsystems enabled in the configuration, which run in the
+// background until the context is done. The node dialled by Start is then
+// closed.
+func (m *Monolith) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handler != nil {
+		return errors.New("monolith is already started")
+	}
+
+	cfg := m.cfg
+	err := cfg.Validate()
+	if err != nil {
+		return err
+	}
+
+	logger := m.Logger
+	if logger == nil {
+		l, err := newLogger(cfg, m.output())
+		if err != nil {
+			return err
+		}
+		m.onReload(func(cfg *Config) error {
+			h, err := logHandler(cfg, m.output())
+			if err != nil {
+				return err
+			}
+			l.SetHandler(h)
+			return nil
+		})
+		logger = l
+	}
+	m.logger = logger
+
+	client := m.Node
+	// rpc balances the calls across the failover endpoints when they are
+	// configured.
+	var rpc *failover.Backend
+	switch {
+	case client != nil:
+	case cfg.RPCURL == "":
+		return errors.New("rpc_url is not set in the configuration")
+	case len(cfg.Failover.URLs) > 0:
+		rpc, err = dialFailover(ctx, cfg, logging.With(logger, "module", "failover"))
+		if err != nil {
+			return err
+		}
+		client = rpc
+	default:
+		c, err := ethclient.DialContext(ctx, cfg.RPCURL)
+		if err != nil {
+			return errors.Wrapf(err, "connecting to %s", cfg.RPCURL)
+		}
+		go func() {
+			<-ctx.Done()
+			c.Close()
+		}()
+		client = c
+	}
+
+	// The transactions are only sent to the network of chain_id, or of the
+	// node on startup.
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting chain ID")
+	}
+	if cfg.ChainID != 0 && chainID.Uint64() != cfg.ChainID {
+		return errors.Wrapf(registry.ErrWrongNetwork, "the node is on chain %s, chain_id is %d", chainID, cfg.ChainID)
+	}
+
+	oracle, err := newGasOracle(cfg, client)
+	if err != nil {
+		return err
+	}
+	methods := txmgr.NewRegistry()
+	if cfg.MethodDefaultsFile != "" {
+		methods, err = txmgr.LoadRegistryFile(cfg.MethodDefaultsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var node bind.ContractBackend = client
+	var metricsRegistry metrics.Registry
+	if cfg.Metrics {
+		// go-ethereum only records metrics when this process-wide switch
+		// is set, the metrics themselves are kept in the registry of the
+		// Monolith.
+		metrics.Enabled = true
+		metricsRegistry = metrics.NewRegistry()
+		instrumented, err := telemetry.NewBackend(client, metricsRegistry, bindings.ContractABIs)
+		if err != nil {
+			return err
+		}
+		// The API does not wait for its transactions, their receipts are
+		// fetched in the background to record their outcome.
+		go instrumented.Watch(ctx, client, receiptInterval)
+		node = instrumented
+	}
+	tracer, err := newTracer(ctx, cfg, m.output())
+	if err != nil {
+		return err
+	}
+	if cfg.Tracing.Enabled {
+		node, err = telemetry.NewTracingBackend(node, tracer, bindings.ContractABIs)
+		if err != nil {
+			return err
+		}
+	}
+	logs := logfilter.NewBackend(node, client)
+	logs.MaxBlocks = cfg.LogFilter.MaxBlocks
+	logs.Attempts = cfg.LogFilter.Attempts
+	logs.Backoff = time.Duration(cfg.LogFilter.Backoff)
+	logs.Interval = time.Duration(cfg.LogFilter.Interval)
+	logs.Logger = logging.With(logger, "module", "logfilter")
+	node = logs
+	backend := txmgr.NewWithRegistry(gas.NewBackend(registry.NewGuard(node, client, chainID), oracle), methods)
+	backend.SetLogger(logging.With(logger, "module", "txmgr"))
+	if cfg.MaxTxPerMinute > 0 {
+		backend.SetRateLimiter(txmgr.NewRateLimiter(cfg.MaxTxPerMinute))
+	}
+	// The call cache sits above txmgr, so that the dry runs of the
+	// transactions are made against the latest state of the node rather than
+	// a cached result.
+	var contracts bind.ContractBackend = backend
+	var calls *cache.Backend
+	if cfg.CallCache.Enabled {
+		calls = startCallCache(ctx, cfg, backend, client, metricsRegistry, logging.With(logger, "module", "cache"))
+		contracts = calls
+	}
+
+	apiCfg := api.Config{
+		Licence:        cfg.Contracts.Licence,
+		TokenWhitelist: cfg.Contracts.TokenWhitelist,
+		Controller:     cfg.Contracts.Controller,
+		APIKeys:        cfg.apiKeys(m.getenv),
+		Metrics:        metricsRegistry,
+		TransactOpts:   m.TransactOpts,
+	}
+	var guard *readonly.Guard
+	if apiCfg.TransactOpts == nil && (cfg.KMS.Provider != "" || cfg.KeystoreDir != "" || cfg.KeystoreFile != "") {
+		apiCfg.TransactOpts, apiCfg.Signer, guard, err = startOperator(ctx, cfg, chainID, m.getenv, logging.With(logger, "module", "readonly"))
+		if err != nil {
+			return err
+		}
+	}
+	// writable holds the state changing requests back in read-only mode.
+	writable := func(h http.Handler) http.Handler {
+		if guard == nil {
+			return h
+		}
+		return guard.Handler(h)
+	}
+
+	apiHandler, err := api.New(contracts, apiCfg)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", writable(apiHandler))
+	if guard != nil {
+		mux.Handle("/read-only", guard.StatusHandler())
+	}
+	if rpc != nil {
+		mux.Handle("/rpc", failover.Handler(rpc))
+	}
+
+	if cfg.Relayer.Enabled {
+		r, err := startRelayer(ctx, cfg, contracts, client, apiCfg.TransactOpts, logging.With(logger, "module", "relayer"))
+		if err != nil {
+			return err
+		}
+		h := writable(relayer.NewHandler(r))
+		mux.Handle("/relay", h)
+		mux.Handle("/relay/", h)
+	}
+
+	if cfg.Provisioning.Enabled {
+		p, err := startProvisioner(ctx, cfg, contracts, client, apiCfg.TransactOpts, logging.With(logger, "module", "provision"))
+		if err != nil {
+			return err
+		}
+		h := writable(provision.NewHandler(p))
+		mux.Handle("/wallets", h)
+		mux.Handle("/wallets/", h)
+	}
+
+	var handlers []indexer.Handler
+	var alerts *alert.Engine
+	if cfg.Alerts.RulesFile != "" {
+		alertLogger := logging.With(logger, "module", "alert")
+		alerts, err = startAlerts(ctx, cfg, metricsRegistry, m.getenv, alertLogger)
+		if err != nil {
+			return err
+		}
+		m.onReload(func(cfg *Config) error {
+			if cfg.Alerts.RulesFile == "" {
+				return nil
+			}
+			rules, err := loadAlertRules(cfg, metricsRegistry)
+			if err != nil {
+				return err
+			}
+			alerts.SetRules(rules, rules.Notifiers(alertLogger, m.getenv))
+			return nil
+		})
+		if cfg.Indexer.FastPath {
+			err = startHeadFeed(ctx, cfg, logging.With(logger, "module", "headfeed"), alerts)
+			if err != nil {
+				return err
+			}
+		} else {
+			handlers = append(handlers, indexer.WithConfirmations(alerts, cfg.Alerts.Confirmations))
+		}
+	}
+
+	if cfg.Drift.SpecFile != "" {
+		detector, err := startDriftDetector(ctx, cfg, contracts, apiCfg.TransactOpts, alerts, logging.With(logger, "module", "drift"))
+		if err != nil {
+			return err
+		}
+		m.onReload(func(cfg *Config) error {
+			if cfg.Drift.SpecFile == "" {
+				return nil
+			}
+			spec, err := loadDriftSpec(cfg)
+			if err != nil {
+				return err
+			}
+			detector.SetSpec(spec)
+			return nil
+		})
+	}
+
+	if calls != nil {
+		handlers = append(handlers, calls)
+	}
+
+	var idx *indexer.Indexer
+	if cfg.Indexer.Enabled {
+		if len(cfg.Webhooks.Endpoints) > 0 {
+			notifier := startWebhooks(ctx, cfg, m.getenv, m.output())
+			m.onReload(func(cfg *Config) error {
+				notifier.SetEndpoints(webhookEndpoints(cfg, m.getenv)...)
+				return nil
+			})
+			handlers = append(handlers, notifier)
+		}
+		idx, err = startIndexer(ctx, cfg, logs, client, logging.With(logger, "module", "indexer"), handlers...)
+		if err != nil {
+			return err
+		}
+		mux.Handle("/graphql", graphql.NewHandler(idx.Store()))
+		times := analytics.NewNodeTimes(client)
+		mux.Handle("/analytics", analytics.NewHandler(idx.Store(), times))
+		licence, err := bindings.NewLicenceCaller(cfg.Contracts.Licence, node)
+		if err != nil {
+			return err
+		}
+		mux.Handle("/analytics/licence-amount", analytics.NewLicenceWhatIfHandler(idx.Store(), times, licence))
+		mux.Handle("/blocks/", blockview.NewHandler(idx.Store(), client))
+		if cfg.Analytics.AttributionFile != "" {
+			attribution, err := analytics.ReadAttributionFile(cfg.Analytics.AttributionFile)
+			if err != nil {
+				return err
+			}
+			mux.Handle("/analytics/referrers", analytics.NewReferrersHandler(idx.Store(), attribution))
+		}
+
+		if cfg.SLO.IndexerLag.Target > 0 {
+			tracker := startIndexerLagSLO(ctx, cfg, idx, logging.With(logger, "module", "slo"))
+			if metricsRegistry != nil {
+				tracker.Register(metricsRegistry)
+			}
+			mux.Handle("/slo", slo.Handler(tracker))
+		}
+
+		if cfg.Reconciliation.Enabled {
+			_, err = startReconciliation(ctx, cfg, idx.Store(), m.getenv, alerts, m.output())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Dust.Enabled {
+		startDustDetector(ctx, cfg, client, alerts, m.output())
+	}
+
+	if cfg.Invariants.Enabled && idx != nil {
+		_, err = startInvariants(ctx, cfg, contracts, idx.Store(), alerts, m.output())
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.StatusPage.Enabled {
+		var store indexer.Store
+		if idx != nil {
+			store = idx.Store()
+		}
+		err = startStatusPage(ctx, cfg, client, store, m.getenv, logging.With(logger, "module", "status"))
+		if err != nil {
+			return err
+		}
+	}
+
+	var handler http.Handler = mux
+	var gate *canary.Gate
+	if cfg.Canary.Enabled {
+		gate, err = startCanary(ctx, cfg, contracts, client, apiCfg.TransactOpts, idx, alerts, logging.With(logger, "module", "canary"))
+		if err != nil {
+			return err
+		}
+		mux.Handle("/canary", gate.StatusHandler())
+		handler = gate.Handler(mux)
+	}
+	if cfg.GRPC.ListenAddress != "" {
+		m.grpcServer, err = newGRPCServer(cfg, contracts, apiCfg, guard, gate)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.Tracing.Enabled {
+		handler = telemetry.TraceHandler(tracer, handler)
+	}
+
+	m.allowlist, err = access.NewAllowlist(cfg.AllowedCIDRs...)
+	if err != nil {
+		return err
+	}
+	m.onReload(func(cfg *Config) error {
+		return m.allowlist.Set(cfg.AllowedCIDRs...)
+	})
+
+	m.handler = handler
+	return nil
+}
+
+// Handler returns the handler of the API and the routes of the subsystems,
+// for programs serving them along their own routes. It is nil until the
+// Monolith is started.
+func (m *Monolith) Handler() http.Handler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.handler
+}
+
+// GRPCServer returns the gRPC server of the contract operations, for programs
+// serving it on their own listener. It is nil until the Monolith is started,
+// and when grpc.listen_address is not set.
+func (m *Monolith) GRPCServer() *grpc.Server {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.grpcServer
+}
+
+// Reload applies the settings of cfg which can change without a restart: the
+// logging settings, the drift spec, the alert rules, the webhook endpoints
+// and the allowed CIDRs. It returns the other settings changed since the
+// Monolith was started, which are ignored until it is restarted.
+func (m *Monolith) Reload(cfg *Config) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handler == nil {
+		return nil, errors.New("monolith is not started")
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []string
+	for _, apply := range m.appliers {
+		err := apply(cfg)
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	ignored := m.cfg.restartRequired(cfg)
+	if len(failed) > 0 {
+		return ignored, errors.Errorf("applying the configuration: %s", strings.Join(failed, "; "))
+	}
+	return ignored, nil
+}
+
+// Serve serves the handler of the started Monolith on the configured listen
+// address, and its gRPC server on grpc.listen_address when set, until the
+// context is done, then shuts the servers down.
+func (m *Monolith) Serve(ctx context.Context) error {
+	handler := m.Handler()
+	if handler == nil {
+		return errors.New("monolith is not started")
+	}
+	if m.cfg.TLS.CAFile == "" && len(m.cfg.AllowedCIDRs) == 0 {
+		m.logger.Warn("The API accepts connections from any address without client certificates, set tls.ca_file or allowed_cidrs")
+	}
+	l, err := m.listen(m.cfg.ListenAddress)
+	if err != nil {
+		return err
+	}
+	grpcServer := m.GRPCServer()
+	var grpcListener net.Listener
+	if grpcServer != nil {
+		grpcListener, err = m.listen(m.cfg.GRPC.ListenAddress)
+		if err != nil {
+			l.Close()
+			return err
+		}
+	}
+
+	srv := &http.Server{
+		Addr:         m.cfg.ListenAddress,
+		Handler:      handler,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		m.logger.Info("API listening", "address", l.Addr())
+		errs <- errors.Wrap(srv.Serve(l), "serving API")
+	}()
+	if grpcServer != nil {
+		go func() {
+			m.logger.Info("gRPC listening", "address", grpcListener.Addr())
+			errs <- errors.Wrap(grpcServer.Serve(grpcListener), "serving gRPC")
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		srv.Close()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = srv.Shutdown(shutdownCtx)
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	return err
+}
+
+// Run starts the Monolith and serves it until the context is done.
+func (m *Monolith) Run(ctx context.Context) error {
+	err := m.Start(ctx)
+	if err != nil {
+		return err
+	}
+	return m.Serve(ctx)
+}
+
+// listen opens a listener of the API on address, closing the connections
+// from addresses outside of the allowlist and serving TLS when it is
+// configured.
+func (m *Monolith) listen(address string) (net.Listener, error) {
+	cfg := m.cfg
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listening on %s", address)
+	}
+	l = m.allowlist.Listener(l, func(addr net.Addr) {
+		m.logger.Warn("Rejected connection", "addr", addr)
+	})
+	if cfg.TLS.CertFile == "" {
+		return l, nil
+	}
+	tlsConfig, err := access.ServerConfig(cfg.TLS)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return tls.NewListener(l, tlsConfig), nil
+}
+
+// operatorSigner returns the address of the operator key, held by the KMS,
+// the keystore directory or the keystore file configured, and the signer of
+// the transactions of the given chain sent with a context. The KMS is called
+// with the context of each transaction, the keystores ignore it.
+func operatorSigner(ctx context.Context, cfg *Config, chainID *big.Int, getenv func(string) string) (common.Address, session.SignerFunc, error) {
+	if cfg.KMS.Provider != "" {
+		kms, err := newKMS(cfg, getenv)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		s, err := signer.NewKMSSigner(ctx, kms, chainID)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		return s.Address(), s.SignerFn, nil
+	}
+
+	var opts *bind.TransactOpts
+	passphrase := getenv(cfg.PasswordEnv)
+	if cfg.KeystoreDir != "" {
+		var err error
+		opts, err = keys.Open(cfg.KeystoreDir).TransactOpts(cfg.Account, passphrase, chainID)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+	} else {
+		key, err := signer.DecryptKeyFile(cfg.KeystoreFile, passphrase)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		opts = signer.NewTransactOpts(key, chainID)
+	}
+	return opts.From, func(context.Context) bind.SignerFn { return opts.Signer }, nil
+}
+
+func newKMS(cfg *Config, getenv func(string) string) (signer.KMS, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch cfg.KMS.Provider {
+	case "aws":
+		creds, err := signer.AWSCredentialsFrom(getenv)
+		if err != nil {
+			return nil, err
+		}
+		return &signer.AWSKMS{
+			Region:      cfg.KMS.Region,
+			KeyID:       cfg.KMS.KeyID,
+			Credentials: creds,
+			Endpoint:    cfg.KMS.Endpoint,
+			Client:      client,
+		}, nil
+	case "gcp":
+		return &signer.GCPKMS{
+			KeyVersion: cfg.KMS.KeyID,
+			Endpoint:   cfg.KMS.Endpoint,
+			Client:     client,
+		}, nil
+	}
+	return nil, errors.Errorf("unknown kms provider %q, expected aws or gcp", cfg.KMS.Provider)
+}
@@ -0,0 +1,46 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+)
+
+const defaultProvisioningInterval = time.Minute
+
+// startProvisioner assigns the wallets of the new owners with the operator
+// key, which must be a controller, and keeps the wallet cache filled in the
+// background.
+func startProvisioner(ctx context.Context, cfg *Config, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, logger logging.Logger) (*provision.Provisioner, error) {
+	if opts == nil {
+		return nil, errors.New("provisioning requires kms, keystore_dir or keystore_file to be set")
+	}
+
+	var assignments provision.Assignments = provision.NewMemoryAssignments()
+	if cfg.Provisioning.AssignmentsFile != "" {
+		var err error
+		assignments, err = provision.OpenFileAssignments(cfg.Provisioning.AssignmentsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := provision.New(cfg.Contracts.WalletDeployer, cfg.Contracts.WalletCache, backend, receipts, opts, assignments)
+	if err != nil {
+		return nil, err
+	}
+	p.Target = cfg.Provisioning.Target
+	p.BatchSize = cfg.Provisioning.BatchSize
+	p.Logger = logger
+
+	interval := time.Duration(cfg.Provisioning.Interval)
+	if interval <= 0 {
+		interval = defaultProvisioningInterval
+	}
+	go p.Run(ctx, interval)
+	return p, nil
+}
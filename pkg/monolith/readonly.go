@@ -0,0 +1,122 @@
+package monolith
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/readonly"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+// signerDependency is the name of the operator signer in the reasons of the
+// read-only mode.
+const signerDependency = "signer"
+
+// operator opens the signer of the operator key on demand, so that the
+// Monolith starts, and keeps serving the reads, while the KMS is unreachable
+// or the keystore cannot be opened.
+type operator struct {
+	cfg     *Config
+	chainID *big.Int
+	getenv  func(string) string
+	guard   *readonly.Guard
+
+	mu   sync.Mutex
+	from common.Address
+	sign session.SignerFunc
+	kms  signer.KMS
+}
+
+// startOperator returns the options signing with the operator key, the signer
+// of the transactions sent with another context than ctx, and the guard
+// holding the state changing requests back while the key is unavailable,
+// checked every read_only.check_interval. Without account, the signer must
+// open on startup as the address of the operator is not known otherwise.
+func startOperator(ctx context.Context, cfg *Config, chainID *big.Int, getenv func(string) string, logger logging.Logger) (*bind.TransactOpts, session.SignerFunc, *readonly.Guard, error) {
+	o := &operator{cfg: cfg, chainID: chainID, getenv: getenv}
+	o.guard = &readonly.Guard{
+		Dependencies: []readonly.Dependency{{Name: signerDependency, Check: o.check}},
+		Interval:     time.Duration(cfg.ReadOnly.CheckInterval),
+		Logger:       logger,
+	}
+
+	from, _, err := o.open(ctx)
+	if err != nil {
+		if cfg.Account == (common.Address{}) {
+			return nil, nil, nil, errors.Wrap(err, "opening the signer, set account to start in read-only mode instead")
+		}
+		from = cfg.Account
+		o.guard.Fail(signerDependency, err)
+	}
+	go o.guard.Run(ctx)
+
+	return &bind.TransactOpts{From: from, Signer: o.signer(ctx)}, o.signer, o.guard, nil
+}
+
+// signer returns the function signing with the operator key in ctx, opening
+// the signer with ctx until it opens and reporting its failures to the guard.
+func (o *operator) signer(ctx context.Context) bind.SignerFn {
+	return func(s types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		_, sign, err := o.open(ctx)
+		if err != nil {
+			o.guard.Fail(signerDependency, err)
+			return nil, errors.Wrap(readonly.ErrReadOnly, err.Error())
+		}
+		signed, err := sign(ctx)(s, address, tx)
+		if err != nil {
+			if errors.Cause(err) != signer.ErrNotAuthorized {
+				o.guard.Fail(signerDependency, err)
+			}
+			return nil, err
+		}
+		o.guard.Pass(signerDependency)
+		return signed, nil
+	}
+}
+
+// open returns the address and the signer of the operator key, opening it
+// the first time it succeeds.
+func (o *operator) open(ctx context.Context) (common.Address, session.SignerFunc, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sign != nil {
+		return o.from, o.sign, nil
+	}
+	from, sign, err := operatorSigner(ctx, o.cfg, o.chainID, o.getenv)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	if o.cfg.Account != (common.Address{}) && from != o.cfg.Account {
+		return common.Address{}, nil, errors.Errorf("the signer holds the key of %s, not of account %s", from.Hex(), o.cfg.Account.Hex())
+	}
+	o.from, o.sign = from, sign
+	return from, sign, nil
+}
+
+// check opens the signer and, as a KMS can become unreachable after it was
+// opened, fetches the public key of the KMS key.
+func (o *operator) check(ctx context.Context) error {
+	_, _, err := o.open(ctx)
+	if err != nil || o.cfg.KMS.Provider == "" {
+		return err
+	}
+	o.mu.Lock()
+	if o.kms == nil {
+		o.kms, err = newKMS(o.cfg, o.getenv)
+	}
+	kms := o.kms
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	_, err = kms.PublicKey(ctx)
+	return errors.Wrap(err, "fetching the public key of the KMS key")
+}
@@ -0,0 +1,67 @@
+package monolith
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/etherscan"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+const defaultReconciliationInterval = 10 * time.Minute
+
+// startReconciliation compares the indexed events with the logs of the
+// explorer in the background. The discrepancies are logged to output, and
+// alerted as critical when the alerts are enabled: either the index or the
+// explorer is wrong.
+func startReconciliation(ctx context.Context, cfg *Config, store indexer.Store, getenv func(string) string, alerts *alert.Engine, output io.Writer) (*etherscan.Reconciler, error) {
+	contracts, err := indexedContracts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := cfg.Reconciliation
+	url := c.APIURL
+	if url == "" {
+		url = etherscan.DefaultURL
+	}
+	var key string
+	if c.APIKeyEnv != "" {
+		key = getenv(c.APIKeyEnv)
+	}
+	interval := time.Duration(c.Interval)
+	if interval <= 0 {
+		interval = defaultReconciliationInterval
+	}
+
+	logger := log.New(output, "reconciliation: ", log.LstdFlags)
+	r := etherscan.NewReconciler(etherscan.NewClient(url, key), store, contracts...)
+	r.StartBlock = c.StartBlock
+	r.Confirmations = c.Confirmations
+	r.Interval = interval
+	r.ErrorLog = logger
+	r.Alert = func(ctx context.Context, discrepancies []etherscan.Discrepancy) error {
+		for _, d := range discrepancies {
+			logger.Print(d)
+			if alerts == nil {
+				continue
+			}
+			err := alerts.Send(ctx, alert.Alert{
+				Rule:     "reconciliation",
+				Severity: alert.Critical,
+				Summary:  fmt.Sprintf("indexed events disagree with the explorer: %s", d),
+				State:    alert.Firing,
+				Time:     time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	go r.Run(ctx)
+	return r, nil
+}
@@ -0,0 +1,50 @@
+package monolith
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/relayer"
+)
+
+const defaultSettleInterval = 15 * time.Second
+
+// startRelayer relays the meta-transactions of the wallet owners with the
+// operator key, which must be a controller, and settles their fees in the
+// background.
+func startRelayer(ctx context.Context, cfg *Config, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, logger logging.Logger) (*relayer.Relayer, error) {
+	if opts == nil {
+		return nil, errors.New("the relayer requires kms, keystore_dir or keystore_file to be set")
+	}
+
+	var ledger relayer.Ledger = relayer.NewMemoryLedger()
+	if cfg.Relayer.LedgerFile != "" {
+		var err error
+		ledger, err = relayer.OpenFileLedger(cfg.Relayer.LedgerFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := relayer.New(backend, receipts, opts, ledger)
+	r.Logger = logger
+	if cfg.Relayer.Allowance != "" {
+		// The allowance was validated by Config.Validate.
+		r.Allowance, _ = new(big.Int).SetString(cfg.Relayer.Allowance, 10)
+	}
+	if cfg.Relayer.HolderToken != (common.Address{}) {
+		r.Eligible = relayer.TokenHolders(cfg.Relayer.HolderToken, backend)
+	}
+
+	interval := time.Duration(cfg.Relayer.SettleInterval)
+	if interval <= 0 {
+		interval = defaultSettleInterval
+	}
+	go r.Run(ctx, interval)
+	return r, nil
+}
@@ -0,0 +1,50 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/slo"
+)
+
+const (
+	defaultSLOWindow        = 30 * 24 * time.Hour
+	defaultSLOProbeInterval = 15 * time.Second
+	defaultIndexerLagBlocks = 3
+)
+
+// startIndexerLagSLO samples the lag of the indexer in the background, each
+// sample is good when the lag is at most max_blocks.
+func startIndexerLagSLO(ctx context.Context, cfg *Config, idx *indexer.Indexer, logger logging.Logger) *slo.Tracker {
+	c := cfg.SLO.IndexerLag
+	window := time.Duration(c.Window)
+	if window <= 0 {
+		window = defaultSLOWindow
+	}
+	interval := time.Duration(c.Interval)
+	if interval <= 0 {
+		interval = defaultSLOProbeInterval
+	}
+	maxBlocks := c.MaxBlocks
+	if maxBlocks == 0 {
+		maxBlocks = defaultIndexerLagBlocks
+	}
+
+	tracker := slo.NewTracker(slo.Objective{Name: "indexer_lag", Target: c.Target, Window: window})
+	p := &slo.Probe{
+		Tracker:  tracker,
+		Interval: interval,
+		Check: func(ctx context.Context) (bool, error) {
+			lag, err := idx.Lag(ctx)
+			if err != nil {
+				return false, err
+			}
+			return lag <= maxBlocks, nil
+		},
+		Logger: logger,
+	}
+	go p.Run(ctx)
+	return tracker
+}
@@ -0,0 +1,58 @@
+package monolith
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/status"
+)
+
+const defaultStatusInterval = time.Minute
+
+// startStatusPage publishes the status page to the configured destinations
+// in the background. The store is nil when the indexer is disabled.
+func startStatusPage(ctx context.Context, cfg *Config, backend status.Backend, store indexer.Store, getenv func(string) string, logger logging.Logger) error {
+	c := cfg.StatusPage
+	var publishers []status.Publisher
+	if c.Dir != "" {
+		publishers = append(publishers, status.DirPublisher{Dir: c.Dir})
+	}
+	if c.S3.Bucket != "" {
+		creds, err := signer.AWSCredentialsFrom(getenv)
+		if err != nil {
+			return errors.Wrap(err, "status_page.s3")
+		}
+		publishers = append(publishers, &status.S3Publisher{
+			Bucket:      c.S3.Bucket,
+			Region:      c.S3.Region,
+			Prefix:      c.S3.Prefix,
+			Endpoint:    c.S3.Endpoint,
+			Credentials: creds,
+		})
+	}
+	if c.GitHub.Repo != "" {
+		token := getenv(c.GitHub.TokenEnv)
+		if token == "" {
+			return errors.Errorf("status_page.github: %s is not set", c.GitHub.TokenEnv)
+		}
+		publishers = append(publishers, &status.GitHubPublisher{
+			Repo:   c.GitHub.Repo,
+			Branch: c.GitHub.Branch,
+			Dir:    c.GitHub.Dir,
+			Token:  token,
+		})
+	}
+
+	interval := time.Duration(c.Interval)
+	if interval <= 0 {
+		interval = defaultStatusInterval
+	}
+	p := status.NewPage(status.NewGenerator(backend, store, cfg.Contracts.Licence), publishers...)
+	p.Logger = logger
+	go p.Run(ctx, interval)
+	return nil
+}
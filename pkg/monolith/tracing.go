@@ -0,0 +1,31 @@
+package monolith
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+)
+
+// newTracer returns the tracer writing the spans to the configured file, or
+// to output when none is, and a no-op tracer when tracing is disabled. The
+// file is closed when the context is done.
+func newTracer(ctx context.Context, cfg *Config, output io.Writer) (telemetry.Tracer, error) {
+	if !cfg.Tracing.Enabled {
+		return telemetry.NoopTracer{}, nil
+	}
+	if cfg.Tracing.File == "" {
+		return telemetry.NewWriterTracer(output), nil
+	}
+	f, err := os.OpenFile(cfg.Tracing.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening tracing file")
+	}
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+	return telemetry.NewWriterTracer(f), nil
+}
@@ -0,0 +1,374 @@
+package monolith
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// The errors returned by the clients, to compare with errors.Cause.
+var (
+	// ErrControllerStopped is returned by the AccessClient of a stopped
+	// controller.
+	ErrControllerStopped = bindings.ErrControllerStopped
+	// ErrNotOwner is returned for a change of the roles requiring the owner
+	// of the controller.
+	ErrNotOwner = bindings.ErrNotOwner
+	// ErrNotAdmin is returned for an account which is not an admin.
+	ErrNotAdmin = bindings.ErrNotAdmin
+	// ErrNotController is returned for an account which is not a controller.
+	ErrNotController = bindings.ErrNotController
+	// ErrTokenNotAvailable is returned for a token missing from the token
+	// whitelist.
+	ErrTokenNotAvailable = bindings.ErrTokenNotAvailable
+	// ErrZeroDestination is returned for a transfer to the zero address.
+	ErrZeroDestination = bindings.ErrZeroDestination
+	// ErrZeroAmount is returned for a transfer of nothing.
+	ErrZeroAmount = bindings.ErrZeroAmount
+	// ErrSpendLimitExceeded is returned for a transfer above the available
+	// spend limit.
+	ErrSpendLimitExceeded = bindings.ErrSpendLimitExceeded
+)
+
+// ETH is the asset address the Wallet uses for ether.
+var ETH = bindings.ETH
+
+// LicenceScale is the scale of the licence fee: the scaled fee is in
+// thousandths of the loaded amount.
+const LicenceScale = bindings.LicenceScale
+
+// The value types of the clients.
+type (
+	// Roles are the roles of an account at the Controller.
+	Roles = bindings.Roles
+	// TokenRate is a token of the token whitelist with its rate in ether.
+	TokenRate = bindings.TokenRate
+	// WhitelistedToken is a token of the token whitelist.
+	WhitelistedToken = bindings.WhitelistedToken
+	// NewToken is a token to add to the token whitelist.
+	NewToken = bindings.NewToken
+	// LicenceFee is the fee charged by the Licence on card loads.
+	LicenceFee = bindings.LicenceFee
+	// HolderAsset is an amount of a token held by the Holder or paid out of
+	// it.
+	HolderAsset = bindings.HolderAsset
+	// Redemption is a payout of the Holder to a TKN holder burning their TKN.
+	Redemption = bindings.Redemption
+	// DailyLimit is the state of one of the daily limits of a Wallet.
+	DailyLimit = bindings.DailyLimit
+	// HolderBackend is the backend of a HolderClient.
+	HolderBackend = bindings.HolderBackend
+)
+
+// ParseRate parses a rate given in ether per token into the rate stored by
+// the Oracle.
+func ParseRate(etherPerToken string) (*big.Int, error) {
+	return bindings.ParseRate(etherPerToken)
+}
+
+// AccessClient is a client of the roles of a deployed Controller.
+type AccessClient struct {
+	c *bindings.AccessClient
+}
+
+// NewAccessClient binds the Controller deployed at address.
+func NewAccessClient(address common.Address, backend bind.ContractBackend) (*AccessClient, error) {
+	c, err := bindings.NewAccessClient(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessClient{c: c}, nil
+}
+
+// Address returns the address of the Controller.
+func (c *AccessClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// Roles returns the roles of an account. Only the owner keeps its role when
+// the controller is stopped.
+func (c *AccessClient) Roles(ctx context.Context, account common.Address) (Roles, error) {
+	return c.c.Roles(ctx, account)
+}
+
+// RequireAdmin fails with ErrNotAdmin unless account is an admin.
+func (c *AccessClient) RequireAdmin(ctx context.Context, account common.Address) error {
+	return c.c.RequireAdmin(ctx, account)
+}
+
+// RequireController fails with ErrNotController unless account is a
+// controller.
+func (c *AccessClient) RequireController(ctx context.Context, account common.Address) error {
+	return c.c.RequireController(ctx, account)
+}
+
+// Members returns the admins and the controllers, sorted.
+func (c *AccessClient) Members(ctx context.Context) (admins, controllers []common.Address, err error) {
+	return c.c.Members(ctx)
+}
+
+// GrantAdmin makes account an admin. It requires the owner.
+func (c *AccessClient) GrantAdmin(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	return c.c.GrantAdmin(opts, account)
+}
+
+// RevokeAdmin removes the admin role of account. It requires the owner.
+func (c *AccessClient) RevokeAdmin(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	return c.c.RevokeAdmin(opts, account)
+}
+
+// GrantController makes account a controller. It requires an admin.
+func (c *AccessClient) GrantController(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	return c.c.GrantController(opts, account)
+}
+
+// RevokeController removes the controller role of account. It requires an
+// admin.
+func (c *AccessClient) RevokeController(opts *bind.TransactOpts, account common.Address) (*types.Transaction, error) {
+	return c.c.RevokeController(opts, account)
+}
+
+// TokenWhitelistClient is a client of a deployed TokenWhitelist.
+type TokenWhitelistClient struct {
+	c *bindings.TokenWhitelistClient
+}
+
+// NewTokenWhitelistClient binds the TokenWhitelist deployed at address.
+func NewTokenWhitelistClient(address common.Address, backend bind.ContractBackend) (*TokenWhitelistClient, error) {
+	c, err := bindings.NewTokenWhitelistClient(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenWhitelistClient{c: c}, nil
+}
+
+// Address returns the address of the TokenWhitelist.
+func (c *TokenWhitelistClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// Token returns a whitelisted token, failing with ErrTokenNotAvailable when
+// it is not whitelisted.
+func (c *TokenWhitelistClient) Token(ctx context.Context, token common.Address) (WhitelistedToken, error) {
+	return c.c.Token(ctx, token)
+}
+
+// Tokens returns the whitelisted tokens.
+func (c *TokenWhitelistClient) Tokens(ctx context.Context) ([]WhitelistedToken, error) {
+	return c.c.Tokens(ctx)
+}
+
+// IsLoadable tells whether the token can be loaded to the TokenCard.
+func (c *TokenWhitelistClient) IsLoadable(ctx context.Context, token common.Address) (bool, error) {
+	return c.c.IsLoadable(ctx, token)
+}
+
+// IsRedeemable tells whether the token can be redeemed at the Holder.
+func (c *TokenWhitelistClient) IsRedeemable(ctx context.Context, token common.Address) (bool, error) {
+	return c.c.IsRedeemable(ctx, token)
+}
+
+// AddTokens whitelists the tokens. Only admins may add tokens.
+func (c *TokenWhitelistClient) AddTokens(opts *bind.TransactOpts, tokens ...NewToken) (*types.Transaction, error) {
+	return c.c.AddTokens(opts, tokens...)
+}
+
+// RemoveTokens removes the tokens from the whitelist. Only admins may remove
+// tokens.
+func (c *TokenWhitelistClient) RemoveTokens(opts *bind.TransactOpts, tokens ...common.Address) (*types.Transaction, error) {
+	return c.c.RemoveTokens(opts, tokens...)
+}
+
+// OracleClient is a client of a deployed Oracle, reading the rates it stores
+// in the token whitelist.
+type OracleClient struct {
+	c *bindings.OracleClient
+}
+
+// NewOracleClient binds the Oracle deployed at address and its token
+// whitelist.
+func NewOracleClient(address, tokenWhitelist common.Address, backend bind.ContractBackend) (*OracleClient, error) {
+	c, err := bindings.NewOracleClient(address, tokenWhitelist, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleClient{c: c}, nil
+}
+
+// Address returns the address of the Oracle.
+func (c *OracleClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// Rate returns the rate of a token.
+func (c *OracleClient) Rate(ctx context.Context, token common.Address) (TokenRate, error) {
+	return c.c.Rate(ctx, token)
+}
+
+// Rates returns the rates of the whitelisted tokens.
+func (c *OracleClient) Rates(ctx context.Context) ([]TokenRate, error) {
+	return c.c.Rates(ctx)
+}
+
+// UpdateRates queries the rates of the tokens, all the whitelisted ones when
+// none are given, with a callback using up to gasLimit. Only controllers may
+// update the rates.
+func (c *OracleClient) UpdateRates(opts *bind.TransactOpts, gasLimit uint64, tokens ...common.Address) (*types.Transaction, error) {
+	return c.c.UpdateRates(opts, gasLimit, tokens...)
+}
+
+// SetRate sets the rate of a token in wei per whole token, see ParseRate,
+// bypassing the oracle. Only admins may set the rates.
+func (c *OracleClient) SetRate(opts *bind.TransactOpts, token common.Address, rate *big.Int, at time.Time) (*types.Transaction, error) {
+	return c.c.SetRate(opts, token, rate, at)
+}
+
+// LicenceClient is a client of a deployed Licence.
+type LicenceClient struct {
+	c *bindings.LicenceClient
+}
+
+// NewLicenceClient binds the Licence deployed at address.
+func NewLicenceClient(address common.Address, backend bind.ContractBackend) (*LicenceClient, error) {
+	c, err := bindings.NewLicenceClient(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &LicenceClient{c: c}, nil
+}
+
+// Address returns the address of the Licence.
+func (c *LicenceClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// Fee returns the current licence fee.
+func (c *LicenceClient) Fee(ctx context.Context) (LicenceFee, error) {
+	return c.c.Fee(ctx)
+}
+
+// Split splits a card load of amount of asset into the loaded amount and the
+// licence fee.
+func (c *LicenceClient) Split(ctx context.Context, asset common.Address, amount *big.Int) (load, licence *big.Int, err error) {
+	return c.c.Split(ctx, asset, amount)
+}
+
+// WatchFee sends the new licence fee to sink each time the licence DAO updates
+// it, until the subscription is unsubscribed or fails.
+func (c *LicenceClient) WatchFee(opts *bind.WatchOpts, sink chan<- LicenceFee) (event.Subscription, error) {
+	return c.c.WatchFee(opts, sink)
+}
+
+// HolderClient is a client of a deployed Holder, the contract backing TKN
+// with the redeemable tokens of the token whitelist.
+type HolderClient struct {
+	c *bindings.HolderClient
+}
+
+// NewHolderClient binds the Holder deployed at address and the token
+// whitelist listing its redeemable tokens.
+func NewHolderClient(address, tokenWhitelist common.Address, backend HolderBackend) (*HolderClient, error) {
+	c, err := bindings.NewHolderClient(address, tokenWhitelist, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &HolderClient{c: c}, nil
+}
+
+// Address returns the address of the Holder.
+func (c *HolderClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// Balance returns the balance of the Holder in a token, ETH for ether.
+func (c *HolderClient) Balance(ctx context.Context, token common.Address) (*big.Int, error) {
+	return c.c.Balance(ctx, token)
+}
+
+// Redeemable returns the balances of the redeemable tokens.
+func (c *HolderClient) Redeemable(ctx context.Context) ([]HolderAsset, error) {
+	return c.c.Redeemable(ctx)
+}
+
+// Claimable returns the balances of the Holder in the tokens, which must not
+// be redeemable, that an admin can claim.
+func (c *HolderClient) Claimable(ctx context.Context, tokens ...common.Address) ([]HolderAsset, error) {
+	return c.c.Claimable(ctx, tokens...)
+}
+
+// Share returns the assets paid out for burning an amount of TKN.
+func (c *HolderClient) Share(ctx context.Context, tkn *big.Int) ([]HolderAsset, error) {
+	return c.c.Share(ctx, tkn)
+}
+
+// Claim sends the whole balance of the Holder in the tokens to an address.
+// Only admins may claim.
+func (c *HolderClient) Claim(opts *bind.TransactOpts, to common.Address, tokens ...common.Address) (*types.Transaction, error) {
+	return c.c.Claim(opts, to, tokens...)
+}
+
+// Redemptions returns the payouts of the Holder in the block range, to the
+// given addresses only when there are some.
+func (c *HolderClient) Redemptions(opts *bind.FilterOpts, to ...common.Address) ([]Redemption, error) {
+	return c.c.Redemptions(opts, to...)
+}
+
+// WalletClient is a client of a deployed Wallet, checking transfers against
+// its daily limits before sending them.
+type WalletClient struct {
+	c *bindings.WalletClient
+}
+
+// NewWalletClient binds the Wallet deployed at address.
+func NewWalletClient(address common.Address, backend bind.ContractBackend) (*WalletClient, error) {
+	c, err := bindings.NewWalletClient(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletClient{c: c}, nil
+}
+
+// Address returns the address of the Wallet.
+func (c *WalletClient) Address() common.Address {
+	return c.c.Address()
+}
+
+// SpendLimit returns the daily limit of the transfers to addresses which are
+// not whitelisted, in wei.
+func (c *WalletClient) SpendLimit(ctx context.Context) (DailyLimit, error) {
+	return c.c.SpendLimit(ctx)
+}
+
+// LoadLimit returns the daily limit of the card loads, in stablecoin.
+func (c *WalletClient) LoadLimit(ctx context.Context) (DailyLimit, error) {
+	return c.c.LoadLimit(ctx)
+}
+
+// GasTopUpLimit returns the daily limit of the gas top ups, in wei.
+func (c *WalletClient) GasTopUpLimit(ctx context.Context) (DailyLimit, error) {
+	return c.c.GasTopUpLimit(ctx)
+}
+
+// IsWhitelisted tells whether transfers to the address bypass the spend
+// limit.
+func (c *WalletClient) IsWhitelisted(ctx context.Context, address common.Address) (bool, error) {
+	return c.c.IsWhitelisted(ctx, address)
+}
+
+// CheckTransfer checks that the Wallet would transfer amount of asset to the
+// address.
+func (c *WalletClient) CheckTransfer(ctx context.Context, to, asset common.Address, amount *big.Int) error {
+	return c.c.CheckTransfer(ctx, to, asset, amount)
+}
+
+// Transfer transfers amount of asset to the address. It fails without
+// sending the transaction when CheckTransfer does.
+func (c *WalletClient) Transfer(opts *bind.TransactOpts, to, asset common.Address, amount *big.Int) (*types.Transaction, error) {
+	return c.c.Transfer(opts, to, asset, amount)
+}
@@ -0,0 +1,28 @@
+// Package monolith is the stable API of the monolith contracts: the clients of
+// the deployed contracts, their value types and the event indexer.
+//
+// # Compatibility
+//
+// The package follows semantic versioning through its import path. Within v1,
+// no exported identifier is removed or renamed, no signature changes, no field
+// is removed from a struct and no method is removed from a type; only
+// additions are made. Breaking changes are made in a new major version, at
+// pkg/monolith/v2, while v1 is kept alongside it.
+//
+// The generated bindings of pkg/bindings are not covered: they follow the
+// contracts and change whenever they are regenerated. The clients of this
+// package only expose their hand-written methods, so that a regeneration
+// never breaks a program importing only this package.
+//
+// # Deprecation
+//
+// An identifier to be replaced is first marked with a "Deprecated:" paragraph
+// naming its replacement, in a minor release. It keeps working as before
+// until the next major version, where it is removed. The identifiers of the
+// other packages of the module that this package replaces are deprecated the
+// same way.
+//
+// The surface of the package is recorded in test/monolith/testdata/v1.api,
+// and the test of the package fails on any change of the surface that is not
+// an addition.
+package monolith
@@ -0,0 +1,111 @@
+package monolith
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// The errors of the decoding of the indexed logs, to compare with
+// errors.Cause.
+var (
+	// ErrAnonymousLog is a log without topics.
+	ErrAnonymousLog = indexer.ErrAnonymousLog
+	// ErrUnknownEvent is a log whose first topic is no event of the ABI.
+	ErrUnknownEvent = indexer.ErrUnknownEvent
+	// ErrMalformedLog is a log whose data or topics do not match its event.
+	ErrMalformedLog = indexer.ErrMalformedLog
+)
+
+// The types of the event indexer.
+type (
+	// Indexer copies the events of a set of contracts into a Store.
+	Indexer = indexer.Indexer
+	// Contract is a contract whose events are indexed, see NewContract.
+	Contract = indexer.Contract
+	// Event is a decoded contract event.
+	Event = indexer.Event
+	// Position is the position of an event in the chain.
+	Position = indexer.Position
+	// TokenChange is a token added to or removed from the token whitelist.
+	TokenChange = indexer.TokenChange
+	// Query selects stored events.
+	Query = indexer.Query
+	// Backend is the subset of the node API used by the indexer.
+	Backend = indexer.Backend
+	// Store persists indexed events.
+	Store = indexer.Store
+	// Remover is implemented by the stores which can remove the events of
+	// the blocks reorganized.
+	Remover = indexer.Remover
+	// Replacer is implemented by the stores which can replace the events of
+	// the blocks reorganized at once.
+	Replacer = indexer.Replacer
+	// MemoryStore is a Store keeping the events in memory.
+	MemoryStore = indexer.MemoryStore
+	// Handler is notified of the events stored by an Indexer.
+	Handler = indexer.Handler
+	// HandlerFunc adapts a function to the Handler interface.
+	HandlerFunc = indexer.HandlerFunc
+	// Finality is implemented by the handlers requiring the events to be
+	// confirmed before they are handled.
+	Finality = indexer.Finality
+	// Hook transforms the events before they are stored.
+	Hook = indexer.Hook
+	// HookFunc adapts a function to the Hook interface.
+	HookFunc = indexer.HookFunc
+)
+
+// NewIndexer creates a new indexer following the given contracts.
+func NewIndexer(backend Backend, store Store, contracts ...Contract) *Indexer {
+	return indexer.New(backend, store, contracts...)
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return indexer.NewMemoryStore()
+}
+
+// NewContract returns the contract deployed at address to index, given its
+// name in the configuration: controller, holder, licence, oracle,
+// token_whitelist, wallet, wallet_cache or wallet_deployer.
+func NewContract(name string, address common.Address) (Contract, error) {
+	contractABI, ok := bindings.ContractABIs[name]
+	if !ok {
+		return Contract{}, errors.Errorf("unknown contract %q", name)
+	}
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return Contract{}, errors.Wrapf(err, "parsing %s ABI", name)
+	}
+	return Contract{Name: name, Address: address, ABI: parsed}, nil
+}
+
+// WithConfirmations returns a handler handing the events to h once they
+// are buried under each of the given numbers of blocks.
+func WithConfirmations(h Handler, confirmations ...uint64) Handler {
+	return indexer.WithConfirmations(h, confirmations...)
+}
+
+// DecodeTokenChange decodes an AddedToken or RemovedToken event of the token
+// whitelist, it returns false for the other events.
+func DecodeTokenChange(e Event) (TokenChange, bool, error) {
+	return indexer.DecodeTokenChange(e)
+}
+
+// TokenChanges returns a handler calling fn with the tokens added to and
+// removed from the whitelist indexed as contract, in chain order.
+func TokenChanges(contract string, fn func(ctx context.Context, changes []TokenChange) error) Handler {
+	return indexer.TokenChanges(contract, fn)
+}
+
+// FormatArg converts a decoded event argument to a value with a stable JSON
+// and string representation.
+func FormatArg(v interface{}) interface{} {
+	return indexer.FormatArg(v)
+}
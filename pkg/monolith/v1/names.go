@@ -0,0 +1,55 @@
+package monolith
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/names"
+)
+
+// ErrNameNotFound is returned for an ENS name without an address and for an
+// address without a primary name.
+var ErrNameNotFound = names.ErrNotFound
+
+// MainnetENSRegistry is the address of the ENS registry of mainnet and of the
+// public test networks.
+var MainnetENSRegistry = names.MainnetRegistry
+
+// NameResolver resolves the ENS names given for the address parameters of
+// the clients, and the addresses of their results back to their names.
+type NameResolver struct {
+	r *names.Resolver
+}
+
+// NewNameResolver binds the ENS registry deployed at registry.
+func NewNameResolver(registry common.Address, backend bind.ContractBackend) (*NameResolver, error) {
+	r, err := names.NewResolver(registry, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &NameResolver{r: r}, nil
+}
+
+// Resolve returns the address of an ENS name, or the address itself when s
+// is a hex address, so that either can be given:
+//
+//	account, err := resolver.Resolve(ctx, "alice.eth")
+//	...
+//	roles, err := access.Roles(ctx, account)
+func (r *NameResolver) Resolve(ctx context.Context, s string) (common.Address, error) {
+	return r.r.Resolve(ctx, s)
+}
+
+// Lookup returns the primary ENS name of an address, failing with
+// ErrNameNotFound when it has none or when it does not resolve back to the
+// address.
+func (r *NameResolver) Lookup(ctx context.Context, address common.Address) (string, error) {
+	return r.r.Lookup(ctx, address)
+}
+
+// Names returns the primary ENS names of the addresses which have one, e.g.
+// of the parties of the events of an Indexer.
+func (r *NameResolver) Names(ctx context.Context, addresses ...common.Address) (map[common.Address]string, error) {
+	return r.r.Names(ctx, addresses...)
+}
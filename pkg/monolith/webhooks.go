@@ -0,0 +1,52 @@
+package monolith
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"github.com/tokencard/contracts/v2/pkg/webhook"
+)
+
+// webhookQueueSize is the number of deliveries queued before the indexer
+// waits for the notifier.
+const webhookQueueSize = 1024
+
+// startWebhooks delivers the indexed events to the configured endpoints in
+// the background, logging the failed deliveries to output. The secrets of
+// the endpoints are looked up with getenv.
+func startWebhooks(ctx context.Context, cfg *Config, getenv func(string) string, output io.Writer) *webhook.Notifier {
+	n := webhook.NewNotifier(webhookQueueSize, webhookEndpoints(cfg, getenv)...)
+	if cfg.Webhooks.Attempts > 0 {
+		n.Attempts = cfg.Webhooks.Attempts
+	}
+	if cfg.Webhooks.Backoff > 0 {
+		n.Backoff = time.Duration(cfg.Webhooks.Backoff)
+	}
+	if cfg.Webhooks.DeadLetterFile != "" {
+		n.DeadLetter = webhook.NewFileDeadLetter(cfg.Webhooks.DeadLetterFile)
+	}
+	n.ErrorLog = log.New(output, "webhooks: ", log.LstdFlags)
+
+	go n.Run(ctx)
+	return n
+}
+
+func webhookEndpoints(cfg *Config, getenv func(string) string) []webhook.Endpoint {
+	var endpoints []webhook.Endpoint
+	for _, e := range cfg.Webhooks.Endpoints {
+		endpoint := webhook.Endpoint{
+			URL:           e.URL,
+			Secret:        getenv(e.SecretEnv),
+			Events:        e.Events,
+			Confirmations: e.Confirmations,
+		}
+		if e.RecipientKey != "" {
+			// The key was validated by Config.Validate.
+			endpoint.RecipientKey, _ = webhook.ParsePublicKey(e.RecipientKey)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
@@ -0,0 +1,162 @@
+// Package names resolves the ENS names to addresses and the addresses back to
+// their primary ENS names, so that the operators and the reports deal with
+// alice.eth rather than with its address:
+//
+//	r, err := names.NewResolver(names.MainnetRegistry, client)
+//	...
+//	to, err := r.Resolve(ctx, "alice.eth")
+//	...
+//	name, err := r.Lookup(ctx, to)
+//
+// The names are only lowercased, not fully normalised: names with characters
+// outside of ASCII must be given in their normalised form.
+package names
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/externals/ens"
+)
+
+// MainnetRegistry is the address of the ENS registry of mainnet and of the
+// public test networks.
+var MainnetRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ErrNotFound is the cause of the errors of the names without an address and
+// of the addresses without a primary name.
+var ErrNotFound = errors.New("name not found")
+
+// Namehash returns the ENS node of a name.
+func Namehash(name string) common.Hash {
+	node := common.Hash{}
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], label[:])
+	}
+	return node
+}
+
+// IsName tells whether s is an ENS name rather than a hex address.
+func IsName(s string) bool {
+	if common.IsHexAddress(s) || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver resolves the names of an ENS registry.
+type Resolver struct {
+	registry *ens.ENSRegistry
+	backend  bind.ContractBackend
+}
+
+// NewResolver returns a resolver of the names of the ENS registry at
+// registry.
+func NewResolver(registry common.Address, backend bind.ContractBackend) (*Resolver, error) {
+	r, err := ens.NewENSRegistry(registry, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding ENS registry contract")
+	}
+	return &Resolver{registry: r, backend: backend}, nil
+}
+
+// Resolve returns the address of s, an ENS name or a hex address returned as
+// it is. A name without a resolver or an address fails with ErrNotFound.
+func (r *Resolver) Resolve(ctx context.Context, s string) (common.Address, error) {
+	if common.IsHexAddress(s) {
+		return common.HexToAddress(s), nil
+	}
+	if !IsName(s) {
+		return common.Address{}, errors.Errorf("%q is neither an address nor an ENS name", s)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	node := Namehash(s)
+	resolver, err := r.resolver(opts, node)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "resolving %s", s)
+	}
+	a, err := resolver.Addr(opts, node)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "resolving %s", s)
+	}
+	if a == (common.Address{}) {
+		return common.Address{}, errors.Wrapf(ErrNotFound, "%s has no address", s)
+	}
+	return a, nil
+}
+
+// Lookup returns the primary name of an address, from its reverse record,
+// failing with ErrNotFound when it has none. The name must resolve back to
+// the address: anyone can claim any name in the reverse record of their
+// address.
+func (r *Resolver) Lookup(ctx context.Context, a common.Address) (string, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	node := Namehash(hex.EncodeToString(a.Bytes()) + ".addr.reverse")
+	resolver, err := r.resolver(opts, node)
+	if err != nil {
+		return "", errors.Wrapf(err, "looking up %s", a.Hex())
+	}
+	name, err := resolver.Name(opts, node)
+	if err != nil {
+		return "", errors.Wrapf(err, "looking up %s", a.Hex())
+	}
+	if name == "" {
+		return "", errors.Wrapf(ErrNotFound, "%s has no name", a.Hex())
+	}
+	forward, err := r.Resolve(ctx, name)
+	if errors.Cause(err) == ErrNotFound || err == nil && forward != a {
+		return "", errors.Wrapf(ErrNotFound, "%s claims %s, which does not resolve to it", a.Hex(), name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Names returns the primary names of the addresses which have one, to show
+// the addresses of query results and events by name.
+func (r *Resolver) Names(ctx context.Context, addresses ...common.Address) (map[common.Address]string, error) {
+	names := make(map[common.Address]string)
+	for _, a := range addresses {
+		if _, ok := names[a]; ok {
+			continue
+		}
+		name, err := r.Lookup(ctx, a)
+		if errors.Cause(err) == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		names[a] = name
+	}
+	return names, nil
+}
+
+// resolver returns the resolver of a node, failing with ErrNotFound when it
+// has none.
+func (r *Resolver) resolver(opts *bind.CallOpts, node common.Hash) (*ens.PublicResolver, error) {
+	a, err := r.registry.Resolver(opts, node)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting resolver")
+	}
+	if a == (common.Address{}) {
+		return nil, errors.Wrap(ErrNotFound, "no resolver")
+	}
+	return ens.NewPublicResolver(a, r.backend)
+}
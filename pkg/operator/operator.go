@@ -0,0 +1,315 @@
+// Package operator runs monolithd instances in Kubernetes clusters. Its
+// Reconciler turns each Monolith resource of package v1alpha1 into the
+// ConfigMap holding the configuration files of monolithd and the Deployment
+// running it, and reports the rollout in the status of the resource.
+//
+// The configuration files are checked as monolithd would check them before
+// being rolled out: an invalid spec is reported with a false Ready condition
+// and the InvalidSpec reason, while the running pods keep their last valid
+// configuration. The pods are restarted when the configuration files change,
+// monolithd reloading only part of its settings.
+//
+// The desired state of the contracts is reconciled by monolithd itself, from
+// the drift spec of the resource, see package reconcile.
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/monolith"
+	"github.com/tokencard/contracts/v2/pkg/operator/v1alpha1"
+	"github.com/tokencard/contracts/v2/pkg/reconcile"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ConfigDir is where the ConfigMap of a Monolith is mounted.
+	ConfigDir = "/etc/monolith"
+	// SecretsDir is where the Secret of a Monolith is mounted, its keystore
+	// file is SecretsDir/operator.json.
+	SecretsDir = "/secrets"
+	// ConfigHashAnnotation annotates the pods with the hash of their
+	// configuration files.
+	ConfigHashAnnotation = "monolith.tokencard.io/config-hash"
+
+	// ReadyCondition is the type of the condition reporting the rollout.
+	ReadyCondition = "Ready"
+
+	configFile  = "monolithd.json"
+	methodsFile = "methods.json"
+	specFile    = "spec.yaml"
+	container   = "monolithd"
+)
+
+// Reconciler reconciles the Monolith resources.
+type Reconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	Logger logging.Logger
+}
+
+// SetupWithManager registers the reconciler in a manager, reconciling a
+// Monolith when it or the objects it owns change.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Monolith{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&appsv1.Deployment{}).
+		Complete(r)
+}
+
+// Reconcile brings the ConfigMap and the Deployment of a Monolith to its
+// spec. They are deleted with the Monolith, which they are owned by.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrlreconcile.Request) (ctrlreconcile.Result, error) {
+	logger := logging.With(r.Logger, "monolith", req.NamespacedName.String())
+
+	m := &v1alpha1.Monolith{}
+	err := r.Client.Get(ctx, req.NamespacedName, m)
+	if apierrors.IsNotFound(err) {
+		return ctrlreconcile.Result{}, nil
+	}
+	if err != nil {
+		return ctrlreconcile.Result{}, errors.Wrap(err, "getting monolith")
+	}
+
+	files, cfg, err := ConfigFiles(m.Spec)
+	if err != nil {
+		logger.Warn("Invalid spec, the running pods are left as is", "err", err)
+		m.Status.ObservedGeneration = m.Generation
+		meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{
+			Type:               ReadyCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "InvalidSpec",
+			Message:            err.Error(),
+			ObservedGeneration: m.Generation,
+		})
+		return ctrlreconcile.Result{}, r.updateStatus(ctx, m)
+	}
+	hash := hashFiles(files)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: m.Name + "-config", Namespace: m.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Labels = labels(m)
+		cm.Data = files
+		return controllerutil.SetControllerReference(m, cm, r.Scheme)
+	})
+	if err != nil {
+		return ctrlreconcile.Result{}, errors.Wrap(err, "applying configmap")
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Applied configmap", "name", cm.Name, "operation", op)
+	}
+
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: m.Name, Namespace: m.Namespace}}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, d, func() error {
+		deployment(d, m, cm.Name, hash, cfg)
+		return controllerutil.SetControllerReference(m, d, r.Scheme)
+	})
+	if err != nil {
+		return ctrlreconcile.Result{}, errors.Wrap(err, "applying deployment")
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Applied deployment", "name", d.Name, "operation", op, "config_hash", hash)
+	}
+
+	m.Status.ObservedGeneration = m.Generation
+	m.Status.ConfigHash = hash
+	m.Status.ReadyReplicas = d.Status.ReadyReplicas
+	ready := metav1.Condition{
+		Type:               ReadyCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Progressing",
+		Message:            strconv.Itoa(int(d.Status.ReadyReplicas)) + " of " + strconv.Itoa(int(*d.Spec.Replicas)) + " pods are ready",
+		ObservedGeneration: m.Generation,
+	}
+	if d.Status.ObservedGeneration >= d.Generation && d.Status.UpdatedReplicas == *d.Spec.Replicas && d.Status.ReadyReplicas == *d.Spec.Replicas {
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "RolledOut"
+	}
+	meta.SetStatusCondition(&m.Status.Conditions, ready)
+	return ctrlreconcile.Result{}, r.updateStatus(ctx, m)
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, m *v1alpha1.Monolith) error {
+	err := r.Client.Status().Update(ctx, m)
+	if err != nil {
+		return errors.Wrap(err, "updating monolith status")
+	}
+	return nil
+}
+
+// ConfigFiles returns the configuration files of a spec, by name, and the
+// configuration of monolithd they hold. The method defaults and drift spec
+// paths of the configuration are set to the files mounted from the spec.
+func ConfigFiles(spec v1alpha1.MonolithSpec) (map[string]string, *monolith.Config, error) {
+	var raw map[string]interface{}
+	err := json.Unmarshal([]byte(spec.Config), &raw)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "decoding config")
+	}
+	if raw == nil {
+		return nil, nil, errors.New("config is empty")
+	}
+
+	files := map[string]string{}
+	if spec.MethodDefaults != "" {
+		_, err := txmgr.LoadRegistry(strings.NewReader(spec.MethodDefaults))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "methodDefaults")
+		}
+		files[methodsFile] = spec.MethodDefaults
+		raw["method_defaults_file"] = path.Join(ConfigDir, methodsFile)
+	}
+	if spec.DriftSpec != "" {
+		_, err := reconcile.LoadSpec(strings.NewReader(spec.DriftSpec))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "driftSpec")
+		}
+		files[specFile] = spec.DriftSpec
+		drift, ok := raw["drift"].(map[string]interface{})
+		if !ok {
+			drift = map[string]interface{}{}
+			raw["drift"] = drift
+		}
+		drift["spec_file"] = path.Join(ConfigDir, specFile)
+	}
+
+	b, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "encoding config")
+	}
+	cfg := monolith.DefaultConfig()
+	err = json.Unmarshal(b, cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "decoding config")
+	}
+	if cfg.RPCURL == "" {
+		return nil, nil, errors.New("rpc_url is not set in the config")
+	}
+	err = cfg.Validate()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "config")
+	}
+	files[configFile] = string(b)
+	return files, cfg, nil
+}
+
+// hashFiles returns the hash of the configuration files.
+func hashFiles(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(files[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func labels(m *v1alpha1.Monolith) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "monolithd",
+		"app.kubernetes.io/instance":   m.Name,
+		"app.kubernetes.io/managed-by": "monolith-operator",
+	}
+}
+
+// deployment sets the fields of the Deployment of a Monolith, leaving the
+// fields defaulted by the API server as they are.
+func deployment(d *appsv1.Deployment, m *v1alpha1.Monolith, configMap, hash string, cfg *monolith.Config) {
+	replicas := int32(1)
+	if m.Spec.Replicas != nil {
+		replicas = *m.Spec.Replicas
+	}
+	selector := map[string]string{"app.kubernetes.io/instance": m.Name}
+
+	d.Labels = labels(m)
+	d.Spec.Replicas = &replicas
+	if d.Spec.Selector == nil {
+		// The selector of a Deployment cannot change.
+		d.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	}
+	d.Spec.Template.Labels = labels(m)
+	if d.Spec.Template.Annotations == nil {
+		d.Spec.Template.Annotations = map[string]string{}
+	}
+	d.Spec.Template.Annotations[ConfigHashAnnotation] = hash
+
+	volumes := []corev1.Volume{{
+		Name:         "config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}}},
+	}}
+	mounts := []corev1.VolumeMount{{Name: "config", MountPath: ConfigDir, ReadOnly: true}}
+	var env []corev1.EnvFromSource
+	if m.Spec.SecretName != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name:         "secrets",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: m.Spec.SecretName}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "secrets", MountPath: SecretsDir, ReadOnly: true})
+		env = []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: m.Spec.SecretName}}}}
+	}
+	d.Spec.Template.Spec.Volumes = volumes
+
+	var c *corev1.Container
+	for i := range d.Spec.Template.Spec.Containers {
+		if d.Spec.Template.Spec.Containers[i].Name == container {
+			c = &d.Spec.Template.Spec.Containers[i]
+		}
+	}
+	if c == nil {
+		d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: container})
+		c = &d.Spec.Template.Spec.Containers[len(d.Spec.Template.Spec.Containers)-1]
+	}
+	c.Image = m.Spec.Image
+	c.Args = []string{"-config=" + path.Join(ConfigDir, configFile)}
+	c.EnvFrom = env
+	c.VolumeMounts = mounts
+	c.Ports = ports(cfg)
+}
+
+// ports returns the container ports of the listen addresses of monolithd.
+func ports(cfg *monolith.Config) []corev1.ContainerPort {
+	var ports []corev1.ContainerPort
+	add := func(name, address string) {
+		_, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(port)
+		if err != nil || n <= 0 {
+			return
+		}
+		ports = append(ports, corev1.ContainerPort{Name: name, ContainerPort: int32(n), Protocol: corev1.ProtocolTCP})
+	}
+	add("http", cfg.ListenAddress)
+	if cfg.GRPC.ListenAddress != "" {
+		add("grpc", cfg.GRPC.ListenAddress)
+	}
+	return ports
+}
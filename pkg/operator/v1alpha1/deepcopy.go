@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies m into out.
+func (m *Monolith) DeepCopyInto(out *Monolith) {
+	*out = *m
+	out.TypeMeta = m.TypeMeta
+	m.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	m.Spec.DeepCopyInto(&out.Spec)
+	m.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy returns a copy of m.
+func (m *Monolith) DeepCopy() *Monolith {
+	if m == nil {
+		return nil
+	}
+	out := new(Monolith)
+	m.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (m *Monolith) DeepCopyObject() runtime.Object {
+	return m.DeepCopy()
+}
+
+// DeepCopyInto copies s into out.
+func (s *MonolithSpec) DeepCopyInto(out *MonolithSpec) {
+	*out = *s
+	if s.Replicas != nil {
+		out.Replicas = new(int32)
+		*out.Replicas = *s.Replicas
+	}
+}
+
+// DeepCopyInto copies s into out.
+func (s *MonolithStatus) DeepCopyInto(out *MonolithStatus) {
+	*out = *s
+	if s.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(s.Conditions))
+		for i := range s.Conditions {
+			s.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
+}
+
+// DeepCopyInto copies l into out.
+func (l *MonolithList) DeepCopyInto(out *MonolithList) {
+	*out = *l
+	out.TypeMeta = l.TypeMeta
+	l.ListMeta.DeepCopyInto(&out.ListMeta)
+	if l.Items != nil {
+		out.Items = make([]Monolith, len(l.Items))
+		for i := range l.Items {
+			l.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a copy of l.
+func (l *MonolithList) DeepCopy() *MonolithList {
+	if l == nil {
+		return nil
+	}
+	out := new(MonolithList)
+	l.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (l *MonolithList) DeepCopyObject() runtime.Object {
+	return l.DeepCopy()
+}
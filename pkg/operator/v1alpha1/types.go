@@ -0,0 +1,75 @@
+// Package v1alpha1 defines the Monolith custom resource managed by the
+// operator of package operator, in the monolith.tokencard.io API group.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersion is the API group and version of the Monolith resource.
+var GroupVersion = schema.GroupVersion{Group: "monolith.tokencard.io", Version: "v1alpha1"}
+
+var schemeBuilder = runtime.NewSchemeBuilder(func(s *runtime.Scheme) error {
+	s.AddKnownTypes(GroupVersion, &Monolith{}, &MonolithList{})
+	metav1.AddToGroupVersion(s, GroupVersion)
+	return nil
+})
+
+// AddToScheme registers the Monolith resource in a scheme.
+var AddToScheme = schemeBuilder.AddToScheme
+
+// Monolith is a monolithd instance: a Deployment running monolithd with the
+// configuration, method defaults and drift spec of the resource, mounted from
+// a ConfigMap.
+type Monolith struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MonolithSpec   `json:"spec,omitempty"`
+	Status MonolithStatus `json:"status,omitempty"`
+}
+
+// MonolithSpec is the desired state of a monolithd instance.
+type MonolithSpec struct {
+	// Image is the monolithd image.
+	Image string `json:"image"`
+	// Replicas is the number of monolithd pods, 1 when nil.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Config is the JSON configuration of monolithd, see monolith.Config.
+	// The paths of method_defaults_file and drift.spec_file are set to the
+	// mounted MethodDefaults and DriftSpec when they are given.
+	Config string `json:"config"`
+	// MethodDefaults are the JSON method defaults of the transactions, see
+	// txmgr.LoadRegistry.
+	MethodDefaults string `json:"methodDefaults,omitempty"`
+	// DriftSpec is the YAML desired state of the contracts, which monolithd
+	// checks them against and, with drift.remediate, brings them back to, see
+	// package reconcile.
+	DriftSpec string `json:"driftSpec,omitempty"`
+	// SecretName is the Secret holding the secrets of monolithd: the
+	// keystore file under operator.json and the environment variables named
+	// in the configuration under their name. No Secret is mounted when empty.
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// MonolithStatus is the observed state of a monolithd instance.
+type MonolithStatus struct {
+	// ObservedGeneration is the generation of the spec last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ConfigHash is the hash of the configuration files rolled out.
+	ConfigHash string `json:"configHash,omitempty"`
+	// ReadyReplicas is the number of ready monolithd pods.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// Conditions hold the Ready condition, false with the reason
+	// InvalidSpec while the configuration or the drift spec is invalid.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MonolithList is a list of Monolith resources.
+type MonolithList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Monolith `json:"items"`
+}
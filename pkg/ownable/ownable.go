@@ -0,0 +1,188 @@
+// Package ownable administers the ownership of the contracts inheriting the
+// Ownable contract of the monolith, whose ownership is transferred with a
+// transferable flag locking it once cleared.
+//
+// The transfers are guarded: they are checked against the state of the
+// contract before being sent, rather than reverting on chain. Audit checks
+// the ownership of all the contracts of a network at once.
+package ownable
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// Errors of the guards, matching the checks of the contract.
+var (
+	ErrNotOwner        = errors.New("sender is not an owner")
+	ErrNotTransferable = errors.New("ownership is not transferable")
+	ErrZeroAddress     = errors.New("owner cannot be set to zero address")
+)
+
+// Contract is the binding of an ownable contract.
+type Contract interface {
+	bindings.OwnableCaller
+	bindings.OwnableTransactor
+}
+
+// Contracts are the constructors of the bindings of the ownable contracts, by
+// the names of bindings.ContractABIs.
+var Contracts = map[string]func(common.Address, bind.ContractBackend) (Contract, error){
+	"controller": func(a common.Address, backend bind.ContractBackend) (Contract, error) {
+		return bindings.NewController(a, backend)
+	},
+	"wallet": func(a common.Address, backend bind.ContractBackend) (Contract, error) {
+		return bindings.NewWallet(a, backend)
+	},
+}
+
+// Names returns the names of the ownable contracts, sorted.
+func Names() []string {
+	names := make([]string, 0, len(Contracts))
+	for n := range Contracts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bind binds the named ownable contract at address.
+func Bind(name string, address common.Address, backend bind.ContractBackend) (Contract, error) {
+	newContract, ok := Contracts[name]
+	if !ok {
+		return nil, errors.Errorf("contract %q is not ownable", name)
+	}
+	return newContract(address, backend)
+}
+
+// State is the ownership of a contract.
+type State struct {
+	Owner        common.Address `json:"owner"`
+	Transferable bool           `json:"transferable"`
+}
+
+// Renounced tells whether the ownership was renounced, leaving the owner
+// restricted methods uncallable.
+func (s State) Renounced() bool {
+	return s.Owner == (common.Address{})
+}
+
+// Inspect returns the ownership of a contract.
+func Inspect(opts *bind.CallOpts, c Contract) (State, error) {
+	owner, err := c.Owner(opts)
+	if err != nil {
+		return State{}, errors.Wrap(err, "calling owner")
+	}
+	transferable, err := c.IsTransferable(opts)
+	if err != nil {
+		return State{}, errors.Wrap(err, "calling isTransferable")
+	}
+	return State{Owner: owner, Transferable: transferable}, nil
+}
+
+// guard checks that the sender of opts may change the ownership of c.
+func guard(opts *bind.TransactOpts, c Contract) error {
+	s, err := Inspect(&bind.CallOpts{Context: opts.Context, From: opts.From}, c)
+	if err != nil {
+		return err
+	}
+	if s.Owner != opts.From {
+		return errors.Wrapf(ErrNotOwner, "%s is owned by %s", opts.From.Hex(), s.Owner.Hex())
+	}
+	if !s.Transferable {
+		return ErrNotTransferable
+	}
+	return nil
+}
+
+// Transfer transfers the ownership of c to account, locking it when
+// transferable is false. It fails without sending the transaction when the
+// sender is not the owner or the ownership is locked.
+func Transfer(opts *bind.TransactOpts, c Contract, account common.Address, transferable bool) (*types.Transaction, error) {
+	if account == (common.Address{}) {
+		return nil, ErrZeroAddress
+	}
+	err := guard(opts, c)
+	if err != nil {
+		return nil, err
+	}
+	return c.TransferOwnership(opts, account, transferable)
+}
+
+// Renounce leaves c without an owner, for good. It fails without sending the
+// transaction when the sender is not the owner or the ownership is locked.
+func Renounce(opts *bind.TransactOpts, c Contract) (*types.Transaction, error) {
+	err := guard(opts, c)
+	if err != nil {
+		return nil, err
+	}
+	return c.RenounceOwnership(opts)
+}
+
+// Policy is the expected ownership of the contracts.
+type Policy struct {
+	// Owners are the accounts allowed to own the contracts, any when empty.
+	Owners []common.Address
+	// Locked requires the ownership to be no longer transferable.
+	Locked bool
+}
+
+// Entry is the ownership of a contract checked by Audit.
+type Entry struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	State
+	// Problems are the violations of the policy.
+	Problems []string `json:"problems,omitempty"`
+	Err      string   `json:"error,omitempty"`
+}
+
+// OK tells whether the contract complies with the policy.
+func (e Entry) OK() bool {
+	return e.Err == "" && len(e.Problems) == 0
+}
+
+// Audit checks the ownership of the ownable contracts among contracts, by
+// name as in registry.Network, against the policy. The other contracts are
+// ignored. The entries are sorted by name.
+func Audit(ctx context.Context, backend bind.ContractBackend, contracts map[string]common.Address, p Policy) []Entry {
+	owners := make(map[common.Address]bool, len(p.Owners))
+	for _, o := range p.Owners {
+		owners[o] = true
+	}
+
+	var entries []Entry
+	for name, address := range contracts {
+		if _, ok := Contracts[name]; !ok {
+			continue
+		}
+		e := Entry{Name: name, Address: address}
+		c, err := Bind(name, address, backend)
+		if err == nil {
+			e.State, err = Inspect(&bind.CallOpts{Context: ctx}, c)
+		}
+		if err != nil {
+			e.Err = err.Error()
+			entries = append(entries, e)
+			continue
+		}
+		switch {
+		case e.Renounced():
+			e.Problems = append(e.Problems, "ownership is renounced")
+		case len(owners) > 0 && !owners[e.Owner]:
+			e.Problems = append(e.Problems, "owner "+e.Owner.Hex()+" is not expected")
+		}
+		if p.Locked && e.Transferable {
+			e.Problems = append(e.Problems, "ownership is transferable")
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
@@ -0,0 +1,299 @@
+// Package proof gathers the evidence of transactions for the auditors: the
+// receipts of the transactions, the headers of their blocks and the Merkle
+// proofs of the receipts in the receipts tries of the blocks, in a signed
+// Bundle:
+//
+//	b, err := proof.Gather(ctx, client, payouts...)
+//	...
+//	err = b.Sign(signing.KeySigner(key))
+//
+// The auditor checks the signature of the bundle, that each header hashes to
+// the block hash of its entry, that each receipt is in the receipts root of
+// its header, and that the block hashes are those of the chain with any node
+// or block explorer they trust, see Entry.Verify and CheckCanonical.
+package proof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// ErrInvalidProof is the cause of the errors of the entries whose receipt,
+// header or proof do not match.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// Backend reads the receipts and the blocks, *ethclient.Client implements it.
+type Backend interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+}
+
+// HeaderReader reads the headers of the chain, *ethclient.Client implements
+// it.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Entry is the evidence of a transaction.
+type Entry struct {
+	TxHash      common.Hash `json:"tx_hash"`
+	BlockNumber uint64      `json:"block_number"`
+	BlockHash   common.Hash `json:"block_hash"`
+	// Index is the index of the transaction in its block, the key of its
+	// receipt in the receipts trie.
+	Index uint `json:"index"`
+	// Header is the RLP encoding of the header of the block.
+	Header hexutil.Bytes `json:"header"`
+	// Receipt is the RLP encoding of the consensus fields of the receipt:
+	// its status, cumulative gas used, bloom and logs.
+	Receipt hexutil.Bytes `json:"receipt"`
+	// Proof are the nodes of the receipts trie from its root to the
+	// receipt, none when the receipts of the block could not be read.
+	Proof []hexutil.Bytes `json:"proof,omitempty"`
+	// ProofErr is the reason the proof is missing.
+	ProofErr string `json:"proof_error,omitempty"`
+}
+
+// Bundle is a signed set of entries.
+type Bundle struct {
+	Time    time.Time `json:"time"`
+	Entries []Entry   `json:"entries"`
+	// Signature is the signature of the hash of the bundle, see Hash.
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// Gather returns the unsigned bundle of the transactions. The proof of an
+// entry is left out, with the reason, when the receipts of its block can not
+// be read or do not match its receipts root, e.g. from a node not serving the
+// consensus fields of the receipts.
+func Gather(ctx context.Context, backend Backend, txHashes ...common.Hash) (*Bundle, error) {
+	b := &Bundle{Time: time.Now().UTC(), Entries: make([]Entry, 0, len(txHashes))}
+	blocks := make(map[common.Hash]*types.Block)
+	tries := make(map[common.Hash]*trie.Trie)
+	for _, h := range txHashes {
+		r, err := backend.TransactionReceipt(ctx, h)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting receipt of transaction %s", h.Hex())
+		}
+		block, ok := blocks[r.BlockHash]
+		if !ok {
+			block, err = backend.BlockByHash(ctx, r.BlockHash)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting block %s of transaction %s", r.BlockHash.Hex(), h.Hex())
+			}
+			blocks[r.BlockHash] = block
+		}
+		header, err := rlp.EncodeToBytes(block.Header())
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding header of block %s", r.BlockHash.Hex())
+		}
+		receipt, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding receipt of transaction %s", h.Hex())
+		}
+		e := Entry{
+			TxHash:      h,
+			BlockNumber: r.BlockNumber.Uint64(),
+			BlockHash:   r.BlockHash,
+			Index:       r.TransactionIndex,
+			Header:      header,
+			Receipt:     receipt,
+		}
+
+		t, ok := tries[r.BlockHash]
+		if !ok {
+			t, err = receiptsTrie(ctx, backend, block)
+			if err != nil {
+				e.ProofErr = err.Error()
+			}
+			tries[r.BlockHash] = t
+		} else if t == nil {
+			e.ProofErr = "the receipts of the block could not be read"
+		}
+		if t != nil {
+			e.Proof, err = prove(t, e.Index)
+			if err != nil {
+				return nil, errors.Wrapf(err, "proving receipt of transaction %s", h.Hex())
+			}
+		}
+		b.Entries = append(b.Entries, e)
+	}
+	return b, nil
+}
+
+// receiptsTrie returns the receipts trie of the block, checking its root.
+func receiptsTrie(ctx context.Context, backend Backend, block *types.Block) (*trie.Trie, error) {
+	receipts := make(types.Receipts, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		r, err := backend.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting receipt of transaction %s", tx.Hash().Hex())
+		}
+		receipts[i] = r
+	}
+	t := new(trie.Trie)
+	for i, r := range receipts {
+		key, err := rlp.EncodeToBytes(uint(i))
+		if err != nil {
+			return nil, err
+		}
+		value, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		t.Update(key, value)
+	}
+	if t.Hash() != block.ReceiptHash() {
+		return nil, errors.Errorf("the receipts of block %s do not match its receipts root", block.Hash().Hex())
+	}
+	return t, nil
+}
+
+func prove(t *trie.Trie, index uint) ([]hexutil.Bytes, error) {
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, err
+	}
+	nodes := &proofList{}
+	err = t.Prove(key, 0, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return *nodes, nil
+}
+
+// proofList collects the nodes of a proof in order.
+type proofList []hexutil.Bytes
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	return errors.New("deleting a proof node")
+}
+
+// Verify checks that the header of the entry hashes to its block hash and
+// that its receipt is the receipt of its transaction in the receipts root of
+// the header, and returns the decoded receipt. An entry without a proof is
+// invalid. The errors wrap ErrInvalidProof.
+func (e *Entry) Verify() (*types.Receipt, error) {
+	if crypto.Keccak256Hash(e.Header) != e.BlockHash {
+		return nil, errors.Wrapf(ErrInvalidProof, "header of transaction %s does not hash to block %s", e.TxHash.Hex(), e.BlockHash.Hex())
+	}
+	var header types.Header
+	err := rlp.DecodeBytes(e.Header, &header)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidProof, "decoding header of transaction %s: %v", e.TxHash.Hex(), err)
+	}
+	if header.Number.Uint64() != e.BlockNumber {
+		return nil, errors.Wrapf(ErrInvalidProof, "header of transaction %s is block %d, not %d", e.TxHash.Hex(), header.Number.Uint64(), e.BlockNumber)
+	}
+	if len(e.Proof) == 0 {
+		return nil, errors.Wrapf(ErrInvalidProof, "receipt of transaction %s has no proof", e.TxHash.Hex())
+	}
+
+	db := memorydb.New()
+	for _, node := range e.Proof {
+		err = db.Put(crypto.Keccak256(node), node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := rlp.EncodeToBytes(e.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, _, err := trie.VerifyProof(header.ReceiptHash, key, db)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidProof, "proof of transaction %s: %v", e.TxHash.Hex(), err)
+	}
+	if !bytes.Equal(value, e.Receipt) {
+		return nil, errors.Wrapf(ErrInvalidProof, "receipt of transaction %s is not in the receipts root of block %s", e.TxHash.Hex(), e.BlockHash.Hex())
+	}
+	r := &types.Receipt{}
+	err = rlp.DecodeBytes(e.Receipt, r)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidProof, "decoding receipt of transaction %s: %v", e.TxHash.Hex(), err)
+	}
+	return r, nil
+}
+
+// CheckCanonical checks that the blocks of the entries are the blocks of the
+// chain of headers at their number, e.g. a node of the auditor.
+func (b *Bundle) CheckCanonical(ctx context.Context, headers HeaderReader) error {
+	for _, e := range b.Entries {
+		h, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(e.BlockNumber))
+		if err != nil {
+			return errors.Wrapf(err, "getting header of block %d", e.BlockNumber)
+		}
+		if h.Hash() != e.BlockHash {
+			return errors.Wrapf(ErrInvalidProof, "block %d of transaction %s is %s, not %s", e.BlockNumber, e.TxHash.Hex(), h.Hash().Hex(), e.BlockHash.Hex())
+		}
+	}
+	return nil
+}
+
+// Hash returns the keccak256 hash of the JSON encoding of the bundle without
+// its signature.
+func (b *Bundle) Hash() (common.Hash, error) {
+	unsigned := *b
+	unsigned.Signature = nil
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "encoding bundle")
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Sign signs the hash of the bundle.
+func (b *Bundle) Sign(signHash signing.SignHashFunc) error {
+	hash, err := b.Hash()
+	if err != nil {
+		return err
+	}
+	b.Signature, err = signing.SignHash(hash, signHash)
+	return errors.Wrap(err, "signing bundle")
+}
+
+// Signer returns the address which signed the bundle.
+func (b *Bundle) Signer() (common.Address, error) {
+	hash, err := b.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return signing.RecoverHash(hash, b.Signature)
+}
+
+// Verify checks that the bundle was signed by signer and verifies its
+// entries, see Entry.Verify.
+func (b *Bundle) Verify(signer common.Address) error {
+	recovered, err := b.Signer()
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return errors.Wrapf(signing.ErrInvalidSignature, "bundle signed by %s, not %s", recovered.Hex(), signer.Hex())
+	}
+	for i := range b.Entries {
+		_, err := b.Entries[i].Verify()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package provision
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Assignment is a wallet assigned to its owner.
+type Assignment struct {
+	Time   time.Time      `json:"time"`
+	Owner  common.Address `json:"owner"`
+	Wallet common.Address `json:"wallet"`
+	// TxHash is the transaction of the assignment, it is zero for the
+	// wallets found already assigned on the chain.
+	TxHash      common.Hash `json:"tx_hash,omitempty"`
+	BlockNumber uint64      `json:"block_number,omitempty"`
+}
+
+// Assignments records the wallets assigned.
+type Assignments interface {
+	Record(a Assignment) error
+	// Get returns the assignment of an owner, false when none was
+	// recorded.
+	Get(owner common.Address) (Assignment, bool, error)
+}
+
+// MemoryAssignments keeps the assignments in memory.
+type MemoryAssignments struct {
+	mu      sync.Mutex
+	byOwner map[common.Address]Assignment
+}
+
+// NewMemoryAssignments returns empty in-memory assignments.
+func NewMemoryAssignments() *MemoryAssignments {
+	return &MemoryAssignments{byOwner: make(map[common.Address]Assignment)}
+}
+
+// Record implements Assignments.
+func (m *MemoryAssignments) Record(a Assignment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byOwner[a.Owner] = a
+	return nil
+}
+
+// Get implements Assignments.
+func (m *MemoryAssignments) Get(owner common.Address) (Assignment, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.byOwner[owner]
+	return a, ok, nil
+}
+
+// FileAssignments appends the assignments to a file as JSON lines and keeps
+// them in memory, read back from the file when it is opened.
+type FileAssignments struct {
+	memory *MemoryAssignments
+	path   string
+}
+
+// OpenFileAssignments opens the assignments stored in path, which is created
+// on the first assignment.
+func OpenFileAssignments(path string) (*FileAssignments, error) {
+	f := &FileAssignments{memory: NewMemoryAssignments(), path: path}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening assignments")
+	}
+	defer file.Close()
+
+	s := bufio.NewScanner(file)
+	for line := 1; s.Scan(); line++ {
+		var a Assignment
+		err := json.Unmarshal(s.Bytes(), &a)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s line %d", path, line)
+		}
+		f.memory.byOwner[a.Owner] = a
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading assignments")
+	}
+	return f, nil
+}
+
+// Record implements Assignments.
+func (f *FileAssignments) Record(a Assignment) error {
+	f.memory.mu.Lock()
+	defer f.memory.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening assignments")
+	}
+	err = json.NewEncoder(file).Encode(a)
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "writing assignment")
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.Wrap(err, "writing assignment")
+	}
+	f.memory.byOwner[a.Owner] = a
+	return nil
+}
+
+// Get implements Assignments.
+func (f *FileAssignments) Get(owner common.Address) (Assignment, bool, error) {
+	return f.memory.Get(owner)
+}
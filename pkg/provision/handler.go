@@ -0,0 +1,85 @@
+package provision
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// AssignRequest is the body of POST /wallets.
+type AssignRequest struct {
+	Owner common.Address `json:"owner"`
+}
+
+// CacheResponse is the body of GET /wallets.
+type CacheResponse struct {
+	Cached int `json:"cached"`
+	Target int `json:"target"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler serves the provisioner:
+//
+//	POST /wallets    assign a wallet to an owner, returns its Assignment
+//	GET  /wallets    number of cached wallets
+//
+// Assigning a wallet waits for its transaction to be mined, and returns the
+// same wallet when called again for the same owner.
+func NewHandler(p *Provisioner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.TrimSuffix(req.URL.Path, "/") != "/wallets":
+			writeError(w, http.StatusNotFound, errors.Errorf("%s %s not found", req.Method, req.URL.Path))
+		case req.Method == http.MethodPost:
+			handleAssign(p, w, req)
+		case req.Method == http.MethodGet:
+			handleCache(p, w, req)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.Errorf("%s %s not allowed", req.Method, req.URL.Path))
+		}
+	})
+}
+
+func handleAssign(p *Provisioner, w http.ResponseWriter, req *http.Request) {
+	var body AssignRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+		return
+	}
+	a, err := p.AssignWallet(req.Context(), body.Owner)
+	if errors.Cause(err) == ErrZeroOwner {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func handleCache(p *Provisioner, w http.ResponseWriter, req *http.Request) {
+	cached, err := p.Cached(req.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, CacheResponse{Cached: cached, Target: p.Target})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
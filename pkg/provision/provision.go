@@ -0,0 +1,296 @@
+// Package provision onboards the users by assigning them a wallet. Deploying
+// a wallet costs several million gas, so the wallets are deployed in batches
+// ahead of time into the WalletCache, by anyone, and assigning one with the
+// WalletDeployer only transfers a cached wallet to its owner:
+//
+//	p, err := provision.New(deployer, cache, backend, receipts, opts, provision.NewMemoryAssignments())
+//	...
+//	p.Target = 20
+//	go p.Run(ctx, time.Minute)
+//	a, err := p.AssignWallet(ctx, owner)
+package provision
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// ErrZeroOwner is returned when assigning a wallet to the zero address.
+var ErrZeroOwner = errors.New("the owner is the zero address")
+
+// Provisioner keeps wallets cached and assigns them to their owners. The
+// account of the transactions must be a controller to assign wallets.
+type Provisioner struct {
+	deployer    *bindings.WalletDeployer
+	address     common.Address
+	cache       *bindings.WalletCache
+	cacheAddr   common.Address
+	receipts    bind.DeployBackend
+	opts        *bind.TransactOpts
+	assignments Assignments
+
+	// Target is the number of cached wallets kept ready for the next
+	// assignments.
+	Target int
+	// BatchSize caps the number of wallets cached by a refill, and the
+	// number of transactions CacheWallets sends before waiting for them,
+	// unlimited when not positive. One sends them sequentially.
+	BatchSize int
+	Logger    logging.Logger
+
+	// mu serializes the assignments, and refill the refills, which do not
+	// wait for each other. send serializes the sending of the
+	// transactions, which take the pending nonce of the account.
+	mu     sync.Mutex
+	refill sync.Mutex
+	send   sync.Mutex
+}
+
+// New creates a provisioner of the wallets of the deployer, cached by cache,
+// sending transactions with opts through backend and waiting for them in
+// receipts.
+func New(deployer, cache common.Address, backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, assignments Assignments) (*Provisioner, error) {
+	d, err := bindings.NewWalletDeployer(deployer, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet deployer contract")
+	}
+	c, err := bindings.NewWalletCache(cache, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet cache contract")
+	}
+	return &Provisioner{
+		deployer:    d,
+		address:     deployer,
+		cache:       c,
+		cacheAddr:   cache,
+		receipts:    receipts,
+		opts:        opts,
+		assignments: assignments,
+	}, nil
+}
+
+// Cached returns the number of cached wallets.
+func (p *Provisioner) Cached(ctx context.Context) (int, error) {
+	n, err := p.cache.CachedWalletsCount(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, errors.Wrap(err, "calling cachedWalletsCount")
+	}
+	return int(n.Int64()), nil
+}
+
+// Refill caches the wallets missing to reach Target, at most BatchSize of
+// them, and returns how many it cached. The transactions are sent together
+// and waited for once all sent.
+func (p *Provisioner) Refill(ctx context.Context) (int, error) {
+	p.refill.Lock()
+	defer p.refill.Unlock()
+
+	cached, err := p.Cached(ctx)
+	if err != nil {
+		return 0, err
+	}
+	missing := p.Target - cached
+	if p.BatchSize > 0 && missing > p.BatchSize {
+		missing = p.BatchSize
+	}
+	if missing <= 0 {
+		return 0, nil
+	}
+
+	wallets, err := p.cacheBatch(ctx, missing)
+	if len(wallets) > 0 {
+		logging.Or(p.Logger).Info("Cached wallets", "count", len(wallets))
+	}
+	return len(wallets), err
+}
+
+// BatchError is the error of CacheWallets once some of the wallets were
+// cached.
+type BatchError struct {
+	// Cached are the wallets cached before the failure.
+	Cached []common.Address
+	// Missing is the number of wallets requested which were not cached.
+	Missing int
+	Err     error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("cached %d wallets, %d missing: %v", len(e.Cached), e.Missing, e.Err)
+}
+
+// Cause returns the failure which stopped the batch.
+func (e *BatchError) Cause() error {
+	return e.Err
+}
+
+// CacheWallets caches n wallets, whatever Target, and returns their
+// addresses. The transactions are sent in chunks of BatchSize, each chunk
+// sent together with consecutive nonces and waited for before the next one.
+// The error is a *BatchError holding the wallets cached when it failed after
+// caching some.
+func (p *Provisioner) CacheWallets(ctx context.Context, n int) ([]common.Address, error) {
+	p.refill.Lock()
+	defer p.refill.Unlock()
+
+	var cached []common.Address
+	for len(cached) < n {
+		chunk := n - len(cached)
+		if p.BatchSize > 0 && chunk > p.BatchSize {
+			chunk = p.BatchSize
+		}
+		wallets, err := p.cacheBatch(ctx, chunk)
+		cached = append(cached, wallets...)
+		if err != nil {
+			if len(cached) == 0 {
+				return nil, err
+			}
+			return cached, &BatchError{Cached: cached, Missing: n - len(cached), Err: err}
+		}
+	}
+	if n > 0 {
+		logging.Or(p.Logger).Info("Cached wallets", "count", n)
+	}
+	return cached, nil
+}
+
+// cacheBatch sends the transactions caching n wallets together and returns
+// the wallets cached once they are mined, p.refill must be held.
+func (p *Provisioner) cacheBatch(ctx context.Context, n int) ([]common.Address, error) {
+	opts := *p.opts
+	opts.Context = ctx
+	var txs []*types.Transaction
+	var sendErr error
+	p.send.Lock()
+	for i := 0; i < n; i++ {
+		tx, err := p.cache.CacheWallet(&opts)
+		if err != nil {
+			// The wallets already sent are waited for, they are cached
+			// anyway.
+			sendErr = errors.Wrap(err, "sending cacheWallet")
+			break
+		}
+		txs = append(txs, tx)
+	}
+	p.send.Unlock()
+
+	var wallets []common.Address
+	for _, tx := range txs {
+		r, err := p.wait(ctx, tx)
+		if err != nil {
+			return wallets, err
+		}
+		events, err := p.cache.ParseCachedWalletFromReceipt(p.cacheAddr, r)
+		if err != nil {
+			return wallets, errors.Wrapf(err, "parsing CachedWallet event of transaction %s", tx.Hash().Hex())
+		}
+		for _, e := range events {
+			wallets = append(wallets, e.Wallet)
+		}
+	}
+	return wallets, sendErr
+}
+
+// DeployedWallet returns the wallet the WalletDeployer deployed for an
+// owner, the zero address when none was.
+func (p *Provisioner) DeployedWallet(ctx context.Context, owner common.Address) (common.Address, error) {
+	w, err := p.deployer.DeployedWallets(&bind.CallOpts{Context: ctx}, owner)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "calling deployedWallets")
+	}
+	return w, nil
+}
+
+// AssignWallet returns the wallet of an owner, assigning a cached one, or a
+// new one when the cache is empty, on the first call. The wallets assigned
+// by other means are found on the chain and recorded too.
+func (p *Provisioner) AssignWallet(ctx context.Context, owner common.Address) (Assignment, error) {
+	if owner == (common.Address{}) {
+		return Assignment{}, ErrZeroOwner
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a, ok, err := p.assignments.Get(owner)
+	if err != nil || ok {
+		return a, err
+	}
+
+	wallet, err := p.DeployedWallet(ctx, owner)
+	if err != nil {
+		return Assignment{}, err
+	}
+	if wallet != (common.Address{}) {
+		a = Assignment{Time: time.Now().UTC(), Owner: owner, Wallet: wallet}
+		return a, p.assignments.Record(a)
+	}
+
+	opts := *p.opts
+	opts.Context = ctx
+	p.send.Lock()
+	tx, err := p.deployer.DeployWallet(&opts, owner)
+	p.send.Unlock()
+	if err != nil {
+		return Assignment{}, errors.Wrap(err, "sending deployWallet")
+	}
+	receipt, err := p.wait(ctx, tx)
+	if err != nil {
+		return Assignment{}, err
+	}
+	a = Assignment{Time: time.Now().UTC(), Owner: owner, TxHash: tx.Hash(), BlockNumber: receipt.BlockNumber.Uint64()}
+	for _, l := range receipt.Logs {
+		if l.Address != p.address {
+			continue
+		}
+		e, err := p.deployer.ParseDeployedWallet(*l)
+		if err == nil && e.Owner == owner {
+			a.Wallet = e.Wallet
+		}
+	}
+	if a.Wallet == (common.Address{}) {
+		return Assignment{}, errors.Errorf("no DeployedWallet event in transaction %s", tx.Hash().Hex())
+	}
+	err = p.assignments.Record(a)
+	if err != nil {
+		return Assignment{}, err
+	}
+	logging.Or(p.Logger).Info("Assigned wallet", "owner", owner, "wallet", a.Wallet, "hash", tx.Hash())
+	return a, nil
+}
+
+// wait waits for a transaction to be mined and to succeed.
+func (p *Provisioner) wait(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	r, err := bind.WaitMined(ctx, p.receipts, tx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "waiting for transaction %s", tx.Hash().Hex())
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return r, nil
+}
+
+// Run refills the cache every interval until the context is cancelled.
+func (p *Provisioner) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		_, err := p.Refill(ctx)
+		if err != nil && ctx.Err() == nil {
+			logging.Or(p.Logger).Warn("Refilling the wallet cache failed", "err", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
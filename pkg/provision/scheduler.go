@@ -0,0 +1,164 @@
+package provision
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Source lists the owners waiting for a wallet, e.g. the users who completed
+// their onboarding. An owner may be listed again after being assigned a
+// wallet, the Scheduler skips it.
+type Source interface {
+	Owners(ctx context.Context) ([]common.Address, error)
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc func(ctx context.Context) ([]common.Address, error)
+
+// Owners implements Source.
+func (f SourceFunc) Owners(ctx context.Context) ([]common.Address, error) {
+	return f(ctx)
+}
+
+// Scheduler assigns their wallet to the owners of a source on a schedule,
+// instead of on request. Each tick lists the owners of the source, skips
+// those already assigned a wallet and those not Eligible, and assigns the
+// wallets of the first BatchSize of the others.
+//
+// An owner is never submitted twice: the owners assigned are recorded by
+// the Provisioner, which also finds those assigned on the chain by a
+// previous run, and an owner is not submitted again while its assignment
+// is in flight.
+type Scheduler struct {
+	provisioner *Provisioner
+	source      Source
+
+	// Eligible tells whether an owner can be assigned a wallet yet, all
+	// of them when nil. The owners not eligible are checked again on the
+	// next tick.
+	Eligible func(ctx context.Context, owner common.Address) (bool, error)
+	// BatchSize caps the number of wallets assigned by a tick, unlimited
+	// when not positive.
+	BatchSize int
+	Logger    logging.Logger
+
+	mu       sync.Mutex
+	inFlight map[common.Address]bool
+}
+
+// NewScheduler returns a scheduler assigning the wallets of the owners of
+// source with p.
+func NewScheduler(p *Provisioner, source Source) *Scheduler {
+	return &Scheduler{provisioner: p, source: source, inFlight: make(map[common.Address]bool)}
+}
+
+// Tick assigns the wallets of a batch of the owners waiting for one and
+// returns the assignments made. The owners whose assignment failed are
+// retried on the next tick, the error is the first failure.
+func (s *Scheduler) Tick(ctx context.Context) ([]Assignment, error) {
+	owners, err := s.source.Owners(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing owners")
+	}
+	batch, err := s.batch(ctx, owners)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release(batch)
+
+	var assigned []Assignment
+	var first error
+	for _, owner := range batch {
+		a, err := s.provisioner.AssignWallet(ctx, owner)
+		if err != nil {
+			logging.Or(s.Logger).Warn("Assigning a scheduled wallet failed", "owner", owner, "err", err)
+			if first == nil {
+				first = errors.Wrapf(err, "assigning the wallet of %s", owner.Hex())
+			}
+			continue
+		}
+		assigned = append(assigned, a)
+	}
+	return assigned, first
+}
+
+// batch claims the owners to assign by a tick.
+func (s *Scheduler) batch(ctx context.Context, owners []common.Address) ([]common.Address, error) {
+	var batch []common.Address
+	seen := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		if s.BatchSize > 0 && len(batch) >= s.BatchSize {
+			break
+		}
+		if owner == (common.Address{}) || seen[owner] {
+			continue
+		}
+		seen[owner] = true
+		_, assigned, err := s.provisioner.assignments.Get(owner)
+		if err != nil {
+			s.release(batch)
+			return nil, errors.Wrapf(err, "getting the assignment of %s", owner.Hex())
+		}
+		if assigned {
+			continue
+		}
+		if s.Eligible != nil {
+			ok, err := s.Eligible(ctx, owner)
+			if err != nil {
+				s.release(batch)
+				return nil, errors.Wrapf(err, "checking the eligibility of %s", owner.Hex())
+			}
+			if !ok {
+				continue
+			}
+		}
+		if s.claim(owner) {
+			batch = append(batch, owner)
+		}
+	}
+	return batch, nil
+}
+
+// claim marks an owner in flight, false when it already was.
+func (s *Scheduler) claim(owner common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight[owner] {
+		return false
+	}
+	s.inFlight[owner] = true
+	return true
+}
+
+func (s *Scheduler) release(owners []common.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, owner := range owners {
+		delete(s.inFlight, owner)
+	}
+}
+
+// Run ticks every interval until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		assigned, err := s.Tick(ctx)
+		if len(assigned) > 0 {
+			logging.Or(s.Logger).Info("Assigned scheduled wallets", "count", len(assigned))
+		}
+		if err != nil && ctx.Err() == nil {
+			logging.Or(s.Logger).Warn("Assigning the scheduled wallets failed", "err", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
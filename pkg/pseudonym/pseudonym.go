@@ -0,0 +1,179 @@
+// Package pseudonym replaces the addresses of the exported data with
+// pseudonyms, so that analytics vendors can work on the data without
+// learning the real addresses.
+//
+// The pseudonym of an address is derived from the address with a keyed HMAC:
+// the same key gives the same pseudonym in every file, so the exports can be
+// joined, and only the holders of the key can tell the address behind a
+// pseudonym, by deriving the pseudonyms of the addresses they know:
+//
+//	k, err := pseudonym.ParseKey(os.Getenv("EXPORT_PSEUDONYM_KEY"))
+//	...
+//	e = k.Event(e)
+//
+// The block numbers and the amounts are kept, a vendor matching them with the
+// public chain can still find the events. The pseudonyms protect the
+// addresses from being read, not from such an investigation.
+package pseudonym
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// MinKeySize is the minimum size of a key, in bytes.
+const MinKeySize = 32
+
+// Key derives the pseudonyms.
+type Key struct {
+	secret []byte
+}
+
+// NewKey returns the key of the given secret, at least MinKeySize bytes
+// long.
+func NewKey(secret []byte) (*Key, error) {
+	if len(secret) < MinKeySize {
+		return nil, errors.Errorf("the pseudonym key must be at least %d bytes long", MinKeySize)
+	}
+	return &Key{secret: append([]byte(nil), secret...)}, nil
+}
+
+// ParseKey returns the key of a hex encoded secret, with or without 0x.
+func ParseKey(s string) (*Key, error) {
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding pseudonym key")
+	}
+	return NewKey(secret)
+}
+
+// mac returns the HMAC of the data in a domain, so that an address and a
+// hash with the same bytes get different pseudonyms.
+func (k *Key) mac(domain string, data []byte) []byte {
+	m := hmac.New(sha256.New, k.secret)
+	m.Write([]byte(domain))
+	m.Write(data)
+	return m.Sum(nil)
+}
+
+// Address returns the pseudonym of an address, which is an address too so
+// that the exports keep their schema. The zero address is kept, it is
+// nobody's.
+func (k *Key) Address(a common.Address) common.Address {
+	if a == (common.Address{}) {
+		return a
+	}
+	return common.BytesToAddress(k.mac("address", a.Bytes())[:common.AddressLength])
+}
+
+// Hash returns the pseudonym of a transaction hash, which would give the
+// sender of the transaction away.
+func (k *Key) Hash(h common.Hash) common.Hash {
+	if h == (common.Hash{}) {
+		return h
+	}
+	return common.BytesToHash(k.mac("hash", h.Bytes()))
+}
+
+// Value returns the value of an event argument with its addresses replaced:
+// the addresses, decoded or formatted by indexer.FormatArg, and the arrays of
+// addresses. The other values are returned as they are.
+func (k *Key) Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case common.Address:
+		return k.Address(v)
+	case []common.Address:
+		p := make([]common.Address, len(v))
+		for i, a := range v {
+			p[i] = k.Address(a)
+		}
+		return p
+	case string:
+		if isAddress(v) {
+			return k.Address(common.HexToAddress(v)).Hex()
+		}
+	case []interface{}:
+		p := make([]interface{}, len(v))
+		for i, e := range v {
+			p[i] = k.Value(e)
+		}
+		return p
+	}
+	return v
+}
+
+// isAddress tells whether s is a hex encoded address.
+func isAddress(s string) bool {
+	if len(s) != 2+2*common.AddressLength || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
+// Event returns the event with the addresses of its arguments and its
+// transaction hash replaced by their pseudonyms. The address of the contract
+// emitting it is kept.
+func (k *Key) Event(e indexer.Event) indexer.Event {
+	args := make(map[string]interface{}, len(e.Args))
+	for name, v := range e.Args {
+		args[name] = k.Value(v)
+	}
+	e.Args = args
+	e.TxHash = k.Hash(e.TxHash)
+	return e
+}
+
+// Index maps the pseudonyms of known addresses back to the addresses.
+type Index struct {
+	key       *Key
+	addresses map[common.Address]common.Address
+}
+
+// NewIndex returns an empty index of the pseudonyms of the key.
+func (k *Key) NewIndex() *Index {
+	return &Index{key: k, addresses: make(map[common.Address]common.Address)}
+}
+
+// Add records the pseudonyms of the addresses.
+func (i *Index) Add(addresses ...common.Address) {
+	for _, a := range addresses {
+		i.addresses[i.key.Address(a)] = a
+	}
+}
+
+// AddValue records the pseudonyms of the addresses of a value, as replaced
+// by Key.Value.
+func (i *Index) AddValue(v interface{}) {
+	switch v := v.(type) {
+	case common.Address:
+		i.Add(v)
+	case []common.Address:
+		i.Add(v...)
+	case string:
+		if isAddress(v) {
+			i.Add(common.HexToAddress(v))
+		}
+	case []interface{}:
+		for _, e := range v {
+			i.AddValue(e)
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			i.AddValue(e)
+		}
+	}
+}
+
+// Reidentify returns the address of a pseudonym, false when it is not the
+// pseudonym of a recorded address.
+func (i *Index) Reidentify(pseudonym common.Address) (common.Address, bool) {
+	a, ok := i.addresses[pseudonym]
+	return a, ok
+}
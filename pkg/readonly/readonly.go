@@ -0,0 +1,212 @@
+// Package readonly degrades a service to read-only while a dependency of its
+// state changing requests is unavailable, instead of failing: the reads keep
+// being served, and the state changing requests are rejected with 503 Service
+// Unavailable and the reason, until the dependencies are available again.
+//
+// A Guard checks its dependencies every Interval. A dependency failing in
+// between, e.g. a signer failing to sign, reports it with Fail to enter the
+// read-only mode at once:
+//
+//	g := &readonly.Guard{Dependencies: []readonly.Dependency{{Name: "signer", Check: ping}}}
+//	go g.Run(ctx)
+//	mux.Handle("/relay", g.Handler(relayHandler))
+package readonly
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// ErrReadOnly is the cause of the errors of the operations refused in
+// read-only mode.
+var ErrReadOnly = errors.New("read-only mode")
+
+// DefaultInterval is the interval of the checks of a Guard without one.
+const DefaultInterval = 30 * time.Second
+
+// Dependency is a dependency of the state changing requests.
+type Dependency struct {
+	Name string
+	// Check returns why the dependency is unavailable, nil when it is
+	// available.
+	Check func(ctx context.Context) error
+}
+
+// Reason is why a dependency is unavailable.
+type Reason struct {
+	Dependency string    `json:"dependency"`
+	Error      string    `json:"error"`
+	Since      time.Time `json:"since"`
+}
+
+// Status is the mode of a Guard.
+type Status struct {
+	ReadOnly bool     `json:"read_only"`
+	Reasons  []Reason `json:"reasons"`
+}
+
+// Guard holds the service in read-only mode while any of its dependencies is
+// unavailable. The zero Guard is writable until a dependency fails.
+type Guard struct {
+	Dependencies []Dependency
+	// Interval is the interval of the checks of the dependencies,
+	// DefaultInterval when zero.
+	Interval time.Duration
+	Logger   logging.Logger
+
+	mu      sync.Mutex
+	reasons map[string]Reason
+}
+
+// Run checks the dependencies every Interval until the context is done.
+func (g *Guard) Run(ctx context.Context) {
+	interval := g.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		g.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks every dependency once and returns the resulting status.
+func (g *Guard) Check(ctx context.Context) Status {
+	for _, d := range g.Dependencies {
+		err := d.Check(ctx)
+		if err != nil {
+			g.Fail(d.Name, err)
+		} else {
+			g.Pass(d.Name)
+		}
+	}
+	return g.Status()
+}
+
+// Fail records that the dependency is unavailable, entering the read-only
+// mode.
+func (g *Guard) Fail(dependency string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.reasons == nil {
+		g.reasons = make(map[string]Reason)
+	}
+	r, failing := g.reasons[dependency]
+	if !failing {
+		r = Reason{Dependency: dependency, Since: time.Now().UTC()}
+		if len(g.reasons) == 0 {
+			logging.Or(g.Logger).Warn("Entering read-only mode", "dependency", dependency, "err", err)
+		} else {
+			logging.Or(g.Logger).Warn("Dependency unavailable", "dependency", dependency, "err", err)
+		}
+	}
+	r.Error = err.Error()
+	g.reasons[dependency] = r
+}
+
+// Pass records that the dependency is available, leaving the read-only mode
+// when no other dependency is unavailable.
+func (g *Guard) Pass(dependency string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r, failing := g.reasons[dependency]
+	if !failing {
+		return
+	}
+	delete(g.reasons, dependency)
+	if len(g.reasons) == 0 {
+		logging.Or(g.Logger).Info("Leaving read-only mode", "dependency", dependency, "after", time.Since(r.Since).Round(time.Second))
+	} else {
+		logging.Or(g.Logger).Info("Dependency available", "dependency", dependency)
+	}
+}
+
+// ReadOnly tells whether a dependency is unavailable.
+func (g *Guard) ReadOnly() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.reasons) > 0
+}
+
+// Err returns an error wrapping ErrReadOnly with the reasons of the read-only
+// mode, nil when the service is writable.
+func (g *Guard) Err() error {
+	s := g.Status()
+	if !s.ReadOnly {
+		return nil
+	}
+	msg := ""
+	for i, r := range s.Reasons {
+		if i > 0 {
+			msg += "; "
+		}
+		msg += r.Dependency + ": " + r.Error
+	}
+	return errors.Wrap(ErrReadOnly, msg)
+}
+
+// Status returns the mode of the guard, with the reasons in the order of the
+// names of their dependency.
+func (g *Guard) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := Status{ReadOnly: len(g.reasons) > 0, Reasons: make([]Reason, 0, len(g.reasons))}
+	for _, r := range g.reasons {
+		s.Reasons = append(s.Reasons, r)
+	}
+	sort.Slice(s.Reasons, func(a, b int) bool { return s.Reasons[a].Dependency < s.Reasons[b].Dependency })
+	return s
+}
+
+type errorResponse struct {
+	Error   string   `json:"error"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// Handler serves the requests with next, rejecting the methods other than
+// GET, HEAD and OPTIONS with 503 Service Unavailable, the reasons and a
+// Retry-After of the interval of the checks, rounded up to a second, in
+// read-only mode.
+func (g *Guard) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if err := g.Err(); err != nil {
+				interval := g.Interval
+				if interval <= 0 {
+					interval = DefaultInterval
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(interval.Seconds()))))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(errorResponse{Error: err.Error(), Reasons: g.Status().Reasons})
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// StatusHandler serves the Status of the guard as JSON.
+func (g *Guard) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(g.Status())
+	})
+}
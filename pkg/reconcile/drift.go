@@ -0,0 +1,156 @@
+package reconcile
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Drift is a difference between the contracts and the spec found by a Detector.
+// A Drift without actions means that a previously reported drift was resolved.
+type Drift struct {
+	Time    time.Time
+	Actions []Action
+	// Transactions are the transactions sent to remediate the drift, in the
+	// order of the actions, when the detector remediates them.
+	Transactions []*types.Transaction
+}
+
+// Resolved reports whether the contracts match the spec again.
+func (d Drift) Resolved() bool {
+	return len(d.Actions) == 0
+}
+
+// Alerter is notified of the drifts found by a Detector.
+type Alerter interface {
+	Alert(ctx context.Context, d Drift) error
+}
+
+// AlerterFunc adapts a function to the Alerter interface.
+type AlerterFunc func(ctx context.Context, d Drift) error
+
+// Alert implements Alerter.
+func (f AlerterFunc) Alert(ctx context.Context, d Drift) error {
+	return f(ctx, d)
+}
+
+// LogAlerter writes drifts to a logger, as warnings.
+type LogAlerter struct {
+	Logger logging.Logger
+}
+
+// Alert implements Alerter.
+func (l LogAlerter) Alert(ctx context.Context, d Drift) error {
+	if d.Resolved() {
+		l.Logger.Info("Contracts match the spec again")
+		return nil
+	}
+	l.Logger.Warn("Contracts drifted from the spec", "actions", len(d.Actions))
+	for i, a := range d.Actions {
+		if i < len(d.Transactions) {
+			l.Logger.Warn("Remediating drift", "action", a.String(), "tx", d.Transactions[i].Hash().Hex())
+			continue
+		}
+		l.Logger.Warn("Action needed", "action", a.String())
+	}
+	return nil
+}
+
+// Detector periodically compares the contracts with a spec and alerts when
+// they drift apart. Alerts are only raised when the drift changes, not on
+// every check.
+type Detector struct {
+	Reconciler *Reconciler
+	Spec       *Spec
+	Interval   time.Duration
+	Alerter    Alerter
+	// Remediate, when set, signs the transactions applying the actions of
+	// each new drift, bringing the contracts back to the spec. The drift is
+	// only alerted otherwise.
+	Remediate *bind.TransactOpts
+	// Logger receives the errors of failed checks, they are discarded when
+	// nil.
+	Logger logging.Logger
+
+	mu   sync.Mutex
+	last []string
+	// remediated is the drift remediated, not remediated again while its
+	// alert is retried.
+	remediated []string
+}
+
+// SetSpec replaces the spec the contracts are compared with while the detector
+// is running. The next check alerts if the drift changed.
+func (d *Detector) SetSpec(spec *Spec) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Spec = spec
+}
+
+// Run checks for drift every Interval until the context is cancelled.
+func (d *Detector) Run(ctx context.Context) error {
+	t := time.NewTicker(d.Interval)
+	defer t.Stop()
+	for {
+		err := d.Check(ctx)
+		if err != nil {
+			logging.Or(d.Logger).Error("Drift check failed", "err", err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check compares the contracts with the spec once, alerting if the drift changed
+// since the previous check. A new drift is remediated first when Remediate is
+// set; a drift whose remediation failed is alerted and remediated again on the
+// next check.
+func (d *Detector) Check(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	actions, err := d.Reconciler.Plan(&bind.CallOpts{Context: ctx}, d.Spec)
+	if err != nil {
+		return err
+	}
+
+	current := make([]string, len(actions))
+	for i, a := range actions {
+		current[i] = a.String()
+	}
+	if reflect.DeepEqual(current, d.last) || (len(current) == 0 && len(d.last) == 0) {
+		return nil
+	}
+
+	drift := Drift{Time: time.Now(), Actions: actions}
+	var remediateErr error
+	if d.Remediate != nil && len(actions) > 0 && !reflect.DeepEqual(current, d.remediated) {
+		opts := *d.Remediate
+		opts.Context = ctx
+		drift.Transactions, remediateErr = d.Reconciler.Apply(&opts, actions)
+		if remediateErr == nil {
+			d.remediated = current
+		}
+	}
+	err = d.Alerter.Alert(ctx, drift)
+	if err != nil {
+		return err
+	}
+	if remediateErr != nil {
+		return errors.Wrap(remediateErr, "remediating drift")
+	}
+	d.last = current
+	if !reflect.DeepEqual(current, d.remediated) {
+		d.remediated = nil
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package reconcile
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// Contracts holds the addresses of the reconciled contracts. Contracts left as
+// the zero address cannot be part of a spec.
+type Contracts struct {
+	Licence        common.Address
+	Controller     common.Address
+	TokenWhitelist common.Address
+}
+
+// Action is a single transaction needed to converge to the desired state.
+type Action struct {
+	Contract    string
+	Method      string
+	Description string
+	// Role is the role the sender of the transaction must hold.
+	Role string
+
+	send func(opts *bind.TransactOpts) (*types.Transaction, error)
+}
+
+func (a Action) String() string {
+	return fmt.Sprintf("%s.%s: %s (as %s)", a.Contract, a.Method, a.Description, a.Role)
+}
+
+// Reconciler plans and applies the actions converging the contracts to a Spec.
+type Reconciler struct {
+	contracts      Contracts
+	licence        *bindings.Licence
+	controller     *bindings.Controller
+	tokenWhitelist *bindings.TokenWhitelist
+}
+
+// New creates a new reconciler using backend to interact with the contracts.
+func New(backend bind.ContractBackend, contracts Contracts) (*Reconciler, error) {
+	r := &Reconciler{contracts: contracts}
+	var err error
+	if contracts.Licence != (common.Address{}) {
+		r.licence, err = bindings.NewLicence(contracts.Licence, backend)
+		if err != nil {
+			return nil, errors.Wrap(err, "binding licence contract")
+		}
+	}
+	if contracts.Controller != (common.Address{}) {
+		r.controller, err = bindings.NewController(contracts.Controller, backend)
+		if err != nil {
+			return nil, errors.Wrap(err, "binding controller contract")
+		}
+	}
+	if contracts.TokenWhitelist != (common.Address{}) {
+		r.tokenWhitelist, err = bindings.NewTokenWhitelist(contracts.TokenWhitelist, backend)
+		if err != nil {
+			return nil, errors.Wrap(err, "binding token whitelist contract")
+		}
+	}
+	return r, nil
+}
+
+// Plan compares the spec with the state of the contracts and returns the
+// actions needed to converge, in the order they must be applied.
+func (r *Reconciler) Plan(opts *bind.CallOpts, spec *Spec) ([]Action, error) {
+	var actions []Action
+
+	if spec.Licence != nil {
+		if r.licence == nil {
+			return nil, errors.New("licence contract address is not configured")
+		}
+		a, err := r.planLicence(opts, spec.Licence)
+		if err != nil {
+			return nil, errors.Wrap(err, "planning licence")
+		}
+		actions = append(actions, a...)
+	}
+
+	if spec.Controller != nil {
+		if r.controller == nil {
+			return nil, errors.New("controller contract address is not configured")
+		}
+		a, err := r.planController(opts, spec.Controller)
+		if err != nil {
+			return nil, errors.Wrap(err, "planning controller")
+		}
+		actions = append(actions, a...)
+	}
+
+	if spec.TokenWhitelist != nil {
+		if r.tokenWhitelist == nil {
+			return nil, errors.New("token whitelist contract address is not configured")
+		}
+		a, err := r.planTokenWhitelist(opts, spec.TokenWhitelist)
+		if err != nil {
+			return nil, errors.Wrap(err, "planning token whitelist")
+		}
+		actions = append(actions, a...)
+	}
+
+	return actions, nil
+}
+
+// Apply sends the transactions of the actions in order. It stops at the first
+// action failing to be sent and returns the transactions sent so far.
+func (r *Reconciler) Apply(opts *bind.TransactOpts, actions []Action) ([]*types.Transaction, error) {
+	var txs []*types.Transaction
+	for _, a := range actions {
+		o := *opts
+		tx, err := a.send(&o)
+		if err != nil {
+			return txs, errors.Wrapf(err, "applying %s", a)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func (r *Reconciler) planLicence(opts *bind.CallOpts, spec *LicenceSpec) ([]Action, error) {
+	var actions []Action
+	l := r.licence
+
+	// The amount is updated first as it must be sent by the current DAO.
+	if spec.AmountScaled != nil {
+		current, err := l.LicenceAmountScaled(opts)
+		if err != nil {
+			return nil, err
+		}
+		want := new(big.Int).SetUint64(*spec.AmountScaled)
+		if current.Cmp(want) != 0 {
+			actions = append(actions, Action{
+				Contract:    "licence",
+				Method:      "updateLicenceAmount",
+				Description: fmt.Sprintf("change licence amount from %s to %s", current, want),
+				Role:        "licence DAO",
+				send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+					return l.UpdateLicenceAmount(o, want)
+				},
+			})
+		}
+	}
+
+	addresses := []struct {
+		name    string
+		method  string
+		want    *Address
+		current func(*bind.CallOpts) (common.Address, error)
+		locked  func(*bind.CallOpts) (bool, error)
+		update  func(*bind.TransactOpts, common.Address) (*types.Transaction, error)
+	}{
+		{"licence DAO", "updateLicenceDAO", spec.DAO, l.LicenceDAO, l.LicenceDAOLocked, l.UpdateLicenceDAO},
+		{"crypto float", "updateFloat", spec.CryptoFloat, l.CryptoFloat, l.FloatLocked, l.UpdateFloat},
+		{"token holder", "updateHolder", spec.TokenHolder, l.TokenHolder, l.HolderLocked, l.UpdateHolder},
+		{"TKN contract", "updateTKNContractAddress", spec.TKNContract, l.TknContractAddress, l.TknContractAddressLocked, l.UpdateTKNContractAddress},
+	}
+
+	for _, a := range addresses {
+		if a.want == nil {
+			continue
+		}
+		want := common.Address(*a.want)
+		current, err := a.current(opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting %s", a.name)
+		}
+		if current == want {
+			continue
+		}
+		locked, err := a.locked(opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting %s lock", a.name)
+		}
+		if locked {
+			return nil, errors.Errorf("%s is locked to %s, cannot change it to %s", a.name, current.Hex(), want.Hex())
+		}
+		update := a.update
+		actions = append(actions, Action{
+			Contract:    "licence",
+			Method:      a.method,
+			Description: fmt.Sprintf("change %s from %s to %s", a.name, current.Hex(), want.Hex()),
+			Role:        "controller admin",
+			send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+				return update(o, want)
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+func (r *Reconciler) planController(opts *bind.CallOpts, spec *ControllerSpec) ([]Action, error) {
+	var actions []Action
+	c := r.controller
+
+	roles := []struct {
+		name   string
+		method string
+		role   string
+		want   bool
+		list   []Address
+		has    func(*bind.CallOpts, common.Address) (bool, error)
+		change func(*bind.TransactOpts, common.Address) (*types.Transaction, error)
+	}{
+		{"admin", "addAdmin", "controller owner", true, spec.Admins, c.IsAdmin, c.AddAdmin},
+		{"admin", "removeAdmin", "controller owner", false, spec.RemoveAdmins, c.IsAdmin, c.RemoveAdmin},
+		{"controller", "addController", "controller admin", true, spec.Controllers, c.IsController, c.AddController},
+		{"controller", "removeController", "controller admin", false, spec.RemoveControllers, c.IsController, c.RemoveController},
+	}
+
+	for _, r := range roles {
+		for _, a := range r.list {
+			account := common.Address(a)
+			has, err := r.has(opts, account)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting %s role of %s", r.name, account.Hex())
+			}
+			if has == r.want {
+				continue
+			}
+			verb := "grant"
+			if !r.want {
+				verb = "revoke"
+			}
+			change := r.change
+			actions = append(actions, Action{
+				Contract:    "controller",
+				Method:      r.method,
+				Description: fmt.Sprintf("%s %s role to %s", verb, r.name, account.Hex()),
+				Role:        r.role,
+				send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+					return change(o, account)
+				},
+			})
+		}
+	}
+
+	// Ownership is transferred last so that the current owner can still manage the admins.
+	if spec.Owner != nil {
+		want := common.Address(*spec.Owner)
+		current, err := c.Owner(opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting owner")
+		}
+		if current != want {
+			transferable, err := c.IsTransferable(opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting ownership lock")
+			}
+			if !transferable {
+				return nil, errors.Errorf("ownership is locked to %s, cannot transfer it to %s", current.Hex(), want.Hex())
+			}
+			actions = append(actions, Action{
+				Contract:    "controller",
+				Method:      "transferOwnership",
+				Description: fmt.Sprintf("transfer ownership from %s to %s", current.Hex(), want.Hex()),
+				Role:        "controller owner",
+				send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+					return c.TransferOwnership(o, want, true)
+				},
+			})
+		}
+	}
+
+	return actions, nil
+}
+
+func (r *Reconciler) planTokenWhitelist(opts *bind.CallOpts, spec *TokenWhitelistSpec) ([]Action, error) {
+	var actions []Action
+	w := r.tokenWhitelist
+
+	var remove []common.Address
+	var add []TokenSpec
+	wanted := make(map[common.Address]bool)
+
+	for _, t := range spec.Tokens {
+		address := common.Address(t.Address)
+		if wanted[address] {
+			return nil, errors.Errorf("token %s is listed more than once", address.Hex())
+		}
+		wanted[address] = true
+
+		symbol, magnitude, _, available, loadable, redeemable, _, err := w.GetTokenInfo(opts, address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting token %s", address.Hex())
+		}
+		if !available {
+			add = append(add, t)
+			continue
+		}
+		if symbol != t.Symbol || magnitude.Cmp(new(big.Int).SetUint64(t.Magnitude)) != 0 {
+			// The symbol and magnitude can only be changed by adding the token again.
+			remove = append(remove, address)
+			add = append(add, t)
+			continue
+		}
+		if loadable != t.Loadable {
+			want := t.Loadable
+			actions = append(actions, Action{
+				Contract:    "token_whitelist",
+				Method:      "setTokenLoadable",
+				Description: fmt.Sprintf("set %s loadable to %t", t.Symbol, want),
+				Role:        "controller admin",
+				send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+					return w.SetTokenLoadable(o, address, want)
+				},
+			})
+		}
+		if redeemable != t.Redeemable {
+			want := t.Redeemable
+			actions = append(actions, Action{
+				Contract:    "token_whitelist",
+				Method:      "setTokenRedeemable",
+				Description: fmt.Sprintf("set %s redeemable to %t", t.Symbol, want),
+				Role:        "controller admin",
+				send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+					return w.SetTokenRedeemable(o, address, want)
+				},
+			})
+		}
+	}
+
+	if spec.Prune {
+		current, err := w.TokenAddressArray(opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting whitelisted tokens")
+		}
+		for _, a := range current {
+			if !wanted[a] {
+				remove = append(remove, a)
+			}
+		}
+	}
+
+	if len(remove) > 0 {
+		actions = append(actions, Action{
+			Contract:    "token_whitelist",
+			Method:      "removeTokens",
+			Description: fmt.Sprintf("remove %d token(s) %v", len(remove), hexes(remove)),
+			Role:        "controller admin",
+			send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+				return w.RemoveTokens(o, remove)
+			},
+		})
+	}
+
+	if len(add) > 0 {
+		tokens := make([]common.Address, len(add))
+		symbols := make([][32]byte, len(add))
+		magnitudes := make([]*big.Int, len(add))
+		loadable := make([]bool, len(add))
+		redeemable := make([]bool, len(add))
+		names := make([]string, len(add))
+		for i, t := range add {
+			tokens[i] = common.Address(t.Address)
+			copy(symbols[i][:], t.Symbol)
+			magnitudes[i] = new(big.Int).SetUint64(t.Magnitude)
+			loadable[i] = t.Loadable
+			redeemable[i] = t.Redeemable
+			names[i] = t.Symbol
+		}
+		actions = append(actions, Action{
+			Contract:    "token_whitelist",
+			Method:      "addTokens",
+			Description: fmt.Sprintf("add %d token(s) %v", len(add), names),
+			Role:        "controller admin",
+			send: func(o *bind.TransactOpts) (*types.Transaction, error) {
+				return w.AddTokens(o, tokens, symbols, magnitudes, loadable, redeemable, big.NewInt(time.Now().Unix()))
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+func hexes(addresses []common.Address) []string {
+	r := make([]string, len(addresses))
+	for i, a := range addresses {
+		r[i] = a.Hex()
+	}
+	return r
+}
@@ -0,0 +1,117 @@
+// Package reconcile brings the configuration of the deployed contracts in line
+// with a declarative specification.
+//
+// The desired state is described in YAML, the reconciler reads the current state
+// from the chain and plans only the transactions needed to converge. Applying a
+// spec that already matches the chain is a no-op.
+package reconcile
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec is the desired state of the contracts. Nil sections and fields are left untouched:
+//
+//	licence:
+//	  amount_scaled: 10
+//	  dao: "0x..."
+//	  crypto_float: "0x..."
+//	  token_holder: "0x..."
+//	  tkn_contract: "0x..."
+//	controller:
+//	  owner: "0x..."
+//	  admins: ["0x..."]
+//	  remove_admins: ["0x..."]
+//	  controllers: ["0x..."]
+//	  remove_controllers: ["0x..."]
+//	token_whitelist:
+//	  prune: true
+//	  tokens:
+//	  - address: "0x..."
+//	    symbol: TKN
+//	    magnitude: 100000000
+//	    loadable: true
+//	    redeemable: true
+type Spec struct {
+	Licence        *LicenceSpec        `yaml:"licence"`
+	Controller     *ControllerSpec     `yaml:"controller"`
+	TokenWhitelist *TokenWhitelistSpec `yaml:"token_whitelist"`
+}
+
+// LicenceSpec is the desired state of the licence contract.
+type LicenceSpec struct {
+	AmountScaled *uint64  `yaml:"amount_scaled"`
+	DAO          *Address `yaml:"dao"`
+	CryptoFloat  *Address `yaml:"crypto_float"`
+	TokenHolder  *Address `yaml:"token_holder"`
+	TKNContract  *Address `yaml:"tkn_contract"`
+}
+
+// ControllerSpec is the desired state of the controller contract.
+//
+// The controller does not enumerate its roles, accounts which must not hold a
+// role have to be listed explicitly.
+type ControllerSpec struct {
+	Owner             *Address  `yaml:"owner"`
+	Admins            []Address `yaml:"admins"`
+	RemoveAdmins      []Address `yaml:"remove_admins"`
+	Controllers       []Address `yaml:"controllers"`
+	RemoveControllers []Address `yaml:"remove_controllers"`
+}
+
+// TokenWhitelistSpec is the desired state of the token whitelist contract.
+type TokenWhitelistSpec struct {
+	// Prune removes the whitelisted tokens missing from Tokens.
+	Prune  bool        `yaml:"prune"`
+	Tokens []TokenSpec `yaml:"tokens"`
+}
+
+// TokenSpec is the desired whitelist entry of a token.
+type TokenSpec struct {
+	Address    Address `yaml:"address"`
+	Symbol     string  `yaml:"symbol"`
+	Magnitude  uint64  `yaml:"magnitude"`
+	Loadable   bool    `yaml:"loadable"`
+	Redeemable bool    `yaml:"redeemable"`
+}
+
+// Address is a common.Address decoded from a hex string in YAML.
+type Address common.Address
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (a *Address) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+	if !common.IsHexAddress(s) {
+		return errors.Errorf("%q is not a valid address", s)
+	}
+	*a = Address(common.HexToAddress(s))
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (a Address) MarshalYAML() (interface{}, error) {
+	return common.Address(a).Hex(), nil
+}
+
+// LoadSpec reads a spec from YAML.
+func LoadSpec(r io.Reader) (*Spec, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading spec")
+	}
+	s := &Spec{}
+	err = yaml.UnmarshalStrict(b, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding spec")
+	}
+	return s, nil
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// Default is the registry the New*ForChain constructors look the addresses
+// up in, applications load their networks into it on start up:
+//
+//	reg, err := registry.LoadFile("networks.yaml")
+//	...
+//	registry.Default.Override(reg)
+var Default = New()
+
+// Names returns the names of the contracts a registry holds, sorted.
+func Names() []string {
+	names := []string{TKN}
+	for n := range bindings.ContractABIs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func known(name string) bool {
+	_, ok := bindings.ContractABIs[name]
+	return ok || name == TKN
+}
+
+// NewControllerForChain binds the controller deployed on a network.
+func NewControllerForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.Controller, error) {
+	a, err := Default.Address(chainID, "controller")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewController(a, backend)
+}
+
+// NewHolderForChain binds the holder deployed on a network.
+func NewHolderForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.Holder, error) {
+	a, err := Default.Address(chainID, "holder")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewHolder(a, backend)
+}
+
+// NewLicenceForChain binds the licence deployed on a network.
+func NewLicenceForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.Licence, error) {
+	a, err := Default.Address(chainID, "licence")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewLicence(a, backend)
+}
+
+// NewOracleForChain binds the oracle deployed on a network.
+func NewOracleForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.Oracle, error) {
+	a, err := Default.Address(chainID, "oracle")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewOracle(a, backend)
+}
+
+// NewTokenWhitelistForChain binds the token whitelist deployed on a network.
+func NewTokenWhitelistForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.TokenWhitelist, error) {
+	a, err := Default.Address(chainID, "token_whitelist")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewTokenWhitelist(a, backend)
+}
+
+// NewWalletCacheForChain binds the wallet cache deployed on a network.
+func NewWalletCacheForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.WalletCache, error) {
+	a, err := Default.Address(chainID, "wallet_cache")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewWalletCache(a, backend)
+}
+
+// NewWalletDeployerForChain binds the wallet deployer deployed on a network.
+func NewWalletDeployerForChain(chainID *big.Int, backend bind.ContractBackend) (*bindings.WalletDeployer, error) {
+	a, err := Default.Address(chainID, "wallet_deployer")
+	if err != nil {
+		return nil, err
+	}
+	return bindings.NewWalletDeployer(a, backend)
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// ErrWrongNetwork is the cause of the errors of the guardrails when the node
+// or a transaction is not on the expected network.
+var ErrWrongNetwork = errors.New("wrong network")
+
+// ChainReader reads the chain ID of a node, *ethclient.Client implements it.
+type ChainReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// CheckChain checks that the node is on the network of chainID, failing with
+// ErrWrongNetwork when it is not.
+func CheckChain(ctx context.Context, node ChainReader, chainID *big.Int) error {
+	id, err := node.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting chain ID")
+	}
+	if id.Cmp(chainID) != 0 {
+		return errors.Wrapf(ErrWrongNetwork, "the node is on chain %s, expected chain %s", id, chainID)
+	}
+	return nil
+}
+
+// CheckNetwork checks that the registry has the addresses of the network of
+// chainID, failing with ErrWrongNetwork when it has none: the contracts of
+// another network would otherwise be looked up as not deployed.
+func (r *Registry) CheckNetwork(chainID *big.Int) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.networks[chainID.Uint64()]) == 0 {
+		return errors.Wrapf(ErrWrongNetwork, "chain %s is not in the registry", chainID)
+	}
+	return nil
+}
+
+// Guard sends the transactions of a backend only to the network of its chain
+// ID. Before each transaction it checks that the transaction is signed for
+// that network and that the node is still on it, so that a misconfigured or
+// failed over endpoint never receives a transaction meant for another one.
+type Guard struct {
+	bind.ContractBackend
+	node    ChainReader
+	chainID *big.Int
+
+	// AllowUnprotected lets the transactions signed without a chain ID
+	// through. They are valid on every network and refused by default.
+	AllowUnprotected bool
+}
+
+// NewGuard returns a guard sending the transactions of backend to the network
+// of chainID, reading the chain ID of the node from node.
+func NewGuard(backend bind.ContractBackend, node ChainReader, chainID *big.Int) *Guard {
+	return &Guard{ContractBackend: backend, node: node, chainID: new(big.Int).Set(chainID)}
+}
+
+// ChainID returns the chain ID of the network the guard sends to.
+func (g *Guard) ChainID() *big.Int {
+	return new(big.Int).Set(g.chainID)
+}
+
+// SendTransaction sends the transaction after checking its network and the
+// network of the node.
+func (g *Guard) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !tx.Protected() {
+		if !g.AllowUnprotected {
+			return errors.Wrapf(ErrWrongNetwork, "transaction %s is not signed for a network, expected chain %s", tx.Hash().Hex(), g.chainID)
+		}
+	} else if tx.ChainId().Cmp(g.chainID) != 0 {
+		return errors.Wrapf(ErrWrongNetwork, "transaction %s is signed for chain %s, expected chain %s", tx.Hash().Hex(), tx.ChainId(), g.chainID)
+	}
+	err := CheckChain(ctx, g.node, g.chainID)
+	if err != nil {
+		return errors.Wrapf(err, "refusing to send transaction %s", tx.Hash().Hex())
+	}
+	return g.ContractBackend.SendTransaction(ctx, tx)
+}
@@ -0,0 +1,176 @@
+// Package registry holds the addresses of the contracts deployed on each
+// network, so that applications look them up by chain ID instead of
+// hardcoding them:
+//
+//	reg, err := registry.LoadFile("networks.yaml")
+//	...
+//	registry.Default.Override(reg)
+//	controller, err := registry.NewControllerForChain(chainID, backend)
+//
+// The contracts are named after bindings.ContractABIs, the TKN token is
+// named tkn.
+//
+// The guardrails keep the transactions on the expected network: CheckChain
+// checks the network of a node, and a Guard checks it again before sending
+// each transaction:
+//
+//	backend := registry.NewGuard(client, client, big.NewInt(1))
+package registry
+
+import (
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// TKN is the name of the TKN token.
+const TKN = "tkn"
+
+// ErrNotDeployed is returned when the registry has no address for a contract
+// on a network.
+var ErrNotDeployed = errors.New("contract not deployed")
+
+// Network maps the names of the contracts to their address on a network.
+type Network map[string]common.Address
+
+// Registry holds the Network of each chain ID.
+type Registry struct {
+	mu       sync.RWMutex
+	networks map[uint64]Network
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{networks: make(map[uint64]Network)}
+}
+
+// Load reads a registry from YAML or JSON, mapping the chain IDs to the
+// addresses of the contracts:
+//
+//	1:
+//	  controller: "0x..."
+//	  tkn: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+//	3:
+//	  controller: "0x..."
+func Load(r io.Reader) (*Registry, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading registry")
+	}
+	var f map[string]map[string]string
+	err = yaml.UnmarshalStrict(b, &f)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding registry")
+	}
+
+	reg := New()
+	for id, contracts := range f {
+		chainID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a valid chain ID", id)
+		}
+		for name, a := range contracts {
+			if !common.IsHexAddress(a) {
+				return nil, errors.Errorf("address %q of %s on chain %d is not valid", a, name, chainID)
+			}
+			err = reg.Set(new(big.Int).SetUint64(chainID), name, common.HexToAddress(a))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return reg, nil
+}
+
+// LoadFile reads a registry from a YAML or JSON file.
+func LoadFile(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening registry file")
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Set sets the address of the named contract on a network.
+func (r *Registry) Set(chainID *big.Int, name string, address common.Address) error {
+	if !known(name) {
+		return errors.Errorf("unknown contract %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.networks[chainID.Uint64()]
+	if !ok {
+		n = make(Network)
+		r.networks[chainID.Uint64()] = n
+	}
+	n[name] = address
+	return nil
+}
+
+// Override sets the addresses of other, replacing those of the registry, to
+// layer e.g. the addresses of a local deployment over the public ones.
+func (r *Registry) Override(other *Registry) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, contracts := range other.networks {
+		n, ok := r.networks[id]
+		if !ok {
+			n = make(Network)
+			r.networks[id] = n
+		}
+		for name, a := range contracts {
+			n[name] = a
+		}
+	}
+}
+
+// Address returns the address of the named contract on a network, failing
+// with ErrNotDeployed when it has none.
+func (r *Registry) Address(chainID *big.Int, name string) (common.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.networks[chainID.Uint64()][name]
+	if !ok {
+		return common.Address{}, errors.Wrapf(ErrNotDeployed, "%s on chain %s", name, chainID)
+	}
+	return a, nil
+}
+
+// Network returns a copy of the addresses of the contracts on a network.
+func (r *Registry) Network(chainID *big.Int) Network {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := make(Network)
+	for name, a := range r.networks[chainID.Uint64()] {
+		n[name] = a
+	}
+	return n
+}
+
+// ChainIDs returns the chain IDs of the networks in the registry, sorted.
+func (r *Registry) ChainIDs() []*big.Int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]uint64, 0, len(r.networks))
+	for id := range r.networks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	chainIDs := make([]*big.Int, len(ids))
+	for i, id := range ids {
+		chainIDs[i] = new(big.Int).SetUint64(id)
+	}
+	return chainIDs
+}
@@ -0,0 +1,128 @@
+package relayer
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// SubmitRequest is the body of POST /relay.
+type SubmitRequest struct {
+	Wallet common.Address `json:"wallet"`
+	// Nonce is the relay nonce of the wallet as a decimal string.
+	Nonce     string        `json:"nonce"`
+	Data      hexutil.Bytes `json:"data"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// SubmitResponse is the body returned by POST /relay.
+type SubmitResponse struct {
+	TxHash common.Hash `json:"tx_hash"`
+}
+
+// StatusResponse is the body of GET /relay/{user}. Amounts are in wei, as
+// decimal strings.
+type StatusResponse struct {
+	User      common.Address `json:"user"`
+	Spent     string         `json:"spent"`
+	Reserved  string         `json:"reserved"`
+	Remaining string         `json:"remaining,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler serves the relayer:
+//
+//	POST /relay           submit a meta-transaction
+//	GET  /relay/{user}    fees relayed for a user and remaining allowance
+//
+// The meta-transactions are authenticated by the signature of the owner of
+// the wallet.
+func NewHandler(r *Relayer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/relay" && req.Method == http.MethodPost:
+			handleSubmit(r, w, req)
+		case strings.HasPrefix(req.URL.Path, "/relay/") && req.Method == http.MethodGet:
+			handleStatus(r, w, req)
+		default:
+			writeError(w, http.StatusNotFound, errors.Errorf("%s %s not found", req.Method, req.URL.Path))
+		}
+	})
+}
+
+func handleSubmit(r *Relayer, w http.ResponseWriter, req *http.Request) {
+	var body SubmitRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "decoding request"))
+		return
+	}
+	nonce, ok := new(big.Int).SetString(body.Nonce, 10)
+	if !ok {
+		writeError(w, http.StatusBadRequest, errors.Errorf("%q is not a valid nonce", body.Nonce))
+		return
+	}
+
+	tx, err := r.Submit(req.Context(), MetaTx{
+		Wallet:    body.Wallet,
+		Nonce:     nonce,
+		Data:      body.Data,
+		Signature: body.Signature,
+	})
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, SubmitResponse{TxHash: tx.Hash()})
+}
+
+func statusOf(err error) int {
+	switch errors.Cause(err) {
+	case signing.ErrInvalidSignature, ErrNotOwner:
+		return http.StatusUnauthorized
+	case ErrNotEligible:
+		return http.StatusForbidden
+	case ErrNonce:
+		return http.StatusConflict
+	case ErrAllowanceExceeded:
+		return http.StatusPaymentRequired
+	}
+	return http.StatusBadGateway
+}
+
+func handleStatus(r *Relayer, w http.ResponseWriter, req *http.Request) {
+	user := strings.TrimPrefix(req.URL.Path, "/relay/")
+	if !common.IsHexAddress(user) {
+		writeError(w, http.StatusBadRequest, errors.Errorf("%q is not a valid address", user))
+		return
+	}
+	s, err := r.Status(common.HexToAddress(user))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res := StatusResponse{User: s.User, Spent: s.Spent.String(), Reserved: s.Reserved.String()}
+	if s.Remaining != nil {
+		res.Remaining = s.Remaining.String()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
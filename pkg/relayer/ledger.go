@@ -0,0 +1,203 @@
+package relayer
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Charge is the fee paid by the relayer for a meta-transaction of a user.
+type Charge struct {
+	Time   time.Time      `json:"time"`
+	User   common.Address `json:"user"`
+	Wallet common.Address `json:"wallet"`
+	TxHash common.Hash    `json:"tx_hash"`
+	// Fee is the gas used times the gas price, in wei.
+	Fee *big.Int `json:"fee"`
+	// Failed is set when the relayed call reverted, its gas is charged as well.
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Ledger accounts the fees paid for each user.
+type Ledger interface {
+	Charge(c Charge) error
+	// Spent returns the total of the fees charged to user.
+	Spent(user common.Address) (*big.Int, error)
+}
+
+// Relayed is a relayed transaction not settled yet.
+type Relayed struct {
+	TxHash common.Hash    `json:"tx_hash"`
+	User   common.Address `json:"user"`
+	Wallet common.Address `json:"wallet"`
+	// Nonce is the relay nonce of the meta-transaction.
+	Nonce    *big.Int `json:"nonce"`
+	Gas      uint64   `json:"gas"`
+	GasPrice *big.Int `json:"gas_price"`
+}
+
+// MaxFee returns the fee of the transaction if it uses all of its gas.
+func (p Relayed) MaxFee() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(p.Gas), p.GasPrice)
+}
+
+// Journal is implemented by the ledgers which persist the relayed
+// transactions until they are charged, so that a restarted relayer settles
+// them and reserves their fees.
+type Journal interface {
+	// Relay records a relayed transaction.
+	Relay(p Relayed) error
+	// Unsettled returns the relayed transactions not charged yet.
+	Unsettled() []Relayed
+}
+
+// MemoryLedger is a Ledger lost on restart.
+type MemoryLedger struct {
+	mu    sync.Mutex
+	spent map[common.Address]*big.Int
+}
+
+// NewMemoryLedger creates an empty ledger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{spent: make(map[common.Address]*big.Int)}
+}
+
+// Charge implements Ledger.
+func (l *MemoryLedger) Charge(c Charge) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.add(c)
+	return nil
+}
+
+func (l *MemoryLedger) add(c Charge) {
+	spent, ok := l.spent[c.User]
+	if !ok {
+		spent = new(big.Int)
+		l.spent[c.User] = spent
+	}
+	spent.Add(spent, c.Fee)
+}
+
+// Spent implements Ledger.
+func (l *MemoryLedger) Spent(user common.Address) (*big.Int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if spent, ok := l.spent[user]; ok {
+		return new(big.Int).Set(spent), nil
+	}
+	return new(big.Int), nil
+}
+
+// ledgerLine is a line of the file of a FileLedger: a charge, or a relayed
+// transaction when Relayed is set.
+type ledgerLine struct {
+	Charge
+	Relayed *Relayed `json:"relayed,omitempty"`
+}
+
+// FileLedger appends the charges and the relayed transactions to a file as
+// JSON lines and keeps the totals and the transactions not charged yet in
+// memory, read back from the file when it is opened. It implements Journal.
+type FileLedger struct {
+	memory *MemoryLedger
+	path   string
+	// unsettled are the relayed transactions not charged yet, guarded by
+	// memory.mu.
+	unsettled map[common.Hash]Relayed
+}
+
+// OpenFileLedger opens the ledger stored in path, which is created on the first charge.
+func OpenFileLedger(path string) (*FileLedger, error) {
+	l := &FileLedger{memory: NewMemoryLedger(), path: path, unsettled: make(map[common.Hash]Relayed)}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening ledger")
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		var r ledgerLine
+		err := json.Unmarshal(s.Bytes(), &r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding %s line %d", path, line)
+		}
+		if r.Relayed != nil {
+			l.unsettled[r.Relayed.TxHash] = *r.Relayed
+			continue
+		}
+		l.memory.add(r.Charge)
+		delete(l.unsettled, r.TxHash)
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading ledger")
+	}
+	return l, nil
+}
+
+// Charge implements Ledger.
+func (l *FileLedger) Charge(c Charge) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+	err := l.append(c)
+	if err != nil {
+		return errors.Wrap(err, "writing charge")
+	}
+	l.memory.add(c)
+	delete(l.unsettled, c.TxHash)
+	return nil
+}
+
+// Relay implements Journal.
+func (l *FileLedger) Relay(p Relayed) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+	err := l.append(struct {
+		Relayed *Relayed `json:"relayed"`
+	}{&p})
+	if err != nil {
+		return errors.Wrap(err, "writing relayed transaction")
+	}
+	l.unsettled[p.TxHash] = p
+	return nil
+}
+
+// Unsettled implements Journal.
+func (l *FileLedger) Unsettled() []Relayed {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+	unsettled := make([]Relayed, 0, len(l.unsettled))
+	for _, p := range l.unsettled {
+		unsettled = append(unsettled, p)
+	}
+	return unsettled
+}
+
+// append appends a line to the file, memory.mu must be held.
+func (l *FileLedger) append(line interface{}) error {
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(file).Encode(line)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Spent implements Ledger.
+func (l *FileLedger) Spent(user common.Address) (*big.Int, error) {
+	return l.memory.Spent(user)
+}
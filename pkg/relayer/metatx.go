@@ -0,0 +1,48 @@
+// Package relayer submits the meta-transactions of wallet owners who hold no
+// ether. The owner signs the call of a wallet method, the relayer, a
+// controller of the wallets, submits it with executeRelayedTransaction and
+// pays for its gas. The fees paid are accounted per owner in a Ledger and can
+// be capped by an allowance.
+package relayer
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// MetaTx is a call of a wallet method signed by the owner of the wallet.
+type MetaTx struct {
+	Wallet common.Address
+	// Nonce must be the relay nonce of the wallet, incremented by each
+	// relayed transaction.
+	Nonce     *big.Int
+	Data      []byte
+	Signature []byte
+}
+
+// Hash returns the hash signed by the owner for a relayed call: the Ethereum
+// signed message hash of keccak256("rlx:" || nonce || data), as verified by
+// executeRelayedTransaction.
+func Hash(nonce *big.Int, data []byte) common.Hash {
+	message := crypto.Keccak256([]byte("rlx:"), abi.U256(new(big.Int).Set(nonce)), data)
+	return crypto.Keccak256Hash([]byte("\x19Ethereum Signed Message:\n32"), message)
+}
+
+// Sign returns the meta-transaction calling data on wallet with the given
+// relay nonce, signed by the owner of the wallet.
+func Sign(wallet common.Address, nonce *big.Int, data []byte, signHash signing.SignHashFunc) (MetaTx, error) {
+	sig, err := signing.SignHash(Hash(nonce, data), signHash)
+	if err != nil {
+		return MetaTx{}, err
+	}
+	return MetaTx{Wallet: wallet, Nonce: nonce, Data: data, Signature: sig}, nil
+}
+
+// Signer returns the address which signed the meta-transaction.
+func (m MetaTx) Signer() (common.Address, error) {
+	return signing.RecoverHash(Hash(m.Nonce, m.Data), m.Signature)
+}
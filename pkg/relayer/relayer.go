@@ -0,0 +1,290 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Errors returned by Submit for meta-transactions which are not relayed.
+var (
+	ErrNotOwner          = errors.New("meta-transaction not signed by the owner of the wallet")
+	ErrNonce             = errors.New("invalid relay nonce")
+	ErrNotEligible       = errors.New("user not eligible for relayed transactions")
+	ErrAllowanceExceeded = errors.New("relayed fee allowance exceeded")
+)
+
+// Relayer submits meta-transactions with the relayer key. The relayer account
+// must be a controller of the wallets.
+type Relayer struct {
+	backend  bind.ContractBackend
+	receipts bind.DeployBackend
+	opts     *bind.TransactOpts
+	ledger   Ledger
+
+	// Allowance is the total fee in wei relayed for each user, unlimited when nil.
+	Allowance *big.Int
+	// Eligible reports whether a user may have meta-transactions relayed,
+	// every owner may when nil.
+	Eligible func(ctx context.Context, user common.Address) (bool, error)
+	Logger   logging.Logger
+
+	mu sync.Mutex
+	// nonces are the next relay nonces of the wallets with pending
+	// meta-transactions.
+	nonces  map[common.Address]*big.Int
+	pending map[common.Hash]Relayed
+}
+
+// New creates a relayer sending transactions with opts through backend, and
+// charging their fees to the ledger once their receipt is found in receipts.
+// The transactions relayed before a restart are settled as well when the
+// ledger implements Journal.
+func New(backend bind.ContractBackend, receipts bind.DeployBackend, opts *bind.TransactOpts, ledger Ledger) *Relayer {
+	r := &Relayer{
+		backend:  backend,
+		receipts: receipts,
+		opts:     opts,
+		ledger:   ledger,
+		nonces:   make(map[common.Address]*big.Int),
+		pending:  make(map[common.Hash]Relayed),
+	}
+	if journal, ok := ledger.(Journal); ok {
+		for _, p := range journal.Unsettled() {
+			r.pending[p.TxHash] = p
+			next := new(big.Int).Add(p.Nonce, big.NewInt(1))
+			if n, ok := r.nonces[p.Wallet]; !ok || next.Cmp(n) > 0 {
+				r.nonces[p.Wallet] = next
+			}
+		}
+	}
+	return r
+}
+
+// Submit verifies a meta-transaction and sends it. Meta-transactions of a
+// wallet are accepted in the order of their nonce, including those still
+// pending. The fees of the pending transactions of a user are counted against
+// the allowance at their gas limit until they are settled.
+func (r *Relayer) Submit(ctx context.Context, m MetaTx) (*types.Transaction, error) {
+	wallet, err := bindings.NewWallet(m.Wallet, r.backend)
+	if err != nil {
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	owner, err := wallet.Owner(callOpts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting owner of wallet %s", m.Wallet.Hex())
+	}
+	signer, err := m.Signer()
+	if err != nil {
+		return nil, err
+	}
+	if signer != owner {
+		return nil, errors.Wrapf(ErrNotOwner, "signed by %s", signer.Hex())
+	}
+
+	if r.Eligible != nil {
+		ok, err := r.Eligible(ctx, owner)
+		if err != nil {
+			return nil, errors.Wrap(err, "checking eligibility")
+		}
+		if !ok {
+			return nil, errors.Wrap(ErrNotEligible, owner.Hex())
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Allowance != nil {
+		spent, err := r.ledger.Spent(owner)
+		if err != nil {
+			return nil, err
+		}
+		reserved := r.reserved(owner)
+		if new(big.Int).Add(spent, reserved).Cmp(r.Allowance) >= 0 {
+			return nil, errors.Wrapf(ErrAllowanceExceeded, "%s spent %s wei, %s wei reserved by pending transactions", owner.Hex(), spent, reserved)
+		}
+	}
+
+	next, ok := r.nonces[m.Wallet]
+	if !ok {
+		next, err = wallet.RelayNonce(callOpts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting relay nonce")
+		}
+	}
+	if m.Nonce == nil || m.Nonce.Cmp(next) != 0 {
+		return nil, errors.Wrapf(ErrNonce, "expected %s", next)
+	}
+
+	opts := *r.opts
+	opts.Context = ctx
+	tx, err := wallet.ExecuteRelayedTransaction(&opts, m.Nonce, m.Data, m.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "sending relayed transaction")
+	}
+	r.nonces[m.Wallet] = new(big.Int).Add(m.Nonce, big.NewInt(1))
+	p := Relayed{
+		TxHash:   tx.Hash(),
+		User:     owner,
+		Wallet:   m.Wallet,
+		Nonce:    m.Nonce,
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+	}
+	r.pending[tx.Hash()] = p
+	logging.Or(r.Logger).Info("Relayed meta-transaction", "wallet", m.Wallet, "user", owner, "nonce", m.Nonce, "hash", tx.Hash())
+	if journal, ok := r.ledger.(Journal); ok {
+		// The transaction is sent, it is settled by this relayer even when
+		// it cannot be journaled.
+		err = journal.Relay(p)
+		if err != nil {
+			logging.Or(r.Logger).Error("Journaling relayed transaction failed, its fee is not settled after a restart", "hash", tx.Hash(), "err", err)
+		}
+	}
+	return tx, nil
+}
+
+// reserved returns the worst-case fee of the pending transactions of user,
+// r.mu must be held.
+func (r *Relayer) reserved(user common.Address) *big.Int {
+	reserved := new(big.Int)
+	for _, p := range r.pending {
+		if p.User == user {
+			reserved.Add(reserved, p.MaxFee())
+		}
+	}
+	return reserved
+}
+
+// Pending returns the number of relayed transactions not mined yet.
+func (r *Relayer) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Settle charges the fees of the mined relayed transactions to their user.
+func (r *Relayer) Settle(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, p := range r.pending {
+		receipt, err := r.receipts.TransactionReceipt(ctx, hash)
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "getting receipt of %s", hash.Hex())
+		}
+		c := Charge{
+			Time:   time.Now(),
+			User:   p.User,
+			Wallet: p.Wallet,
+			TxHash: hash,
+			Fee:    new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), p.GasPrice),
+			Failed: receipt.Status != types.ReceiptStatusSuccessful,
+		}
+		err = r.ledger.Charge(c)
+		if err != nil {
+			return err
+		}
+		delete(r.pending, hash)
+		if c.Failed {
+			// The nonce of the wallet was not incremented, read it again
+			// from the chain.
+			delete(r.nonces, p.Wallet)
+			logging.Or(r.Logger).Warn("Relayed transaction reverted", "wallet", p.Wallet, "hash", hash)
+		}
+	}
+
+	// Forget the nonces of the wallets without pending transactions, the
+	// owner may have incremented them directly.
+	wallets := make(map[common.Address]bool)
+	for _, p := range r.pending {
+		wallets[p.Wallet] = true
+	}
+	for w := range r.nonces {
+		if !wallets[w] {
+			delete(r.nonces, w)
+		}
+	}
+	return nil
+}
+
+// Run settles the relayed transactions every interval until the context is cancelled.
+func (r *Relayer) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			err := r.Settle(ctx)
+			if err != nil && ctx.Err() == nil {
+				logging.Or(r.Logger).Warn("Settling relayed transactions failed", "err", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Status is the fee account of a user.
+type Status struct {
+	User  common.Address
+	Spent *big.Int
+	// Reserved is the worst-case fee of the pending transactions.
+	Reserved *big.Int
+	// Remaining is nil when the allowance is unlimited.
+	Remaining *big.Int
+}
+
+// Status returns the fee account of user.
+func (r *Relayer) Status(user common.Address) (Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spent, err := r.ledger.Spent(user)
+	if err != nil {
+		return Status{}, err
+	}
+	s := Status{User: user, Spent: spent, Reserved: r.reserved(user)}
+	if r.Allowance != nil {
+		s.Remaining = new(big.Int).Sub(r.Allowance, spent)
+		s.Remaining.Sub(s.Remaining, s.Reserved)
+		if s.Remaining.Sign() < 0 {
+			s.Remaining.SetInt64(0)
+		}
+	}
+	return s, nil
+}
+
+const balanceOfABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
+
+// TokenHolders returns an eligibility check accepting the holders of an ERC20
+// or ERC721 token, e.g. the holders of a membership NFT.
+func TokenHolders(token common.Address, backend bind.ContractCaller) func(ctx context.Context, user common.Address) (bool, error) {
+	parsed, err := abi.JSON(strings.NewReader(balanceOfABI))
+	if err != nil {
+		panic(err)
+	}
+	contract := bind.NewBoundContract(token, parsed, backend, nil, nil)
+	return func(ctx context.Context, user common.Address) (bool, error) {
+		balance := new(big.Int)
+		err := contract.Call(&bind.CallOpts{Context: ctx}, &balance, "balanceOf", user)
+		if err != nil {
+			return false, errors.Wrapf(err, "getting token balance of %s", user.Hex())
+		}
+		return balance.Sign() > 0, nil
+	}
+}
@@ -0,0 +1,302 @@
+// Package replay checks that two providers return the same history of
+// events. The same backfill is run against both over the same blocks, and the
+// events and their order are compared, flagging the provider bugs which would
+// otherwise corrupt an index silently: logs missing from one provider, logs
+// returned twice or out of chain order, logs flagged as removed from the
+// canonical chain, and logs whose content differs.
+//
+// For example:
+//
+//	report, err := replay.Check(ctx, replay.Source{Name: "infura", Backend: a}, replay.Source{Name: "alchemy", Backend: b}, replay.Options{StartBlock: deployment}, contracts...)
+//	for _, d := range report.Differences {
+//		fmt.Println(d)
+//	}
+package replay
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/backfill"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// The kinds of differences.
+const (
+	// Missing is an event returned by the other provider only.
+	Missing = "missing"
+	// Duplicate is an event returned more than once by a provider.
+	Duplicate = "duplicate"
+	// Order is an event returned before an event preceding it in the chain.
+	Order = "order"
+	// Removed is an event flagged as removed from the canonical chain, which
+	// a filter of past blocks never returns.
+	Removed = "removed"
+	// Mismatch is an event returned by both providers with a different
+	// block hash, contract or arguments.
+	Mismatch = "mismatch"
+)
+
+// Source is a provider whose events are compared.
+type Source struct {
+	Name    string
+	Backend indexer.Backend
+}
+
+// Options set the backfill run against both providers.
+type Options struct {
+	// StartBlock is the first block compared, usually the deployment block
+	// of the contracts.
+	StartBlock uint64
+	// EndBlock is the last block compared. When zero, it is the lowest of
+	// the heads of the providers, minus Confirmations.
+	EndBlock uint64
+	// Confirmations are the blocks left out below the heads, which may still
+	// be reorganized while the providers are compared.
+	Confirmations uint64
+	// RangeSize is the number of blocks filtered at once.
+	RangeSize uint64
+	// Events restricts the comparison to the events with these names.
+	Events []string
+	// Logger receives the progress of the backfills.
+	Logger logging.Logger
+}
+
+// Difference is an event on which the providers disagree.
+type Difference struct {
+	Kind string `json:"kind"`
+	// Provider is the provider at fault: the provider missing the event, or
+	// returning it twice, out of order, removed or different.
+	Provider string        `json:"provider"`
+	Event    indexer.Event `json:"event"`
+	// Other is the event returned by the other provider for a mismatch.
+	Other *indexer.Event `json:"other,omitempty"`
+}
+
+func (d Difference) String() string {
+	e := d.Event
+	s := fmt.Sprintf("%s: %s %s.%s at block %d tx %s log %d", d.Provider, d.Kind, e.Contract, e.Name, e.BlockNumber, e.TxHash.Hex(), e.LogIndex)
+	if d.Other != nil {
+		s += fmt.Sprintf(", other has %s.%s in block %s", d.Other.Contract, d.Other.Name, d.Other.BlockHash.Hex())
+	}
+	return s
+}
+
+// Report is the outcome of a comparison.
+type Report struct {
+	StartBlock uint64 `json:"start_block"`
+	EndBlock   uint64 `json:"end_block"`
+	// Events are the number of events returned by each provider.
+	Events      map[string]int `json:"events"`
+	Differences []Difference   `json:"differences"`
+}
+
+// Check runs the same backfill of the contracts against both providers and
+// returns their differences.
+func Check(ctx context.Context, a, b Source, opts Options, contracts ...indexer.Contract) (*Report, error) {
+	if a.Name == b.Name {
+		return nil, errors.Errorf("both providers are named %q", a.Name)
+	}
+	end := opts.EndBlock
+	if end == 0 {
+		var err error
+		end, err = commonHead(ctx, a, b, opts.Confirmations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if end < opts.StartBlock {
+		return nil, errors.Errorf("end block %d is before start block %d", end, opts.StartBlock)
+	}
+
+	ra, err := run(ctx, a, opts, end, contracts)
+	if err != nil {
+		return nil, err
+	}
+	rb, err := run(ctx, b, opts, end, contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		StartBlock: opts.StartBlock,
+		EndBlock:   end,
+		Events:     map[string]int{a.Name: len(ra.events), b.Name: len(rb.events)},
+	}
+	report.Differences = append(report.Differences, ra.anomalies(a.Name)...)
+	report.Differences = append(report.Differences, rb.anomalies(b.Name)...)
+	report.Differences = append(report.Differences, compare(a.Name, ra, b.Name, rb)...)
+	sort.SliceStable(report.Differences, func(i, j int) bool {
+		return report.Differences[i].Event.Position().Before(report.Differences[j].Event.Position())
+	})
+	return report, nil
+}
+
+// commonHead returns the lowest head of the providers, minus confirmations.
+func commonHead(ctx context.Context, a, b Source, confirmations uint64) (uint64, error) {
+	var end uint64
+	for i, s := range []Source{a, b} {
+		h, err := s.Backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "getting latest block of %s", s.Name)
+		}
+		head := h.Number.Uint64()
+		if i == 0 || head < end {
+			end = head
+		}
+	}
+	if end < confirmations {
+		return 0, nil
+	}
+	return end - confirmations, nil
+}
+
+// key identifies a log in the chain.
+type key struct {
+	block uint64
+	tx    common.Hash
+	index uint
+}
+
+func keyOf(e indexer.Event) key {
+	return key{block: e.BlockNumber, tx: e.TxHash, index: e.LogIndex}
+}
+
+// result is the outcome of the backfill against a provider.
+type result struct {
+	// events are the decoded events, in chain order.
+	events []indexer.Event
+	// logs are the logs in the order the provider returned them.
+	logs []types.Log
+}
+
+func run(ctx context.Context, s Source, opts Options, end uint64, contracts []indexer.Contract) (*result, error) {
+	rec := &recorder{Backend: s.Backend}
+	r := &result{}
+	handler := indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+		r.events = append(r.events, events...)
+		return nil
+	})
+	bf := backfill.New(rec, &backfill.MemoryCheckpoint{}, handler, contracts...)
+	bf.StartBlock = opts.StartBlock
+	bf.EndBlock = end
+	bf.RangeSize = opts.RangeSize
+	bf.Events = opts.Events
+	bf.Logger = logging.With(logging.Or(opts.Logger), "provider", s.Name)
+	err := bf.Run(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "backfilling from %s", s.Name)
+	}
+	r.logs = rec.logs()
+	return r, nil
+}
+
+// anomalies returns the events a provider returned twice, out of chain order
+// or flagged as removed. The backfill sorts the events of a range, so the
+// order is checked on the logs as the provider returned them.
+func (r *result) anomalies(provider string) []Difference {
+	events := make(map[key]indexer.Event, len(r.events))
+	for _, e := range r.events {
+		events[keyOf(e)] = e
+	}
+	event := func(l types.Log) indexer.Event {
+		e, ok := events[key{block: l.BlockNumber, tx: l.TxHash, index: l.Index}]
+		if !ok {
+			// Logs of events left out by the backfill are not decoded.
+			e = indexer.Event{Address: l.Address, BlockNumber: l.BlockNumber, BlockHash: l.BlockHash, TxHash: l.TxHash, LogIndex: l.Index, Removed: l.Removed}
+		}
+		return e
+	}
+
+	var diffs []Difference
+	seen := make(map[key]bool, len(r.logs))
+	var last indexer.Position
+	for i, l := range r.logs {
+		k := key{block: l.BlockNumber, tx: l.TxHash, index: l.Index}
+		if seen[k] {
+			diffs = append(diffs, Difference{Kind: Duplicate, Provider: provider, Event: event(l)})
+			continue
+		}
+		seen[k] = true
+		p := indexer.Position{BlockNumber: l.BlockNumber, LogIndex: l.Index}
+		if i > 0 && p.Before(last) {
+			diffs = append(diffs, Difference{Kind: Order, Provider: provider, Event: event(l)})
+		} else {
+			last = p
+		}
+		if l.Removed {
+			diffs = append(diffs, Difference{Kind: Removed, Provider: provider, Event: event(l)})
+		}
+	}
+	return diffs
+}
+
+// compare returns the events missing from a provider, and the events whose
+// content differs between them.
+func compare(nameA string, a *result, nameB string, b *result) []Difference {
+	index := func(events []indexer.Event) map[key]indexer.Event {
+		m := make(map[key]indexer.Event, len(events))
+		for _, e := range events {
+			m[keyOf(e)] = e
+		}
+		return m
+	}
+	ea, eb := index(a.events), index(b.events)
+
+	var diffs []Difference
+	for k, e := range ea {
+		other, ok := eb[k]
+		if !ok {
+			diffs = append(diffs, Difference{Kind: Missing, Provider: nameB, Event: e})
+			continue
+		}
+		if !same(e, other) {
+			o := other
+			diffs = append(diffs, Difference{Kind: Mismatch, Provider: nameB, Event: e, Other: &o})
+		}
+	}
+	for k, e := range eb {
+		if _, ok := ea[k]; !ok {
+			diffs = append(diffs, Difference{Kind: Missing, Provider: nameA, Event: e})
+		}
+	}
+	return diffs
+}
+
+// same tells whether two events at the same position have the same content.
+// The removed flags are reported as anomalies of their provider.
+func same(a, b indexer.Event) bool {
+	return a.BlockHash == b.BlockHash && a.Address == b.Address && a.Name == b.Name && a.TxIndex == b.TxIndex && reflect.DeepEqual(a.Args, b.Args)
+}
+
+// recorder records the logs returned by the backend, in their order.
+type recorder struct {
+	indexer.Backend
+
+	mu  sync.Mutex
+	log []types.Log
+}
+
+func (r *recorder) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := r.Backend.FilterLogs(ctx, query)
+	if err == nil {
+		r.mu.Lock()
+		r.log = append(r.log, logs...)
+		r.mu.Unlock()
+	}
+	return logs, err
+}
+
+func (r *recorder) logs() []types.Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.log
+}
@@ -0,0 +1,220 @@
+// Package rotation rotates the controller key of the operator as a single
+// workflow, rather than a series of manual transactions:
+//
+//  1. check that the old account is a controller and the new one is not,
+//  2. add the new account as a controller,
+//  3. run a canary signed by the new key,
+//  4. switch the account of the monolithd configuration to the new one,
+//  5. remove the old account from the controllers.
+//
+// When a step fails, the steps already done are undone in reverse order, so
+// that the old key remains in charge.
+package rotation
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+	"github.com/tokencard/contracts/v2/pkg/session"
+)
+
+// Step is a step of a workflow.
+type Step struct {
+	Name string
+	Do   func(ctx context.Context) error
+	// Undo reverts Do, it is nil when there is nothing to revert.
+	Undo func(ctx context.Context) error
+}
+
+// ErrAborted is returned when a step was not confirmed.
+var ErrAborted = errors.New("aborted")
+
+// Run runs the steps in order, asking confirm before each of them when it is
+// not nil. When a step fails or is not confirmed, the steps done are undone
+// in reverse order and the error of the step is returned, along with the
+// errors of the undo steps which failed too.
+func Run(ctx context.Context, steps []Step, confirm func(Step) (bool, error), logger logging.Logger) error {
+	log := logging.Or(logger)
+	for i, s := range steps {
+		if confirm != nil {
+			ok, err := confirm(s)
+			if err == nil && !ok {
+				err = ErrAborted
+			}
+			if err != nil {
+				return rollback(ctx, steps[:i], errors.Wrapf(err, "confirming %s", s.Name), log)
+			}
+		}
+		log.Info("Running step", "step", s.Name)
+		err := s.Do(ctx)
+		if err != nil {
+			return rollback(ctx, steps[:i], errors.Wrap(err, s.Name), log)
+		}
+	}
+	return nil
+}
+
+func rollback(ctx context.Context, done []Step, cause error, log logging.Logger) error {
+	log.Error("Rolling back", "err", cause)
+	failed := cause
+	for i := len(done) - 1; i >= 0; i-- {
+		s := done[i]
+		if s.Undo == nil {
+			continue
+		}
+		log.Info("Undoing step", "step", s.Name)
+		err := s.Undo(ctx)
+		if err != nil {
+			failed = errors.Errorf("%v; undoing %s: %v", failed, s.Name, err)
+		}
+	}
+	return failed
+}
+
+// Controller rotates a controller of the Controller contract from Old to New.
+type Controller struct {
+	Controller *bindings.Controller
+	// Receipts waits for the transactions to be mined.
+	Receipts bind.DeployBackend
+	// Admin sends the transactions adding and removing the controllers, it
+	// must be an admin or the owner of the Controller.
+	Admin *bind.TransactOpts
+	Old   common.Address
+	New   common.Address
+	// Canary is run with the key of the new account, the canary step is
+	// skipped when nil.
+	Canary *canary.Canary
+	// ConfigFile is the monolithd configuration whose account is switched
+	// from Old to New, the step is skipped when empty.
+	ConfigFile string
+}
+
+// Steps returns the steps of the rotation.
+func (c *Controller) Steps() []Step {
+	steps := []Step{
+		{Name: "check roles", Do: c.check},
+		{
+			Name: "add controller " + c.New.Hex(),
+			Do: func(ctx context.Context) error {
+				return c.transact(ctx, c.Controller.AddController, c.New)
+			},
+			Undo: func(ctx context.Context) error {
+				return c.transact(ctx, c.Controller.RemoveController, c.New)
+			},
+		},
+	}
+	if c.Canary != nil {
+		steps = append(steps, Step{
+			Name: "run canary",
+			Do: func(ctx context.Context) error {
+				_, err := c.Canary.Run(ctx)
+				return err
+			},
+		})
+	}
+	if c.ConfigFile != "" {
+		var original []byte
+		var mode os.FileMode
+		steps = append(steps, Step{
+			Name: "switch account of " + c.ConfigFile,
+			Do: func(ctx context.Context) error {
+				info, err := os.Stat(c.ConfigFile)
+				if err != nil {
+					return errors.Wrap(err, "reading configuration file")
+				}
+				mode = info.Mode().Perm()
+				original, err = ioutil.ReadFile(c.ConfigFile)
+				if err != nil {
+					return errors.Wrap(err, "reading configuration file")
+				}
+				updated, err := SwitchAccount(original, c.Old, c.New)
+				if err != nil {
+					return err
+				}
+				return ioutil.WriteFile(c.ConfigFile, updated, mode)
+			},
+			Undo: func(ctx context.Context) error {
+				return ioutil.WriteFile(c.ConfigFile, original, mode)
+			},
+		})
+	}
+	return append(steps, Step{
+		Name: "remove controller " + c.Old.Hex(),
+		Do: func(ctx context.Context) error {
+			return c.transact(ctx, c.Controller.RemoveController, c.Old)
+		},
+		Undo: func(ctx context.Context) error {
+			return c.transact(ctx, c.Controller.AddController, c.Old)
+		},
+	})
+}
+
+// check checks that the rotation can start.
+func (c *Controller) check(ctx context.Context) error {
+	opts := &bind.CallOpts{Context: ctx}
+	old, err := c.Controller.IsController(opts, c.Old)
+	if err != nil {
+		return errors.Wrap(err, "calling isController")
+	}
+	if !old {
+		return errors.Errorf("%s is not a controller", c.Old.Hex())
+	}
+	isNew, err := c.Controller.IsController(opts, c.New)
+	if err != nil {
+		return errors.Wrap(err, "calling isController")
+	}
+	if isNew {
+		return errors.Errorf("%s is already a controller", c.New.Hex())
+	}
+	return nil
+}
+
+// transact sends a transaction of the admin and waits for it to succeed.
+func (c *Controller) transact(ctx context.Context, method func(*bind.TransactOpts, common.Address) (*types.Transaction, error), account common.Address) error {
+	opts, cancel, err := session.NewOptsBuilder(c.Admin).Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	tx, err := method(opts, account)
+	if err != nil {
+		return err
+	}
+	r, err := bind.WaitMined(ctx, c.Receipts, tx)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for transaction %s", tx.Hash().Hex())
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		return errors.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return nil
+}
+
+var accountField = regexp.MustCompile(`("account"\s*:\s*")(0x[0-9a-fA-F]{40})(")`)
+
+// SwitchAccount replaces the account of a JSON configuration, leaving the
+// rest of the file untouched. The account must be old.
+func SwitchAccount(config []byte, old, next common.Address) ([]byte, error) {
+	all := accountField.FindAllSubmatch(config, -1)
+	switch len(all) {
+	case 0:
+		return nil, errors.New("the configuration file has no account")
+	case 1:
+	default:
+		return nil, errors.New("the configuration file has several accounts")
+	}
+	m := all[0]
+	if common.HexToAddress(string(m[2])) != old {
+		return nil, errors.Errorf("the account of the configuration file is %s, not %s", m[2], old.Hex())
+	}
+	return accountField.ReplaceAll(config, []byte("${1}"+next.Hex()+"${3}")), nil
+}
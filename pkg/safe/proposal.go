@@ -0,0 +1,129 @@
+package safe
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// ErrDuplicateSignature is returned when an owner signs a proposal twice.
+var ErrDuplicateSignature = errors.New("proposal already signed by this owner")
+
+// Signature is the signature of a transaction by an owner.
+type Signature struct {
+	Signer common.Address `json:"signer"`
+	// Data is the 65 byte [R || S || V] signature of the transaction hash,
+	// with V either 27 or 28 as expected by the Safe.
+	Data hexutil.Bytes `json:"data"`
+}
+
+// Proposal is a transaction and the signatures collected for it so far. It is
+// shared between the owners as a JSON file.
+type Proposal struct {
+	Transaction Transaction `json:"transaction"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Sign signs the transaction with signHash and adds the signature.
+func (p *Proposal) Sign(signHash signing.SignHashFunc) (common.Address, error) {
+	sig, err := signing.SignHash(p.Transaction.Hash(), signHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return p.AddSignature(sig)
+}
+
+// AddSignature adds a [R || S || V] signature of the transaction hash and
+// returns the owner it was signed by.
+func (p *Proposal) AddSignature(sig []byte) (common.Address, error) {
+	signer, err := recoverSigner(p.Transaction.Hash(), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	for _, s := range p.Signatures {
+		if s.Signer == signer {
+			return common.Address{}, errors.Wrap(ErrDuplicateSignature, signer.Hex())
+		}
+	}
+	p.Signatures = append(p.Signatures, Signature{Signer: signer, Data: sig})
+	return signer, nil
+}
+
+// Verify checks that every signature was made by the signer it claims, and
+// that the signer is one of owners.
+func (p *Proposal) Verify(owners []common.Address) error {
+	isOwner := make(map[common.Address]bool, len(owners))
+	for _, o := range owners {
+		isOwner[o] = true
+	}
+	hash := p.Transaction.Hash()
+	for _, s := range p.Signatures {
+		signer, err := recoverSigner(hash, s.Data)
+		if err != nil {
+			return err
+		}
+		if signer != s.Signer {
+			return errors.Errorf("signature of %s was made by %s", s.Signer.Hex(), signer.Hex())
+		}
+		if !isOwner[signer] {
+			return errors.Errorf("%s is not an owner of the Safe", signer.Hex())
+		}
+	}
+	return nil
+}
+
+// PackedSignatures returns the signatures in the form expected by
+// execTransaction: concatenated in ascending order of their signer.
+func (p *Proposal) PackedSignatures() []byte {
+	sigs := append([]Signature(nil), p.Signatures...)
+	sort.Slice(sigs, func(i, j int) bool {
+		return bytes.Compare(sigs[i].Signer[:], sigs[j].Signer[:]) < 0
+	})
+	var packed []byte
+	for _, s := range sigs {
+		packed = append(packed, s.Data...)
+	}
+	return packed
+}
+
+// recoverSigner returns the owner of an ECDSA signature. The Safe gives V
+// values other than 27 and 28 other meanings, such as contract signatures.
+func recoverSigner(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) == 65 && sig[64] != 27 && sig[64] != 28 {
+		return common.Address{}, errors.Errorf("unsupported signature type %d", sig[64])
+	}
+	return signing.RecoverHash(hash, sig)
+}
+
+// LoadProposal reads a proposal from a JSON file.
+func LoadProposal(path string) (*Proposal, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading proposal")
+	}
+	p := &Proposal{}
+	err = json.Unmarshal(b, p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding proposal %s", path)
+	}
+	return p, nil
+}
+
+// Save writes the proposal to a JSON file.
+func (p *Proposal) Save(path string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding proposal")
+	}
+	err = ioutil.WriteFile(path, append(b, '\n'), 0644)
+	if err != nil {
+		return errors.Wrap(err, "writing proposal")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package safe
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// ABI is the subset of the Safe ABI used by this package.
+const ABI = `[
+{"constant":true,"inputs":[],"name":"VERSION","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"}
+]`
+
+// Safe is a deployed Gnosis Safe.
+type Safe struct {
+	address  common.Address
+	abi      abi.ABI
+	contract *bind.BoundContract
+}
+
+// New binds the Safe deployed at address.
+func New(address common.Address, backend bind.ContractBackend) (*Safe, error) {
+	parsed, err := abi.JSON(strings.NewReader(ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Safe{
+		address:  address,
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// Address returns the address of the Safe.
+func (s *Safe) Address() common.Address {
+	return s.address
+}
+
+// Version returns the version of the Safe contract, e.g. "1.1.1".
+func (s *Safe) Version(ctx context.Context) (string, error) {
+	var version string
+	err := s.contract.Call(&bind.CallOpts{Context: ctx}, &version, "VERSION")
+	if err != nil {
+		return "", errors.Wrap(err, "getting Safe version")
+	}
+	return version, nil
+}
+
+// Nonce returns the nonce of the next transaction of the Safe.
+func (s *Safe) Nonce(ctx context.Context) (*big.Int, error) {
+	nonce := new(big.Int)
+	err := s.contract.Call(&bind.CallOpts{Context: ctx}, &nonce, "nonce")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting Safe nonce")
+	}
+	return nonce, nil
+}
+
+// Threshold returns the number of owner signatures a transaction needs.
+func (s *Safe) Threshold(ctx context.Context) (uint64, error) {
+	threshold := new(big.Int)
+	err := s.contract.Call(&bind.CallOpts{Context: ctx}, &threshold, "getThreshold")
+	if err != nil {
+		return 0, errors.Wrap(err, "getting Safe threshold")
+	}
+	return threshold.Uint64(), nil
+}
+
+// Owners returns the owners of the Safe.
+func (s *Safe) Owners(ctx context.Context) ([]common.Address, error) {
+	var owners []common.Address
+	err := s.contract.Call(&bind.CallOpts{Context: ctx}, &owners, "getOwners")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting Safe owners")
+	}
+	return owners, nil
+}
+
+// Propose creates a proposal calling to with data from the Safe, at the
+// current nonce of the Safe.
+func (s *Safe) Propose(ctx context.Context, chainID *big.Int, to common.Address, data []byte) (*Proposal, error) {
+	nonce, err := s.Nonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx := Transaction{
+		Safe:      s.address,
+		To:        to,
+		Value:     new(hexutil.Big),
+		Data:      data,
+		Operation: Call,
+		SafeTxGas: new(hexutil.Big),
+		BaseGas:   new(hexutil.Big),
+		GasPrice:  new(hexutil.Big),
+		Nonce:     (*hexutil.Big)(nonce),
+	}
+	if chainIDInDomain(version) {
+		tx.ChainID = (*hexutil.Big)(chainID)
+	}
+	return &Proposal{Transaction: tx}, nil
+}
+
+// chainIDInDomain reports whether the EIP-712 domain of a Safe version
+// includes the chain ID, which it does from 1.3.0.
+func chainIDInDomain(version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major > 1 || major == 1 && minor >= 3
+}
+
+// ExecData returns the call data of the execTransaction call executing p.
+func (s *Safe) ExecData(p *Proposal) ([]byte, error) {
+	return s.abi.Pack("execTransaction", execArgs(p)...)
+}
+
+func execArgs(p *Proposal) []interface{} {
+	t := p.Transaction
+	return []interface{}{t.To, bigOf(t.Value), []byte(t.Data), uint8(t.Operation),
+		bigOf(t.SafeTxGas), bigOf(t.BaseGas), bigOf(t.GasPrice), t.GasToken, t.RefundReceiver, p.PackedSignatures()}
+}
+
+// Exec submits p with execTransaction once it has been signed by enough
+// owners. The Safe does not revert when the inner call fails, it emits an
+// ExecutionFailure event instead.
+func (s *Safe) Exec(opts *bind.TransactOpts, p *Proposal) (*types.Transaction, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p.Transaction.Safe != s.address {
+		return nil, errors.Errorf("proposal is for Safe %s, not %s", p.Transaction.Safe.Hex(), s.address.Hex())
+	}
+	nonce, err := s.Nonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bigOf(p.Transaction.Nonce).Cmp(nonce) != 0 {
+		return nil, errors.Errorf("proposal nonce is %s but the Safe is at nonce %s", bigOf(p.Transaction.Nonce), nonce)
+	}
+	owners, err := s.Owners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = p.Verify(owners)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := s.Threshold(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(p.Signatures)) < threshold {
+		return nil, errors.Errorf("proposal has %d of the %d signatures required", len(p.Signatures), threshold)
+	}
+
+	return s.contract.Transact(opts, "execTransaction", execArgs(p)...)
+}
@@ -0,0 +1,106 @@
+// Package safe executes calls through a Gnosis Safe multisig wallet.
+//
+// Contracts owned by a Safe only accept owner-restricted calls, such as
+// transferOwnership or addAdmin on the controller, from the Safe itself. A
+// Proposal wraps such a call in a Safe Transaction, collects the signatures of
+// the owners of the Safe and, once the threshold is reached, is submitted by
+// any account through execTransaction:
+//
+//	p, err := s.Propose(ctx, chainID, controller, data) // shared as JSON
+//	_, err = p.Sign(signHash)                           // by each owner
+//	tx, err := s.Exec(opts, p)                          // by anyone
+package safe
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// Operation is the kind of call made by the Safe.
+type Operation uint8
+
+// Operations supported by the Safe.
+const (
+	Call         Operation = 0
+	DelegateCall Operation = 1
+)
+
+// safeTxTypes are the EIP-712 types of the transactions signed by the owners.
+var safeTxTypes = signing.Types{
+	"SafeTx": {
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+		{Name: "operation", Type: "uint8"},
+		{Name: "safeTxGas", Type: "uint256"},
+		{Name: "baseGas", Type: "uint256"},
+		{Name: "gasPrice", Type: "uint256"},
+		{Name: "gasToken", Type: "address"},
+		{Name: "refundReceiver", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+	},
+}
+
+// Transaction is a call executed by a Safe. The gas refund fields are left
+// zero by Propose: the account submitting the transaction pays for its gas.
+type Transaction struct {
+	Safe           common.Address `json:"safe"`
+	To             common.Address `json:"to"`
+	Value          *hexutil.Big   `json:"value"`
+	Data           hexutil.Bytes  `json:"data"`
+	Operation      Operation      `json:"operation"`
+	SafeTxGas      *hexutil.Big   `json:"safeTxGas"`
+	BaseGas        *hexutil.Big   `json:"baseGas"`
+	GasPrice       *hexutil.Big   `json:"gasPrice"`
+	GasToken       common.Address `json:"gasToken"`
+	RefundReceiver common.Address `json:"refundReceiver"`
+	Nonce          *hexutil.Big   `json:"nonce"`
+	// ChainID is the chain of a Safe from version 1.3.0, nil for the
+	// earlier versions whose domain does not include it.
+	ChainID *hexutil.Big `json:"chainId,omitempty"`
+}
+
+// TypedData returns the EIP-712 message signed by the owners. The domain of
+// the Safes before 1.3.0 only has the verifying contract.
+func (t *Transaction) TypedData() signing.TypedData {
+	safe := t.Safe
+	domain := signing.Domain{VerifyingContract: &safe}
+	if t.ChainID != nil {
+		domain.ChainID = (*big.Int)(t.ChainID)
+	}
+	return signing.TypedData{
+		Types:       safeTxTypes,
+		PrimaryType: "SafeTx",
+		Domain:      domain,
+		Message: map[string]interface{}{
+			"to":             t.To,
+			"value":          bigOf(t.Value),
+			"data":           []byte(t.Data),
+			"operation":      uint8(t.Operation),
+			"safeTxGas":      bigOf(t.SafeTxGas),
+			"baseGas":        bigOf(t.BaseGas),
+			"gasPrice":       bigOf(t.GasPrice),
+			"gasToken":       t.GasToken,
+			"refundReceiver": t.RefundReceiver,
+			"nonce":          bigOf(t.Nonce),
+		},
+	}
+}
+
+// Hash returns the EIP-712 hash of the transaction signed by the owners.
+func (t *Transaction) Hash() common.Hash {
+	data := t.TypedData()
+	// The types are fixed and the values always encode.
+	hash, _ := data.Hash()
+	return hash
+}
+
+func bigOf(i *hexutil.Big) *big.Int {
+	if i == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(i)
+}
@@ -0,0 +1,163 @@
+// Package script runs Starlark scripts calling the commands of a program, so
+// that the operators write their maintenance scripts with conditions, loops
+// and functions rather than as lists of commands:
+//
+//	for owner in ["0x...", "0x..."]:
+//	    if not controller_roles(owner)["controller"]:
+//	        print(owner, "is not a controller")
+//	set_licence_amount("10")
+//	events("licence", from_block = 9000000)
+//
+// Each command is a function named after it, with its dashes replaced by
+// underscores. Its positional arguments are passed as the arguments of the
+// command and its keyword arguments as its flags, before them, see Args.
+// Starlark has no exceptions, a failing command stops the script.
+package script
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+func init() {
+	// The scripts are written at the top level, without functions, while
+	// and recursion stay disallowed.
+	resolve.AllowGlobalReassign = true
+}
+
+// Command runs a command of the program with its command line arguments.
+type Command func(ctx context.Context, args []string) error
+
+// Script holds the functions and values the scripts are run with.
+type Script struct {
+	// Commands are exposed as functions named after them.
+	Commands map[string]Command
+	// Globals are the other values of the scripts, e.g. the builtins
+	// returning the state of the contracts. They take precedence over the
+	// commands.
+	Globals starlark.StringDict
+	// Print receives the output of print, written to stdout when nil.
+	Print func(msg string)
+}
+
+// Exec runs the script of the file, read from src when it is not nil, see
+// starlark.ExecFile. Once ctx is done, the next command fails and stops the
+// script. As Starlark has no while statement nor recursion, the scripts end.
+func (s *Script) Exec(ctx context.Context, filename string, src interface{}) error {
+	out := s.Print
+	if out == nil {
+		out = func(msg string) { fmt.Println(msg) }
+	}
+	thread := &starlark.Thread{
+		Name:  filename,
+		Print: func(_ *starlark.Thread, msg string) { out(msg) },
+	}
+	thread.SetLocal(contextKey, ctx)
+
+	_, err := starlark.ExecFile(thread, filename, src, s.predeclared())
+	if e, ok := err.(*starlark.EvalError); ok {
+		return errors.New(e.Backtrace())
+	}
+	return err
+}
+
+// contextKey is the thread local holding the context of the script.
+const contextKey = "context"
+
+// Context returns the context of the script run by thread, for the builtins
+// of Globals.
+func Context(thread *starlark.Thread) context.Context {
+	ctx, ok := thread.Local(contextKey).(context.Context)
+	if !ok {
+		return context.Background()
+	}
+	return ctx
+}
+
+func (s *Script) predeclared() starlark.StringDict {
+	d := make(starlark.StringDict, len(s.Commands)+len(s.Globals))
+	for name, cmd := range s.Commands {
+		d[FunctionName(name)] = command(name, cmd)
+	}
+	for name, v := range s.Globals {
+		d[name] = v
+	}
+	return d
+}
+
+// FunctionName returns the name of the function of a command.
+func FunctionName(command string) string {
+	return strings.Replace(command, "-", "_", -1)
+}
+
+func command(name string, cmd Command) *starlark.Builtin {
+	return starlark.NewBuiltin(FunctionName(name), func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		ctx := Context(thread)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		a, err := Args(args, kwargs)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		err = cmd(ctx, a)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		return starlark.None, nil
+	})
+}
+
+// Args returns the command line of the arguments of a function: the keyword
+// arguments as flags, sorted by name, with their underscores replaced by
+// dashes, followed by the positional arguments. The arguments are strings,
+// integers or, for the flags, booleans:
+//
+//	f("licence", 1, from_block = 10, follow = True) // -follow -from-block=10 licence 1
+func Args(args starlark.Tuple, kwargs []starlark.Tuple) ([]string, error) {
+	flags := make([]string, 0, len(kwargs))
+	for _, kv := range kwargs {
+		name := strings.Replace(string(kv[0].(starlark.String)), "_", "-", -1)
+		if b, ok := kv[1].(starlark.Bool); ok {
+			if b {
+				flags = append(flags, "-"+name)
+			} else {
+				flags = append(flags, "-"+name+"=false")
+			}
+			continue
+		}
+		v, err := arg(kv[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "flag %s", name)
+		}
+		flags = append(flags, "-"+name+"="+v)
+	}
+	sort.Strings(flags)
+
+	line := flags
+	for i, a := range args {
+		v, err := arg(a)
+		if err != nil {
+			return nil, errors.Wrapf(err, "argument %d", i+1)
+		}
+		line = append(line, v)
+	}
+	return line, nil
+}
+
+func arg(v starlark.Value) (string, error) {
+	switch v := v.(type) {
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		return v.String(), nil
+	default:
+		return "", errors.Errorf("%s is not a string or an int", v.Type())
+	}
+}
@@ -0,0 +1,191 @@
+package session
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidOpts is the cause of the errors of the options rejected by an
+// OptsBuilder.
+var ErrInvalidOpts = errors.New("invalid transaction options")
+
+// GasPricer suggests the gas price of the transactions, *gas.Oracle and the
+// backends implement it.
+type GasPricer interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// NonceSource returns the next nonce of an account, the backends implement
+// it.
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// OptsBuilder composes the options of a transaction and checks them when they
+// are built, instead of filling a bind.TransactOpts by hand:
+//
+//	opts, cancel, err := session.NewOptsBuilder(signer.NewTransactOpts(key, chainID)).
+//		GasPrices(oracle).
+//		GasLimit(200000).
+//		Timeout(time.Minute).
+//		Build(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer cancel()
+//
+// An OptsBuilder is not safe for concurrent use, the options it builds are
+// never shared.
+type OptsBuilder struct {
+	opts     bind.TransactOpts
+	prices   GasPricer
+	nonces   NonceSource
+	deadline time.Time
+	timeout  time.Duration
+	loop     bool
+}
+
+// NewOptsBuilder returns a builder starting from a copy of base, usually the
+// options of a signer holding only From and Signer. A nil base has no signer.
+func NewOptsBuilder(base *bind.TransactOpts) *OptsBuilder {
+	return &OptsBuilder{opts: *copyTransactOpts(base)}
+}
+
+// Signer signs the transactions from the account of opts, the other options
+// of opts are ignored.
+func (b *OptsBuilder) Signer(opts *bind.TransactOpts) *OptsBuilder {
+	b.opts.From = opts.From
+	b.opts.Signer = opts.Signer
+	return b
+}
+
+// GasPrice sends the transactions with the given gas price.
+func (b *OptsBuilder) GasPrice(price *big.Int) *OptsBuilder {
+	b.opts.GasPrice = copyBig(price)
+	b.prices = nil
+	return b
+}
+
+// GasPrices sends the transactions with the gas price suggested by prices
+// when the options are built, e.g. by the oracle of a gas strategy.
+func (b *OptsBuilder) GasPrices(prices GasPricer) *OptsBuilder {
+	b.prices = prices
+	b.opts.GasPrice = nil
+	return b
+}
+
+// GasLimit sends the transactions with the given gas limit, estimated by the
+// binding when zero.
+func (b *OptsBuilder) GasLimit(limit uint64) *OptsBuilder {
+	b.opts.GasLimit = limit
+	return b
+}
+
+// Nonce sends the transaction with the given nonce.
+func (b *OptsBuilder) Nonce(nonce uint64) *OptsBuilder {
+	b.opts.Nonce = new(big.Int).SetUint64(nonce)
+	b.nonces = nil
+	return b
+}
+
+// Nonces sends the transaction with the next nonce of the account returned
+// by nonces when the options are built. Without a nonce, the binding gets it
+// from its backend when the transaction is sent.
+func (b *OptsBuilder) Nonces(nonces NonceSource) *OptsBuilder {
+	b.nonces = nonces
+	b.opts.Nonce = nil
+	return b
+}
+
+// Value sends the given amount of wei with the transactions.
+func (b *OptsBuilder) Value(value *big.Int) *OptsBuilder {
+	b.opts.Value = copyBig(value)
+	return b
+}
+
+// Deadline cancels the context of the options at the given time.
+func (b *OptsBuilder) Deadline(deadline time.Time) *OptsBuilder {
+	b.deadline = deadline
+	b.timeout = 0
+	return b
+}
+
+// Timeout cancels the context of the options after the given duration from
+// when they are built.
+func (b *OptsBuilder) Timeout(timeout time.Duration) *OptsBuilder {
+	b.timeout = timeout
+	b.deadline = time.Time{}
+	return b
+}
+
+// Loop marks the options as sent with several transactions in a loop. Their
+// gas limit must be set, the estimates of the bindings are made against the
+// state before the earlier transactions of the loop are mined, and their
+// nonce must not be, every transaction would replace the previous one.
+func (b *OptsBuilder) Loop() *OptsBuilder {
+	b.loop = true
+	return b
+}
+
+// Build checks the options and returns a private copy of them, bound to a
+// context derived from ctx with the deadline of the builder. The gas price
+// and the nonce of the sources are read with ctx. The cancel function must be
+// called once the options are no longer used. The errors of invalid options
+// wrap ErrInvalidOpts.
+func (b *OptsBuilder) Build(ctx context.Context) (*bind.TransactOpts, context.CancelFunc, error) {
+	err := b.validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := copyTransactOpts(&b.opts)
+	if b.prices != nil {
+		opts.GasPrice, err = b.prices.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "getting gas price")
+		}
+	}
+	if b.nonces != nil {
+		nonce, err := b.nonces.PendingNonceAt(ctx, opts.From)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "getting nonce of %s", opts.From.Hex())
+		}
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+	}
+
+	var cancel context.CancelFunc
+	switch {
+	case !b.deadline.IsZero():
+		opts.Context, cancel = context.WithDeadline(ctx, b.deadline)
+	case b.timeout > 0:
+		opts.Context, cancel = context.WithTimeout(ctx, b.timeout)
+	default:
+		opts.Context, cancel = context.WithCancel(ctx)
+	}
+	return opts, cancel, nil
+}
+
+func (b *OptsBuilder) validate() error {
+	switch {
+	case b.opts.Signer == nil:
+		return errors.Wrap(ErrInvalidOpts, "no signer")
+	case b.opts.Value != nil && b.opts.Value.Sign() < 0:
+		return errors.Wrapf(ErrInvalidOpts, "negative value %s", b.opts.Value)
+	case b.opts.GasPrice != nil && b.opts.GasPrice.Sign() < 0:
+		return errors.Wrapf(ErrInvalidOpts, "negative gas price %s", b.opts.GasPrice)
+	case b.timeout < 0:
+		return errors.Wrapf(ErrInvalidOpts, "negative timeout %s", b.timeout)
+	case !b.deadline.IsZero() && !b.deadline.After(time.Now()):
+		return errors.Wrapf(ErrInvalidOpts, "deadline %s has passed", b.deadline.Format(time.RFC3339))
+	case b.loop && b.opts.GasLimit == 0:
+		return errors.Wrap(ErrInvalidOpts, "no gas limit for the transactions of a loop")
+	case b.loop && (b.opts.Nonce != nil || b.nonces != nil):
+		return errors.Wrap(ErrInvalidOpts, "a nonce for the transactions of a loop")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CallOption overrides an option of a single call.
+type CallOption func(*bind.CallOpts)
+
+// TransactOption overrides an option of a single transaction.
+type TransactOption func(*bind.TransactOpts)
+
+// Call returns a private copy of the current call options bound to ctx, with
+// the overrides applied in order.
+func (s *Session) Call(ctx context.Context, overrides ...CallOption) *bind.CallOpts {
+	opts := s.CallOpts()
+	opts.Context = ctx
+	for _, o := range overrides {
+		o(opts)
+	}
+	return opts
+}
+
+// Transact returns a private copy of the current transact options bound to
+// ctx, with the overrides applied in order. The transactions are signed with
+// ctx when a signer was set by SignWith.
+func (s *Session) Transact(ctx context.Context, overrides ...TransactOption) *bind.TransactOpts {
+	opts := s.TransactOpts()
+	opts.Context = ctx
+	if sign := s.signer(); sign != nil {
+		opts.Signer = sign(ctx)
+	}
+	for _, o := range overrides {
+		o(opts)
+	}
+	return opts
+}
+
+// AtBlock reads the state of the given block, the latest when nil.
+func AtBlock(number *big.Int) CallOption {
+	number = copyBig(number)
+	return func(opts *bind.CallOpts) {
+		opts.BlockNumber = copyBig(number)
+		opts.Pending = false
+	}
+}
+
+// Pending reads the pending state.
+func Pending() CallOption {
+	return func(opts *bind.CallOpts) {
+		opts.Pending = true
+		opts.BlockNumber = nil
+	}
+}
+
+// CallFrom makes the call from the given account.
+func CallFrom(from common.Address) CallOption {
+	return func(opts *bind.CallOpts) {
+		opts.From = from
+	}
+}
+
+// WithValue sends the given amount of wei with the transaction.
+func WithValue(value *big.Int) TransactOption {
+	value = copyBig(value)
+	return func(opts *bind.TransactOpts) {
+		opts.Value = copyBig(value)
+	}
+}
+
+// WithNonce sends the transaction with the given nonce, the pending nonce of
+// the account when nil.
+func WithNonce(nonce *big.Int) TransactOption {
+	nonce = copyBig(nonce)
+	return func(opts *bind.TransactOpts) {
+		opts.Nonce = copyBig(nonce)
+	}
+}
+
+// WithGasPrice sends the transaction with the given gas price, the price
+// suggested by the node when nil.
+func WithGasPrice(price *big.Int) TransactOption {
+	price = copyBig(price)
+	return func(opts *bind.TransactOpts) {
+		opts.GasPrice = copyBig(price)
+	}
+}
+
+// WithGasLimit sends the transaction with the given gas limit, estimated when
+// zero.
+func WithGasLimit(limit uint64) TransactOption {
+	return func(opts *bind.TransactOpts) {
+		opts.GasLimit = limit
+	}
+}
@@ -0,0 +1,132 @@
+// Package session provides a concurrency safe alternative to the generated
+// <Contract>Session types.
+//
+// The generated sessions hold their TransactOpts by value and hand a pointer to
+// that field to every call, so a caller mutating the options (e.g. setting a
+// Nonce or GasLimit) races with every other goroutine using the session.
+//
+// A Session instead stores immutable snapshots of its options. Readers always
+// receive a private copy which they are free to modify, and writers replace the
+// snapshot atomically (copy-on-write). The guarantees are:
+//
+//   - TransactOpts and CallOpts are safe to call from any number of goroutines.
+//   - The returned options are never shared, mutations are not visible to the
+//     Session or to other callers.
+//   - Updates are serialised, each update sees the result of the previous one
+//     and readers observe either the old or the new snapshot, never a mix.
+//
+// The Signer and Context values are shared between copies, they must be safe
+// for concurrent use themselves.
+//
+// The generated sessions and the options of a Session fix the context of the
+// calls when they are built. Call and Transact instead return the options of
+// a single call bound to its context, so that its cancellation and deadline
+// reach the node, with the overrides of that call only:
+//
+//	amount, err := licence.LicenceAmountScaled(s.Call(ctx, session.AtBlock(n)))
+//	tx, err := wallet.Transfer(s.Transact(ctx, session.WithGasLimit(100000)), to, asset, amount)
+//
+// The Signer of the options signs with the context it was built with, e.g. a
+// KMS signer. SignWith instead builds the Signer of each transaction from the
+// context given to Transact.
+package session
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// Session holds the call and transact options used to interact with contracts.
+type Session struct {
+	mu       sync.Mutex // serialises writers
+	transact atomic.Value
+	call     atomic.Value
+	sign     atomic.Value // SignerFunc
+}
+
+// SignerFunc returns the function signing the transactions sent with ctx.
+type SignerFunc func(ctx context.Context) bind.SignerFn
+
+// New creates a new session. Both options are copied, nil is treated as the zero value.
+func New(transactOpts *bind.TransactOpts, callOpts *bind.CallOpts) *Session {
+	s := &Session{}
+	s.transact.Store(copyTransactOpts(transactOpts))
+	s.call.Store(copyCallOpts(callOpts))
+	return s
+}
+
+// TransactOpts returns a private copy of the current transact options.
+func (s *Session) TransactOpts() *bind.TransactOpts {
+	return copyTransactOpts(s.transact.Load().(*bind.TransactOpts))
+}
+
+// CallOpts returns a private copy of the current call options.
+func (s *Session) CallOpts() *bind.CallOpts {
+	return copyCallOpts(s.call.Load().(*bind.CallOpts))
+}
+
+// UpdateTransactOpts applies fn to a copy of the current transact options and
+// makes the result the new snapshot.
+func (s *Session) UpdateTransactOpts(fn func(*bind.TransactOpts)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opts := s.TransactOpts()
+	fn(opts)
+	s.transact.Store(opts)
+}
+
+// UpdateCallOpts applies fn to a copy of the current call options and makes the
+// result the new snapshot.
+func (s *Session) UpdateCallOpts(fn func(*bind.CallOpts)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opts := s.CallOpts()
+	fn(opts)
+	s.call.Store(opts)
+}
+
+// SignWith makes Transact sign the transactions with the signer built by fn
+// from their context, in place of the Signer of the transact options. A nil
+// fn restores the Signer of the options.
+func (s *Session) SignWith(fn SignerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sign.Store(fn)
+}
+
+// signer returns the function set by SignWith, nil when there is none.
+func (s *Session) signer() SignerFunc {
+	fn, _ := s.sign.Load().(SignerFunc)
+	return fn
+}
+
+func copyTransactOpts(opts *bind.TransactOpts) *bind.TransactOpts {
+	if opts == nil {
+		return &bind.TransactOpts{}
+	}
+	c := *opts
+	c.Nonce = copyBig(opts.Nonce)
+	c.Value = copyBig(opts.Value)
+	c.GasPrice = copyBig(opts.GasPrice)
+	return &c
+}
+
+func copyCallOpts(opts *bind.CallOpts) *bind.CallOpts {
+	if opts == nil {
+		return &bind.CallOpts{}
+	}
+	c := *opts
+	c.BlockNumber = copyBig(opts.BlockNumber)
+	return &c
+}
+
+func copyBig(i *big.Int) *big.Int {
+	if i == nil {
+		return nil
+	}
+	return new(big.Int).Set(i)
+}
@@ -0,0 +1,197 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AWSCredentials are the credentials signing the requests to AWS.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// AWSCredentialsFromEnv reads the credentials from the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+func AWSCredentialsFromEnv() (AWSCredentials, error) {
+	return AWSCredentialsFrom(os.Getenv)
+}
+
+// AWSCredentialsFrom reads the standard AWS_* variables with getenv, for
+// programs keeping their environment elsewhere than in the process.
+func AWSCredentialsFrom(getenv func(string) string) (AWSCredentials, error) {
+	c := AWSCredentials{
+		AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return AWSCredentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return c, nil
+}
+
+// AWSKMS is a KMS backed by an AWS KMS asymmetric ECC_SECG_P256K1 key, called
+// through the KMS JSON API with Signature Version 4 signed requests.
+type AWSKMS struct {
+	Region string
+	// KeyID is the ID, ARN or alias of the key.
+	KeyID       string
+	Credentials AWSCredentials
+	// Endpoint overrides https://kms.<region>.amazonaws.com, e.g. for a VPC endpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// PublicKey implements KMS.
+func (k *AWSKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	var resp struct {
+		PublicKey []byte
+	}
+	err := k.call(ctx, "GetPublicKey", map[string]interface{}{"KeyId": k.KeyID}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.PublicKey, nil
+}
+
+// Sign implements KMS.
+func (k *AWSKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	var resp struct {
+		Signature []byte
+	}
+	err := k.call(ctx, "Sign", map[string]interface{}{
+		"KeyId":            k.KeyID,
+		"Message":          digest,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call sends a request to the KMS JSON API. Byte slices are encoded in base64
+// by encoding/json as the API expects.
+func (k *AWSKMS) call(ctx context.Context, action string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "encoding KMS request")
+	}
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", k.Region)
+	}
+	r, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating KMS request")
+	}
+	r.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	r.Header.Set("X-Amz-Target", "TrentService."+action)
+	SignAWSRequest(r, body, k.Credentials, k.Region, "kms", time.Now())
+
+	client := k.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "calling KMS %s", action)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading KMS %s response", action)
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("KMS %s failed with status %s: %s", action, res.Status, bytes.TrimSpace(b))
+	}
+	err = json.Unmarshal(b, resp)
+	if err != nil {
+		return errors.Wrapf(err, "decoding KMS %s response", action)
+	}
+	return nil
+}
+
+// SignAWSRequest adds the Signature Version 4 headers to a request to the AWS
+// service in region, signing all of its headers. The headers must be set
+// before it is signed.
+func SignAWSRequest(r *http.Request, body []byte, c AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	r.Header.Set("Host", r.URL.Host)
+	r.Header.Set("X-Amz-Date", amzDate)
+	if c.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+
+	names := make([]string, 0, len(r.Header))
+	for name := range r.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(r.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		path,
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
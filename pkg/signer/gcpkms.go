@@ -0,0 +1,140 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gcpKMSEndpoint   = "https://cloudkms.googleapis.com/v1/"
+	gcpMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCPKMS is a KMS backed by a Google Cloud KMS EC_SIGN_SECP256K1_SHA256 key
+// version, called through the Cloud KMS REST API.
+type GCPKMS struct {
+	// KeyVersion is the resource name of the key version:
+	// projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>/cryptoKeyVersions/<v>
+	KeyVersion string
+	// Token returns the OAuth2 access token of the requests, GCPMetadataToken
+	// when nil.
+	Token func(ctx context.Context) (string, error)
+	// Endpoint overrides https://cloudkms.googleapis.com/v1/.
+	Endpoint string
+	Client   *http.Client
+}
+
+// PublicKey implements KMS.
+func (k *GCPKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	var resp struct {
+		PEM string `json:"pem"`
+	}
+	err := k.call(ctx, http.MethodGet, k.KeyVersion+"/publicKey", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(resp.PEM))
+	if block == nil {
+		return nil, errors.New("decoding KMS public key PEM")
+	}
+	return block.Bytes, nil
+}
+
+// Sign implements KMS.
+func (k *GCPKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	req := map[string]interface{}{
+		"digest": map[string][]byte{"sha256": digest},
+	}
+	var resp struct {
+		Signature []byte `json:"signature"`
+	}
+	err := k.call(ctx, http.MethodPost, k.KeyVersion+":asymmetricSign", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+func (k *GCPKMS) call(ctx context.Context, method, path string, req, resp interface{}) error {
+	var body []byte
+	if req != nil {
+		var err error
+		body, err = json.Marshal(req)
+		if err != nil {
+			return errors.Wrap(err, "encoding KMS request")
+		}
+	}
+	endpoint := k.Endpoint
+	if endpoint == "" {
+		endpoint = gcpKMSEndpoint
+	}
+	r, err := http.NewRequest(method, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating KMS request")
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	token := k.Token
+	if token == nil {
+		token = GCPMetadataToken
+	}
+	t, err := token(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting access token")
+	}
+	r.Header.Set("Authorization", "Bearer "+t)
+
+	client := k.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "calling KMS %s", path)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading KMS response")
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("KMS request failed with status %s: %s", res.Status, bytes.TrimSpace(b))
+	}
+	err = json.Unmarshal(b, resp)
+	if err != nil {
+		return errors.Wrap(err, "decoding KMS response")
+	}
+	return nil
+}
+
+// GCPMetadataToken returns the access token of the default service account of
+// the instance from the GCE metadata server.
+func GCPMetadataToken(ctx context.Context) (string, error) {
+	r, err := http.NewRequest(http.MethodGet, gcpMetadataToken, nil)
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Metadata-Flavor", "Google")
+	res, err := http.DefaultClient.Do(r.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrap(err, "calling the metadata server")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", errors.Errorf("metadata server returned %s", res.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&token)
+	if err != nil {
+		return "", errors.Wrap(err, "decoding access token")
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,93 @@
+package signer
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultDerivationPath is the derivation path of the first account of a
+// hardware wallet, m/44'/60'/0'/0/0.
+var DefaultDerivationPath = accounts.DefaultBaseDerivationPath
+
+// ParseDerivationPath parses a derivation path such as m/44'/60'/0'/0/1, the
+// components followed by ' being hardened. A relative path is derived from
+// m/44'/60'/0'/0, and DefaultDerivationPath is returned for an empty path.
+func ParseDerivationPath(path string) (accounts.DerivationPath, error) {
+	if path == "" {
+		return DefaultDerivationPath, nil
+	}
+	p, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing derivation path %q", path)
+	}
+	return p, nil
+}
+
+// ErrNoHardwareWallet is returned when no hardware wallet of the requested kind is connected.
+var ErrNoHardwareWallet = errors.New("no hardware wallet found")
+
+// ErrHardwareWalletUnsupported is returned by OpenHardwareWallet in the
+// builds without USB support, made without cgo or with the nousb build tag.
+var ErrHardwareWalletUnsupported = errors.New("hardware wallets are not supported by this build")
+
+// HardwareConfig selects a hardware wallet and the account used to sign.
+type HardwareConfig struct {
+	// Kind is either "ledger" or "trezor".
+	Kind string
+	// Path is the derivation path of the account, DefaultDerivationPath when nil.
+	Path accounts.DerivationPath
+	// PIN returns the PIN of a locked Trezor, entered using the layout shown
+	// on the device.
+	PIN func() (string, error)
+	// Passphrase returns the passphrase of a Trezor protected by one.
+	Passphrase func() (string, error)
+	// Confirm is called before each transaction is sent to the device, the
+	// transaction is only signed once confirmed on the device.
+	Confirm func(tx *types.Transaction)
+}
+
+// HardwareWallet signs transactions with a key held by a Ledger or Trezor.
+// The key never leaves the device and every transaction has to be confirmed
+// on it.
+type HardwareWallet struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+	confirm func(tx *types.Transaction)
+}
+
+// Address returns the address of the derived account.
+func (w *HardwareWallet) Address() common.Address {
+	return w.account.Address
+}
+
+// TransactOpts returns options signing EIP-155 transactions for the given
+// chain on the device. Signing blocks until the transaction is confirmed or
+// rejected on the device.
+func (w *HardwareWallet) TransactOpts(chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: w.account.Address,
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != w.account.Address {
+				return nil, ErrNotAuthorized
+			}
+			if w.confirm != nil {
+				w.confirm(tx)
+			}
+			signed, err := w.wallet.SignTx(w.account, tx, chainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "signing on the hardware wallet")
+			}
+			return signed, nil
+		},
+	}
+}
+
+// Close releases the device.
+func (w *HardwareWallet) Close() error {
+	return w.wallet.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !cgo || nousb
+// +build !cgo nousb
+
+package signer
+
+// OpenHardwareWallet fails with ErrHardwareWalletUnsupported: the USB stack
+// of the hardware wallets requires cgo, and is left out of the builds without
+// it or with the nousb build tag.
+func OpenHardwareWallet(cfg HardwareConfig) (*HardwareWallet, error) {
+	return nil, ErrHardwareWalletUnsupported
+}
@@ -0,0 +1,81 @@
+//go:build cgo && !nousb
+// +build cgo,!nousb
+
+package signer
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	"github.com/pkg/errors"
+)
+
+// OpenHardwareWallet opens the first connected hardware wallet of the
+// configured kind and derives the account of the configured path.
+func OpenHardwareWallet(cfg HardwareConfig) (*HardwareWallet, error) {
+	var hub *usbwallet.Hub
+	var err error
+	switch strings.ToLower(cfg.Kind) {
+	case "ledger":
+		hub, err = usbwallet.NewLedgerHub()
+	case "trezor":
+		hub, err = usbwallet.NewTrezorHubWithHID()
+	default:
+		return nil, errors.Errorf("unknown hardware wallet %q, expected ledger or trezor", cfg.Kind)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s hub", cfg.Kind)
+	}
+
+	wallets := hub.Wallets()
+	if len(wallets) == 0 {
+		return nil, errors.Wrap(ErrNoHardwareWallet, cfg.Kind)
+	}
+	wallet := wallets[0]
+
+	err = openWallet(wallet, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	path := cfg.Path
+	if path == nil {
+		path = DefaultDerivationPath
+	}
+	account, err := wallet.Derive(path, true)
+	if err != nil {
+		wallet.Close()
+		return nil, errors.Wrapf(err, "deriving account %s", path)
+	}
+
+	return &HardwareWallet{wallet: wallet, account: account, confirm: cfg.Confirm}, nil
+}
+
+// openWallet opens the wallet, asking for the PIN and passphrase of a Trezor
+// when the device requires them.
+func openWallet(wallet accounts.Wallet, cfg HardwareConfig) error {
+	err := wallet.Open("")
+	if err == usbwallet.ErrTrezorPINNeeded {
+		err = openWith(wallet, cfg.PIN)
+	}
+	if err == usbwallet.ErrTrezorPassphraseNeeded {
+		err = openWith(wallet, cfg.Passphrase)
+	}
+	if err != nil {
+		return errors.Wrap(err, "opening hardware wallet")
+	}
+	return nil
+}
+
+// openWith opens the wallet with the secret returned by ask.
+func openWith(wallet accounts.Wallet, ask func() (string, error)) error {
+	if ask == nil {
+		return errors.New("the device is locked")
+	}
+	secret, err := ask()
+	if err != nil {
+		return err
+	}
+	return wallet.Open(secret)
+}
@@ -0,0 +1,157 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// KMS is a remote key management service holding a secp256k1 key. The private
+// key never leaves the service, it only signs digests.
+type KMS interface {
+	// PublicKey returns the DER encoded SubjectPublicKeyInfo of the key.
+	PublicKey(ctx context.Context) ([]byte, error)
+	// Sign returns the DER encoded ECDSA signature of a 32 byte digest.
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// secp256k1OID identifies the secp256k1 curve in a SubjectPublicKeyInfo.
+var secp256k1OID = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// KMSSigner signs EIP-155 transactions with the key held by a KMS, calling
+// the KMS with the context of each transaction.
+type KMSSigner struct {
+	kms    KMS
+	pub    *ecdsa.PublicKey
+	from   common.Address
+	signer types.Signer
+}
+
+// NewKMSSigner returns a signer of the transactions of the given chain with
+// the key held by kms. The public key is fetched once with ctx to derive the
+// address of the account.
+func NewKMSSigner(ctx context.Context, kms KMS, chainID *big.Int) (*KMSSigner, error) {
+	der, err := kms.PublicKey(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting KMS public key")
+	}
+	pub, err := parsePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	return &KMSSigner{
+		kms:    kms,
+		pub:    pub,
+		from:   crypto.PubkeyToAddress(*pub),
+		signer: types.NewEIP155Signer(chainID),
+	}, nil
+}
+
+// Address returns the address of the account of the key.
+func (s *KMSSigner) Address() common.Address {
+	return s.from
+}
+
+// SignerFn returns the function signing the transactions with ctx, so that
+// its cancellation and deadline reach the KMS.
+func (s *KMSSigner) SignerFn(ctx context.Context) bind.SignerFn {
+	return func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != s.from {
+			return nil, ErrNotAuthorized
+		}
+		hash := s.signer.Hash(tx).Bytes()
+		der, err := s.kms.Sign(ctx, hash)
+		if err != nil {
+			return nil, errors.Wrap(err, "signing with KMS")
+		}
+		sig, err := recoverableSignature(der, hash, s.pub)
+		if err != nil {
+			return nil, err
+		}
+		return tx.WithSignature(s.signer, sig)
+	}
+}
+
+// TransactOpts returns the options of a transaction sent and signed with
+// ctx.
+func (s *KMSSigner) TransactOpts(ctx context.Context) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    s.from,
+		Signer:  s.SignerFn(ctx),
+		Context: ctx,
+	}
+}
+
+// NewKMSTransactOpts returns options signing EIP-155 transactions for the
+// given chain with the key held by kms, sent and signed with ctx. The options
+// of the transactions sent with another context are returned by the
+// TransactOpts of a KMSSigner.
+func NewKMSTransactOpts(ctx context.Context, kms KMS, chainID *big.Int) (*bind.TransactOpts, error) {
+	s, err := NewKMSSigner(ctx, kms, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return s.TransactOpts(ctx), nil
+}
+
+// parsePublicKey decodes a DER encoded secp256k1 SubjectPublicKeyInfo.
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	_, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding KMS public key")
+	}
+	if !spki.Algorithm.Parameters.Equal(secp256k1OID) {
+		return nil, errors.Errorf("KMS key is on curve %s, not secp256k1", spki.Algorithm.Parameters)
+	}
+	pub, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding KMS public key")
+	}
+	return pub, nil
+}
+
+// recoverableSignature converts a DER encoded ECDSA signature to the 65 byte
+// [R || S || V] form, with S in the lower half of the curve order as required
+// since Homestead and V found by recovering pub.
+func recoverableSignature(der, hash []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	var rs struct {
+		R, S *big.Int
+	}
+	_, err := asn1.Unmarshal(der, &rs)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding KMS signature")
+	}
+	if rs.S.Cmp(secp256k1HalfN) > 0 {
+		rs.S = new(big.Int).Sub(crypto.S256().Params().N, rs.S)
+	}
+
+	sig := make([]byte, 65)
+	copy(sig[32-len(rs.R.Bytes()):32], rs.R.Bytes())
+	copy(sig[64-len(rs.S.Bytes()):64], rs.S.Bytes())
+	want := crypto.FromECDSAPub(pub)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := crypto.Ecrecover(hash, sig)
+		if err == nil && bytes.Equal(recovered, want) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("KMS signature does not match the public key")
+}
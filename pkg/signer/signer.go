@@ -0,0 +1,53 @@
+// Package signer provides the accounts used to sign transactions: decrypted
+// keystore files, hardware wallets and keys held by a remote KMS.
+package signer
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrNotAuthorized is returned when asked to sign for an address other than the signer's.
+var ErrNotAuthorized = errors.New("not authorized to sign this account")
+
+// NewTransactOpts returns options signing EIP-155 transactions for the given chain with key.
+//
+// Unlike bind.NewKeyedTransactor the signature is replay protected.
+func NewTransactOpts(key *ecdsa.PrivateKey, chainID *big.Int) *bind.TransactOpts {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.NewEIP155Signer(chainID)
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, ErrNotAuthorized
+			}
+			sig, err := crypto.Sign(signer.Hash(tx).Bytes(), key)
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, sig)
+		},
+	}
+}
+
+// DecryptKeyFile reads and decrypts a keystore (V3) file.
+func DecryptKeyFile(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading keystore file")
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting keystore file %s", path)
+	}
+	return key.PrivateKey, nil
+}
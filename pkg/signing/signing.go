@@ -0,0 +1,90 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSignature is returned when a signature was not made by the expected signer.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignHashFunc signs a 32 byte hash, returning the [R || S || V] signature
+// with V either 0 or 1, as keystore.SignHash and crypto.Sign do.
+type SignHashFunc func(hash []byte) ([]byte, error)
+
+// KeySigner returns a SignHashFunc signing with a private key.
+func KeySigner(key *ecdsa.PrivateKey) SignHashFunc {
+	return func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, key)
+	}
+}
+
+// Sign signs the hash of the typed data. The signature is returned in the
+// [R || S || V] form with V either 27 or 28, as expected by ecrecover.
+func (t *TypedData) Sign(signHash SignHashFunc) ([]byte, error) {
+	hash, err := t.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return SignHash(hash, signHash)
+}
+
+// Recover returns the address which signed the typed data.
+func (t *TypedData) Recover(sig []byte) (common.Address, error) {
+	hash, err := t.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return RecoverHash(hash, sig)
+}
+
+// Verify checks that the typed data was signed by signer.
+func (t *TypedData) Verify(sig []byte, signer common.Address) error {
+	recovered, err := t.Recover(sig)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return errors.Wrapf(ErrInvalidSignature, "signed by %s, not %s", recovered.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// SignHash signs a hash with signHash, returning the signature with V either 27 or 28.
+func SignHash(hash common.Hash, signHash SignHashFunc) ([]byte, error) {
+	sig, err := signHash(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, errors.Errorf("signature is %d bytes long, expected 65", len(sig))
+	}
+	sig = append([]byte(nil), sig...)
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+// RecoverHash returns the address which signed a hash. V may be either 0 or
+// 1, or 27 or 28.
+func RecoverHash(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, errors.Wrapf(ErrInvalidSignature, "signature is %d bytes long, expected 65", len(sig))
+	}
+	rsv := append([]byte(nil), sig...)
+	if rsv[64] >= 27 {
+		rsv[64] -= 27
+	}
+	if rsv[64] > 1 {
+		return common.Address{}, errors.Wrapf(ErrInvalidSignature, "unsupported recovery id %d", sig[64])
+	}
+	pub, err := crypto.SigToPub(hash[:], rsv)
+	if err != nil {
+		return common.Address{}, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
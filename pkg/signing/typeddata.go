@@ -0,0 +1,375 @@
+// Package signing hashes and signs EIP-712 typed data, the structured
+// messages signed off-chain and verified either in Go or by contracts with
+// ecrecover, e.g. the authorization of a claim:
+//
+//	data := signing.TypedData{
+//		Types: signing.Types{
+//			"Claim": {{Name: "account", Type: "address"}, {Name: "amount", Type: "uint256"}, {Name: "nonce", Type: "uint256"}},
+//		},
+//		PrimaryType: "Claim",
+//		Domain:      signing.Domain{Name: "Monolith", Version: "1", ChainID: big.NewInt(1), VerifyingContract: &contract},
+//		Message:     map[string]interface{}{"account": account, "amount": amount, "nonce": nonce},
+//	}
+//	sig, err := data.Sign(signHash)
+//	signer, err := data.Recover(sig)
+//
+// The EIP712Domain type is derived from the fields set in the Domain and must
+// not be declared in Types.
+package signing
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Field is a member of a struct type.
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types are the struct types of a message, by name.
+type Types map[string][]Field
+
+// Domain separates the messages of different applications, chains and
+// contracts. Only the fields set are part of the domain.
+type Domain struct {
+	Name              string          `json:"name,omitempty"`
+	Version           string          `json:"version,omitempty"`
+	ChainID           *big.Int        `json:"chainId,omitempty"`
+	VerifyingContract *common.Address `json:"verifyingContract,omitempty"`
+	Salt              *common.Hash    `json:"salt,omitempty"`
+}
+
+// fields returns the EIP712Domain type of the domain and its values.
+func (d Domain) fields() ([]Field, map[string]interface{}) {
+	var fields []Field
+	values := make(map[string]interface{})
+	if d.Name != "" {
+		fields = append(fields, Field{"name", "string"})
+		values["name"] = d.Name
+	}
+	if d.Version != "" {
+		fields = append(fields, Field{"version", "string"})
+		values["version"] = d.Version
+	}
+	if d.ChainID != nil {
+		fields = append(fields, Field{"chainId", "uint256"})
+		values["chainId"] = d.ChainID
+	}
+	if d.VerifyingContract != nil {
+		fields = append(fields, Field{"verifyingContract", "address"})
+		values["verifyingContract"] = *d.VerifyingContract
+	}
+	if d.Salt != nil {
+		fields = append(fields, Field{"salt", "bytes32"})
+		values["salt"] = *d.Salt
+	}
+	return fields, values
+}
+
+// TypedData is a message of the PrimaryType struct type.
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      Domain                 `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+const domainType = "EIP712Domain"
+
+// DomainSeparator returns the hash of the domain.
+func (t *TypedData) DomainSeparator() (common.Hash, error) {
+	fields, values := t.Domain.fields()
+	types := Types{domainType: fields}
+	return types.HashStruct(domainType, values)
+}
+
+// Hash returns the hash of the message signed by Sign:
+// keccak256("\x19\x01" || domainSeparator || hashStruct(message)).
+func (t *TypedData) Hash() (common.Hash, error) {
+	if _, ok := t.Types[domainType]; ok {
+		return common.Hash{}, errors.New("the EIP712Domain type is derived from the domain and must not be declared")
+	}
+	domain, err := t.DomainSeparator()
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "hashing domain")
+	}
+	message, err := t.Types.HashStruct(t.PrimaryType, t.Message)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "hashing message")
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], message[:]), nil
+}
+
+// TypeHash returns the hash of the encoded type of a struct.
+func (t Types) TypeHash(name string) (common.Hash, error) {
+	encoded, err := t.EncodeType(name)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte(encoded)), nil
+}
+
+// EncodeType returns the encoding of a struct type followed by the struct
+// types it references, in alphabetical order, e.g.
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (t Types) EncodeType(name string) (string, error) {
+	deps := make(map[string]bool)
+	err := t.dependencies(name, deps)
+	if err != nil {
+		return "", err
+	}
+	delete(deps, name)
+	names := make([]string, 0, len(deps))
+	for n := range deps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range append([]string{name}, names...) {
+		b.WriteString(n + "(")
+		for i, f := range t[n] {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(f.Type + " " + f.Name)
+		}
+		b.WriteString(")")
+	}
+	return b.String(), nil
+}
+
+func (t Types) dependencies(name string, deps map[string]bool) error {
+	if deps[name] {
+		return nil
+	}
+	fields, ok := t[name]
+	if !ok {
+		return errors.Errorf("unknown type %q", name)
+	}
+	deps[name] = true
+	for _, f := range fields {
+		base := f.Type
+		for arraySuffix.MatchString(base) {
+			base = arraySuffix.ReplaceAllString(base, "")
+		}
+		if _, ok := t[base]; ok {
+			err := t.dependencies(base, deps)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HashStruct returns the hash of a struct value:
+// keccak256(typeHash || encodeData(value)).
+func (t Types) HashStruct(name string, value map[string]interface{}) (common.Hash, error) {
+	encoded, err := t.encodeData(name, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+func (t Types) encodeData(name string, value map[string]interface{}) ([]byte, error) {
+	fields, ok := t[name]
+	if !ok {
+		return nil, errors.Errorf("unknown type %q", name)
+	}
+	if len(value) > len(fields) {
+		return nil, errors.Errorf("%s has fields which are not declared in its type", name)
+	}
+	typeHash, err := t.TypeHash(name)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(typeHash[:])
+	for _, f := range fields {
+		v, ok := value[f.Name]
+		if !ok {
+			return nil, errors.Errorf("%s.%s is not set", name, f.Name)
+		}
+		word, err := t.encodeValue(f.Type, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s.%s", name, f.Name)
+		}
+		buf.Write(word)
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	arraySuffix = regexp.MustCompile(`\[\d*\]$`)
+	intType     = regexp.MustCompile(`^(u?)int(\d*)$`)
+	bytesType   = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+// encodeValue returns the 32 byte encoding of a value of the given type.
+func (t Types) encodeValue(typ string, v interface{}) ([]byte, error) {
+	if loc := arraySuffix.FindStringIndex(typ); loc != nil {
+		elem := typ[:loc[0]]
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, errors.Errorf("%T is not an array", v)
+		}
+		if n := typ[loc[0]+1 : loc[1]-1]; n != "" && strconv.Itoa(rv.Len()) != n {
+			return nil, errors.Errorf("array has %d elements, expected %s", rv.Len(), n)
+		}
+		var buf bytes.Buffer
+		for i := 0; i < rv.Len(); i++ {
+			word, err := t.encodeValue(elem, rv.Index(i).Interface())
+			if err != nil {
+				return nil, errors.Wrapf(err, "element %d", i)
+			}
+			buf.Write(word)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+
+	if _, ok := t[typ]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%T is not a %s struct", v, typ)
+		}
+		h, err := t.HashStruct(typ, m)
+		if err != nil {
+			return nil, err
+		}
+		return h[:], nil
+	}
+
+	switch typ {
+	case "address":
+		a, err := toAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		return a.Hash().Bytes(), nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.Errorf("%T is not a bool", v)
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("%T is not a string", v)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	}
+
+	if m := bytesType.FindStringSubmatch(typ); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		b, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if size < 1 || size > 32 || len(b) != size {
+			return nil, errors.Errorf("%d bytes do not fit %s", len(b), typ)
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+	if m := intType.FindStringSubmatch(typ); m != nil {
+		bits := 256
+		if m[2] != "" {
+			bits, _ = strconv.Atoi(m[2])
+		}
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, errors.Errorf("invalid type %s", typ)
+		}
+		i, err := toBig(v)
+		if err != nil {
+			return nil, err
+		}
+		if m[1] == "u" {
+			if i.Sign() < 0 || i.BitLen() > bits {
+				return nil, errors.Errorf("%s overflows %s", i, typ)
+			}
+		} else {
+			limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+			if i.Cmp(limit) >= 0 || i.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return nil, errors.Errorf("%s overflows %s", i, typ)
+			}
+		}
+		return math.PaddedBigBytes(math.U256(new(big.Int).Set(i)), 32), nil
+	}
+	return nil, errors.Errorf("unknown type %q", typ)
+}
+
+func toAddress(v interface{}) (common.Address, error) {
+	switch a := v.(type) {
+	case common.Address:
+		return a, nil
+	case *common.Address:
+		return *a, nil
+	case string:
+		if !common.IsHexAddress(a) {
+			return common.Address{}, errors.Errorf("%q is not an address", a)
+		}
+		return common.HexToAddress(a), nil
+	}
+	return common.Address{}, errors.Errorf("%T is not an address", v)
+}
+
+func toBytes(v interface{}) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case common.Hash:
+		return b[:], nil
+	case string:
+		return common.FromHex(b), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return b, nil
+	}
+	return nil, errors.Errorf("%T is not a byte array", v)
+}
+
+func toBig(v interface{}) (*big.Int, error) {
+	switch i := v.(type) {
+	case *big.Int:
+		return i, nil
+	case string:
+		n, ok := math.ParseBig256(i)
+		if !ok {
+			return nil, errors.Errorf("%q is not an integer", i)
+		}
+		return n, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, errors.Errorf("%T is not an integer", v)
+}
@@ -0,0 +1,18 @@
+package slo
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the Status of the trackers as a JSON array.
+func Handler(trackers ...*Tracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := make([]Status, len(trackers))
+		for i, t := range trackers {
+			statuses[i] = t.Status()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statuses)
+	})
+}
@@ -0,0 +1,46 @@
+package slo
+
+import (
+	"context"
+	"time"
+
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Probe samples a service level indicator at a fixed interval, counting each
+// sample as a good or a bad event of its tracker.
+type Probe struct {
+	Tracker  *Tracker
+	Interval time.Duration
+	// Check reports whether the service currently meets the objective. A
+	// failing check counts as a bad event.
+	Check  func(ctx context.Context) (bool, error)
+	Logger logging.Logger
+}
+
+// Run samples the indicator every Interval until the context is cancelled.
+func (p *Probe) Run(ctx context.Context) error {
+	t := time.NewTicker(p.Interval)
+	defer t.Stop()
+	for {
+		p.Sample(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Sample samples the indicator once.
+func (p *Probe) Sample(ctx context.Context) {
+	good, err := p.Check(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		logging.Or(p.Logger).Warn("Probe failed", "objective", p.Tracker.Objective().Name, "err", err)
+		good = false
+	}
+	p.Tracker.Record(good)
+}
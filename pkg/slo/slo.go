@@ -0,0 +1,246 @@
+// Package slo tracks service level objectives: the fraction of good events a
+// service must achieve over a compliance window, e.g. "the indexer lags less
+// than 3 blocks 99% of the time over 30 days".
+//
+// A Tracker counts the good and bad events in one minute buckets and reports
+// the compliance, the remaining error budget and the burn rate, the speed at
+// which the error budget is consumed: a burn rate of 1 exhausts the budget
+// exactly at the end of the window.
+//
+// Register publishes them in a metrics registry, where the alert rules can
+// page on the multiwindow burn rate conditions:
+//
+//	slo/<name>/compliance               fraction of good events over the window
+//	slo/<name>/error_budget_remaining   fraction of the error budget left
+//	slo/<name>/burn_rate_<w>            burn rate over the last 5m, 30m, 1h and 6h
+//	slo/<name>/fast_burn                1 when the 1h and 5m burn rates exceed 14.4
+//	slo/<name>/slow_burn                1 when the 6h and 30m burn rates exceed 6
+//
+// e.g. the alert rule paging when the indexer burns its budget too fast:
+//
+//	rules:
+//	  - name: indexer-lag-fast-burn
+//	    severity: critical
+//	    metric: slo/indexer_lag/fast_burn
+//	    op: ">="
+//	    threshold: 1
+//
+// Latency objectives, e.g. "99% of the claims are processed within an hour",
+// are tracked with RecordLatency.
+package slo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Resolution is the duration of the buckets events are counted in.
+const Resolution = time.Minute
+
+// BurnRateWindows are the windows the burn rate is published for.
+var BurnRateWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+// Thresholds of the multiwindow burn rate alerts. A fast burn consumes 2% of
+// a 30 day budget in an hour, a slow burn 5% in 6 hours.
+const (
+	FastBurnRate = 14.4
+	SlowBurnRate = 6
+)
+
+// Objective is a service level objective.
+type Objective struct {
+	Name string
+	// Target is the fraction of good events to achieve, e.g. 0.99.
+	Target float64
+	// Window is the compliance window, e.g. 30 days.
+	Window time.Duration
+}
+
+// Status is the state of an objective.
+type Status struct {
+	Objective            string             `json:"objective"`
+	Target               float64            `json:"target"`
+	Window               string             `json:"window"`
+	Good                 uint64             `json:"good"`
+	Total                uint64             `json:"total"`
+	Compliance           float64            `json:"compliance"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"`
+	BurnRates            map[string]float64 `json:"burn_rates"`
+	FastBurn             bool               `json:"fast_burn"`
+	SlowBurn             bool               `json:"slow_burn"`
+}
+
+// Tracker counts the events of an objective.
+type Tracker struct {
+	objective Objective
+
+	mu      sync.Mutex
+	buckets []bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	index       int64
+	good, total uint64
+}
+
+// NewTracker creates a tracker of the objective.
+func NewTracker(o Objective) *Tracker {
+	n := int(o.Window / Resolution)
+	if n < 1 {
+		n = 1
+	}
+	return &Tracker{
+		objective: o,
+		buckets:   make([]bucket, n),
+		now:       time.Now,
+	}
+}
+
+// Objective returns the tracked objective.
+func (t *Tracker) Objective() Objective {
+	return t.objective
+}
+
+// Record counts an event happening now.
+func (t *Tracker) Record(good bool) {
+	t.RecordAt(t.now(), good)
+}
+
+// RecordAt counts an event happening at the given time. Events older than the
+// window are ignored.
+func (t *Tracker) RecordAt(at time.Time, good bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	index := at.UnixNano() / int64(Resolution)
+	if index <= t.now().UnixNano()/int64(Resolution)-int64(len(t.buckets)) {
+		return
+	}
+	b := &t.buckets[int(index%int64(len(t.buckets)))]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+}
+
+// RecordLatency counts an event which is good when it took at most threshold.
+func (t *Tracker) RecordLatency(latency, threshold time.Duration) {
+	t.Record(latency <= threshold)
+}
+
+// counts returns the good and total events of the last window.
+func (t *Tracker) counts(window time.Duration) (good, total uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UnixNano() / int64(Resolution)
+	n := int64(window / Resolution)
+	if n < 1 {
+		n = 1
+	}
+	if n > int64(len(t.buckets)) {
+		n = int64(len(t.buckets))
+	}
+	for _, b := range t.buckets {
+		if b.index > now-n && b.index <= now {
+			good += b.good
+			total += b.total
+		}
+	}
+	return good, total
+}
+
+// Compliance returns the fraction of good events over the last window, 1
+// when there were no events.
+func (t *Tracker) Compliance(window time.Duration) float64 {
+	good, total := t.counts(window)
+	if total == 0 {
+		return 1
+	}
+	return float64(good) / float64(total)
+}
+
+// BurnRate returns the rate the error budget was consumed at over the last window.
+func (t *Tracker) BurnRate(window time.Duration) float64 {
+	budget := 1 - t.objective.Target
+	if budget <= 0 {
+		return 0
+	}
+	return (1 - t.Compliance(window)) / budget
+}
+
+// ErrorBudgetRemaining returns the fraction of the error budget of the
+// compliance window left, negative once the objective is missed.
+func (t *Tracker) ErrorBudgetRemaining() float64 {
+	return 1 - t.BurnRate(t.objective.Window)
+}
+
+// FastBurn reports whether both the 1h and 5m burn rates exceed FastBurnRate.
+func (t *Tracker) FastBurn() bool {
+	return t.BurnRate(time.Hour) > FastBurnRate && t.BurnRate(5*time.Minute) > FastBurnRate
+}
+
+// SlowBurn reports whether both the 6h and 30m burn rates exceed SlowBurnRate.
+func (t *Tracker) SlowBurn() bool {
+	return t.BurnRate(6*time.Hour) > SlowBurnRate && t.BurnRate(30*time.Minute) > SlowBurnRate
+}
+
+// Status returns the current state of the objective.
+func (t *Tracker) Status() Status {
+	good, total := t.counts(t.objective.Window)
+	s := Status{
+		Objective:            t.objective.Name,
+		Target:               t.objective.Target,
+		Window:               t.objective.Window.String(),
+		Good:                 good,
+		Total:                total,
+		Compliance:           t.Compliance(t.objective.Window),
+		ErrorBudgetRemaining: t.ErrorBudgetRemaining(),
+		BurnRates:            make(map[string]float64, len(BurnRateWindows)),
+		FastBurn:             t.FastBurn(),
+		SlowBurn:             t.SlowBurn(),
+	}
+	for _, w := range BurnRateWindows {
+		s.BurnRates[windowName(w)] = t.BurnRate(w)
+	}
+	return s
+}
+
+// Register publishes the state of the objective in the registry as functional
+// gauges, computed when the metrics are read.
+func (t *Tracker) Register(r metrics.Registry) {
+	prefix := "slo/" + t.objective.Name + "/"
+	metrics.NewRegisteredFunctionalGaugeFloat64(prefix+"compliance", r, func() float64 {
+		return t.Compliance(t.objective.Window)
+	})
+	metrics.NewRegisteredFunctionalGaugeFloat64(prefix+"error_budget_remaining", r, t.ErrorBudgetRemaining)
+	for _, w := range BurnRateWindows {
+		w := w
+		metrics.NewRegisteredFunctionalGaugeFloat64(prefix+"burn_rate_"+windowName(w), r, func() float64 {
+			return t.BurnRate(w)
+		})
+	}
+	metrics.NewRegisteredFunctionalGauge(prefix+"fast_burn", r, func() int64 { return boolGauge(t.FastBurn()) })
+	metrics.NewRegisteredFunctionalGauge(prefix+"slow_burn", r, func() int64 { return boolGauge(t.SlowBurn()) })
+}
+
+func windowName(w time.Duration) string {
+	if w%time.Hour == 0 {
+		return fmt.Sprintf("%dh", w/time.Hour)
+	}
+	return fmt.Sprintf("%dm", w/time.Minute)
+}
+
+func boolGauge(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
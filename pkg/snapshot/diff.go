@@ -0,0 +1,207 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Diff is the change of the state of the contracts between two snapshots,
+// e.g. those of the first blocks of two months for the accounting.
+type Diff struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	// Deployed are the wallets deployed or migrated between the snapshots.
+	Deployed []Wallet `json:"deployed"`
+	// Activated are the wallets of both snapshots whose whitelist was set
+	// between them. The wallets deployed and activated are only in Deployed.
+	Activated []Wallet `json:"activated"`
+	// OwnerChanges are the wallets of both snapshots whose owner changed.
+	OwnerChanges []OwnerChange `json:"owner_changes"`
+	// Removed are the wallets of the first snapshot missing from the second
+	// one, which only happens when the snapshots are of different chains or
+	// were taken with different start blocks.
+	Removed []Wallet `json:"removed,omitempty"`
+	// Parameters are the changes of the parameters of the Licence and the
+	// WalletCache.
+	Parameters    []ParameterChange `json:"parameters"`
+	TokensAdded   []Token           `json:"tokens_added"`
+	TokensRemoved []Token           `json:"tokens_removed"`
+}
+
+// OwnerChange is a change of the owner of a wallet.
+type OwnerChange struct {
+	Wallet common.Address `json:"wallet"`
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+}
+
+// ParameterChange is a change of a parameter of a contract.
+type ParameterChange struct {
+	Contract string `json:"contract"`
+	Name     string `json:"name"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// Empty tells whether nothing changed between the snapshots.
+func (d *Diff) Empty() bool {
+	return len(d.Deployed) == 0 && len(d.Activated) == 0 && len(d.OwnerChanges) == 0 && len(d.Removed) == 0 &&
+		len(d.Parameters) == 0 && len(d.TokensAdded) == 0 && len(d.TokensRemoved) == 0
+}
+
+// Compare returns the changes from the snapshot from to the snapshot to.
+func Compare(from, to *Snapshot) (*Diff, error) {
+	if from.Block > to.Block {
+		return nil, errors.Errorf("snapshot of block %d is after snapshot of block %d", from.Block, to.Block)
+	}
+	d := &Diff{
+		FromBlock:     from.Block,
+		ToBlock:       to.Block,
+		Deployed:      []Wallet{},
+		Activated:     []Wallet{},
+		OwnerChanges:  []OwnerChange{},
+		Parameters:    []ParameterChange{},
+		TokensAdded:   []Token{},
+		TokensRemoved: []Token{},
+	}
+
+	before := make(map[common.Address]Wallet, len(from.Wallets))
+	for _, w := range from.Wallets {
+		before[w.Address] = w
+	}
+	for _, w := range to.Wallets {
+		old, ok := before[w.Address]
+		if !ok {
+			d.Deployed = append(d.Deployed, w)
+			continue
+		}
+		delete(before, w.Address)
+		if w.Owner != old.Owner {
+			d.OwnerChanges = append(d.OwnerChanges, OwnerChange{Wallet: w.Address, From: old.Owner, To: w.Owner})
+		}
+		if w.WhitelistSet && !old.WhitelistSet {
+			d.Activated = append(d.Activated, w)
+		}
+		if w.SpendLimit != old.SpendLimit {
+			d.Parameters = append(d.Parameters, ParameterChange{Contract: w.Address.Hex(), Name: "spend_limit", From: old.SpendLimit, To: w.SpendLimit})
+		}
+	}
+	for _, w := range from.Wallets {
+		if _, ok := before[w.Address]; ok {
+			d.Removed = append(d.Removed, w)
+		}
+	}
+
+	if from.Licence != nil && to.Licence != nil {
+		a, b := from.Licence, to.Licence
+		d.parameters("licence", []string{"amount_scaled", a.AmountScaled, b.AmountScaled})
+		d.parameters("licence", addresses("dao", a.DAO, b.DAO), bools("dao_locked", a.DAOLocked, b.DAOLocked))
+		d.parameters("licence", addresses("crypto_float", a.CryptoFloat, b.CryptoFloat), bools("crypto_float_locked", a.CryptoFloatLocked, b.CryptoFloatLocked))
+		d.parameters("licence", addresses("token_holder", a.TokenHolder, b.TokenHolder), bools("token_holder_locked", a.TokenHolderLocked, b.TokenHolderLocked))
+		d.parameters("licence", addresses("tkn", a.TKN, b.TKN), bools("tkn_locked", a.TKNLocked, b.TKNLocked))
+	}
+	if from.WalletCache != nil && to.WalletCache != nil {
+		d.parameters("wallet_cache", []string{"default_spend_limit", from.WalletCache.DefaultSpendLimit, to.WalletCache.DefaultSpendLimit})
+	}
+
+	tokens := make(map[common.Address]bool, len(from.Tokens))
+	for _, t := range from.Tokens {
+		tokens[t.Address] = true
+	}
+	for _, t := range to.Tokens {
+		if !tokens[t.Address] {
+			d.TokensAdded = append(d.TokensAdded, t)
+		}
+		delete(tokens, t.Address)
+	}
+	for _, t := range from.Tokens {
+		if tokens[t.Address] {
+			d.TokensRemoved = append(d.TokensRemoved, t)
+		}
+	}
+	return d, nil
+}
+
+// parameters records the changes of the name, from, to triples.
+func (d *Diff) parameters(contract string, changes ...[]string) {
+	for _, c := range changes {
+		if c[1] != c[2] {
+			d.Parameters = append(d.Parameters, ParameterChange{Contract: contract, Name: c[0], From: c[1], To: c[2]})
+		}
+	}
+}
+
+func addresses(name string, from, to common.Address) []string {
+	return []string{name, from.Hex(), to.Hex()}
+}
+
+func bools(name string, from, to bool) []string {
+	return []string{name, strconv.FormatBool(from), strconv.FormatBool(to)}
+}
+
+// ReadJSON reads a snapshot written by WriteJSON.
+func ReadJSON(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	err := json.NewDecoder(r).Decode(&s)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading snapshot")
+	}
+	return &s, nil
+}
+
+// ReadJSONFile reads the snapshot of a file written by WriteJSON.
+func ReadJSONFile(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening snapshot")
+	}
+	defer f.Close()
+	return ReadJSON(f)
+}
+
+// WriteDiffJSON writes the diff as an indented JSON document.
+func WriteDiffJSON(w io.Writer, d *Diff) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(d), "writing diff")
+}
+
+// WriteDiffCSV writes the diff as CSV, one row per change, for the
+// spreadsheets of the accounting.
+func WriteDiffCSV(w io.Writer, d *Diff) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"change", "subject", "name", "from", "to"})
+	for _, wallet := range d.Deployed {
+		var migrated string
+		if wallet.Migrated != nil {
+			migrated = wallet.Migrated.Hex()
+		}
+		cw.Write([]string{"deployed", wallet.Address.Hex(), "owner", migrated, wallet.Owner.Hex()})
+	}
+	for _, wallet := range d.Activated {
+		cw.Write([]string{"activated", wallet.Address.Hex(), "whitelist_set", "false", "true"})
+	}
+	for _, c := range d.OwnerChanges {
+		cw.Write([]string{"owner_changed", c.Wallet.Hex(), "owner", c.From.Hex(), c.To.Hex()})
+	}
+	for _, wallet := range d.Removed {
+		cw.Write([]string{"removed", wallet.Address.Hex(), "owner", wallet.Owner.Hex(), ""})
+	}
+	for _, p := range d.Parameters {
+		cw.Write([]string{"parameter", p.Contract, p.Name, p.From, p.To})
+	}
+	for _, t := range d.TokensAdded {
+		cw.Write([]string{"token_added", t.Address.Hex(), "symbol", "", t.Symbol})
+	}
+	for _, t := range d.TokensRemoved {
+		cw.Write([]string{"token_removed", t.Address.Hex(), "symbol", t.Symbol, ""})
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "writing diff")
+}
@@ -0,0 +1,394 @@
+// Package snapshot dumps the state of the contracts at a block, for the
+// audits and for the migrations to new versions of the contracts: the
+// parameters of the Licence, the tokens of the TokenWhitelist, the wallets
+// left in the WalletCache and every wallet deployed by the WalletDeployer,
+// with the owner it was deployed for and its owner at the block.
+//
+// The wallets are found from the events of the WalletDeployer, the state is
+// read at the block from the contracts themselves, which requires an archive
+// node for past blocks:
+//
+//	s, err := snapshot.Take(ctx, backend, contracts, snapshot.Options{StartBlock: deployment, Block: 9000000})
+//	...
+//	err = snapshot.WriteJSON(os.Stdout, s)
+//
+// Compare reports the changes between two snapshots, the wallets deployed,
+// activated and changing owner and the parameters changed, e.g. for the
+// accounting of a month.
+package snapshot
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// Backend is the part of the node API used by Take.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Contracts are the addresses of the contracts of the snapshot, those left
+// as the zero address are left out.
+type Contracts struct {
+	Licence        common.Address
+	TokenWhitelist common.Address
+	WalletCache    common.Address
+	WalletDeployer common.Address
+}
+
+// Options are the options of Take.
+type Options struct {
+	// StartBlock is the first block searched for the wallets deployed,
+	// usually the deployment block of the WalletDeployer.
+	StartBlock uint64
+	// Block is the block of the snapshot, the latest one when zero.
+	Block uint64
+}
+
+// Snapshot is the state of the contracts at a block.
+type Snapshot struct {
+	Block     uint64      `json:"block"`
+	BlockHash common.Hash `json:"block_hash"`
+	Time      time.Time   `json:"time"`
+
+	Licence     *Licence     `json:"licence,omitempty"`
+	Tokens      []Token      `json:"tokens,omitempty"`
+	WalletCache *WalletCache `json:"wallet_cache,omitempty"`
+	// Wallets are the wallets deployed by the WalletDeployer, in the order
+	// they were deployed.
+	Wallets []Wallet `json:"wallets"`
+	Totals  Totals   `json:"totals"`
+}
+
+// Licence are the parameters of the Licence.
+type Licence struct {
+	Address common.Address `json:"address"`
+	// AmountScaled is the licence fee in thousandths of the loaded amount.
+	AmountScaled      string         `json:"amount_scaled"`
+	DAO               common.Address `json:"dao"`
+	DAOLocked         bool           `json:"dao_locked"`
+	CryptoFloat       common.Address `json:"crypto_float"`
+	CryptoFloatLocked bool           `json:"crypto_float_locked"`
+	TokenHolder       common.Address `json:"token_holder"`
+	TokenHolderLocked bool           `json:"token_holder_locked"`
+	TKN               common.Address `json:"tkn"`
+	TKNLocked         bool           `json:"tkn_locked"`
+}
+
+// Token is a token of the TokenWhitelist.
+type Token struct {
+	Address    common.Address `json:"address"`
+	Symbol     string         `json:"symbol"`
+	Magnitude  string         `json:"magnitude"`
+	Rate       string         `json:"rate"`
+	Loadable   bool           `json:"loadable"`
+	Redeemable bool           `json:"redeemable"`
+}
+
+// WalletCache is the state of the WalletCache.
+type WalletCache struct {
+	Address           common.Address `json:"address"`
+	DefaultSpendLimit string         `json:"default_spend_limit"`
+	// Cached are the wallets cached and not assigned yet.
+	Cached []common.Address `json:"cached"`
+}
+
+// Wallet is a wallet deployed by the WalletDeployer.
+type Wallet struct {
+	Address common.Address `json:"address"`
+	// Owner is the owner of the wallet at the block.
+	Owner common.Address `json:"owner"`
+	// FirstOwner is the owner the wallet was deployed for.
+	FirstOwner  common.Address `json:"first_owner"`
+	DeployBlock uint64         `json:"deploy_block"`
+	// Migrated is the wallet the wallet was migrated from, if any.
+	Migrated *common.Address `json:"migrated,omitempty"`
+	// Transferable tells whether the owner can still transfer the wallet.
+	Transferable bool `json:"transferable"`
+	// WhitelistSet tells whether the owner has set the whitelist of the
+	// wallet, activating it.
+	WhitelistSet bool   `json:"whitelist_set"`
+	SpendLimit   string `json:"spend_limit"`
+}
+
+// Totals are the counts of the snapshot.
+type Totals struct {
+	Deployed  int `json:"deployed"`
+	Migrated  int `json:"migrated"`
+	Activated int `json:"activated"`
+	Cached    int `json:"cached"`
+	Tokens    int `json:"tokens"`
+}
+
+// Take reads the state of the contracts at the block of the options.
+func Take(ctx context.Context, backend Backend, contracts Contracts, opts Options) (*Snapshot, error) {
+	var number *big.Int
+	if opts.Block != 0 {
+		number = new(big.Int).SetUint64(opts.Block)
+	}
+	header, err := backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the block of the snapshot")
+	}
+	s := &Snapshot{
+		Block:     header.Number.Uint64(),
+		BlockHash: header.Hash(),
+		Time:      time.Unix(int64(header.Time), 0).UTC(),
+		Wallets:   []Wallet{},
+	}
+	if s.Block < opts.StartBlock {
+		return nil, errors.Errorf("block %d is before start block %d", s.Block, opts.StartBlock)
+	}
+	call := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+
+	if contracts.Licence != (common.Address{}) {
+		s.Licence, err = licence(call, backend, contracts.Licence)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if contracts.TokenWhitelist != (common.Address{}) {
+		s.Tokens, err = tokens(call, backend, contracts.TokenWhitelist)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if contracts.WalletCache != (common.Address{}) {
+		s.WalletCache, err = walletCache(call, backend, contracts.WalletCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if contracts.WalletDeployer != (common.Address{}) {
+		s.Wallets, err = wallets(call, backend, contracts.WalletDeployer, opts.StartBlock)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.Totals.Deployed = len(s.Wallets)
+	s.Totals.Tokens = len(s.Tokens)
+	if s.WalletCache != nil {
+		s.Totals.Cached = len(s.WalletCache.Cached)
+	}
+	for _, w := range s.Wallets {
+		if w.Migrated != nil {
+			s.Totals.Migrated++
+		}
+		if w.WhitelistSet {
+			s.Totals.Activated++
+		}
+	}
+	return s, nil
+}
+
+func licence(call *bind.CallOpts, backend Backend, address common.Address) (*Licence, error) {
+	c, err := bindings.NewLicence(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding licence contract")
+	}
+	l := &Licence{Address: address}
+	amount, err := c.LicenceAmountScaled(call)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting licence amount")
+	}
+	l.AmountScaled = amount.String()
+	reads := []struct {
+		name  string
+		read  func(*bind.CallOpts) (common.Address, error)
+		to    *common.Address
+		lock  func(*bind.CallOpts) (bool, error)
+		isSet *bool
+	}{
+		{"licence DAO", c.LicenceDAO, &l.DAO, c.LicenceDAOLocked, &l.DAOLocked},
+		{"crypto float", c.CryptoFloat, &l.CryptoFloat, c.FloatLocked, &l.CryptoFloatLocked},
+		{"token holder", c.TokenHolder, &l.TokenHolder, c.HolderLocked, &l.TokenHolderLocked},
+		{"TKN contract address", c.TknContractAddress, &l.TKN, c.TknContractAddressLocked, &l.TKNLocked},
+	}
+	for _, r := range reads {
+		*r.to, err = r.read(call)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting %s", r.name)
+		}
+		*r.isSet, err = r.lock(call)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting whether the %s is locked", r.name)
+		}
+	}
+	return l, nil
+}
+
+func tokens(call *bind.CallOpts, backend Backend, address common.Address) ([]Token, error) {
+	c, err := bindings.NewTokenWhitelist(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding token whitelist contract")
+	}
+	addresses, err := c.TokenAddressArray(call)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting whitelisted tokens")
+	}
+	tokens := make([]Token, 0, len(addresses))
+	for _, a := range addresses {
+		symbol, magnitude, rate, available, loadable, redeemable, _, err := c.GetTokenInfo(call, a)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting token info of %s", a.Hex())
+		}
+		if !available {
+			continue
+		}
+		tokens = append(tokens, Token{
+			Address:    a,
+			Symbol:     symbol,
+			Magnitude:  magnitude.String(),
+			Rate:       rate.String(),
+			Loadable:   loadable,
+			Redeemable: redeemable,
+		})
+	}
+	return tokens, nil
+}
+
+func walletCache(call *bind.CallOpts, backend Backend, address common.Address) (*WalletCache, error) {
+	c, err := bindings.NewWalletCache(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet cache contract")
+	}
+	limit, err := c.DefaultSpendLimit(call)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting default spend limit")
+	}
+	count, err := c.CachedWalletsCount(call)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting cached wallets count")
+	}
+	w := &WalletCache{Address: address, DefaultSpendLimit: limit.String(), Cached: []common.Address{}}
+	for i := int64(0); i < count.Int64(); i++ {
+		a, err := c.CachedWallets(call, big.NewInt(i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting cached wallet %d", i)
+		}
+		w.Cached = append(w.Cached, a)
+	}
+	return w, nil
+}
+
+// wallets returns the wallets deployed or migrated by the WalletDeployer up
+// to the block of call, with their state at that block.
+func wallets(call *bind.CallOpts, backend Backend, address common.Address, start uint64) ([]Wallet, error) {
+	d, err := bindings.NewWalletDeployer(address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding wallet deployer contract")
+	}
+	end := call.BlockNumber.Uint64()
+	filter := &bind.FilterOpts{Start: start, End: &end, Context: call.Context}
+
+	type deployment struct {
+		wallet Wallet
+		index  uint
+	}
+	var deployed []deployment
+	it, err := d.FilterDeployedWallet(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering deployed wallets")
+	}
+	for it.Next() {
+		w := Wallet{Address: it.Event.Wallet, FirstOwner: it.Event.Owner, DeployBlock: it.Event.Raw.BlockNumber}
+		deployed = append(deployed, deployment{wallet: w, index: it.Event.Raw.Index})
+	}
+	err = it.Error()
+	it.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering deployed wallets")
+	}
+	mit, err := d.FilterMigratedWallet(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering migrated wallets")
+	}
+	for mit.Next() {
+		old := mit.Event.OldWallet
+		w := Wallet{Address: mit.Event.Wallet, FirstOwner: mit.Event.Owner, DeployBlock: mit.Event.Raw.BlockNumber, Migrated: &old}
+		deployed = append(deployed, deployment{wallet: w, index: mit.Event.Raw.Index})
+	}
+	err = mit.Error()
+	mit.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "filtering migrated wallets")
+	}
+	sort.SliceStable(deployed, func(a, b int) bool {
+		if deployed[a].wallet.DeployBlock != deployed[b].wallet.DeployBlock {
+			return deployed[a].wallet.DeployBlock < deployed[b].wallet.DeployBlock
+		}
+		return deployed[a].index < deployed[b].index
+	})
+
+	wallets := make([]Wallet, len(deployed))
+	for i, dep := range deployed {
+		w := dep.wallet
+		c, err := bindings.NewWallet(w.Address, backend)
+		if err != nil {
+			return nil, errors.Wrap(err, "binding wallet contract")
+		}
+		w.Owner, err = c.Owner(call)
+		if err == nil {
+			w.Transferable, err = c.IsTransferable(call)
+		}
+		if err == nil {
+			w.WhitelistSet, err = c.IsSetWhitelist(call)
+		}
+		var limit *big.Int
+		if err == nil {
+			limit, err = c.SpendLimitValue(call)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading wallet %s", w.Address.Hex())
+		}
+		w.SpendLimit = limit.String()
+		wallets[i] = w
+	}
+	return wallets, nil
+}
+
+// WriteJSON writes the snapshot as an indented JSON document.
+func WriteJSON(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(s), "writing snapshot")
+}
+
+// WriteCSV writes the wallets of the snapshot as CSV, one row per wallet.
+// The values of the other contracts are only in the JSON document.
+func WriteCSV(w io.Writer, s *Snapshot) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"wallet", "owner", "first_owner", "deploy_block", "migrated", "transferable", "whitelist_set", "spend_limit"})
+	for _, wallet := range s.Wallets {
+		var migrated string
+		if wallet.Migrated != nil {
+			migrated = wallet.Migrated.Hex()
+		}
+		cw.Write([]string{
+			wallet.Address.Hex(),
+			wallet.Owner.Hex(),
+			wallet.FirstOwner.Hex(),
+			strconv.FormatUint(wallet.DeployBlock, 10),
+			migrated,
+			strconv.FormatBool(wallet.Transferable),
+			strconv.FormatBool(wallet.WhitelistSet),
+			wallet.SpendLimit,
+		})
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "writing snapshot")
+}
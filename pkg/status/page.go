@@ -0,0 +1,129 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// The names of the files of the page.
+const (
+	JSONFile = "status.json"
+	HTMLFile = "index.html"
+)
+
+var pageTemplate = template.Must(template.New(HTMLFile).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Status</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; }
+td, th { text-align: left; padding: 0.2em 1em 0.2em 0; }
+.ok { color: #080; } .failed { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Status: {{if .Healthy}}<span class="ok">operational</span>{{else}}<span class="failed">degraded</span>{{end}}</h1>
+<p>Generated at {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}, see also <a href="status.json">status.json</a>.</p>
+<h2>Chain</h2>
+<table>
+{{with .Chain}}{{if .Connected}}<tr><th>Connected</th><td class="ok">yes</td></tr>
+<tr><th>Chain ID</th><td>{{.ChainID}}</td></tr>
+<tr><th>Head block</th><td>{{.HeadBlock}} at {{.HeadTime.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+{{else}}<tr><th>Connected</th><td class="failed">no: {{.Error}}</td></tr>
+{{end}}{{end}}</table>
+{{with .Indexer}}<h2>Indexer</h2>
+<table>
+<tr><th>Indexed block</th><td>{{.Head}}</td></tr>
+<tr><th>Lag</th><td>{{.Lag}} blocks</td></tr>
+{{if .Error}}<tr><th>Error</th><td class="failed">{{.Error}}</td></tr>
+{{end}}</table>
+<h2>Last payout to the token holder</h2>
+{{with $.LastPayout}}<table>
+<tr><th>Block</th><td>{{.BlockNumber}}</td></tr>
+<tr><th>Transaction</th><td>{{.TxHash.Hex}}{{with .TransferLogIndex}}, Transfer log {{.}}{{end}}</td></tr>
+<tr><th>Amount</th><td>{{.Amount}} of {{.Asset}}</td></tr>
+</table>
+{{else}}<p>None yet.</p>
+{{end}}{{end}}{{with .Licence}}<h2>Licence</h2>
+<table>
+<tr><th>Address</th><td>{{.Address.Hex}}</td></tr>
+{{if .Error}}<tr><th>Error</th><td class="failed">{{.Error}}</td></tr>
+{{else}}<tr><th>Fee</th><td>{{.FeePercent}}%</td></tr>
+<tr><th>DAO</th><td>{{.DAO.Hex}}</td></tr>
+<tr><th>Crypto float</th><td>{{.CryptoFloat.Hex}}</td></tr>
+<tr><th>Token holder</th><td>{{.TokenHolder.Hex}}</td></tr>
+<tr><th>TKN</th><td>{{.TKN.Hex}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML renders the status as an HTML page.
+func WriteHTML(w io.Writer, s *Status) error {
+	return errors.Wrap(pageTemplate.Execute(w, s), "rendering status page")
+}
+
+// Page generates the status page and publishes it.
+type Page struct {
+	generator  *Generator
+	publishers []Publisher
+
+	Logger logging.Logger
+}
+
+// NewPage returns the page of the status of g, published by publishers.
+func NewPage(g *Generator, publishers ...Publisher) *Page {
+	return &Page{generator: g, publishers: publishers}
+}
+
+// Publish generates the status and publishes its files with each publisher.
+// All the publishers are tried, the error is the first failure.
+func (p *Page) Publish(ctx context.Context) (*Status, error) {
+	s := p.generator.Generate(ctx)
+	doc, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding status")
+	}
+	var html bytes.Buffer
+	err = WriteHTML(&html, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var first error
+	for _, pub := range p.publishers {
+		err := pub.Publish(ctx, JSONFile, "application/json", doc)
+		if err == nil {
+			err = pub.Publish(ctx, HTMLFile, "text/html; charset=utf-8", html.Bytes())
+		}
+		if err != nil {
+			logging.Or(p.Logger).Warn("Publishing the status page failed", "publisher", pub.String(), "err", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return s, first
+}
+
+// Run publishes the page every interval until the context is cancelled.
+func (p *Page) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		p.Publish(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+// Publisher publishes the files of the page.
+type Publisher interface {
+	Publish(ctx context.Context, name, contentType string, body []byte) error
+	// String describes where the files are published, without any secret.
+	String() string
+}
+
+// DirPublisher writes the files to a directory, served as is or pushed by
+// another job. The files are replaced atomically.
+type DirPublisher struct {
+	Dir string
+}
+
+// Publish implements Publisher.
+func (d DirPublisher) Publish(ctx context.Context, name, contentType string, body []byte) error {
+	err := os.MkdirAll(d.Dir, 0755)
+	if err != nil {
+		return errors.Wrap(err, "creating status directory")
+	}
+	f, err := ioutil.TempFile(d.Dir, "."+name)
+	if err != nil {
+		return errors.Wrap(err, "creating status file")
+	}
+	_, err = f.Write(body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(d.Dir, name))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return errors.Wrapf(err, "writing %s", name)
+	}
+	return nil
+}
+
+func (d DirPublisher) String() string {
+	return d.Dir
+}
+
+// S3Publisher uploads the files to an S3 bucket, e.g. one hosting a static
+// website, with Signature Version 4 signed requests.
+type S3Publisher struct {
+	Bucket string
+	Region string
+	// Prefix is prepended to the keys of the files, e.g. "status/".
+	Prefix      string
+	Credentials signer.AWSCredentials
+	// Endpoint overrides https://<bucket>.s3.<region>.amazonaws.com, e.g.
+	// for a compatible storage. The bucket is then part of the path.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Publish implements Publisher.
+func (s *S3Publisher) Publish(ctx context.Context, name, contentType string, body []byte) error {
+	u := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s%s", s.Bucket, s.Region, s.Prefix, name)
+	if s.Endpoint != "" {
+		u = fmt.Sprintf("%s/%s/%s%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, s.Prefix, name)
+	}
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating S3 request")
+	}
+	sum := sha256.Sum256(body)
+	req.Header.Set("Content-Type", contentType)
+	// The status must not be cached for longer than it is refreshed.
+	req.Header.Set("Cache-Control", "max-age=60")
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	signer.SignAWSRequest(req, body, s.Credentials, s.Region, "s3", time.Now())
+	return do(ctx, s.Client, req, nil, "uploading "+name+" to S3")
+}
+
+func (s *S3Publisher) String() string {
+	return "s3://" + s.Bucket + "/" + s.Prefix
+}
+
+// GitHubPublisher commits the files to the branch of a repository published
+// by GitHub Pages, through the contents API.
+type GitHubPublisher struct {
+	// Repo is the repository, as owner/name.
+	Repo   string
+	Branch string
+	// Dir is the directory of the files in the repository, the root when
+	// empty.
+	Dir string
+	// Token is a token allowed to write the contents of the repository.
+	Token string
+	// Endpoint overrides https://api.github.com, e.g. for GitHub Enterprise.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Publish implements Publisher.
+func (g *GitHubPublisher) Publish(ctx context.Context, name, contentType string, body []byte) error {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.github.com"
+	}
+	u := fmt.Sprintf("%s/repos/%s/contents/%s", strings.TrimSuffix(endpoint, "/"), g.Repo, path.Join(g.Dir, name))
+
+	// Replacing a file requires the hash of its current content.
+	req, err := g.request(http.MethodGet, u+"?ref="+url.QueryEscape(g.Branch), nil)
+	if err != nil {
+		return err
+	}
+	var current struct {
+		SHA string `json:"sha"`
+	}
+	err = do(ctx, g.Client, req, &current, "getting "+name+" from GitHub")
+	if err != nil && errors.Cause(err) != errNotFound {
+		return err
+	}
+
+	update := map[string]string{
+		"message": "Update " + name,
+		"content": base64.StdEncoding.EncodeToString(body),
+		"branch":  g.Branch,
+	}
+	if current.SHA != "" {
+		update["sha"] = current.SHA
+	}
+	b, err := json.Marshal(update)
+	if err != nil {
+		return errors.Wrap(err, "encoding GitHub request")
+	}
+	req, err = g.request(http.MethodPut, u, b)
+	if err != nil {
+		return err
+	}
+	return do(ctx, g.Client, req, nil, "committing "+name+" to GitHub")
+}
+
+func (g *GitHubPublisher) request(method, u string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating GitHub request")
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "token "+g.Token)
+	return req, nil
+}
+
+func (g *GitHubPublisher) String() string {
+	return "github.com/" + g.Repo + "@" + g.Branch + "/" + g.Dir
+}
+
+var errNotFound = errors.New("not found")
+
+// do sends the request and decodes the JSON response into v when it is not
+// nil. A 404 response fails with errNotFound.
+func do(ctx context.Context, client *http.Client, req *http.Request, v interface{}, action string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, action)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, action)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.Wrap(errNotFound, action)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("%s failed with status %s: %s", action, resp.Status, bytes.TrimSpace(b))
+	}
+	if v == nil {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(b, v), action)
+}
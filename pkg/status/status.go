@@ -0,0 +1,198 @@
+// Package status generates the static status page of the program, for the
+// stakeholders without access to the dashboards: the connectivity of the
+// node, the lag of the indexer, the last payout of the licence fees to the
+// token holder and the parameters of the Licence.
+//
+// The page is a status.json document and an index.html rendering of it,
+// published every interval to a directory, an S3 bucket or the branch of a
+// GitHub Pages site:
+//
+//	p := status.NewPage(status.NewGenerator(client, idx.Store(), licence), &status.S3Publisher{Bucket: "status", Region: "eu-west-1", Credentials: creds})
+//	go p.Run(ctx, time.Minute)
+package status
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// PayoutEvent is the event of the payouts of the licence fees to the token
+// holder.
+const PayoutEvent = indexer.PayoutEvent
+
+// Backend is the part of the node API used by the Generator.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Status is the content of the status page. The failures to read a section
+// are reported in its Error rather than failing the page.
+type Status struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Chain       Chain     `json:"chain"`
+	// Indexer is nil when the program runs without an indexer.
+	Indexer *Indexer `json:"indexer,omitempty"`
+	// LastPayout is nil when there is no indexer or no payout yet.
+	LastPayout *Payout `json:"last_payout,omitempty"`
+	// Licence is nil when no Licence is configured.
+	Licence *Licence `json:"licence,omitempty"`
+}
+
+// Healthy tells whether the node is connected and all the sections were read.
+func (s *Status) Healthy() bool {
+	return s.Chain.Connected && (s.Indexer == nil || s.Indexer.Error == "") && (s.Licence == nil || s.Licence.Error == "")
+}
+
+// Chain is the connectivity of the node.
+type Chain struct {
+	Connected bool      `json:"connected"`
+	ChainID   string    `json:"chain_id,omitempty"`
+	HeadBlock uint64    `json:"head_block,omitempty"`
+	HeadTime  time.Time `json:"head_time,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Indexer is the progress of the indexer.
+type Indexer struct {
+	Head uint64 `json:"head"`
+	// Lag is the number of blocks of the node not indexed yet.
+	Lag   uint64 `json:"lag"`
+	Error string `json:"error,omitempty"`
+}
+
+// Payout is a payout of the licence fees to the token holder.
+type Payout struct {
+	BlockNumber uint64      `json:"block_number"`
+	TxHash      common.Hash `json:"tx_hash"`
+	// Asset is the asset paid out, the zero address is ether.
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+	// TransferLogIndex is the index of the Transfer log of the asset which
+	// paid the payout in its transaction, when the indexer links the
+	// payouts.
+	TransferLogIndex *uint `json:"transfer_log_index,omitempty"`
+}
+
+// Licence are the parameters of the Licence.
+type Licence struct {
+	Address common.Address `json:"address"`
+	// FeePercent is the licence fee in percent of the loaded amounts.
+	FeePercent  string         `json:"fee_percent,omitempty"`
+	DAO         common.Address `json:"dao"`
+	CryptoFloat common.Address `json:"crypto_float"`
+	TokenHolder common.Address `json:"token_holder"`
+	TKN         common.Address `json:"tkn"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Generator reads the status of the program.
+type Generator struct {
+	backend Backend
+	store   indexer.Store
+	licence common.Address
+
+	// Now returns the time of the status, time.Now when nil.
+	Now func() time.Time
+}
+
+// NewGenerator returns a generator reading the status from the node, the
+// events of store and the Licence at licence. The store may be nil when the
+// program runs without an indexer, licence the zero address when no Licence
+// is configured.
+func NewGenerator(backend Backend, store indexer.Store, licence common.Address) *Generator {
+	return &Generator{backend: backend, store: store, licence: licence}
+}
+
+// Generate reads the status.
+func (g *Generator) Generate(ctx context.Context) *Status {
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	s := &Status{GeneratedAt: now().UTC()}
+
+	head, err := g.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		s.Chain.Error = err.Error()
+	} else {
+		s.Chain.HeadBlock = head.Number.Uint64()
+		s.Chain.HeadTime = time.Unix(int64(head.Time), 0).UTC()
+		id, err := g.backend.ChainID(ctx)
+		if err != nil {
+			s.Chain.Error = err.Error()
+		} else {
+			s.Chain.Connected = true
+			s.Chain.ChainID = id.String()
+		}
+	}
+
+	if g.store != nil {
+		s.Indexer, s.LastPayout = g.indexer(s.Chain)
+	}
+	if g.licence != (common.Address{}) {
+		s.Licence = g.licenceParameters(ctx)
+	}
+	return s
+}
+
+func (g *Generator) indexer(chain Chain) (*Indexer, *Payout) {
+	i := &Indexer{}
+	head, ok := g.store.Head()
+	if ok {
+		i.Head = head
+	}
+	if chain.Connected && chain.HeadBlock > i.Head {
+		i.Lag = chain.HeadBlock - i.Head
+	}
+	events, err := g.store.Events(indexer.Query{Contract: "licence", Name: PayoutEvent})
+	if err != nil {
+		i.Error = err.Error()
+		return i, nil
+	}
+	if len(events) == 0 {
+		return i, nil
+	}
+	last := events[len(events)-1]
+	p := &Payout{BlockNumber: last.BlockNumber, TxHash: last.TxHash}
+	p.Asset, _ = indexer.FormatArg(last.Args["_asset"]).(string)
+	p.Amount, _ = indexer.FormatArg(last.Args["_amount"]).(string)
+	if i, ok := indexer.PayoutTransfer(last); ok {
+		p.TransferLogIndex = &i
+	}
+	return i, p
+}
+
+func (g *Generator) licenceParameters(ctx context.Context) *Licence {
+	l := &Licence{Address: g.licence}
+	c, err := bindings.NewLicenceClient(g.licence, g.backend)
+	if err != nil {
+		l.Error = err.Error()
+		return l
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	fee, err := c.Fee(ctx)
+	if err == nil {
+		l.FeePercent = fee.Percent()
+		l.TKN = fee.TKN
+		l.DAO, err = c.LicenceDAO(opts)
+	}
+	if err == nil {
+		l.CryptoFloat, err = c.CryptoFloat(opts)
+	}
+	if err == nil {
+		l.TokenHolder, err = c.TokenHolder(opts)
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	return l
+}
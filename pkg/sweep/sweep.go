@@ -0,0 +1,113 @@
+// Package sweep plans the sweeps of the tokens held by the contracts to cold
+// storage.
+//
+// Only the contracts implementing claim(address _to, address _asset, uint
+// _amount) can be swept, and only by their admins. The balance of each
+// contract above the reserve kept for its projected near-term claims is
+// claimed to the cold storage address. The planned calls are not sent: they
+// are meant to be wrapped in Safe proposals signed offline by the owners, see
+// pkg/safe.
+package sweep
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const tokenABI = `[
+{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_asset","type":"address"},{"name":"_amount","type":"uint256"}],"name":"claim","outputs":[],"type":"function"}
+]`
+
+var parsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(tokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Contract is a contract holding tokens.
+type Contract struct {
+	Name    string
+	Address common.Address
+}
+
+// Sweep is a planned claim of the excess balance of a contract.
+type Sweep struct {
+	Contract string         `json:"contract"`
+	Address  common.Address `json:"address"`
+	Balance  *big.Int       `json:"balance"`
+	Reserve  *big.Int       `json:"reserve"`
+	Amount   *big.Int       `json:"amount"`
+	// Data is the calldata of the claim, to be sent to Address by an admin.
+	Data hexutil.Bytes `json:"data"`
+}
+
+// Planner plans the sweeps of a token to cold storage.
+type Planner struct {
+	Token       common.Address
+	ColdStorage common.Address
+	// Reserves are the projected near-term claims on the contracts, by name,
+	// which are left in the contracts. A contract without a reserve is swept
+	// entirely.
+	Reserves map[string]*big.Int
+	// Minimum is the smallest amount worth a sweep, smaller excesses are left
+	// in the contracts.
+	Minimum *big.Int
+}
+
+// Plan returns the sweeps of the contracts whose balance exceeds their
+// reserve by at least the minimum, in the order of the contracts.
+func (p *Planner) Plan(ctx context.Context, backend bind.ContractCaller, contracts ...Contract) ([]Sweep, error) {
+	if p.ColdStorage == (common.Address{}) {
+		return nil, errors.New("cold storage address is not set")
+	}
+	token := bind.NewBoundContract(p.Token, parsedABI, backend, nil, nil)
+	var sweeps []Sweep
+	for _, c := range contracts {
+		balance := new(big.Int)
+		err := token.Call(&bind.CallOpts{Context: ctx}, &balance, "balanceOf", c.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting token balance of %s", c.Name)
+		}
+		s, ok, err := p.plan(c, balance)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sweeps = append(sweeps, s)
+		}
+	}
+	return sweeps, nil
+}
+
+func (p *Planner) plan(c Contract, balance *big.Int) (Sweep, bool, error) {
+	reserve := new(big.Int)
+	if r, ok := p.Reserves[c.Name]; ok {
+		reserve.Set(r)
+	}
+	amount := new(big.Int).Sub(balance, reserve)
+	if amount.Sign() <= 0 || (p.Minimum != nil && amount.Cmp(p.Minimum) < 0) {
+		return Sweep{}, false, nil
+	}
+	data, err := parsedABI.Pack("claim", p.ColdStorage, p.Token, amount)
+	if err != nil {
+		return Sweep{}, false, errors.Wrapf(err, "packing claim of %s", c.Name)
+	}
+	return Sweep{
+		Contract: c.Name,
+		Address:  c.Address,
+		Balance:  balance,
+		Reserve:  reserve,
+		Amount:   amount,
+		Data:     data,
+	}, true, nil
+}
@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
+)
+
+// method is a contract method known by its selector.
+type method struct {
+	contract string
+	name     string
+}
+
+// methodTable maps the selectors of the methods of a set of contracts.
+type methodTable map[[4]byte]method
+
+func newMethodTable(abis map[string]string) (methodTable, error) {
+	t := make(methodTable)
+	for contract, a := range abis {
+		parsed, err := abi.JSON(strings.NewReader(a))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s ABI", contract)
+		}
+		for _, m := range parsed.Methods {
+			var id [4]byte
+			copy(id[:], m.ID())
+			t[id] = method{contract: contract, name: m.Name}
+		}
+	}
+	return t, nil
+}
+
+// lookup returns the method called with the given transaction or call data.
+func (t methodTable) lookup(data []byte) (method, bool) {
+	if len(data) < 4 {
+		return method{}, false
+	}
+	var id [4]byte
+	copy(id[:], data)
+	m, ok := t[id]
+	return m, ok
+}
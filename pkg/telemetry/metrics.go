@@ -0,0 +1,322 @@
+// Package telemetry instruments the calls made by the contract bindings.
+//
+// Backend wraps a bind.ContractBackend and records in a go-ethereum metrics
+// registry:
+//
+//	rpc/<call>                            latency of the node calls
+//	rpc/<call>/errors                     failed node calls
+//	tx/<contract>/<method>/sent           submitted transactions
+//	tx/<contract>/<method>/estimate_errors failed gas estimations, usually reverts
+//	tx/<contract>/<method>/reverted       mined transactions which reverted
+//	tx/<contract>/<method>/gas_used       gas used by the mined transactions
+//	tx/<contract>/<method>/unobserved     sent transactions whose receipt was not fetched in time
+//	subscriptions/active                  active event subscriptions
+//
+// The outcome of a transaction is recorded when its receipt is fetched through
+// the backend. Programs which do not wait for their transactions run Watch to
+// fetch the receipts in the background.
+//
+// As in go-ethereum, metrics are only recorded when metrics.Enabled is set.
+// Handler serves the registry in the Prometheus text format, with the slashes
+// of the names replaced by underscores.
+//
+// TracingBackend starts a span for each contract call, transaction and log
+// query, tagged with the contract, method, block numbers and transaction hash.
+// Spans are recorded by a Tracer, WriterTracer writes them as JSON lines.
+package telemetry
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+	"github.com/pkg/errors"
+)
+
+// Handler serves the metrics of the registry in the Prometheus text format.
+func Handler(registry metrics.Registry) http.Handler {
+	return prometheus.Handler(registry)
+}
+
+// DefaultPendingTTL is the time a sent transaction waits for its receipt
+// before it is forgotten, when the Backend has no PendingTTL.
+const DefaultPendingTTL = time.Hour
+
+// Backend is a bind.ContractBackend recording metrics of the calls made
+// through it.
+type Backend struct {
+	bind.ContractBackend
+	// PendingTTL is the time a sent transaction waits for its receipt before
+	// it is counted as unobserved and forgotten, DefaultPendingTTL when zero.
+	PendingTTL time.Duration
+
+	registry metrics.Registry
+	methods  methodTable
+
+	mu sync.Mutex
+	// pending holds the sent transactions until their receipt is fetched.
+	pending map[common.Hash]pendingTx
+}
+
+// pendingTx is a sent transaction waiting for its receipt.
+type pendingTx struct {
+	name string
+	sent time.Time
+}
+
+// NewBackend instruments a backend. Transactions are attributed to the
+// methods of the given contract ABIs, indexed by contract name, other
+// transactions are recorded as tx/unknown.
+func NewBackend(backend bind.ContractBackend, registry metrics.Registry, abis map[string]string) (*Backend, error) {
+	methods, err := newMethodTable(abis)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{
+		ContractBackend: backend,
+		registry:        registry,
+		methods:         methods,
+		pending:         make(map[common.Hash]pendingTx),
+	}, nil
+}
+
+func (b *Backend) method(data []byte) string {
+	if m, ok := b.methods.lookup(data); ok {
+		return "tx/" + m.contract + "/" + m.name
+	}
+	return "tx/unknown"
+}
+
+// observe records the latency and the failure of a node call.
+func (b *Backend) observe(call string, start time.Time, err error) {
+	metrics.GetOrRegisterTimer("rpc/"+call, b.registry).UpdateSince(start)
+	if err != nil {
+		metrics.GetOrRegisterCounter("rpc/"+call+"/errors", b.registry).Inc(1)
+	}
+}
+
+// CodeAt implements bind.ContractCaller.
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	code, err := b.ContractBackend.CodeAt(ctx, contract, blockNumber)
+	b.observe("code_at", start, err)
+	return code, err
+}
+
+// CallContract implements bind.ContractCaller.
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	out, err := b.ContractBackend.CallContract(ctx, call, blockNumber)
+	b.observe("call", start, err)
+	return out, err
+}
+
+// PendingCodeAt implements bind.ContractTransactor.
+func (b *Backend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	start := time.Now()
+	code, err := b.ContractBackend.PendingCodeAt(ctx, account)
+	b.observe("pending_code_at", start, err)
+	return code, err
+}
+
+// PendingNonceAt implements bind.ContractTransactor.
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	start := time.Now()
+	nonce, err := b.ContractBackend.PendingNonceAt(ctx, account)
+	b.observe("pending_nonce_at", start, err)
+	return nonce, err
+}
+
+// SuggestGasPrice implements bind.ContractTransactor.
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	price, err := b.ContractBackend.SuggestGasPrice(ctx)
+	b.observe("suggest_gas_price", start, err)
+	return price, err
+}
+
+// EstimateGas implements bind.ContractTransactor.
+func (b *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	start := time.Now()
+	gas, err := b.ContractBackend.EstimateGas(ctx, call)
+	b.observe("estimate_gas", start, err)
+	if err != nil {
+		metrics.GetOrRegisterCounter(b.method(call.Data)+"/estimate_errors", b.registry).Inc(1)
+	}
+	return gas, err
+}
+
+// SendTransaction implements bind.ContractTransactor.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	start := time.Now()
+	err := b.ContractBackend.SendTransaction(ctx, tx)
+	b.observe("send_transaction", start, err)
+	if err != nil {
+		return err
+	}
+
+	name := b.method(tx.Data())
+	metrics.GetOrRegisterCounter(name+"/sent", b.registry).Inc(1)
+	b.mu.Lock()
+	b.expire(start)
+	b.pending[tx.Hash()] = pendingTx{name: name, sent: start}
+	b.mu.Unlock()
+	return nil
+}
+
+// expire forgets the transactions sent more than PendingTTL before now,
+// counting them as unobserved. It must be called with the lock held.
+func (b *Backend) expire(now time.Time) {
+	ttl := b.PendingTTL
+	if ttl <= 0 {
+		ttl = DefaultPendingTTL
+	}
+	for hash, p := range b.pending {
+		if now.Sub(p.sent) > ttl {
+			delete(b.pending, hash)
+			metrics.GetOrRegisterCounter(p.name+"/unobserved", b.registry).Inc(1)
+		}
+	}
+}
+
+// Pending returns the number of sent transactions waiting for their receipt.
+func (b *Backend) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Watch fetches the receipts of the sent transactions from receipts every
+// interval until the context is done, recording their outcome.
+func (b *Backend) Watch(ctx context.Context, receipts bind.DeployBackend, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.poll(ctx, receipts)
+		}
+	}
+}
+
+// poll fetches the receipts of the sent transactions once. The transactions
+// not mined yet stay pending until they expire.
+func (b *Backend) poll(ctx context.Context, receipts bind.DeployBackend) {
+	b.mu.Lock()
+	b.expire(time.Now())
+	hashes := make([]common.Hash, 0, len(b.pending))
+	for hash := range b.pending {
+		hashes = append(hashes, hash)
+	}
+	b.mu.Unlock()
+
+	for _, hash := range hashes {
+		r, err := receipts.TransactionReceipt(ctx, hash)
+		if err != nil || r == nil {
+			continue
+		}
+		b.Observe(r)
+	}
+}
+
+// TransactionReceipt implements bind.DeployBackend when the wrapped backend
+// does, recording the outcome of the transactions sent through the backend.
+func (b *Backend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	db, ok := b.ContractBackend.(bind.DeployBackend)
+	if !ok {
+		return nil, errors.New("backend does not provide transaction receipts")
+	}
+	start := time.Now()
+	r, err := db.TransactionReceipt(ctx, hash)
+	b.observe("transaction_receipt", start, err)
+	if err != nil || r == nil {
+		return r, err
+	}
+	b.Observe(r)
+	return r, nil
+}
+
+// Observe records the outcome of a mined transaction sent through the backend.
+// Receipts of other transactions, or observed before, are ignored.
+func (b *Backend) Observe(r *types.Receipt) {
+	b.mu.Lock()
+	p, ok := b.pending[r.TxHash]
+	delete(b.pending, r.TxHash)
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	name := p.name
+	metrics.GetOrRegisterHistogram(name+"/gas_used", b.registry, metrics.NewExpDecaySample(1028, 0.015)).Update(int64(r.GasUsed))
+	if r.Status == types.ReceiptStatusFailed {
+		metrics.GetOrRegisterCounter(name+"/reverted", b.registry).Inc(1)
+	}
+}
+
+// FilterLogs implements bind.ContractFilterer.
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	start := time.Now()
+	logs, err := b.ContractBackend.FilterLogs(ctx, query)
+	b.observe("filter_logs", start, err)
+	return logs, err
+}
+
+// SubscribeFilterLogs implements bind.ContractFilterer.
+func (b *Backend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	start := time.Now()
+	sub, err := b.ContractBackend.SubscribeFilterLogs(ctx, query, ch)
+	b.observe("subscribe_filter_logs", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	active := metrics.GetOrRegisterGauge("subscriptions/active", b.registry)
+	active.Inc(1)
+	return &subscription{Subscription: sub, active: active}, nil
+}
+
+// subscription decrements the active subscriptions gauge once it ends.
+type subscription struct {
+	ethereum.Subscription
+	active metrics.Gauge
+	once   sync.Once
+
+	init sync.Once
+	errs chan error
+}
+
+// Unsubscribe implements ethereum.Subscription.
+func (s *subscription) Unsubscribe() {
+	s.Subscription.Unsubscribe()
+	s.done()
+}
+
+// Err implements ethereum.Subscription.
+func (s *subscription) Err() <-chan error {
+	s.init.Do(func() {
+		s.errs = make(chan error, 1)
+		go func() {
+			err, ok := <-s.Subscription.Err()
+			s.done()
+			if ok {
+				s.errs <- err
+			}
+			close(s.errs)
+		}()
+	})
+	return s.errs
+}
+
+func (s *subscription) done() {
+	s.once.Do(func() { s.active.Dec(1) })
+}
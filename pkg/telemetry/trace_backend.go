@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TracingBackend is a bind.ContractBackend starting a span for each contract
+// call, gas estimation, transaction and log query. The spans are children of
+// the span carried by the context of the bind options.
+type TracingBackend struct {
+	bind.ContractBackend
+	tracer  Tracer
+	methods methodTable
+}
+
+// NewTracingBackend wraps a backend. Calls are attributed to the methods of
+// the given contract ABIs, indexed by contract name.
+func NewTracingBackend(backend bind.ContractBackend, tracer Tracer, abis map[string]string) (*TracingBackend, error) {
+	methods, err := newMethodTable(abis)
+	if err != nil {
+		return nil, err
+	}
+	return &TracingBackend{
+		ContractBackend: backend,
+		tracer:          tracer,
+		methods:         methods,
+	}, nil
+}
+
+func (b *TracingBackend) start(ctx context.Context, name string, to *common.Address, data []byte) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var attrs []Attribute
+	if to != nil {
+		attrs = append(attrs, Attr("eth.to", to.Hex()))
+	}
+	if m, ok := b.methods.lookup(data); ok {
+		attrs = append(attrs, Attr("eth.contract", m.contract), Attr("eth.method", m.name))
+	}
+	return b.tracer.Start(ctx, name, attrs...)
+}
+
+func end(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// CallContract implements bind.ContractCaller.
+func (b *TracingBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctx, span := b.start(ctx, "eth.call", call.To, call.Data)
+	if blockNumber != nil {
+		span.SetAttributes(Attr("eth.block_number", blockNumber.String()))
+	}
+	out, err := b.ContractBackend.CallContract(ctx, call, blockNumber)
+	end(span, err)
+	return out, err
+}
+
+// EstimateGas implements bind.ContractTransactor.
+func (b *TracingBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	ctx, span := b.start(ctx, "eth.estimateGas", call.To, call.Data)
+	gas, err := b.ContractBackend.EstimateGas(ctx, call)
+	if err == nil {
+		span.SetAttributes(Attr("eth.gas", gas))
+	}
+	end(span, err)
+	return gas, err
+}
+
+// SendTransaction implements bind.ContractTransactor.
+func (b *TracingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, span := b.start(ctx, "eth.sendTransaction", tx.To(), tx.Data())
+	span.SetAttributes(Attr("eth.tx_hash", tx.Hash().Hex()), Attr("eth.nonce", tx.Nonce()))
+	err := b.ContractBackend.SendTransaction(ctx, tx)
+	end(span, err)
+	return err
+}
+
+// FilterLogs implements bind.ContractFilterer.
+func (b *TracingBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, span := b.start(ctx, "eth.getLogs", nil, nil)
+	if query.FromBlock != nil {
+		span.SetAttributes(Attr("eth.from_block", query.FromBlock.String()))
+	}
+	if query.ToBlock != nil {
+		span.SetAttributes(Attr("eth.to_block", query.ToBlock.String()))
+	}
+	logs, err := b.ContractBackend.FilterLogs(ctx, query)
+	if err == nil {
+		span.SetAttributes(Attr("eth.logs", len(logs)))
+	}
+	end(span, err)
+	return logs, err
+}
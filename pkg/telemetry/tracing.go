@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+)
+
+// Attribute is a key value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates an attribute.
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation of a trace.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans. The interface follows the shape of the OpenTelemetry
+// API so that a tracer of the SDK can be adapted in a few lines. The returned
+// context carries the span, spans started from it are its children.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Extractor is implemented by the tracers continuing the traces of the
+// requests they receive.
+type Extractor interface {
+	// Extract returns a context carrying the remote span of the headers.
+	Extract(ctx context.Context, h http.Header) context.Context
+}
+
+// NoopTracer is a Tracer which does not record anything.
+type NoopTracer struct{}
+
+// Start implements Tracer.
+func (NoopTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// TraceHandler starts a span for each request served by h, continuing the
+// trace of the request when the tracer is an Extractor.
+func TraceHandler(tracer Tracer, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if e, ok := tracer.(Extractor); ok {
+			ctx = e.Extract(ctx, r.Header)
+		}
+		ctx, span := tracer.Start(ctx, "http "+r.Method,
+			Attr("http.method", r.Method),
+			Attr("http.target", r.URL.Path),
+		)
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r.WithContext(ctx))
+		span.SetAttributes(Attr("http.status_code", sw.status))
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
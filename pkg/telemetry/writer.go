@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+// SpanContext identifies a span within a trace.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+}
+
+// Traceparent returns the W3C traceparent header value of the span.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%x-%x-01", sc.TraceID, sc.SpanID)
+}
+
+type spanContextKey struct{}
+
+// SpanContextFromContext returns the span context carried by ctx, if any.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// WriterTracer writes the ended spans to a writer as JSON lines, to be shipped
+// by a log collector. It continues the traces of W3C traceparent headers.
+type WriterTracer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterTracer creates a tracer writing spans to w.
+func NewWriterTracer(w io.Writer) *WriterTracer {
+	return &WriterTracer{w: w}
+}
+
+// Start implements Tracer.
+func (t *WriterTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &writerSpan{
+		tracer:     t,
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		s.context.TraceID = parent.TraceID
+		s.parent = parent.SpanID[:]
+	} else {
+		rand.Read(s.context.TraceID[:])
+	}
+	rand.Read(s.context.SpanID[:])
+	s.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanContextKey{}, s.context), s
+}
+
+// Extract implements Extractor.
+func (t *WriterTracer) Extract(ctx context.Context, h http.Header) context.Context {
+	sc, ok := parseTraceparent(h.Get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// parseTraceparent parses a version 00 traceparent header.
+func parseTraceparent(v string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(v, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return sc, false
+	}
+	_, err := hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	if err != nil || sc.TraceID == [16]byte{} {
+		return sc, false
+	}
+	_, err = hex.Decode(sc.SpanID[:], []byte(parts[2]))
+	if err != nil || sc.SpanID == [8]byte{} {
+		return sc, false
+	}
+	return sc, true
+}
+
+// SpanRecord is the JSON representation of an ended span.
+type SpanRecord struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	Duration   time.Duration          `json:"duration_ns"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type writerSpan struct {
+	tracer  *WriterTracer
+	context SpanContext
+	parent  []byte
+	name    string
+	start   time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *writerSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range attrs {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *writerSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *writerSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	r := SpanRecord{
+		TraceID:    hex.EncodeToString(s.context.TraceID[:]),
+		SpanID:     hex.EncodeToString(s.context.SpanID[:]),
+		ParentID:   hex.EncodeToString(s.parent),
+		Name:       s.name,
+		Start:      s.start,
+		Duration:   time.Since(s.start),
+		Attributes: s.attributes,
+	}
+	if s.err != nil {
+		r.Error = s.err.Error()
+	}
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	json.NewEncoder(s.tracer.w).Encode(r)
+}
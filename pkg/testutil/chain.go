@@ -0,0 +1,114 @@
+package testutil
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend is a test backend mining the pending transactions on Commit, such
+// as backends.SimulatedBackend or the backends of ethertest.
+type Backend interface {
+	bind.ContractBackend
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	Commit()
+	Close() error
+}
+
+// headerBackend is implemented by the backends returning the headers of
+// their blocks.
+type headerBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Mining tells when a Chain mines the pending transactions.
+type Mining int
+
+const (
+	// MineManually leaves the mining to Commit and Mined.
+	MineManually Mining = iota
+	// MineOnSend mines each transaction when it is sent, as a block cannot
+	// hold the deployments of several wallets.
+	MineOnSend
+	// MineOnReceipt mines the pending transactions when a receipt is
+	// requested.
+	MineOnReceipt
+)
+
+// NewChain returns a chain over the backend, at block 0 until a transaction
+// is mined through it. No contract is deployed, see New.
+func NewChain(backend Backend, mining Mining) *Chain {
+	return &Chain{Backend: backend, Mining: mining, head: big.NewInt(0)}
+}
+
+// HeaderByNumber returns the header of the block, the head reported being
+// the block of the last transaction mined through the chain, moved by
+// Advance. The headers of the backends without HeaderByNumber only hold
+// their number.
+func (c *Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = c.Head()
+	}
+	if b, ok := c.Backend.(headerBackend); ok {
+		h, err := b.HeaderByNumber(ctx, number)
+		if err == nil && h != nil {
+			return h, nil
+		}
+	}
+	return &types.Header{Number: new(big.Int).Set(number)}, nil
+}
+
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := c.Backend.SendTransaction(ctx, tx)
+	if err != nil || c.Mining != MineOnSend {
+		return err
+	}
+	c.Commit()
+	r, err := c.Backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return err
+	}
+	c.MoveHead(r.BlockNumber)
+	return nil
+}
+
+func (c *Chain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if c.Mining == MineOnReceipt {
+		c.Commit()
+	}
+	r, err := c.Backend.TransactionReceipt(ctx, hash)
+	if err == nil && r != nil {
+		c.MoveHead(r.BlockNumber)
+	}
+	return r, err
+}
+
+// Head returns the block reported as the head.
+func (c *Chain) Head() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.head)
+}
+
+// Advance moves the head by the given number of blocks without mining them,
+// back when it is negative, e.g. to bury a transaction under confirmations or
+// to lag behind the blocks indexed.
+func (c *Chain) Advance(blocks int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = new(big.Int).Add(c.head, big.NewInt(blocks))
+}
+
+// MoveHead moves the head to the block, never back, e.g. to the block of a
+// transaction mined through the backend.
+func (c *Chain) MoveHead(number *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number.Cmp(c.head) > 0 {
+		c.head = new(big.Int).Set(number)
+	}
+}
@@ -0,0 +1,191 @@
+package testutil
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/externals/ens"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/names"
+)
+
+// The ENS names the contracts are registered with, the defaults of the
+// contracts.
+const (
+	ControllerName     = "controller.tokencard.eth"
+	TokenWhitelistName = "token-whitelist.tokencard.eth"
+	OracleName         = "oracle.tokencard.eth"
+	LicenceName        = "licence.tokencard.eth"
+	WalletCacheName    = "wallet-cache.tokencard.eth"
+	WalletDeployerName = "wallet-deployer.tokencard.eth"
+)
+
+// lastUpdate is the update date of the tokens of the whitelist.
+var lastUpdate = big.NewInt(20180913153211)
+
+// mined mines the transaction of a step of the deployment.
+func (c *Chain) mined(step string, tx *types.Transaction, err error) error {
+	if err != nil {
+		return errors.Wrap(err, step)
+	}
+	_, err = c.Mined(tx)
+	return errors.Wrap(err, step)
+}
+
+// deployContracts deploys the contracts of the wallets, registers them with
+// ENS and whitelists TKN and the stablecoin.
+func (c *Chain) deployContracts(spendLimit *big.Int) error {
+	deployer := c.Deployer.Session.TransactOpts
+	node := names.Namehash
+
+	var tx *types.Transaction
+	var err error
+	c.StablecoinAddress, tx, c.Stablecoin, err = mocks.DeployToken(deployer(), c)
+	err = c.mined("deploying stablecoin", tx, err)
+	if err != nil {
+		return err
+	}
+
+	c.ControllerContractAddress, tx, c.ControllerContract, err = bindings.DeployController(deployer(), c, c.Deployer.Address)
+	err = c.mined("deploying controller", tx, err)
+	if err != nil {
+		return err
+	}
+	tx, err = c.ControllerContract.AddAdmin(deployer(), c.Admin.Address)
+	err = c.mined("adding admin", tx, err)
+	if err != nil {
+		return err
+	}
+	tx, err = c.ControllerContract.AddController(c.Admin.Session.TransactOpts(), c.Controller.Address)
+	err = c.mined("adding controller", tx, err)
+	if err != nil {
+		return err
+	}
+
+	c.ENSRegistryAddress, tx, c.ENSRegistry, err = ens.DeployENSRegistry(deployer(), c)
+	err = c.mined("deploying ENS registry", tx, err)
+	if err != nil {
+		return err
+	}
+	c.ENSResolverAddress, tx, c.ENSResolver, err = ens.DeployPublicResolver(deployer(), c, c.ENSRegistryAddress)
+	err = c.mined("deploying ENS resolver", tx, err)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"eth", "tokencard.eth"} {
+		tx, err = c.ENSRegistry.SetSubnodeOwner(deployer(), parent(name), label(name), c.Deployer.Address)
+		err = c.mined("owning ENS node "+name, tx, err)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.register(ControllerName, c.ControllerContractAddress)
+	if err != nil {
+		return err
+	}
+
+	c.TokenWhitelistAddress, tx, c.TokenWhitelist, err = bindings.DeployTokenWhitelist(deployer(), c, c.ENSRegistryAddress, node(OracleName), node(ControllerName), c.StablecoinAddress)
+	err = c.mined("deploying token whitelist", tx, err)
+	if err != nil {
+		return err
+	}
+	err = c.register(TokenWhitelistName, c.TokenWhitelistAddress)
+	if err != nil {
+		return err
+	}
+	tx, err = c.TokenWhitelist.AddTokens(c.Admin.Session.TransactOpts(), []common.Address{c.TKNAddress, c.StablecoinAddress}, [][32]byte{symbol("TKN"), symbol("DAI")}, []*big.Int{big.NewInt(1e8), big.NewInt(1e18)}, []bool{true, true}, []bool{true, true}, lastUpdate)
+	err = c.mined("whitelisting tokens", tx, err)
+	if err != nil {
+		return err
+	}
+
+	c.HolderAddress, tx, c.Holder, err = bindings.DeployHolder(deployer(), c, c.TKNAddress, c.ENSRegistryAddress, node(TokenWhitelistName), node(ControllerName))
+	err = c.mined("deploying holder", tx, err)
+	if err != nil {
+		return err
+	}
+	c.LicenceAddress, tx, c.Licence, err = bindings.DeployLicence(deployer(), c, big.NewInt(10), c.Deployer.Address, c.HolderAddress, common.Address{}, c.ENSRegistryAddress, node(ControllerName))
+	err = c.mined("deploying licence", tx, err)
+	if err != nil {
+		return err
+	}
+	err = c.register(LicenceName, c.LicenceAddress)
+	if err != nil {
+		return err
+	}
+
+	c.WalletCacheAddress, tx, c.WalletCache, err = bindings.DeployWalletCache(deployer(), c, c.ENSRegistryAddress, spendLimit, node(ControllerName), node(LicenceName), node(TokenWhitelistName), node(WalletDeployerName))
+	err = c.mined("deploying wallet cache", tx, err)
+	if err != nil {
+		return err
+	}
+	err = c.register(WalletCacheName, c.WalletCacheAddress)
+	if err != nil {
+		return err
+	}
+	c.WalletDeployerAddress, tx, c.WalletDeployer, err = bindings.DeployWalletDeployer(deployer(), c, c.ENSRegistryAddress, node(ControllerName), node(WalletCacheName))
+	err = c.mined("deploying wallet deployer", tx, err)
+	if err != nil {
+		return err
+	}
+	return c.register(WalletDeployerName, c.WalletDeployerAddress)
+}
+
+// register points the ENS name, a subdomain of tokencard.eth, to address.
+func (c *Chain) register(name string, address common.Address) error {
+	deployer := c.Deployer.Session.TransactOpts
+	node := names.Namehash(name)
+	tx, err := c.ENSRegistry.SetSubnodeOwner(deployer(), parent(name), label(name), c.Deployer.Address)
+	err = c.mined("owning ENS node "+name, tx, err)
+	if err != nil {
+		return err
+	}
+	tx, err = c.ENSRegistry.SetResolver(deployer(), node, c.ENSResolverAddress)
+	err = c.mined("setting resolver of "+name, tx, err)
+	if err != nil {
+		return err
+	}
+	tx, err = c.ENSResolver.SetAddr(deployer(), node, address)
+	return c.mined("registering "+name, tx, err)
+}
+
+// deployWallet deploys the wallet of account with the wallet deployer.
+func (c *Chain) deployWallet(account *Account) error {
+	tx, err := c.WalletDeployer.DeployWallet(c.Controller.Session.TransactOpts(), account.Address)
+	err = c.mined("deploying wallet of "+account.Address.Hex(), tx, err)
+	if err != nil {
+		return err
+	}
+	account.WalletAddress, err = c.WalletDeployer.DeployedWallets(nil, account.Address)
+	if err != nil {
+		return errors.Wrapf(err, "getting wallet of %s", account.Address.Hex())
+	}
+	account.Wallet, err = bindings.NewWallet(account.WalletAddress, c)
+	return errors.Wrapf(err, "binding wallet of %s", account.Address.Hex())
+}
+
+// parent returns the node of the parent domain of name.
+func parent(name string) common.Hash {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) == 1 {
+		return common.Hash{}
+	}
+	return names.Namehash(parts[1])
+}
+
+// label returns the hash of the first label of name.
+func label(name string) common.Hash {
+	return crypto.Keccak256Hash([]byte(strings.SplitN(name, ".", 2)[0]))
+}
+
+// symbol returns the symbol of a token of the whitelist.
+func symbol(s string) [32]byte {
+	var b [32]byte
+	copy(b[:], s)
+	return b
+}
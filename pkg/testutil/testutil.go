@@ -0,0 +1,276 @@
+// Package testutil sets up a simulated chain for the tests of the packages
+// using the bindings, in a single call:
+//
+//	chain, err := testutil.New(testutil.Config{Accounts: 2})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer chain.Close()
+//	alice := chain.Accounts[0]
+//	tx, err := chain.TKN.Transfer(alice.Session.TransactOpts(), chain.Accounts[1].Address, big.NewInt(1))
+//	_, err = chain.Mined(tx)
+//
+// The chain is a backends.SimulatedBackend with funded accounts, a mock TKN
+// token minted to every account and the contracts of the wallets deployed
+// and registered with ENS: Controller, TokenWhitelist, Holder, Licence,
+// WalletCache and WalletDeployer, with a Wallet owned by every account, see
+// Chain.
+//
+// NewChain runs a Chain over another backend, such as the backends of
+// ethertest measuring the coverage of the contracts.
+package testutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/externals/ens"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/session"
+)
+
+// Defaults of the Config fields left zero.
+var (
+	DefaultBalance    = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	DefaultTKN        = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e8))
+	DefaultGasLimit   = uint64(10000000)
+	DefaultSpendLimit = new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+)
+
+// ErrReverted is returned by Mined for the transactions which failed.
+var ErrReverted = errors.New("transaction reverted")
+
+// Config is the initial state of a Chain.
+type Config struct {
+	// Accounts is the number of accounts created besides the deployer, the
+	// admin and the controller.
+	Accounts int
+	// Balance is the ether balance in wei of every account, DefaultBalance
+	// when nil.
+	Balance *big.Int
+	// TKN is the amount of TKN minted to every account, DefaultTKN when nil.
+	TKN *big.Int
+	// GasLimit is the gas limit of the blocks, DefaultGasLimit when zero.
+	GasLimit uint64
+	// SpendLimit is the daily spend limit of the wallets in wei,
+	// DefaultSpendLimit when nil.
+	SpendLimit *big.Int
+	// Keys are the keys of the deployer followed by the accounts, the admin
+	// and the controller, which are generated when missing. Fixed keys make
+	// the chain deterministic, see NumKeys.
+	Keys []*ecdsa.PrivateKey
+}
+
+// NumKeys returns the number of keys of the accounts of the chain.
+func (cfg Config) NumKeys() int {
+	return cfg.Accounts + 3
+}
+
+// Account is a funded account.
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+	// Session holds the options of the account, safe to share between
+	// goroutines.
+	Session *session.Session
+
+	// Wallet is the wallet owned by the account, deployed by New for the
+	// Accounts of the chain only.
+	Wallet        *bindings.Wallet
+	WalletAddress common.Address
+}
+
+// Chain is a test chain reporting the block of the last transaction mined
+// through it as its head, with the contracts deployed by New.
+type Chain struct {
+	Backend
+	Mining Mining
+
+	mu   sync.Mutex
+	head *big.Int
+
+	// Simulated is the backend created by New, nil on the chains of
+	// NewChain.
+	Simulated *backends.SimulatedBackend
+
+	// Deployer is the account the contracts were deployed with, the owner
+	// of the controller.
+	Deployer *Account
+	Accounts []*Account
+	// Admin and Controller hold the admin and controller roles of the
+	// controller.
+	Admin      *Account
+	Controller *Account
+
+	TKN        *mocks.BurnerToken
+	TKNAddress common.Address
+
+	Stablecoin        *mocks.Token
+	StablecoinAddress common.Address
+
+	ENSRegistry        *ens.ENSRegistry
+	ENSRegistryAddress common.Address
+	ENSResolver        *ens.PublicResolver
+	ENSResolverAddress common.Address
+
+	ControllerContract        *bindings.Controller
+	ControllerContractAddress common.Address
+
+	TokenWhitelist        *bindings.TokenWhitelist
+	TokenWhitelistAddress common.Address
+
+	Holder        *bindings.Holder
+	HolderAddress common.Address
+
+	Licence        *bindings.Licence
+	LicenceAddress common.Address
+
+	WalletCache        *bindings.WalletCache
+	WalletCacheAddress common.Address
+
+	WalletDeployer        *bindings.WalletDeployer
+	WalletDeployerAddress common.Address
+}
+
+// New creates a chain with the accounts funded, the mock TKN token deployed
+// and minted to every account, including the deployer, and the contracts of
+// the wallets deployed.
+func New(cfg Config) (*Chain, error) {
+	if cfg.Balance == nil {
+		cfg.Balance = DefaultBalance
+	}
+	if cfg.TKN == nil {
+		cfg.TKN = DefaultTKN
+	}
+	if cfg.GasLimit == 0 {
+		cfg.GasLimit = DefaultGasLimit
+	}
+	if cfg.SpendLimit == nil {
+		cfg.SpendLimit = DefaultSpendLimit
+	}
+
+	accounts := make([]*Account, cfg.NumKeys())
+	alloc := make(core.GenesisAlloc, len(accounts))
+	for i := range accounts {
+		var key *ecdsa.PrivateKey
+		if i < len(cfg.Keys) {
+			key = cfg.Keys[i]
+		}
+		a, err := newAccount(key)
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = a
+		alloc[a.Address] = core.GenesisAccount{Balance: new(big.Int).Set(cfg.Balance)}
+	}
+
+	simulated := backends.NewSimulatedBackend(alloc, cfg.GasLimit)
+	c := NewChain(simulated, MineManually)
+	c.Simulated = simulated
+	c.Deployer = accounts[0]
+	c.Accounts = accounts[1 : cfg.Accounts+1 : cfg.Accounts+1]
+	c.Admin = accounts[cfg.Accounts+1]
+	c.Controller = accounts[cfg.Accounts+2]
+
+	var tx *types.Transaction
+	var err error
+	c.TKNAddress, tx, c.TKN, err = mocks.DeployBurnerToken(c.Deployer.Session.TransactOpts(), c)
+	if err != nil {
+		return nil, errors.Wrap(err, "deploying TKN")
+	}
+	_, err = c.Mined(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "deploying TKN")
+	}
+
+	if cfg.TKN.Sign() > 0 {
+		var txs []*types.Transaction
+		for _, a := range accounts {
+			tx, err := c.TKN.Mint(c.Deployer.Session.TransactOpts(), a.Address, cfg.TKN)
+			if err != nil {
+				return nil, errors.Wrapf(err, "minting TKN to %s", a.Address.Hex())
+			}
+			txs = append(txs, tx)
+		}
+		_, err = c.Mined(txs...)
+		if err != nil {
+			return nil, errors.Wrap(err, "minting TKN")
+		}
+	}
+
+	err = c.deployContracts(cfg.SpendLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range c.Accounts {
+		err = c.deployWallet(a)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func newAccount(key *ecdsa.PrivateKey) (*Account, error) {
+	if key == nil {
+		var err error
+		key, err = crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "generating key")
+		}
+	}
+	return &Account{
+		Key:     key,
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+		Session: session.New(bind.NewKeyedTransactor(key), nil),
+	}, nil
+}
+
+// TKNSession returns a session of the TKN token sending the transactions
+// from account.
+func (c *Chain) TKNSession(account *Account) *mocks.BurnerTokenSession {
+	return &mocks.BurnerTokenSession{
+		Contract:     c.TKN,
+		CallOpts:     *account.Session.CallOpts(),
+		TransactOpts: *account.Session.TransactOpts(),
+	}
+}
+
+// WalletSession returns a session of the wallet of account sending the
+// transactions from it.
+func (c *Chain) WalletSession(account *Account) *bindings.WalletSession {
+	return &bindings.WalletSession{
+		Contract:     account.Wallet,
+		CallOpts:     *account.Session.CallOpts(),
+		TransactOpts: *account.Session.TransactOpts(),
+	}
+}
+
+// Mined commits a block with the pending transactions and returns the
+// receipts of txs, failing with ErrReverted when one of them reverted.
+func (c *Chain) Mined(txs ...*types.Transaction) ([]*types.Receipt, error) {
+	c.Commit()
+	receipts := make([]*types.Receipt, len(txs))
+	for i, tx := range txs {
+		r, err := c.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting receipt of %s", tx.Hash().Hex())
+		}
+		if r.Status != types.ReceiptStatusSuccessful {
+			return receipts, errors.Wrap(ErrReverted, tx.Hash().Hex())
+		}
+		receipts[i] = r
+	}
+	return receipts, nil
+}
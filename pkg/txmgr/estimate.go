@@ -0,0 +1,102 @@
+package txmgr
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// errEstimated stops a binding method once its transaction is built.
+var errEstimated = errors.New("estimated")
+
+// Estimate is the gas a transaction needs, padded by the policy of the method
+// called, and its projected cost at the current gas price.
+type Estimate struct {
+	// Gas is the estimate of the node.
+	Gas uint64 `json:"gas"`
+	// Limit is the estimate padded by Policy, the gas limit the manager
+	// sends the transaction with.
+	Limit  uint64 `json:"limit"`
+	Policy Policy `json:"policy"`
+	// GasPrice is the gas price of the call, or the price suggested by the
+	// backend when the call has none.
+	GasPrice *big.Int `json:"gas_price"`
+	// Cost is the projected cost in wei of the gas limit at the gas price,
+	// the most the transaction can be charged.
+	Cost *big.Int `json:"cost"`
+}
+
+// CostEther returns the projected cost in ether, e.g. "0.0021".
+func (e *Estimate) CostEther() string {
+	s := new(big.Rat).SetFrac(e.Cost, big.NewInt(params.Ether)).FloatString(18)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+// Estimate estimates the gas of a call, padded by the policy of the method
+// called like the gas limits of the transactions sent through the manager.
+func (m *Manager) Estimate(ctx context.Context, call ethereum.CallMsg) (*Estimate, error) {
+	gas, err := m.ContractBackend.EstimateGas(ctx, call)
+	if err != nil {
+		return nil, errors.Wrap(err, "estimating gas")
+	}
+	price := call.GasPrice
+	if price == nil {
+		price, err = m.ContractBackend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting gas price")
+		}
+	}
+	p := m.Policy(call.Data)
+	limit := p.Apply(gas)
+	return &Estimate{
+		Gas:      gas,
+		Limit:    limit,
+		Policy:   p,
+		GasPrice: price,
+		Cost:     new(big.Int).Mul(new(big.Int).SetUint64(limit), price),
+	}, nil
+}
+
+// EstimateMethod estimates the transaction a method of a binding would send
+// with opts, without sending it. The method is called with a copy of opts
+// whose signer stops it once the transaction is built:
+//
+//	e, err := m.EstimateMethod(ctx, opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+//		return licence.UpdateLicenceAmount(opts, amount)
+//	})
+func (m *Manager) EstimateMethod(ctx context.Context, opts *bind.TransactOpts, method func(opts *bind.TransactOpts) (*types.Transaction, error)) (*Estimate, error) {
+	var built *types.Transaction
+	o := *opts
+	o.Context = ctx
+	if o.GasLimit == 0 {
+		// Skips the estimate of the binding, the transaction is estimated
+		// below.
+		o.GasLimit = math.MaxUint64
+	}
+	o.Signer = func(signer types.Signer, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		built = tx
+		return nil, errEstimated
+	}
+	_, err := method(&o)
+	if built == nil {
+		if err == nil {
+			err = errors.New("the method sent no transaction")
+		}
+		return nil, err
+	}
+	return m.Estimate(ctx, ethereum.CallMsg{
+		From:     opts.From,
+		To:       built.To(),
+		GasPrice: opts.GasPrice,
+		Value:    built.Value(),
+		Data:     built.Data(),
+	})
+}
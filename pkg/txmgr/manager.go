@@ -0,0 +1,226 @@
+// Package txmgr contains the transaction manager sitting between the generated
+// bindings and the Ethereum backend.
+//
+// The Manager implements bind.ContractBackend so it can be passed to any
+// New<Contract> constructor. The transactions sent through it are handled
+// according to the Defaults registered for the method being called:
+//
+//   - a zero GasLimit is replaced by the estimate padded by the gas padding policy,
+//   - methods requiring a dry run are simulated before they are sent, the
+//     revert reason of a failing simulation is returned instead of wasting
+//     the gas of a failing transaction,
+//   - sending is retried following the retry policy,
+//   - Wait waits for the number of confirmations required.
+//
+// A RateLimiter can also be set to cap the number of transactions each signer
+// sends per minute, the transactions rejected by a dry run not counting. Estimate and EstimateMethod report the padded gas limit of
+// a transaction and its projected cost without sending it.
+//
+// For example:
+//
+//	m := txmgr.New(client)
+//	err := m.SetPolicy(bindings.HolderABI, "burn", txmgr.Policy{Percentage: 25, Floor: 50000})
+//	holder, err := bindings.NewHolder(address, m)
+package txmgr
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+// Selector is the 4 byte method identifier at the start of the call data.
+type Selector [4]byte
+
+// Manager applies per method Defaults to the transactions sent through it.
+type Manager struct {
+	bind.ContractBackend
+	registry *Registry
+	logger   logging.Logger
+	dryRun   bool
+	limiter  *RateLimiter
+}
+
+// New creates a new transaction manager sending transactions through backend.
+func New(backend bind.ContractBackend) *Manager {
+	return NewWithRegistry(backend, NewRegistry())
+}
+
+// NewWithRegistry creates a new transaction manager using the Defaults held in registry.
+func NewWithRegistry(backend bind.ContractBackend, registry *Registry) *Manager {
+	return &Manager{
+		ContractBackend: backend,
+		registry:        registry,
+		logger:          logging.Nop,
+	}
+}
+
+// SetLogger sets the logger the submissions, confirmations and reverts are
+// reported to. It must be called before the manager is used.
+func (m *Manager) SetLogger(l logging.Logger) {
+	m.logger = logging.Or(l)
+}
+
+// SetDryRun makes the manager simulate every transaction before sending it,
+// whatever the Defaults of the method called. It must be called before the
+// manager is used.
+func (m *Manager) SetDryRun(on bool) {
+	m.dryRun = on
+}
+
+// SetRateLimiter limits the transactions sent by each signer, the
+// transactions over the limit failing with ErrRateLimited. It must be called
+// before the manager is used.
+func (m *Manager) SetRateLimiter(l *RateLimiter) {
+	m.limiter = l
+}
+
+// Registry returns the registry of method Defaults used by the manager.
+func (m *Manager) Registry() *Registry {
+	return m.registry
+}
+
+// MethodSelector returns the selector of the named method of a contract ABI.
+func MethodSelector(contractABI, method string) (Selector, error) {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return Selector{}, errors.Wrap(err, "parsing contract ABI")
+	}
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return Selector{}, errors.Errorf("method %q not found in ABI", method)
+	}
+	var s Selector
+	copy(s[:], m.ID())
+	return s, nil
+}
+
+// SetPolicy sets the gas padding policy of the named method of a contract ABI.
+func (m *Manager) SetPolicy(contractABI, method string, p Policy) error {
+	return m.registry.update(contractABI, method, func(d *Defaults) {
+		d.GasPadding = &p
+	})
+}
+
+// Policy returns the gas padding policy applied to calls with the given data.
+func (m *Manager) Policy(data []byte) Policy {
+	return m.registry.Lookup(data).Padding()
+}
+
+// EstimateGas implements bind.ContractTransactor, returning the padded estimate.
+func (m *Manager) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	estimate, err := m.ContractBackend.EstimateGas(ctx, call)
+	if err != nil {
+		return 0, err
+	}
+	return m.Policy(call.Data).Apply(estimate), nil
+}
+
+// SendTransaction implements bind.ContractTransactor, simulating and retrying
+// the transaction as required by the Defaults of the method called.
+func (m *Manager) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	d := m.registry.Lookup(tx.Data())
+
+	if d.DryRun || m.dryRun {
+		err := m.Simulate(ctx, tx)
+		if err != nil {
+			m.logger.Warn("Transaction rejected by dry run", "hash", tx.Hash(), "to", tx.To(), "err", err)
+			return err
+		}
+	}
+
+	// The transactions rejected by their dry run are not sent, they do not
+	// count against the rate limit.
+	if m.limiter != nil {
+		from, err := sender(tx)
+		if err != nil {
+			return errors.Wrap(err, "rate limit")
+		}
+		if !m.limiter.Allow(from) {
+			m.logger.Error("Transaction rejected by rate limit", "hash", tx.Hash(), "from", from, "to", tx.To(), "nonce", tx.Nonce())
+			return errors.Wrap(ErrRateLimited, from.Hex())
+		}
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = m.ContractBackend.SendTransaction(ctx, tx)
+		if err == nil || isKnownTransaction(err) {
+			m.logger.Info("Submitted transaction", "hash", tx.Hash(), "to", tx.To(), "nonce", tx.Nonce(), "gas", tx.Gas(), "gasprice", tx.GasPrice())
+			return nil
+		}
+		if attempt >= d.Retry.Attempts {
+			m.logger.Error("Sending transaction failed", "hash", tx.Hash(), "attempts", attempt+1, "err", err)
+			return err
+		}
+		m.logger.Warn("Sending transaction failed, retrying", "hash", tx.Hash(), "attempt", attempt+1, "err", err)
+		select {
+		case <-time.After(time.Duration(d.Retry.Backoff)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Simulate runs the transaction against the latest state without sending it.
+// The error of a transaction reverting with a reason is caused by a
+// *RevertError.
+//
+// The transaction is called with its sender and data first, the backends
+// returning the reason of a revert as the output of the call. As they do not
+// report reverted calls as errors, nor reverts without a reason, the gas of
+// the transaction is then estimated, which fails when execution reverts.
+func (m *Manager) Simulate(ctx context.Context, tx *types.Transaction) error {
+	from, err := sender(tx)
+	if err != nil {
+		return errors.Wrap(err, "dry run")
+	}
+	call := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+	if tx.To() != nil {
+		out, err := m.ContractBackend.CallContract(ctx, call, nil)
+		if err != nil {
+			return errors.Wrap(err, "dry run failed")
+		}
+		if reason, ok := RevertReason(out); ok {
+			return errors.Wrap(&RevertError{Reason: reason}, "dry run failed")
+		}
+	}
+	gas, err := m.ContractBackend.EstimateGas(ctx, call)
+	if err != nil {
+		return errors.Wrap(err, "dry run failed")
+	}
+	if gas > tx.Gas() {
+		return errors.Errorf("dry run failed: transaction needs %d gas, limit is %d", gas, tx.Gas())
+	}
+	return nil
+}
+
+// sender recovers the sender of a signed transaction.
+func sender(tx *types.Transaction) (common.Address, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	return types.Sender(signer, tx)
+}
+
+// isKnownTransaction reports whether a send error means the node already has the transaction.
+func isKnownTransaction(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,34 @@
+package txmgr
+
+// Policy describes how the estimated gas of a transaction is padded before it is
+// used as the gas limit.
+//
+// The padded limit is the larger of the estimate increased by Percentage and the
+// estimate increased by Floor, capped at Ceiling when it is set.
+type Policy struct {
+	Percentage uint64 // relative padding, in percent of the estimate
+	Floor      uint64 // minimum absolute padding, in gas
+	Ceiling    uint64 // maximum gas limit, 0 means unbounded
+}
+
+// DefaultPolicy is used for methods without a policy of their own.
+var DefaultPolicy = Policy{Percentage: 10}
+
+// Apply returns the gas limit to use for a transaction with the given estimate.
+func (p Policy) Apply(estimate uint64) uint64 {
+	relative := estimate + estimate*p.Percentage/100
+	absolute := estimate + p.Floor
+
+	limit := relative
+	if absolute > limit {
+		limit = absolute
+	}
+	if p.Ceiling != 0 && limit > p.Ceiling {
+		limit = p.Ceiling
+	}
+	if limit < estimate {
+		// The ceiling never makes a transaction fail that would otherwise succeed.
+		limit = estimate
+	}
+	return limit
+}
@@ -0,0 +1,62 @@
+package txmgr
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrRateLimited is returned by SendTransaction when the sender has exceeded
+// its transaction rate.
+var ErrRateLimited = errors.New("transaction rate limit exceeded")
+
+// RateLimiter is a token bucket per signer, limiting the number of
+// transactions sent per minute. A signer can send up to Burst transactions at
+// once, the bucket is then refilled at the rate per minute.
+type RateLimiter struct {
+	perMinute float64
+	burst     float64
+
+	mu      sync.Mutex
+	buckets map[common.Address]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows each signer perMinute transactions per minute, and a
+// burst of up to perMinute transactions.
+func NewRateLimiter(perMinute int) *RateLimiter {
+	return &RateLimiter{
+		perMinute: float64(perMinute),
+		burst:     float64(perMinute),
+		buckets:   make(map[common.Address]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of signer, reporting whether there was one.
+func (l *RateLimiter) Allow(signer common.Address) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[signer]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[signer] = b
+	}
+	b.tokens += now.Sub(b.last).Minutes() * l.perMinute
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,199 @@
+package txmgr
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// Defaults are the operational settings applied to the transactions calling a method.
+type Defaults struct {
+	// GasPadding overrides DefaultPolicy when set.
+	GasPadding *Policy `json:"gas_padding,omitempty"`
+	// Confirmations is the number of blocks a transaction must be buried under
+	// before it is considered final, 0 and 1 both mean once it is mined.
+	Confirmations uint64 `json:"confirmations"`
+	// Retry describes how sending the transaction is retried on failure.
+	Retry RetryPolicy `json:"retry"`
+	// DryRun requires the transaction to be simulated successfully before it is sent.
+	DryRun bool `json:"dry_run"`
+}
+
+// Padding returns the gas padding policy of the method.
+func (d Defaults) Padding() Policy {
+	if d.GasPadding == nil {
+		return DefaultPolicy
+	}
+	return *d.GasPadding
+}
+
+// RetryPolicy describes how many times and how often an operation is retried.
+type RetryPolicy struct {
+	Attempts int      `json:"attempts"`
+	Backoff  Duration `json:"backoff"`
+}
+
+// Duration is a time.Duration encoded in JSON and YAML as a string, e.g. "1m30s".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Registry holds the Defaults of each contract method.
+type Registry struct {
+	mu       sync.RWMutex
+	fallback Defaults
+	methods  map[Selector]Defaults
+}
+
+// NewRegistry creates an empty registry, all methods use the zero Defaults.
+func NewRegistry() *Registry {
+	return &Registry{methods: make(map[Selector]Defaults)}
+}
+
+// registryFile is the JSON representation of a registry:
+//
+//	{
+//	  "default": {"confirmations": 1},
+//	  "methods": {
+//	    "licence.updateLicenceAmount": {
+//	      "gas_padding": {"percentage": 20, "floor": 50000},
+//	      "confirmations": 3,
+//	      "retry": {"attempts": 3, "backoff": "5s"},
+//	      "dry_run": true
+//	    }
+//	  }
+//	}
+//
+// Methods are named <contract>.<method> using the names of bindings.ContractABIs.
+type registryFile struct {
+	Default Defaults            `json:"default"`
+	Methods map[string]Defaults `json:"methods"`
+}
+
+// LoadRegistry reads a registry from its JSON representation.
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	var f registryFile
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding method defaults")
+	}
+
+	reg := NewRegistry()
+	reg.SetFallback(f.Default)
+	for name, d := range f.Methods {
+		parts := strings.SplitN(name, ".", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("method %q is not of the form <contract>.<method>", name)
+		}
+		contractABI, ok := bindings.ContractABIs[parts[0]]
+		if !ok {
+			return nil, errors.Errorf("unknown contract %q", parts[0])
+		}
+		err = reg.Set(contractABI, parts[1], d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting defaults of %s", name)
+		}
+	}
+	return reg, nil
+}
+
+// LoadRegistryFile reads a registry from a JSON file.
+func LoadRegistryFile(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening method defaults file")
+	}
+	defer f.Close()
+	return LoadRegistry(f)
+}
+
+// SetFallback sets the Defaults of the methods without an entry of their own.
+func (r *Registry) SetFallback(d Defaults) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = d
+}
+
+// Set sets the Defaults of the named method of a contract ABI.
+func (r *Registry) Set(contractABI, method string, d Defaults) error {
+	s, err := MethodSelector(contractABI, method)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods[s] = d
+	return nil
+}
+
+// update applies fn to the Defaults of the named method.
+func (r *Registry) update(contractABI, method string, fn func(*Defaults)) error {
+	s, err := MethodSelector(contractABI, method)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.methods[s]
+	if !ok {
+		d = r.fallback
+	}
+	fn(&d)
+	r.methods[s] = d
+	return nil
+}
+
+// Lookup returns the Defaults applying to a call with the given data.
+func (r *Registry) Lookup(data []byte) Defaults {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(data) < 4 {
+		return r.fallback
+	}
+	var s Selector
+	copy(s[:], data)
+	d, ok := r.methods[s]
+	if !ok {
+		return r.fallback
+	}
+	return d
+}
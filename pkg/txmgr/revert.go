@@ -0,0 +1,45 @@
+package txmgr
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// revertSelector is the selector of Error(string), which the contracts revert
+// with when a require or a revert gives a reason.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+var revertArguments = func() abi.Arguments {
+	t, err := abi.NewType("string", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return abi.Arguments{{Type: t}}
+}()
+
+// RevertError is the cause of the error of a dry run which reverted with a
+// reason, to compare with errors.Cause.
+type RevertError struct {
+	// Reason is the reason given by the contract, e.g. "sender is not an
+	// admin".
+	Reason string
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+// RevertReason decodes the reason of a revert from the output of a call. It
+// returns false when the output is not the encoding of an Error(string).
+func RevertReason(output []byte) (string, bool) {
+	if len(output) < len(revertSelector) || !bytes.Equal(output[:len(revertSelector)], revertSelector) {
+		return "", false
+	}
+	var reason string
+	err := revertArguments.Unpack(&reason, output[len(revertSelector):])
+	if err != nil {
+		return "", false
+	}
+	return reason, true
+}
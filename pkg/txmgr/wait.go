@@ -0,0 +1,74 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// confirmationPollInterval is the delay between two checks of the chain head
+// while waiting for confirmations.
+const confirmationPollInterval = time.Second
+
+// ErrReverted is the cause of the error returned by Wait for a transaction
+// which reverted.
+var ErrReverted = errors.New("transaction reverted")
+
+// Chain is the part of the node API used to wait for transactions.
+type Chain interface {
+	bind.DeployBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Wait waits for the transaction to be mined and buried under the number of
+// confirmations configured for the method called. The receipt of a reverted
+// transaction is returned along with an error caused by ErrReverted.
+func (m *Manager) Wait(ctx context.Context, chain Chain, tx *types.Transaction) (*types.Receipt, error) {
+	r, err := bind.WaitMined(ctx, chain, tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "waiting for transaction to be mined")
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		m.logger.Error("Transaction reverted", "hash", tx.Hash(), "block", r.BlockNumber, "gas", r.GasUsed)
+		return r, errors.Wrapf(ErrReverted, "transaction %s failed in block %s", tx.Hash().Hex(), r.BlockNumber)
+	}
+	m.logger.Info("Transaction mined", "hash", tx.Hash(), "block", r.BlockNumber, "gas", r.GasUsed)
+
+	confirmations := m.registry.Lookup(tx.Data()).Confirmations
+	if confirmations <= 1 {
+		return r, nil
+	}
+	for {
+		ok, err := confirmed(ctx, chain, r, confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.logger.Info("Transaction confirmed", "hash", tx.Hash(), "confirmations", confirmations)
+			return r, nil
+		}
+		select {
+		case <-time.After(confirmationPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// confirmed tells whether the head of the chain is confirmations blocks past
+// the block of the receipt, counting its own.
+func confirmed(ctx context.Context, chain Chain, r *types.Receipt, confirmations uint64) (bool, error) {
+	if confirmations <= 1 {
+		return true, nil
+	}
+	head, err := chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "getting latest block")
+	}
+	target := new(big.Int).Add(r.BlockNumber, new(big.Int).SetUint64(confirmations-1))
+	return head.Number.Cmp(target) >= 0, nil
+}
@@ -0,0 +1,196 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultWaitInterval is the delay between two polls of the node made by a
+// Waiter when Interval is not set.
+const DefaultWaitInterval = time.Second
+
+// ErrReplaced is the cause of the error returned by Waiter.Wait for a
+// transaction whose nonce was used by another transaction, which the waiter
+// was not told about with Replaced.
+var ErrReplaced = errors.New("transaction replaced")
+
+// WaitBackend is the part of the node API used by a Waiter.
+type WaitBackend interface {
+	Chain
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// Mined is a transaction mined and buried under the confirmations of the
+// waiter.
+type Mined struct {
+	// Tx is the transaction mined, a replacement of the transaction waited
+	// for when it was replaced.
+	Tx      *types.Transaction
+	Receipt *types.Receipt
+}
+
+// Succeeded tells whether the transaction succeeded, failed ones are mined
+// all the same.
+func (m *Mined) Succeeded() bool {
+	return m.Receipt.Status == types.ReceiptStatusSuccessful
+}
+
+// Waiter waits for transactions to be mined and confirmed, following the
+// transactions replacing them, e.g. sped up or cancelled with a higher gas
+// price.
+//
+// For example:
+//
+//	w := txmgr.NewWaiter(client, 12)
+//	tx, err := deployer.DeployWallet(opts, owner)
+//	...
+//	m, err := w.Wait(ctx, tx)
+//	...
+//	events, err := indexer.ReceiptEvents(walletDeployer, m.Receipt)
+type Waiter struct {
+	backend WaitBackend
+
+	// Confirmations is the number of blocks the transaction must be buried
+	// under, counting its own: 1 returns as soon as it is mined.
+	Confirmations uint64
+	// Interval is the delay between two polls of the node.
+	Interval time.Duration
+
+	mu sync.Mutex
+	// nonces are the transactions sharing a nonce, by hash, until a wait
+	// for one of them returns.
+	nonces map[common.Hash]*[]*types.Transaction
+}
+
+// NewWaiter returns a waiter for the given number of confirmations.
+func NewWaiter(backend WaitBackend, confirmations uint64) *Waiter {
+	return &Waiter{
+		backend:       backend,
+		Confirmations: confirmations,
+		Interval:      DefaultWaitInterval,
+		nonces:        make(map[common.Hash]*[]*types.Transaction),
+	}
+}
+
+// Replaced records that replacement was sent with the nonce of tx, to speed
+// it up or cancel it. Waiting for any of the transactions sharing the nonce
+// then waits for whichever is mined.
+func (w *Waiter) Replaced(tx, replacement *types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	g := w.nonces[tx.Hash()]
+	if g == nil {
+		g = &[]*types.Transaction{tx}
+		w.nonces[tx.Hash()] = g
+	}
+	for _, t := range *g {
+		if t.Hash() == replacement.Hash() {
+			return
+		}
+	}
+	*g = append(*g, replacement)
+	w.nonces[replacement.Hash()] = g
+}
+
+// candidates returns tx and the transactions sharing its nonce.
+func (w *Waiter) candidates(tx *types.Transaction) []*types.Transaction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	txs := []*types.Transaction{tx}
+	if g := w.nonces[tx.Hash()]; g != nil {
+		for _, t := range *g {
+			if t.Hash() != tx.Hash() {
+				txs = append(txs, t)
+			}
+		}
+	}
+	return txs
+}
+
+// forget drops tx and the transactions sharing its nonce.
+func (w *Waiter) forget(tx *types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	g := w.nonces[tx.Hash()]
+	if g == nil {
+		return
+	}
+	for _, t := range *g {
+		delete(w.nonces, t.Hash())
+	}
+}
+
+// Wait waits until tx, or a transaction recorded as replacing it, is mined
+// and buried under Confirmations blocks. A receipt dropped by a reorg while
+// waiting for the confirmations is waited for again. The transactions mined
+// but failed are returned without error, see Mined.Succeeded. The error is
+// caused by ErrReplaced when another transaction used the nonce of tx. The
+// replacements of tx are forgotten once it returns.
+func (w *Waiter) Wait(ctx context.Context, tx *types.Transaction) (*Mined, error) {
+	defer w.forget(tx)
+	from, err := sender(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "recovering sender")
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+	// replaced is set once the nonce of tx was used by a transaction without
+	// a receipt found, which is looked up once more before giving up in case
+	// the receipt lagged behind the nonce.
+	replaced := false
+	for {
+		m, err := w.mined(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			ok, err := confirmed(ctx, w.backend, m.Receipt, w.Confirmations)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return m, nil
+			}
+			replaced = false
+		} else {
+			if replaced {
+				return nil, errors.Wrapf(ErrReplaced, "nonce %d of %s used by another transaction than %s", tx.Nonce(), from.Hex(), tx.Hash().Hex())
+			}
+			nonce, err := w.backend.NonceAt(ctx, from, nil)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting nonce")
+			}
+			replaced = nonce > tx.Nonce()
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// mined returns the first of the candidates for tx with a receipt, nil when
+// none has one yet.
+func (w *Waiter) mined(ctx context.Context, tx *types.Transaction) (*Mined, error) {
+	for _, t := range w.candidates(tx) {
+		r, err := w.backend.TransactionReceipt(ctx, t.Hash())
+		if err == ethereum.NotFound || (err == nil && r == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting receipt of %s", t.Hash().Hex())
+		}
+		return &Mined{Tx: t, Receipt: r}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,183 @@
+// Package units converts the amounts of ether and of tokens between their
+// base unit, as the contracts and the bindings hold them, and the decimal
+// strings read from the operators and the configuration files:
+//
+//	amount, err := units.ParseEther("1.5")     // 1500000000000000000 wei
+//	price, err := units.ParseGwei("20")        // 20000000000 wei
+//	units.FormatTKN(big.NewInt(150000000))     // "1.5"
+//
+// The amounts are parsed exactly: an amount with more decimals than its unit
+// has, or beyond the range of a uint256, is an error rather than rounded or
+// truncated.
+package units
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Decimals of the units.
+const (
+	// GweiDecimals are the decimals of gwei, the unit of the gas prices.
+	GweiDecimals = 9
+	// EtherDecimals are the decimals of ether.
+	EtherDecimals = 18
+	// TKNDecimals are the decimals of the TKN token.
+	TKNDecimals = 8
+)
+
+// maxDecimals bounds the decimals of the units, those of an ERC20 token fit
+// in a uint8.
+const maxDecimals = 255
+
+// MaxUint256 is the largest amount of a uint256.
+var MaxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ParseInt parses a decimal integer, with an optional leading minus sign.
+// Unlike big.Int.SetString, a plus sign, underscores and surrounding spaces
+// are rejected, and the absolute value must fit in a uint256.
+func ParseInt(s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(s, "-")
+	if !isDigits(digits) {
+		return nil, errors.Errorf("%q is not a decimal integer", s)
+	}
+	n, _ := new(big.Int).SetString(digits, 10)
+	if n.Cmp(MaxUint256) > 0 {
+		return nil, errors.Errorf("%q is out of the range of a uint256", s)
+	}
+	if len(digits) < len(s) {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// ParseUint parses a decimal integer which is not negative and fits in a
+// uint256, an amount in its base unit.
+func ParseUint(s string) (*big.Int, error) {
+	if strings.HasPrefix(s, "-") {
+		return nil, errors.Errorf("%q is negative", s)
+	}
+	return ParseInt(s)
+}
+
+// ParseDecimal parses a decimal amount of a unit with the given decimals,
+// e.g. "1.5" with 18 decimals, and returns it in the base unit. The amount
+// must not be negative, nor have more decimals than the unit.
+func ParseDecimal(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 || decimals > maxDecimals {
+		return nil, errors.Errorf("invalid number of decimals %d", decimals)
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+		if whole == "" || fraction == "" || !isDigits(fraction) {
+			return nil, errors.Errorf("%q is not a decimal amount", s)
+		}
+	}
+	if strings.HasPrefix(whole, "-") {
+		return nil, errors.Errorf("%q is negative", s)
+	}
+	if !isDigits(whole) {
+		return nil, errors.Errorf("%q is not a decimal amount", s)
+	}
+	trimmed := strings.TrimRight(fraction, "0")
+	if len(trimmed) > decimals {
+		return nil, errors.Errorf("%q has more than %d decimals", s, decimals)
+	}
+	n, _ := new(big.Int).SetString(whole+trimmed+strings.Repeat("0", decimals-len(trimmed)), 10)
+	if n.Cmp(MaxUint256) > 0 {
+		return nil, errors.Errorf("%q is out of the range of a uint256", s)
+	}
+	return n, nil
+}
+
+// Format formats an amount in the base unit of a unit with the given
+// decimals, without its trailing zeros, e.g. "1.5" for 1500000000000000000
+// with 18 decimals. Format(nil, d) is "0".
+func Format(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	s := whole
+	if fraction != "" {
+		s += "." + fraction
+	}
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Convert converts a whole amount of a unit worth 10^from base units to a
+// unit worth 10^to, e.g. from gwei (GweiDecimals) to wei (0). An amount which
+// cannot be converted exactly is an error.
+func Convert(amount *big.Int, from, to int) (*big.Int, error) {
+	if from < 0 || from > maxDecimals || to < 0 || to > maxDecimals {
+		return nil, errors.Errorf("invalid number of decimals %d or %d", from, to)
+	}
+	if from >= to {
+		return new(big.Int).Mul(amount, pow10(from-to)), nil
+	}
+	q, r := new(big.Int).QuoRem(amount, pow10(to-from), new(big.Int))
+	if r.Sign() != 0 {
+		return nil, errors.Errorf("%s cannot be converted exactly from %d to %d decimals", amount, from, to)
+	}
+	return q, nil
+}
+
+// ParseEther parses an amount of ether and returns it in wei.
+func ParseEther(s string) (*big.Int, error) {
+	return ParseDecimal(s, EtherDecimals)
+}
+
+// FormatEther formats an amount of wei in ether.
+func FormatEther(wei *big.Int) string {
+	return Format(wei, EtherDecimals)
+}
+
+// ParseGwei parses an amount of gwei, e.g. a gas price, and returns it in
+// wei.
+func ParseGwei(s string) (*big.Int, error) {
+	return ParseDecimal(s, GweiDecimals)
+}
+
+// FormatGwei formats an amount of wei in gwei.
+func FormatGwei(wei *big.Int) string {
+	return Format(wei, GweiDecimals)
+}
+
+// ParseTKN parses an amount of TKN and returns it in its base unit.
+func ParseTKN(s string) (*big.Int, error) {
+	return ParseDecimal(s, TKNDecimals)
+}
+
+// FormatTKN formats an amount of TKN in its base unit.
+func FormatTKN(amount *big.Int) string {
+	return Format(amount, TKNDecimals)
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,355 @@
+// Package watch keeps the event subscriptions of the bindings alive when the
+// connection to the node drops. A subscription made through a Filterer
+// reconnects with an exponential backoff, filters the logs emitted while it
+// was disconnected from the block of the last log delivered, and skips the
+// logs it already delivered, so that the WatchX methods of the bindings
+// deliver every log once.
+//
+// For example:
+//
+//	f, err := watch.New(ctx, watch.DialURL("wss://mainnet.example"))
+//	token, err := bindings.NewTokenFilterer(address, f)
+//	sub, err := token.WatchTransfer(nil, sink, nil, nil)
+//
+// The logs removed from the chain by a reorganization are delivered again
+// with their Removed flag set, as the node sends them. The logs of the last
+// block delivered which the chain lost while the subscription was
+// disconnected are delivered as removed once it resumes, the older ones are
+// not followed.
+//
+// The logs filtered after a long disconnection may span more blocks than the
+// provider accepts in a query, dial a logfilter.Backend to filter them in
+// chunks.
+package watch
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/logging"
+)
+
+const (
+	// DefaultBackoff is the delay before the first reconnection when Backoff
+	// is not set.
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff is the longest delay between two reconnections when
+	// MaxBackoff is not set.
+	DefaultMaxBackoff = time.Minute
+)
+
+// Backend is a connection to a node.
+type Backend interface {
+	bind.ContractFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// DialFunc opens a new connection to the node.
+type DialFunc func(ctx context.Context) (Backend, error)
+
+// DialURL returns a DialFunc connecting to the node at url, which must be a
+// WebSocket or IPC endpoint to support subscriptions.
+func DialURL(url string) DialFunc {
+	return func(ctx context.Context) (Backend, error) {
+		c, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			return nil, errors.Wrapf(err, "connecting to %s", url)
+		}
+		return c, nil
+	}
+}
+
+// Filterer is a bind.ContractFilterer whose subscriptions survive the loss of
+// the connection to the node. The subscriptions share a single connection,
+// replaced by the first of them noticing that it dropped.
+type Filterer struct {
+	dial DialFunc
+
+	// Backoff is the delay before the first reconnection of a subscription,
+	// doubled after each failed attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two reconnections.
+	MaxBackoff time.Duration
+	// Logger receives the dropped and resumed subscriptions.
+	Logger logging.Logger
+
+	mu      sync.Mutex
+	backend Backend
+}
+
+// New creates a Filterer and opens its first connection.
+func New(ctx context.Context, dial DialFunc) (*Filterer, error) {
+	backend, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Filterer{
+		dial:       dial,
+		Backoff:    DefaultBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		backend:    backend,
+	}, nil
+}
+
+// connection returns the current connection.
+func (f *Filterer) connection() Backend {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.backend
+}
+
+// reconnect replaces the failed connection, unless another subscription
+// already did.
+func (f *Filterer) reconnect(ctx context.Context, failed Backend) (Backend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.backend != failed {
+		return f.backend, nil
+	}
+	backend, err := f.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := failed.(interface{ Close() }); ok {
+		c.Close()
+	}
+	f.backend = backend
+	return backend, nil
+}
+
+// Close closes the current connection, once the subscriptions are
+// unsubscribed.
+func (f *Filterer) Close() {
+	if c, ok := f.connection().(interface{ Close() }); ok {
+		c.Close()
+	}
+}
+
+// FilterLogs implements bind.ContractFilterer.
+func (f *Filterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return f.connection().FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs implements bind.ContractFilterer. The subscription only
+// fails when it is established, it then reconnects until it is unsubscribed.
+// Its logs are filtered from the FromBlock of the query, or from the head of
+// the chain when it is not set, after a reconnection.
+func (f *Filterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, sink chan<- types.Log) (ethereum.Subscription, error) {
+	backend := f.connection()
+	s := &subscription{
+		filterer: f,
+		query:    query,
+		sink:     sink,
+		seen:     make(map[logKey]types.Log),
+		err:      make(chan error),
+		quit:     make(chan struct{}),
+	}
+	if query.FromBlock != nil && query.FromBlock.Sign() >= 0 {
+		s.last = query.FromBlock.Uint64()
+	} else {
+		head, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting latest block")
+		}
+		s.last = head.Number.Uint64()
+	}
+
+	logs := make(chan types.Log)
+	sub, err := backend.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return nil, err
+	}
+	go s.loop(backend, sub, logs)
+	return s, nil
+}
+
+// logKey identifies a delivered log.
+type logKey struct {
+	block   common.Hash
+	index   uint
+	removed bool
+}
+
+type subscription struct {
+	filterer *Filterer
+	query    ethereum.FilterQuery
+	sink     chan<- types.Log
+
+	// last is the block logs are filtered from after a reconnection: the
+	// block of the last log delivered, or where the subscription started.
+	last uint64
+	// seen holds the logs delivered from last.
+	seen map[logKey]types.Log
+
+	err      chan error
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+// Unsubscribe implements ethereum.Subscription.
+func (s *subscription) Unsubscribe() {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+}
+
+// Err implements ethereum.Subscription. The channel is closed on
+// Unsubscribe, no error is sent on it as the subscription reconnects.
+func (s *subscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *subscription) logger() logging.Logger {
+	return logging.Or(s.filterer.Logger)
+}
+
+func (s *subscription) loop(backend Backend, sub ethereum.Subscription, logs chan types.Log) {
+	defer close(s.err)
+	for {
+		select {
+		case l := <-logs:
+			if !s.deliver(l) {
+				sub.Unsubscribe()
+				return
+			}
+		case err := <-sub.Err():
+			sub.Unsubscribe()
+			s.logger().Warn("Log subscription dropped, reconnecting", "from", s.last, "err", err)
+			var ok bool
+			backend, sub, logs, ok = s.resume(backend)
+			if !ok {
+				return
+			}
+		case <-s.quit:
+			sub.Unsubscribe()
+			return
+		}
+	}
+}
+
+// resume reconnects with an exponential backoff, subscribes again and
+// delivers the logs emitted since the last one delivered. It returns false
+// when the subscription is unsubscribed first.
+func (s *subscription) resume(failed Backend) (Backend, ethereum.Subscription, chan types.Log, bool) {
+	backoff := s.filterer.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	maxBackoff := s.filterer.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-s.quit:
+			return nil, nil, nil, false
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		backend, err := s.filterer.reconnect(ctx, failed)
+		if err != nil {
+			s.logger().Warn("Reconnecting failed", "attempt", attempt, "err", err)
+			continue
+		}
+		failed = backend
+
+		// Subscribing before filtering leaves no gap between the two, the
+		// logs of both are deduplicated.
+		logs := make(chan types.Log)
+		sub, err := backend.SubscribeFilterLogs(ctx, s.query, logs)
+		if err != nil {
+			s.logger().Warn("Resubscribing failed", "attempt", attempt, "err", err)
+			continue
+		}
+		query := s.query
+		query.FromBlock = new(big.Int).SetUint64(s.last)
+		query.ToBlock = nil
+		missed, err := backend.FilterLogs(ctx, query)
+		if err != nil {
+			sub.Unsubscribe()
+			s.logger().Warn("Filtering missed logs failed", "attempt", attempt, "from", s.last, "err", err)
+			continue
+		}
+		for _, l := range append(s.lost(missed), missed...) {
+			if !s.deliver(l) {
+				sub.Unsubscribe()
+				return nil, nil, nil, false
+			}
+		}
+		s.logger().Info("Log subscription resumed", "from", query.FromBlock, "missed", len(missed))
+		return backend, sub, logs, true
+	}
+}
+
+// lost returns the delivered logs which are not among the logs filtered from
+// the block of the last one, flagged as removed.
+func (s *subscription) lost(filtered []types.Log) []types.Log {
+	canonical := make(map[logKey]bool, len(filtered))
+	for _, l := range filtered {
+		canonical[logKey{block: l.BlockHash, index: l.Index}] = true
+	}
+	var lost []types.Log
+	for k, l := range s.seen {
+		if k.removed || canonical[k] {
+			continue
+		}
+		if _, ok := s.seen[logKey{block: k.block, index: k.index, removed: true}]; ok {
+			continue
+		}
+		l.Removed = true
+		lost = append(lost, l)
+	}
+	sort.Slice(lost, func(i, j int) bool {
+		return lost[i].Index > lost[j].Index
+	})
+	return lost
+}
+
+// deliver sends the log to the sink unless it was already delivered. It
+// returns false when the subscription is unsubscribed first.
+func (s *subscription) deliver(l types.Log) bool {
+	key := logKey{block: l.BlockHash, index: l.Index, removed: l.Removed}
+	if _, ok := s.seen[key]; ok {
+		return true
+	}
+	select {
+	case s.sink <- l:
+	case <-s.quit:
+		return false
+	}
+
+	s.seen[key] = l
+	if l.BlockNumber > s.last {
+		s.last = l.BlockNumber
+		for k, seen := range s.seen {
+			if seen.BlockNumber < s.last {
+				delete(s.seen, k)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Failure is a delivery that could not be completed.
+type Failure struct {
+	Time     time.Time `json:"time"`
+	URL      string    `json:"url"`
+	Payload  Payload   `json:"payload"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+}
+
+// DeadLetter receives the deliveries which failed after all the attempts.
+type DeadLetter interface {
+	Put(f Failure) error
+}
+
+// DeadLetterFunc adapts a function to the DeadLetter interface.
+type DeadLetterFunc func(f Failure) error
+
+// Put implements DeadLetter.
+func (fn DeadLetterFunc) Put(f Failure) error {
+	return fn(f)
+}
+
+// FileDeadLetter appends the failed deliveries to a file as JSON lines, from
+// which they can be inspected and replayed.
+type FileDeadLetter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileDeadLetter creates a dead letter queue writing to the given file.
+func NewFileDeadLetter(path string) *FileDeadLetter {
+	return &FileDeadLetter{path: path}
+}
+
+// Put implements DeadLetter.
+func (d *FileDeadLetter) Put(f Failure) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "opening dead letter file")
+	}
+	err = json.NewEncoder(file).Encode(f)
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "writing dead letter")
+	}
+	return file.Close()
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// EncryptionHeader names the encryption of the body of encrypted deliveries.
+const EncryptionHeader = "X-Monolith-Encryption"
+
+// SealedBox is the encryption of the deliveries to endpoints with a recipient
+// key: an anonymous X25519-XSalsa20-Poly1305 box as produced by libsodium's
+// crypto_box_seal, the 32 byte ephemeral public key followed by the box.
+const SealedBox = "x25519-xsalsa20-poly1305-sealedbox"
+
+// PublicKey is the X25519 public key of a recipient.
+type PublicKey [32]byte
+
+// ParsePublicKey decodes a base64 encoded X25519 public key.
+func ParsePublicKey(s string) (*PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding recipient key")
+	}
+	if len(b) != len(PublicKey{}) {
+		return nil, errors.Errorf("recipient key is %d bytes long, expected 32", len(b))
+	}
+	var key PublicKey
+	copy(key[:], b)
+	return &key, nil
+}
+
+// String returns the key encoded in base64.
+func (k PublicKey) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// Seal encrypts a message to the recipient. Only the holder of the private key
+// can open it, the sender cannot.
+func Seal(message []byte, recipient *PublicKey) ([]byte, error) {
+	ephemeralPublic, ephemeralPrivate, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating ephemeral key")
+	}
+	nonce := sealNonce(ephemeralPublic, (*[32]byte)(recipient))
+	return box.Seal(ephemeralPublic[:], message, nonce, (*[32]byte)(recipient), ephemeralPrivate), nil
+}
+
+// Open decrypts a message sealed to the key pair.
+func Open(sealed []byte, public *PublicKey, private *[32]byte) ([]byte, error) {
+	if len(sealed) < 32+box.Overhead {
+		return nil, errors.New("sealed message too short")
+	}
+	var ephemeralPublic [32]byte
+	copy(ephemeralPublic[:], sealed)
+	nonce := sealNonce(&ephemeralPublic, (*[32]byte)(public))
+	message, ok := box.Open(nil, sealed[32:], nonce, &ephemeralPublic, private)
+	if !ok {
+		return nil, errors.New("decrypting sealed message")
+	}
+	return message, nil
+}
+
+// sealNonce derives the nonce of a sealed box from the public keys, as
+// libsodium does: blake2b-192(ephemeral public key || recipient public key).
+func sealNonce(ephemeral, recipient *[32]byte) *[24]byte {
+	h, _ := blake2b.New(24, nil)
+	h.Write(ephemeral[:])
+	h.Write(recipient[:])
+	var nonce [24]byte
+	copy(nonce[:], h.Sum(nil))
+	return &nonce
+}
@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Notifier delivers events to webhook endpoints. It implements indexer.Handler
+// and indexer.Finality so that it can be attached to an indexer. Deliveries are queued and sent by
+// Run, a slow endpoint does not hold back the indexer.
+type Notifier struct {
+	Client *http.Client
+	// Attempts is the number of times a delivery is tried.
+	Attempts int
+	// Backoff is the delay before the first retry, doubled for each retry.
+	Backoff    time.Duration
+	DeadLetter DeadLetter
+	// ErrorLog receives the failed deliveries, they are discarded when nil.
+	ErrorLog *log.Logger
+
+	mu        sync.RWMutex
+	endpoints []Endpoint
+	queue     chan delivery
+}
+
+type delivery struct {
+	endpoint Endpoint
+	payload  Payload
+}
+
+// NewNotifier creates a notifier delivering to the given endpoints, queueing
+// up to size deliveries.
+func NewNotifier(size int, endpoints ...Endpoint) *Notifier {
+	return &Notifier{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Attempts:  5,
+		Backoff:   time.Second,
+		endpoints: endpoints,
+		queue:     make(chan delivery, size),
+	}
+}
+
+// Endpoints returns the endpoints the events are delivered to.
+func (n *Notifier) Endpoints() []Endpoint {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]Endpoint(nil), n.endpoints...)
+}
+
+// SetEndpoints replaces the endpoints the events are delivered to. Queued
+// deliveries are still sent to the previous endpoints.
+func (n *Notifier) SetEndpoints(endpoints ...Endpoint) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.endpoints = endpoints
+}
+
+// Confirmations implements indexer.Finality, the notifier is handed the
+// events at the finality level of each endpoint.
+func (n *Notifier) Confirmations() []uint64 {
+	seen := make(map[uint64]bool)
+	var levels []uint64
+	for _, e := range n.Endpoints() {
+		if !seen[e.Confirmations] {
+			seen[e.Confirmations] = true
+			levels = append(levels, e.Confirmations)
+		}
+	}
+	return levels
+}
+
+// HandleEvents implements indexer.Handler, queueing the deliveries of the
+// events to the endpoints of their finality level.
+func (n *Notifier) HandleEvents(ctx context.Context, events []indexer.Event) error {
+	endpoints := n.Endpoints()
+	for _, ev := range events {
+		for _, e := range endpoints {
+			if !e.wants(ev) {
+				continue
+			}
+			select {
+			case n.queue <- delivery{endpoint: e, payload: NewPayload(ev)}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Run sends the queued deliveries until the context is cancelled.
+func (n *Notifier) Run(ctx context.Context) error {
+	for {
+		select {
+		case d := <-n.queue:
+			n.deliver(ctx, d)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// deliver sends a delivery, retrying failures and handing it to the dead
+// letter queue when all the attempts failed.
+func (n *Notifier) deliver(ctx context.Context, d delivery) {
+	backoff := n.Backoff
+	var err error
+	attempt := 0
+	for attempt < n.Attempts || attempt == 0 {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff *= 2
+		}
+		attempt++
+		err = n.Send(ctx, d.endpoint, d.payload)
+		if err == nil {
+			return
+		}
+	}
+
+	if n.ErrorLog != nil {
+		n.ErrorLog.Printf("delivering %s to %s failed after %d attempt(s): %v", d.payload.ID, d.endpoint.URL, attempt, err)
+	}
+	if n.DeadLetter == nil {
+		return
+	}
+	dlErr := n.DeadLetter.Put(Failure{
+		Time:     time.Now(),
+		URL:      d.endpoint.URL,
+		Payload:  d.payload,
+		Attempts: attempt,
+		Error:    err.Error(),
+	})
+	if dlErr != nil && n.ErrorLog != nil {
+		n.ErrorLog.Printf("dead lettering %s: %v", d.payload.ID, dlErr)
+	}
+}
+
+// Send posts a signed payload to the endpoint once, encrypted when the
+// endpoint has a recipient key. Any response other than
+// 2xx is an error.
+func (n *Notifier) Send(ctx context.Context, e Endpoint, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "encoding payload")
+	}
+	contentType := "application/json"
+	if e.RecipientKey != nil {
+		body, err = Seal(body, e.RecipientKey)
+		if err != nil {
+			return errors.Wrap(err, "encrypting payload")
+		}
+		contentType = "application/octet-stream"
+	}
+	req, err := http.NewRequest(http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", contentType)
+	if e.RecipientKey != nil {
+		req.Header.Set(EncryptionHeader, SealedBox)
+	}
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(e.Secret, timestamp, body))
+
+	resp, err := n.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "posting payload")
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
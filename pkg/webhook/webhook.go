@@ -0,0 +1,109 @@
+// Package webhook posts the indexed contract events to external systems.
+//
+// Each delivery is a JSON Payload signed with the secret of the endpoint. The
+// signature is sent in the X-Monolith-Signature header as the hex encoded
+// HMAC-SHA256 of the timestamp header, a dot and the body:
+//
+//	X-Monolith-Timestamp: 1576800000
+//	X-Monolith-Signature: sha256=<hex encoded HMAC>
+//
+// Receivers should reject stale timestamps to prevent replays.
+//
+// Deliveries to endpoints with a recipient key are encrypted end-to-end, for
+// payloads carrying personal data: the body is the SealedBox of the JSON
+// payload, sent as application/octet-stream with the X-Monolith-Encryption
+// header. The signature covers the encrypted body, receivers verify it before
+// opening the box with their private key.
+//
+// Failed
+// deliveries are retried with an exponential backoff and handed to the dead
+// letter queue once the attempts are exhausted.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// Signature and timestamp headers of the deliveries.
+const (
+	SignatureHeader = "X-Monolith-Signature"
+	TimestampHeader = "X-Monolith-Timestamp"
+)
+
+// Endpoint is a webhook receiver.
+type Endpoint struct {
+	URL    string
+	Secret string
+	// Events are the names of the events delivered to the endpoint, either
+	// as "Name" or "contract.Name". All events are delivered when empty.
+	Events []string
+	// RecipientKey encrypts the deliveries when set.
+	RecipientKey *PublicKey
+	// Confirmations is the number of blocks mined on top of the block of an
+	// event before it is delivered, zero delivering it as soon as indexed.
+	// The payloads carry it as the confirmations of the event.
+	Confirmations uint64
+}
+
+func (e Endpoint) wants(ev indexer.Event) bool {
+	if ev.Confirmations != e.Confirmations {
+		return false
+	}
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, name := range e.Events {
+		if name == ev.Name || name == ev.Contract+"."+ev.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the body of a delivery.
+type Payload struct {
+	// ID identifies the event, receivers can use it to discard duplicate
+	// deliveries. The event removed by a reorganization, with its Removed
+	// flag set, has its own ID suffixed with "-removed" so that receivers
+	// undo it.
+	ID    string        `json:"id"`
+	Event indexer.Event `json:"event"`
+}
+
+// NewPayload creates the payload delivering an event.
+func NewPayload(ev indexer.Event) Payload {
+	args := make(map[string]interface{}, len(ev.Args))
+	for k, v := range ev.Args {
+		args[k] = indexer.FormatArg(v)
+	}
+	ev.Args = args
+	id := fmt.Sprintf("%s-%d", ev.TxHash.Hex(), ev.LogIndex)
+	if ev.Removed {
+		id += "-removed"
+	}
+	return Payload{
+		ID:    id,
+		Event: ev,
+	}
+}
+
+// Sign returns the signature of a delivery body sent at the given Unix time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of the body sent at the
+// given Unix time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
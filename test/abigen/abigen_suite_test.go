@@ -0,0 +1,13 @@
+package abigen_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAbigenSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Abigen Suite")
+}
@@ -0,0 +1,82 @@
+package abigen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/build"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/tools/abigen/generate"
+)
+
+var _ = Describe("Generate", func() {
+
+	It("should reproduce the committed bindings", func() {
+		drifted, err := generate.Verify(build.Artifacts, "../../pkg/bindings", generate.Targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drifted).To(BeEmpty())
+	})
+
+	When("the bindings are written to a directory", func() {
+
+		var dir string
+		var targets []generate.Target
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "abigen")
+			Expect(err).ToNot(HaveOccurred())
+			targets = []generate.Target{
+				{Contract: "controller/Controller", Out: "controller.go", Type: "Controller", Package: "bindings"},
+				{Contract: "mocks/token/Token", Out: "mocks/token.go", Type: "Token", Package: "mocks"},
+			}
+			Expect(generate.Write(build.Artifacts, dir, targets)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should verify them", func() {
+			drifted, err := generate.Verify(build.Artifacts, dir, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifted).To(BeEmpty())
+		})
+
+		It("should report an edited binding", func() {
+			out := filepath.Join(dir, "mocks", "token.go")
+			Expect(ioutil.WriteFile(out, []byte("package mocks\n"), 0644)).To(Succeed())
+			drifted, err := generate.Verify(build.Artifacts, dir, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifted).To(Equal([]string{out}))
+		})
+
+		It("should report a missing binding", func() {
+			out := filepath.Join(dir, "controller.go")
+			Expect(os.Remove(out)).To(Succeed())
+			drifted, err := generate.Verify(build.Artifacts, dir, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drifted).To(Equal([]string{out}))
+		})
+	})
+
+	It("should declare the parsed ABIs and the topics of the events", func() {
+		for name, parsed := range bindings.ContractParsedABIs {
+			Expect(parsed.Methods).ToNot(BeEmpty(), name)
+		}
+		Expect(bindings.LicenceTransferredToTokenHolderTopic).To(Equal(bindings.LicenceParsedABI.Events["TransferredToTokenHolder"].ID()))
+		Expect(bindings.WalletBulkTransferredTopic).To(Equal(bindings.WalletParsedABI.Events["BulkTransferred"].ID()))
+		Expect(indexer.TransferTopic).To(Equal(mocks.TokenTransferTopic))
+		Expect(indexer.ApprovalTopic).To(Equal(mocks.TokenApprovalTopic))
+	})
+
+	It("should fail on a contract which was not compiled", func() {
+		_, err := generate.Generate(build.Artifacts, generate.Target{Contract: "missing/Missing", Type: "Missing", Package: "bindings"})
+		Expect(err).To(MatchError(ContainSubstring("reading the ABI of Missing")))
+	})
+})
@@ -0,0 +1,13 @@
+package access_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAccessSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Access Suite")
+}
@@ -0,0 +1,81 @@
+package access_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tokencard/contracts/v2/pkg/access"
+)
+
+var _ = Describe("Allowlist", func() {
+
+	It("should allow the addresses of the networks", func() {
+		a, err := access.NewAllowlist("10.0.0.0/8", "192.168.1.7", "fd00::/8")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.Allows(net.ParseIP("10.1.2.3"))).To(BeTrue())
+		Expect(a.Allows(net.ParseIP("192.168.1.7"))).To(BeTrue())
+		Expect(a.Allows(net.ParseIP("192.168.1.8"))).To(BeFalse())
+		Expect(a.Allows(net.ParseIP("fd12::1"))).To(BeTrue())
+		Expect(a.Allows(net.ParseIP("127.0.0.1"))).To(BeFalse())
+	})
+
+	It("should allow every address when empty", func() {
+		a, err := access.NewAllowlist()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.Allows(net.ParseIP("203.0.113.1"))).To(BeTrue())
+	})
+
+	It("should reject invalid blocks", func() {
+		_, err := access.NewAllowlist("10.0.0.0/33")
+		Expect(err).To(HaveOccurred())
+		_, err = access.NewAllowlist("localhost")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Listener", func() {
+
+		var allowlist *access.Allowlist
+		var server *httptest.Server
+		var rejected chan net.Addr
+
+		BeforeEach(func() {
+			var err error
+			allowlist, err = access.NewAllowlist("10.0.0.0/8")
+			Expect(err).ToNot(HaveOccurred())
+			rejected = make(chan net.Addr, 1)
+
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+			server.Listener = allowlist.Listener(server.Listener, func(addr net.Addr) {
+				rejected <- addr
+			})
+			server.Start()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should close the connections from other networks", func() {
+			_, err := http.Get(server.URL)
+			Expect(err).To(HaveOccurred())
+			Eventually(rejected).Should(Receive())
+		})
+
+		It("should accept the connections once their network is allowed", func() {
+			Expect(allowlist.Set("127.0.0.0/8", "::1")).To(Succeed())
+			resp, err := http.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal("ok"))
+		})
+	})
+})
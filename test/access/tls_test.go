@@ -0,0 +1,139 @@
+package access_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tokencard/contracts/v2/pkg/access"
+)
+
+// issue creates a certificate signed by parent, self-signed when parent is nil,
+// and writes it and its key to dir.
+func issue(dir, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, access.TLSFiles) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+
+	files := access.TLSFiles{
+		CertFile: filepath.Join(dir, name+".crt"),
+		KeyFile:  filepath.Join(dir, name+".key"),
+	}
+	Expect(ioutil.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(ioutil.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+	return cert, key, files
+}
+
+var _ = Describe("Mutual TLS", func() {
+
+	var dir string
+	var server *httptest.Server
+	var serverFiles, clientFiles, strangerFiles access.TLSFiles
+	var caFile string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "access")
+		Expect(err).ToNot(HaveOccurred())
+
+		ca, caKey, caFiles := issue(dir, "ca", &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}, nil, nil)
+		caFile = caFiles.CertFile
+		_, _, serverFiles = issue(dir, "server", &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}, ca, caKey)
+		_, _, clientFiles = issue(dir, "client", &x509.Certificate{
+			SerialNumber: big.NewInt(3),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, ca, caKey)
+		_, _, strangerFiles = issue(dir, "stranger", &x509.Certificate{
+			SerialNumber:          big.NewInt(4),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, nil, nil)
+
+		serverFiles.CAFile = caFile
+		tlsConfig, err := access.ServerConfig(serverFiles)
+		Expect(err).ToNot(HaveOccurred())
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}))
+		server.TLS = tlsConfig
+		server.StartTLS()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	get := func(files access.TLSFiles) (string, error) {
+		tlsConfig, err := access.ClientConfig(files)
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	It("should accept clients with a certificate issued by the CA", func() {
+		clientFiles.CAFile = caFile
+		name, err := get(clientFiles)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("client"))
+	})
+
+	It("should reject clients without a certificate", func() {
+		_, err := get(access.TLSFiles{CAFile: caFile})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject clients with a certificate of another CA", func() {
+		strangerFiles.CAFile = caFile
+		_, err := get(strangerFiles)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject servers not issued by the CA", func() {
+		clientFiles.CAFile = strangerFiles.CertFile
+		_, err := get(clientFiles)
+		Expect(err).To(HaveOccurred())
+	})
+})
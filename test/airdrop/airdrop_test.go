@@ -0,0 +1,135 @@
+package airdrop_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/airdrop"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("ReadRecipients", func() {
+
+	a, b := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+
+	It("reads the first column of a CSV file after its header", func() {
+		r, err := airdrop.ReadRecipients(strings.NewReader("address,name\n"+a.Hex()+",alice\n"+strings.ToLower(b.Hex())+",bob\n"), airdrop.CSV)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal([]common.Address{a, b}))
+	})
+
+	It("reads the strings and the objects of a JSON array", func() {
+		r, err := airdrop.ReadRecipients(strings.NewReader(`["`+a.Hex()+`", {"address": "`+b.Hex()+`"}]`), airdrop.JSON)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal([]common.Address{a, b}))
+	})
+
+	It("rejects the invalid lists", func() {
+		// The address of the EIP-55 examples, its last letter in the wrong case.
+		wrongChecksum := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"
+		for list, msg := range map[string]string{
+			"0x1234\n":                            "line 1",
+			a.Hex() + "\n" + a.Hex() + "\n":       "already listed on line 1",
+			common.Address{}.Hex() + "\n":         "zero address",
+			a.Hex() + "\n" + wrongChecksum + "\n": "line 2: " + wrongChecksum + " has an invalid checksum",
+		} {
+			_, err := airdrop.ReadRecipients(strings.NewReader(list), airdrop.CSV)
+			Expect(err).To(MatchError(ContainSubstring(msg)), list)
+		}
+	})
+})
+
+var _ = Describe("Airdrop", func() {
+
+	var p *provision.Provisioner
+	var dir, journal string
+	var recipients []common.Address
+	ctx := context.Background()
+
+	newAirdrop := func(backend *FailingChain) *airdrop.Airdrop {
+		var err error
+		p, err = provision.New(WalletDeployerAddress, WalletCacheAddress, backend, Chain, Controller.TransactOpts(), provision.NewMemoryAssignments())
+		Expect(err).ToNot(HaveOccurred())
+		return airdrop.New(p, journal)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "airdrop")
+		Expect(err).ToNot(HaveOccurred())
+		journal = filepath.Join(dir, "journal.jsonl")
+		recipients = []common.Address{Owner.Address(), RandomAccount.Address(), BankAccount.Address()}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("assigns a wallet to each recipient and reconciles them", func() {
+		a := newAirdrop(&FailingChain{TestChain: Chain, Left: -1})
+		var progress []int
+		a.Progress = func(done, total int, r airdrop.Result) {
+			Expect(total).To(Equal(3))
+			progress = append(progress, done)
+		}
+		report, err := a.Run(ctx, recipients)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress).To(Equal([]int{1, 2, 3}))
+		Expect(report.Complete()).To(BeTrue())
+		Expect(report.Assigned).To(Equal(3))
+	})
+
+	It("checks that the cache holds a wallet for each recipient", func() {
+		a := newAirdrop(&FailingChain{TestChain: Chain, Left: -1})
+		err := a.CheckSupply(ctx, recipients)
+		Expect(errors.Cause(err)).To(Equal(airdrop.ErrSupply))
+
+		p.Target = 3
+		_, err = p.Refill(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.CheckSupply(ctx, recipients)).To(Succeed())
+	})
+
+	It("resumes an interrupted airdrop", func() {
+		a := newAirdrop(&FailingChain{TestChain: Chain, Left: 1})
+		report, err := a.Run(ctx, recipients)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeFalse())
+		Expect(report.Assigned).To(Equal(1))
+		Expect(report.Missing).To(Equal(recipients[1:]))
+
+		a = newAirdrop(&FailingChain{TestChain: Chain, Left: -1})
+		var resumed []common.Address
+		a.Progress = func(done, total int, r airdrop.Result) {
+			resumed = append(resumed, r.Owner)
+		}
+		report, err = a.Run(ctx, recipients)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resumed).To(Equal(recipients[1:]))
+		Expect(report.Complete()).To(BeTrue())
+
+		report, err = a.Reconcile(ctx, recipients)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeTrue())
+	})
+
+	It("reports the wallets deployed outside of the journal", func() {
+		_, err := WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		a := newAirdrop(&FailingChain{TestChain: Chain, Left: -1})
+		report, err := a.Reconcile(ctx, recipients)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Mismatched).To(HaveLen(1))
+		Expect(report.Mismatched[0].Owner).To(Equal(Owner.Address()))
+		Expect(report.Missing).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,18 @@
+package alert_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAlertSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Alert Suite")
+}
+
+var _ = BeforeSuite(func() {
+	metrics.Enabled = true
+})
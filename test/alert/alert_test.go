@@ -0,0 +1,284 @@
+package alert_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/alert"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/webhook"
+)
+
+const rulesYAML = `
+interval: 10s
+receivers:
+- name: oncall
+  log: true
+- name: all
+  log: true
+routes:
+- receiver: oncall
+  severities: [critical]
+- receiver: all
+rules:
+- name: dao-changed
+  severity: critical
+  event:
+    contract: licence
+    name: UpdatedLicenceDAO
+    args: {_newDAO: "0x00000000000000000000000000000000000000aa"}
+- name: reverts
+  severity: warning
+  metric: tx/licence/updateLicenceAmount/reverted
+  increase: true
+  op: ">"
+  threshold: 0
+- name: slow
+  severity: info
+  metric: rpc/call
+  field: max
+  op: ">="
+  threshold: 100
+  for: 1m
+`
+
+func loadRules(s string) *alert.Rules {
+	rules, err := alert.LoadRules(strings.NewReader(s))
+	Expect(err).ToNot(HaveOccurred())
+	return rules
+}
+
+var _ = Describe("Rules", func() {
+
+	It("should load the rules", func() {
+		rules := loadRules(rulesYAML)
+		Expect(rules.Rules).To(HaveLen(3))
+		Expect(time.Duration(rules.Interval)).To(Equal(10 * time.Second))
+		Expect(time.Duration(rules.Rules[2].For)).To(Equal(time.Minute))
+		Expect(rules.MetricRules()).To(BeTrue())
+		Expect(rules.EventRules()).To(BeTrue())
+	})
+
+	invalid := []struct {
+		name, rules, err string
+	}{
+		{"unknown severity", "rules: [{name: a, severity: fatal, metric: m, op: '>'}]", `unknown severity "fatal"`},
+		{"unknown op", "rules: [{name: a, severity: info, metric: m, op: '=>'}]", `unknown op "=>"`},
+		{"unknown field", "rules: [{name: a, severity: info, metric: m, op: '>', field: p42}]", `unknown field "p42"`},
+		{"no condition", "rules: [{name: a, severity: info}]", "neither a metric nor an event"},
+		{"duplicate rule", "rules: [{name: a, severity: info, event: {}}, {name: a, severity: info, event: {}}]", `duplicate rule "a"`},
+		{"unknown receiver", "routes: [{receiver: pager}]", `unknown receiver "pager"`},
+		{"receiver without destination", "receivers: [{name: pager}]", "neither log nor webhook"},
+		{"unknown key", "rules: [{name: a, severity: info, event: {}, treshold: 1}]", "treshold"},
+	}
+	for _, tc := range invalid {
+		tc := tc
+		It("should reject a rule with "+tc.name, func() {
+			_, err := alert.LoadRules(strings.NewReader(tc.rules))
+			Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		})
+	}
+})
+
+var _ = Describe("Engine", func() {
+
+	var registry metrics.Registry
+	var engine *alert.Engine
+	var received map[string][]alert.Alert
+	var now time.Time
+
+	record := func(receiver string) alert.Notifier {
+		return alert.NotifierFunc(func(ctx context.Context, a alert.Alert) error {
+			received[receiver] = append(received[receiver], a)
+			return nil
+		})
+	}
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+		received = make(map[string][]alert.Alert)
+		now = time.Now()
+		engine = alert.NewEngine(loadRules(rulesYAML), map[string]alert.Notifier{
+			"oncall": record("oncall"),
+			"all":    record("all"),
+		}, registry, nil)
+	})
+
+	It("should not alert while the conditions do not hold", func() {
+		engine.Evaluate(context.Background(), now)
+		Expect(received).To(BeEmpty())
+	})
+
+	When("a counter increases", func() {
+
+		var reverted metrics.Counter
+
+		BeforeEach(func() {
+			reverted = metrics.NewCounter()
+			Expect(registry.Register("tx/licence/updateLicenceAmount/reverted", reverted)).To(Succeed())
+			reverted.Inc(2)
+			engine.Evaluate(context.Background(), now)
+		})
+
+		It("should fire once", func() {
+			Expect(received["all"]).To(HaveLen(1))
+			a := received["all"][0]
+			Expect(a.Rule).To(Equal("reverts"))
+			Expect(a.State).To(Equal(alert.Firing))
+			Expect(a.Value).To(Equal(2.0))
+		})
+
+		It("should only route to the receivers of its severity", func() {
+			Expect(received["oncall"]).To(BeEmpty())
+		})
+
+		It("should resolve when the counter stops increasing", func() {
+			engine.Evaluate(context.Background(), now.Add(10*time.Second))
+			Expect(received["all"]).To(HaveLen(2))
+			Expect(received["all"][1].Resolved()).To(BeTrue())
+		})
+
+		It("should keep firing while the counter increases", func() {
+			reverted.Inc(1)
+			engine.Evaluate(context.Background(), now.Add(10*time.Second))
+			Expect(received["all"]).To(HaveLen(1))
+		})
+	})
+
+	When("a condition must hold for a duration", func() {
+
+		BeforeEach(func() {
+			metrics.GetOrRegisterTimer("rpc/call", registry).Update(200 * time.Nanosecond)
+		})
+
+		It("should only fire once the duration elapsed", func() {
+			engine.Evaluate(context.Background(), now)
+			Expect(received).To(BeEmpty())
+			engine.Evaluate(context.Background(), now.Add(30*time.Second))
+			Expect(received).To(BeEmpty())
+			engine.Evaluate(context.Background(), now.Add(time.Minute))
+			Expect(received["all"]).To(HaveLen(1))
+			Expect(received["all"][0].Rule).To(Equal("slow"))
+			Expect(received["all"][0].Value).To(Equal(200.0))
+		})
+	})
+
+	Describe("HandleEvents", func() {
+
+		event := func(dao string) indexer.Event {
+			return indexer.Event{
+				Contract: "licence",
+				Name:     "UpdatedLicenceDAO",
+				Args:     map[string]interface{}{"_newDAO": common.HexToAddress(dao)},
+			}
+		}
+
+		It("should fire for the matching events", func() {
+			Expect(engine.HandleEvents(context.Background(), []indexer.Event{event("0xaa"), event("0xbb")})).To(Succeed())
+			Expect(received["oncall"]).To(HaveLen(1))
+			Expect(received["all"]).To(HaveLen(1))
+			a := received["oncall"][0]
+			Expect(a.Rule).To(Equal("dao-changed"))
+			Expect(a.Severity).To(Equal(alert.Critical))
+			Expect(a.Event.Args["_newDAO"]).To(Equal(common.HexToAddress("0xaa").Hex()))
+		})
+
+		It("should resolve the alerts of the events removed by a reorganization", func() {
+			removed := event("0xaa")
+			removed.Removed = true
+			Expect(engine.HandleEvents(context.Background(), []indexer.Event{removed})).To(Succeed())
+			Expect(received["oncall"]).To(HaveLen(1))
+			Expect(received["oncall"][0].Resolved()).To(BeTrue())
+		})
+	})
+
+	Describe("SetRules", func() {
+
+		It("should evaluate the new rules", func() {
+			engine.SetRules(loadRules(`
+receivers: [{name: all, log: true}]
+routes: [{receiver: all}]
+rules: [{name: low, severity: info, metric: rpc/call, field: count, op: "==", threshold: 0}]
+`), map[string]alert.Notifier{"all": record("all")})
+			engine.Evaluate(context.Background(), now)
+			Expect(received["all"]).To(HaveLen(1))
+			Expect(received["all"][0].Rule).To(Equal("low"))
+		})
+	})
+
+	Describe("Send", func() {
+
+		It("should route the alert to the receivers", func() {
+			err := engine.Send(context.Background(), alert.Alert{Rule: "canary", Severity: alert.Critical, State: alert.Firing})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(received["oncall"]).To(HaveLen(1))
+			Expect(received["all"]).To(HaveLen(1))
+		})
+
+		It("should fail when a notification fails", func() {
+			engine.SetRules(loadRules(rulesYAML), map[string]alert.Notifier{
+				"oncall": record("oncall"),
+				"all": alert.NotifierFunc(func(ctx context.Context, a alert.Alert) error {
+					return errors.New("unreachable")
+				}),
+			})
+			err := engine.Send(context.Background(), alert.Alert{Rule: "canary", Severity: alert.Critical, State: alert.Firing})
+			Expect(err).To(MatchError(ContainSubstring("unreachable")))
+			Expect(received["oncall"]).To(HaveLen(1))
+		})
+
+		It("should fail when no receiver is routed the alert", func() {
+			engine.SetRules(loadRules(`
+receivers: [{name: oncall, log: true}]
+routes: [{receiver: oncall, severities: [critical]}]
+`), map[string]alert.Notifier{"oncall": record("oncall")})
+			err := engine.Send(context.Background(), alert.Alert{Rule: "canary", Severity: alert.Info, State: alert.Firing})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("WebhookNotifier", func() {
+
+	It("should post signed alerts", func() {
+		var got alert.Alert
+		var verified bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).ToNot(HaveOccurred())
+			ts, err := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+			Expect(err).ToNot(HaveOccurred())
+			verified = webhook.Verify("secret", ts, body, r.Header.Get(webhook.SignatureHeader))
+			Expect(json.Unmarshal(body, &got)).To(Succeed())
+		}))
+		defer srv.Close()
+
+		n := alert.WebhookNotifier{URL: srv.URL, Secret: "secret"}
+		err := n.Notify(context.Background(), alert.Alert{Rule: "reverts", Severity: alert.Warning, State: alert.Firing, Value: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(verified).To(BeTrue())
+		Expect(got.Rule).To(Equal("reverts"))
+		Expect(got.Value).To(Equal(1.0))
+	})
+
+	It("should fail on an error response", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		err := alert.WebhookNotifier{URL: srv.URL}.Notify(context.Background(), alert.Alert{})
+		Expect(err).To(MatchError(ContainSubstring("500")))
+	})
+})
@@ -0,0 +1,119 @@
+package analytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/analytics"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// times mines a block on the first day of each month of 2020, block 1 in
+// January.
+type times struct{}
+
+func (times) BlockTime(ctx context.Context, number uint64) (time.Time, error) {
+	return time.Date(2020, time.Month(number), 1, 12, 0, 0, 0, time.UTC), nil
+}
+
+var _ = Describe("Analytics", func() {
+
+	var store *indexer.MemoryStore
+	ether := common.Address{}.Hex()
+	a, b, c := common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xc")
+	ctx := context.Background()
+
+	fee := func(month uint64, from common.Address, amount int64) indexer.Event {
+		return indexer.Event{
+			Contract:    "licence",
+			Name:        analytics.FeeEvent,
+			BlockNumber: month,
+			Args: map[string]interface{}{
+				"_from":   from,
+				"_to":     common.HexToAddress("0x1"),
+				"_asset":  common.Address{},
+				"_amount": big.NewInt(amount),
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		store = indexer.NewMemoryStore()
+		Expect(store.Append(4, []indexer.Event{
+			fee(1, a, 10),
+			fee(1, b, 20),
+			fee(2, a, 10),
+			fee(2, c, 5),
+			fee(3, a, 10),
+			fee(4, c, 5),
+		})).To(Succeed())
+	})
+
+	It("groups the wallets in cohorts by the month of their first fee", func() {
+		r, err := analytics.Compute(ctx, store, times{}, analytics.Options{Now: time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Cohorts).To(HaveLen(2))
+
+		jan := r.Cohorts[0]
+		Expect(jan.Month).To(Equal("2020-01"))
+		Expect(jan.Wallets).To(Equal(2))
+		Expect(jan.Retention).To(Equal([]float64{1, 0.5, 0.5, 0}))
+		Expect(jan.Fees).To(Equal(map[string]string{ether: "50"}))
+		Expect(jan.FeesPerWallet).To(Equal(map[string]string{ether: "25"}))
+
+		feb := r.Cohorts[1]
+		Expect(feb.Month).To(Equal("2020-02"))
+		Expect(feb.Retention).To(Equal([]float64{1, 0, 1}))
+		Expect(feb.Fees).To(Equal(map[string]string{ether: "10"}))
+	})
+
+	It("counts the wallets reaching each stage of the funnel", func() {
+		r, err := analytics.Compute(ctx, store, times{}, analytics.Options{Now: time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Funnel).To(Equal([]analytics.Stage{
+			{Name: analytics.StagePaid, Wallets: 3},
+			{Name: analytics.StageReturned, Wallets: 2},
+			{Name: analytics.StageActive, Wallets: 1},
+		}))
+	})
+
+	It("caps the months of the retention", func() {
+		r, err := analytics.Compute(ctx, store, times{}, analytics.Options{Now: time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC), MaxMonths: 2})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Cohorts[0].Retention).To(Equal([]float64{1, 0.5}))
+	})
+
+	It("reads the fees stored formatted", func() {
+		s := indexer.NewMemoryStore()
+		e := fee(1, a, 10)
+		for k, v := range e.Args {
+			e.Args[k] = indexer.FormatArg(v)
+		}
+		Expect(s.Append(1, []indexer.Event{e})).To(Succeed())
+		r, err := analytics.Compute(ctx, s, times{}, analytics.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Cohorts[0].Fees).To(Equal(map[string]string{ether: "10"}))
+	})
+
+	It("serves the report", func() {
+		rec := httptest.NewRecorder()
+		analytics.NewHandler(store, times{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics?months=1", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var r analytics.Report
+		Expect(json.Unmarshal(rec.Body.Bytes(), &r)).To(Succeed())
+		Expect(r.Cohorts).To(HaveLen(2))
+		Expect(r.Cohorts[0].Retention).To(Equal([]float64{1}))
+
+		rec = httptest.NewRecorder()
+		analytics.NewHandler(store, times{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics?months=x", nil))
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+})
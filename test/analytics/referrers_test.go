@@ -0,0 +1,107 @@
+package analytics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/analytics"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+var _ = Describe("Referrers", func() {
+
+	var store *indexer.MemoryStore
+	var attribution analytics.Attribution
+	ether := common.Address{}.Hex()
+	token := common.HexToAddress("0x7").Hex()
+	a, b, c, d := common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xc"), common.HexToAddress("0xd")
+
+	fee := func(block uint64, from common.Address, asset string, amount int64) indexer.Event {
+		return indexer.Event{
+			Contract:    "licence",
+			Name:        analytics.FeeEvent,
+			BlockNumber: block,
+			Args: map[string]interface{}{
+				"_from":   from,
+				"_to":     common.HexToAddress("0x1"),
+				"_asset":  common.HexToAddress(asset),
+				"_amount": big.NewInt(amount),
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		store = indexer.NewMemoryStore()
+		Expect(store.Append(3, []indexer.Event{
+			fee(1, a, ether, 10),
+			fee(2, a, token, 3),
+			fee(2, b, ether, 20),
+			fee(3, d, ether, 100),
+		})).To(Succeed())
+
+		var err error
+		attribution, err = analytics.ReadAttribution(strings.NewReader("wallet,referrer\n" +
+			a.Hex() + ",partner-a\n" +
+			b.Hex() + ",partner-b\n" +
+			c.Hex() + ",partner-a\n"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("computes the conversion and the fees of each referrer", func() {
+		referrers, err := analytics.ComputeReferrers(store, attribution)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(Equal([]analytics.Referrer{
+			{Referrer: "partner-a", Wallets: 2, Activated: 1, Conversion: 0.5, Fees: map[string]string{ether: "10", token: "3"}},
+			{Referrer: "partner-b", Wallets: 1, Activated: 1, Conversion: 1, Fees: map[string]string{ether: "20"}},
+		}))
+	})
+
+	It("writes the statistics as CSV with a column of fees by asset", func() {
+		referrers, err := analytics.ComputeReferrers(store, attribution)
+		Expect(err).ToNot(HaveOccurred())
+		var buf bytes.Buffer
+		Expect(analytics.WriteReferrersCSV(&buf, referrers)).To(Succeed())
+		Expect(buf.String()).To(Equal(
+			"referrer,wallets,activated,conversion,fees_" + ether + ",fees_" + token + "\n" +
+				"partner-a,2,1,0.5,10,3\n" +
+				"partner-b,1,1,1,20,0\n"))
+	})
+
+	It("serves the statistics as JSON and CSV", func() {
+		h := analytics.NewReferrersHandler(store, attribution)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/referrers", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var referrers []analytics.Referrer
+		Expect(json.Unmarshal(rec.Body.Bytes(), &referrers)).To(Succeed())
+		Expect(referrers).To(HaveLen(2))
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/referrers?format=csv", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("text/csv"))
+		Expect(rec.Body.String()).To(HavePrefix("referrer,wallets,activated,conversion,"))
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/referrers?format=xml", nil))
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects the wallets attributed to several referrers", func() {
+		_, err := analytics.ReadAttribution(strings.NewReader("wallet,referrer\n" + a.Hex() + ",partner-a\n" + a.Hex() + ",partner-b\n"))
+		Expect(err).To(MatchError(ContainSubstring("attributed to both partner-a and partner-b")))
+	})
+
+	It("rejects an attribution without its header", func() {
+		_, err := analytics.ReadAttribution(strings.NewReader(a.Hex() + ",partner-a\n"))
+		Expect(err).To(MatchError(ContainSubstring("want wallet,referrer")))
+	})
+})
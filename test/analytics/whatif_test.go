@@ -0,0 +1,138 @@
+package analytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/analytics"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// licenceAmount is the current licence amount.
+type licenceAmount int64
+
+func (a licenceAmount) LicenceAmountScaled(opts *bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(int64(a)), nil
+}
+
+var _ = Describe("LicenceWhatIf", func() {
+
+	var store *indexer.MemoryStore
+	ether := common.Address{}.Hex()
+	token := common.HexToAddress("0x70")
+	tkn := common.HexToAddress("0x7c")
+	ctx := context.Background()
+	now := time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	// load returns the events of a load of amount by a wallet in the given
+	// month with the licence amount, 10 being 1%.
+	load := func(tx byte, month uint64, asset common.Address, amount, licence int64) []indexer.Event {
+		net := amount * 1000 / (licence + 1000)
+		event := func(name string, amount int64, index uint) indexer.Event {
+			return indexer.Event{
+				Contract:    "licence",
+				Name:        name,
+				BlockNumber: month,
+				TxHash:      common.BytesToHash([]byte{tx}),
+				LogIndex:    index,
+				Args: map[string]interface{}{
+					"_from":   common.HexToAddress("0xa"),
+					"_to":     common.HexToAddress("0x1"),
+					"_asset":  asset,
+					"_amount": big.NewInt(amount),
+				},
+			}
+		}
+		return []indexer.Event{
+			event(analytics.FeeEvent, amount-net, 0),
+			event(analytics.FloatEvent, net, 1),
+		}
+	}
+
+	BeforeEach(func() {
+		store = indexer.NewMemoryStore()
+		var events []indexer.Event
+		// Before the three months of history.
+		events = append(events, load(1, 2, common.Address{}, 1010000, 10)...)
+		events = append(events, load(2, 4, common.Address{}, 1010000, 10)...)
+		events = append(events, load(3, 5, common.Address{}, 2020000, 10)...)
+		events = append(events, load(4, 6, token, 5050, 50)...)
+		// TKN pays no fee, only the net amount is loaded.
+		events = append(events, load(5, 6, tkn, 1000, 10)[1])
+		Expect(store.Append(6, events)).To(Succeed())
+	})
+
+	It("reports the fees of the recent loads with the proposed amount", func() {
+		impact, err := analytics.LicenceWhatIf(ctx, store, times{}, licenceAmount(10), 20, analytics.WhatIfOptions{Now: now})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.CurrentAmount).To(Equal(uint64(10)))
+		Expect(impact.ProposedAmount).To(Equal(uint64(20)))
+		Expect(impact.Months).To(Equal(3))
+		Expect(impact.Horizon).To(Equal(12))
+		Expect(impact.Loads).To(Equal(3))
+		Expect(impact.Assets).To(HaveLen(2))
+
+		eth := impact.Assets[0]
+		Expect(eth.Asset).To(Equal(ether))
+		Expect(eth.Loads).To(Equal(2))
+		Expect(eth.Loaded).To(Equal("3030000"))
+		Expect(eth.Fees).To(Equal("30000"))
+		Expect(eth.CurrentFees).To(Equal("30000"))
+		// 3030000 - 3030000 * 1000 / 1020
+		Expect(eth.ProposedFees).To(Equal("59412"))
+		Expect(eth.Change).To(Equal("29412"))
+		Expect(eth.ProjectedCurrent).To(Equal("120000"))
+		Expect(eth.ProjectedProposed).To(Equal("237648"))
+		Expect(eth.ProjectedChange).To(Equal("117648"))
+
+		// Loaded with a licence amount of 5%, valued at the current one.
+		tok := impact.Assets[1]
+		Expect(tok.Asset).To(Equal(token.Hex()))
+		Expect(tok.Fees).To(Equal("241"))
+		Expect(tok.CurrentFees).To(Equal("50"))
+		Expect(tok.ProposedFees).To(Equal("100"))
+	})
+
+	It("reports lower fees for a lower amount", func() {
+		impact, err := analytics.LicenceWhatIf(ctx, store, times{}, licenceAmount(10), 1, analytics.WhatIfOptions{Now: now, Months: 1, Horizon: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Loads).To(Equal(1))
+		Expect(impact.Assets[0].Asset).To(Equal(token.Hex()))
+		Expect(impact.Assets[0].Change).To(Equal("-44"))
+		Expect(impact.Assets[0].ProjectedChange).To(Equal("-44"))
+	})
+
+	It("rejects the amounts the licence rejects", func() {
+		for _, amount := range []uint64{0, 1001} {
+			_, err := analytics.LicenceWhatIf(ctx, store, times{}, licenceAmount(10), amount, analytics.WhatIfOptions{Now: now})
+			Expect(errors.Cause(err)).To(Equal(analytics.ErrLicenceAmountOutOfRange))
+		}
+	})
+
+	It("is served as JSON", func() {
+		handler := analytics.NewLicenceWhatIfHandler(store, times{}, licenceAmount(10))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/licence-amount?proposed=20&months=120", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var impact analytics.LicenceImpact
+		Expect(json.Unmarshal(rec.Body.Bytes(), &impact)).To(Succeed())
+		Expect(impact.ProposedAmount).To(Equal(uint64(20)))
+		Expect(impact.Loads).To(Equal(4))
+
+		for _, query := range []string{"", "?proposed=x", "?proposed=0", "?proposed=20&horizon=-1"} {
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/licence-amount"+query, nil))
+			Expect(rec.Code).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+})
@@ -0,0 +1,52 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/api"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestAPISuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API Suite")
+}
+
+const apiKey = "secret"
+
+var Server *httptest.Server
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+
+	// The licence amount can only be updated by the licence DAO.
+	tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Commit()
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+
+	handler, err := api.New(Backend, api.Config{
+		Licence:        LicenceAddress,
+		TokenWhitelist: TokenWhitelistAddress,
+		Controller:     ControllerContractAddress,
+		APIKeys:        []string{apiKey},
+		TransactOpts:   RandomAccount.TransactOpts(),
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Server = httptest.NewServer(handler)
+})
+
+var _ = AfterEach(func() {
+	Server.Close()
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/api"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func getJSON(path string, v interface{}) int {
+	res, err := http.Get(Server.URL + path)
+	Expect(err).ToNot(HaveOccurred())
+	defer res.Body.Close()
+	Expect(json.NewDecoder(res.Body).Decode(v)).To(Succeed())
+	return res.StatusCode
+}
+
+func postAmount(amount, key string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, Server.URL+"/licence/amount", strings.NewReader(`{"amount": "`+amount+`"}`))
+	Expect(err).ToNot(HaveOccurred())
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	res, err := http.DefaultClient.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+	return res
+}
+
+var _ = Describe("API", func() {
+
+	It("should return the licence configuration", func() {
+		var l api.LicenceResponse
+		Expect(getJSON("/licence", &l)).To(Equal(http.StatusOK))
+		Expect(l.AmountScaled).To(Equal("10"))
+		Expect(l.TokenHolder).To(Equal(TokenHolderAddress))
+		Expect(l.CryptoFloat).To(Equal(CryptoFloatAddress))
+	})
+
+	It("should list the whitelisted tokens", func() {
+		var tokens []common.Address
+		Expect(getJSON("/tokens", &tokens)).To(Equal(http.StatusOK))
+		Expect(tokens).To(ContainElement(TKNBurnerAddress))
+		Expect(tokens).To(ContainElement(StablecoinAddress))
+	})
+
+	It("should return a whitelisted token", func() {
+		var t api.TokenResponse
+		Expect(getJSON("/tokens/"+TKNBurnerAddress.Hex(), &t)).To(Equal(http.StatusOK))
+		Expect(t.Symbol).To(Equal("TKN"))
+		Expect(t.Magnitude).To(Equal("100000000"))
+	})
+
+	It("should not find a token that is not whitelisted", func() {
+		var e map[string]string
+		Expect(getJSON("/tokens/"+RandomAccount.Address().Hex(), &e)).To(Equal(http.StatusNotFound))
+	})
+
+	It("should reject an invalid address", func() {
+		var e map[string]string
+		Expect(getJSON("/controller/0x123", &e)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should return the controller roles of an address", func() {
+		var r api.RolesResponse
+		Expect(getJSON("/controller/"+ControllerAdmin.Address().Hex(), &r)).To(Equal(http.StatusOK))
+		Expect(r.Admin).To(BeTrue())
+		Expect(r.Controller).To(BeFalse())
+		Expect(r.Owner).To(BeFalse())
+	})
+
+	When("the licence amount is updated without an API key", func() {
+		It("should be unauthorized", func() {
+			res := postAmount("20", "")
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("the licence amount is updated with a wrong API key", func() {
+		It("should be unauthorized", func() {
+			res := postAmount("20", "wrong")
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	When("the licence amount is updated with a valid API key", func() {
+		BeforeEach(func() {
+			res := postAmount("20", apiKey)
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusAccepted))
+			Backend.Commit()
+		})
+
+		It("should update the licence amount", func() {
+			var l api.LicenceResponse
+			Expect(getJSON("/licence", &l)).To(Equal(http.StatusOK))
+			Expect(l.AmountScaled).To(Equal("20"))
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestAuditSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Audit Suite")
+}
+
+var _ = BeforeEach(func() {
+	Expect(InitializeBackend()).To(Succeed())
+})
+
+var _ = AfterEach(func() {
+	Expect(Backend.Close()).To(Succeed())
+})
+
+func isSuccessful(tx *types.Transaction) bool {
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	return r.Status == types.ReceiptStatusSuccessful
+}
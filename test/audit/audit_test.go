@@ -0,0 +1,86 @@
+package audit_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/audit"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+// chainID is the chain ID the simulated backend is registered under.
+var chainID = big.NewInt(1337)
+
+var _ = Describe("Audit", func() {
+
+	var reg *registry.Registry
+	var backends map[uint64]bind.ContractBackend
+	var roster *audit.Roster
+
+	BeforeEach(func() {
+		reg = registry.New()
+		Expect(reg.Set(chainID, "controller", ControllerContractAddress)).To(Succeed())
+		backends = map[uint64]bind.ContractBackend{chainID.Uint64(): Backend}
+		roster = &audit.Roster{
+			Owners:      []common.Address{ControllerOwner.Address()},
+			Admins:      []common.Address{ControllerAdmin.Address()},
+			Controllers: []common.Address{Controller.Address()},
+		}
+	})
+
+	It("should read the controller roles", func() {
+		admins, controllers, err := audit.ReadRoles(context.Background(), ControllerContractAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(admins).To(Equal([]common.Address{ControllerAdmin.Address()}))
+		Expect(controllers).To(Equal([]common.Address{Controller.Address()}))
+	})
+
+	It("should find the contracts compliant with the roster", func() {
+		networks := audit.Audit(context.Background(), reg, backends, roster)
+		Expect(networks).To(HaveLen(1))
+		Expect(networks[0].ChainID).To(BeEquivalentTo(1337))
+		Expect(networks[0].Ownership).To(HaveLen(1))
+		Expect(networks[0].Ownership[0].Owner).To(Equal(ControllerOwner.Address()))
+		Expect(networks[0].Controller.Admins).To(Equal(roster.Admins))
+		Expect(networks[0].OK()).To(BeTrue())
+	})
+
+	When("a controller is removed", func() {
+
+		BeforeEach(func() {
+			tx, err := ControllerContract.RemoveController(ControllerAdmin.TransactOpts(), Controller.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should report the missing controller", func() {
+			networks := audit.Audit(context.Background(), reg, backends, roster)
+			Expect(networks[0].Controller.Controllers).To(BeEmpty())
+			Expect(networks[0].Controller.Problems).To(Equal([]string{"approved controller " + Controller.Address().Hex() + " is missing"}))
+			Expect(networks[0].OK()).To(BeFalse())
+		})
+	})
+
+	It("should report the accounts which are not approved", func() {
+		roster.Admins = nil
+		roster.Owners = []common.Address{RandomAccount.Address()}
+		networks := audit.Audit(context.Background(), reg, backends, roster)
+		Expect(networks[0].Controller.Problems).To(Equal([]string{"admin " + ControllerAdmin.Address().Hex() + " is not approved"}))
+		Expect(networks[0].Ownership[0].Problems).To(Equal([]string{"owner " + ControllerOwner.Address().Hex() + " is not expected"}))
+	})
+
+	It("should report the networks without a backend", func() {
+		Expect(reg.Set(big.NewInt(3), "controller", ControllerContractAddress)).To(Succeed())
+		networks := audit.Audit(context.Background(), reg, backends, roster)
+		Expect(networks).To(HaveLen(2))
+		Expect(networks[0].ChainID).To(BeEquivalentTo(3))
+		Expect(networks[0].Err).To(Equal("no backend for chain 3"))
+		Expect(networks[1].OK()).To(BeTrue())
+	})
+})
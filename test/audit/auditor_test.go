@@ -0,0 +1,120 @@
+package audit_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/audit"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Auditor", func() {
+
+	var key *ecdsa.PrivateKey
+	var auditor *audit.Auditor
+	var report *audit.Report
+
+	BeforeEach(func() {
+		var err error
+		key, err = crypto.GenerateKey()
+		Expect(err).ToNot(HaveOccurred())
+		dir, err := ioutil.TempDir("", "audit")
+		Expect(err).ToNot(HaveOccurred())
+
+		reg := registry.New()
+		Expect(reg.Set(chainID, "controller", ControllerContractAddress)).To(Succeed())
+		auditor = &audit.Auditor{
+			Registry: reg,
+			Backends: map[uint64]bind.ContractBackend{chainID.Uint64(): Backend},
+			Roster: &audit.Roster{
+				Owners:      []common.Address{ControllerOwner.Address()},
+				Admins:      []common.Address{ControllerAdmin.Address()},
+				Controllers: []common.Address{Controller.Address()},
+			},
+			Sign: signing.KeySigner(key),
+			Dir:  dir,
+		}
+		report, err = auditor.Check(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(auditor.Dir)
+	})
+
+	It("should write a compliant report signed by the key", func() {
+		Expect(report.Compliant()).To(BeTrue())
+		Expect(report.Changes).To(BeEmpty())
+		Expect(report.Verify(crypto.PubkeyToAddress(key.PublicKey))).To(Succeed())
+
+		latest, err := audit.LatestReport(auditor.Dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(latest).To(Equal(report))
+		Expect(latest.Verify(crypto.PubkeyToAddress(key.PublicKey))).To(Succeed())
+	})
+
+	It("should detect a tampered report", func() {
+		report.Networks[0].Controller.Admins = nil
+		Expect(report.Verify(crypto.PubkeyToAddress(key.PublicKey))).To(MatchError(ContainSubstring(signing.ErrInvalidSignature.Error())))
+	})
+
+	When("a controller is removed", func() {
+
+		BeforeEach(func() {
+			tx, err := ControllerContract.RemoveController(ControllerAdmin.TransactOpts(), Controller.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+			// The reports are named after the second they were made at.
+			Expect(os.Rename(
+				filepath.Join(auditor.Dir, "audit-"+report.Time.Format("20060102T150405Z")+".json"),
+				filepath.Join(auditor.Dir, "audit-20000101T000000Z.json"),
+			)).To(Succeed())
+		})
+
+		It("should list the change since the previous report", func() {
+			next, err := auditor.Check(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.Compliant()).To(BeFalse())
+			Expect(next.Changes).To(Equal([]audit.Change{{
+				ChainID:  1337,
+				Contract: "controller",
+				Field:    "controllers",
+				Old:      Controller.Address().Hex(),
+			}}))
+		})
+
+		It("should refuse a previous report signed by another key", func() {
+			other, err := crypto.GenerateKey()
+			Expect(err).ToNot(HaveOccurred())
+			auditor.Sign = signing.KeySigner(other)
+			_, err = auditor.Check(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("verifying previous report")))
+		})
+	})
+})
+
+var _ = Describe("Diff", func() {
+
+	It("should list the changed, added and removed fields", func() {
+		prev := []audit.Network{{ChainID: 1, Controller: &audit.Roles{Admins: []common.Address{common.HexToAddress("0xa")}, Controllers: []common.Address{}}}}
+		next := []audit.Network{
+			{ChainID: 1, Controller: &audit.Roles{Admins: []common.Address{common.HexToAddress("0xb")}, Controllers: []common.Address{}}},
+			{ChainID: 3, Err: "no backend for chain 3"},
+		}
+		Expect(audit.Diff(prev, next)).To(Equal([]audit.Change{
+			{ChainID: 1, Contract: "controller", Field: "admins", Old: common.HexToAddress("0xa").Hex(), New: common.HexToAddress("0xb").Hex()},
+			{ChainID: 3, Field: "error", New: "no backend for chain 3"},
+		}))
+	})
+})
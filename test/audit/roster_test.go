@@ -0,0 +1,39 @@
+package audit_test
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/audit"
+)
+
+var _ = Describe("LoadRoster", func() {
+
+	It("should read the approved accounts", func() {
+		roster, err := audit.LoadRoster(strings.NewReader(`
+owners: ["0x000000000000000000000000000000000000000a"]
+locked: true
+admins: ["0x000000000000000000000000000000000000000b"]
+controllers: ["0x000000000000000000000000000000000000000c", "0x000000000000000000000000000000000000000d"]
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(roster).To(Equal(&audit.Roster{
+			Owners:      []common.Address{common.HexToAddress("0xa")},
+			Locked:      true,
+			Admins:      []common.Address{common.HexToAddress("0xb")},
+			Controllers: []common.Address{common.HexToAddress("0xc"), common.HexToAddress("0xd")},
+		}))
+	})
+
+	It("should reject an invalid address", func() {
+		_, err := audit.LoadRoster(strings.NewReader(`admins: ["0xb"]`))
+		Expect(err).To(MatchError(`address "0xb" of admins is not valid`))
+	})
+
+	It("should reject an unknown field", func() {
+		_, err := audit.LoadRoster(strings.NewReader(`owner: "0x000000000000000000000000000000000000000a"`))
+		Expect(err).To(MatchError(ContainSubstring("decoding roster")))
+	})
+})
@@ -0,0 +1,51 @@
+package backfill_test
+
+import (
+	"context"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestBackfillSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Backfill Suite")
+}
+
+// chain is the test chain refusing, like the providers, the filters spanning
+// more than maxRange blocks when it is set, and all of them when it is down.
+type chain struct {
+	*shared.TestChain
+	maxRange uint64
+	down     bool
+	filters  int
+}
+
+func (c *chain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.filters++
+	if c.down {
+		return nil, errors.New("provider unavailable")
+	}
+	if c.maxRange > 0 && q.ToBlock.Uint64()-q.FromBlock.Uint64()+1 > c.maxRange {
+		return nil, errors.New("query timeout exceeded")
+	}
+	return c.TestChain.FilterLogs(ctx, q)
+}
+
+var Chain *chain
+
+var _ = BeforeEach(func() {
+	err := shared.InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = &chain{TestChain: shared.NewTestChain(shared.Backend, shared.MineManually)}
+})
+
+var _ = AfterEach(func() {
+	err := shared.Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,264 @@
+package backfill_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/backfill"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Backfill", func() {
+
+	var token indexer.Contract
+	var start, end uint64
+	var checkpoint *backfill.MemoryCheckpoint
+	var handled []indexer.Event
+	var batches int
+	ctx := context.Background()
+
+	collect := indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+		handled = append(handled, events...)
+		batches++
+		return nil
+	})
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(mocks.TokenABI))
+		Expect(err).ToNot(HaveOccurred())
+		token = indexer.Contract{Name: "token", Address: ERC20Contract1Address, ABI: parsed}
+		checkpoint = &backfill.MemoryCheckpoint{}
+		handled = nil
+		batches = 0
+
+		tx, err := ERC20Contract1.Credit(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(100))
+		Chain.CommitTx(tx, err)
+		start = Chain.Head().Uint64() + 1
+
+		for i := 0; i < 6; i++ {
+			tx, err = ERC20Contract1.Transfer(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(int64(i+1)))
+			Chain.CommitTx(tx, err)
+		}
+		tx, err = ERC20Contract1.Approve(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(1))
+		Chain.CommitTx(tx, err)
+		end = Chain.Head().Uint64()
+	})
+
+	newBackfill := func(handler indexer.Handler) *backfill.Backfill {
+		b := backfill.New(Chain, checkpoint, handler, token)
+		b.StartBlock = start
+		b.RangeSize = 2
+		return b
+	}
+
+	It("should handle the events of every range up to the head", func() {
+		Expect(newBackfill(collect).Run(ctx)).To(Succeed())
+
+		Expect(handled).To(HaveLen(7))
+		Expect(batches).To(Equal(4))
+		for i, e := range handled[:6] {
+			Expect(e.Name).To(Equal("Transfer"))
+			Expect(e.Args["amount"]).To(Equal(big.NewInt(int64(i + 1))))
+		}
+		Expect(handled[6].Name).To(Equal("Approval"))
+
+		next, ok, err := checkpoint.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal(end + 1))
+	})
+
+	It("should stop at the end block", func() {
+		b := newBackfill(collect)
+		b.EndBlock = start + 2
+		Expect(b.Run(ctx)).To(Succeed())
+		Expect(handled).To(HaveLen(3))
+	})
+
+	It("should only scan the given events", func() {
+		b := newBackfill(collect)
+		b.Events = []string{"Transfer"}
+		Expect(b.Run(ctx)).To(Succeed())
+		Expect(handled).To(HaveLen(6))
+	})
+
+	It("should fail on the events of no contract", func() {
+		b := newBackfill(collect)
+		b.Events = []string{"Referred"}
+		Expect(b.Run(ctx)).To(MatchError(ContainSubstring("no contract emits Referred events")))
+	})
+
+	It("should resume from the checkpoint after a failure", func() {
+		calls := 0
+		failing := indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+			calls++
+			if calls == 2 {
+				return errors.New("crashed")
+			}
+			return collect(ctx, events)
+		})
+		Expect(newBackfill(failing).Run(ctx)).To(MatchError(ContainSubstring("crashed")))
+		Expect(handled).To(HaveLen(2))
+		next, _, err := checkpoint.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(next).To(Equal(start + 2))
+
+		Expect(newBackfill(collect).Run(ctx)).To(Succeed())
+		Expect(handled).To(HaveLen(7))
+		Expect(handled[2].Args["amount"]).To(Equal(big.NewInt(3)))
+	})
+
+	It("should split the ranges refused by the provider", func() {
+		Chain.maxRange = 3
+		b := newBackfill(collect)
+		b.RangeSize = 7
+		Expect(b.Run(ctx)).To(Succeed())
+		Expect(handled).To(HaveLen(7))
+		Expect(Chain.filters).To(BeNumerically(">", 3))
+	})
+
+	It("should fail when the provider refuses a single block", func() {
+		Chain.down = true
+		Expect(newBackfill(collect).Run(ctx)).To(MatchError(ContainSubstring("provider unavailable")))
+		Expect(Chain.filters).To(Equal(2))
+		_, ok, err := checkpoint.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+})
+
+// crashingStore is a store failing to append after a number of writes.
+type crashingStore struct {
+	indexer.Store
+	writes int
+}
+
+func (s *crashingStore) Append(head uint64, events []indexer.Event) error {
+	if s.writes == 0 {
+		return errors.New("crashed")
+	}
+	s.writes--
+	return s.Store.Append(head, events)
+}
+
+var _ = Describe("StoreCheckpoint", func() {
+
+	var token indexer.Contract
+	var start uint64
+	var dir, path string
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(mocks.TokenABI))
+		Expect(err).ToNot(HaveOccurred())
+		token = indexer.Contract{Name: "token", Address: ERC20Contract1Address, ABI: parsed}
+		dir, err = ioutil.TempDir("", "backfill")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "events.jsonl")
+
+		tx, err := ERC20Contract1.Credit(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(100))
+		Chain.CommitTx(tx, err)
+		start = Chain.Head().Uint64() + 1
+		for i := 0; i < 6; i++ {
+			tx, err = ERC20Contract1.Transfer(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(int64(i+1)))
+			Chain.CommitTx(tx, err)
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	newBackfill := func(store indexer.Store) *backfill.Backfill {
+		b := backfill.New(Chain, backfill.NewStoreCheckpoint(store), nil, token)
+		b.StartBlock = start
+		b.RangeSize = 2
+		return b
+	}
+
+	It("should store the events of every range exactly once across a crash", func() {
+		store, err := indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(newBackfill(&crashingStore{Store: store, writes: 1}).Run(ctx)).To(MatchError(ContainSubstring("crashed")))
+		Expect(store.Close()).To(Succeed())
+
+		store, err = indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		defer store.Close()
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		head, _ := store.Head()
+		Expect(head).To(Equal(start + 1))
+
+		Expect(newBackfill(store).Run(ctx)).To(Succeed())
+		events, err = store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(6))
+		for i, e := range events {
+			Expect(e.Args["amount"]).To(Equal(big.NewInt(int64(i + 1)).String()))
+		}
+		head, _ = store.Head()
+		Expect(head).To(Equal(Chain.Head().Uint64()))
+	})
+
+	It("should notify the handler of the committed events", func() {
+		var handled []indexer.Event
+		b := backfill.New(Chain, backfill.NewStoreCheckpoint(indexer.NewMemoryStore()), indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+			handled = append(handled, events...)
+			return errors.New("not delivered")
+		}), token)
+		b.StartBlock = start
+		Expect(b.Run(ctx)).To(Succeed())
+		Expect(handled).To(HaveLen(6))
+	})
+})
+
+var _ = Describe("FileCheckpoint", func() {
+
+	var dir, path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "backfill")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "checkpoint.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should read back the saved progress", func() {
+		_, ok, err := backfill.NewFileCheckpoint(path).Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(backfill.NewFileCheckpoint(path).Save(42)).To(Succeed())
+		Expect(backfill.NewFileCheckpoint(path).Save(43)).To(Succeed())
+		next, ok, err := backfill.NewFileCheckpoint(path).Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal(uint64(43)))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("should fail on a corrupted file", func() {
+		Expect(ioutil.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+		_, _, err := backfill.NewFileCheckpoint(path).Load()
+		Expect(err).To(MatchError(ContainSubstring(path)))
+	})
+})
@@ -0,0 +1,92 @@
+package bindcheck_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindcheck"
+)
+
+const binding = `package bindings
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethbind "github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const WalletABI = "[]"
+
+const WalletBin = "0x"
+
+var (
+	_ = abi.U256
+	_ = ethbind.Bind
+)
+
+func shadowed() {
+	abi := struct{ U256 int }{}
+	_ = abi.U256
+}
+`
+
+const current = `package bindings
+
+import "github.com/ethereum/go-ethereum/accounts/abi"
+
+const TokenABI = "[]"
+
+var _ = abi.JSON
+`
+
+var _ = Describe("Check", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "bindcheck")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "mocks"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "wallet.go"), []byte(binding), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "mocks", "token.go"), []byte(current), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "wallet_test.go"), []byte(binding), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should report the deprecated symbols", func() {
+		findings, err := bindcheck.Check(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings).To(HaveLen(2))
+		Expect(findings[0].Symbol).To(Equal("abi.U256"))
+		Expect(findings[0].Pos.Line).To(Equal(13))
+		Expect(findings[0].String()).To(ContainSubstring("wallet.go:13:6: abi.U256 is removed"))
+		Expect(findings[1].Symbol).To(Equal("ethbind.Bind"))
+		Expect(findings[1].Types).To(Equal([]string{"Wallet"}))
+	})
+
+	It("should list the bindings to regenerate", func() {
+		findings, err := bindcheck.Check(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bindcheck.Regenerate(findings)).To(Equal(map[string][]string{
+			filepath.Join(dir, "wallet.go"): {"Wallet"},
+		}))
+	})
+
+	It("should fail on a file which does not parse", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "broken.go"), []byte("package"), 0644)).To(Succeed())
+		_, err := bindcheck.Check(dir)
+		Expect(err).To(MatchError(ContainSubstring("parsing")))
+	})
+
+	It("should check the generated bindings", func() {
+		findings, err := bindcheck.Check("../../pkg/bindings")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bindcheck.Regenerate(findings)).To(HaveKey("../../pkg/bindings/wallet.go"))
+	})
+})
@@ -0,0 +1,13 @@
+package bindmigrate_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBindmigrateSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Binding Migration Suite")
+}
@@ -0,0 +1,130 @@
+package bindmigrate_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindmigrate"
+)
+
+const consumer = `package consumer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	b "github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+// load binds the wallet.
+func load(address common.Address, backend bind.ContractBackend) error {
+	w, err := b.NewWallet(address, backend)
+	if err != nil {
+		return err
+	}
+	_, err = w.SpendLimitValue(nil)
+	return err
+}
+
+func oracle(address common.Address, backend bind.ContractBackend) {
+	b.NewOracle(address, backend)
+}
+
+func shadowed() {
+	b := struct{ NewWallet int }{}
+	_ = b.NewWallet
+}
+`
+
+const named = `package named
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+)
+
+var licence *bindings.Licence
+
+func bindLicence(address common.Address, backend bind.ContractBackend) (err error) {
+	licence, err = bindings.NewLicence(address, backend)
+	return err
+}
+
+var _ bindings.ControllerSession
+`
+
+var _ = Describe("Scan", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "bindmigrate")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "named"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "vendor"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "consumer.go"), []byte(consumer), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "named", "named.go"), []byte(named), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "vendor", "vendored.go"), []byte(named), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should suggest the clients replacing the bindings", func() {
+		suggestions, err := bindmigrate.Scan(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(suggestions).To(HaveLen(5))
+
+		Expect(suggestions[0].Symbol).To(Equal("b.NewWallet"))
+		Expect(suggestions[0].Replacement).To(Equal("b.NewWalletClient"))
+		Expect(suggestions[0].Pos.Line).To(Equal(11))
+		Expect(suggestions[0].Rewrite).To(BeTrue())
+		Expect(suggestions[0].String()).To(ContainSubstring("consumer.go:11:12: b.NewWallet -> b.NewWalletClient (rewritable)"))
+		Expect(suggestions[0].Hint).To(ContainSubstring("monolith.NewWalletClient of pkg/monolith/v1"))
+
+		Expect(suggestions[1].Symbol).To(Equal("b.NewOracle"))
+		Expect(suggestions[1].Rewrite).To(BeFalse())
+		Expect(suggestions[1].Hint).To(ContainSubstring("address of the token whitelist"))
+	})
+
+	It("should not rewrite the constructors of a type named in the package", func() {
+		suggestions, err := bindmigrate.Scan(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(suggestions[2].Symbol).To(Equal("bindings.Licence"))
+		Expect(suggestions[2].Replacement).To(Equal("bindings.LicenceClient"))
+		Expect(suggestions[3].Symbol).To(Equal("bindings.NewLicence"))
+		Expect(suggestions[3].Rewrite).To(BeFalse())
+		Expect(suggestions[3].Hint).To(ContainSubstring("named.go:9:14, change it to *bindings.LicenceClient"))
+		Expect(suggestions[4].Symbol).To(Equal("bindings.ControllerSession"))
+		Expect(suggestions[4].Replacement).To(Equal("bindings.AccessClient"))
+	})
+
+	It("should rewrite the rewritable uses", func() {
+		suggestions, err := bindmigrate.Scan(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		src, changed, err := bindmigrate.Rewrite(filepath.Join(dir, "consumer.go"), suggestions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(string(src)).To(ContainSubstring("w, err := b.NewWalletClient(address, backend)"))
+		Expect(string(src)).To(ContainSubstring("b.NewOracle(address, backend)"))
+		Expect(string(src)).To(ContainSubstring("_ = b.NewWallet\n"))
+		Expect(string(src)).To(ContainSubstring("// load binds the wallet."))
+
+		_, changed, err = bindmigrate.Rewrite(filepath.Join(dir, "named", "named.go"), suggestions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should fail on a file which does not parse", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "broken.go"), []byte("package"), 0644)).To(Succeed())
+		_, err := bindmigrate.Scan(dir)
+		Expect(err).To(MatchError(ContainSubstring("parsing")))
+	})
+})
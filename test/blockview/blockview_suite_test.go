@@ -0,0 +1,13 @@
+package blockview_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBlockviewSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Blockview Suite")
+}
@@ -0,0 +1,206 @@
+package blockview_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/blockview"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+// headers are the headers of a chain, by number. The headers of reorg
+// replace them once read reads.
+type headers struct {
+	blocks map[uint64]*types.Header
+	reorg  map[uint64]*types.Header
+	reads  int
+}
+
+func (h *headers) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	h.reads++
+	if r, ok := h.reorg[number.Uint64()]; ok && h.reads > 1 {
+		return r, nil
+	}
+	b, ok := h.blocks[number.Uint64()]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return b, nil
+}
+
+var _ = Describe("blockview", func() {
+
+	var (
+		store    *indexer.MemoryStore
+		chain    *headers
+		header   *types.Header
+		wallet   = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		licence  = common.HexToAddress("0x2000000000000000000000000000000000000002")
+		float    = common.HexToAddress("0x3000000000000000000000000000000000000003")
+		holder   = common.HexToAddress("0x4000000000000000000000000000000000000004")
+		token    = common.HexToAddress("0x5000000000000000000000000000000000000005")
+		owner    = common.HexToAddress("0x6000000000000000000000000000000000000006")
+		sender   = common.HexToAddress("0x7000000000000000000000000000000000000007")
+		tx1      = common.HexToHash("0x01")
+		tx2      = common.HexToHash("0x02")
+		tx3      = common.HexToHash("0x03")
+		ctx      = context.Background()
+		event    func(contract string, address common.Address, name string, tx common.Hash, index uint, args map[string]interface{}) indexer.Event
+		topic    = func(a common.Address) common.Hash { return common.BytesToHash(a.Bytes()) }
+		ethAsset = common.Address{}
+	)
+
+	BeforeEach(func() {
+		header = &types.Header{Number: big.NewInt(10), ParentHash: common.HexToHash("0x09"), Time: 1600000000, Difficulty: big.NewInt(1)}
+		chain = &headers{blocks: map[uint64]*types.Header{10: header}}
+		event = func(contract string, address common.Address, name string, tx common.Hash, index uint, args map[string]interface{}) indexer.Event {
+			return indexer.Event{
+				Contract:    contract,
+				Address:     address,
+				Name:        name,
+				BlockNumber: 10,
+				BlockHash:   header.Hash(),
+				TxHash:      tx,
+				LogIndex:    index,
+				Args:        args,
+			}
+		}
+		store = indexer.NewMemoryStore()
+		Expect(store.Append(10, []indexer.Event{
+			event("wallet", wallet, "Received", tx1, 0, map[string]interface{}{"_from": sender, "_amount": big.NewInt(1000)}),
+			event("tkn", token, "Transfer", tx2, 1, map[string]interface{}{"_from": topic(wallet), "_to": topic(float), "_value": big.NewInt(99)}),
+			event("tkn", token, "Transfer", tx2, 2, map[string]interface{}{"_from": topic(wallet), "_to": topic(holder), "_value": big.NewInt(1)}),
+			event("licence", licence, "TransferredToCryptoFloat", tx2, 3, map[string]interface{}{"_from": wallet, "_to": float, "_asset": token, "_amount": big.NewInt(99)}),
+			event("licence", licence, "TransferredToTokenHolder", tx2, 4, map[string]interface{}{"_from": wallet, "_to": holder, "_asset": token, "_amount": big.NewInt(1)}),
+			event("wallet", wallet, "ToppedUpGas", tx3, 5, map[string]interface{}{"_sender": owner, "_owner": owner, "_amount": big.NewInt(200)}),
+			event("licence", licence, "UpdatedLicenceAmount", tx3, 6, map[string]interface{}{"_newAmount": big.NewInt(10)}),
+		})).To(Succeed())
+	})
+
+	It("returns the events of the block", func() {
+		b, err := blockview.View(ctx, store, chain, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Number).To(Equal(uint64(10)))
+		Expect(b.Hash).To(Equal(header.Hash()))
+		Expect(b.ParentHash).To(Equal(common.HexToHash("0x09")))
+		Expect(b.Time).To(Equal(uint64(1600000000)))
+		Expect(b.Events).To(HaveLen(7))
+		Expect(b.Events[0].Args).To(Equal(map[string]interface{}{"_from": sender.Hex(), "_amount": "1000"}))
+	})
+
+	It("returns the parameters changed", func() {
+		b, err := blockview.View(ctx, store, chain, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Changes).To(Equal([]blockview.Change{{
+			Contract:  "licence",
+			Address:   licence,
+			Parameter: "licence_amount",
+			Event:     "UpdatedLicenceAmount",
+			TxHash:    tx3,
+			LogIndex:  6,
+			Args:      map[string]interface{}{"_newAmount": "10"},
+		}}))
+	})
+
+	It("returns the balance effects, counting each movement once", func() {
+		b, err := blockview.View(ctx, store, chain, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Balances).To(Equal([]blockview.Balance{
+			{Account: wallet, Asset: ethAsset, Delta: "800"},
+			{Account: wallet, Asset: token, Delta: "-100"},
+			{Account: float, Asset: token, Delta: "99"},
+			{Account: holder, Asset: token, Delta: "1"},
+			{Account: owner, Asset: ethAsset, Delta: "200"},
+			{Account: sender, Asset: ethAsset, Delta: "-1000"},
+		}))
+	})
+
+	It("reads the events read back from a file", func() {
+		dir, err := ioutil.TempDir("", "blockview")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "events.jsonl")
+		file, err := indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Append(10, events)).To(Succeed())
+		Expect(file.Close()).To(Succeed())
+		file, err = indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		want, err := blockview.View(ctx, store, chain, 10)
+		Expect(err).ToNot(HaveOccurred())
+		got, err := blockview.View(ctx, file, chain, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Balances).To(Equal(want.Balances))
+		Expect(got.Changes).To(Equal(want.Changes))
+	})
+
+	It("returns an empty document for a block without events", func() {
+		chain.blocks[9] = &types.Header{Number: big.NewInt(9), Difficulty: big.NewInt(1)}
+		b, err := blockview.View(ctx, store, chain, 9)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Events).To(BeEmpty())
+		Expect(b.Changes).To(BeEmpty())
+		Expect(b.Balances).To(BeEmpty())
+	})
+
+	It("fails for a block not indexed yet", func() {
+		_, err := blockview.View(ctx, store, chain, 11)
+		Expect(errors.Cause(err)).To(Equal(blockview.ErrNotIndexed))
+	})
+
+	It("fails for a block reorganized since it was indexed", func() {
+		chain.blocks[10] = &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2)}
+		_, err := blockview.View(ctx, store, chain, 10)
+		Expect(errors.Cause(err)).To(Equal(blockview.ErrReorganized))
+	})
+
+	It("fails for a block reorganized while it is read", func() {
+		chain.reorg = map[uint64]*types.Header{10: {Number: big.NewInt(10), Difficulty: big.NewInt(2)}}
+		_, err := blockview.View(ctx, store, chain, 10)
+		Expect(errors.Cause(err)).To(Equal(blockview.ErrReorganized))
+	})
+
+	Describe("the handler", func() {
+
+		get := func(path string) (int, map[string]interface{}) {
+			w := httptest.NewRecorder()
+			blockview.NewHandler(store, chain).ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			var body map[string]interface{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			return w.Code, body
+		}
+
+		It("serves the document of a block", func() {
+			code, body := get("/blocks/10")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body["hash"]).To(Equal(header.Hash().Hex()))
+			Expect(body["events"]).To(HaveLen(7))
+		})
+
+		It("does not find a block not indexed yet", func() {
+			code, _ := get("/blocks/11")
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects an invalid block number", func() {
+			code, body := get("/blocks/latest")
+			Expect(code).To(Equal(http.StatusBadRequest))
+			Expect(body["error"]).To(Equal(`invalid block number "latest"`))
+		})
+	})
+})
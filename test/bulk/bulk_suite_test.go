@@ -0,0 +1,32 @@
+package bulk_test
+
+import (
+	"math/big"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestBulkSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bulk Suite")
+}
+
+var Chain *TestChain
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = NewTestChain(Backend, MineOnSend)
+
+	_, err = TKNBurner.Mint(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(1000))
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Commit()
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,176 @@
+package bulk_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bulk"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("ReadTransfers", func() {
+
+	a, b := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+
+	It("reads the receivers and the amounts after the header", func() {
+		t, err := bulk.ReadTransfers(strings.NewReader("receiver,amount\n" + a.Hex() + ",10\n" + strings.ToLower(b.Hex()) + ", 20\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t).To(Equal([]bulk.Transfer{{To: a, Amount: big.NewInt(10)}, {To: b, Amount: big.NewInt(20)}}))
+	})
+
+	It("rejects the invalid lists", func() {
+		for list, msg := range map[string]string{
+			a.Hex() + "\n":                        "line 1",
+			a.Hex() + ",1\n" + a.Hex() + ",2\n":   "already listed on line 1",
+			common.Address{}.Hex() + ",1\n":       "zero address",
+			a.Hex() + ",0\n":                      "not a positive amount",
+			a.Hex() + ",1\n" + b.Hex() + ",1.5\n": "line 2",
+			"0x1234,1\n":                          "not an address",
+		} {
+			_, err := bulk.ReadTransfers(strings.NewReader(list))
+			Expect(err).To(MatchError(ContainSubstring(msg)), list)
+		}
+	})
+})
+
+var _ = Describe("Batch", func() {
+
+	var dir, journal string
+	var transfers []bulk.Transfer
+	ctx := context.Background()
+
+	newBatch := func(backend *FailingChain) *bulk.Batch {
+		b := bulk.New(TKNBurnerAddress, backend, Chain, RandomAccount.TransactOpts(), journal)
+		b.ChunkSize = 2
+		return b
+	}
+
+	balance := func(a common.Address) *big.Int {
+		b, err := TKNBurner.BalanceOf(nil, a)
+		Expect(err).ToNot(HaveOccurred())
+		return b
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "bulk")
+		Expect(err).ToNot(HaveOccurred())
+		journal = filepath.Join(dir, "journal.jsonl")
+		transfers = []bulk.Transfer{
+			{To: common.HexToAddress("0x1"), Amount: big.NewInt(100)},
+			{To: common.HexToAddress("0x2"), Amount: big.NewInt(200)},
+			{To: common.HexToAddress("0x3"), Amount: big.NewInt(300)},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("rejects the receivers which can not hold the tokens", func() {
+		transfers = append(transfers,
+			bulk.Transfer{To: TKNBurnerAddress, Amount: big.NewInt(1)},
+			bulk.Transfer{To: RandomAccount.Address(), Amount: big.NewInt(1)},
+			bulk.Transfer{To: LicenceAddress, Amount: big.NewInt(1)},
+		)
+		rejected, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Preflight(ctx, transfers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rejected).To(Equal([]bulk.Rejection{
+			{To: TKNBurnerAddress, Reason: "receiver is the token contract"},
+			{To: RandomAccount.Address(), Reason: "receiver is the sender"},
+			{To: LicenceAddress, Reason: "receiver is a contract"},
+		}))
+
+		b := newBatch(&FailingChain{TestChain: Chain, Left: -1})
+		b.AllowContracts = true
+		rejected, err = b.Preflight(ctx, transfers[len(transfers)-1:])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rejected).To(BeEmpty())
+	})
+
+	It("rejects the transfers beyond the balance of the sender", func() {
+		transfers[2].Amount = big.NewInt(701)
+		rejected, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Preflight(ctx, transfers)
+		Expect(errors.Cause(err)).To(Equal(bulk.ErrBalance))
+		// Each transfer alone is within the balance.
+		Expect(rejected).To(BeEmpty())
+	})
+
+	It("sends the transfers in chunks and verifies them", func() {
+		var progress []int
+		b := newBatch(&FailingChain{TestChain: Chain, Left: -1})
+		b.Progress = func(done, total int, r bulk.Result) {
+			Expect(total).To(Equal(3))
+			Expect(r.Status).To(Equal(bulk.Mined))
+			progress = append(progress, done)
+		}
+		report, err := b.Run(ctx, transfers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeTrue())
+		Expect(progress).To(Equal([]int{1, 2, 3}))
+		for _, t := range transfers {
+			Expect(balance(t.To)).To(Equal(t.Amount))
+		}
+		Expect(balance(RandomAccount.Address())).To(Equal(big.NewInt(400)))
+	})
+
+	It("resumes an interrupted batch without sending a transfer twice", func() {
+		_, err := newBatch(&FailingChain{TestChain: Chain, Left: 1}).Run(ctx, transfers)
+		Expect(err).To(MatchError(ContainSubstring("node down")))
+		Expect(balance(transfers[0].To)).To(Equal(transfers[0].Amount))
+		Expect(balance(transfers[1].To).Sign()).To(BeZero())
+
+		report, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Run(ctx, transfers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeTrue())
+		for _, t := range transfers {
+			Expect(balance(t.To)).To(Equal(t.Amount))
+		}
+	})
+
+	It("leaves the transfers sent without a receipt to the next run", func() {
+		unconfirmed := bulk.Result{To: transfers[0].To, Amount: transfers[0].Amount, Status: bulk.Sent, TxHash: common.HexToHash("0x1")}
+		line, err := json.Marshal(unconfirmed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(journal, append(line, '\n'), 0644)).To(Succeed())
+
+		report, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Run(ctx, transfers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeFalse())
+		Expect(report.Verified).To(Equal(2))
+		Expect(report.Unconfirmed).To(Equal([]bulk.Result{unconfirmed}))
+		Expect(balance(transfers[0].To).Sign()).To(BeZero())
+	})
+
+	It("reports the journaled transactions without the transfer", func() {
+		_, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Run(ctx, transfers[:1])
+		Expect(err).ToNot(HaveOccurred())
+
+		// A receiver journaled with the transaction of another one.
+		results, err := ioutil.ReadFile(journal)
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(results)), "\n")
+		var mined bulk.Result
+		Expect(json.Unmarshal([]byte(lines[len(lines)-1]), &mined)).To(Succeed())
+		mined.To = transfers[1].To
+		f, err := os.OpenFile(journal, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.NewEncoder(f).Encode(mined)).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+
+		report, err := newBatch(&FailingChain{TestChain: Chain, Left: -1}).Verify(ctx, transfers)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Verified).To(Equal(1))
+		Expect(report.Mismatched).To(Equal([]bulk.Mismatch{{To: transfers[1].To, TxHash: mined.TxHash, Reason: "no Transfer event to the receiver"}}))
+		Expect(report.Missing).To(Equal(transfers[2:]))
+	})
+})
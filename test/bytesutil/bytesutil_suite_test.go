@@ -0,0 +1,78 @@
+package bytesutil_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/bytesutil"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+func TestBytesutilSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bytes Utilities Suite")
+}
+
+// deployExporter deploys the exporter of the BytesUtils library the native
+// implementation is compared against.
+func deployExporter() (*testutil.Chain, *mocks.BytesUtilsExporter, error) {
+	chain, err := testutil.New(testutil.Config{})
+	if err != nil {
+		return nil, nil, err
+	}
+	_, tx, exporter, err := mocks.DeployBytesUtilsExporter(chain.Deployer.Session.TransactOpts(), chain)
+	if err != nil {
+		chain.Close()
+		return nil, nil, errors.Wrap(err, "deploying the exporter")
+	}
+	_, err = chain.Mined(tx)
+	if err != nil {
+		chain.Close()
+		return nil, nil, errors.Wrap(err, "deploying the exporter")
+	}
+	return chain, exporter, nil
+}
+
+// compare returns an error describing how the native implementation and the
+// exporter disagree on the slicing of b at from, if they do.
+func compare(exporter *mocks.BytesUtilsExporter, b []byte, from *big.Int) error {
+	address, err := bytesutil.BytesToAddress(b, from)
+	want, wantErr := exporter.BytesToAddress(nil, b, from)
+	e := agree("bytesToAddress", address, err, want, wantErr)
+	if e != nil {
+		return e
+	}
+
+	b4, err := bytesutil.BytesToBytes4(b, from)
+	wantB4, wantErr := exporter.BytesToBytes4(nil, b, from)
+	e = agree("bytesToBytes4", b4, err, wantB4, wantErr)
+	if e != nil {
+		return e
+	}
+
+	u, err := bytesutil.BytesToUint256(b, from)
+	wantU, wantErr := exporter.BytesToUint256(nil, b, from)
+	if err == nil && wantErr == nil && u.Cmp(wantU) != 0 {
+		return errors.Errorf("bytesToUint256(%x, %s): got %s, want %s", b, from, u, wantU)
+	}
+	return agree("bytesToUint256", nil, err, nil, wantErr)
+}
+
+func agree(name string, got interface{}, err error, want interface{}, wantErr error) error {
+	switch {
+	case err == nil && wantErr != nil:
+		return errors.Errorf("%s: got %v, want the error %q", name, got, wantErr)
+	case err != nil && wantErr == nil:
+		return errors.Errorf("%s: got the error %q, want %v", name, err, want)
+	case err != nil && !strings.Contains(wantErr.Error(), err.Error()):
+		return errors.Errorf("%s: got the error %q, want %q", name, err, wantErr)
+	case err == nil && got != want:
+		return errors.Errorf("%s: got %v, want %v", name, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package bytesutil_test
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/bytesutil"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+var _ = Describe("bytesutil", func() {
+
+	It("should slice an address", func() {
+		b := common.Hex2Bytes("ffffffffffffffffffffffffffffffffffffffff0000000000000000000000000000000000000001ffffffffffffff")
+		addr, err := bytesutil.BytesToAddress(b, big.NewInt(20))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addr).To(Equal(common.HexToAddress("0x1")))
+	})
+
+	It("should slice 4 bytes", func() {
+		b4, err := bytesutil.BytesToBytes4(common.Hex2Bytes("0102030405"), big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b4).To(Equal([4]byte{2, 3, 4, 5}))
+	})
+
+	It("should slice a uint256", func() {
+		b := common.LeftPadBytes([]byte{0x01, 0x00}, 33)
+		u, err := bytesutil.BytesToUint256(b, big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(u.String()).To(Equal("256"))
+	})
+
+	It("should fail when slicing out of range", func() {
+		_, err := bytesutil.BytesToAddress(make([]byte, 20), big.NewInt(1))
+		Expect(err).To(Equal(bytesutil.ErrOutOfRange))
+	})
+
+	It("should fail when the end position overflows", func() {
+		_, err := bytesutil.BytesToUint256(make([]byte, 32), big.NewInt(-20))
+		Expect(err).To(Equal(bytesutil.ErrOverflow))
+	})
+
+	It("should wrap negative start positions around", func() {
+		_, err := bytesutil.BytesToAddress(make([]byte, 21), big.NewInt(-23))
+		Expect(err).To(Equal(bytesutil.ErrOutOfRange))
+	})
+
+	Describe("compared to the contracts", func() {
+
+		var chain *testutil.Chain
+		var exporter *mocks.BytesUtilsExporter
+
+		BeforeEach(func() {
+			var err error
+			chain, exporter, err = deployExporter()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(chain.Close()).To(Succeed())
+		})
+
+		It("should agree on the edge cases", func() {
+			for _, from := range []int64{0, 1, 4, 12, 20, 31, 32, 33, -1, -4, -20, -23, -32, -33} {
+				for _, n := range []int{0, 3, 4, 19, 20, 21, 31, 32, 33, 52, 64} {
+					b := make([]byte, n)
+					for i := range b {
+						b[i] = byte(i + 1)
+					}
+					Expect(compare(exporter, b, big.NewInt(from))).To(Succeed())
+				}
+			}
+		})
+
+		It("should agree on random inputs", func() {
+			r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			for i := 0; i < 200; i++ {
+				b := make([]byte, r.Intn(80))
+				r.Read(b)
+				from := big.NewInt(int64(r.Intn(len(b) + 8)))
+				Expect(compare(exporter, b, from)).To(Succeed())
+			}
+		})
+	})
+})
@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package bytesutil_test
+
+import (
+	"math/big"
+	"testing"
+)
+
+// FuzzBytesUtils compares the native implementation with the exporter of the
+// contract library, run with:
+//
+//	go test ./test/bytesutil -run '^$' -fuzz FuzzBytesUtils
+func FuzzBytesUtils(f *testing.F) {
+	chain, exporter, err := deployExporter()
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer chain.Close()
+
+	f.Add([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), []byte{4})
+	f.Add(make([]byte, 20), []byte{1})
+	f.Add(make([]byte, 32), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xec})
+	f.Fuzz(func(t *testing.T, b []byte, from []byte) {
+		if len(from) > 32 {
+			from = from[:32]
+		}
+		err := compare(exporter, b, new(big.Int).SetBytes(from))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package cache_test
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCacheSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Suite")
+}
+
+// node answers the calls with the number of calls it received, failing them
+// with err when it is set, and runs during while answering when it is set.
+// The other methods of a backend are not implemented.
+type node struct {
+	bind.ContractBackend
+	calls  int
+	head   int64
+	err    error
+	during func()
+}
+
+func (n *node) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	n.calls++
+	if n.during != nil {
+		n.during()
+	}
+	if n.err != nil {
+		return nil, n.err
+	}
+	return []byte{byte(n.calls)}, nil
+}
+
+func (n *node) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(atomic.LoadInt64(&n.head))}, nil
+}
+
+var Node *node
+
+var _ = BeforeEach(func() {
+	Node = &node{head: 100}
+})
@@ -0,0 +1,135 @@
+package cache_test
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/cache"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+var _ = Describe("Backend", func() {
+
+	var calls *cache.Backend
+	var licence, controller common.Address
+
+	call := func(to common.Address, data string, block *big.Int) byte {
+		out, err := calls.CallContract(context.Background(), ethereum.CallMsg{To: &to, Data: []byte(data)}, block)
+		Expect(err).ToNot(HaveOccurred())
+		return out[0]
+	}
+
+	BeforeEach(func() {
+		licence = common.HexToAddress("0x1")
+		controller = common.HexToAddress("0x2")
+		calls = cache.NewBackend(Node, Node)
+	})
+
+	It("should cache the results by contract, call data and block", func() {
+		Expect(call(licence, "owner", nil)).To(Equal(byte(1)))
+		Expect(call(licence, "owner", nil)).To(Equal(byte(1)))
+		Expect(call(licence, "amount", nil)).To(Equal(byte(2)))
+		Expect(call(controller, "owner", nil)).To(Equal(byte(3)))
+		Expect(call(licence, "owner", big.NewInt(99))).To(Equal(byte(4)))
+		Expect(call(licence, "owner", big.NewInt(99))).To(Equal(byte(4)))
+		Expect(Node.calls).To(Equal(4))
+		Expect(calls.Stats()).To(Equal(cache.Stats{Hits: 2, Misses: 4, Entries: 4}))
+	})
+
+	It("should expire the results after the TTL", func() {
+		calls.TTL = time.Millisecond
+		call(licence, "owner", nil)
+		time.Sleep(2 * time.Millisecond)
+		Expect(call(licence, "owner", nil)).To(Equal(byte(2)))
+	})
+
+	It("should not cache the failed calls", func() {
+		Node.err = errors.New("execution reverted")
+		_, err := calls.CallContract(context.Background(), ethereum.CallMsg{To: &licence}, nil)
+		Expect(err).To(MatchError("execution reverted"))
+		Node.err = nil
+		Expect(call(licence, "", nil)).To(Equal(byte(2)))
+	})
+
+	It("should drop the results at the latest block on a new head", func() {
+		calls.SetHead(100)
+		call(licence, "owner", nil)
+		call(licence, "owner", big.NewInt(100))
+		calls.SetHead(100)
+		Expect(call(licence, "owner", nil)).To(Equal(byte(1)))
+
+		calls.SetHead(101)
+		Expect(call(licence, "owner", nil)).To(Equal(byte(3)))
+		Expect(call(licence, "owner", big.NewInt(100))).To(Equal(byte(2)))
+		Expect(calls.Stats().Invalidations).To(Equal(int64(1)))
+	})
+
+	It("should poll the head", func() {
+		calls.Interval = time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go calls.Run(ctx)
+
+		Eventually(func() byte { return call(licence, "owner", nil) }).Should(Equal(byte(1)))
+		atomic.StoreInt64(&Node.head, 101)
+		Eventually(func() byte { return call(licence, "owner", nil) }).Should(Equal(byte(2)))
+	})
+
+	It("should drop the results of the contracts emitting the events", func() {
+		calls.Events = []string{"licence.UpdatedLicenceAmount"}
+		call(licence, "amount", nil)
+		call(controller, "owner", nil)
+
+		Expect(calls.HandleEvents(context.Background(), []indexer.Event{{Contract: "licence", Name: "TransferredOwnership", Address: licence}})).To(Succeed())
+		Expect(call(licence, "amount", nil)).To(Equal(byte(1)))
+
+		Expect(calls.HandleEvents(context.Background(), []indexer.Event{{Contract: "licence", Name: "UpdatedLicenceAmount", Address: licence}})).To(Succeed())
+		Expect(call(licence, "amount", nil)).To(Equal(byte(3)))
+		Expect(call(controller, "owner", nil)).To(Equal(byte(2)))
+	})
+
+	It("should not cache the results of the calls made before an invalidation", func() {
+		calls.SetHead(100)
+		Node.during = func() { calls.SetHead(101) }
+		Expect(call(licence, "owner", nil)).To(Equal(byte(1)))
+		Node.during = func() { calls.Invalidate(licence) }
+		Expect(call(licence, "owner", big.NewInt(101))).To(Equal(byte(2)))
+		Node.during = nil
+
+		Expect(call(licence, "owner", nil)).To(Equal(byte(3)))
+		Expect(call(licence, "owner", big.NewInt(101))).To(Equal(byte(4)))
+		Expect(call(licence, "owner", nil)).To(Equal(byte(3)))
+		Expect(calls.Stats().Entries).To(Equal(2))
+	})
+
+	It("should evict results beyond MaxEntries", func() {
+		calls.MaxEntries = 2
+		call(licence, "a", nil)
+		call(licence, "b", nil)
+		call(licence, "c", nil)
+		Expect(calls.Stats().Entries).To(Equal(2))
+	})
+
+	It("should record the metrics", func() {
+		metrics.Enabled = true
+		defer func() { metrics.Enabled = false }()
+		registry := metrics.NewRegistry()
+		calls.Registry = registry
+
+		call(licence, "owner", nil)
+		call(licence, "owner", nil)
+		calls.Invalidate(licence)
+		Expect(metrics.GetOrRegisterCounter("cache/hits", registry).Count()).To(Equal(int64(1)))
+		Expect(metrics.GetOrRegisterCounter("cache/misses", registry).Count()).To(Equal(int64(1)))
+		Expect(metrics.GetOrRegisterCounter("cache/invalidations", registry).Count()).To(Equal(int64(1)))
+		Expect(metrics.GetOrRegisterGauge("cache/entries", registry).Value()).To(Equal(int64(0)))
+	})
+})
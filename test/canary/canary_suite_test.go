@@ -0,0 +1,28 @@
+package canary_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+func TestCanarySuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Canary Suite")
+}
+
+var Chain *testutil.Chain
+
+var _ = BeforeEach(func() {
+	var err error
+	Chain, err = testutil.New(testutil.Config{})
+	Expect(err).ToNot(HaveOccurred())
+	Chain.Mining = testutil.MineOnReceipt
+})
+
+var _ = AfterEach(func() {
+	err := Chain.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,188 @@
+package canary_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+// unreachable is a backend failing to broadcast transactions.
+type unreachable struct {
+	*testutil.Chain
+}
+
+func (unreachable) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return errors.New("connection refused")
+}
+
+func steps(r canary.Report) []string {
+	var names []string
+	for _, s := range r.Steps {
+		names = append(names, s.Step)
+	}
+	return names
+}
+
+var _ = Describe("Canary", func() {
+
+	var c *canary.Canary
+	var alerted []canary.Report
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(canary.TokenABI))
+		Expect(err).ToNot(HaveOccurred())
+		store := indexer.NewMemoryStore()
+		idx := indexer.New(Chain, store, indexer.Contract{Name: "canary", Address: Chain.StablecoinAddress, ABI: parsed})
+		indexed := canary.Indexed(store, "canary")
+
+		alerted = nil
+		c = &canary.Canary{
+			Backend:  Chain,
+			Receipts: Chain,
+			Opts:     Chain.Deployer.Session.TransactOpts(),
+			Token:    Chain.StablecoinAddress,
+			Indexed: func(ctx context.Context, tx common.Hash) (bool, error) {
+				err := idx.Sync(ctx)
+				if err != nil {
+					return false, err
+				}
+				return indexed(ctx, tx)
+			},
+			Alert: func(ctx context.Context, r canary.Report) error {
+				alerted = append(alerted, r)
+				return nil
+			},
+			PollInterval: 10 * time.Millisecond,
+		}
+	})
+
+	It("should pass once the transaction went through the pipeline", func() {
+		r, err := c.Run(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Passed).To(BeTrue())
+		Expect(steps(r)).To(Equal([]string{canary.Sign, canary.Broadcast, canary.Mine, canary.Index, canary.Alert}))
+		Expect(alerted).To(HaveLen(1))
+		Expect(alerted[0].TxHash).To(Equal(r.TxHash))
+
+		allowance, err := Chain.Stablecoin.Allowance(nil, Chain.Deployer.Address, Chain.Deployer.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowance.String()).To(Equal("1"))
+	})
+
+	It("should fail when the transaction cannot be signed", func() {
+		opts := Chain.Deployer.Session.TransactOpts()
+		opts.Signer = func(types.Signer, common.Address, *types.Transaction) (*types.Transaction, error) {
+			return nil, errors.New("key locked")
+		}
+		c.Opts = opts
+		r, err := c.Run(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("key locked")))
+		Expect(r.Passed).To(BeFalse())
+		Expect(steps(r)).To(Equal([]string{canary.Sign}))
+		Expect(alerted).To(BeEmpty())
+	})
+
+	It("should fail when the transaction cannot be broadcast", func() {
+		c.Backend = unreachable{Chain}
+		r, err := c.Run(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("connection refused")))
+		Expect(steps(r)).To(Equal([]string{canary.Sign, canary.Broadcast}))
+		Expect(r.Steps[1].Err).To(ContainSubstring("connection refused"))
+	})
+
+	It("should fail when the transaction is not indexed in time", func() {
+		c.Indexed = func(ctx context.Context, tx common.Hash) (bool, error) {
+			return false, nil
+		}
+		c.Timeout = 100 * time.Millisecond
+		r, err := c.Run(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("not indexed")))
+		Expect(r.Passed).To(BeFalse())
+		Expect(steps(r)).To(Equal([]string{canary.Sign, canary.Broadcast, canary.Mine, canary.Index}))
+	})
+
+	It("should fail when the alert cannot be sent", func() {
+		c.Alert = func(ctx context.Context, r canary.Report) error {
+			return errors.New("no receiver")
+		}
+		r, err := c.Run(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(r.Passed).To(BeFalse())
+		Expect(r.TxHash).ToNot(Equal(common.Hash{}))
+	})
+})
+
+var _ = Describe("Gate", func() {
+
+	var gate *canary.Gate
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		gate = &canary.Gate{}
+		mux := http.NewServeMux()
+		mux.Handle("/canary", gate.StatusHandler())
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		server = httptest.NewServer(gate.Handler(mux))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	post := func() int {
+		res, err := http.Post(server.URL+"/licence/amount", "application/json", strings.NewReader("{}"))
+		Expect(err).ToNot(HaveOccurred())
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	It("should hold back state changing requests until a canary passed", func() {
+		Expect(post()).To(Equal(http.StatusServiceUnavailable))
+
+		gate.Record(canary.Report{Steps: []canary.StepResult{{Step: canary.Sign, Err: "key locked"}}})
+		Expect(gate.Open()).To(BeFalse())
+		Expect(post()).To(Equal(http.StatusServiceUnavailable))
+
+		gate.Record(canary.Report{Passed: true})
+		Expect(gate.Open()).To(BeTrue())
+		Expect(post()).To(Equal(http.StatusOK))
+	})
+
+	It("should serve read-only requests", func() {
+		res, err := http.Get(server.URL + "/licence/amount")
+		Expect(err).ToNot(HaveOccurred())
+		res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should serve the report of the last canary", func() {
+		res, err := http.Get(server.URL + "/canary")
+		Expect(err).ToNot(HaveOccurred())
+		res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+
+		gate.Record(canary.Report{TxHash: common.HexToHash("0x01"), Passed: true})
+		res, err = http.Get(server.URL + "/canary")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		var r canary.Report
+		Expect(json.NewDecoder(res.Body).Decode(&r)).To(Succeed())
+		Expect(r.Passed).To(BeTrue())
+		Expect(r.TxHash).To(Equal(common.HexToHash("0x01")))
+	})
+})
@@ -0,0 +1,19 @@
+package conformance_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update regenerates the published vectors from the outcomes of the reference
+// implementation instead of comparing them, the changes must then be
+// reviewed as changes of the semantics of the module.
+var update = flag.Bool("update", false, "regenerate the published vectors")
+
+func TestConformanceSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conformance Suite")
+}
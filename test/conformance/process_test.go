@@ -0,0 +1,36 @@
+package conformance_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/conformance"
+)
+
+var _ = Describe("Process", func() {
+
+	It("should run the vectors through a program", func() {
+		// The program fails every vector with the same error class.
+		p, err := conformance.StartProcess(context.Background(), "sh", "-c", `while read -r v; do echo '{"error":"malformed_log"}'; done`)
+		Expect(err).ToNot(HaveOccurred())
+		defer p.Close()
+
+		results, err := conformance.Run(conformance.Default(), p.Run)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(len(conformance.Default().Vectors)))
+		for _, r := range results {
+			Expect(r.Passed).To(Equal(r.Expected.Error == conformance.ErrorMalformedLog), r.Vector)
+		}
+		Expect(p.Close()).To(Succeed())
+	})
+
+	It("should fail when the program exits early", func() {
+		p, err := conformance.StartProcess(context.Background(), "true")
+		Expect(err).ToNot(HaveOccurred())
+		defer p.Close()
+
+		_, err = conformance.Run(conformance.Default(), p.Run)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,207 @@
+package conformance_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/conformance"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/relayer"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+const vectorsPath = "../../pkg/conformance/vectors.json"
+
+var (
+	contractAddress = common.HexToAddress("0x5a7ac1a4e2d76e33a1b5a2c4b8d4a8b1e3a77c01")
+	sender          = common.HexToAddress("0x1f4e0b3c2a9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f")
+	account         = common.HexToAddress("0x7d9c4f2e1a0b3c5d6e8f9a1b2c3d4e5f6a7b8c9d")
+	token           = common.HexToAddress("0x3e8b5f1c9d2a4b6e8f0a1c3e5b7d9f2a4c6e8b0d")
+	signingKey, _   = crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+)
+
+// logOf returns the log of an event of a contract, at a fixed position.
+func logOf(contract, event string, args ...interface{}) *types.Log {
+	parsed, err := abi.JSON(strings.NewReader(bindings.ContractABIs[contract]))
+	Expect(err).ToNot(HaveOccurred())
+	e, ok := parsed.Events[event]
+	Expect(ok).To(BeTrue(), "no %s event in %s", event, contract)
+	data, err := e.Inputs.NonIndexed().Pack(args...)
+	Expect(err).ToNot(HaveOccurred())
+	return &types.Log{
+		Address:     contractAddress,
+		Topics:      []common.Hash{e.ID()},
+		Data:        data,
+		BlockNumber: 9000001,
+		TxHash:      common.HexToHash("0x6f1c"),
+		TxIndex:     3,
+		BlockHash:   common.HexToHash("0xb10c"),
+		Index:       7,
+	}
+}
+
+func metaTx(nonce *big.Int, data []byte) *conformance.MetaTx {
+	m, err := relayer.Sign(account, nonce, data, signing.KeySigner(signingKey))
+	Expect(err).ToNot(HaveOccurred())
+	return &conformance.MetaTx{Wallet: m.Wallet, Nonce: (*hexutil.Big)(m.Nonce), Data: m.Data, Signature: m.Signature}
+}
+
+// inputs are the vectors without their expected outcomes.
+func inputs() []conformance.Vector {
+	var vectors []conformance.Vector
+	event := func(name, contract string, l *types.Log) {
+		vectors = append(vectors, conformance.Vector{Name: name, Kind: conformance.Event, Contract: contract, Log: l})
+	}
+	tokenChange := func(name string, l *types.Log) {
+		vectors = append(vectors, conformance.Vector{Name: name, Kind: conformance.TokenChange, Contract: "token_whitelist", Log: l})
+	}
+	relay := func(name string, m *conformance.MetaTx) {
+		vectors = append(vectors, conformance.Vector{Name: name, Kind: conformance.Relay, Relay: m})
+	}
+
+	event("event/addresses", "controller", logOf("controller", "AddedAdmin", sender, account))
+	event("event/string-uint-bool", "token_whitelist", logOf("token_whitelist", "AddedToken", sender, token, "TKN", big.NewInt(100000000), true, false))
+	event("event/bytes", "wallet", logOf("wallet", "ExecutedTransaction", token, big.NewInt(0), hexutil.MustDecode("0xa9059cbb"), []byte{}))
+	event("event/address-array-bytes32", "wallet", logOf("wallet", "SubmittedWhitelistAddition", []common.Address{sender, account}, crypto.Keccak256Hash([]byte("whitelist"))))
+	event("event/bytes4", "token_whitelist", logOf("token_whitelist", "AddedMethodId", [4]byte{0xa9, 0x05, 0x9c, 0xbb}))
+	event("event/no-arguments", "wallet", logOf("wallet", "UpdatedAvailableLimit"))
+	event("event/max-uint256", "holder", logOf("holder", "CashAndBurned", account, token, math.MaxBig256))
+	removed := logOf("controller", "Stopped", sender)
+	removed.Removed = true
+	event("event/removed", "controller", removed)
+
+	anonymous := logOf("controller", "Started", sender)
+	anonymous.Topics = []common.Hash{}
+	event("event/anonymous", "controller", anonymous)
+	unknown := logOf("controller", "Started", sender)
+	unknown.Topics = []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))}
+	event("event/unknown", "controller", unknown)
+	truncated := logOf("controller", "AddedAdmin", sender, account)
+	truncated.Data = truncated.Data[:32]
+	event("event/truncated-data", "controller", truncated)
+	extraTopic := logOf("controller", "AddedAdmin", sender, account)
+	extraTopic.Topics = append(extraTopic.Topics, common.BytesToHash(account.Bytes()))
+	event("event/extra-topic", "controller", extraTopic)
+
+	tokenChange("token-change/added", logOf("token_whitelist", "AddedToken", sender, token, "TKN", big.NewInt(100000000), true, true))
+	tokenChange("token-change/removed", logOf("token_whitelist", "RemovedToken", sender, token))
+	tokenChange("token-change/other-event", logOf("token_whitelist", "UpdatedTokenRate", sender, token, big.NewInt(42)))
+	malformed := logOf("token_whitelist", "AddedToken", sender, token, "TKN", big.NewInt(100000000), true, true)
+	malformed.Data = malformed.Data[:64]
+	tokenChange("token-change/malformed", malformed)
+
+	relay("relay/signed", metaTx(big.NewInt(0), hexutil.MustDecode("0xa9059cbb")))
+	relay("relay/large-nonce", metaTx(new(big.Int).Lsh(big.NewInt(1), 200), []byte{}))
+	short := metaTx(big.NewInt(1), []byte{1})
+	short.Signature = short.Signature[:64]
+	relay("relay/short-signature", short)
+	badRecovery := metaTx(big.NewInt(1), []byte{1})
+	badRecovery.Signature[64] = 5
+	relay("relay/bad-recovery-id", badRecovery)
+	return vectors
+}
+
+// generated returns the vectors with the outcomes of the reference
+// implementation.
+func generated() *conformance.Suite {
+	s := &conformance.Suite{Version: conformance.Version, Vectors: inputs()}
+	for i, v := range s.Vectors {
+		o, err := conformance.Reference(v)
+		Expect(err).ToNot(HaveOccurred())
+		s.Vectors[i].Expect = o
+	}
+	return s
+}
+
+var _ = Describe("Vectors", func() {
+
+	It("should be the outcomes of the reference implementation", func() {
+		s := generated()
+		if *update {
+			Expect(s.Save(vectorsPath)).To(Succeed())
+			return
+		}
+		published, err := json.Marshal(conformance.Default())
+		Expect(err).ToNot(HaveOccurred())
+		current, err := json.Marshal(s)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).To(MatchJSON(published), "run go test ./test/conformance -update and review the changes")
+	})
+
+	It("should be passed by the reference implementation", func() {
+		results, err := conformance.Run(conformance.Default(), conformance.Reference)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).ToNot(BeEmpty())
+		Expect(conformance.Failed(results)).To(BeEmpty())
+	})
+
+	It("should be loaded from a file", func() {
+		s, err := conformance.Load(vectorsPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Vectors).To(HaveLen(len(conformance.Default().Vectors)))
+	})
+
+	It("should expect every error class but other", func() {
+		classes := make(map[string]bool)
+		for _, v := range conformance.Default().Vectors {
+			classes[v.Expect.Error] = true
+		}
+		Expect(classes).To(HaveKey(conformance.ErrorAnonymousLog))
+		Expect(classes).To(HaveKey(conformance.ErrorUnknownEvent))
+		Expect(classes).To(HaveKey(conformance.ErrorMalformedLog))
+		Expect(classes).To(HaveKey(conformance.ErrorInvalidSignature))
+		Expect(classes).ToNot(HaveKey(conformance.ErrorOther))
+	})
+
+	It("should report the diverging outcomes", func() {
+		results, err := conformance.Run(conformance.Default(), func(v conformance.Vector) (conformance.Outcome, error) {
+			if v.Name == "relay/signed" {
+				return conformance.Outcome{Error: conformance.ErrorInvalidSignature}, nil
+			}
+			return conformance.Reference(v)
+		})
+		Expect(err).ToNot(HaveOccurred())
+		failed := conformance.Failed(results)
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].Vector).To(Equal("relay/signed"))
+		Expect(failed[0].String()).To(ContainSubstring("got error invalid_signature"))
+	})
+})
+
+var _ = Describe("Outcome", func() {
+
+	It("should compare the outputs as JSON", func() {
+		a := conformance.Outcome{Output: json.RawMessage(`{"a": 1, "b": [true]}`)}
+		b := conformance.Outcome{Output: json.RawMessage(`{"b":[true],"a":1}`)}
+		Expect(a.Equal(b)).To(BeTrue())
+		Expect(a.Equal(conformance.Outcome{Output: json.RawMessage(`{"a":2,"b":[true]}`)})).To(BeFalse())
+		Expect(a.Equal(conformance.Outcome{})).To(BeFalse())
+	})
+
+	It("should not round large integers", func() {
+		a := conformance.Outcome{Output: json.RawMessage(`{"a":100000000000000000001}`)}
+		b := conformance.Outcome{Output: json.RawMessage(`{"a":100000000000000000000}`)}
+		Expect(a.Equal(b)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Classify", func() {
+
+	It("should classify the decoding errors", func() {
+		_, err := indexer.NewEvent(indexer.Contract{}, types.Log{})
+		Expect(conformance.Classify(err)).To(Equal(conformance.ErrorAnonymousLog))
+		Expect(conformance.Classify(nil)).To(BeEmpty())
+		Expect(conformance.Classify(signing.ErrInvalidSignature)).To(Equal(conformance.ErrorInvalidSignature))
+		Expect(conformance.Classify(json.Unmarshal([]byte("{"), new(interface{})))).To(Equal(conformance.ErrorOther))
+	})
+})
@@ -0,0 +1,124 @@
+package controller_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("AccessClient", func() {
+
+	var client *bindings.AccessClient
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewAccessClient(ControllerContractAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return its address", func() {
+		Expect(client.Address()).To(Equal(ControllerContractAddress))
+	})
+
+	It("should return the roles of the accounts", func() {
+		r, err := client.Roles(ctx, ControllerOwner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal(bindings.Roles{Owner: true}))
+
+		r, err = client.Roles(ctx, ControllerAdmin.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal(bindings.Roles{Admin: true}))
+
+		r, err = client.Roles(ctx, Controller.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal(bindings.Roles{Controller: true}))
+
+		r, err = client.Roles(ctx, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r).To(Equal(bindings.Roles{}))
+	})
+
+	It("should require the roles", func() {
+		Expect(client.RequireAdmin(ctx, ControllerAdmin.Address())).To(Succeed())
+		Expect(client.RequireController(ctx, Controller.Address())).To(Succeed())
+		Expect(errors.Cause(client.RequireAdmin(ctx, Controller.Address()))).To(Equal(bindings.ErrNotAdmin))
+		Expect(errors.Cause(client.RequireController(ctx, RandomAccount.Address()))).To(Equal(bindings.ErrNotController))
+	})
+
+	It("should list the members", func() {
+		admins, controllers, err := client.Members(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(admins).To(Equal([]common.Address{ControllerAdmin.Address()}))
+		Expect(controllers).To(ContainElement(Controller.Address()))
+	})
+
+	It("should grant and revoke the controller role", func() {
+		tx, err := client.GrantController(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+		Expect(client.RequireController(ctx, RandomAccount.Address())).To(Succeed())
+
+		tx, err = client.RevokeController(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+		Expect(client.RequireController(ctx, RandomAccount.Address())).ToNot(Succeed())
+	})
+
+	It("should grant and revoke the admin role", func() {
+		tx, err := client.GrantAdmin(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+		Expect(client.RequireAdmin(ctx, RandomAccount.Address())).To(Succeed())
+
+		tx, err = client.RevokeAdmin(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+		Expect(client.RequireAdmin(ctx, RandomAccount.Address())).ToNot(Succeed())
+	})
+
+	It("should not send the role changes bound to fail", func() {
+		_, err := client.GrantAdmin(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(errors.Cause(err)).To(Equal(bindings.ErrNotOwner))
+
+		_, err = client.GrantController(Controller.TransactOpts(), RandomAccount.Address())
+		Expect(errors.Cause(err)).To(Equal(bindings.ErrNotAdmin))
+
+		_, err = client.GrantController(ControllerAdmin.TransactOpts(), Controller.Address())
+		Expect(err).To(MatchError(ContainSubstring("already a controller")))
+
+		_, err = client.RevokeAdmin(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		Expect(err).To(MatchError(ContainSubstring("not an admin")))
+	})
+
+	When("the controller is stopped", func() {
+
+		BeforeEach(func() {
+			tx, err := ControllerContract.Stop(ControllerAdmin.TransactOpts())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should grant no admin nor controller role", func() {
+			r, err := client.Roles(ctx, ControllerAdmin.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r).To(Equal(bindings.Roles{}))
+			Expect(client.RequireController(ctx, Controller.Address())).To(Equal(bindings.ErrControllerStopped))
+		})
+
+		It("should not send role changes", func() {
+			_, err := client.GrantController(ControllerOwner.TransactOpts(), RandomAccount.Address())
+			Expect(err).To(Equal(bindings.ErrControllerStopped))
+		})
+	})
+})
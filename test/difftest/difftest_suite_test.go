@@ -0,0 +1,18 @@
+package difftest_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update saves the traces of the scenarios instead of comparing them, it is
+// run with the old bindings before they are regenerated.
+var update = flag.Bool("update", false, "save the traces of the scenarios in testdata")
+
+func TestDifftestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Binding Differential Suite")
+}
@@ -0,0 +1,67 @@
+package difftest_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/difftest"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+var config = testutil.Config{Accounts: 2}
+
+var _ = Describe("Scenarios", func() {
+
+	for name, scenario := range scenarios {
+		name, scenario := name, scenario
+		path := filepath.Join("testdata", name+".json")
+
+		It(fmt.Sprintf("should behave as the previous bindings in the %s scenario", name), func() {
+			trace, err := difftest.Run(context.Background(), config, scenario)
+			Expect(err).ToNot(HaveOccurred())
+			if *update {
+				Expect(trace.Save(path)).To(Succeed())
+				return
+			}
+
+			previous, err := difftest.LoadTrace(path)
+			Expect(err).ToNot(HaveOccurred())
+			for _, d := range difftest.Compare(previous, trace) {
+				Fail(d.String())
+			}
+		})
+	}
+})
+
+var _ = Describe("Compare", func() {
+
+	It("should report the diverging entries", func() {
+		old, err := difftest.Run(context.Background(), config, tknScenario)
+		Expect(err).ToNot(HaveOccurred())
+		new, err := difftest.Run(context.Background(), config, func(ctx context.Context, env *difftest.Env) error {
+			// A transfer not sent through the recorded backend still
+			// shows in the balances recorded by the scenario.
+			_, err := env.Chain.TKN.Transfer(env.Chain.Accounts[0].Session.TransactOpts(), env.Chain.Accounts[1].Address, big.NewInt(1))
+			if err != nil {
+				return err
+			}
+			env.Chain.Commit()
+			return tknScenario(ctx, env)
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(difftest.Compare(old, new)).ToNot(BeEmpty())
+		Expect(difftest.Compare(old, old)).To(BeEmpty())
+	})
+
+	It("should report a shorter trace", func() {
+		old := difftest.Trace{{Kind: difftest.Value, Name: "a"}, {Kind: difftest.Value, Name: "b"}}
+		diffs := difftest.Compare(old, old[:1])
+		Expect(diffs).To(HaveLen(1))
+		Expect(diffs[0].Index).To(Equal(1))
+		Expect(diffs[0].New).To(BeNil())
+	})
+})
@@ -0,0 +1,179 @@
+package difftest_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/difftest"
+)
+
+// scenarios exercise the bindings of the contracts which can be deployed
+// without the ENS setup.
+var scenarios = map[string]difftest.Scenario{
+	"tkn":        tknScenario,
+	"token":      tokenScenario,
+	"controller": controllerScenario,
+}
+
+func tknScenario(ctx context.Context, env *difftest.Env) error {
+	tkn, err := mocks.NewBurnerToken(env.Chain.TKNAddress, env.Backend)
+	if err != nil {
+		return err
+	}
+	alice, bob := env.Chain.Accounts[0], env.Chain.Accounts[1]
+
+	_, err = tkn.Transfer(alice.Session.TransactOpts(), bob.Address, big.NewInt(100))
+	if err != nil {
+		return err
+	}
+	_, err = tkn.Approve(bob.Session.TransactOpts(), alice.Address, big.NewInt(50))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = tkn.TransferFrom(alice.Session.TransactOpts(), bob.Address, alice.Address, big.NewInt(30))
+	if err != nil {
+		return err
+	}
+	_, err = tkn.IncreaseApproval(bob.Session.TransactOpts(), alice.Address, big.NewInt(5))
+	if err != nil {
+		return err
+	}
+	_, err = tkn.DecreaseApproval(bob.Session.TransactOpts(), alice.Address, big.NewInt(10))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range env.Chain.Accounts {
+		balance, err := tkn.BalanceOf(nil, a.Address)
+		if err != nil {
+			return err
+		}
+		err = env.Record("balance "+a.Address.Hex(), balance)
+		if err != nil {
+			return err
+		}
+	}
+	allowance, err := tkn.Allowance(nil, bob.Address, alice.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Record("allowance", allowance)
+	if err != nil {
+		return err
+	}
+	supply, err := tkn.TotalSupply(nil)
+	if err != nil {
+		return err
+	}
+	return env.Record("total supply", supply)
+}
+
+func tokenScenario(ctx context.Context, env *difftest.Env) error {
+	deployer, alice := env.Chain.Deployer, env.Chain.Accounts[0]
+	_, _, token, err := mocks.DeployToken(deployer.Session.TransactOpts(), env.Backend)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = token.Credit(deployer.Session.TransactOpts(), alice.Address, big.NewInt(1000))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = token.Debit(deployer.Session.TransactOpts(), alice.Address, big.NewInt(400))
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	balance, err := token.BalanceOf(nil, alice.Address)
+	if err != nil {
+		return err
+	}
+	return env.Record("balance", balance)
+}
+
+func controllerScenario(ctx context.Context, env *difftest.Env) error {
+	owner, admin, controller := env.Chain.Deployer, env.Chain.Accounts[0], env.Chain.Accounts[1]
+	_, _, c, err := bindings.DeployController(owner.Session.TransactOpts(), env.Backend, owner.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.AddAdmin(owner.Session.TransactOpts(), admin.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.AddController(admin.Session.TransactOpts(), controller.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	isAdmin, err := c.IsAdmin(nil, admin.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Record("is admin", isAdmin)
+	if err != nil {
+		return err
+	}
+	isController, err := c.IsController(nil, controller.Address)
+	if err != nil {
+		return err
+	}
+	err = env.Record("is controller", isController)
+	if err != nil {
+		return err
+	}
+	count, err := c.ControllerCount(nil)
+	if err != nil {
+		return err
+	}
+	err = env.Record("controller count", count)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Stop(admin.Session.TransactOpts())
+	if err != nil {
+		return err
+	}
+	err = env.Commit(ctx)
+	if err != nil {
+		return err
+	}
+	stopped, err := c.IsStopped(nil)
+	if err != nil {
+		return err
+	}
+	return env.Record("stopped", stopped)
+}
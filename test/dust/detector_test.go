@@ -0,0 +1,171 @@
+package dust_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/dust"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Detector", func() {
+
+	var detector *dust.Detector
+	var alerted []dust.Finding
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "dust")
+		Expect(err).ToNot(HaveOccurred())
+
+		alerted = nil
+		detector = &dust.Detector{
+			Backend: Chain,
+			Contracts: []dust.Contract{
+				{Name: "token_whitelist", Address: TokenWhitelistAddress, Claimable: true},
+				{Name: "licence", Address: LicenceAddress, Claimable: true},
+				{Name: "holder", Address: TokenHolderAddress, HoldsETH: true, Tokens: []common.Address{StablecoinAddress}},
+			},
+			Books: dust.NewFileBooks(filepath.Join(dir, "dust.jsonl")),
+			Alert: func(ctx context.Context, findings []dust.Finding) error {
+				alerted = append(alerted, findings...)
+				return nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should find nothing when no dust was sent", func() {
+		findings, err := detector.Check(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+		Expect(alerted).To(BeEmpty())
+	})
+
+	When("TKN and ETH are sent to contracts", func() {
+
+		var tknTx *types.Transaction
+
+		BeforeEach(func() {
+			var err error
+			tknTx, err = TKNBurner.Mint(BankAccount.TransactOpts(), TokenWhitelistAddress, big.NewInt(1234))
+			Chain.CommitTx(tknTx, err)
+			Chain.CommitTx(TKNBurner.Mint(BankAccount.TransactOpts(), TokenHolderAddress, big.NewInt(99)))
+
+			err = BankAccount.Transfer(Backend, LicenceAddress, FinneyToWei(5))
+			Expect(err).ToNot(HaveOccurred())
+			err = BankAccount.Transfer(Backend, TokenHolderAddress, FinneyToWei(5))
+			Expect(err).ToNot(HaveOccurred())
+			Chain.Advance(2)
+		})
+
+		It("should find them and alert them", func() {
+			findings, err := detector.Check(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(alerted).To(Equal(findings))
+			Expect(findings).To(HaveLen(3))
+
+			Expect(findings[0].Contract).To(Equal("token_whitelist"))
+			Expect(findings[0].Standard).To(Equal(dust.ERC20))
+			Expect(findings[0].Asset).To(Equal(TKNBurnerAddress))
+			Expect(findings[0].Amount.String()).To(Equal("1234"))
+			Expect(findings[0].TxHash).To(Equal(tknTx.Hash()))
+			Expect(findings[0].Recoverable).To(BeTrue())
+
+			// The holder is meant to hold ETH, only the TKN is reported.
+			Expect(findings[1].Contract).To(Equal("holder"))
+			Expect(findings[1].Standard).To(Equal(dust.ERC20))
+			Expect(findings[1].Recoverable).To(BeFalse())
+
+			Expect(findings[2].Contract).To(Equal("licence"))
+			Expect(findings[2].Standard).To(Equal(dust.ETH))
+			Expect(findings[2].Amount).To(Equal(FinneyToWei(5)))
+		})
+
+		It("should record them in the books", func() {
+			findings, err := detector.Check(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			recorded, err := detector.Books.Findings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorded).To(HaveLen(len(findings)))
+			Expect(recorded[0].Amount.String()).To(Equal("1234"))
+		})
+
+		When("they were already found", func() {
+			BeforeEach(func() {
+				_, err := detector.Check(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should not find them again", func() {
+				findings, err := detector.Check(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings).To(BeEmpty())
+			})
+
+			It("should resume from the books after a restart", func() {
+				restarted := &dust.Detector{Backend: Chain, Contracts: detector.Contracts, Books: detector.Books}
+				findings, err := restarted.Check(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings).To(BeEmpty())
+			})
+
+			It("should only find the ETH sent since", func() {
+				err := BankAccount.Transfer(Backend, LicenceAddress, FinneyToWei(1))
+				Expect(err).ToNot(HaveOccurred())
+				Chain.Advance(1)
+
+				findings, err := detector.Check(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings).To(HaveLen(1))
+				Expect(findings[0].Amount).To(Equal(FinneyToWei(1)))
+				Expect(findings[0].Balance).To(Equal(FinneyToWei(6)))
+			})
+		})
+	})
+
+	It("should find the ERC721 tokens, which cannot be claimed", func() {
+		token := common.HexToAddress("0x721")
+		detector.Backend = &logs{TestChain: Chain, logs: []types.Log{{
+			Address: token,
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.BytesToHash(RandomAccount.Address().Bytes()),
+				common.BytesToHash(TokenWhitelistAddress.Bytes()),
+				common.BigToHash(big.NewInt(42)),
+			},
+		}}}
+		findings, err := detector.Check(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Standard).To(Equal(dust.ERC721))
+		Expect(findings[0].Asset).To(Equal(token))
+		Expect(findings[0].From).To(Equal(RandomAccount.Address()))
+		Expect(findings[0].Amount.String()).To(Equal("42"))
+		Expect(findings[0].Recoverable).To(BeFalse())
+	})
+})
+
+// logs returns canned logs instead of those of the chain.
+type logs struct {
+	*TestChain
+	logs []types.Log
+}
+
+func (l *logs) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return l.logs, nil
+}
@@ -0,0 +1,31 @@
+package dust_test
+
+import (
+	"math/big"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestDustSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dust Suite")
+}
+
+var Chain *TestChain
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = NewTestChain(Backend, MineManually)
+
+	// Move the head to the latest block, the only one the backend serves.
+	Chain.CommitTx(TKNBurner.Mint(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(1)))
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
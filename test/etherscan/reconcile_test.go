@@ -0,0 +1,222 @@
+package etherscan_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/etherscan"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+// explorer serves logs like the logs endpoint of Etherscan, which writes zero
+// as "0x".
+type explorer struct {
+	logs     []types.Log
+	requests []url.Values
+	// failure is the message of the error returned instead of the logs.
+	failure string
+}
+
+func (x *explorer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	x.requests = append(x.requests, q)
+	if x.failure != "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "NOTOK", "result": x.failure})
+		return
+	}
+	from, _ := strconv.ParseUint(q.Get("fromBlock"), 10, 64)
+	to, _ := strconv.ParseUint(q.Get("toBlock"), 10, 64)
+	page, _ := strconv.Atoi(q.Get("page"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	hex := func(n uint64) string {
+		if n == 0 {
+			return "0x"
+		}
+		return hexutil.EncodeUint64(n)
+	}
+	var matching []map[string]interface{}
+	for _, l := range x.logs {
+		if l.Address != common.HexToAddress(q.Get("address")) || l.BlockNumber < from || l.BlockNumber > to {
+			continue
+		}
+		matching = append(matching, map[string]interface{}{
+			"address":          l.Address,
+			"topics":           l.Topics,
+			"data":             hexutil.Encode(l.Data),
+			"blockNumber":      hex(l.BlockNumber),
+			"blockHash":        l.BlockHash,
+			"logIndex":         hex(uint64(l.Index)),
+			"transactionHash":  l.TxHash,
+			"transactionIndex": hex(uint64(l.TxIndex)),
+		})
+	}
+	start := (page - 1) * offset
+	if start >= len(matching) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "No records found", "result": []interface{}{}})
+		return
+	}
+	end := start + offset
+	if end > len(matching) {
+		end = len(matching)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": matching[start:end]})
+}
+
+var _ = Describe("Reconciler", func() {
+
+	var x *explorer
+	var server *httptest.Server
+	var client *etherscan.Client
+	var store *indexer.MemoryStore
+	var reconciler *etherscan.Reconciler
+	var contract indexer.Contract
+	var logs []types.Log
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		contract = indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed}
+
+		logs = nil
+		for i := 0; i < 2; i++ {
+			tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.BigToAddress(common.Big1))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			r, err := Backend.TransactionReceipt(ctx, tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+			logs = append(logs, *r.Logs[0])
+		}
+
+		store = indexer.NewMemoryStore()
+		var events []indexer.Event
+		for _, l := range logs {
+			e, err := indexer.NewEvent(contract, l)
+			Expect(err).ToNot(HaveOccurred())
+			events = append(events, e)
+		}
+		Expect(store.Append(logs[1].BlockNumber, events)).To(Succeed())
+
+		x = &explorer{logs: append([]types.Log(nil), logs...)}
+		server = httptest.NewServer(x)
+		client = etherscan.NewClient(server.URL, "secret")
+		reconciler = etherscan.NewReconciler(client, store, contract)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("finds no discrepancy when the explorer has the indexed events", func() {
+		report, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Indexed).To(Equal(2))
+		Expect(report.Explorer).To(Equal(2))
+		Expect(report.Discrepancies).To(BeEmpty())
+		Expect(x.requests[0].Get("apikey")).To(Equal("secret"))
+		Expect(x.requests[0].Get("address")).To(Equal(LicenceAddress.Hex()))
+	})
+
+	It("reports the events of the explorer missing from the index", func() {
+		store = indexer.NewMemoryStore()
+		Expect(store.Append(logs[1].BlockNumber, nil)).To(Succeed())
+		reconciler = etherscan.NewReconciler(client, store, contract)
+
+		report, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Discrepancies).To(HaveLen(2))
+		Expect(report.Discrepancies[0].Kind).To(Equal(etherscan.Missing))
+		Expect(report.Discrepancies[0].Event.TxHash).To(Equal(logs[0].TxHash))
+		Expect(report.Discrepancies[0].Event.Name).To(Equal("UpdatedLicenceDAO"))
+	})
+
+	It("reports the indexed events the explorer does not know", func() {
+		x.logs = x.logs[1:]
+		report, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Discrepancies).To(HaveLen(1))
+		Expect(report.Discrepancies[0].Kind).To(Equal(etherscan.Unknown))
+		Expect(report.Discrepancies[0].Event.TxHash).To(Equal(logs[0].TxHash))
+	})
+
+	It("reports the events whose content differs", func() {
+		x.logs[1].BlockHash = common.HexToHash("0x1")
+		report, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Discrepancies).To(HaveLen(1))
+		d := report.Discrepancies[0]
+		Expect(d.Kind).To(Equal(etherscan.Mismatch))
+		Expect(d.Event.BlockHash).To(Equal(logs[1].BlockHash))
+		Expect(d.Explorer.BlockHash).To(Equal(common.HexToHash("0x1")))
+	})
+
+	It("compares the arguments of the stores holding them formatted", func() {
+		dir, err := ioutil.TempDir("", "etherscan")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		segments, err := indexer.OpenSegmentStore(dir)
+		Expect(err).ToNot(HaveOccurred())
+		defer segments.Close()
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(segments.Append(logs[1].BlockNumber, events)).To(Succeed())
+
+		report, err := etherscan.NewReconciler(client, segments, contract).Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Discrepancies).To(BeEmpty())
+	})
+
+	It("requests the logs page by page", func() {
+		client.PageSize = 1
+		report, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Discrepancies).To(BeEmpty())
+		Expect(x.requests).To(HaveLen(3))
+		Expect(x.requests[2].Get("page")).To(Equal("3"))
+	})
+
+	It("fails on the errors of the explorer without leaking the key", func() {
+		x.failure = "Invalid API Key"
+		_, err := reconciler.Check(ctx, 0, logs[1].BlockNumber)
+		Expect(err).To(MatchError(ContainSubstring("Invalid API Key")))
+		Expect(err.Error()).ToNot(ContainSubstring("secret"))
+	})
+
+	It("reconciles the confirmed blocks indexed since the previous check", func() {
+		x.logs = x.logs[1:]
+		alerted := make(chan []etherscan.Discrepancy, 1)
+		reconciler.Confirmations = logs[1].BlockNumber - logs[0].BlockNumber
+		reconciler.Interval = time.Hour
+		reconciler.Alert = func(ctx context.Context, d []etherscan.Discrepancy) error {
+			alerted <- d
+			return nil
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go reconciler.Run(ctx)
+
+		var d []etherscan.Discrepancy
+		Eventually(alerted).Should(Receive(&d))
+		Expect(d).To(HaveLen(1))
+		Expect(d[0].Kind).To(Equal(etherscan.Unknown))
+		Expect(x.requests[0].Get("toBlock")).To(Equal(strconv.FormatUint(logs[0].BlockNumber, 10)))
+	})
+})
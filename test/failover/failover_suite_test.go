@@ -0,0 +1,133 @@
+package failover_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/failover"
+)
+
+func TestFailoverSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Failover Suite")
+}
+
+// node is an endpoint answering the calls with its name, failing them with
+// err when it is set or hanging until the call times out when hang is set.
+// The other methods of a Node are not implemented.
+type node struct {
+	failover.Node
+
+	mu    sync.Mutex
+	name  string
+	head  uint64
+	err   error
+	hang  bool
+	calls int
+	sent  []common.Hash
+	// logs are the logs of the chain, returned up to the head of the node.
+	logs []types.Log
+}
+
+func (n *node) answer(ctx context.Context) error {
+	n.mu.Lock()
+	n.calls++
+	err, hang := n.err, n.hang
+	n.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return err
+}
+
+func (n *node) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	err := n.answer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(n.name), nil
+}
+
+func (n *node) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	err := n.answer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n.head)}, nil
+}
+
+func (n *node) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	err := n.answer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var logs []types.Log
+	for _, l := range n.logs {
+		if l.BlockNumber > n.head || l.BlockNumber < q.FromBlock.Uint64() || (q.ToBlock != nil && l.BlockNumber > q.ToBlock.Uint64()) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+func (n *node) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := n.answer(ctx)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, tx.Hash())
+	return err
+}
+
+func (n *node) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	err := n.answer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, ethereum.NotFound
+}
+
+func (n *node) set(err error, hang bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.err, n.hang = err, hang
+}
+
+func (n *node) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls
+}
+
+// call returns the name of the endpoint answering a call.
+func call(b *failover.Backend) (string, error) {
+	out, err := b.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	return string(out), err
+}
+
+var Nodes []*node
+var Backend *failover.Backend
+
+var _ = BeforeEach(func() {
+	Nodes = []*node{{name: "a", head: 100}, {name: "b", head: 100}, {name: "c", head: 100}}
+	var endpoints []failover.Endpoint
+	for _, n := range Nodes {
+		endpoints = append(endpoints, failover.Endpoint{Name: n.name, Node: n})
+	}
+	var err error
+	Backend, err = failover.New(endpoints...)
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Timeout = 50 * time.Millisecond
+	Backend.RetryAfter = time.Hour
+})
@@ -0,0 +1,18 @@
+package fixtures_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update saves the fixtures of the scenarios instead of comparing them, when the
+// scenarios or the contracts changed.
+var update = flag.Bool("update", false, "save the fixtures of the scenarios in pkg/fixtures/data")
+
+func TestFixturesSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fixtures Suite")
+}
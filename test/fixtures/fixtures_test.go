@@ -0,0 +1,84 @@
+package fixtures_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/difftest"
+	"github.com/tokencard/contracts/v2/pkg/fixtures"
+)
+
+var _ = Describe("Fixtures", func() {
+
+	for _, name := range fixtures.Names() {
+		name := name
+
+		It(fmt.Sprintf("should reproduce the %s fixture", name), func() {
+			f, err := difftest.GenerateFixture(context.Background(), fixtures.Config, name, fixtures.Scenarios[name])
+			Expect(err).ToNot(HaveOccurred())
+			if *update {
+				Expect(f.Save(filepath.Join("../../pkg/fixtures", fixtures.Dir, name+".json"))).To(Succeed())
+				return
+			}
+
+			// The fixtures are compared as their readers decode them.
+			committed, err := fixtures.Load(name)
+			Expect(err).ToNot(HaveOccurred())
+			want, err := json.Marshal(committed)
+			Expect(err).ToNot(HaveOccurred())
+			got, err := json.Marshal(f)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(MatchJSON(want))
+		})
+	}
+
+	It("should decode the transactions and the events", func() {
+		f, err := fixtures.Load("controller")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Version).To(Equal(difftest.FixtureVersion))
+		Expect(f.Accounts).To(HaveLen(4))
+		address := f.Contracts["controller"]
+		Expect(address).ToNot(Equal(common.Address{}))
+
+		Expect(f.Transactions[0].To).To(BeNil())
+		Expect(*f.Transactions[0].ContractAddress).To(Equal(address))
+		Expect(f.Transactions[0].Contract).To(Equal("controller"))
+		var methods []string
+		for _, t := range f.Transactions[1:] {
+			Expect(*t.To).To(Equal(address))
+			Expect(t.Status).To(Equal(uint64(1)))
+			methods = append(methods, t.Method)
+		}
+		Expect(methods).To(Equal([]string{"addAdmin", "addController", "addController", "removeController", "stop", "start"}))
+
+		var names []string
+		for _, e := range f.Events {
+			Expect(e.Contract).To(Equal("controller"))
+			names = append(names, e.Name)
+		}
+		Expect(names).To(ContainElement("AddedAdmin"))
+		Expect(names).To(ContainElement("RemovedController"))
+	})
+
+	It("should format the arguments of the events", func() {
+		f, err := fixtures.Load("tkn")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Events).ToNot(BeEmpty())
+		for _, e := range f.Events {
+			Expect(e.Contract).To(Equal("tkn"))
+			for _, v := range e.Args {
+				Expect(v).To(BeAssignableToTypeOf(""))
+			}
+		}
+	})
+
+	It("should fail on an unknown scenario", func() {
+		_, err := fixtures.Load("missing")
+		Expect(err).To(HaveOccurred())
+	})
+})
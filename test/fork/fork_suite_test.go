@@ -0,0 +1,13 @@
+package fork_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestForkSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fork Suite")
+}
@@ -0,0 +1,76 @@
+package fork_test
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/fork"
+)
+
+// These specs run against a node forking mainnet, started e.g. with
+// anvil --fork-url $MAINNET_RPC_URL, and are skipped unless
+// MONOLITH_FORK_URL is its URL.
+var _ = Describe("Mainnet fork", func() {
+
+	var node *fork.Node
+	var ctx context.Context
+	var snapshot string
+
+	BeforeEach(func() {
+		url := os.Getenv("MONOLITH_FORK_URL")
+		if url == "" {
+			Skip("MONOLITH_FORK_URL is not set")
+		}
+		ctx = context.Background()
+		var err error
+		node, err = fork.Dial(ctx, url)
+		Expect(err).ToNot(HaveOccurred())
+		snapshot, err = node.Snapshot(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if node != nil {
+			Expect(node.Revert(ctx, snapshot)).To(Succeed())
+			node.Close()
+		}
+	})
+
+	It("should let the owner of the deployed controller add an admin", func() {
+		address, err := node.Resolve(ctx, "controller.tokencard.eth")
+		Expect(err).ToNot(HaveOccurred())
+		controller, err := bindings.NewController(address, node)
+		Expect(err).ToNot(HaveOccurred())
+
+		opts, err := node.ImpersonateOwner(ctx, address)
+		Expect(err).ToNot(HaveOccurred())
+		admin := common.HexToAddress("0x000000000000000000000000000000000000ad31")
+		tx, err := controller.AddAdmin(opts, admin)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Mine(ctx, 1)).To(Succeed())
+		receipt, err := bind.WaitMined(ctx, node, tx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipt.Status).To(BeEquivalentTo(1))
+
+		isAdmin, err := controller.IsAdmin(nil, admin)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isAdmin).To(BeTrue())
+	})
+
+	It("should fast forward the chain", func() {
+		before, err := node.HeaderByNumber(ctx, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.IncreaseTime(ctx, 24*time.Hour)).To(Succeed())
+		Expect(node.Mine(ctx, 10)).To(Succeed())
+		after, err := node.HeaderByNumber(ctx, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after.Number.Uint64()).To(Equal(before.Number.Uint64() + 11))
+		Expect(after.Time).To(BeNumerically(">=", before.Time+24*60*60))
+	})
+})
@@ -0,0 +1,223 @@
+package fork_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/fork"
+)
+
+// fakeNode serves the methods of a forking node the tests use, recording
+// their arguments.
+type fakeNode struct {
+	mu           sync.Mutex
+	owner        common.Address
+	impersonated map[common.Address]bool
+	balances     map[common.Address]*big.Int
+	mined        uint64
+	increased    int64
+	snapshots    int
+	sent         []map[string]interface{}
+	receipts     []common.Hash
+}
+
+type hardhatAPI struct{ n *fakeNode }
+
+func (a hardhatAPI) ImpersonateAccount(account common.Address) error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.impersonated[account] = true
+	return nil
+}
+
+func (a hardhatAPI) StopImpersonatingAccount(account common.Address) error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	delete(a.n.impersonated, account)
+	return nil
+}
+
+func (a hardhatAPI) SetBalance(account common.Address, balance *hexutil.Big) error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.balances[account] = balance.ToInt()
+	return nil
+}
+
+func (a hardhatAPI) Mine(blocks hexutil.Uint64) error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.mined += uint64(blocks)
+	return nil
+}
+
+type evmAPI struct{ n *fakeNode }
+
+func (a evmAPI) IncreaseTime(seconds int64) error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.increased += seconds
+	return nil
+}
+
+func (a evmAPI) Mine() error {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.mined++
+	return nil
+}
+
+func (a evmAPI) Snapshot() string {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.snapshots++
+	return hexutil.EncodeUint64(uint64(a.n.snapshots))
+}
+
+func (a evmAPI) Revert(id string) bool {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	return id == hexutil.EncodeUint64(uint64(a.n.snapshots))
+}
+
+type ethAPI struct{ n *fakeNode }
+
+func (a ethAPI) Call(args map[string]interface{}, block string) hexutil.Bytes {
+	return common.LeftPadBytes(a.n.owner.Bytes(), 32)
+}
+
+func (a ethAPI) GetBalance(account common.Address, block string) *hexutil.Big {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	if b, ok := a.n.balances[account]; ok {
+		return (*hexutil.Big)(b)
+	}
+	return new(hexutil.Big)
+}
+
+func (a ethAPI) SendTransaction(args map[string]interface{}) common.Hash {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.sent = append(a.n.sent, args)
+	return common.BigToHash(big.NewInt(int64(len(a.n.sent))))
+}
+
+func (a ethAPI) GetTransactionReceipt(h common.Hash) *types.Receipt {
+	a.n.mu.Lock()
+	defer a.n.mu.Unlock()
+	a.n.receipts = append(a.n.receipts, h)
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: h, Logs: []*types.Log{}}
+}
+
+var _ = Describe("Node", func() {
+
+	var fake *fakeNode
+	var node *fork.Node
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = &fakeNode{
+			owner:        common.HexToAddress("0x0000000000000000000000000000000000000a11"),
+			impersonated: make(map[common.Address]bool),
+			balances:     make(map[common.Address]*big.Int),
+		}
+		server := rpc.NewServer()
+		Expect(server.RegisterName("hardhat", hardhatAPI{fake})).To(Succeed())
+		Expect(server.RegisterName("evm", evmAPI{fake})).To(Succeed())
+		Expect(server.RegisterName("eth", ethAPI{fake})).To(Succeed())
+		node = fork.NewNode(rpc.DialInProc(server))
+	})
+
+	Describe("Impersonate", func() {
+
+		var account common.Address
+		var tx *types.Transaction
+
+		BeforeEach(func() {
+			account = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+			opts, err := node.Impersonate(ctx, account)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.impersonated[account]).To(BeTrue())
+
+			to := common.HexToAddress("0x0000000000000000000000000000000000000c0c")
+			tx = types.NewTransaction(3, to, big.NewInt(5), 21000, big.NewInt(1), []byte{0x01})
+			tx, err = opts.Signer(types.HomesteadSigner{}, account, tx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.SendTransaction(ctx, tx)).To(Succeed())
+		})
+
+		It("should send the transactions from the account", func() {
+			Expect(fake.sent).To(HaveLen(1))
+			Expect(fake.sent[0]["from"]).To(Equal(strings.ToLower(account.Hex())))
+			Expect(fake.sent[0]["to"]).To(Equal("0x0000000000000000000000000000000000000c0c"))
+			Expect(fake.sent[0]["nonce"]).To(Equal("0x3"))
+			Expect(fake.sent[0]["value"]).To(Equal("0x5"))
+			Expect(fake.sent[0]["data"]).To(Equal("0x01"))
+		})
+
+		It("should find the receipt by the hash of the bindings", func() {
+			_, err := node.TransactionReceipt(ctx, tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.receipts).To(Equal([]common.Hash{common.BigToHash(big.NewInt(1))}))
+		})
+
+		It("should refuse to sign for another account", func() {
+			opts, err := node.Impersonate(ctx, account)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = opts.Signer(types.HomesteadSigner{}, fake.owner, tx)
+			Expect(err).To(MatchError(ContainSubstring("not impersonating")))
+		})
+
+		It("should stop impersonating", func() {
+			Expect(node.StopImpersonating(ctx, account)).To(Succeed())
+			Expect(fake.impersonated[account]).To(BeFalse())
+		})
+	})
+
+	Describe("ImpersonateOwner", func() {
+
+		It("should impersonate and fund the owner", func() {
+			opts, err := node.ImpersonateOwner(ctx, common.HexToAddress("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts.From).To(Equal(fake.owner))
+			Expect(fake.impersonated[fake.owner]).To(BeTrue())
+			Expect(fake.balances[fake.owner]).To(Equal(fork.OwnerBalance))
+		})
+
+		It("should not lower the balance of the owner", func() {
+			balance := new(big.Int).Mul(fork.OwnerBalance, big.NewInt(2))
+			fake.balances[fake.owner] = balance
+			_, err := node.ImpersonateOwner(ctx, common.HexToAddress("0x01"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.balances[fake.owner]).To(Equal(balance))
+		})
+	})
+
+	It("should mine blocks", func() {
+		Expect(node.Mine(ctx, 10)).To(Succeed())
+		Expect(fake.mined).To(BeEquivalentTo(10))
+	})
+
+	It("should increase the time and mine a block", func() {
+		Expect(node.IncreaseTime(ctx, 24*time.Hour)).To(Succeed())
+		Expect(fake.increased).To(BeEquivalentTo(24 * 60 * 60))
+		Expect(fake.mined).To(BeEquivalentTo(1))
+	})
+
+	It("should revert to a snapshot", func() {
+		id, err := node.Snapshot(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node.Revert(ctx, id)).To(Succeed())
+		Expect(node.Revert(ctx, "0x42")).To(MatchError(ContainSubstring("unknown snapshot")))
+	})
+})
@@ -0,0 +1,13 @@
+package gas_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestGasSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gas Suite")
+}
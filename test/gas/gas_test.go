@@ -0,0 +1,97 @@
+package gas_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/gas"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+type fixedPricer struct {
+	price *big.Int
+}
+
+func (f fixedPricer) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return f.price, nil
+}
+
+var _ = Describe("NodeSource", func() {
+
+	source := gas.NewNodeSource(fixedPricer{GweiToWei(10)})
+
+	It("should return the node suggestion for the standard strategy", func() {
+		p, err := source.GasPrice(context.Background(), gas.Standard)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(10).String()))
+	})
+
+	It("should increase the node suggestion for the fast strategy", func() {
+		p, err := source.GasPrice(context.Background(), gas.Fast)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal("12500000000"))
+	})
+
+	It("should decrease the node suggestion for the slow strategy", func() {
+		p, err := source.GasPrice(context.Background(), gas.Slow)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(8).String()))
+	})
+})
+
+var _ = Describe("StationSource", func() {
+
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"fast": 200.0, "average": 100.0, "safeLow": 15.0}`)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should convert the fast price from tenths of gwei", func() {
+		p, err := gas.NewStationSource(server.URL, nil).GasPrice(context.Background(), gas.Fast)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(20).String()))
+	})
+
+	It("should use the average price for the standard strategy", func() {
+		p, err := gas.NewStationSource(server.URL, nil).GasPrice(context.Background(), gas.Standard)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(10).String()))
+	})
+
+	It("should use the safe low price for the slow strategy", func() {
+		p, err := gas.NewStationSource(server.URL, nil).GasPrice(context.Background(), gas.Slow)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal("1500000000"))
+	})
+})
+
+var _ = Describe("Oracle", func() {
+
+	It("should bound the price to the configured maximum", func() {
+		o := gas.NewOracle(gas.NewNodeSource(fixedPricer{GweiToWei(100)}), gas.Fast)
+		o.Max = GweiToWei(50)
+		p, err := o.SuggestGasPrice(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(50).String()))
+	})
+
+	It("should bound the price to the configured minimum", func() {
+		o := gas.NewOracle(gas.NewNodeSource(fixedPricer{GweiToWei(1)}), gas.Slow)
+		o.Min = GweiToWei(2)
+		p, err := o.SuggestGasPrice(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.String()).To(Equal(GweiToWei(2).String()))
+	})
+})
@@ -0,0 +1,92 @@
+package graphql_test
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/graphql"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+func TestGraphQLSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GraphQL Suite")
+}
+
+var Server *httptest.Server
+
+// Events are the events indexed up to block 15.
+func Events() []indexer.Event {
+	return []indexer.Event{
+		{
+			Contract:    "licence",
+			Address:     common.HexToAddress("0x10"),
+			Name:        "UpdatedLicenceAmount",
+			BlockNumber: 10,
+			TxHash:      common.HexToHash("0xa"),
+			Args:        map[string]interface{}{"_newAmount": big.NewInt(10)},
+		},
+		{
+			Contract:    "licence",
+			Address:     common.HexToAddress("0x10"),
+			Name:        "UpdatedLicenceDAO",
+			BlockNumber: 11,
+			TxHash:      common.HexToHash("0xb"),
+			Args:        map[string]interface{}{"_newDAO": common.HexToAddress("0x20")},
+		},
+		{
+			Contract:    "controller",
+			Address:     common.HexToAddress("0x30"),
+			Name:        "AddedController",
+			BlockNumber: 12,
+			TxHash:      common.HexToHash("0xc"),
+			Args:        map[string]interface{}{"_sender": common.HexToAddress("0x40"), "_controller": common.HexToAddress("0x50")},
+		},
+		{
+			Contract:    "token_whitelist",
+			Name:        "AddedToken",
+			BlockNumber: 13,
+			Args: map[string]interface{}{
+				"_token":      common.HexToAddress("0x60"),
+				"_symbol":     "TKN",
+				"_magnitude":  big.NewInt(100),
+				"_loadable":   true,
+				"_redeemable": true,
+			},
+		},
+		{
+			Contract:    "token_whitelist",
+			Name:        "AddedToken",
+			BlockNumber: 14,
+			Args: map[string]interface{}{
+				"_token":      common.HexToAddress("0x70"),
+				"_symbol":     "DAI",
+				"_magnitude":  big.NewInt(100),
+				"_loadable":   true,
+				"_redeemable": false,
+			},
+		},
+		{
+			Contract:    "token_whitelist",
+			Name:        "UpdatedTokenLoadable",
+			BlockNumber: 15,
+			Args:        map[string]interface{}{"_token": common.HexToAddress("0x60"), "_loadable": false},
+		},
+	}
+}
+
+var _ = BeforeEach(func() {
+	store := indexer.NewMemoryStore()
+	err := store.Append(15, Events())
+	Expect(err).ToNot(HaveOccurred())
+	Server = httptest.NewServer(graphql.NewHandler(store))
+})
+
+var _ = AfterEach(func() {
+	Server.Close()
+})
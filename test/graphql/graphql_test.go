@@ -0,0 +1,185 @@
+package graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/graphql"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+func query(req graphql.Request) (int, string) {
+	body, err := json.Marshal(req)
+	Expect(err).ToNot(HaveOccurred())
+	resp, err := http.Post(Server.URL, "application/json", bytes.NewReader(body))
+	Expect(err).ToNot(HaveOccurred())
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return resp.StatusCode, string(b)
+}
+
+var _ = Describe("GraphQL", func() {
+
+	It("returns the indexed head", func() {
+		status, body := query(graphql.Request{Query: "{ head }"})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"data":{"head":15}}`))
+	})
+
+	It("returns the selected fields of the events in order", func() {
+		status, body := query(graphql.Request{Query: `{ events(contract: "licence") { edges { node { name blockNumber args } } } }`})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"data":{"events":{"edges":[
+			{"node":{"name":"UpdatedLicenceAmount","blockNumber":10,"args":{"_newAmount":"10"}}},
+			{"node":{"name":"UpdatedLicenceDAO","blockNumber":11,"args":{"_newDAO":"0x0000000000000000000000000000000000000020"}}}
+		]}}}`))
+	})
+
+	It("filters the events by argument", func() {
+		_, body := query(graphql.Request{Query: `{
+			events(where: {_newDAO: "0x0000000000000000000000000000000000000020"}, fromBlock: 10, toBlock: 12) {
+				edges { node { name } }
+			}
+		}`})
+		Expect(body).To(MatchJSON(`{"data":{"events":{"edges":[{"node":{"name":"UpdatedLicenceDAO"}}]}}}`))
+	})
+
+	It("supports aliases", func() {
+		_, body := query(graphql.Request{Query: `{ latest: events(fromBlock: 12, toBlock: 12) { edges { node { event: name } } } }`})
+		Expect(body).To(MatchJSON(`{"data":{"latest":{"edges":[{"node":{"event":"AddedController"}}]}}}`))
+	})
+
+	It("resolves variables", func() {
+		_, body := query(graphql.Request{
+			Query:     `query Events($name: String!, $first: Int = 5) { events(name: $name, first: $first) { edges { node { transactionHash } } } }`,
+			Variables: map[string]interface{}{"name": "UpdatedLicenceDAO"},
+		})
+		Expect(body).To(MatchJSON(`{"data":{"events":{"edges":[{"node":{"transactionHash":"0x000000000000000000000000000000000000000000000000000000000000000b"}}]}}}`))
+	})
+
+	It("paginates the events with cursors", func() {
+		page := `query Page($after: String) {
+			events(contract: "licence", first: 1, after: $after) {
+				edges { node { name } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}`
+
+		var first struct {
+			Data struct {
+				Events struct {
+					Edges []struct {
+						Node struct{ Name string }
+					}
+					PageInfo struct {
+						HasNextPage bool
+						EndCursor   string
+					}
+				}
+			}
+		}
+		_, body := query(graphql.Request{Query: page})
+		Expect(json.Unmarshal([]byte(body), &first)).To(Succeed())
+		Expect(first.Data.Events.Edges).To(HaveLen(1))
+		Expect(first.Data.Events.Edges[0].Node.Name).To(Equal("UpdatedLicenceAmount"))
+		Expect(first.Data.Events.PageInfo.HasNextPage).To(BeTrue())
+
+		_, body = query(graphql.Request{Query: page, Variables: map[string]interface{}{"after": first.Data.Events.PageInfo.EndCursor}})
+		Expect(body).To(ContainSubstring(`"edges":[{"node":{"name":"UpdatedLicenceDAO"}}]`))
+		Expect(body).To(ContainSubstring(`"hasNextPage":false`))
+	})
+
+	It("rejects invalid cursors", func() {
+		_, body := query(graphql.Request{Query: `{ events(after: "bogus") { edges { cursor } } }`})
+		Expect(body).To(MatchJSON(`{"data":{"events":null},"errors":[{"message":"invalid value bogus for argument \"after\"","path":["events"]}]}`))
+	})
+
+	It("returns the activation status of each token", func() {
+		_, body := query(graphql.Request{Query: `{ tokens { symbol loadable redeemable updatedAt } }`})
+		Expect(body).To(MatchJSON(`{"data":{"tokens":[
+			{"symbol":"TKN","loadable":false,"redeemable":true,"updatedAt":15},
+			{"symbol":"DAI","loadable":true,"redeemable":false,"updatedAt":14}
+		]}}`))
+	})
+
+	It("accepts GET requests", func() {
+		resp, err := http.Get(Server.URL + "?query=" + url.QueryEscape(`{ tokens(address: "0x0000000000000000000000000000000000000070") { whitelisted } }`))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(b)).To(MatchJSON(`{"data":{"tokens":[{"whitelisted":true}]}}`))
+	})
+
+	It("rejects malformed queries", func() {
+		status, body := query(graphql.Request{Query: `{ events { edges }`})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(MatchJSON(`{"data":null,"errors":[{"message":"unexpected end of query, expected a name"}]}`))
+	})
+
+	It("rejects fragments", func() {
+		status, _ := query(graphql.Request{Query: `{ events { ...page } }`})
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("reports unknown fields", func() {
+		status, body := query(graphql.Request{Query: `{ head events(first: 2) { edges { node { gas } } } }`})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{
+			"data":{"head":15,"events":{"edges":[{"node":{"gas":null}},{"node":{"gas":null}}]}},
+			"errors":[{"message":"cannot query field \"gas\" on type \"Event\"","path":["events","edges","node","gas"]}]
+		}`))
+	})
+})
+
+var _ = Describe("GraphQL over the persisted stores", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "graphql")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	tokens := func(store indexer.Store) string {
+		Expect(store.Append(15, Events())).To(Succeed())
+		body, err := json.Marshal(graphql.Request{Query: `{ tokens { symbol loadable redeemable updatedAt } }`})
+		Expect(err).ToNot(HaveOccurred())
+		rec := httptest.NewRecorder()
+		graphql.NewHandler(store).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+		return rec.Body.String()
+	}
+
+	const want = `{"data":{"tokens":[
+		{"symbol":"TKN","loadable":false,"redeemable":true,"updatedAt":15},
+		{"symbol":"DAI","loadable":true,"redeemable":false,"updatedAt":14}
+	]}}`
+
+	It("returns the tokens stored in a file", func() {
+		store, err := indexer.OpenFileStore(filepath.Join(dir, "events.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+		defer store.Close()
+		Expect(tokens(store)).To(MatchJSON(want))
+	})
+
+	It("returns the tokens stored in segments", func() {
+		store, err := indexer.OpenSegmentStore(dir)
+		Expect(err).ToNot(HaveOccurred())
+		defer store.Close()
+		Expect(tokens(store)).To(MatchJSON(want))
+	})
+})
@@ -0,0 +1,64 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	contractsgrpc "github.com/tokencard/contracts/v2/pkg/grpc"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestGRPCSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gRPC Suite")
+}
+
+const apiKey = "secret"
+
+var Server *grpc.Server
+var Conn *grpc.ClientConn
+var Client contractsgrpc.ContractsClient
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+
+	// The licence amount can only be updated by the licence DAO.
+	tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Commit()
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+
+	s, err := contractsgrpc.New(Backend, contractsgrpc.Config{
+		Licence:        LicenceAddress,
+		TokenWhitelist: TokenWhitelistAddress,
+		Controller:     ControllerContractAddress,
+		APIKeys:        []string{apiKey},
+		TransactOpts:   RandomAccount.TransactOpts(),
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Server = contractsgrpc.NewServer(s)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	go Server.Serve(l)
+
+	Conn, err = grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).ToNot(HaveOccurred())
+	Client = contractsgrpc.NewContractsClient(Conn)
+})
+
+var _ = AfterEach(func() {
+	Conn.Close()
+	Server.Stop()
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
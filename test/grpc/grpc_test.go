@@ -0,0 +1,122 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	contractsgrpc "github.com/tokencard/contracts/v2/pkg/grpc"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// code returns the status code of the error of a call.
+func code(err error) codes.Code {
+	return status.Code(err)
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+}
+
+var _ = Describe("Contracts service", func() {
+
+	It("should return the licence configuration", func() {
+		l, err := Client.GetLicence(context.Background(), &contractsgrpc.GetLicenceRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.AmountScaled).To(Equal("10"))
+		Expect(l.TokenHolder).To(Equal(TokenHolderAddress.Hex()))
+		Expect(l.CryptoFloat).To(Equal(CryptoFloatAddress.Hex()))
+		Expect(l.LicenceDao).To(Equal(RandomAccount.Address().Hex()))
+	})
+
+	It("should list the whitelisted tokens", func() {
+		res, err := Client.ListTokens(context.Background(), &contractsgrpc.ListTokensRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Addresses).To(ContainElement(TKNBurnerAddress.Hex()))
+		Expect(res.Addresses).To(ContainElement(StablecoinAddress.Hex()))
+	})
+
+	It("should return a whitelisted token", func() {
+		t, err := Client.GetToken(context.Background(), &contractsgrpc.GetTokenRequest{Address: TKNBurnerAddress.Hex()})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Symbol).To(Equal("TKN"))
+		Expect(t.Magnitude).To(Equal("100000000"))
+		Expect(t.Available).To(BeTrue())
+	})
+
+	It("should not find a token that is not whitelisted", func() {
+		_, err := Client.GetToken(context.Background(), &contractsgrpc.GetTokenRequest{Address: RandomAccount.Address().Hex()})
+		Expect(code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("should reject an invalid address", func() {
+		_, err := Client.GetRoles(context.Background(), &contractsgrpc.GetRolesRequest{Address: "0x123"})
+		Expect(code(err)).To(Equal(codes.InvalidArgument))
+		Expect(status.Convert(err).Message()).To(Equal(`"0x123" is not a valid address`))
+	})
+
+	It("should return the controller roles of an address", func() {
+		r, err := Client.GetRoles(context.Background(), &contractsgrpc.GetRolesRequest{Address: ControllerAdmin.Address().Hex()})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Admin).To(BeTrue())
+		Expect(r.Controller).To(BeFalse())
+		Expect(r.Owner).To(BeFalse())
+	})
+
+	When("the licence amount is updated without an API key", func() {
+		It("should be unauthenticated", func() {
+			_, err := Client.UpdateLicenceAmount(context.Background(), &contractsgrpc.UpdateLicenceAmountRequest{Amount: "20"})
+			Expect(code(err)).To(Equal(codes.Unauthenticated))
+		})
+	})
+
+	When("the licence amount is updated with a wrong API key", func() {
+		It("should be unauthenticated", func() {
+			_, err := Client.UpdateLicenceAmount(withKey("wrong"), &contractsgrpc.UpdateLicenceAmountRequest{Amount: "20"})
+			Expect(code(err)).To(Equal(codes.Unauthenticated))
+		})
+	})
+
+	When("the licence amount is invalid", func() {
+		It("should be rejected", func() {
+			_, err := Client.UpdateLicenceAmount(withKey(apiKey), &contractsgrpc.UpdateLicenceAmountRequest{Amount: "-1"})
+			Expect(code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	When("the licence amount is updated with a valid API key", func() {
+		BeforeEach(func() {
+			tx, err := Client.UpdateLicenceAmount(withKey(apiKey), &contractsgrpc.UpdateLicenceAmountRequest{Amount: "20"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tx.TxHash).To(HavePrefix("0x"))
+			Backend.Commit()
+		})
+
+		It("should update the licence amount", func() {
+			l, err := Client.GetLicence(context.Background(), &contractsgrpc.GetLicenceRequest{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l.AmountScaled).To(Equal("20"))
+		})
+	})
+
+	When("the service is read-only", func() {
+		It("should refuse the updates with the reason", func() {
+			s, err := contractsgrpc.New(Backend, contractsgrpc.Config{
+				Licence:        LicenceAddress,
+				TokenWhitelist: TokenWhitelistAddress,
+				Controller:     ControllerContractAddress,
+				APIKeys:        []string{apiKey},
+				TransactOpts:   RandomAccount.TransactOpts(),
+				Writable:       func() error { return errors.New("read-only: signer unavailable") },
+			})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = s.UpdateLicenceAmount(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+apiKey)), &contractsgrpc.UpdateLicenceAmountRequest{Amount: "20"})
+			Expect(code(err)).To(Equal(codes.Unavailable))
+			Expect(status.Convert(err).Message()).To(Equal("read-only: signer unavailable"))
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package holder_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("HolderClient", func() {
+
+	var client *bindings.HolderClient
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewHolderClient(TokenHolderAddress, TokenWhitelistAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := TokenWhitelist.AddTokens(
+			ControllerAdmin.TransactOpts(),
+			[]common.Address{common.HexToAddress("0x0"), ERC20Contract1Address, ERC20Contract2Address},
+			StringsToByte32("ETH", "ERC1", "ERC2"),
+			[]*big.Int{DecimalsToMagnitude(big.NewInt(18)), DecimalsToMagnitude(big.NewInt(18)), DecimalsToMagnitude(big.NewInt(18))},
+			[]bool{true, true, true},
+			[]bool{true, true, false},
+			big.NewInt(20180913153211),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+
+		tx, err = ERC20Contract1.Credit(BankAccount.TransactOpts(), TokenHolderAddress, big.NewInt(1000))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+
+		tx, err = ERC20Contract2.Credit(BankAccount.TransactOpts(), TokenHolderAddress, big.NewInt(500))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+
+		err = BankAccount.Transfer(Backend, TokenHolderAddress, EthToWei(1))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return its address", func() {
+		Expect(client.Address()).To(Equal(TokenHolderAddress))
+	})
+
+	It("should return the redeemable balances", func() {
+		assets, err := client.Redeemable(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		balances := make(map[common.Address]string)
+		for _, a := range assets {
+			balances[a.Token] = a.Amount.String()
+		}
+		Expect(balances).To(HaveKeyWithValue(bindings.ETH, EthToWei(1).String()))
+		Expect(balances).To(HaveKeyWithValue(ERC20Contract1Address, "1000"))
+		Expect(balances).ToNot(HaveKey(ERC20Contract2Address))
+	})
+
+	It("should return the claimable balances", func() {
+		assets, err := client.Claimable(ctx, ERC20Contract2Address, ERC20Contract3Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assets).To(HaveLen(1))
+		Expect(assets[0].Token).To(Equal(ERC20Contract2Address))
+		Expect(assets[0].Amount.String()).To(Equal("500"))
+	})
+
+	It("should refuse to claim redeemable tokens", func() {
+		_, err := client.Claimable(ctx, ERC20Contract1Address)
+		Expect(err).To(MatchError(ContainSubstring("is redeemable")))
+		_, err = client.Claim(ControllerAdmin.TransactOpts(), RandomAccount.Address(), ERC20Contract1Address)
+		Expect(err).To(MatchError(ContainSubstring("is redeemable")))
+	})
+
+	When("an admin claims the non redeemable tokens", func() {
+		BeforeEach(func() {
+			tx, err := client.Claim(ControllerAdmin.TransactOpts(), RandomAccount.Address(), ERC20Contract2Address)
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should send them to the recipient", func() {
+			b, err := ERC20Contract2.BalanceOf(nil, RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.String()).To(Equal("500"))
+			assets, err := client.Claimable(ctx, ERC20Contract2Address)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(assets).To(BeEmpty())
+		})
+	})
+
+	When("TKN is burned", func() {
+
+		var share []bindings.HolderAsset
+
+		BeforeEach(func() {
+			tx, err := TKNBurner.Mint(Owner.TransactOpts(), RandomAccount.Address(), big.NewInt(1000))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+
+			tx, err = TKNBurner.SetTokenHolder(Owner.TransactOpts(), TokenHolderAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+
+			share, err = client.Share(ctx, big.NewInt(300))
+			Expect(err).ToNot(HaveOccurred())
+
+			tx, err = TKNBurner.Burn(RandomAccount.TransactOpts(), big.NewInt(300))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should have predicted the redemptions", func() {
+			redemptions, err := client.Redemptions(&bind.FilterOpts{Context: ctx}, RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			paid := make(map[common.Address]string)
+			for _, r := range redemptions {
+				Expect(r.To).To(Equal(RandomAccount.Address()))
+				paid[r.Asset] = r.Amount.String()
+			}
+			Expect(paid).To(HaveKeyWithValue(ERC20Contract1Address, "300"))
+			Expect(paid).To(HaveKeyWithValue(bindings.ETH, FinneyToWei(300).String()))
+			for _, a := range share {
+				if a.Amount.Sign() > 0 {
+					Expect(paid).To(HaveKeyWithValue(a.Token, a.Amount.String()))
+				}
+			}
+		})
+
+		It("should not return the redemptions of other addresses", func() {
+			redemptions, err := client.Redemptions(&bind.FilterOpts{Context: ctx}, BankAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(redemptions).To(BeEmpty())
+		})
+	})
+
+	It("should refuse to share more TKN than the supply", func() {
+		_, err := client.Share(ctx, big.NewInt(1))
+		Expect(err).To(MatchError(ContainSubstring("exceeds the supply")))
+	})
+})
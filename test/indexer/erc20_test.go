@@ -0,0 +1,64 @@
+package indexer_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Involving", func() {
+
+	var idx *indexer.Indexer
+	var store *indexer.MemoryStore
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(indexer.ERC20ABI))
+		Expect(err).ToNot(HaveOccurred())
+		store = indexer.NewMemoryStore()
+		idx = indexer.New(Chain, store, indexer.Contract{Name: "tkn", Address: TKNBurnerAddress, ABI: parsed})
+		idx.Hooks = append(idx.Hooks, indexer.Involving("tkn", LicenceAddress))
+
+		tx, err := TKNBurner.Mint(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+		Chain.CommitTx(tx, err)
+		tx, err = TKNBurner.Transfer(RandomAccount.TransactOpts(), LicenceAddress, big.NewInt(10))
+		Chain.CommitTx(tx, err)
+		tx, err = TKNBurner.Transfer(RandomAccount.TransactOpts(), Owner.Address(), big.NewInt(20))
+		Chain.CommitTx(tx, err)
+		tx, err = TKNBurner.Approve(RandomAccount.TransactOpts(), LicenceAddress, big.NewInt(30))
+		Chain.CommitTx(tx, err)
+
+		Expect(idx.Sync(context.Background())).To(Succeed())
+	})
+
+	It("keeps the transfers and the approvals of the addresses, with their addresses decoded", func() {
+		events, err := store.Events(indexer.Query{Contract: "tkn"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+
+		Expect(events[0].Name).To(Equal("Transfer"))
+		Expect(events[0].Args["_from"]).To(Equal(RandomAccount.Address()))
+		Expect(events[0].Args["_to"]).To(Equal(LicenceAddress))
+		Expect(events[0].Args["_value"]).To(Equal(big.NewInt(10)))
+
+		Expect(events[1].Name).To(Equal("Approval"))
+		Expect(events[1].Args["_owner"]).To(Equal(RandomAccount.Address()))
+		Expect(events[1].Args["_spender"]).To(Equal(LicenceAddress))
+	})
+
+	It("keeps the events of the other contracts", func() {
+		hook := indexer.Involving("tkn", LicenceAddress)
+		e := indexer.Event{Contract: "licence", Name: "Claimed", Args: map[string]interface{}{"_to": common.HexToAddress("0x1")}}
+		out, keep, err := hook.Transform(context.Background(), e)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keep).To(BeTrue())
+		Expect(out).To(Equal(e))
+	})
+})
@@ -0,0 +1,140 @@
+package indexer_test
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+var _ = Describe("FileStore", func() {
+
+	var dir, path string
+
+	event := func(block uint64, index uint) indexer.Event {
+		return indexer.Event{
+			Contract:    "licence",
+			Name:        "TransferredToTokenHolder",
+			BlockNumber: block,
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+			LogIndex:    index,
+			Args:        map[string]interface{}{"amount": big.NewInt(int64(block))},
+		}
+	}
+
+	open := func() *indexer.FileStore {
+		s, err := indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		return s
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "indexer")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "events.jsonl")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads back the events and the head", func() {
+		s := open()
+		_, ok := s.Head()
+		Expect(ok).To(BeFalse())
+		Expect(s.Append(10, []indexer.Event{event(9, 0), event(10, 1)})).To(Succeed())
+		Expect(s.Append(12, nil)).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		head, ok := s.Head()
+		Expect(ok).To(BeTrue())
+		Expect(head).To(Equal(uint64(12)))
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].BlockNumber).To(Equal(uint64(10)))
+		Expect(events[1].Args["amount"]).To(Equal("10"))
+	})
+
+	It("persists the removed events", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event(9, 0), event(10, 1)})).To(Succeed())
+		Expect(s.Remove([]indexer.Event{event(10, 1)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].BlockNumber).To(Equal(uint64(9)))
+		head, _ := s.Head()
+		Expect(head).To(Equal(uint64(10)))
+	})
+
+	It("replaces the events of a reorganized block in a single write", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event(9, 0), event(10, 1)})).To(Succeed())
+		replacement := event(10, 1)
+		replacement.BlockHash = common.HexToHash("0x10")
+		Expect(s.Replace(11, []indexer.Event{event(10, 1)}, []indexer.Event{replacement, event(11, 0)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		head, _ := s.Head()
+		Expect(head).To(Equal(uint64(11)))
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[1].BlockHash).To(Equal(replacement.BlockHash))
+	})
+
+	It("drops the events of a write torn by a crash", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event(9, 0)})).To(Succeed())
+		Expect(s.Append(12, []indexer.Event{event(11, 0), event(12, 0)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		// Cut the second write after its first event, then in the middle of
+		// its second one.
+		data, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		var lines []int
+		for i, b := range data {
+			if b == '\n' {
+				lines = append(lines, i+1)
+			}
+		}
+		Expect(lines).To(HaveLen(5))
+		for _, size := range []int{lines[2], lines[2] + 10} {
+			Expect(ioutil.WriteFile(path, data[:size], 0644)).To(Succeed())
+
+			s = open()
+			head, _ := s.Head()
+			Expect(head).To(Equal(uint64(10)))
+			events, err := s.Events(indexer.Query{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+
+			// The blocks are indexed again from where the complete writes
+			// stopped.
+			Expect(s.Append(12, []indexer.Event{event(11, 0), event(12, 0)})).To(Succeed())
+			Expect(s.Close()).To(Succeed())
+			s = open()
+			events, err = s.Events(indexer.Query{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+			Expect(s.Close()).To(Succeed())
+		}
+	})
+})
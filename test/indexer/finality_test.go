@@ -0,0 +1,116 @@
+package indexer_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Finality", func() {
+
+	var idx *indexer.Indexer
+	var fast, final []indexer.Event
+	var tx *types.Transaction
+	ctx := context.Background()
+
+	collect := func(events *[]indexer.Event) indexer.Handler {
+		return indexer.HandlerFunc(func(ctx context.Context, e []indexer.Event) error {
+			*events = append(*events, e...)
+			return nil
+		})
+	}
+
+	// mine mines an empty block.
+	mine := func() {
+		Chain.Commit()
+		Chain.Advance(1)
+	}
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		idx = indexer.New(Chain, indexer.NewMemoryStore(), indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		idx.ReorgDepth = 3
+		fast, final = nil, nil
+		idx.Handlers = []indexer.Handler{collect(&fast), indexer.WithConfirmations(collect(&final), 2)}
+
+		tx, err = Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Chain.CommitTx(tx, err)
+		Expect(idx.Sync(ctx)).To(Succeed())
+	})
+
+	It("hands the events as soon as indexed to the other handlers", func() {
+		Expect(fast).To(HaveLen(1))
+		Expect(fast[0].Confirmations).To(BeZero())
+		Expect(final).To(BeEmpty())
+	})
+
+	It("hands the events once confirmed, labelled with the level", func() {
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(final).To(BeEmpty())
+
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(final).To(HaveLen(1))
+		Expect(final[0].TxHash).To(Equal(tx.Hash()))
+		Expect(final[0].Confirmations).To(Equal(uint64(2)))
+		Expect(fast).To(HaveLen(1))
+
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(final).To(HaveLen(1))
+	})
+
+	It("hands the same events at each level of a handler", func() {
+		var both []indexer.Event
+		idx.Handlers = append(idx.Handlers, indexer.WithConfirmations(collect(&both), 0, 1))
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(both).To(HaveLen(1))
+		Expect(both[0].Confirmations).To(Equal(uint64(1)))
+	})
+
+	It("does not hand the events of later blocks at a level reached by the head", func() {
+		var deep []indexer.Event
+		level := Chain.Head().Uint64() + 1
+		idx.Handlers = append(idx.Handlers, indexer.WithConfirmations(collect(&deep), level))
+		Expect(idx.Sync(ctx)).To(Succeed())
+
+		mine()
+		Expect(Chain.Head().Uint64()).To(Equal(level))
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(deep).To(BeEmpty())
+	})
+
+	It("does not hand the events removed before they were confirmed", func() {
+		Chain.reorg(tx)
+		mine()
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(fast).To(HaveLen(2))
+		Expect(fast[1].Removed).To(BeTrue())
+		Expect(final).To(BeEmpty())
+	})
+
+	It("hands the removal of the confirmed events to their level", func() {
+		mine()
+		mine()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(final).To(HaveLen(1))
+
+		Chain.reorg(tx)
+		Expect(idx.Sync(ctx)).To(Succeed())
+		Expect(final).To(HaveLen(2))
+		Expect(final[1].Removed).To(BeTrue())
+		Expect(final[1].Confirmations).To(Equal(uint64(2)))
+	})
+})
@@ -0,0 +1,163 @@
+package indexer_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+// heads is a node announcing the heads of a chain and returning their logs,
+// which the test builds block by block.
+type heads struct {
+	feed   event.Feed
+	blocks map[common.Hash][]types.Log
+	chain  []*types.Header
+}
+
+func (h *heads) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return h.feed.Subscribe(ch), nil
+}
+
+func (h *heads) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.BlockHash != nil {
+		return h.blocks[*q.BlockHash], nil
+	}
+	var logs []types.Log
+	for _, b := range h.chain {
+		n := b.Number.Int64()
+		if n >= q.FromBlock.Int64() && n <= q.ToBlock.Int64() {
+			logs = append(logs, h.blocks[b.Hash()]...)
+		}
+	}
+	return logs, nil
+}
+
+// mine adds a block with the logs on top of the block of the given number,
+// replacing the blocks above it, and returns its header.
+func (h *heads) mine(parent int, logs ...types.Log) *types.Header {
+	header := &types.Header{Number: big.NewInt(int64(parent + 1)), Extra: []byte(strings.Repeat("x", len(h.blocks)))}
+	if parent >= 0 {
+		header.ParentHash = h.chain[parent].Hash()
+	}
+	for i := range logs {
+		logs[i].BlockNumber = header.Number.Uint64()
+		logs[i].BlockHash = header.Hash()
+		logs[i].Index = uint(i)
+	}
+	h.chain = append(h.chain[:parent+1], header)
+	h.blocks[header.Hash()] = logs
+	return header
+}
+
+var _ = Describe("HeadFeed", func() {
+
+	var node *heads
+	var feed *indexer.HeadFeed
+	var handed []indexer.Event
+	var log types.Log
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		node = &heads{blocks: make(map[common.Hash][]types.Log)}
+		feed = indexer.NewHeadFeed(node, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		handed = nil
+		feed.Handlers = []indexer.Handler{indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+			handed = append(handed, events...)
+			return nil
+		})}
+
+		tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		r, err := Backend.TransactionReceipt(ctx, tx.Hash())
+		Expect(err).ToNot(HaveOccurred())
+		log = *r.Logs[0]
+
+		Expect(feed.Head(ctx, node.mine(-1))).To(Succeed())
+	})
+
+	It("hands the events of the new head", func() {
+		h := node.mine(0, log)
+		Expect(feed.Head(ctx, h)).To(Succeed())
+		Expect(handed).To(HaveLen(1))
+		Expect(handed[0].Name).To(Equal("UpdatedLicenceDAO"))
+		Expect(handed[0].BlockHash).To(Equal(h.Hash()))
+		Expect(handed[0].Removed).To(BeFalse())
+	})
+
+	It("hands the events of the blocks skipped since the last head", func() {
+		node.mine(0, log)
+		node.mine(1)
+		Expect(feed.Head(ctx, node.mine(2, log))).To(Succeed())
+		Expect(handed).To(HaveLen(2))
+		Expect(handed[0].BlockNumber).To(Equal(uint64(1)))
+		Expect(handed[1].BlockNumber).To(Equal(uint64(3)))
+	})
+
+	It("hands the events of a replaced head as removed before the new ones", func() {
+		first := node.mine(0, log)
+		Expect(feed.Head(ctx, first)).To(Succeed())
+		Expect(feed.Head(ctx, node.mine(1))).To(Succeed())
+		handed = nil
+
+		replacement := node.mine(0, log)
+		Expect(feed.Head(ctx, replacement)).To(Succeed())
+		Expect(handed).To(HaveLen(2))
+		Expect(handed[0].Removed).To(BeTrue())
+		Expect(handed[0].BlockHash).To(Equal(first.Hash()))
+		Expect(handed[1].Removed).To(BeFalse())
+		Expect(handed[1].BlockHash).To(Equal(replacement.Hash()))
+	})
+
+	It("undoes the parent of a head on another branch", func() {
+		first := node.mine(0, log)
+		Expect(feed.Head(ctx, first)).To(Succeed())
+		handed = nil
+
+		node.mine(0)
+		Expect(feed.Head(ctx, node.mine(1))).To(Succeed())
+		Expect(handed).To(HaveLen(1))
+		Expect(handed[0].Removed).To(BeTrue())
+		Expect(handed[0].BlockHash).To(Equal(first.Hash()))
+	})
+
+	It("leaves the reorganizations deeper than its depth to the indexer", func() {
+		feed.Depth = 1
+		Expect(feed.Head(ctx, node.mine(0, log))).To(Succeed())
+		Expect(feed.Head(ctx, node.mine(1))).To(Succeed())
+		handed = nil
+
+		Expect(feed.Head(ctx, node.mine(0))).To(Succeed())
+		Expect(handed).To(BeEmpty())
+	})
+
+	It("hands the events of the heads it is subscribed to", func() {
+		events := make(chan []indexer.Event, 1)
+		feed.Handlers = []indexer.Handler{indexer.HandlerFunc(func(ctx context.Context, e []indexer.Event) error {
+			events <- e
+			return nil
+		})}
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- feed.Run(ctx) }()
+		h := node.mine(0, log)
+		Eventually(func() int { return node.feed.Send(h) }).Should(Equal(1))
+		Eventually(events).Should(Receive(HaveLen(1)))
+		cancel()
+		Expect(<-done).To(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,73 @@
+package indexer_test
+
+import (
+	"context"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestIndexerSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Indexer Suite")
+}
+
+// chain is the test chain whose blocks orphaned by reorg no longer return
+// their logs, like the node once it switched to another branch.
+type chain struct {
+	*TestChain
+	last     *types.Transaction
+	orphaned map[common.Hash]bool
+}
+
+func (c *chain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := c.TestChain.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var canonical []types.Log
+	for _, l := range logs {
+		if !c.orphaned[l.BlockHash] {
+			canonical = append(canonical, l)
+		}
+	}
+	return canonical, nil
+}
+
+// reorg orphans the block of the transaction.
+func (c *chain) reorg(tx *types.Transaction) {
+	r, err := c.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	c.orphaned[r.BlockHash] = true
+}
+
+// orphanHead orphans the block of the last transaction committed.
+func (c *chain) orphanHead() {
+	c.reorg(c.last)
+}
+
+// CommitTx mines the transaction, moving the head of the chain to its block,
+// and records it as the last transaction committed.
+func (c *chain) CommitTx(tx *types.Transaction, err error) {
+	c.TestChain.CommitTx(tx, err)
+	c.last = tx
+}
+
+var Chain *chain
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = &chain{TestChain: NewTestChain(Backend, MineManually), orphaned: make(map[common.Hash]bool)}
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,161 @@
+package indexer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Indexer", func() {
+
+	var store *indexer.MemoryStore
+	var idx *indexer.Indexer
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		store = indexer.NewMemoryStore()
+		idx = indexer.New(Chain, store, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+	})
+
+	When("the licence DAO is updated", func() {
+		BeforeEach(func() {
+			tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+			Chain.CommitTx(tx, err)
+
+			err = idx.Sync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("indexes up to the head of the chain", func() {
+			head, ok := store.Head()
+			Expect(ok).To(BeTrue())
+			Expect(head).To(Equal(Chain.Head().Uint64()))
+		})
+
+		It("stores the decoded event", func() {
+			events, err := store.Events(indexer.Query{Contract: "licence", Name: "UpdatedLicenceDAO"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Address).To(Equal(LicenceAddress))
+			Expect(events[0].BlockNumber).To(Equal(Chain.Head().Uint64()))
+			Expect(events[0].Args["_newDAO"]).To(Equal(RandomAccount.Address()))
+		})
+
+		When("it is updated again", func() {
+			BeforeEach(func() {
+				tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.HexToAddress("0x1"))
+				Chain.CommitTx(tx, err)
+
+				err = idx.Sync(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("only indexes the new blocks", func() {
+				events, err := store.Events(indexer.Query{Name: "UpdatedLicenceDAO"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(events).To(HaveLen(2))
+				Expect(events[1].Args["_newDAO"]).To(Equal(common.HexToAddress("0x1")))
+			})
+
+			It("filters by block range", func() {
+				events, err := store.Events(indexer.Query{FromBlock: Chain.Head().Uint64()})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+			})
+		})
+	})
+})
+
+var _ = Describe("Hooks", func() {
+
+	var store *indexer.MemoryStore
+	var idx *indexer.Indexer
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		store = indexer.NewMemoryStore()
+		idx = indexer.New(Chain, store, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+
+		tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Chain.CommitTx(tx, err)
+	})
+
+	It("stores the transformed events", func() {
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			e.Args = map[string]interface{}{"tenant": "acme"}
+			return e, true, nil
+		})}
+		Expect(idx.Sync(context.Background())).To(Succeed())
+
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Args).To(Equal(map[string]interface{}{"tenant": "acme"}))
+	})
+
+	It("drops the filtered events", func() {
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			return e, e.Name != "UpdatedLicenceDAO", nil
+		})}
+		Expect(idx.Sync(context.Background())).To(Succeed())
+
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(BeEmpty())
+		_, ok := store.Head()
+		Expect(ok).To(BeTrue())
+	})
+
+	It("retries the blocks when a hook fails", func() {
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			return e, false, errors.New("out of fuel")
+		})}
+		Expect(idx.Sync(context.Background())).To(MatchError(ContainSubstring("out of fuel")))
+		_, ok := store.Head()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("fails the synchronisation when a hook panics", func() {
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			panic("nil tenant")
+		})}
+		err := idx.Sync(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("hook 0 on UpdatedLicenceDAO event in transaction")))
+		Expect(err).To(MatchError(ContainSubstring("panic: nil tenant")))
+		_, ok := store.Head()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("fails the synchronisation when a hook runs out of time", func() {
+		release := make(chan struct{})
+		defer close(release)
+		idx.HookTimeout = 10 * time.Millisecond
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			<-release
+			return e, true, nil
+		})}
+		Expect(idx.Sync(context.Background())).To(MatchError(ContainSubstring("timed out after 10ms")))
+		_, ok := store.Head()
+		Expect(ok).To(BeFalse())
+
+		idx.Hooks = []indexer.Hook{indexer.HookFunc(func(ctx context.Context, e indexer.Event) (indexer.Event, bool, error) {
+			return e, true, nil
+		})}
+		Expect(idx.Sync(context.Background())).To(Succeed())
+		events, err := store.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,123 @@
+package indexer_test
+
+import (
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// legacyRecorder records the events written to it, failing while err is set.
+type legacyRecorder struct {
+	err    error
+	events []indexer.Event
+}
+
+func (l *legacyRecorder) Write(head uint64, events []indexer.Event) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.events = append(l.events, events...)
+	return nil
+}
+
+// failingStore fails to append events.
+type failingStore struct {
+	indexer.Store
+}
+
+func (failingStore) Append(head uint64, events []indexer.Event) error {
+	return errors.New("disk full")
+}
+
+var _ = Describe("Mirror", func() {
+
+	var (
+		legacy  *legacyRecorder
+		mirror  *indexer.Mirror
+		reports []indexer.Divergence
+	)
+
+	event := func(block uint64, index uint) indexer.Event {
+		return indexer.Event{Contract: "licence", Name: "UpdatedLicenceAmount", BlockNumber: block, LogIndex: index}
+	}
+
+	BeforeEach(func() {
+		legacy = &legacyRecorder{}
+		mirror = indexer.NewMirror(indexer.NewMemoryStore(), legacy)
+		reports = nil
+		mirror.Report = func(d indexer.Divergence) {
+			reports = append(reports, d)
+		}
+	})
+
+	It("writes the stored events to the legacy system", func() {
+		Expect(mirror.Append(10, []indexer.Event{event(5, 0), event(10, 1)})).To(Succeed())
+		Expect(legacy.events).To(Equal([]indexer.Event{event(5, 0), event(10, 1)}))
+		events, err := mirror.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(reports).To(BeEmpty())
+	})
+
+	It("writes the removed events with their Removed flag", func() {
+		Expect(mirror.Append(10, []indexer.Event{event(5, 0), event(10, 1)})).To(Succeed())
+		Expect(mirror.Remove([]indexer.Event{event(10, 1)})).To(Succeed())
+		removed := event(10, 1)
+		removed.Removed = true
+		Expect(legacy.events).To(Equal([]indexer.Event{event(5, 0), event(10, 1), removed}))
+	})
+
+	It("writes the replaced events before the events replacing them", func() {
+		Expect(mirror.Append(10, []indexer.Event{event(5, 0), event(10, 1)})).To(Succeed())
+		Expect(mirror.Replace(11, []indexer.Event{event(10, 1)}, []indexer.Event{event(11, 0)})).To(Succeed())
+		removed := event(10, 1)
+		removed.Removed = true
+		Expect(legacy.events).To(Equal([]indexer.Event{event(5, 0), event(10, 1), removed, event(11, 0)}))
+		events, err := mirror.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(Equal([]indexer.Event{event(5, 0), event(11, 0)}))
+	})
+
+	It("fails to remove from a store not removing events", func() {
+		mirror = indexer.NewMirror(struct{ indexer.Store }{indexer.NewMemoryStore()}, legacy)
+		Expect(mirror.Remove([]indexer.Event{event(10, 1)})).To(MatchError("the mirrored store does not remove events"))
+	})
+
+	It("keeps storing while the legacy system fails, and catches it up", func() {
+		legacy.err = errors.New("queue down")
+		Expect(mirror.Append(10, []indexer.Event{event(5, 0)})).To(Succeed())
+		Expect(mirror.Append(11, []indexer.Event{event(11, 0)})).To(Succeed())
+		head, _ := mirror.Head()
+		Expect(head).To(Equal(uint64(11)))
+		Expect(mirror.Divergence()).To(Equal(indexer.Divergence{Head: 11, Behind: 2}))
+		Expect(reports).To(HaveLen(2))
+		Expect(reports[1].Behind).To(Equal(2))
+		Expect(reports[1].Err).To(MatchError("writing to legacy system: queue down"))
+
+		legacy.err = nil
+		Expect(mirror.Append(12, nil)).To(Succeed())
+		Expect(legacy.events).To(Equal([]indexer.Event{event(5, 0), event(11, 0)}))
+		Expect(mirror.Divergence()).To(Equal(indexer.Divergence{Head: 12}))
+		Expect(reports).To(HaveLen(3))
+		Expect(reports[2]).To(Equal(indexer.Divergence{Head: 12}))
+	})
+
+	It("drops the oldest events beyond MaxBehind", func() {
+		mirror.MaxBehind = 1
+		legacy.err = errors.New("queue down")
+		Expect(mirror.Append(10, []indexer.Event{event(5, 0), event(10, 0)})).To(Succeed())
+		Expect(mirror.Divergence().Behind).To(Equal(2))
+
+		legacy.err = nil
+		Expect(mirror.Append(11, nil)).To(Succeed())
+		Expect(legacy.events).To(Equal([]indexer.Event{event(10, 0)}))
+	})
+
+	It("does not write the events the store failed to append", func() {
+		mirror = indexer.NewMirror(failingStore{indexer.NewMemoryStore()}, legacy)
+		Expect(mirror.Append(9, []indexer.Event{event(9, 0)})).To(MatchError("disk full"))
+		Expect(legacy.events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,84 @@
+package indexer_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokencard/ethertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("LinkPayouts", func() {
+
+	ctx := context.Background()
+	var store *indexer.MemoryStore
+	var load *types.Transaction
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		store = indexer.NewMemoryStore()
+		idx := indexer.New(Chain, store, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		idx.Hooks = append(idx.Hooks, indexer.LinkPayouts("licence", Chain))
+
+		tx, err := Stablecoin.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(1000))
+		Chain.CommitTx(tx, err)
+		tx, err = Stablecoin.Approve(RandomAccount.TransactOpts(), LicenceAddress, big.NewInt(1000))
+		Chain.CommitTx(tx, err)
+		load, err = Licence.Load(RandomAccount.TransactOpts(), StablecoinAddress, big.NewInt(1000))
+		Chain.CommitTx(load, err)
+		tx, err = Licence.Load(RandomAccount.TransactOpts(ethertest.WithValue(big.NewInt(1010))), common.Address{}, big.NewInt(1010))
+		Chain.CommitTx(tx, err)
+
+		Expect(idx.Sync(ctx)).To(Succeed())
+	})
+
+	It("links the payouts of a token to the Transfer log paying the token holder", func() {
+		events, err := store.Events(indexer.Query{Contract: "licence", Name: indexer.PayoutEvent})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].TxHash).To(Equal(load.Hash()))
+
+		index, ok := indexer.PayoutTransfer(events[0])
+		Expect(ok).To(BeTrue())
+		r, err := Chain.TransactionReceipt(ctx, load.Hash())
+		Expect(err).ToNot(HaveOccurred())
+		var transfer *types.Log
+		for _, l := range r.Logs {
+			if l.Index == index {
+				transfer = l
+			}
+		}
+		Expect(transfer).ToNot(BeNil())
+		Expect(transfer.Address).To(Equal(StablecoinAddress))
+		Expect(common.BytesToAddress(transfer.Topics[2].Bytes())).To(Equal(TokenHolderAddress))
+		Expect(new(big.Int).SetBytes(transfer.Data)).To(Equal(big.NewInt(10)))
+	})
+
+	It("leaves the payouts of ether unlinked", func() {
+		events, err := store.Events(indexer.Query{Contract: "licence", Name: indexer.PayoutEvent})
+		Expect(err).ToNot(HaveOccurred())
+		_, ok := indexer.PayoutTransfer(events[1])
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reads the links back from the stored arguments", func() {
+		events, err := store.Events(indexer.Query{Contract: "licence", Name: indexer.PayoutEvent})
+		Expect(err).ToNot(HaveOccurred())
+		index, _ := indexer.PayoutTransfer(events[0])
+		stored := events[0]
+		stored.Args = map[string]interface{}{indexer.TransferArg: indexer.FormatArg(events[0].Args[indexer.TransferArg])}
+		read, ok := indexer.PayoutTransfer(stored)
+		Expect(ok).To(BeTrue())
+		Expect(read).To(Equal(index))
+	})
+})
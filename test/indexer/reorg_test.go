@@ -0,0 +1,205 @@
+package indexer_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+// appendOnly is a store which can not remove events.
+type appendOnly struct {
+	indexer.Store
+}
+
+var _ = Describe("Reorganizations", func() {
+
+	var store *indexer.MemoryStore
+	var idx *indexer.Indexer
+	var handled []indexer.Event
+	var first, second *types.Transaction
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		store = indexer.NewMemoryStore()
+		idx = indexer.New(Chain, store, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		idx.ReorgDepth = 3
+		handled = nil
+		idx.Handlers = []indexer.Handler{indexer.HandlerFunc(func(ctx context.Context, events []indexer.Event) error {
+			handled = append(handled, events...)
+			return nil
+		})}
+
+		first, err = Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Chain.CommitTx(first, err)
+		second, err = Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.HexToAddress("0x1"))
+		Chain.CommitTx(second, err)
+
+		Expect(idx.Sync(ctx)).To(Succeed())
+		handled = nil
+	})
+
+	daos := func() []common.Address {
+		events, err := store.Events(indexer.Query{Name: "UpdatedLicenceDAO"})
+		Expect(err).ToNot(HaveOccurred())
+		var a []common.Address
+		for _, e := range events {
+			a = append(a, e.Args["_newDAO"].(common.Address))
+		}
+		return a
+	}
+
+	When("a block is reorganized", func() {
+		BeforeEach(func() {
+			Chain.reorg(second)
+			tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.HexToAddress("0x2"))
+			Chain.CommitTx(tx, err)
+
+			Expect(idx.Sync(ctx)).To(Succeed())
+		})
+
+		It("removes its events from the store", func() {
+			Expect(daos()).To(Equal([]common.Address{RandomAccount.Address(), common.HexToAddress("0x2")}))
+		})
+
+		It("hands its events again as removed before the new ones", func() {
+			Expect(handled).To(HaveLen(2))
+			Expect(handled[0].Removed).To(BeTrue())
+			Expect(handled[0].TxHash).To(Equal(second.Hash()))
+			Expect(handled[1].Removed).To(BeFalse())
+			Expect(handled[1].Args["_newDAO"]).To(Equal(common.HexToAddress("0x2")))
+		})
+
+		It("does not hand the events again on the next synchronisation", func() {
+			handled = nil
+			Expect(idx.Sync(ctx)).To(Succeed())
+			Expect(handled).To(BeEmpty())
+		})
+	})
+
+	When("a block deeper than the reorg depth is reorganized", func() {
+		BeforeEach(func() {
+			idx.ReorgDepth = 1
+			Chain.reorg(first)
+			Expect(idx.Sync(ctx)).To(Succeed())
+		})
+
+		It("keeps its events", func() {
+			Expect(daos()).To(HaveLen(2))
+			Expect(handled).To(BeEmpty())
+		})
+	})
+
+	When("the node lags behind the indexed blocks", func() {
+		BeforeEach(func() {
+			Chain.Advance(-1)
+			Expect(idx.Sync(ctx)).To(Succeed())
+		})
+
+		It("keeps the events above its head", func() {
+			Expect(daos()).To(HaveLen(2))
+			Expect(handled).To(BeEmpty())
+			head, _ := store.Head()
+			Expect(head).To(Equal(Chain.Head().Uint64() + 1))
+		})
+	})
+
+	It("requires a store replacing events", func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		idx = indexer.New(Chain, appendOnly{store}, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		idx.ReorgDepth = 3
+		Expect(idx.Sync(ctx)).To(MatchError("following reorganizations requires a store replacing events"))
+	})
+})
+
+var _ = Describe("Reorganizations stored to a file", func() {
+
+	var dir, path string
+	var parsed abi.ABI
+	var second *types.Transaction
+	ctx := context.Background()
+
+	open := func() (*indexer.FileStore, *indexer.Indexer) {
+		s, err := indexer.OpenFileStore(path)
+		Expect(err).ToNot(HaveOccurred())
+		idx := indexer.New(Chain, s, indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed})
+		idx.ReorgDepth = 3
+		return s, idx
+	}
+
+	daos := func(s indexer.Store) []string {
+		events, err := s.Events(indexer.Query{Name: "UpdatedLicenceDAO"})
+		Expect(err).ToNot(HaveOccurred())
+		var a []string
+		for _, e := range events {
+			a = append(a, e.Args["_newDAO"].(string))
+		}
+		return a
+	}
+
+	BeforeEach(func() {
+		var err error
+		parsed, err = abi.JSON(strings.NewReader(bindings.LicenceABI))
+		Expect(err).ToNot(HaveOccurred())
+		dir, err = ioutil.TempDir("", "indexer")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "events.jsonl")
+
+		first, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Chain.CommitTx(first, err)
+		second, err = Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.HexToAddress("0x1"))
+		Chain.CommitTx(second, err)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("never persists the removals without the events replacing them", func() {
+		s, idx := open()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		before := daos(s)
+		Expect(before).To(HaveLen(2))
+		Expect(s.Close()).To(Succeed())
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		synced := int(info.Size())
+
+		Chain.reorg(second)
+		tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), common.HexToAddress("0x2"))
+		Chain.CommitTx(tx, err)
+		s, idx = open()
+		Expect(idx.Sync(ctx)).To(Succeed())
+		after := daos(s)
+		Expect(after).To(Equal([]string{before[0], common.HexToAddress("0x2").Hex()}))
+		Expect(s.Close()).To(Succeed())
+
+		data, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Crash at every byte of the replacement: the store is left as it
+		// was before it, and indexes the reorganized block again.
+		for size := synced; size < len(data); size++ {
+			Expect(ioutil.WriteFile(path, data[:size], 0644)).To(Succeed())
+			s, idx := open()
+			Expect(daos(s)).To(Equal(before), "crashed after %d bytes", size)
+			Expect(idx.Sync(ctx)).To(Succeed())
+			Expect(daos(s)).To(Equal(after), "crashed after %d bytes", size)
+			Expect(s.Close()).To(Succeed())
+		}
+	})
+})
@@ -0,0 +1,208 @@
+package indexer_test
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+)
+
+var _ = Describe("SegmentStore", func() {
+
+	var dir string
+
+	event := func(contract, name string, block uint64, index uint) indexer.Event {
+		return indexer.Event{
+			Contract:    contract,
+			Name:        name,
+			BlockNumber: block,
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+			LogIndex:    index,
+			Args:        map[string]interface{}{"amount": big.NewInt(int64(block))},
+		}
+	}
+
+	open := func() *indexer.SegmentStore {
+		s, err := indexer.OpenSegmentStore(dir)
+		Expect(err).ToNot(HaveOccurred())
+		return s
+	}
+
+	segments := func() []string {
+		files, err := filepath.Glob(filepath.Join(dir, "segment-*"))
+		Expect(err).ToNot(HaveOccurred())
+		return files
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "segments")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads back the events and the head", func() {
+		s := open()
+		_, ok := s.Head()
+		Expect(ok).To(BeFalse())
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 9, 0), event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Append(12, nil)).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		head, ok := s.Head()
+		Expect(ok).To(BeTrue())
+		Expect(head).To(Equal(uint64(12)))
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].BlockNumber).To(Equal(uint64(10)))
+		Expect(events[1].Args["amount"]).To(Equal("10"))
+	})
+
+	It("only reads the segments which may match a query", func() {
+		s := open()
+		defer s.Close()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 10, 0)})).To(Succeed())
+		Expect(s.Append(20, []indexer.Event{event("controller", "B", 20, 0)})).To(Succeed())
+		Expect(segments()).To(HaveLen(2))
+		Expect(os.Remove(segments()[1])).To(Succeed())
+
+		events, err := s.Events(indexer.Query{Contract: "licence"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		events, err = s.Events(indexer.Query{ToBlock: 15})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		_, err = s.Events(indexer.Query{Name: "B"})
+		Expect(err).To(MatchError(ContainSubstring("opening segment")))
+	})
+
+	It("returns the events in chain order across segments", func() {
+		s := open()
+		defer s.Close()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 8, 0), event("licence", "A", 10, 0)})).To(Succeed())
+		Expect(s.Remove([]indexer.Event{event("licence", "A", 10, 0)})).To(Succeed())
+		Expect(s.Append(11, []indexer.Event{event("licence", "A", 9, 0), event("licence", "A", 10, 0)})).To(Succeed())
+
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].BlockNumber).To(Equal(uint64(8)))
+		Expect(events[1].BlockNumber).To(Equal(uint64(9)))
+		Expect(events[2].BlockNumber).To(Equal(uint64(10)))
+
+		events, err = s.Events(indexer.Query{Limit: 1, After: &indexer.Position{BlockNumber: 8}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].BlockNumber).To(Equal(uint64(9)))
+	})
+
+	It("persists the removed events", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 9, 0), event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Remove([]indexer.Event{event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].BlockNumber).To(Equal(uint64(9)))
+	})
+
+	It("drops the segment of a commit torn by a crash", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 9, 0)})).To(Succeed())
+		Expect(s.Append(12, []indexer.Event{event("licence", "A", 11, 0)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		log := filepath.Join(dir, "_log.jsonl")
+		data, err := ioutil.ReadFile(log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(log, data[:len(data)-10], 0644)).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		head, _ := s.Head()
+		Expect(head).To(Equal(uint64(10)))
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(segments()).To(HaveLen(1))
+
+		Expect(s.Append(12, []indexer.Event{event("licence", "A", 11, 0)})).To(Succeed())
+		events, err = s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("replaces the events of a reorganized block in a single commit", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 9, 0), event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+		log := filepath.Join(dir, "_log.jsonl")
+		committed, err := ioutil.ReadFile(log)
+		Expect(err).ToNot(HaveOccurred())
+
+		replacement := event("licence", "A", 10, 1)
+		replacement.BlockHash = common.HexToHash("0x10")
+		s = open()
+		Expect(s.Replace(11, []indexer.Event{event("licence", "A", 10, 1)}, []indexer.Event{replacement, event("licence", "A", 11, 0)})).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+		data, err := ioutil.ReadFile(log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(data)).To(BeNumerically(">", len(committed)))
+
+		s = open()
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[1].BlockHash).To(Equal(replacement.BlockHash))
+		Expect(s.Close()).To(Succeed())
+
+		// Crash at every byte of the commit: the removals are dropped along
+		// with the segment of the events replacing them.
+		for size := len(committed); size < len(data); size++ {
+			Expect(ioutil.WriteFile(log, data[:size], 0644)).To(Succeed())
+			s = open()
+			head, _ := s.Head()
+			Expect(head).To(Equal(uint64(10)))
+			events, err := s.Events(indexer.Query{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			Expect(events[1].BlockHash).To(Equal(event("licence", "A", 10, 1).BlockHash))
+			Expect(segments()).To(HaveLen(1))
+			Expect(s.Close()).To(Succeed())
+		}
+	})
+
+	It("compacts the events left into a single segment", func() {
+		s := open()
+		Expect(s.Append(10, []indexer.Event{event("licence", "A", 9, 0), event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Append(11, []indexer.Event{event("licence", "A", 11, 0)})).To(Succeed())
+		Expect(s.Remove([]indexer.Event{event("licence", "A", 10, 1)})).To(Succeed())
+		Expect(s.Compact()).To(Succeed())
+		Expect(segments()).To(HaveLen(1))
+		Expect(s.Close()).To(Succeed())
+
+		s = open()
+		defer s.Close()
+		head, _ := s.Head()
+		Expect(head).To(Equal(uint64(11)))
+		events, err := s.Events(indexer.Query{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+})
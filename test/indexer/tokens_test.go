@@ -0,0 +1,103 @@
+package indexer_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("TokenChanges", func() {
+
+	var idx *indexer.Indexer
+	var changes []indexer.TokenChange
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(bindings.TokenWhitelistABI))
+		Expect(err).ToNot(HaveOccurred())
+		idx = indexer.New(Chain, indexer.NewMemoryStore(), indexer.Contract{Name: "token_whitelist", Address: TokenWhitelistAddress, ABI: parsed})
+		changes = nil
+		idx.Handlers = append(idx.Handlers, indexer.TokenChanges("token_whitelist", func(ctx context.Context, c []indexer.TokenChange) error {
+			changes = append(changes, c...)
+			return nil
+		}))
+	})
+
+	When("a token is added and another one removed", func() {
+		BeforeEach(func() {
+			w, err := bindings.NewTokenWhitelistClient(TokenWhitelistAddress, Chain)
+			Expect(err).ToNot(HaveOccurred())
+
+			tx, err := w.AddTokens(ControllerAdmin.TransactOpts(), bindings.NewToken{Address: common.HexToAddress("0x1"), Symbol: "BNT", Decimals: 18, Loadable: true})
+			Chain.CommitTx(tx, err)
+
+			tx, err = w.RemoveTokens(ControllerAdmin.TransactOpts(), TKNBurnerAddress)
+			Chain.CommitTx(tx, err)
+
+			err = idx.Sync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("streams the changes in order, after those of the deployment", func() {
+			Expect(len(changes)).To(BeNumerically(">", 2))
+			changes = changes[len(changes)-2:]
+
+			Expect(changes[0].Added).To(BeTrue())
+			Expect(changes[0].Token).To(Equal(common.HexToAddress("0x1")))
+			Expect(changes[0].Symbol).To(Equal("BNT"))
+			Expect(changes[0].Magnitude).To(Equal(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)))
+			Expect(changes[0].Loadable).To(BeTrue())
+			Expect(changes[0].Redeemable).To(BeFalse())
+
+			Expect(changes[1].Added).To(BeFalse())
+			Expect(changes[1].Token).To(Equal(TKNBurnerAddress))
+			Expect(changes[1].Event.BlockNumber).To(Equal(Chain.Head().Uint64()))
+		})
+
+		When("the block of the removal is reorganized", func() {
+			BeforeEach(func() {
+				idx.ReorgDepth = 2
+				changes = nil
+				Chain.orphanHead()
+
+				err := idx.Sync(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("streams the removal again as reverted", func() {
+				Expect(changes).To(HaveLen(1))
+				Expect(changes[0].Added).To(BeFalse())
+				Expect(changes[0].Reverted).To(BeTrue())
+				Expect(changes[0].Token).To(Equal(TKNBurnerAddress))
+			})
+		})
+	})
+
+	It("decodes the arguments formatted by the persisted stores", func() {
+		c, ok, err := indexer.DecodeTokenChange(indexer.Event{Name: "AddedToken", Args: map[string]interface{}{
+			"_token":     indexer.FormatArg(TKNBurnerAddress),
+			"_symbol":    "TKN",
+			"_magnitude": indexer.FormatArg(big.NewInt(100000000)),
+			"_loadable":  true,
+		}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(c.Token).To(Equal(TKNBurnerAddress))
+		Expect(c.Magnitude.String()).To(Equal("100000000"))
+		Expect(c.Loadable).To(BeTrue())
+	})
+
+	It("ignores the other events", func() {
+		_, ok, err := indexer.DecodeTokenChange(indexer.Event{Name: "UpdatedTokenRate"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,67 @@
+package invariant_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestInvariantSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Invariant Suite")
+}
+
+var Chain *TestChain
+
+// index returns a store holding the events of the contracts up to the head,
+// as indexed from their deployment.
+func index() *indexer.MemoryStore {
+	contracts := []struct {
+		name    string
+		address common.Address
+		abi     string
+	}{
+		{"controller", ControllerContractAddress, bindings.ControllerABI},
+		{"licence", LicenceAddress, bindings.LicenceABI},
+		{"token_whitelist", TokenWhitelistAddress, bindings.TokenWhitelistABI},
+	}
+	var events []indexer.Event
+	for _, c := range contracts {
+		parsed, err := abi.JSON(strings.NewReader(c.abi))
+		Expect(err).ToNot(HaveOccurred())
+		logs, err := Backend.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(0),
+			ToBlock:   Chain.Head(),
+			Addresses: []common.Address{c.address},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		for _, l := range logs {
+			e, err := indexer.NewEvent(indexer.Contract{Name: c.name, Address: c.address, ABI: parsed}, l)
+			Expect(err).ToNot(HaveOccurred())
+			events = append(events, e)
+		}
+	}
+	store := indexer.NewMemoryStore()
+	Expect(store.Append(Chain.Head().Uint64(), events)).To(Succeed())
+	return store
+}
+
+var _ = BeforeEach(func() {
+	Expect(InitializeBackend()).To(Succeed())
+	Chain = NewTestChain(Backend, MineManually)
+	Chain.CommitTx(TKNBurner.Mint(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(1000)))
+})
+
+var _ = AfterEach(func() {
+	Expect(Backend.Close()).To(Succeed())
+})
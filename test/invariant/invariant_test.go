@@ -0,0 +1,181 @@
+package invariant_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/invariant"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("invariants", func() {
+
+	var (
+		ctx        = context.Background()
+		invariants []invariant.Invariant
+		alerted    [][]invariant.Violation
+		monitor    func(store indexer.Store) *invariant.Monitor
+	)
+
+	BeforeEach(func() {
+		licence, err := bindings.NewLicenceCaller(LicenceAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		whitelist, err := bindings.NewTokenWhitelistCaller(TokenWhitelistAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		controller, err := bindings.NewControllerCaller(ControllerContractAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		invariants = []invariant.Invariant{
+			invariant.LicenceAmount(licence, "licence"),
+			invariant.TokenWhitelist(whitelist, "token_whitelist"),
+			invariant.ControllerRoles(controller, "controller"),
+			invariant.TKNSupply(TKNBurnerAddress, Backend, nil),
+		}
+		alerted = nil
+		monitor = func(store indexer.Store) *invariant.Monitor {
+			return &invariant.Monitor{
+				Store:      store,
+				Invariants: invariants,
+				Alert: func(ctx context.Context, violations []invariant.Violation) error {
+					alerted = append(alerted, violations)
+					return nil
+				},
+			}
+		}
+	})
+
+	details := func(violations []invariant.Violation) []string {
+		var r []string
+		for _, v := range violations {
+			r = append(r, v.Invariant+": "+v.Detail)
+		}
+		return r
+	}
+
+	It("holds for the deployed contracts", func() {
+		violations, err := monitor(index()).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+		Expect(alerted).To(BeEmpty())
+	})
+
+	It("checks nothing until a block is indexed", func() {
+		violations, err := monitor(indexer.NewMemoryStore()).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+
+	It("finds the roles missing from the index", func() {
+		store := index()
+		events, err := store.Events(indexer.Query{Contract: "controller", Name: "AddedController"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Remove(events)).To(Succeed())
+
+		violations, err := monitor(store).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details(violations)).To(Equal([]string{"controller_roles: controller count is 1, 0 controllers are indexed"}))
+	})
+
+	It("finds the tokens missing from the index", func() {
+		store := index()
+		events, err := store.Events(indexer.Query{Contract: "token_whitelist", Name: "AddedToken", Args: map[string]string{"_token": StablecoinAddress.Hex()}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(store.Remove(events)).To(Succeed())
+
+		violations, err := monitor(store).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details(violations)).To(Equal([]string{"token_whitelist: token " + StablecoinAddress.Hex() + " is not indexed"}))
+	})
+
+	It("finds a licence amount not matching its last indexed update", func() {
+		store := index()
+		Expect(store.Append(Chain.Head().Uint64(), []indexer.Event{{
+			Contract:    "licence",
+			Address:     LicenceAddress,
+			Name:        "UpdatedLicenceAmount",
+			BlockNumber: Chain.Head().Uint64(),
+			LogIndex:    99,
+			Args:        map[string]interface{}{"_newAmount": big.NewInt(42)},
+		}})).To(Succeed())
+
+		violations, err := monitor(store).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details(violations)).To(Equal([]string{"licence_amount: licence amount is 10, the last indexed update set 42"}))
+	})
+
+	It("finds the TKN supply growing or above its cap", func() {
+		invariants = []invariant.Invariant{invariant.TKNSupply(TKNBurnerAddress, Backend, big.NewInt(1))}
+		m := monitor(index())
+		violations, err := m.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Detail).To(HavePrefix("total supply "))
+		Expect(violations[0].Detail).To(HaveSuffix(" is above the cap 1"))
+
+		// The supply is remembered by the invariant between the checks.
+		Chain.CommitTx(TKNBurner.Mint(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(1)))
+		violations, err = monitor(index()).Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[1].Detail).To(MatchRegexp(`^total supply grew from \d+ to \d+$`))
+	})
+
+	It("alerts a violation once, and again once resolved", func() {
+		store := index()
+		events, err := store.Events(indexer.Query{Contract: "controller", Name: "AddedController"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Remove(events)).To(Succeed())
+		m := monitor(store)
+
+		_, err = m.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = m.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(alerted).To(HaveLen(1))
+		Expect(alerted[0][0].Resolved).To(BeFalse())
+
+		Expect(store.Append(Chain.Head().Uint64(), events)).To(Succeed())
+		violations, err := m.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+		Expect(alerted).To(HaveLen(2))
+		Expect(alerted[1]).To(HaveLen(1))
+		Expect(alerted[1][0].Resolved).To(BeTrue())
+		Expect(alerted[1][0].Invariant).To(Equal("controller_roles"))
+	})
+
+	It("keeps the violations of an invariant failing to be checked", func() {
+		failing := errors.New("node down")
+		fail := false
+		invariants = append(invariants[:2], invariant.Invariant{
+			Name: "controller_roles",
+			Check: func(opts *bind.CallOpts, store indexer.Store) ([]string, error) {
+				if fail {
+					return nil, failing
+				}
+				return []string{"broken"}, nil
+			},
+		})
+		m := monitor(index())
+		_, err := m.Check(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		fail = true
+		_, err = m.Check(ctx)
+		Expect(err).To(MatchError("1 invariants not checked: checking controller_roles: node down"))
+		Expect(alerted).To(HaveLen(1))
+	})
+
+	It("reads the state at the last indexed block", func() {
+		store := index()
+		Chain.CommitTx(TKNBurner.Mint(BankAccount.TransactOpts(), common.HexToAddress("0x1"), big.NewInt(1)))
+		_, err := monitor(store).Check(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,144 @@
+package keys_test
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/keys"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+var _ = Describe("Keystore", func() {
+
+	var dir string
+	var ks *keys.Keystore
+	var account common.Address
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "keys")
+		Expect(err).ToNot(HaveOccurred())
+		ks = keys.OpenWithScrypt(dir, keystore.LightScryptN, keystore.LightScryptP)
+		account, err = ks.Create("secret")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should list the created account", func() {
+		Expect(ks.Accounts()).To(Equal([]common.Address{account}))
+		Expect(ks.Has(account)).To(BeTrue())
+	})
+
+	It("should write the key file in the directory", func() {
+		path, err := ks.Path(account)
+		Expect(err).ToNot(HaveOccurred())
+		key, err := signer.DecryptKeyFile(path, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key.PublicKey).ToNot(BeNil())
+	})
+
+	Describe("TransactOpts", func() {
+
+		chainID := big.NewInt(1337)
+
+		It("should sign replay protected transactions for the chain", func() {
+			opts, err := ks.TransactOpts(account, "secret", chainID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts.From).To(Equal(account))
+
+			signer := types.NewEIP155Signer(chainID)
+			tx, err := opts.Signer(signer, account, types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tx.Protected()).To(BeTrue())
+			Expect(tx.ChainId()).To(Equal(chainID))
+			from, err := types.Sender(signer, tx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(from).To(Equal(account))
+		})
+
+		It("should not sign for another address", func() {
+			opts, err := ks.TransactOpts(account, "secret", chainID)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = opts.Signer(types.NewEIP155Signer(chainID), common.HexToAddress("0x1"), types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil))
+			Expect(err).To(Equal(signer.ErrNotAuthorized))
+		})
+
+		It("should fail with the wrong passphrase", func() {
+			_, err := ks.TransactOpts(account, "wrong", chainID)
+			Expect(errors.Cause(err)).To(Equal(keystore.ErrDecrypt))
+		})
+
+		It("should fail for an unknown account", func() {
+			_, err := ks.TransactOpts(common.HexToAddress("0x1"), "secret", chainID)
+			Expect(errors.Cause(err)).To(Equal(keys.ErrUnknownAccount))
+		})
+	})
+
+	Describe("SignHash", func() {
+
+		It("should sign the hash with the key of the account", func() {
+			hash := crypto.Keccak256([]byte("message"))
+			sig, err := ks.SignHash(account, "secret", hash)
+			Expect(err).ToNot(HaveOccurred())
+			pub, err := crypto.SigToPub(hash, sig)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crypto.PubkeyToAddress(*pub)).To(Equal(account))
+		})
+
+		It("should fail with the wrong passphrase", func() {
+			_, err := ks.SignHash(account, "wrong", crypto.Keccak256([]byte("message")))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Rotate", func() {
+
+		It("should create a new account and keep the old one", func() {
+			next, err := ks.Rotate(account, "secret")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next).ToNot(Equal(account))
+			Expect(ks.Accounts()).To(ConsistOf([]common.Address{account, next}))
+
+			_, err = ks.TransactOpts(next, "secret", big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should require the passphrase of the old account", func() {
+			_, err := ks.Rotate(account, "wrong")
+			Expect(err).To(HaveOccurred())
+			Expect(ks.Accounts()).To(Equal([]common.Address{account}))
+		})
+
+		When("the old account is retired", func() {
+
+			It("should delete its key file", func() {
+				next, err := ks.Rotate(account, "secret")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ks.Retire(account, "secret")).To(Succeed())
+				Expect(ks.Accounts()).To(Equal([]common.Address{next}))
+			})
+		})
+	})
+
+	Describe("ChangePassphrase", func() {
+
+		It("should encrypt the key with the new passphrase", func() {
+			Expect(ks.ChangePassphrase(account, "secret", "new secret")).To(Succeed())
+			_, err := ks.TransactOpts(account, "secret", big.NewInt(1))
+			Expect(err).To(HaveOccurred())
+			_, err = ks.TransactOpts(account, "new secret", big.NewInt(1))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
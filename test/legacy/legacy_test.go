@@ -0,0 +1,141 @@
+package legacy_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/legacy"
+)
+
+var _ = Describe("legacy", func() {
+
+	schema := legacy.Schema{
+		"event_name": "name",
+		"block":      "block_number",
+		"tx":         "tx_hash",
+		"amount":     "args._amount",
+		"undo":       "removed",
+	}
+
+	event := indexer.Event{
+		Contract:    "licence",
+		Name:        "TransferredToTokenHolder",
+		BlockNumber: 12,
+		TxHash:      common.HexToHash("0x01"),
+		Args:        map[string]interface{}{"_amount": big.NewInt(100)},
+	}
+
+	Describe("Schema", func() {
+		It("maps the events to the legacy records", func() {
+			Expect(schema.Validate()).To(Succeed())
+			Expect(schema.Record(event)).To(Equal(map[string]interface{}{
+				"event_name": "TransferredToTokenHolder",
+				"block":      uint64(12),
+				"tx":         common.HexToHash("0x01").Hex(),
+				"amount":     "100",
+				"undo":       false,
+			}))
+		})
+
+		It("writes the events as they are without a schema", func() {
+			Expect(legacy.Schema(nil).Record(event)).To(Equal(event))
+		})
+
+		It("rejects the unknown fields", func() {
+			Expect(legacy.Schema{"id": "identifier"}.Validate()).To(MatchError(`field "id" of the legacy records maps unknown event field "identifier"`))
+			Expect(legacy.Schema{"arg": "args."}.Validate()).To(HaveOccurred())
+		})
+	})
+
+	Describe("File", func() {
+		var (
+			dir  string
+			path string
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "legacy")
+			Expect(err).ToNot(HaveOccurred())
+			path = filepath.Join(dir, "events.jsonl")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("appends a record per line", func() {
+			f, err := legacy.OpenFile(path, schema)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Write(12, []indexer.Event{event})).To(Succeed())
+			removed := event
+			removed.Removed = true
+			Expect(f.Write(13, []indexer.Event{removed})).To(Succeed())
+			Expect(f.Close()).To(Succeed())
+
+			b, err := ioutil.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(
+				`{"amount":"100","block":12,"event_name":"TransferredToTokenHolder","tx":"` + event.TxHash.Hex() + `","undo":false}` + "\n" +
+					`{"amount":"100","block":12,"event_name":"TransferredToTokenHolder","tx":"` + event.TxHash.Hex() + `","undo":true}` + "\n"))
+		})
+
+		It("rejects an invalid schema", func() {
+			_, err := legacy.OpenFile(path, legacy.Schema{"id": "identifier"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Queue", func() {
+		var (
+			status  int
+			batches []legacy.Batch
+			server  *httptest.Server
+			queue   *legacy.Queue
+		)
+
+		BeforeEach(func() {
+			status = http.StatusAccepted
+			batches = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var b legacy.Batch
+				Expect(json.NewDecoder(r.Body).Decode(&b)).To(Succeed())
+				batches = append(batches, b)
+				w.WriteHeader(status)
+			}))
+			queue = &legacy.Queue{URL: server.URL, Schema: legacy.Schema{"tx": "tx_hash"}}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("posts the records in a batch", func() {
+			Expect(queue.Write(12, []indexer.Event{event, event})).To(Succeed())
+			Expect(batches).To(Equal([]legacy.Batch{{Head: 12, Records: []interface{}{
+				map[string]interface{}{"tx": event.TxHash.Hex()},
+				map[string]interface{}{"tx": event.TxHash.Hex()},
+			}}}))
+		})
+
+		It("posts nothing without events", func() {
+			Expect(queue.Write(12, nil)).To(Succeed())
+			Expect(batches).To(BeEmpty())
+		})
+
+		It("fails on an error status", func() {
+			status = http.StatusServiceUnavailable
+			Expect(queue.Write(12, []indexer.Event{event})).To(MatchError("unexpected response status 503 Service Unavailable"))
+		})
+	})
+})
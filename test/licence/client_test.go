@@ -0,0 +1,126 @@
+package licence_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("LicenceClient", func() {
+
+	var client *bindings.LicenceClient
+	tkn := common.HexToAddress("0xaAAf91D9b90dF800Df4F55c205fd6989c977E73a")
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewLicenceClient(LicenceAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should return its address", func() {
+		Expect(client.Address()).To(Equal(LicenceAddress))
+	})
+
+	It("should return the current fee", func() {
+		f, err := client.Fee(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Scaled.String()).To(Equal("10"))
+		Expect(f.TKN).To(Equal(tkn))
+		Expect(f.Percent()).To(Equal("1.0"))
+	})
+
+	It("should not charge a licence on TKN", func() {
+		load, licence, err := client.Split(context.Background(), tkn, big.NewInt(1000))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(load.String()).To(Equal("1000"))
+		Expect(licence.String()).To(Equal("0"))
+	})
+
+	It("should return the smallest amount loading a given amount", func() {
+		f, err := client.Fee(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		for _, l := range []int64{1, 99, 100, 1000, 123456789} {
+			gross := f.Gross(common.Address{}, big.NewInt(l))
+			load, _ := f.Split(common.Address{}, gross)
+			Expect(load.Int64()).To(BeNumerically(">=", l))
+			load, _ = f.Split(common.Address{}, new(big.Int).Sub(gross, big.NewInt(1)))
+			Expect(load.Int64()).To(BeNumerically("<", l))
+		}
+	})
+
+	When("ETH is loaded", func() {
+
+		var load, licence *big.Int
+		var floatBefore, holderBefore *big.Int
+
+		BeforeEach(func() {
+			var err error
+			amount := EthToWei(1)
+			load, licence, err = client.Split(context.Background(), common.Address{}, amount)
+			Expect(err).ToNot(HaveOccurred())
+
+			floatBefore, err = Backend.BalanceAt(context.Background(), CryptoFloatAddress, nil)
+			Expect(err).ToNot(HaveOccurred())
+			holderBefore, err = Backend.BalanceAt(context.Background(), TokenHolderAddress, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			opts := BankAccount.TransactOpts()
+			opts.Value = amount
+			tx, err := Licence.Load(opts, common.Address{}, amount)
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should split the amount as the licence does", func() {
+			float, err := Backend.BalanceAt(context.Background(), CryptoFloatAddress, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(float.Sub(float, floatBefore)).To(Equal(load))
+			holder, err := Backend.BalanceAt(context.Background(), TokenHolderAddress, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(holder.Sub(holder, holderBefore)).To(Equal(licence))
+		})
+	})
+
+	When("the DAO updates the fee", func() {
+
+		var fees chan bindings.LicenceFee
+		var sub event.Subscription
+
+		BeforeEach(func() {
+			tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), DAO.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+
+			fees = make(chan bindings.LicenceFee, 1)
+			sub, err = client.WatchFee(&bind.WatchOpts{Context: context.Background()}, fees)
+			Expect(err).ToNot(HaveOccurred())
+
+			tx, err = Licence.UpdateLicenceAmount(DAO.TransactOpts(), big.NewInt(25))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		AfterEach(func() {
+			sub.Unsubscribe()
+		})
+
+		It("should send the new fee to the watchers", func() {
+			var f bindings.LicenceFee
+			Eventually(fees, time.Second).Should(Receive(&f))
+			Expect(f.Scaled.String()).To(Equal("25"))
+			Expect(f.TKN).To(Equal(tkn))
+			Expect(f.Percent()).To(Equal("2.5"))
+		})
+	})
+})
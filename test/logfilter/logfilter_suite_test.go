@@ -0,0 +1,79 @@
+package logfilter_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+func TestLogFilterSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LogFilter Suite")
+}
+
+// provider adds the HeaderByNumber method to the test backend, reporting the
+// block of the last transaction as the head, and refuses log queries like the
+// hosted providers do: over maxRange blocks when it is set, and the next
+// limited queries as over the rate limit.
+type provider struct {
+	ethertest.TestBackend
+	head     *big.Int
+	maxRange uint64
+	limited  int
+	queries  []ethereum.FilterQuery
+}
+
+func (p *provider) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: p.head}, nil
+}
+
+func (p *provider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	p.queries = append(p.queries, q)
+	if p.limited > 0 {
+		p.limited--
+		return nil, errors.New("429 Too Many Requests: project ID request rate exceeded")
+	}
+	if p.maxRange > 0 && q.BlockHash == nil && q.ToBlock.Uint64()-q.FromBlock.Uint64()+1 > p.maxRange {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	return p.TestBackend.FilterLogs(ctx, q)
+}
+
+// blocks returns the number of blocks of each query.
+func (p *provider) blocks() []uint64 {
+	var blocks []uint64
+	for _, q := range p.queries {
+		blocks = append(blocks, q.ToBlock.Uint64()-q.FromBlock.Uint64()+1)
+	}
+	return blocks
+}
+
+// commit mines the transaction and moves the head of the chain to its block.
+func (p *provider) commit(tx *types.Transaction) {
+	p.Commit()
+	r, err := p.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+	p.head = r.BlockNumber
+}
+
+var Provider *provider
+
+var _ = BeforeEach(func() {
+	err := shared.InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Provider = &provider{TestBackend: shared.Backend}
+})
+
+var _ = AfterEach(func() {
+	err := shared.Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
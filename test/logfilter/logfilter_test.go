@@ -0,0 +1,123 @@
+package logfilter_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/logfilter"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Backend", func() {
+
+	var logs *logfilter.Backend
+	var token *mocks.Token
+	var start uint64
+
+	transfers := func(opts *bind.FilterOpts) ([]*big.Int, error) {
+		it, err := token.FilterTransfer(opts, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var amounts []*big.Int
+		for it.Next() {
+			amounts = append(amounts, it.Event.Amount)
+		}
+		return amounts, it.Error()
+	}
+
+	BeforeEach(func() {
+		tx, err := ERC20Contract1.Credit(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		Provider.commit(tx)
+		start = Provider.head.Uint64() + 1
+
+		for i := 0; i < 5; i++ {
+			tx, err = ERC20Contract1.Transfer(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(int64(i+1)))
+			Expect(err).ToNot(HaveOccurred())
+			Provider.commit(tx)
+		}
+
+		logs = logfilter.NewBackend(Provider, Provider)
+		logs.Backoff = time.Millisecond
+		token, err = mocks.NewToken(ERC20Contract1Address, logs)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should filter the range in chunks up to the head", func() {
+		logs.MaxBlocks = 2
+		amounts, err := transfers(&bind.FilterOpts{Start: start})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amounts).To(Equal([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}))
+		Expect(Provider.blocks()).To(Equal([]uint64{2, 2, 1}))
+	})
+
+	It("should send the ranges within MaxBlocks as they are", func() {
+		end := start + 2
+		amounts, err := transfers(&bind.FilterOpts{Start: start, End: &end})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amounts).To(HaveLen(3))
+		Expect(Provider.blocks()).To(Equal([]uint64{3}))
+	})
+
+	It("should split the chunks refused as too large", func() {
+		Provider.maxRange = 2
+		amounts, err := transfers(&bind.FilterOpts{Start: start})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amounts).To(HaveLen(5))
+		Expect(Provider.blocks()).To(Equal([]uint64{5, 2, 2, 1}))
+	})
+
+	It("should retry the queries over the rate limit", func() {
+		Provider.limited = 2
+		amounts, err := transfers(&bind.FilterOpts{Start: start})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amounts).To(HaveLen(5))
+		Expect(Provider.queries).To(HaveLen(3))
+	})
+
+	It("should give up after the attempts", func() {
+		Provider.limited = 10
+		logs.Attempts = 3
+		_, err := transfers(&bind.FilterOpts{Start: start})
+		Expect(err).To(MatchError(ContainSubstring("429 Too Many Requests")))
+		Expect(Provider.queries).To(HaveLen(3))
+	})
+
+	It("should stop waiting when the context is done", func() {
+		Provider.limited = 10
+		logs.Backoff = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := transfers(&bind.FilterOpts{Start: start, Context: ctx})
+		Expect(errors.Cause(err)).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("should space the queries by the interval", func() {
+		logs.MaxBlocks = 1
+		logs.Interval = 20 * time.Millisecond
+		began := time.Now()
+		amounts, err := transfers(&bind.FilterOpts{Start: start})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amounts).To(HaveLen(5))
+		Expect(time.Since(began)).To(BeNumerically(">=", 80*time.Millisecond))
+	})
+
+	It("should send the queries of a block hash as they are", func() {
+		hash := common.HexToHash("0x01")
+		logs.FilterLogs(context.Background(), ethereum.FilterQuery{BlockHash: &hash})
+		Expect(Provider.queries).To(HaveLen(1))
+		Expect(Provider.queries[0].BlockHash).To(Equal(&hash))
+		Expect(Provider.queries[0].FromBlock).To(BeNil())
+		Expect(Provider.queries[0].ToBlock).To(BeNil())
+	})
+})
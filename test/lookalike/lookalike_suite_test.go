@@ -0,0 +1,13 @@
+package lookalike_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLookalikeSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lookalike Suite")
+}
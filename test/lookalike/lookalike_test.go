@@ -0,0 +1,103 @@
+package lookalike_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/lookalike"
+)
+
+var _ = Describe("Check", func() {
+
+	treasury := common.HexToAddress("0xA1b2C3d4e5F60718293a4B5c6D7e8F9012345678")
+	known := []lookalike.Counterparty{
+		{Address: treasury, Label: "treasury"},
+		{Address: common.HexToAddress("0x00000000000000000000000000000000000000ff"), Label: "other"},
+	}
+
+	It("should not match a known address itself", func() {
+		Expect(lookalike.Check(treasury, known, 0)).To(BeEmpty())
+	})
+
+	It("should not match an unrelated address", func() {
+		Expect(lookalike.Check(common.HexToAddress("0x1234567890123456789012345678901234567890"), known, 0)).To(BeEmpty())
+	})
+
+	It("should match an address sharing the first and last characters", func() {
+		poisoned := common.HexToAddress("0xa1b2c30000000000000000000000000000005678")
+		matches := lookalike.Check(poisoned, known, 0)
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Address).To(Equal(treasury))
+		Expect(matches[0].Label).To(Equal("treasury"))
+		Expect(matches[0].Prefix).To(Equal(6))
+		Expect(matches[0].Suffix).To(Equal(4))
+	})
+
+	It("should match on the suffix alone", func() {
+		poisoned := common.HexToAddress("0xffffffffffffffffffffffffffffffff12345678")
+		Expect(lookalike.Check(poisoned, known, 0)).To(HaveLen(1))
+	})
+
+	It("should not match below the threshold", func() {
+		poisoned := common.HexToAddress("0xa1b2c30000000000000000000000000000005678")
+		Expect(lookalike.Check(poisoned, known, 11)).To(BeEmpty())
+	})
+
+	It("should report a known address once", func() {
+		poisoned := common.HexToAddress("0xa1b2c30000000000000000000000000000005678")
+		Expect(lookalike.Check(poisoned, append(known, known[0]), 0)).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Book", func() {
+
+	var dir string
+	var book *lookalike.Book
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "lookalike")
+		Expect(err).ToNot(HaveOccurred())
+		book = lookalike.NewBook(filepath.Join(dir, "addresses.jsonl"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should be empty before the first counterparty", func() {
+		recent, err := book.Recent(time.Time{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recent).To(BeEmpty())
+	})
+
+	When("counterparties are added", func() {
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			for _, c := range []lookalike.Counterparty{
+				{Address: common.HexToAddress("0x1"), Label: "old", LastSeen: now.Add(-100 * 24 * time.Hour)},
+				{Address: common.HexToAddress("0x2"), Label: "claim recipient", LastSeen: now.Add(-time.Hour)},
+				{Address: common.HexToAddress("0x2"), Label: "new owner", LastSeen: now},
+			} {
+				Expect(book.Add(c)).To(Succeed())
+			}
+		})
+
+		It("should return the latest record of the recent ones", func() {
+			recent, err := book.Recent(now.Add(-90 * 24 * time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recent).To(HaveLen(1))
+			Expect(recent[0].Address).To(Equal(common.HexToAddress("0x2")))
+			Expect(recent[0].Label).To(Equal("new owner"))
+			Expect(recent[0].LastSeen).To(BeTemporally("==", now))
+		})
+	})
+})
@@ -0,0 +1,54 @@
+package migration_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestMigrationSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migration Suite")
+}
+
+var Chain *TestChain
+
+var OldDeployerAddress common.Address
+var OldDeployer *bindings.WalletDeployer
+var OldCache *bindings.WalletCache
+
+var NewDeployerAddress common.Address
+var NewDeployer *bindings.WalletDeployer
+var NewCacheAddress common.Address
+var NewCache *bindings.WalletCache
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = NewTestChain(Backend, MineOnSend)
+
+	var oldCacheAddress common.Address
+	oldCacheAddress, _, OldCache, err = bindings.DeployWalletCache(BankAccount.TransactOpts(), Chain, ENSRegistryAddress, EthToWei(1), [32]byte{}, [32]byte{}, [32]byte{}, [32]byte{})
+	Expect(err).ToNot(HaveOccurred())
+	OldDeployerAddress, _, OldDeployer, err = bindings.DeployWalletDeployer(BankAccount.TransactOpts(), Chain, ENSRegistryAddress, [32]byte{}, [32]byte{})
+	Expect(err).ToNot(HaveOccurred())
+	Chain.RegisterName("wallet-deployer", OldDeployerAddress)
+	Chain.RegisterName("wallet-cache", oldCacheAddress)
+
+	// The new contracts resolve each other under names of their own.
+	NewCacheAddress, _, NewCache, err = bindings.DeployWalletCache(BankAccount.TransactOpts(), Chain, ENSRegistryAddress, EthToWei(1), [32]byte{}, [32]byte{}, [32]byte{}, EnsNode("wallet-deployer-v2.tokencard.eth"))
+	Expect(err).ToNot(HaveOccurred())
+	NewDeployerAddress, _, NewDeployer, err = bindings.DeployWalletDeployer(BankAccount.TransactOpts(), Chain, ENSRegistryAddress, [32]byte{}, EnsNode("wallet-cache-v2.tokencard.eth"))
+	Expect(err).ToNot(HaveOccurred())
+	Chain.RegisterName("wallet-deployer-v2", NewDeployerAddress)
+	Chain.RegisterName("wallet-cache-v2", NewCacheAddress)
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
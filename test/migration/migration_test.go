@@ -0,0 +1,168 @@
+package migration_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/migration"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Migrator", func() {
+
+	ctx := context.Background()
+	var dir string
+	var migrator *migration.Migrator
+	var s *snapshot.Snapshot
+	var first, second common.Address
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "migration")
+		Expect(err).ToNot(HaveOccurred())
+
+		Chain.CommitTx(OldDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address()))
+		Chain.CommitTx(OldDeployer.DeployWallet(Controller.TransactOpts(), RandomAccount.Address()))
+		first, err = OldDeployer.DeployedWallets(nil, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		second, err = OldDeployer.DeployedWallets(nil, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+
+		w, err := bindings.NewWallet(second, Chain)
+		Expect(err).ToNot(HaveOccurred())
+		Chain.CommitTx(w.SetSpendLimit(RandomAccount.TransactOpts(), EthToWei(3)))
+		Chain.CommitTx(w.SetGasTopUpLimit(RandomAccount.TransactOpts(), FinneyToWei(200)))
+		Chain.CommitTx(w.SetWhitelist(RandomAccount.TransactOpts(), []common.Address{Owner.Address(), BankAccount.Address()}))
+
+		s, err = snapshot.Take(ctx, Chain, snapshot.Contracts{WalletDeployer: OldDeployerAddress}, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		migrator, err = migration.New(NewDeployerAddress, NewCacheAddress, Chain, Chain, Controller.TransactOpts(), filepath.Join(dir, "journal.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("plans the settings of the old wallets", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(steps).To(HaveLen(2))
+		Expect(steps[0].OldWallet).To(Equal(first))
+		Expect(steps[0].Owner).To(Equal(Owner.Address()))
+		Expect(steps[0].SpendLimit.Set).To(BeFalse())
+		Expect(steps[0].Whitelist).To(BeNil())
+		Expect(steps[1].SpendLimit).To(Equal(migration.Limit{Set: true, Value: EthToWei(3)}))
+		Expect(steps[1].GasTopUpLimit).To(Equal(migration.Limit{Set: true, Value: FinneyToWei(200)}))
+		Expect(steps[1].Whitelist).To(Equal([]common.Address{Owner.Address(), BankAccount.Address()}))
+	})
+
+	It("simulates the migrations without sending them", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		Chain.CommitTx(NewCache.CacheWallet(RandomAccount.TransactOpts()))
+		steps[0].Owner = RandomAccount.Address()
+
+		d, err := migrator.DryRun(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Cached).To(Equal(uint64(1)))
+		Expect(d.Pending).To(HaveLen(1))
+		Expect(d.Pending[0].OldWallet).To(Equal(second))
+		Expect(d.Gas).ToNot(BeZero())
+		Expect(d.Rejected).To(HaveLen(1))
+		Expect(d.Rejected[0].OldWallet).To(Equal(first))
+
+		w, err := NewDeployer.DeployedWallets(nil, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w).To(Equal(common.Address{}))
+	})
+
+	It("migrates the wallets with their settings and verifies them", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		Chain.CommitTx(NewCache.CacheWallet(RandomAccount.TransactOpts()))
+
+		var progress []migration.Result
+		migrator.Progress = func(done, total int, r migration.Result) {
+			progress = append(progress, r)
+		}
+		report, err := migrator.Run(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeTrue())
+		Expect(report.Verified).To(Equal(2))
+		Expect(progress).To(HaveLen(2))
+		Expect(progress[1].Error).To(BeEmpty())
+
+		migrated, err := NewDeployer.DeployedWallets(nil, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress[1].Wallet).To(Equal(migrated))
+		step, err := migration.ReadStep(ctx, Chain, migrated)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(step.Owner).To(Equal(RandomAccount.Address()))
+		Expect(step.Whitelist).To(Equal(steps[1].Whitelist))
+
+		journal, err := ioutil.ReadFile(filepath.Join(dir, "journal.jsonl"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(journal)).To(ContainSubstring(strings.ToLower(migrated.Hex())))
+	})
+
+	It("resumes the migration of the wallets not migrated", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = migrator.Run(ctx, steps[:1])
+		Expect(err).ToNot(HaveOccurred())
+
+		var migrated []common.Address
+		migrator.Progress = func(done, total int, r migration.Result) {
+			Expect(done).To(Equal(2))
+			migrated = append(migrated, r.OldWallet)
+		}
+		report, err := migrator.Run(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeTrue())
+		Expect(migrated).To(Equal([]common.Address{second}))
+	})
+
+	It("journals the failed migrations and reports them missing", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		steps[1].Owner = BankAccount.Address()
+
+		var failed []migration.Result
+		migrator.Progress = func(done, total int, r migration.Result) {
+			if r.Error != "" {
+				failed = append(failed, r)
+			}
+		}
+		report, err := migrator.Run(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].OldWallet).To(Equal(second))
+		Expect(report.Verified).To(Equal(1))
+		Expect(report.Missing).To(HaveLen(1))
+		Expect(report.Missing[0].OldWallet).To(Equal(second))
+	})
+
+	It("reports the new wallets whose settings differ", func() {
+		steps, err := migrator.Plan(ctx, s)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = migrator.Run(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+
+		steps[1].SpendLimit.Value = EthToWei(4)
+		report, err := migrator.Verify(ctx, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Complete()).To(BeFalse())
+		Expect(report.Mismatched).To(HaveLen(1))
+		Expect(report.Mismatched[0].OldWallet).To(Equal(second))
+		Expect(report.Mismatched[0].Reason).To(ContainSubstring("spend limit"))
+	})
+})
@@ -0,0 +1,219 @@
+package monolith_test
+
+import (
+	"bufio"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	v1Path    = "github.com/tokencard/contracts/v2/pkg/monolith/v1"
+	v1Surface = "testdata/v1.api"
+)
+
+// surface lists the exported identifiers of a package one per line, with the
+// exported fields and methods of its types, including those of the types it
+// aliases.
+func surface(pkg *types.Package) []string {
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	typeString := func(t types.Type) string {
+		return types.TypeString(t, qualifier)
+	}
+
+	var lines []string
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		tn, ok := obj.(*types.TypeName)
+		if !ok {
+			lines = append(lines, types.ObjectString(obj, qualifier))
+			continue
+		}
+
+		t := types.Unalias(tn.Type())
+		switch u := t.Underlying().(type) {
+		case *types.Struct:
+			if tn.IsAlias() {
+				lines = append(lines, fmt.Sprintf("type %s = %s", name, typeString(t)))
+			} else {
+				lines = append(lines, fmt.Sprintf("type %s struct", name))
+			}
+			for i := 0; i < u.NumFields(); i++ {
+				f := u.Field(i)
+				if f.Exported() {
+					lines = append(lines, fmt.Sprintf("field %s.%s %s", name, f.Name(), typeString(f.Type())))
+				}
+			}
+		case *types.Interface:
+			if tn.IsAlias() {
+				lines = append(lines, fmt.Sprintf("type %s = %s", name, typeString(t)))
+			} else {
+				lines = append(lines, fmt.Sprintf("type %s interface", name))
+			}
+		default:
+			if tn.IsAlias() {
+				lines = append(lines, fmt.Sprintf("type %s = %s", name, typeString(t)))
+			} else {
+				lines = append(lines, fmt.Sprintf("type %s %s", name, typeString(u)))
+			}
+		}
+
+		mt := t
+		if !types.IsInterface(t) {
+			mt = types.NewPointer(t)
+		}
+		methods := types.NewMethodSet(mt)
+		for i := 0; i < methods.Len(); i++ {
+			m := methods.At(i).Obj()
+			if m.Exported() {
+				lines = append(lines, fmt.Sprintf("method %s.%s %s", name, m.Name(), typeString(m.Type())))
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func readSurface(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if l := s.Text(); l != "" && !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+	return lines, s.Err()
+}
+
+func difference(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, l := range b {
+		in[l] = true
+	}
+	var d []string
+	for _, l := range a {
+		if !in[l] {
+			d = append(d, l)
+		}
+	}
+	return d
+}
+
+// generated tells whether an object is declared in a generated file.
+func generated(fset *token.FileSet, obj types.Object) bool {
+	data, err := ioutil.ReadFile(fset.Position(obj.Pos()).Filename)
+	Expect(err).ToNot(HaveOccurred())
+	return strings.HasPrefix(string(data), "// Code generated")
+}
+
+var _ = Describe("API surface", func() {
+
+	var fset *token.FileSet
+	var pkg *types.Package
+
+	BeforeEach(func() {
+		if pkg != nil {
+			return
+		}
+		fset = token.NewFileSet()
+		var err error
+		pkg, err = importer.ForCompiler(fset, "source", nil).(types.ImporterFrom).ImportFrom(v1Path, ".", 0)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should only change by additions", func() {
+		current := surface(pkg)
+		recorded, err := readSurface(v1Surface)
+		Expect(err).ToNot(HaveOccurred())
+
+		removed := difference(recorded, current)
+		Expect(removed).To(BeEmpty(), "breaking changes of v1, deprecate the identifiers instead and make the changes in v2")
+
+		added := difference(current, recorded)
+		if *update && len(added) > 0 {
+			header := "# The API surface of pkg/monolith/v1, see its package documentation.\n# Lines may only be added, with go test ./test/monolith -update.\n"
+			Expect(ioutil.WriteFile(v1Surface, []byte(header+strings.Join(current, "\n")+"\n"), 0644)).To(Succeed())
+			return
+		}
+		Expect(added).To(BeEmpty(), "additions to v1, record them with -update")
+	})
+
+	It("should not expose the generated bindings", func() {
+		exposed := map[string]bool{}
+		var visit func(t types.Type)
+		visit = func(t types.Type) {
+			switch t := types.Unalias(t).(type) {
+			case *types.Named:
+				obj := t.Obj()
+				if obj.Pkg() != nil && strings.HasSuffix(obj.Pkg().Path(), "/pkg/bindings") && generated(fset, obj) {
+					exposed[obj.Name()] = true
+				}
+			case *types.Pointer:
+				visit(t.Elem())
+			case *types.Slice:
+				visit(t.Elem())
+			case *types.Map:
+				visit(t.Key())
+				visit(t.Elem())
+			case *types.Chan:
+				visit(t.Elem())
+			case *types.Signature:
+				for i := 0; i < t.Params().Len(); i++ {
+					visit(t.Params().At(i).Type())
+				}
+				for i := 0; i < t.Results().Len(); i++ {
+					visit(t.Results().At(i).Type())
+				}
+			}
+		}
+
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !obj.Exported() {
+				continue
+			}
+			visit(obj.Type())
+			if s, ok := types.Unalias(obj.Type()).Underlying().(*types.Struct); ok {
+				for i := 0; i < s.NumFields(); i++ {
+					if s.Field(i).Exported() {
+						visit(s.Field(i).Type())
+					}
+				}
+			}
+			mt := obj.Type()
+			if !types.IsInterface(mt) {
+				mt = types.NewPointer(mt)
+			}
+			methods := types.NewMethodSet(mt)
+			for i := 0; i < methods.Len(); i++ {
+				if methods.At(i).Obj().Exported() {
+					visit(methods.At(i).Obj().Type())
+				}
+			}
+		}
+		Expect(exposed).To(BeEmpty())
+	})
+})
@@ -0,0 +1,25 @@
+package monolith_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/tokencard/contracts/v2/pkg/monolith/v1"
+)
+
+var _ = Describe("NewContract", func() {
+
+	It("should parse the ABI of a known contract", func() {
+		address := common.HexToAddress("0x5a7ac1a4e2d76e33a1b5a2c4b8d4a8b1e3a77c01")
+		c, err := v1.NewContract("token_whitelist", address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Name).To(Equal("token_whitelist"))
+		Expect(c.Address).To(Equal(address))
+		Expect(c.ABI.Events).To(HaveKey("AddedToken"))
+	})
+
+	It("should fail on an unknown contract", func() {
+		_, err := v1.NewContract("referral", common.Address{})
+		Expect(err).To(MatchError(ContainSubstring("unknown contract")))
+	})
+})
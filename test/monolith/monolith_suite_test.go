@@ -0,0 +1,18 @@
+package monolith_test
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update records the additions to the API surface of v1 instead of failing
+// on them, removals and changes are never recorded.
+var update = flag.Bool("update", false, "record the additions to the API surface")
+
+func TestMonolithSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Monolith Suite")
+}
@@ -0,0 +1,479 @@
+package monolith_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/tokencard/ethertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	contractsgrpc "github.com/tokencard/contracts/v2/pkg/grpc"
+	"github.com/tokencard/contracts/v2/pkg/monolith"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// node adds the methods of a Node missing from the test backend.
+type node struct {
+	ethertest.TestBackend
+}
+
+func (n node) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(0)}, nil
+}
+
+func (n node) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+var _ = Describe("Monolith", func() {
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var output *bytes.Buffer
+
+	config := func() *monolith.Config {
+		cfg := monolith.DefaultConfig()
+		cfg.APIKeysEnv = "API_KEYS"
+		cfg.Contracts.Licence = LicenceAddress
+		cfg.Contracts.TokenWhitelist = TokenWhitelistAddress
+		cfg.Contracts.Controller = ControllerContractAddress
+		return cfg
+	}
+
+	newMonolith := func(cfg *monolith.Config, env map[string]string) *monolith.Monolith {
+		m := monolith.New(cfg)
+		m.Node = node{Backend}
+		m.Getenv = func(key string) string { return env[key] }
+		m.Output = output
+		return m
+	}
+
+	serve := func(m *monolith.Monolith, method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	BeforeEach(func() {
+		err := InitializeBackend()
+		Expect(err).ToNot(HaveOccurred())
+		ctx, cancel = context.WithCancel(context.Background())
+		output = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		cancel()
+		err := Backend.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should serve the API of the injected node", func() {
+		m := newMonolith(config(), nil)
+		Expect(m.Handler()).To(BeNil())
+		Expect(m.Start(ctx)).To(Succeed())
+
+		rec := serve(m, http.MethodGet, "/licence", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring(`"licence_dao"`))
+	})
+
+	It("should not start twice", func() {
+		m := newMonolith(config(), nil)
+		Expect(m.Start(ctx)).To(Succeed())
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("already started")))
+	})
+
+	It("should not share state between monoliths", func() {
+		withMetrics := config()
+		withMetrics.Metrics = true
+		a := newMonolith(withMetrics, map[string]string{"API_KEYS": "a"})
+		Expect(a.Start(ctx)).To(Succeed())
+		b := newMonolith(config(), map[string]string{"API_KEYS": "b"})
+		Expect(b.Start(ctx)).To(Succeed())
+
+		Expect(serve(a, http.MethodGet, "/metrics", "").Code).To(Equal(http.StatusOK))
+		Expect(serve(b, http.MethodGet, "/metrics", "").Code).To(Equal(http.StatusNotFound))
+
+		req := httptest.NewRequest(http.MethodPost, "/licence/amount", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer a")
+		rec := httptest.NewRecorder()
+		b.Handler().ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should serve the routes of the enabled subsystems", func() {
+		cfg := config()
+		cfg.Indexer.Enabled = true
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(Succeed())
+
+		rec := serve(m, http.MethodPost, "/graphql", `{"query":"{ events { name } }"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(serve(m, http.MethodGet, "/relay", "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should serve the contract operations over gRPC", func() {
+		m := newMonolith(config(), map[string]string{"API_KEYS": "secret"})
+		Expect(m.Start(ctx)).To(Succeed())
+		Expect(m.GRPCServer()).To(BeNil())
+
+		cfg := config()
+		cfg.GRPC.ListenAddress = "127.0.0.1:0"
+		m = newMonolith(cfg, map[string]string{"API_KEYS": "secret"})
+		Expect(m.Start(ctx)).To(Succeed())
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		go m.GRPCServer().Serve(l)
+		defer m.GRPCServer().Stop()
+
+		conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		client := contractsgrpc.NewContractsClient(conn)
+		licence, err := client.GetLicence(ctx, &contractsgrpc.GetLicenceRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(licence.AmountScaled).To(Equal("10"))
+
+		authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+		_, err = client.UpdateLicenceAmount(authorized, &contractsgrpc.UpdateLicenceAmountRequest{Amount: "20"})
+		Expect(status.Code(err)).To(Equal(codes.Unavailable))
+		Expect(err).To(MatchError(ContainSubstring("no signing account configured")))
+	})
+
+	It("should not serve gRPC on the listen address of the API", func() {
+		cfg := config()
+		cfg.GRPC.ListenAddress = cfg.ListenAddress
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError("grpc.listen_address must differ from listen_address"))
+	})
+
+	It("should require a node announcing the heads for the fast path", func() {
+		cfg := config()
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.FastPath = true
+		cfg.RPCURL = "http://localhost:8545"
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("websocket or IPC rpc_url")))
+	})
+
+	It("should require a node returning its blocks for the EWMA gas prices", func() {
+		cfg := config()
+		cfg.Gas.Source = "ewma"
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("gas.source ewma requires a node returning its blocks")))
+	})
+
+	It("should reject the invalid gas price settings", func() {
+		for _, c := range []struct {
+			set func(cfg *monolith.Config)
+			err string
+		}{
+			{func(cfg *monolith.Config) { cfg.GasStrategy = "urgent" }, "gas_strategy"},
+			{func(cfg *monolith.Config) { cfg.Gas.Source = "oracle" }, "is not node, ewma or station"},
+			{func(cfg *monolith.Config) { cfg.Gas.Source, cfg.Gas.Alpha = "ewma", 1.5 }, "gas.alpha 1.5 is out of range"},
+			{func(cfg *monolith.Config) { cfg.Gas.Window = 10 }, "require gas.source ewma"},
+			{func(cfg *monolith.Config) {
+				cfg.Gas.Source, cfg.Failover.URLs = "ewma", []string{"http://backup"}
+			}, "can not be used with failover.urls"},
+			{func(cfg *monolith.Config) { cfg.Gas.Source, cfg.Gas.StationURL = "station", "ftp://prices" }, "is not an HTTP URL"},
+			{func(cfg *monolith.Config) { cfg.Gas.StationURL = "https://prices" }, "requires gas.source station"},
+			{func(cfg *monolith.Config) { cfg.Gas.MinPrice = "-1" }, "gas.min_price"},
+			{func(cfg *monolith.Config) { cfg.Gas.MinPrice, cfg.Gas.MaxPrice = "2", "1" }, "must not be above gas.max_price"},
+		} {
+			cfg := config()
+			c.set(cfg)
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(c.err)))
+		}
+	})
+
+	It("should require the indexer for the reconciliation", func() {
+		cfg := config()
+		cfg.Reconciliation.Enabled = true
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("reconciliation requires the indexer")))
+	})
+
+	It("should require the address of TKN to index its events", func() {
+		cfg := config()
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.TKNEvents = true
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("indexer.tkn_events requires contracts.tkn")))
+	})
+
+	It("should require the Licence to link its payouts", func() {
+		cfg := config()
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.LinkPayouts = true
+		cfg.Contracts.Licence = common.Address{}
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("indexer.link_payouts requires contracts.licence")))
+	})
+
+	It("should refuse to start on another network than chain_id", func() {
+		cfg := config()
+		cfg.ChainID = 1
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("the node is on chain 1337, chain_id is 1: wrong network")))
+
+		cfg.ChainID = 1337
+		Expect(newMonolith(cfg, nil).Start(ctx)).To(Succeed())
+	})
+
+	It("should require a destination for the status page", func() {
+		cfg := config()
+		cfg.StatusPage.Enabled = true
+		m := newMonolith(cfg, nil)
+		Expect(m.Start(ctx)).To(MatchError(ContainSubstring("status_page requires dir, s3.bucket or github.repo")))
+	})
+
+	It("should log to the injected logger", func() {
+		buf := gbytes.NewBuffer()
+		logger := log.New()
+		logger.SetHandler(log.StreamHandler(buf, log.LogfmtFormat()))
+
+		cfg := config()
+		cfg.Indexer.Enabled = true
+		m := newMonolith(cfg, nil)
+		m.Logger = logger
+		Expect(m.Start(ctx)).To(Succeed())
+		Eventually(buf).Should(gbytes.Say("module=indexer"))
+		Expect(output.Len()).To(BeZero())
+	})
+
+	Describe("read-only mode", func() {
+
+		var dir string
+		var key *ecdsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "monolith")
+			Expect(err).ToNot(HaveOccurred())
+			key, err = crypto.GenerateKey()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		signerConfig := func() *monolith.Config {
+			cfg := config()
+			cfg.KeystoreFile = filepath.Join(dir, "operator.json")
+			cfg.Account = crypto.PubkeyToAddress(key.PublicKey)
+			cfg.ReadOnly.CheckInterval = txmgr.Duration(10 * time.Millisecond)
+			return cfg
+		}
+
+		post := func(m *monolith.Monolith) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/licence/amount", strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer a")
+			rec := httptest.NewRecorder()
+			m.Handler().ServeHTTP(rec, req)
+			return rec
+		}
+
+		It("should start read-only until the signer can be opened", func() {
+			m := newMonolith(signerConfig(), map[string]string{"API_KEYS": "a", "MONOLITHD_PASSWORD": "secret"})
+			Expect(m.Start(ctx)).To(Succeed())
+
+			Expect(serve(m, http.MethodGet, "/licence", "").Code).To(Equal(http.StatusOK))
+			rec := post(m)
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rec.Body.String()).To(ContainSubstring("read-only mode"))
+			Expect(rec.Body.String()).To(ContainSubstring(`"dependency":"signer"`))
+			Expect(serve(m, http.MethodGet, "/read-only", "").Body.String()).To(ContainSubstring(`"read_only":true`))
+
+			ks := keystore.NewKeyStore(filepath.Join(dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+			account, err := ks.ImportECDSA(key, "secret")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Rename(account.URL.Path, filepath.Join(dir, "operator.json"))).To(Succeed())
+
+			Eventually(func() string {
+				return serve(m, http.MethodGet, "/read-only", "").Body.String()
+			}).Should(ContainSubstring(`"read_only":false`))
+			Expect(post(m).Code).ToNot(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("should require the account to start read-only", func() {
+			cfg := signerConfig()
+			cfg.Account = common.Address{}
+			m := newMonolith(cfg, nil)
+			Expect(m.Start(ctx)).To(MatchError(ContainSubstring("set account to start in read-only mode")))
+		})
+	})
+
+	Describe("drift", func() {
+
+		var dir string
+		var received chan string
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "monolith")
+			Expect(err).ToNot(HaveOccurred())
+			received = make(chan string, 10)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+				received <- string(body)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+			os.RemoveAll(dir)
+		})
+
+		// driftConfig checks that the admin added by the shared setup is
+		// removed, the contracts drift from the spec from the start.
+		driftConfig := func() *monolith.Config {
+			cfg := config()
+			cfg.Drift.SpecFile = filepath.Join(dir, "spec.yaml")
+			spec := "controller:\n  remove_admins: [\"" + ControllerAdmin.Address().Hex() + "\"]\n"
+			Expect(ioutil.WriteFile(cfg.Drift.SpecFile, []byte(spec), 0600)).To(Succeed())
+			cfg.Alerts.RulesFile = filepath.Join(dir, "alerts.yaml")
+			rules := "receivers: [{name: ops, webhook: {url: \"" + server.URL + "\"}}]\nroutes: [{receiver: ops}]\n"
+			Expect(ioutil.WriteFile(cfg.Alerts.RulesFile, []byte(rules), 0600)).To(Succeed())
+			return cfg
+		}
+
+		isAdmin := func() bool {
+			Backend.Commit()
+			ok, err := ControllerContract.IsAdmin(nil, ControllerAdmin.Address())
+			Expect(err).ToNot(HaveOccurred())
+			return ok
+		}
+
+		It("should send the drifts to the receivers of the alerts", func() {
+			m := newMonolith(driftConfig(), nil)
+			Expect(m.Start(ctx)).To(Succeed())
+
+			var alert string
+			Eventually(received).Should(Receive(&alert))
+			Expect(alert).To(ContainSubstring(`"rule":"drift"`))
+			Expect(alert).To(ContainSubstring(`"severity":"warning"`))
+			Expect(alert).To(ContainSubstring("action(s) needed: controller.removeAdmin"))
+			Consistently(isAdmin, 100*time.Millisecond).Should(BeTrue())
+		})
+
+		It("should remediate the drifts with the operator key", func() {
+			cfg := driftConfig()
+			cfg.Drift.Remediate = true
+			m := newMonolith(cfg, nil)
+			m.TransactOpts = signer.NewTransactOpts(ControllerOwner.PrivKey(), big.NewInt(1337))
+			Expect(m.Start(ctx)).To(Succeed())
+
+			var alert string
+			Eventually(received).Should(Receive(&alert))
+			Expect(alert).To(ContainSubstring("action(s) remediated: controller.removeAdmin"))
+			Eventually(isAdmin).Should(BeFalse())
+		})
+
+		It("should price the remediations with the gas station", func() {
+			var queried int32
+			station := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&queried, 1)
+				w.Write([]byte(`{"fast": 30, "average": 20, "safeLow": 10}`))
+			}))
+			defer station.Close()
+
+			cfg := driftConfig()
+			cfg.Drift.Remediate = true
+			cfg.GasStrategy = "fast"
+			cfg.Gas.Source = "station"
+			cfg.Gas.StationURL = station.URL
+			m := newMonolith(cfg, nil)
+			m.TransactOpts = signer.NewTransactOpts(ControllerOwner.PrivKey(), big.NewInt(1337))
+			Expect(m.Start(ctx)).To(Succeed())
+
+			Eventually(isAdmin).Should(BeFalse())
+			Expect(atomic.LoadInt32(&queried)).To(BeNumerically(">", 0))
+		})
+
+		It("should require a signer to remediate the drifts", func() {
+			cfg := driftConfig()
+			cfg.Drift.Remediate = true
+			m := newMonolith(cfg, nil)
+			Expect(m.Start(ctx)).To(MatchError(ContainSubstring("drift.remediate requires kms, keystore_dir or keystore_file")))
+		})
+	})
+
+	Describe("Reload", func() {
+
+		It("should fail before the monolith is started", func() {
+			_, err := newMonolith(config(), nil).Reload(config())
+			Expect(err).To(MatchError(ContainSubstring("not started")))
+		})
+
+		It("should return the settings requiring a restart", func() {
+			m := newMonolith(config(), nil)
+			Expect(m.Start(ctx)).To(Succeed())
+
+			next := config()
+			next.ListenAddress = ":9090"
+			next.GRPC.ListenAddress = ":9091"
+			next.AllowedCIDRs = []string{"10.0.0.0/8"}
+			ignored, err := m.Reload(next)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ignored).To(ConsistOf("listen_address", "grpc"))
+		})
+
+		It("should require a restart to change the source of the gas prices", func() {
+			m := newMonolith(config(), nil)
+			Expect(m.Start(ctx)).To(Succeed())
+
+			next := config()
+			next.Gas.Source = "station"
+			ignored, err := m.Reload(next)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ignored).To(ConsistOf("gas"))
+		})
+
+		It("should reject an invalid configuration", func() {
+			m := newMonolith(config(), nil)
+			Expect(m.Start(ctx)).To(Succeed())
+
+			next := config()
+			next.AllowedCIDRs = []string{"nowhere"}
+			_, err := m.Reload(next)
+			Expect(err).To(MatchError(ContainSubstring("allowed_cidrs")))
+		})
+
+		It("should report the settings failing to apply", func() {
+			m := newMonolith(config(), nil)
+			Expect(m.Start(ctx)).To(Succeed())
+
+			next := config()
+			next.LogLevel = "loud"
+			_, err := m.Reload(next)
+			Expect(err).To(MatchError(ContainSubstring("log_level")))
+		})
+	})
+})
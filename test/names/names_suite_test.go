@@ -0,0 +1,22 @@
+package names_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestNamesSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Names Suite")
+}
+
+var _ = BeforeEach(func() {
+	Expect(InitializeBackend()).To(Succeed())
+})
+
+var _ = AfterEach(func() {
+	Expect(Backend.Close()).To(Succeed())
+})
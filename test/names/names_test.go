@@ -0,0 +1,122 @@
+package names_test
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/names"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func mined(tx *types.Transaction, err error) {
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Commit()
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+}
+
+// setReverse sets the primary name of an address in its reverse record.
+func setReverse(a common.Address, name string) {
+	label := hex.EncodeToString(a.Bytes())
+	node := EnsNode(label + ".addr.reverse")
+	owner, err := ENSRegistry.Owner(nil, EnsNode("addr.reverse"))
+	Expect(err).ToNot(HaveOccurred())
+	if owner != BankAccount.Address() {
+		mined(ENSRegistry.SetSubnodeOwner(BankAccount.TransactOpts(), EnsNode(""), LabelHash("reverse"), BankAccount.Address()))
+		mined(ENSRegistry.SetSubnodeOwner(BankAccount.TransactOpts(), EnsNode("reverse"), LabelHash("addr"), BankAccount.Address()))
+	}
+	mined(ENSRegistry.SetSubnodeOwner(BankAccount.TransactOpts(), EnsNode("addr.reverse"), LabelHash(label), BankAccount.Address()))
+	mined(ENSRegistry.SetResolver(BankAccount.TransactOpts(), node, ENSResolverAddress))
+	mined(ENSResolver.SetName(BankAccount.TransactOpts(), node, name))
+}
+
+var _ = Describe("names", func() {
+
+	var r *names.Resolver
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		var err error
+		r, err = names.NewResolver(ENSRegistryAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("computes the nodes of the names", func() {
+		Expect(names.Namehash("")).To(Equal(common.Hash{}))
+		Expect(names.Namehash("controller.tokencard.eth")).To(Equal(ControllerName))
+		Expect(names.Namehash("Controller.TokenCard.eth")).To(Equal(ControllerName))
+	})
+
+	It("tells the names from the addresses", func() {
+		Expect(names.IsName("alice.eth")).To(BeTrue())
+		Expect(names.IsName(ControllerContractAddress.Hex())).To(BeFalse())
+		Expect(names.IsName("alice")).To(BeFalse())
+		Expect(names.IsName("alice..eth")).To(BeFalse())
+	})
+
+	Describe("Resolve", func() {
+
+		It("resolves a name to its address", func() {
+			a, err := r.Resolve(ctx, "controller.tokencard.eth")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(a).To(Equal(ControllerContractAddress))
+		})
+
+		It("returns a hex address as it is", func() {
+			a, err := r.Resolve(ctx, Owner.Address().Hex())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(a).To(Equal(Owner.Address()))
+		})
+
+		It("fails for a name without a resolver", func() {
+			_, err := r.Resolve(ctx, "alice.eth")
+			Expect(errors.Cause(err)).To(Equal(names.ErrNotFound))
+		})
+
+		It("fails for a name without an address", func() {
+			mined(ENSRegistry.SetSubnodeOwner(BankAccount.TransactOpts(), EnsNode("eth"), LabelHash("alice"), BankAccount.Address()))
+			mined(ENSRegistry.SetResolver(BankAccount.TransactOpts(), EnsNode("alice.eth"), ENSResolverAddress))
+			_, err := r.Resolve(ctx, "alice.eth")
+			Expect(errors.Cause(err)).To(Equal(names.ErrNotFound))
+		})
+
+		It("fails for something else", func() {
+			_, err := r.Resolve(ctx, "alice")
+			Expect(err).To(MatchError(`"alice" is neither an address nor an ENS name`))
+		})
+	})
+
+	Describe("Lookup", func() {
+
+		It("returns the primary name of an address", func() {
+			setReverse(ControllerContractAddress, "controller.tokencard.eth")
+			name, err := r.Lookup(ctx, ControllerContractAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("controller.tokencard.eth"))
+		})
+
+		It("fails for an address without a reverse record", func() {
+			_, err := r.Lookup(ctx, Owner.Address())
+			Expect(errors.Cause(err)).To(Equal(names.ErrNotFound))
+		})
+
+		It("fails for a name not resolving back to the address", func() {
+			setReverse(Owner.Address(), "controller.tokencard.eth")
+			_, err := r.Lookup(ctx, Owner.Address())
+			Expect(errors.Cause(err)).To(Equal(names.ErrNotFound))
+		})
+	})
+
+	It("returns the names of the addresses which have one", func() {
+		setReverse(ControllerContractAddress, "controller.tokencard.eth")
+		n, err := r.Names(ctx, ControllerContractAddress, Owner.Address(), ControllerContractAddress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(map[common.Address]string{ControllerContractAddress: "controller.tokencard.eth"}))
+	})
+})
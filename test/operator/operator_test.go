@@ -0,0 +1,269 @@
+package operator_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/operator"
+	"github.com/tokencard/contracts/v2/pkg/operator/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const config = `{
+  "listen_address": ":8080",
+  "rpc_url": "http://parity-poa:8545",
+  "keystore_file": "/secrets/operator.json",
+  "drift": {"interval": "5m"},
+  "contracts": {"licence": "0x0000000000000000000000000000000000000001"}
+}`
+
+const driftSpec = `licence:
+  amount_scaled: 10
+`
+
+var _ = Describe("Reconciler", func() {
+
+	var ctx context.Context
+	var scheme *runtime.Scheme
+	var c client.Client
+	var r *operator.Reconciler
+	var m *v1alpha1.Monolith
+	name := types.NamespacedName{Namespace: "contracts", Name: "monolithd"}
+
+	reconcileMonolith := func() {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		ExpectWithOffset(1, res).To(Equal(reconcile.Result{}))
+	}
+
+	get := func(obj client.Object, name string) {
+		err := c.Get(ctx, types.NamespacedName{Namespace: "contracts", Name: name}, obj)
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	}
+
+	current := func() *v1alpha1.Monolith {
+		m := &v1alpha1.Monolith{}
+		get(m, name.Name)
+		return m
+	}
+
+	update := func(change func(m *v1alpha1.Monolith)) {
+		m := current()
+		change(m)
+		m.Generation++
+		ExpectWithOffset(1, c.Update(ctx, m)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		replicas := int32(2)
+		m = &v1alpha1.Monolith{
+			ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name, Generation: 1},
+			Spec: v1alpha1.MonolithSpec{
+				Image:      "quay.io/token/monolithd:v1.0.0",
+				Replicas:   &replicas,
+				Config:     config,
+				DriftSpec:  driftSpec,
+				SecretName: "monolithd-keys",
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+		r = &operator.Reconciler{Client: c, Scheme: scheme}
+	})
+
+	When("a monolith is created", func() {
+		BeforeEach(reconcileMonolith)
+
+		It("should mount the configuration files from a configmap", func() {
+			cm := &corev1.ConfigMap{}
+			get(cm, "monolithd-config")
+			Expect(cm.Data).To(HaveKeyWithValue("spec.yaml", driftSpec))
+			Expect(cm.Data).To(HaveKey("monolithd.json"))
+			Expect(cm.Data["monolithd.json"]).To(ContainSubstring(`"spec_file": "/etc/monolith/spec.yaml"`))
+			Expect(cm.Data["monolithd.json"]).To(ContainSubstring(`"interval": "5m"`))
+			Expect(cm.OwnerReferences).To(HaveLen(1))
+			Expect(cm.OwnerReferences[0].Name).To(Equal("monolithd"))
+			Expect(*cm.OwnerReferences[0].Controller).To(BeTrue())
+		})
+
+		It("should run monolithd in a deployment", func() {
+			d := &appsv1.Deployment{}
+			get(d, "monolithd")
+			Expect(*d.Spec.Replicas).To(Equal(int32(2)))
+			Expect(d.OwnerReferences).To(HaveLen(1))
+			Expect(d.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/instance": "monolithd"}))
+			Expect(d.Spec.Template.Annotations).To(HaveKey(operator.ConfigHashAnnotation))
+
+			Expect(d.Spec.Template.Spec.Containers).To(HaveLen(1))
+			container := d.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("quay.io/token/monolithd:v1.0.0"))
+			Expect(container.Args).To(Equal([]string{"-config=/etc/monolith/monolithd.json"}))
+			Expect(container.Ports).To(HaveLen(1))
+			Expect(container.Ports[0].ContainerPort).To(Equal(int32(8080)))
+			Expect(container.EnvFrom).To(HaveLen(1))
+			Expect(container.EnvFrom[0].SecretRef.Name).To(Equal("monolithd-keys"))
+			Expect(container.VolumeMounts).To(ConsistOf(
+				corev1.VolumeMount{Name: "config", MountPath: "/etc/monolith", ReadOnly: true},
+				corev1.VolumeMount{Name: "secrets", MountPath: "/secrets", ReadOnly: true},
+			))
+		})
+
+		It("should report the rollout in progress", func() {
+			m := current()
+			Expect(m.Status.ObservedGeneration).To(Equal(int64(1)))
+			Expect(m.Status.ConfigHash).ToNot(BeEmpty())
+			ready := meta.FindStatusCondition(m.Status.Conditions, operator.ReadyCondition)
+			Expect(ready).ToNot(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("Progressing"))
+			Expect(ready.Message).To(Equal("0 of 2 pods are ready"))
+		})
+
+		It("should not change anything when reconciled again", func() {
+			d := &appsv1.Deployment{}
+			get(d, "monolithd")
+			reconcileMonolith()
+			again := &appsv1.Deployment{}
+			get(again, "monolithd")
+			Expect(again.ResourceVersion).To(Equal(d.ResourceVersion))
+		})
+
+		When("the pods are ready", func() {
+			BeforeEach(func() {
+				d := &appsv1.Deployment{}
+				get(d, "monolithd")
+				d.Status.ObservedGeneration = d.Generation
+				d.Status.UpdatedReplicas = 2
+				d.Status.ReadyReplicas = 2
+				Expect(c.Status().Update(ctx, d)).To(Succeed())
+				reconcileMonolith()
+			})
+
+			It("should report the monolith ready", func() {
+				m := current()
+				Expect(m.Status.ReadyReplicas).To(Equal(int32(2)))
+				ready := meta.FindStatusCondition(m.Status.Conditions, operator.ReadyCondition)
+				Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+				Expect(ready.Reason).To(Equal("RolledOut"))
+			})
+		})
+
+		When("the drift spec changes", func() {
+			var hash string
+
+			BeforeEach(func() {
+				hash = current().Status.ConfigHash
+				update(func(m *v1alpha1.Monolith) {
+					m.Spec.DriftSpec = "licence:\n  amount_scaled: 20\n"
+				})
+				reconcileMonolith()
+			})
+
+			It("should roll the pods out with the new spec", func() {
+				cm := &corev1.ConfigMap{}
+				get(cm, "monolithd-config")
+				Expect(cm.Data["spec.yaml"]).To(ContainSubstring("amount_scaled: 20"))
+
+				d := &appsv1.Deployment{}
+				get(d, "monolithd")
+				Expect(d.Spec.Template.Annotations[operator.ConfigHashAnnotation]).ToNot(Equal(hash))
+				Expect(current().Status.ConfigHash).To(Equal(d.Spec.Template.Annotations[operator.ConfigHashAnnotation]))
+				Expect(current().Status.ObservedGeneration).To(Equal(int64(2)))
+			})
+		})
+
+		When("the config becomes invalid", func() {
+			var d *appsv1.Deployment
+
+			BeforeEach(func() {
+				d = &appsv1.Deployment{}
+				get(d, "monolithd")
+				update(func(m *v1alpha1.Monolith) {
+					m.Spec.Config = strings.Replace(config, `"listen_address": ":8080",`, `"listen_address": ":8080", "max_tx_per_minute": -1,`, 1)
+				})
+				reconcileMonolith()
+			})
+
+			It("should report the invalid spec", func() {
+				m := current()
+				Expect(m.Status.ObservedGeneration).To(Equal(int64(2)))
+				ready := meta.FindStatusCondition(m.Status.Conditions, operator.ReadyCondition)
+				Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+				Expect(ready.Reason).To(Equal("InvalidSpec"))
+				Expect(ready.Message).To(Equal("config: max_tx_per_minute must not be negative"))
+			})
+
+			It("should leave the running pods as they are", func() {
+				again := &appsv1.Deployment{}
+				get(again, "monolithd")
+				Expect(again.ResourceVersion).To(Equal(d.ResourceVersion))
+			})
+		})
+	})
+
+	When("the config has no rpc_url", func() {
+		BeforeEach(func() {
+			update(func(m *v1alpha1.Monolith) {
+				m.Spec.Config = `{"listen_address": ":8080"}`
+			})
+			reconcileMonolith()
+		})
+
+		It("should not create the deployment", func() {
+			err := c.Get(ctx, name, &appsv1.Deployment{})
+			Expect(err).To(HaveOccurred())
+			ready := meta.FindStatusCondition(current().Status.Conditions, operator.ReadyCondition)
+			Expect(ready.Reason).To(Equal("InvalidSpec"))
+			Expect(ready.Message).To(Equal("rpc_url is not set in the config"))
+		})
+	})
+
+	When("the drift spec is invalid", func() {
+		It("should be reported", func() {
+			update(func(m *v1alpha1.Monolith) {
+				m.Spec.DriftSpec = "licence: ["
+			})
+			reconcileMonolith()
+			ready := meta.FindStatusCondition(current().Status.Conditions, operator.ReadyCondition)
+			Expect(ready.Reason).To(Equal("InvalidSpec"))
+			Expect(ready.Message).To(HavePrefix("driftSpec: "))
+		})
+	})
+
+	When("the monolith was deleted", func() {
+		It("should succeed without doing anything", func() {
+			Expect(c.Delete(ctx, m)).To(Succeed())
+			reconcileMonolith()
+			err := c.Get(ctx, name, &appsv1.Deployment{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should expose the gRPC port when it is configured", func() {
+		update(func(m *v1alpha1.Monolith) {
+			m.Spec.Config = strings.Replace(config, `"listen_address": ":8080",`, `"listen_address": ":8080", "grpc": {"listen_address": ":9090"},`, 1)
+		})
+		reconcileMonolith()
+		d := &appsv1.Deployment{}
+		get(d, "monolithd")
+		Expect(d.Spec.Template.Spec.Containers[0].Ports).To(ConsistOf(
+			corev1.ContainerPort{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+			corev1.ContainerPort{Name: "grpc", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+		))
+	})
+})
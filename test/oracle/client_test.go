@@ -0,0 +1,111 @@
+package oracle_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+var _ = Describe("OracleClient", func() {
+
+	var client *bindings.OracleClient
+	var ctx context.Context
+	var tkn common.Address
+	var updated time.Time
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewOracleClient(OracleAddress, TokenWhitelistAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+
+		tkn = common.HexToAddress("0x1")
+		tx, err := TokenWhitelist.AddTokens(
+			ControllerAdmin.TransactOpts(),
+			[]common.Address{tkn},
+			StringsToByte32("TKN"),
+			[]*big.Int{DecimalsToMagnitude(big.NewInt(8))},
+			[]bool{true},
+			[]bool{true},
+			big.NewInt(0),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+
+		rate, err := bindings.ParseRate("0.00001633")
+		Expect(err).ToNot(HaveOccurred())
+		updated = time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+		tx, err = client.SetRate(ControllerAdmin.TransactOpts(), tkn, rate, updated)
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+	})
+
+	It("should return the rate of a token", func() {
+		r, err := client.Rate(ctx, tkn)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Symbol).To(Equal("TKN"))
+		Expect(r.Rate.String()).To(Equal("16330000000000"))
+		Expect(r.EtherPerToken()).To(Equal("0.000016330000000000"))
+		Expect(r.LastUpdate).To(Equal(updated))
+		Expect(r.Decimals()).To(Equal(8))
+	})
+
+	It("should convert between token base units and wei", func() {
+		r, err := client.Rate(ctx, tkn)
+		Expect(err).ToNot(HaveOccurred())
+		// 1.5 TKN
+		Expect(r.ToEther(big.NewInt(150000000)).String()).To(Equal("24495000000000"))
+		Expect(r.FromEther(big.NewInt(24495000000000)).String()).To(Equal("150000000"))
+	})
+
+	It("should return the rates of all the tokens", func() {
+		rates, err := client.Rates(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rates).To(HaveLen(1))
+		Expect(rates[0].Token).To(Equal(tkn))
+	})
+
+	It("should fail for a token which is not whitelisted", func() {
+		_, err := client.Rate(ctx, common.HexToAddress("0x2"))
+		Expect(err).To(MatchError(ContainSubstring(bindings.ErrTokenNotAvailable.Error())))
+	})
+
+	It("should request the update of the listed tokens", func() {
+		tx, err := client.UpdateRates(Controller.TransactOpts(ethertest.WithValue(big.NewInt(100000000))), gasLimit, tkn)
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeTrue())
+
+		it, err := Oracle.FilterRequestedUpdate(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(it.Next()).To(BeTrue())
+		Expect(it.Event.Symbol).To(Equal("TKN"))
+		Expect(it.Next()).To(BeFalse())
+	})
+})
+
+var _ = Describe("ParseRate", func() {
+
+	It("should parse a rate in ether per token", func() {
+		Expect(bindings.ParseRate("1.5")).To(Equal(big.NewInt(1500000000000000000)))
+	})
+
+	It("should reject more than 18 decimals", func() {
+		_, err := bindings.ParseRate("0.0000000000000000001")
+		Expect(err).To(MatchError(ContainSubstring("more than 18 decimals")))
+	})
+
+	It("should reject a negative rate", func() {
+		_, err := bindings.ParseRate("-1")
+		Expect(err).To(MatchError(`"-1" is not a valid rate`))
+	})
+})
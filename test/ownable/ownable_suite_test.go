@@ -0,0 +1,22 @@
+package ownable_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestOwnableSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ownable Suite")
+}
+
+var _ = BeforeEach(func() {
+	Expect(InitializeBackend()).To(Succeed())
+})
+
+var _ = AfterEach(func() {
+	Expect(Backend.Close()).To(Succeed())
+})
@@ -0,0 +1,167 @@
+package ownable_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/ownable"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func mined(tx *types.Transaction) {
+	Backend.Commit()
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+}
+
+var _ = Describe("ownable", func() {
+
+	var controller ownable.Contract
+	var wallet ownable.Contract
+	var walletAddress common.Address
+
+	BeforeEach(func() {
+		var err error
+		controller, err = ownable.Bind("controller", ControllerContractAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+
+		var tx *types.Transaction
+		walletAddress, tx, _, err = bindings.DeployWallet(BankAccount.TransactOpts(), Backend, Owner.Address(), true, ENSRegistryAddress, TokenWhitelistName, ControllerName, LicenceName, EthToWei(100))
+		Expect(err).ToNot(HaveOccurred())
+		mined(tx)
+		wallet, err = ownable.Bind("wallet", walletAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	inspect := func(c ownable.Contract) ownable.State {
+		s, err := ownable.Inspect(nil, c)
+		Expect(err).ToNot(HaveOccurred())
+		return s
+	}
+
+	It("should only bind the ownable contracts", func() {
+		_, err := ownable.Bind("licence", LicenceAddress, Backend)
+		Expect(err).To(MatchError(ContainSubstring("not ownable")))
+	})
+
+	It("should inspect the ownership", func() {
+		Expect(inspect(controller)).To(Equal(ownable.State{Owner: ControllerOwner.Address(), Transferable: false}))
+		Expect(inspect(wallet)).To(Equal(ownable.State{Owner: Owner.Address(), Transferable: true}))
+	})
+
+	Describe("Transfer", func() {
+
+		It("should transfer the ownership", func() {
+			tx, err := ownable.Transfer(Owner.TransactOpts(), wallet, RandomAccount.Address(), true)
+			Expect(err).ToNot(HaveOccurred())
+			mined(tx)
+			Expect(inspect(wallet)).To(Equal(ownable.State{Owner: RandomAccount.Address(), Transferable: true}))
+		})
+
+		It("should lock the ownership", func() {
+			tx, err := ownable.Transfer(Owner.TransactOpts(), wallet, RandomAccount.Address(), false)
+			Expect(err).ToNot(HaveOccurred())
+			mined(tx)
+			Expect(inspect(wallet).Transferable).To(BeFalse())
+
+			_, err = ownable.Transfer(RandomAccount.TransactOpts(), wallet, Owner.Address(), true)
+			Expect(errors.Cause(err)).To(Equal(ownable.ErrNotTransferable))
+		})
+
+		It("should refuse a locked ownership", func() {
+			_, err := ownable.Transfer(ControllerOwner.TransactOpts(), controller, Owner.Address(), true)
+			Expect(errors.Cause(err)).To(Equal(ownable.ErrNotTransferable))
+		})
+
+		It("should refuse a sender which is not the owner", func() {
+			_, err := ownable.Transfer(RandomAccount.TransactOpts(), wallet, RandomAccount.Address(), true)
+			Expect(errors.Cause(err)).To(Equal(ownable.ErrNotOwner))
+		})
+
+		It("should refuse the zero address", func() {
+			_, err := ownable.Transfer(Owner.TransactOpts(), wallet, common.Address{}, true)
+			Expect(err).To(Equal(ownable.ErrZeroAddress))
+		})
+	})
+
+	Describe("Renounce", func() {
+
+		It("should leave the contract without an owner", func() {
+			tx, err := ownable.Renounce(Owner.TransactOpts(), wallet)
+			Expect(err).ToNot(HaveOccurred())
+			mined(tx)
+			Expect(inspect(wallet).Renounced()).To(BeTrue())
+		})
+
+		It("should refuse a sender which is not the owner", func() {
+			_, err := ownable.Renounce(RandomAccount.TransactOpts(), wallet)
+			Expect(errors.Cause(err)).To(Equal(ownable.ErrNotOwner))
+		})
+
+		It("should refuse a locked ownership", func() {
+			_, err := ownable.Renounce(ControllerOwner.TransactOpts(), controller)
+			Expect(errors.Cause(err)).To(Equal(ownable.ErrNotTransferable))
+		})
+	})
+
+	Describe("Audit", func() {
+
+		var contracts map[string]common.Address
+
+		BeforeEach(func() {
+			contracts = map[string]common.Address{
+				"controller": ControllerContractAddress,
+				"licence":    LicenceAddress,
+				"wallet":     walletAddress,
+			}
+		})
+
+		audit := func(p ownable.Policy) []ownable.Entry {
+			entries := ownable.Audit(context.Background(), Backend, contracts, p)
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Name).To(Equal("controller"))
+			Expect(entries[1].Name).To(Equal("wallet"))
+			return entries
+		}
+
+		It("should pass the contracts owned by the expected owners", func() {
+			entries := audit(ownable.Policy{Owners: []common.Address{ControllerOwner.Address(), Owner.Address()}})
+			Expect(entries[0].Owner).To(Equal(ControllerOwner.Address()))
+			Expect(entries[0].OK()).To(BeTrue())
+			Expect(entries[1].OK()).To(BeTrue())
+		})
+
+		It("should report an unexpected owner", func() {
+			entries := audit(ownable.Policy{Owners: []common.Address{Owner.Address()}})
+			Expect(entries[0].Problems).To(ConsistOf(ContainSubstring("is not expected")))
+			Expect(entries[1].OK()).To(BeTrue())
+		})
+
+		It("should report a transferable ownership", func() {
+			entries := audit(ownable.Policy{Locked: true})
+			Expect(entries[0].OK()).To(BeTrue())
+			Expect(entries[1].Problems).To(ConsistOf("ownership is transferable"))
+		})
+
+		It("should report a renounced ownership", func() {
+			tx, err := ownable.Renounce(Owner.TransactOpts(), wallet)
+			Expect(err).ToNot(HaveOccurred())
+			mined(tx)
+			entries := audit(ownable.Policy{})
+			Expect(entries[1].Problems).To(ConsistOf("ownership is renounced"))
+		})
+
+		It("should report the contracts which cannot be read", func() {
+			contracts["wallet"] = common.HexToAddress("0x01")
+			entries := audit(ownable.Policy{})
+			Expect(entries[1].Err).ToNot(BeEmpty())
+			Expect(entries[1].OK()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package proof_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestProofSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Proof Suite")
+}
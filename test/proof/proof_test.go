@@ -0,0 +1,178 @@
+package proof_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/proof"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+// chain reads the blocks of the simulated chain like a node.
+type chain struct {
+	*testutil.Chain
+	// hidden are the receipts the node does not return.
+	hidden map[common.Hash]bool
+}
+
+func (c *chain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if c.hidden[txHash] {
+		return nil, errors.New("not found")
+	}
+	return c.Chain.TransactionReceipt(ctx, txHash)
+}
+
+func (c *chain) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	b := c.Simulated.Blockchain().GetBlockByHash(hash)
+	if b == nil {
+		return nil, errors.New("not found")
+	}
+	return b, nil
+}
+
+func (c *chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	h := c.Simulated.Blockchain().GetHeaderByNumber(number.Uint64())
+	if h == nil {
+		return nil, errors.New("not found")
+	}
+	return h, nil
+}
+
+var _ = Describe("Bundle", func() {
+
+	var (
+		ctx      = context.Background()
+		c        *chain
+		payouts  []common.Hash
+		auditKey *ecdsa.PrivateKey
+		auditor  common.Address
+	)
+
+	BeforeEach(func() {
+		tc, err := testutil.New(testutil.Config{Accounts: 3})
+		Expect(err).ToNot(HaveOccurred())
+		c = &chain{Chain: tc, hidden: make(map[common.Hash]bool)}
+		auditKey, err = crypto.GenerateKey()
+		Expect(err).ToNot(HaveOccurred())
+		auditor = crypto.PubkeyToAddress(auditKey.PublicKey)
+
+		// Several transactions in a block, proven in a trie of more than
+		// one node.
+		var txs []*types.Transaction
+		for _, a := range c.Accounts {
+			tx, err := c.TKNSession(a).Transfer(c.Deployer.Address, big.NewInt(10))
+			Expect(err).ToNot(HaveOccurred())
+			txs = append(txs, tx)
+		}
+		_, err = c.Mined(txs...)
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := c.TKNSession(c.Deployer).Transfer(c.Accounts[0].Address, big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = c.Mined(tx)
+		Expect(err).ToNot(HaveOccurred())
+
+		payouts = []common.Hash{txs[0].Hash(), txs[2].Hash(), tx.Hash()}
+	})
+
+	AfterEach(func() {
+		Expect(c.Close()).To(Succeed())
+	})
+
+	gather := func() *proof.Bundle {
+		b, err := proof.Gather(ctx, c, payouts...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Sign(signing.KeySigner(auditKey))).To(Succeed())
+		return b
+	}
+
+	It("proves the receipts of the transactions in their blocks", func() {
+		b := gather()
+		Expect(b.Entries).To(HaveLen(3))
+		for i, e := range b.Entries {
+			Expect(e.TxHash).To(Equal(payouts[i]))
+			Expect(e.ProofErr).To(BeEmpty())
+			r, err := e.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+			Expect(r.Logs).To(HaveLen(1))
+		}
+		Expect(b.Entries[0].BlockHash).To(Equal(b.Entries[1].BlockHash))
+		Expect(b.Entries[1].Index).To(Equal(uint(2)))
+
+		Expect(b.Verify(auditor)).To(Succeed())
+		Expect(b.CheckCanonical(ctx, c)).To(Succeed())
+	})
+
+	It("survives its JSON encoding", func() {
+		data, err := json.Marshal(gather())
+		Expect(err).ToNot(HaveOccurred())
+		b := &proof.Bundle{}
+		Expect(json.Unmarshal(data, b)).To(Succeed())
+		Expect(b.Verify(auditor)).To(Succeed())
+	})
+
+	It("rejects a bundle signed by another key", func() {
+		err := gather().Verify(c.Deployer.Address)
+		Expect(errors.Cause(err)).To(Equal(signing.ErrInvalidSignature))
+	})
+
+	It("rejects a bundle changed after it was signed", func() {
+		b := gather()
+		b.Entries = b.Entries[1:]
+		err := b.Verify(auditor)
+		Expect(errors.Cause(err)).To(Equal(signing.ErrInvalidSignature))
+	})
+
+	It("rejects a receipt not in its block", func() {
+		b := gather()
+		b.Entries[0].Receipt = b.Entries[1].Receipt
+		_, err := b.Entries[0].Verify()
+		Expect(errors.Cause(err)).To(Equal(proof.ErrInvalidProof))
+	})
+
+	It("rejects a header not of its block", func() {
+		b := gather()
+		b.Entries[0].Header = b.Entries[2].Header
+		_, err := b.Entries[0].Verify()
+		Expect(errors.Cause(err)).To(Equal(proof.ErrInvalidProof))
+	})
+
+	It("leaves out the proofs when the receipts of the block cannot be read", func() {
+		block, err := c.BlockByHash(ctx, gather().Entries[0].BlockHash)
+		Expect(err).ToNot(HaveOccurred())
+		c.hidden[block.Transactions()[1].Hash()] = true
+
+		b := gather()
+		Expect(b.Entries[0].Proof).To(BeEmpty())
+		Expect(b.Entries[0].ProofErr).To(ContainSubstring("getting receipt"))
+		Expect(b.Entries[1].Proof).To(BeEmpty())
+		Expect(b.Entries[1].ProofErr).ToNot(BeEmpty())
+		Expect(b.Entries[2].ProofErr).To(BeEmpty())
+
+		_, err = b.Entries[0].Verify()
+		Expect(errors.Cause(err)).To(Equal(proof.ErrInvalidProof))
+		_, err = b.Entries[2].Verify()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("rejects the blocks of another chain", func() {
+		b := gather()
+		other, err := testutil.New(testutil.Config{Accounts: 3})
+		Expect(err).ToNot(HaveOccurred())
+		defer other.Close()
+		for i := 0; i < 3; i++ {
+			other.Commit()
+		}
+		err = b.CheckCanonical(ctx, &chain{Chain: other})
+		Expect(errors.Cause(err)).To(Equal(proof.ErrInvalidProof))
+	})
+})
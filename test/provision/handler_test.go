@@ -0,0 +1,61 @@
+package provision_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Handler", func() {
+
+	var handler http.Handler
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	BeforeEach(func() {
+		p, err := provision.New(WalletDeployerAddress, WalletCacheAddress, Chain, Chain, Controller.TransactOpts(), provision.NewMemoryAssignments())
+		Expect(err).ToNot(HaveOccurred())
+		p.Target = 5
+		handler = provision.NewHandler(p)
+	})
+
+	It("should assign a wallet", func() {
+		rec := serve(http.MethodPost, "/wallets", `{"owner":"`+Owner.Address().Hex()+`"}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var a provision.Assignment
+		Expect(json.Unmarshal(rec.Body.Bytes(), &a)).To(Succeed())
+		Expect(a.Owner).To(Equal(Owner.Address()))
+
+		deployed, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.Wallet).To(Equal(deployed))
+	})
+
+	It("should reject the zero owner", func() {
+		rec := serve(http.MethodPost, "/wallets", `{}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		rec = serve(http.MethodPost, "/wallets", `{`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should return the cached wallets", func() {
+		rec := serve(http.MethodGet, "/wallets", "")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(MatchJSON(`{"cached":0,"target":5}`))
+	})
+
+	It("should not serve the other paths", func() {
+		Expect(serve(http.MethodGet, "/wallets/x", "").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(http.MethodDelete, "/wallets", "").Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
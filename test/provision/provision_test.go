@@ -0,0 +1,200 @@
+package provision_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Provisioner", func() {
+
+	var p *provision.Provisioner
+	var assignments *provision.MemoryAssignments
+	ctx := context.Background()
+
+	cached := func() int {
+		n, err := p.Cached(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		return n
+	}
+
+	BeforeEach(func() {
+		assignments = provision.NewMemoryAssignments()
+		var err error
+		p, err = provision.New(WalletDeployerAddress, WalletCacheAddress, Chain, Chain, Controller.TransactOpts(), assignments)
+		Expect(err).ToNot(HaveOccurred())
+		p.Target = 3
+		p.BatchSize = 2
+	})
+
+	It("should cache the wallets in batches up to the target", func() {
+		n, err := p.Refill(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(cached()).To(Equal(2))
+
+		n, err = p.Refill(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(cached()).To(Equal(3))
+
+		n, err = p.Refill(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(BeZero())
+	})
+
+	It("should cache the wallets requested in chunks of the batch size", func() {
+		wallets, err := p.CacheWallets(ctx, 5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wallets).To(HaveLen(5))
+		Expect(cached()).To(Equal(5))
+		for i, w := range wallets {
+			cached, err := WalletCache.CachedWallets(nil, big.NewInt(int64(i)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cached).To(Equal(w))
+		}
+	})
+
+	It("should report the wallets cached before a failure", func() {
+		p, err := provision.New(WalletDeployerAddress, WalletCacheAddress, &FailingChain{TestChain: Chain, Left: 3}, Chain, Controller.TransactOpts(), assignments)
+		Expect(err).ToNot(HaveOccurred())
+		p.BatchSize = 2
+		wallets, err := p.CacheWallets(ctx, 5)
+		Expect(wallets).To(HaveLen(3))
+		batch, ok := err.(*provision.BatchError)
+		Expect(ok).To(BeTrue())
+		Expect(batch.Cached).To(Equal(wallets))
+		Expect(batch.Missing).To(Equal(2))
+		Expect(errors.Cause(err)).To(MatchError(ContainSubstring("node down")))
+		Expect(cached()).To(Equal(3))
+	})
+
+	When("wallets are cached", func() {
+
+		BeforeEach(func() {
+			_, err := p.Refill(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should assign a cached wallet to its owner", func() {
+			a, err := p.AssignWallet(ctx, Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(a.Owner).To(Equal(Owner.Address()))
+			Expect(a.TxHash).ToNot(Equal(common.Hash{}))
+			Expect(cached()).To(Equal(1))
+
+			deployed, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(a.Wallet).To(Equal(deployed))
+
+			w, err := bindings.NewWallet(a.Wallet, Backend)
+			Expect(err).ToNot(HaveOccurred())
+			owner, err := w.Owner(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal(Owner.Address()))
+
+			recorded, ok, err := assignments.Get(Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(recorded).To(Equal(a))
+		})
+
+		It("should return the same wallet to the same owner", func() {
+			a, err := p.AssignWallet(ctx, Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			again, err := p.AssignWallet(ctx, Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(again).To(Equal(a))
+			Expect(cached()).To(Equal(1))
+		})
+	})
+
+	It("should assign a new wallet when none is cached", func() {
+		a, err := p.AssignWallet(ctx, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.Wallet).ToNot(Equal(common.Address{}))
+		Expect(cached()).To(BeZero())
+	})
+
+	It("should record the wallets assigned by other means", func() {
+		_, err := WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		deployed, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+
+		a, err := p.AssignWallet(ctx, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(a.Wallet).To(Equal(deployed))
+		Expect(a.TxHash).To(Equal(common.Hash{}))
+		_, ok, err := assignments.Get(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should not assign a wallet to the zero address", func() {
+		_, err := p.AssignWallet(ctx, common.Address{})
+		Expect(errors.Cause(err)).To(Equal(provision.ErrZeroOwner))
+	})
+
+	It("should fail without the controller role", func() {
+		p, err := provision.New(WalletDeployerAddress, WalletCacheAddress, Chain, Chain, RandomAccount.TransactOpts(), assignments)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.AssignWallet(ctx, Owner.Address())
+		Expect(err).To(HaveOccurred())
+		_, ok, err := assignments.Get(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("FileAssignments", func() {
+
+	var dir, path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "provision")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "wallets.jsonl")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should read back the assignments", func() {
+		f, err := provision.OpenFileAssignments(path)
+		Expect(err).ToNot(HaveOccurred())
+		a := provision.Assignment{Owner: Owner.Address(), Wallet: RandomAccount.Address(), BlockNumber: 7}
+		Expect(f.Record(a)).To(Succeed())
+
+		f, err = provision.OpenFileAssignments(path)
+		Expect(err).ToNot(HaveOccurred())
+		got, ok, err := f.Get(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(got.Wallet).To(Equal(a.Wallet))
+		Expect(got.BlockNumber).To(Equal(uint64(7)))
+
+		_, ok, err = f.Get(RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should fail on a corrupted file", func() {
+		Expect(ioutil.WriteFile(path, []byte("{\n"), 0600)).To(Succeed())
+		_, err := provision.OpenFileAssignments(path)
+		Expect(err).To(MatchError(ContainSubstring("line 1")))
+	})
+})
@@ -0,0 +1,82 @@
+package provision_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/provision"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Scheduler", func() {
+
+	var p *provision.Provisioner
+	var s *provision.Scheduler
+	var owners []common.Address
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		var err error
+		p, err = provision.New(WalletDeployerAddress, WalletCacheAddress, Chain, Chain, Controller.TransactOpts(), provision.NewMemoryAssignments())
+		Expect(err).ToNot(HaveOccurred())
+		owners = []common.Address{Owner.Address(), RandomAccount.Address(), BankAccount.Address()}
+		s = provision.NewScheduler(p, provision.SourceFunc(func(ctx context.Context) ([]common.Address, error) {
+			return owners, nil
+		}))
+		s.BatchSize = 2
+	})
+
+	It("should assign the wallets of the owners in batches", func() {
+		assigned, err := s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(HaveLen(2))
+		Expect(assigned[0].Owner).To(Equal(Owner.Address()))
+		Expect(assigned[1].Owner).To(Equal(RandomAccount.Address()))
+
+		assigned, err = s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(HaveLen(1))
+		Expect(assigned[0].Owner).To(Equal(BankAccount.Address()))
+
+		assigned, err = s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(BeEmpty())
+	})
+
+	It("should skip the owners not eligible yet", func() {
+		eligible := map[common.Address]bool{RandomAccount.Address(): true}
+		s.Eligible = func(ctx context.Context, owner common.Address) (bool, error) {
+			return eligible[owner], nil
+		}
+		assigned, err := s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(HaveLen(1))
+		Expect(assigned[0].Owner).To(Equal(RandomAccount.Address()))
+
+		eligible[Owner.Address()] = true
+		assigned, err = s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(HaveLen(1))
+		Expect(assigned[0].Owner).To(Equal(Owner.Address()))
+	})
+
+	It("should not submit again the owners assigned by a previous run", func() {
+		_, err := s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		// A new provisioner has not recorded the assignments, it finds them
+		// on the chain.
+		p, err := provision.New(WalletDeployerAddress, WalletCacheAddress, Chain, Chain, Controller.TransactOpts(), provision.NewMemoryAssignments())
+		Expect(err).ToNot(HaveOccurred())
+		s = provision.NewScheduler(p, provision.SourceFunc(func(ctx context.Context) ([]common.Address, error) {
+			return owners[:2], nil
+		}))
+		assigned, err := s.Tick(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(assigned).To(HaveLen(2))
+		Expect(assigned[0].TxHash).To(Equal(common.Hash{}))
+		Expect(assigned[1].TxHash).To(Equal(common.Hash{}))
+	})
+})
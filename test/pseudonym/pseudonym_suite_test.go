@@ -0,0 +1,13 @@
+package pseudonym_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPseudonymSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pseudonym Suite")
+}
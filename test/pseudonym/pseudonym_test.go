@@ -0,0 +1,78 @@
+package pseudonym_test
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/pseudonym"
+)
+
+var _ = Describe("Key", func() {
+
+	var key *pseudonym.Key
+	wallet := common.HexToAddress("0xa")
+
+	BeforeEach(func() {
+		var err error
+		key, err = pseudonym.ParseKey("0x" + strings.Repeat("ab", 32))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("replaces an address by the same pseudonym in every form", func() {
+		p := key.Address(wallet)
+		Expect(p).ToNot(Equal(wallet))
+		Expect(key.Value(wallet)).To(Equal(p))
+		Expect(key.Value(wallet.Hex())).To(Equal(p.Hex()))
+		Expect(key.Value([]common.Address{wallet})).To(Equal([]common.Address{p}))
+	})
+
+	It("derives other pseudonyms with another key", func() {
+		other, err := pseudonym.ParseKey(strings.Repeat("cd", 32))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Address(wallet)).ToNot(Equal(key.Address(wallet)))
+	})
+
+	It("keeps the values which are not addresses", func() {
+		Expect(key.Value(big.NewInt(10))).To(Equal(big.NewInt(10)))
+		Expect(key.Value("10")).To(Equal("10"))
+		Expect(key.Value(common.Address{})).To(Equal(common.Address{}))
+	})
+
+	It("replaces the addresses and the transaction hash of an event", func() {
+		e := indexer.Event{
+			Contract: "licence",
+			Address:  common.HexToAddress("0x10"),
+			TxHash:   common.HexToHash("0x1"),
+			Args:     map[string]interface{}{"_from": wallet, "_amount": big.NewInt(10)},
+		}
+		p := key.Event(e)
+		Expect(p.Address).To(Equal(e.Address))
+		Expect(p.TxHash).ToNot(Equal(e.TxHash))
+		Expect(p.Args["_from"]).To(Equal(key.Address(wallet)))
+		Expect(p.Args["_amount"]).To(Equal(big.NewInt(10)))
+		Expect(e.Args["_from"]).To(Equal(wallet))
+	})
+
+	It("reidentifies the pseudonyms of the indexed addresses", func() {
+		index := key.NewIndex()
+		index.AddValue(map[string]interface{}{"_from": wallet.Hex(), "_to": []interface{}{common.HexToAddress("0xb").Hex()}})
+		a, ok := index.Reidentify(key.Address(wallet))
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal(wallet))
+		a, ok = index.Reidentify(key.Address(common.HexToAddress("0xb")))
+		Expect(ok).To(BeTrue())
+		Expect(a).To(Equal(common.HexToAddress("0xb")))
+		_, ok = index.Reidentify(wallet)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects the short keys", func() {
+		_, err := pseudonym.ParseKey("abcd")
+		Expect(err).To(MatchError(ContainSubstring("at least 32 bytes")))
+	})
+})
@@ -0,0 +1,13 @@
+package readonly_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestReadonlySuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Readonly Suite")
+}
@@ -0,0 +1,144 @@
+package readonly_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/readonly"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Guard", func() {
+
+	var guard *readonly.Guard
+	var available int32
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&available, 1)
+		guard = &readonly.Guard{
+			Dependencies: []readonly.Dependency{{
+				Name: "signer",
+				Check: func(ctx context.Context) error {
+					if atomic.LoadInt32(&available) == 0 {
+						return errors.New("kms unreachable")
+					}
+					return nil
+				},
+			}},
+			Interval: 10 * time.Millisecond,
+		}
+	})
+
+	It("should be writable while the dependencies are available", func() {
+		Expect(guard.Check(context.Background())).To(Equal(readonly.Status{Reasons: []readonly.Reason{}}))
+		Expect(guard.ReadOnly()).To(BeFalse())
+		Expect(guard.Err()).ToNot(HaveOccurred())
+	})
+
+	It("should enter the read-only mode while a dependency is unavailable", func() {
+		atomic.StoreInt32(&available, 0)
+		s := guard.Check(context.Background())
+		Expect(s.ReadOnly).To(BeTrue())
+		Expect(s.Reasons).To(HaveLen(1))
+		Expect(s.Reasons[0].Dependency).To(Equal("signer"))
+		Expect(s.Reasons[0].Error).To(Equal("kms unreachable"))
+		Expect(errors.Cause(guard.Err())).To(Equal(readonly.ErrReadOnly))
+		Expect(guard.Err()).To(MatchError("signer: kms unreachable: read-only mode"))
+	})
+
+	It("should keep the time a dependency became unavailable", func() {
+		guard.Fail("signer", errors.New("locked"))
+		since := guard.Status().Reasons[0].Since
+		guard.Fail("signer", errors.New("still locked"))
+		Expect(guard.Status().Reasons).To(Equal([]readonly.Reason{{Dependency: "signer", Error: "still locked", Since: since}}))
+	})
+
+	It("should stay read-only until every dependency is available", func() {
+		guard.Fail("signer", errors.New("locked"))
+		guard.Fail("policy", errors.New("down"))
+		Expect(guard.Status().Reasons[0].Dependency).To(Equal("policy"))
+
+		guard.Pass("signer")
+		Expect(guard.ReadOnly()).To(BeTrue())
+		guard.Pass("policy")
+		Expect(guard.ReadOnly()).To(BeFalse())
+	})
+
+	It("should recover when the dependencies are available again", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		atomic.StoreInt32(&available, 0)
+		go guard.Run(ctx)
+		Eventually(guard.ReadOnly).Should(BeTrue())
+
+		atomic.StoreInt32(&available, 1)
+		Eventually(guard.ReadOnly).Should(BeFalse())
+	})
+
+	Describe("Handler", func() {
+
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.Handle("/read-only", guard.StatusHandler())
+			mux.Handle("/", guard.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should reject the state changing requests with the reasons in read-only mode", func() {
+			res, err := http.Post(server.URL+"/licence/amount", "application/json", strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+			guard.Fail("signer", errors.New("kms unreachable"))
+			res, err = http.Post(server.URL+"/licence/amount", "application/json", strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(res.Header.Get("Retry-After")).To(Equal("1"))
+
+			var body struct {
+				Error   string
+				Reasons []readonly.Reason
+			}
+			Expect(json.NewDecoder(res.Body).Decode(&body)).To(Succeed())
+			Expect(body.Error).To(Equal("signer: kms unreachable: read-only mode"))
+			Expect(body.Reasons).To(HaveLen(1))
+		})
+
+		It("should serve the reads in read-only mode", func() {
+			guard.Fail("signer", errors.New("kms unreachable"))
+			res, err := http.Get(server.URL + "/licence")
+			Expect(err).ToNot(HaveOccurred())
+			res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should serve the status of the guard", func() {
+			guard.Fail("signer", errors.New("kms unreachable"))
+			res, err := http.Get(server.URL + "/read-only")
+			Expect(err).ToNot(HaveOccurred())
+			defer res.Body.Close()
+			var s readonly.Status
+			Expect(json.NewDecoder(res.Body).Decode(&s)).To(Succeed())
+			Expect(s.ReadOnly).To(BeTrue())
+			Expect(s.Reasons[0].Error).To(Equal("kms unreachable"))
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package reconcile_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/reconcile"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Detector", func() {
+
+	var detector *reconcile.Detector
+	var drifts []reconcile.Drift
+
+	setAdmin := func(add bool) {
+		var tx *types.Transaction
+		var err error
+		if add {
+			tx, err = ControllerContract.AddAdmin(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		} else {
+			tx, err = ControllerContract.RemoveAdmin(ControllerOwner.TransactOpts(), RandomAccount.Address())
+		}
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+	}
+
+	isAdmin := func() bool {
+		ok, err := ControllerContract.IsAdmin(nil, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		return ok
+	}
+
+	BeforeEach(func() {
+		r, err := reconcile.New(Backend, reconcile.Contracts{Controller: ControllerContractAddress})
+		Expect(err).ToNot(HaveOccurred())
+
+		drifts = nil
+		detector = &reconcile.Detector{
+			Reconciler: r,
+			Spec:       loadSpec(fmt.Sprintf("controller:\n  remove_admins: [\"%s\"]\n", RandomAccount.Address().Hex())),
+			Alerter: reconcile.AlerterFunc(func(ctx context.Context, d reconcile.Drift) error {
+				drifts = append(drifts, d)
+				return nil
+			}),
+		}
+		Expect(detector.Check(context.Background())).To(Succeed())
+	})
+
+	It("should not alert when the contracts match the spec", func() {
+		Expect(drifts).To(BeEmpty())
+	})
+
+	When("the contracts drift from the spec", func() {
+
+		BeforeEach(func() {
+			setAdmin(true)
+			Expect(detector.Check(context.Background())).To(Succeed())
+		})
+
+		It("should alert once", func() {
+			Expect(drifts).To(HaveLen(1))
+			Expect(drifts[0].Actions).To(HaveLen(1))
+			Expect(drifts[0].Actions[0].Method).To(Equal("removeAdmin"))
+		})
+
+		It("should not alert again for the same drift", func() {
+			Expect(detector.Check(context.Background())).To(Succeed())
+			Expect(drifts).To(HaveLen(1))
+		})
+
+		When("the spec is replaced to accept the change", func() {
+
+			BeforeEach(func() {
+				detector.SetSpec(loadSpec(fmt.Sprintf("controller:\n  admins: [\"%s\"]\n", RandomAccount.Address().Hex())))
+				Expect(detector.Check(context.Background())).To(Succeed())
+			})
+
+			It("should alert that the contracts match again", func() {
+				Expect(drifts).To(HaveLen(2))
+				Expect(drifts[1].Resolved()).To(BeTrue())
+			})
+		})
+
+		When("the drift is resolved", func() {
+
+			BeforeEach(func() {
+				setAdmin(false)
+				Expect(detector.Check(context.Background())).To(Succeed())
+			})
+
+			It("should alert that the contracts match again", func() {
+				Expect(drifts).To(HaveLen(2))
+				Expect(drifts[1].Resolved()).To(BeTrue())
+			})
+		})
+	})
+
+	When("the detector remediates the drifts", func() {
+
+		BeforeEach(func() {
+			detector.Remediate = ControllerOwner.TransactOpts()
+			setAdmin(true)
+			Expect(detector.Check(context.Background())).To(Succeed())
+		})
+
+		It("should send the transactions of the actions along the alert", func() {
+			Expect(drifts).To(HaveLen(1))
+			Expect(drifts[0].Transactions).To(HaveLen(1))
+			Backend.Commit()
+			Expect(isAdmin()).To(BeFalse())
+		})
+
+		It("should not remediate the drift again while the transactions are pending", func() {
+			Expect(detector.Check(context.Background())).To(Succeed())
+			Expect(drifts).To(HaveLen(1))
+		})
+
+		It("should alert once the contracts match the spec again", func() {
+			Backend.Commit()
+			Expect(detector.Check(context.Background())).To(Succeed())
+			Expect(drifts).To(HaveLen(2))
+			Expect(drifts[1].Resolved()).To(BeTrue())
+		})
+
+		It("should remediate the drift again when it comes back", func() {
+			Backend.Commit()
+			Expect(detector.Check(context.Background())).To(Succeed())
+			setAdmin(true)
+			Expect(detector.Check(context.Background())).To(Succeed())
+			Expect(drifts).To(HaveLen(3))
+			Expect(drifts[2].Transactions).To(HaveLen(1))
+			Backend.Commit()
+			Expect(isAdmin()).To(BeFalse())
+		})
+	})
+
+	It("should not remediate a drift again while its alert is retried", func() {
+		detector.Remediate = ControllerOwner.TransactOpts()
+		alerter := detector.Alerter
+		down := true
+		detector.Alerter = reconcile.AlerterFunc(func(ctx context.Context, d reconcile.Drift) error {
+			if down {
+				return errors.New("receiver down")
+			}
+			return alerter.Alert(ctx, d)
+		})
+		setAdmin(true)
+		Expect(detector.Check(context.Background())).To(MatchError("receiver down"))
+
+		down = false
+		Expect(detector.Check(context.Background())).To(Succeed())
+		Expect(drifts).To(HaveLen(1))
+		Expect(drifts[0].Transactions).To(BeEmpty())
+		Backend.Commit()
+		Expect(isAdmin()).To(BeFalse())
+	})
+})
@@ -0,0 +1,177 @@
+package reconcile_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/reconcile"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+func loadSpec(yaml string) *reconcile.Spec {
+	s, err := reconcile.LoadSpec(strings.NewReader(yaml))
+	Expect(err).ToNot(HaveOccurred())
+	return s
+}
+
+// apply sends each action as the account holding the role it requires.
+func apply(r *reconcile.Reconciler, actions []reconcile.Action) {
+	signers := map[string]*ethertest.Account{
+		"controller owner": ControllerOwner,
+		"controller admin": ControllerAdmin,
+	}
+	for _, a := range actions {
+		signer, ok := signers[a.Role]
+		Expect(ok).To(BeTrue(), a.String())
+		txs, err := r.Apply(signer.TransactOpts(), []reconcile.Action{a})
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		receipt, err := Backend.TransactionReceipt(context.Background(), txs[0].Hash())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipt.Status).To(Equal(types.ReceiptStatusSuccessful), a.String())
+	}
+}
+
+var _ = Describe("Reconciler", func() {
+
+	var r *reconcile.Reconciler
+
+	BeforeEach(func() {
+		var err error
+		r, err = reconcile.New(Backend, reconcile.Contracts{
+			Licence:        LicenceAddress,
+			Controller:     ControllerContractAddress,
+			TokenWhitelist: TokenWhitelistAddress,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject unknown fields", func() {
+		_, err := reconcile.LoadSpec(strings.NewReader("licence:\n  tkn_bonus: 10\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject invalid addresses", func() {
+		_, err := reconcile.LoadSpec(strings.NewReader("licence:\n  dao: 0x12\n"))
+		Expect(err).To(MatchError(ContainSubstring("not a valid address")))
+	})
+
+	It("should not plan anything for a spec matching the chain", func() {
+		actions, err := r.Plan(nil, loadSpec(fmt.Sprintf(`
+licence:
+  amount_scaled: 10
+  token_holder: "%s"
+controller:
+  owner: "%s"
+  admins: ["%s"]
+  controllers: ["%s"]
+token_whitelist:
+  tokens:
+  - address: "%s"
+    symbol: TKN
+    magnitude: 100000000
+    loadable: true
+    redeemable: true
+`, TokenHolderAddress.Hex(), ControllerOwner.Address().Hex(), ControllerAdmin.Address().Hex(), Controller.Address().Hex(), TKNBurnerAddress.Hex())))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actions).To(BeEmpty())
+	})
+
+	When("the spec differs from the chain", func() {
+
+		var spec *reconcile.Spec
+		var actions []reconcile.Action
+
+		BeforeEach(func() {
+			spec = loadSpec(fmt.Sprintf(`
+licence:
+  dao: "%s"
+controller:
+  admins: ["%s"]
+  remove_controllers: ["%s"]
+token_whitelist:
+  prune: true
+  tokens:
+  - address: "%s"
+    symbol: TKN
+    magnitude: 100000000
+    loadable: false
+    redeemable: true
+  - address: "%s"
+    symbol: ERC1
+    magnitude: 1000
+    loadable: true
+    redeemable: false
+`, RandomAccount.Address().Hex(), RandomAccount.Address().Hex(), Controller.Address().Hex(), TKNBurnerAddress.Hex(), ERC20Contract1Address.Hex()))
+
+			var err error
+			actions, err = r.Plan(nil, spec)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should plan only the needed transactions", func() {
+			methods := []string{}
+			for _, a := range actions {
+				methods = append(methods, a.Contract+"."+a.Method)
+			}
+			Expect(methods).To(Equal([]string{
+				"licence.updateLicenceDAO",
+				"controller.addAdmin",
+				"controller.removeController",
+				"token_whitelist.setTokenLoadable",
+				"token_whitelist.removeTokens",
+				"token_whitelist.addTokens",
+			}))
+		})
+
+		When("the plan is applied", func() {
+
+			BeforeEach(func() {
+				apply(r, actions)
+			})
+
+			It("should converge to the spec", func() {
+				dao, err := Licence.LicenceDAO(nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dao).To(Equal(RandomAccount.Address()))
+
+				isAdmin, err := ControllerContract.IsAdmin(nil, RandomAccount.Address())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(isAdmin).To(BeTrue())
+
+				tokens, err := TokenWhitelist.TokenAddressArray(nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(tokens).To(ConsistOf(TKNBurnerAddress, ERC20Contract1Address))
+			})
+
+			It("should not plan anything when reconciled again", func() {
+				actions, err := r.Plan(&bind.CallOpts{}, spec)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actions).To(BeEmpty())
+			})
+		})
+	})
+
+	When("a locked licence parameter differs from the spec", func() {
+
+		BeforeEach(func() {
+			tx, err := Licence.LockHolder(ControllerAdmin.TransactOpts())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			receipt, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(receipt.Status).To(Equal(types.ReceiptStatusSuccessful))
+		})
+
+		It("should fail to plan", func() {
+			_, err := r.Plan(nil, loadSpec(fmt.Sprintf("licence:\n  token_holder: \"%s\"\n", RandomAccount.Address().Hex())))
+			Expect(err).To(MatchError(ContainSubstring("token holder is locked")))
+		})
+	})
+})
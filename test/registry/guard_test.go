@@ -0,0 +1,90 @@
+package registry_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+)
+
+// network is a node on a chain, recording the transactions sent to it.
+type network struct {
+	bind.ContractBackend
+	chainID *big.Int
+	sent    []*types.Transaction
+}
+
+func (n *network) ChainID(ctx context.Context) (*big.Int, error) {
+	return n.chainID, nil
+}
+
+func (n *network) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	n.sent = append(n.sent, tx)
+	return nil
+}
+
+var _ = Describe("Guard", func() {
+
+	ctx := context.Background()
+	var node *network
+	var guard *registry.Guard
+
+	sign := func(signer types.Signer) *types.Transaction {
+		key, err := crypto.GenerateKey()
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		Expect(err).ToNot(HaveOccurred())
+		return tx
+	}
+
+	BeforeEach(func() {
+		node = &network{chainID: ropsten}
+		guard = registry.NewGuard(node, node, ropsten)
+	})
+
+	It("sends the transactions signed for the network of the node", func() {
+		tx := sign(types.NewEIP155Signer(ropsten))
+		Expect(guard.SendTransaction(ctx, tx)).To(Succeed())
+		Expect(node.sent).To(Equal([]*types.Transaction{tx}))
+	})
+
+	It("refuses the transactions signed for another network", func() {
+		err := guard.SendTransaction(ctx, sign(types.NewEIP155Signer(mainnet)))
+		Expect(errors.Cause(err)).To(Equal(registry.ErrWrongNetwork))
+		Expect(err).To(MatchError(ContainSubstring("signed for chain 1, expected chain 3")))
+		Expect(node.sent).To(BeEmpty())
+	})
+
+	It("refuses to send when the node moved to another network", func() {
+		node.chainID = mainnet
+		err := guard.SendTransaction(ctx, sign(types.NewEIP155Signer(ropsten)))
+		Expect(errors.Cause(err)).To(Equal(registry.ErrWrongNetwork))
+		Expect(err).To(MatchError(ContainSubstring("the node is on chain 1, expected chain 3")))
+		Expect(node.sent).To(BeEmpty())
+	})
+
+	It("refuses the transactions valid on every network unless allowed", func() {
+		tx := sign(types.HomesteadSigner{})
+		err := guard.SendTransaction(ctx, tx)
+		Expect(errors.Cause(err)).To(Equal(registry.ErrWrongNetwork))
+
+		guard.AllowUnprotected = true
+		Expect(guard.SendTransaction(ctx, tx)).To(Succeed())
+		Expect(node.sent).To(HaveLen(1))
+	})
+
+	It("checks that the registry has the network of the node", func() {
+		reg := load(networksYAML)
+		Expect(reg.CheckNetwork(ropsten)).To(Succeed())
+		err := reg.CheckNetwork(big.NewInt(1337))
+		Expect(errors.Cause(err)).To(Equal(registry.ErrWrongNetwork))
+		Expect(registry.CheckChain(ctx, node, mainnet)).To(MatchError(ContainSubstring("wrong network")))
+	})
+})
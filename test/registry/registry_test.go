@@ -0,0 +1,106 @@
+package registry_test
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/registry"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+const networksYAML = `
+1:
+  controller: "0x00000000000000000000000000000000000000c1"
+  tkn: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+3:
+  controller: "0x00000000000000000000000000000000000000c3"
+`
+
+const networksJSON = `{"1": {"licence": "0x00000000000000000000000000000000000000d1"}}`
+
+var mainnet = big.NewInt(1)
+var ropsten = big.NewInt(3)
+
+func load(s string) *registry.Registry {
+	reg, err := registry.Load(strings.NewReader(s))
+	Expect(err).ToNot(HaveOccurred())
+	return reg
+}
+
+var _ = Describe("Registry", func() {
+
+	It("should load the addresses from YAML", func() {
+		reg := load(networksYAML)
+		Expect(reg.Address(mainnet, "controller")).To(Equal(common.HexToAddress("0xc1")))
+		Expect(reg.Address(ropsten, "controller")).To(Equal(common.HexToAddress("0xc3")))
+		Expect(reg.Address(mainnet, registry.TKN)).To(Equal(common.HexToAddress("0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")))
+		Expect(reg.ChainIDs()).To(Equal([]*big.Int{mainnet, ropsten}))
+	})
+
+	It("should load the addresses from JSON", func() {
+		reg := load(networksJSON)
+		Expect(reg.Address(mainnet, "licence")).To(Equal(common.HexToAddress("0xd1")))
+	})
+
+	It("should fail for a contract not deployed on the network", func() {
+		_, err := load(networksYAML).Address(ropsten, "licence")
+		Expect(errors.Cause(err)).To(Equal(registry.ErrNotDeployed))
+	})
+
+	invalid := []struct {
+		name, registry, err string
+	}{
+		{"an unknown contract", `{"1": {"referral": "0x0000000000000000000000000000000000000001"}}`, `unknown contract "referral"`},
+		{"an invalid address", `{"1": {"licence": "0x01z"}}`, "is not valid"},
+		{"an invalid chain ID", `{"mainnet": {"licence": "0x0000000000000000000000000000000000000001"}}`, "not a valid chain ID"},
+	}
+	for _, tc := range invalid {
+		tc := tc
+		It("should reject "+tc.name, func() {
+			_, err := registry.Load(strings.NewReader(tc.registry))
+			Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		})
+	}
+
+	It("should override the addresses", func() {
+		reg := load(networksYAML)
+		reg.Override(load(`{"1": {"controller": "0x00000000000000000000000000000000000000c2"}, "5": {"licence": "0x0000000000000000000000000000000000000005"}}`))
+		Expect(reg.Network(mainnet)).To(Equal(registry.Network{
+			"controller": common.HexToAddress("0xc2"),
+			"tkn":        common.HexToAddress("0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+		}))
+		Expect(reg.Address(ropsten, "controller")).To(Equal(common.HexToAddress("0xc3")))
+		Expect(reg.Address(big.NewInt(5), "licence")).To(Equal(common.HexToAddress("0x05")))
+	})
+
+	Describe("NewControllerForChain", func() {
+
+		var chainID = big.NewInt(1337)
+
+		BeforeEach(func() {
+			Expect(InitializeBackend()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(Backend.Close()).To(Succeed())
+		})
+
+		It("should bind the controller of the network", func() {
+			Expect(registry.Default.Set(chainID, "controller", ControllerContractAddress)).To(Succeed())
+			controller, err := registry.NewControllerForChain(chainID, Backend)
+			Expect(err).ToNot(HaveOccurred())
+			isController, err := controller.IsController(nil, Controller.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isController).To(BeTrue())
+		})
+
+		It("should fail when the network has no controller", func() {
+			_, err := registry.NewControllerForChain(big.NewInt(1338), Backend)
+			Expect(errors.Cause(err)).To(Equal(registry.ErrNotDeployed))
+		})
+	})
+})
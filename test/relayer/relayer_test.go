@@ -0,0 +1,322 @@
+package relayer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/relayer"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+var walletABI abi.ABI
+
+func init() {
+	var err error
+	walletABI, err = abi.JSON(strings.NewReader(bindings.WalletABI))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// transferData is the call data of a transfer of amount ether from the wallet.
+func transferData(to common.Address, amount *big.Int) []byte {
+	data, err := walletABI.Pack("transfer", to, common.Address{}, amount)
+	Expect(err).ToNot(HaveOccurred())
+	return data
+}
+
+func signedTransfer(nonce int64, amount *big.Int) relayer.MetaTx {
+	m, err := relayer.Sign(WalletAddress, big.NewInt(nonce), transferData(RandomAccount.Address(), amount), signing.KeySigner(Owner.PrivKey()))
+	Expect(err).ToNot(HaveOccurred())
+	return m
+}
+
+var _ = Describe("Relayer", func() {
+
+	var r *relayer.Relayer
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		r = relayer.New(Backend, Backend, Controller.TransactOpts(), relayer.NewMemoryLedger())
+	})
+
+	When("the owner signs a meta-transaction", func() {
+		var before *big.Int
+
+		BeforeEach(func() {
+			before = RandomAccount.Balance(Backend)
+			tx, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("executes it from the wallet", func() {
+			Expect(RandomAccount.Balance(Backend)).To(Equal(new(big.Int).Add(before, EthToWei(1))))
+			nonce, err := Wallet.RelayNonce(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nonce.String()).To(Equal("1"))
+		})
+
+		It("charges the fee to the owner once settled", func() {
+			Expect(r.Pending()).To(Equal(1))
+			err := r.Settle(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Pending()).To(Equal(0))
+
+			s, err := r.Status(Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Spent.Sign()).To(Equal(1))
+			Expect(s.Remaining).To(BeNil())
+		})
+
+		It("rejects a replayed nonce", func() {
+			_, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+			Expect(errors.Cause(err)).To(Equal(relayer.ErrNonce))
+		})
+	})
+
+	It("accepts the next nonce while the previous meta-transaction is pending", func() {
+		_, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = r.Submit(ctx, signedTransfer(1, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = r.Submit(ctx, signedTransfer(1, EthToWei(1)))
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrNonce))
+		Backend.Commit()
+
+		nonce, err := Wallet.RelayNonce(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nonce.String()).To(Equal("2"))
+	})
+
+	It("rejects meta-transactions not signed by the owner", func() {
+		key, err := crypto.GenerateKey()
+		Expect(err).ToNot(HaveOccurred())
+		m, err := relayer.Sign(WalletAddress, big.NewInt(0), transferData(RandomAccount.Address(), EthToWei(1)), signing.KeySigner(key))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = r.Submit(ctx, m)
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrNotOwner))
+		Expect(r.Pending()).To(Equal(0))
+	})
+
+	It("rejects meta-transactions of users who are not eligible", func() {
+		r.Eligible = relayer.TokenHolders(StablecoinAddress, Backend)
+		_, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrNotEligible))
+
+		_, err = Stablecoin.Credit(BankAccount.TransactOpts(), Owner.Address(), big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		_, err = r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("stops relaying once the allowance is spent", func() {
+		r.Allowance = big.NewInt(1)
+		_, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		err = r.Settle(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = r.Submit(ctx, signedTransfer(1, EthToWei(1)))
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrAllowanceExceeded))
+		s, err := r.Status(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Remaining.String()).To(Equal("0"))
+	})
+
+	It("reserves the worst-case fee of the pending meta-transactions", func() {
+		tx, err := r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+		maxFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+		s, err := r.Status(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Reserved.String()).To(Equal(maxFee.String()))
+
+		// The first meta-transaction is not settled, its fee can not exceed
+		// its gas limit.
+		r.Allowance = maxFee
+		_, err = r.Submit(ctx, signedTransfer(1, EthToWei(1)))
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrAllowanceExceeded))
+
+		r.Allowance = new(big.Int).Add(maxFee, big.NewInt(1))
+		_, err = r.Submit(ctx, signedTransfer(1, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+
+		Backend.Commit()
+		Expect(r.Settle(ctx)).To(Succeed())
+		s, err = r.Status(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Reserved.Sign()).To(Equal(0))
+		Expect(s.Spent.Sign()).To(Equal(1))
+	})
+
+	It("reads the nonce from the wallet again after a reverted meta-transaction", func() {
+		// Without a gas limit the transaction would fail the gas estimation.
+		r = relayer.New(Backend, Backend, Controller.TransactOpts(ethertest.WithGasLimit(500000)), relayer.NewMemoryLedger())
+		tx, err := r.Submit(ctx, signedTransfer(0, EthToWei(1000)))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeFalse())
+		err = r.Settle(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = r.Submit(ctx, signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("the handler", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(relayer.NewHandler(r))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		post := func(m relayer.MetaTx) *http.Response {
+			body, err := json.Marshal(relayer.SubmitRequest{
+				Wallet:    m.Wallet,
+				Nonce:     m.Nonce.String(),
+				Data:      hexutil.Bytes(m.Data),
+				Signature: hexutil.Bytes(m.Signature),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			res, err := http.Post(server.URL+"/relay", "application/json", bytes.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			return res
+		}
+
+		It("relays a meta-transaction", func() {
+			res := post(signedTransfer(0, EthToWei(1)))
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusAccepted))
+			var body relayer.SubmitResponse
+			err := json.NewDecoder(res.Body).Decode(&body)
+			Expect(err).ToNot(HaveOccurred())
+
+			Backend.Commit()
+			receipt, err := Backend.TransactionReceipt(ctx, body.TxHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(receipt.Status).To(Equal(types.ReceiptStatusSuccessful))
+		})
+
+		It("returns a conflict for an invalid nonce", func() {
+			res := post(signedTransfer(1, EthToWei(1)))
+			res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusConflict))
+		})
+
+		It("returns the fees spent by a user", func() {
+			r.Allowance = EthToWei(1)
+			res := post(signedTransfer(0, EthToWei(1)))
+			res.Body.Close()
+			Backend.Commit()
+			err := r.Settle(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			spent, err := r.Status(Owner.Address())
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err = http.Get(server.URL + "/relay/" + Owner.Address().Hex())
+			Expect(err).ToNot(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			var body relayer.StatusResponse
+			err = json.NewDecoder(res.Body).Decode(&body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body.User).To(Equal(Owner.Address()))
+			Expect(body.Spent).To(Equal(spent.Spent.String()))
+			Expect(body.Reserved).To(Equal("0"))
+			Expect(body.Remaining).To(Equal(new(big.Int).Sub(EthToWei(1), spent.Spent).String()))
+		})
+	})
+})
+
+var _ = Describe("FileLedger", func() {
+
+	var path string
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "ledger")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "ledger.jsonl")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(filepath.Dir(path))
+	})
+
+	It("settles the meta-transactions relayed before a restart", func() {
+		l, err := relayer.OpenFileLedger(path)
+		Expect(err).ToNot(HaveOccurred())
+		r := relayer.New(Backend, Backend, Controller.TransactOpts(), l)
+		tx, err := r.Submit(context.Background(), signedTransfer(0, EthToWei(1)))
+		Expect(err).ToNot(HaveOccurred())
+
+		l, err = relayer.OpenFileLedger(path)
+		Expect(err).ToNot(HaveOccurred())
+		r = relayer.New(Backend, Backend, Controller.TransactOpts(), l)
+		Expect(r.Pending()).To(Equal(1))
+		s, err := r.Status(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Reserved.String()).To(Equal(new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()).String()))
+		// The nonce of the pending meta-transaction is not reused.
+		_, err = r.Submit(context.Background(), signedTransfer(0, EthToWei(1)))
+		Expect(errors.Cause(err)).To(Equal(relayer.ErrNonce))
+
+		Backend.Commit()
+		Expect(r.Settle(context.Background())).To(Succeed())
+		Expect(r.Pending()).To(Equal(0))
+
+		l, err = relayer.OpenFileLedger(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Unsettled()).To(BeEmpty())
+		spent, err := l.Spent(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spent.Sign()).To(Equal(1))
+	})
+
+	It("reads the charges back when reopened", func() {
+		l, err := relayer.OpenFileLedger(path)
+		Expect(err).ToNot(HaveOccurred())
+		for _, fee := range []int64{3, 4} {
+			err = l.Charge(relayer.Charge{Time: time.Now(), User: Owner.Address(), Wallet: WalletAddress, Fee: big.NewInt(fee)})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		l, err = relayer.OpenFileLedger(path)
+		Expect(err).ToNot(HaveOccurred())
+		spent, err := l.Spent(Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spent.String()).To(Equal("7"))
+		spent, err = l.Spent(RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spent.Sign()).To(Equal(0))
+	})
+})
@@ -0,0 +1,130 @@
+package release_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/tools/release/dist"
+)
+
+var _ = Describe("Dist", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "release")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("ParseTargets", func() {
+
+		It("should parse a list of targets", func() {
+			targets, err := dist.ParseTargets("linux/arm64, windows/amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets).To(Equal([]dist.Target{{OS: "linux", Arch: "arm64"}, {OS: "windows", Arch: "amd64"}}))
+		})
+
+		It("should reject a target without an architecture", func() {
+			_, err := dist.ParseTargets("linux")
+			Expect(err).To(MatchError(ContainSubstring(`invalid target "linux"`)))
+		})
+	})
+
+	It("should only build the darwin targets on darwin hosts", func() {
+		t := dist.Target{OS: "darwin", Arch: "arm64"}
+		Expect(t.Cgo()).To(BeTrue())
+		Expect(t.Buildable()).To(Equal(runtime.GOOS == "darwin"))
+	})
+
+	It("should cross-compile without cgo", func() {
+		t := dist.Target{OS: "windows", Arch: "amd64"}
+		if runtime.GOOS == "windows" {
+			t.OS = "linux"
+		}
+		Expect(t.Cgo()).To(BeFalse())
+		paths, err := dist.Build(dir, t, "monolithd")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(Equal([]string{filepath.Join(dir, t.Executable("monolithd"))}))
+		Expect(paths[0]).To(BeAnExistingFile())
+	})
+
+	Describe("Package", func() {
+
+		var files []string
+
+		BeforeEach(func() {
+			files = []string{filepath.Join(dir, "monolithd"), filepath.Join(dir, "LICENSE")}
+			Expect(ioutil.WriteFile(files[0], []byte("binary"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(files[1], []byte("licence"), 0644)).To(Succeed())
+		})
+
+		It("should write a gzipped tar file for unix targets", func() {
+			path, err := dist.Package(dir, "v1.0.0", dist.Target{OS: "linux", Arch: "arm64"}, files...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Base(path)).To(Equal("monolith_v1.0.0_linux_arm64.tar.gz"))
+
+			f, err := os.Open(path)
+			Expect(err).ToNot(HaveOccurred())
+			defer f.Close()
+			gz, err := gzip.NewReader(f)
+			Expect(err).ToNot(HaveOccurred())
+			tr := tar.NewReader(gz)
+
+			h, err := tr.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(h.Name).To(Equal("monolith_v1.0.0_linux_arm64/monolithd"))
+			Expect(h.FileInfo().Mode().Perm() & 0111).ToNot(BeZero())
+			data, err := ioutil.ReadAll(tr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("binary"))
+
+			h, err = tr.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(h.Name).To(Equal("monolith_v1.0.0_linux_arm64/LICENSE"))
+		})
+
+		It("should write a zip file for windows", func() {
+			path, err := dist.Package(dir, "v1.0.0", dist.Target{OS: "windows", Arch: "amd64"}, files...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Base(path)).To(Equal("monolith_v1.0.0_windows_amd64.zip"))
+
+			z, err := zip.OpenReader(path)
+			Expect(err).ToNot(HaveOccurred())
+			defer z.Close()
+			Expect(z.File).To(HaveLen(2))
+			Expect(z.File[0].Name).To(Equal("monolith_v1.0.0_windows_amd64/monolithd"))
+		})
+
+		It("should not leave an archive behind on failure", func() {
+			_, err := dist.Package(dir, "v1.0.0", dist.Target{OS: "linux", Arch: "amd64"}, filepath.Join(dir, "missing"))
+			Expect(err).To(HaveOccurred())
+			Expect(filepath.Join(dir, "monolith_v1.0.0_linux_amd64.tar.gz")).ToNot(BeAnExistingFile())
+		})
+	})
+
+	It("should write the checksums of the archives", func() {
+		a := filepath.Join(dir, "b.zip")
+		b := filepath.Join(dir, "a.tar.gz")
+		Expect(ioutil.WriteFile(a, []byte("a"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(b, nil, 0644)).To(Succeed())
+		Expect(dist.WriteChecksums(dir, a, b)).To(Succeed())
+
+		sums, err := ioutil.ReadFile(filepath.Join(dir, "SHA256SUMS"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(sums)).To(Equal(
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  a.tar.gz\n" +
+				"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  b.zip\n"))
+	})
+})
@@ -0,0 +1,49 @@
+package replay_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+func TestReplaySuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replay Suite")
+}
+
+// provider serves the logs of the test backend like a provider would, with
+// the bugs set by tamper applied to the result of each filter.
+type provider struct {
+	ethertest.TestBackend
+	head   *big.Int
+	tamper func(logs []types.Log) []types.Log
+}
+
+func (p *provider) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: p.head}, nil
+}
+
+func (p *provider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := p.TestBackend.FilterLogs(ctx, q)
+	if err != nil || p.tamper == nil {
+		return logs, err
+	}
+	return p.tamper(logs), nil
+}
+
+var _ = BeforeEach(func() {
+	err := shared.InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+})
+
+var _ = AfterEach(func() {
+	err := shared.Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
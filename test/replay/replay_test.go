@@ -0,0 +1,151 @@
+package replay_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/replay"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Replay", func() {
+
+	var token indexer.Contract
+	var start, end uint64
+	var a, b *provider
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		parsed, err := abi.JSON(strings.NewReader(mocks.TokenABI))
+		Expect(err).ToNot(HaveOccurred())
+		token = indexer.Contract{Name: "token", Address: ERC20Contract1Address, ABI: parsed}
+
+		tx, err := ERC20Contract1.Credit(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		start = blockOf(tx) + 1
+
+		for i := 0; i < 4; i++ {
+			tx, err = ERC20Contract1.Transfer(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(int64(i+1)))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+		}
+		end = blockOf(tx)
+
+		a = &provider{TestBackend: Backend, head: new(big.Int).SetUint64(end)}
+		b = &provider{TestBackend: Backend, head: new(big.Int).SetUint64(end)}
+	})
+
+	check := func(opts replay.Options) *replay.Report {
+		opts.StartBlock = start
+		opts.RangeSize = 2
+		report, err := replay.Check(ctx, replay.Source{Name: "a", Backend: a}, replay.Source{Name: "b", Backend: b}, opts, token)
+		Expect(err).ToNot(HaveOccurred())
+		return report
+	}
+
+	It("should find no difference between identical providers", func() {
+		report := check(replay.Options{})
+		Expect(report.EndBlock).To(Equal(end))
+		Expect(report.Events).To(Equal(map[string]int{"a": 4, "b": 4}))
+		Expect(report.Differences).To(BeEmpty())
+	})
+
+	It("should compare up to the lowest head less the confirmations", func() {
+		b.head = new(big.Int).SetUint64(end - 1)
+		report := check(replay.Options{Confirmations: 1})
+		Expect(report.EndBlock).To(Equal(end - 2))
+		Expect(report.Events["a"]).To(Equal(2))
+		Expect(report.Differences).To(BeEmpty())
+	})
+
+	It("should flag the logs missing from a provider", func() {
+		b.tamper = func(logs []types.Log) []types.Log {
+			var kept []types.Log
+			for _, l := range logs {
+				if l.BlockNumber != end {
+					kept = append(kept, l)
+				}
+			}
+			return kept
+		}
+		report := check(replay.Options{})
+		Expect(report.Events).To(Equal(map[string]int{"a": 4, "b": 3}))
+		Expect(report.Differences).To(HaveLen(1))
+		d := report.Differences[0]
+		Expect(d.Kind).To(Equal(replay.Missing))
+		Expect(d.Provider).To(Equal("b"))
+		Expect(d.Event.BlockNumber).To(Equal(end))
+		Expect(d.Event.Args["amount"]).To(Equal(big.NewInt(4)))
+	})
+
+	It("should flag the logs wrongly marked as removed", func() {
+		a.tamper = func(logs []types.Log) []types.Log {
+			for i := range logs {
+				if logs[i].BlockNumber == start {
+					logs[i].Removed = true
+				}
+			}
+			return logs
+		}
+		report := check(replay.Options{})
+		Expect(report.Differences).To(HaveLen(1))
+		Expect(report.Differences[0].Kind).To(Equal(replay.Removed))
+		Expect(report.Differences[0].Provider).To(Equal("a"))
+		Expect(report.Differences[0].Event.BlockNumber).To(Equal(start))
+	})
+
+	It("should flag the logs returned twice or out of order", func() {
+		b.tamper = func(logs []types.Log) []types.Log {
+			if len(logs) < 2 {
+				return logs
+			}
+			return []types.Log{logs[1], logs[0], logs[0]}
+		}
+		report := check(replay.Options{})
+		var kinds []string
+		for _, d := range report.Differences {
+			Expect(d.Provider).To(Equal("b"))
+			kinds = append(kinds, d.Kind)
+		}
+		Expect(kinds).To(ConsistOf(replay.Order, replay.Duplicate, replay.Order, replay.Duplicate))
+	})
+
+	It("should flag the logs whose content differs", func() {
+		b.tamper = func(logs []types.Log) []types.Log {
+			for i := range logs {
+				if logs[i].BlockNumber == start {
+					logs[i].BlockHash = common.HexToHash("0x01")
+				}
+			}
+			return logs
+		}
+		report := check(replay.Options{})
+		Expect(report.Differences).To(HaveLen(1))
+		d := report.Differences[0]
+		Expect(d.Kind).To(Equal(replay.Mismatch))
+		Expect(d.Provider).To(Equal("b"))
+		Expect(d.Other.BlockHash).To(Equal(common.HexToHash("0x01")))
+		Expect(d.String()).To(ContainSubstring("b: mismatch token.Transfer"))
+	})
+
+	It("should refuse two providers of the same name", func() {
+		_, err := replay.Check(ctx, replay.Source{Name: "a", Backend: a}, replay.Source{Name: "a", Backend: b}, replay.Options{}, token)
+		Expect(err).To(MatchError(`both providers are named "a"`))
+	})
+})
+
+func blockOf(tx *types.Transaction) uint64 {
+	r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+	return r.BlockNumber.Uint64()
+}
@@ -0,0 +1,105 @@
+package rotation_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/canary"
+	"github.com/tokencard/contracts/v2/pkg/rotation"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Controller", func() {
+
+	var dir, configFile string
+	var r *rotation.Controller
+
+	isController := func(a common.Address) bool {
+		ok, err := ControllerContract.IsController(nil, a)
+		Expect(err).ToNot(HaveOccurred())
+		return ok
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "rotation")
+		Expect(err).ToNot(HaveOccurred())
+		configFile = filepath.Join(dir, "monolithd.json")
+		Expect(ioutil.WriteFile(configFile, []byte(`{"account": "`+Controller.Address().Hex()+`"}`), 0600)).To(Succeed())
+
+		r = &rotation.Controller{
+			Controller: ControllerContract,
+			Receipts:   Chain,
+			Admin:      ControllerAdmin.TransactOpts(),
+			Old:        Controller.Address(),
+			New:        RandomAccount.Address(),
+			Canary: &canary.Canary{
+				Backend:  Chain,
+				Receipts: Chain,
+				Opts:     RandomAccount.TransactOpts(),
+				Token:    StablecoinAddress,
+			},
+			ConfigFile: configFile,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should move the controller role to the new account", func() {
+		Expect(rotation.Run(context.Background(), r.Steps(), nil, nil)).To(Succeed())
+		Expect(isController(RandomAccount.Address())).To(BeTrue())
+		Expect(isController(Controller.Address())).To(BeFalse())
+
+		config, err := ioutil.ReadFile(configFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(config)).To(Equal(`{"account": "` + RandomAccount.Address().Hex() + `"}`))
+
+		allowance, err := Stablecoin.Allowance(nil, RandomAccount.Address(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowance.String()).To(Equal("1"))
+	})
+
+	It("should refuse to rotate an account which is not a controller", func() {
+		r.Old = RandomAccount.Address()
+		r.New = Owner.Address()
+		err := rotation.Run(context.Background(), r.Steps(), nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("is not a controller")))
+		Expect(isController(Owner.Address())).To(BeFalse())
+	})
+
+	When("the configuration file has another account", func() {
+
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(configFile, []byte(`{"account": "`+Owner.Address().Hex()+`"}`), 0600)).To(Succeed())
+		})
+
+		It("should roll the new controller back", func() {
+			err := rotation.Run(context.Background(), r.Steps(), nil, nil)
+			Expect(err).To(MatchError(ContainSubstring("switch account")))
+			Expect(isController(RandomAccount.Address())).To(BeFalse())
+			Expect(isController(Controller.Address())).To(BeTrue())
+		})
+	})
+
+	When("the old controller is not removed", func() {
+
+		It("should restore the configuration file", func() {
+			confirm := func(s rotation.Step) (bool, error) {
+				return s.Name != "remove controller "+Controller.Address().Hex(), nil
+			}
+			err := rotation.Run(context.Background(), r.Steps(), confirm, nil)
+			Expect(err).To(MatchError(ContainSubstring("aborted")))
+			config, err := ioutil.ReadFile(configFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(config)).To(Equal(`{"account": "` + Controller.Address().Hex() + `"}`))
+			Expect(isController(RandomAccount.Address())).To(BeFalse())
+		})
+	})
+})
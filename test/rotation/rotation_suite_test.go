@@ -0,0 +1,27 @@
+package rotation_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestRotationSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rotation Suite")
+}
+
+var Chain *TestChain
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = NewTestChain(Backend, MineOnReceipt)
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
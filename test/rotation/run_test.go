@@ -0,0 +1,102 @@
+package rotation_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/rotation"
+)
+
+var _ = Describe("Run", func() {
+
+	var log []string
+	var steps []rotation.Step
+
+	step := func(name string, fail, undoable bool) rotation.Step {
+		s := rotation.Step{
+			Name: name,
+			Do: func(ctx context.Context) error {
+				if fail {
+					return errors.New("failed")
+				}
+				log = append(log, "do "+name)
+				return nil
+			},
+		}
+		if undoable {
+			s.Undo = func(ctx context.Context) error {
+				log = append(log, "undo "+name)
+				return nil
+			}
+		}
+		return s
+	}
+
+	BeforeEach(func() {
+		log = nil
+		steps = []rotation.Step{step("a", false, true), step("b", false, false), step("c", false, true)}
+	})
+
+	It("should run the steps in order", func() {
+		Expect(rotation.Run(context.Background(), steps, nil, nil)).To(Succeed())
+		Expect(log).To(Equal([]string{"do a", "do b", "do c"}))
+	})
+
+	It("should undo the steps done when a step fails", func() {
+		steps = append(steps, step("d", true, true))
+		err := rotation.Run(context.Background(), steps, nil, nil)
+		Expect(err).To(MatchError("d: failed"))
+		Expect(log).To(Equal([]string{"do a", "do b", "do c", "undo c", "undo a"}))
+	})
+
+	It("should undo the steps done when a step is not confirmed", func() {
+		confirm := func(s rotation.Step) (bool, error) {
+			return s.Name != "c", nil
+		}
+		err := rotation.Run(context.Background(), steps, confirm, nil)
+		Expect(errors.Cause(err)).To(Equal(rotation.ErrAborted))
+		Expect(log).To(Equal([]string{"do a", "do b", "undo a"}))
+	})
+
+	It("should report the undo steps which fail", func() {
+		steps[0].Undo = func(ctx context.Context) error {
+			return errors.New("stuck")
+		}
+		steps = append(steps, step("d", true, true))
+		err := rotation.Run(context.Background(), steps, nil, nil)
+		Expect(err).To(MatchError("d: failed; undoing a: stuck"))
+		Expect(log).To(Equal([]string{"do a", "do b", "do c", "undo c"}))
+	})
+})
+
+var _ = Describe("SwitchAccount", func() {
+
+	const config = `{
+  "rpc_url": "http://localhost:8545",
+  "account": "0x000000000000000000000000000000000000000A",
+  "contracts": {"controller": "0x000000000000000000000000000000000000000b"}
+}`
+
+	It("should only replace the account", func() {
+		updated, err := rotation.SwitchAccount([]byte(config), common.HexToAddress("0xa"), common.HexToAddress("0xc"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(updated)).To(Equal(`{
+  "rpc_url": "http://localhost:8545",
+  "account": "0x000000000000000000000000000000000000000C",
+  "contracts": {"controller": "0x000000000000000000000000000000000000000b"}
+}`))
+	})
+
+	It("should refuse to replace another account", func() {
+		_, err := rotation.SwitchAccount([]byte(config), common.HexToAddress("0xb"), common.HexToAddress("0xc"))
+		Expect(err).To(MatchError(ContainSubstring("is 0x000000000000000000000000000000000000000A, not")))
+	})
+
+	It("should refuse a configuration without account", func() {
+		_, err := rotation.SwitchAccount([]byte(`{"keystore_file": "key.json"}`), common.HexToAddress("0xa"), common.HexToAddress("0xc"))
+		Expect(err).To(MatchError("the configuration file has no account"))
+	})
+})
@@ -0,0 +1,215 @@
+package safe_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tokencard/contracts/v2/pkg/safe"
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+// typedData is the EIP-712 message of a Safe transaction, hashed by the
+// go-ethereum implementation to check the hash of the package.
+func typedData(t safe.Transaction) core.TypedData {
+	domainType := []core.Type{{Name: "verifyingContract", Type: "address"}}
+	domain := core.TypedDataDomain{VerifyingContract: t.Safe.Hex()}
+	if t.ChainID != nil {
+		domainType = append([]core.Type{{Name: "chainId", Type: "uint256"}}, domainType...)
+		domain.ChainId = (*math.HexOrDecimal256)(t.ChainID)
+	}
+	return core.TypedData{
+		Types: core.Types{
+			"EIP712Domain": domainType,
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain:      domain,
+		Message: core.TypedDataMessage{
+			"to":             t.To.Hex(),
+			"value":          (*big.Int)(t.Value).String(),
+			"data":           []byte(t.Data),
+			"operation":      "0",
+			"safeTxGas":      "0",
+			"baseGas":        "0",
+			"gasPrice":       "0",
+			"gasToken":       t.GasToken.Hex(),
+			"refundReceiver": t.RefundReceiver.Hex(),
+			"nonce":          (*big.Int)(t.Nonce).String(),
+		},
+	}
+}
+
+func eip712Hash(t safe.Transaction) common.Hash {
+	td := typedData(t)
+	// go-ethereum requires a chain ID in the domain, which the Safes before
+	// 1.3.0 do not have: the domain is hashed from its fields and any chain
+	// ID satisfies the validation.
+	domainData := core.TypedDataMessage{"verifyingContract": td.Domain.VerifyingContract}
+	if td.Domain.ChainId != nil {
+		domainData["chainId"] = td.Domain.ChainId
+	} else {
+		td.Domain.ChainId = math.NewHexOrDecimal256(1)
+	}
+	domain, err := td.HashStruct("EIP712Domain", domainData)
+	Expect(err).ToNot(HaveOccurred())
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	Expect(err).ToNot(HaveOccurred())
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, message)
+}
+
+var _ = Describe("Proposal", func() {
+
+	var tx safe.Transaction
+	var keys []*ecdsa.PrivateKey
+	var owners []common.Address
+
+	BeforeEach(func() {
+		tx = safe.Transaction{
+			Safe:      common.HexToAddress("0x5afe"),
+			To:        common.HexToAddress("0xc0"),
+			Value:     new(hexutil.Big),
+			Data:      hexutil.Bytes{0x70, 0x48, 0x02, 0x75, 0x01},
+			SafeTxGas: new(hexutil.Big),
+			BaseGas:   new(hexutil.Big),
+			GasPrice:  new(hexutil.Big),
+			Nonce:     (*hexutil.Big)(big.NewInt(7)),
+		}
+		keys, owners = nil, nil
+		for i := 0; i < 3; i++ {
+			key, err := crypto.GenerateKey()
+			Expect(err).ToNot(HaveOccurred())
+			keys = append(keys, key)
+			owners = append(owners, crypto.PubkeyToAddress(key.PublicKey))
+		}
+	})
+
+	It("should hash Safe transactions before 1.3.0 as EIP-712 messages", func() {
+		Expect(tx.Hash()).To(Equal(eip712Hash(tx)))
+	})
+
+	It("should include the chain ID in the domain from 1.3.0", func() {
+		legacy := tx.Hash()
+		tx.ChainID = (*hexutil.Big)(big.NewInt(1))
+		Expect(tx.Hash()).To(Equal(eip712Hash(tx)))
+		Expect(tx.Hash()).ToNot(Equal(legacy))
+	})
+
+	It("should collect the signatures of the owners", func() {
+		p := &safe.Proposal{Transaction: tx}
+		for i, key := range keys {
+			owner, err := p.Sign(signing.KeySigner(key))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal(owners[i]))
+		}
+		Expect(p.Signatures).To(HaveLen(3))
+		Expect(p.Verify(owners)).To(Succeed())
+		for _, s := range p.Signatures {
+			Expect(s.Data[64]).To(BeNumerically(">=", 27))
+		}
+	})
+
+	It("should reject a second signature of the same owner", func() {
+		p := &safe.Proposal{Transaction: tx}
+		_, err := p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).To(MatchError(ContainSubstring(safe.ErrDuplicateSignature.Error())))
+	})
+
+	It("should reject the signatures of non owners", func() {
+		p := &safe.Proposal{Transaction: tx}
+		_, err := p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Verify(owners[1:])).To(MatchError(ContainSubstring("is not an owner")))
+	})
+
+	It("should reject signatures of another transaction", func() {
+		p := &safe.Proposal{Transaction: tx}
+		_, err := p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).ToNot(HaveOccurred())
+		p.Transaction.Nonce = (*hexutil.Big)(big.NewInt(8))
+		Expect(p.Verify(owners)).ToNot(Succeed())
+	})
+
+	It("should pack the signatures in ascending order of their signer", func() {
+		p := &safe.Proposal{Transaction: tx}
+		for _, key := range keys {
+			_, err := p.Sign(signing.KeySigner(key))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		packed := p.PackedSignatures()
+		Expect(packed).To(HaveLen(3 * 65))
+
+		var previous common.Address
+		for i := 0; i < 3; i++ {
+			pub, err := crypto.SigToPub(tx.Hash().Bytes(), append(append([]byte(nil), packed[i*65:i*65+64]...), packed[i*65+64]-27))
+			Expect(err).ToNot(HaveOccurred())
+			signer := crypto.PubkeyToAddress(*pub)
+			Expect(bytes.Compare(signer[:], previous[:])).To(Equal(1))
+			previous = signer
+		}
+	})
+
+	It("should be saved and loaded with its signatures", func() {
+		dir, err := ioutil.TempDir("", "safe")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		p := &safe.Proposal{Transaction: tx}
+		_, err = p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).ToNot(HaveOccurred())
+		path := filepath.Join(dir, "proposal.json")
+		Expect(p.Save(path)).To(Succeed())
+
+		loaded, err := safe.LoadProposal(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded.Transaction.Hash()).To(Equal(tx.Hash()))
+		Expect(loaded.Verify(owners)).To(Succeed())
+	})
+
+	It("should encode the execTransaction call", func() {
+		s, err := safe.New(tx.Safe, nil)
+		Expect(err).ToNot(HaveOccurred())
+		p := &safe.Proposal{Transaction: tx}
+		_, err = p.Sign(signing.KeySigner(keys[0]))
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := s.ExecData(p)
+		Expect(err).ToNot(HaveOccurred())
+		parsed, err := abi.JSON(strings.NewReader(safe.ABI))
+		Expect(err).ToNot(HaveOccurred())
+		method := parsed.Methods["execTransaction"]
+		Expect(data[:4]).To(Equal(method.ID()))
+
+		args, err := method.Inputs.UnpackValues(data[4:])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(args[0]).To(Equal(tx.To))
+		Expect(args[2]).To(Equal([]byte(tx.Data)))
+		Expect(args[9]).To(Equal(p.PackedSignatures()))
+	})
+})
@@ -0,0 +1,13 @@
+package safe_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSafeSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Safe Suite")
+}
@@ -0,0 +1,13 @@
+package script_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestScriptSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Script Suite")
+}
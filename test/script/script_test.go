@@ -0,0 +1,101 @@
+package script_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/script"
+	"go.starlark.net/starlark"
+)
+
+var _ = Describe("Script", func() {
+
+	var s *script.Script
+	var lines []string
+	var printed []string
+
+	record := func(name string) script.Command {
+		return func(ctx context.Context, args []string) error {
+			lines = append(lines, strings.Join(append([]string{name}, args...), " "))
+			return nil
+		}
+	}
+
+	BeforeEach(func() {
+		lines, printed = nil, nil
+		s = &script.Script{
+			Commands: map[string]script.Command{
+				"roles":              record("roles"),
+				"set-licence-amount": record("set-licence-amount"),
+				"events":             record("events"),
+				"claim": func(ctx context.Context, args []string) error {
+					return errors.New("execution reverted")
+				},
+			},
+			Globals: starlark.StringDict{
+				"admins": starlark.NewList([]starlark.Value{starlark.String("0x1"), starlark.String("0x2")}),
+			},
+			Print: func(msg string) { printed = append(printed, msg) },
+		}
+	})
+
+	It("should call the commands with their arguments and flags", func() {
+		src := `
+for admin in admins:
+    roles(admin)
+set_licence_amount(10)
+events("licence", from_block = 9000000, follow = True, decode = False)
+print("done", len(admins))
+`
+		Expect(s.Exec(context.Background(), "rotate.star", src)).To(Succeed())
+		Expect(lines).To(Equal([]string{
+			"roles 0x1",
+			"roles 0x2",
+			"set-licence-amount 10",
+			"events -decode=false -follow -from-block=9000000 licence",
+		}))
+		Expect(printed).To(Equal([]string{"done 2"}))
+	})
+
+	It("should stop at the first failing command", func() {
+		err := s.Exec(context.Background(), "claim.star", "roles(\"0x1\")\nclaim(\"holder\")\nroles(\"0x2\")\n")
+		Expect(err).To(MatchError(ContainSubstring("claim.star:2")))
+		Expect(err).To(MatchError(ContainSubstring("claim: execution reverted")))
+		Expect(lines).To(Equal([]string{"roles 0x1"}))
+	})
+
+	It("should reject the arguments which are not strings or integers", func() {
+		err := s.Exec(context.Background(), "roles.star", "roles([\"0x1\"])\n")
+		Expect(err).To(MatchError(ContainSubstring("argument 1: list is not a string or an int")))
+		Expect(lines).To(BeEmpty())
+	})
+
+	It("should not run the commands once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := s.Exec(ctx, "roles.star", "roles(\"0x1\")\n")
+		Expect(err).To(MatchError(ContainSubstring("context canceled")))
+		Expect(lines).To(BeEmpty())
+	})
+
+	It("should reject the while statements", func() {
+		err := s.Exec(context.Background(), "loop.star", "def f():\n    while True:\n        roles(\"0x1\")\nf()\n")
+		Expect(err).To(MatchError(ContainSubstring("while")))
+		Expect(lines).To(BeEmpty())
+	})
+
+	It("should hand its context to the other builtins", func() {
+		type key struct{}
+		var got interface{}
+		s.Globals["check"] = starlark.NewBuiltin("check", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			got = script.Context(thread).Value(key{})
+			return starlark.None, nil
+		})
+		ctx := context.WithValue(context.Background(), key{}, "script")
+		Expect(s.Exec(ctx, "check.star", "check()\n")).To(Succeed())
+		Expect(got).To(Equal("script"))
+	})
+})
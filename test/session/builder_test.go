@@ -0,0 +1,150 @@
+package session_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/session"
+)
+
+// sources returns a fixed gas price and nonce.
+type sources struct {
+	price   *big.Int
+	nonce   uint64
+	account common.Address
+	err     error
+}
+
+func (s *sources) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return s.price, s.err
+}
+
+func (s *sources) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	s.account = account
+	return s.nonce, s.err
+}
+
+var _ = Describe("OptsBuilder", func() {
+
+	var (
+		ctx    = context.Background()
+		signer *bind.TransactOpts
+	)
+
+	BeforeEach(func() {
+		signer = &bind.TransactOpts{
+			From: common.HexToAddress("0x1"),
+			Signer: func(_ types.Signer, _ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		}
+	})
+
+	It("should compose the options", func() {
+		s := &sources{price: big.NewInt(20), nonce: 5}
+		opts, cancel, err := session.NewOptsBuilder(signer).
+			GasPrices(s).
+			Nonces(s).
+			GasLimit(100000).
+			Value(big.NewInt(3)).
+			Timeout(time.Minute).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer cancel()
+
+		Expect(opts.From).To(Equal(signer.From))
+		Expect(opts.Signer).ToNot(BeNil())
+		Expect(opts.GasPrice.String()).To(Equal("20"))
+		Expect(opts.Nonce.String()).To(Equal("5"))
+		Expect(s.account).To(Equal(signer.From))
+		Expect(opts.GasLimit).To(Equal(uint64(100000)))
+		Expect(opts.Value.String()).To(Equal("3"))
+		deadline, ok := opts.Context.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+	})
+
+	It("should keep the options of the base and the later settings", func() {
+		signer.GasPrice = big.NewInt(7)
+		signer.GasLimit = 21000
+		opts, cancel, err := session.NewOptsBuilder(signer).Nonce(3).Nonce(4).Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer cancel()
+		Expect(opts.GasPrice.String()).To(Equal("7"))
+		Expect(opts.GasLimit).To(Equal(uint64(21000)))
+		Expect(opts.Nonce.String()).To(Equal("4"))
+
+		opts.GasPrice.SetInt64(8)
+		Expect(signer.GasPrice.String()).To(Equal("7"))
+	})
+
+	It("should bind the options to the context", func() {
+		c, stop := context.WithCancel(ctx)
+		opts, cancel, err := session.NewOptsBuilder(signer).Build(c)
+		Expect(err).ToNot(HaveOccurred())
+		defer cancel()
+		stop()
+		Expect(opts.Context.Err()).To(Equal(context.Canceled))
+	})
+
+	It("should use the signer set last", func() {
+		other := &bind.TransactOpts{From: common.HexToAddress("0x2"), Signer: signer.Signer, GasLimit: 1}
+		opts, cancel, err := session.NewOptsBuilder(signer).Signer(other).Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		defer cancel()
+		Expect(opts.From).To(Equal(other.From))
+		Expect(opts.GasLimit).To(BeZero())
+	})
+
+	It("should return the errors of the sources", func() {
+		s := &sources{err: errors.New("down")}
+		_, _, err := session.NewOptsBuilder(signer).GasPrices(s).Build(ctx)
+		Expect(err).To(MatchError(ContainSubstring("down")))
+	})
+
+	invalid := []struct {
+		name   string
+		build  func(b *session.OptsBuilder) *session.OptsBuilder
+		reason string
+	}{
+		{"without a signer", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return session.NewOptsBuilder(nil)
+		}, "no signer"},
+		{"with a negative value", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return b.Value(big.NewInt(-1))
+		}, "negative value"},
+		{"with a passed deadline", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return b.Deadline(time.Now().Add(-time.Second))
+		}, "has passed"},
+		{"in a loop without a gas limit", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return b.Loop()
+		}, "no gas limit"},
+		{"in a loop with a nonce", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return b.Loop().GasLimit(100000).Nonce(1)
+		}, "a nonce"},
+		{"in a loop with a nonce source", func(b *session.OptsBuilder) *session.OptsBuilder {
+			return b.Loop().GasLimit(100000).Nonces(&sources{})
+		}, "a nonce"},
+	}
+	for _, c := range invalid {
+		c := c
+		It("should reject the options "+c.name, func() {
+			_, _, err := c.build(session.NewOptsBuilder(signer)).Build(ctx)
+			Expect(errors.Cause(err)).To(Equal(session.ErrInvalidOpts))
+			Expect(err).To(MatchError(ContainSubstring(c.reason)))
+		})
+	}
+
+	It("should accept the options of a loop with a gas limit", func() {
+		_, cancel, err := session.NewOptsBuilder(signer).Loop().GasLimit(100000).Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+	})
+})
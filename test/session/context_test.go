@@ -0,0 +1,121 @@
+package session_test
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/session"
+)
+
+type contextKey struct{}
+
+// node records the context and the block of the calls it receives, returning
+// a uint256 of 10.
+type node struct {
+	ctx   context.Context
+	block *big.Int
+	from  common.Address
+}
+
+func (n *node) CodeAt(ctx context.Context, contract common.Address, block *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (n *node) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	n.ctx, n.block, n.from = ctx, block, call.From
+	return common.LeftPadBytes([]byte{10}, 32), nil
+}
+
+var _ = Describe("Session contexts", func() {
+
+	var s *session.Session
+
+	BeforeEach(func() {
+		s = session.New(&bind.TransactOpts{
+			From:     common.HexToAddress("0x1"),
+			GasLimit: 100000,
+		}, &bind.CallOpts{BlockNumber: big.NewInt(7)})
+	})
+
+	It("should hand the context of each call to the node", func() {
+		n := &node{}
+		licence, err := bindings.NewLicenceCaller(common.HexToAddress("0x2"), n)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx := context.WithValue(context.Background(), contextKey{}, "first")
+		amount, err := licence.LicenceAmountScaled(s.Call(ctx))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amount.String()).To(Equal("10"))
+		Expect(n.ctx.Value(contextKey{})).To(Equal("first"))
+		Expect(n.block.String()).To(Equal("7"))
+
+		ctx = context.WithValue(context.Background(), contextKey{}, "second")
+		_, err = licence.LicenceAmountScaled(s.Call(ctx, session.AtBlock(big.NewInt(9)), session.CallFrom(common.HexToAddress("0x3"))))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n.ctx.Value(contextKey{})).To(Equal("second"))
+		Expect(n.block.String()).To(Equal("9"))
+		Expect(n.from).To(Equal(common.HexToAddress("0x3")))
+	})
+
+	It("should keep the overrides to their call", func() {
+		opts := s.Call(context.Background(), session.Pending())
+		Expect(opts.Pending).To(BeTrue())
+		Expect(opts.BlockNumber).To(BeNil())
+		Expect(s.CallOpts().BlockNumber.String()).To(Equal("7"))
+		Expect(s.CallOpts().Context).To(BeNil())
+	})
+
+	It("should override the options of a transaction", func() {
+		value := big.NewInt(5)
+		override := session.WithValue(value)
+		value.SetInt64(6)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		opts := s.Transact(ctx, override, session.WithNonce(big.NewInt(3)), session.WithGasPrice(big.NewInt(2)), session.WithGasLimit(0))
+		Expect(opts.Context.Err()).To(Equal(context.Canceled))
+		Expect(opts.From).To(Equal(common.HexToAddress("0x1")))
+		Expect(opts.Value.String()).To(Equal("5"))
+		Expect(opts.Nonce.String()).To(Equal("3"))
+		Expect(opts.GasPrice.String()).To(Equal("2"))
+		Expect(opts.GasLimit).To(BeZero())
+
+		opts.Value.SetInt64(7)
+		Expect(s.Transact(context.Background(), override).Value.String()).To(Equal("5"))
+		Expect(s.TransactOpts().Value).To(BeNil())
+		Expect(s.TransactOpts().GasLimit).To(Equal(uint64(100000)))
+	})
+
+	It("should sign the transactions with their context", func() {
+		var signedWith context.Context
+		s.SignWith(func(ctx context.Context) bind.SignerFn {
+			return func(_ types.Signer, _ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				signedWith = ctx
+				return tx, nil
+			}
+		})
+		tx := types.NewTransaction(0, common.HexToAddress("0x2"), nil, 0, nil, nil)
+
+		ctx := context.WithValue(context.Background(), contextKey{}, "first")
+		opts := s.Transact(ctx)
+		_, err := opts.Signer(nil, opts.From, tx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signedWith.Value(contextKey{})).To(Equal("first"))
+
+		ctx = context.WithValue(context.Background(), contextKey{}, "second")
+		opts = s.Transact(ctx)
+		_, err = opts.Signer(nil, opts.From, tx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signedWith.Value(contextKey{})).To(Equal("second"))
+
+		s.SignWith(nil)
+		Expect(s.Transact(ctx).Signer).To(BeNil())
+	})
+})
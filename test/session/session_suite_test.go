@@ -0,0 +1,15 @@
+package session_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// These specs are meant to be run with the race detector enabled:
+//   go test -race ./test/session/...
+func TestSessionSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Session Suite")
+}
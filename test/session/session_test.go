@@ -0,0 +1,83 @@
+package session_test
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/session"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Session", func() {
+
+	var s *session.Session
+
+	BeforeEach(func() {
+		s = session.New(&bind.TransactOpts{
+			From:     common.HexToAddress("0x1"),
+			GasPrice: GweiToWei(1),
+			GasLimit: 100000,
+		}, nil)
+	})
+
+	It("should not expose mutations of returned transact options", func() {
+		opts := s.TransactOpts()
+		opts.GasLimit = 1
+		opts.GasPrice.SetInt64(1)
+		Expect(s.TransactOpts().GasLimit).To(Equal(uint64(100000)))
+		Expect(s.TransactOpts().GasPrice.String()).To(Equal(GweiToWei(1).String()))
+	})
+
+	It("should not expose mutations of returned call options", func() {
+		s.UpdateCallOpts(func(o *bind.CallOpts) {
+			o.BlockNumber = big.NewInt(10)
+		})
+		opts := s.CallOpts()
+		opts.BlockNumber.SetInt64(1)
+		Expect(s.CallOpts().BlockNumber.String()).To(Equal("10"))
+	})
+
+	It("should not share the options passed to the constructor", func() {
+		opts := &bind.TransactOpts{Value: big.NewInt(5)}
+		s := session.New(opts, nil)
+		opts.Value.SetInt64(6)
+		Expect(s.TransactOpts().Value.String()).To(Equal("5"))
+	})
+
+	When("the options are updated concurrently with readers", func() {
+
+		BeforeEach(func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					s.UpdateTransactOpts(func(o *bind.TransactOpts) {
+						o.GasLimit++
+						o.GasPrice.Add(o.GasPrice, big.NewInt(1))
+					})
+				}()
+				go func() {
+					defer wg.Done()
+					opts := s.TransactOpts()
+					opts.Nonce = big.NewInt(1)
+					opts.GasPrice.SetInt64(0)
+				}()
+			}
+			wg.Wait()
+		})
+
+		It("should apply every update exactly once", func() {
+			Expect(s.TransactOpts().GasLimit).To(Equal(uint64(100050)))
+			Expect(s.TransactOpts().GasPrice.String()).To(Equal("1000000050"))
+		})
+
+		It("should not leak reader mutations into the session", func() {
+			Expect(s.TransactOpts().Nonce).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+	"github.com/tokencard/ethertest"
+)
+
+// The mining modes of a TestChain.
+const (
+	MineManually  = testutil.MineManually
+	MineOnSend    = testutil.MineOnSend
+	MineOnReceipt = testutil.MineOnReceipt
+)
+
+// TestChain is the chain of package testutil over the test backend, which
+// adds the HeaderByNumber method required by the indexer and the services
+// built on it, reporting the block of the last transaction mined through it
+// as the head.
+type TestChain struct {
+	*testutil.Chain
+}
+
+// NewTestChain returns a chain over the backend, at block 0 until a
+// transaction is mined through it.
+func NewTestChain(backend ethertest.TestBackend, mining testutil.Mining) *TestChain {
+	return &TestChain{Chain: testutil.NewChain(backend, mining)}
+}
+
+// CommitTx mines the transaction, unless it was already, checks that it
+// succeeded and moves the head of the chain to its block. It takes the
+// results of a binding call as is.
+func (c *TestChain) CommitTx(tx *types.Transaction, err error) {
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	r, err := c.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	if r == nil && err == nil {
+		c.Commit()
+		r, err = c.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	}
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	ExpectWithOffset(1, r.Status).To(Equal(types.ReceiptStatusSuccessful))
+	c.MoveHead(r.BlockNumber)
+}
+
+// FailingChain is a test chain failing to send the transactions once it sent
+// Left of them, never when Left is negative.
+type FailingChain struct {
+	*TestChain
+	Left int
+}
+
+func (f *FailingChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if f.Left == 0 {
+		return errors.New("node down")
+	}
+	f.Left--
+	return f.TestChain.SendTransaction(ctx, tx)
+}
+
+// RegisterName points an ENS name of tokencard.eth to an address.
+func (c *TestChain) RegisterName(label string, address common.Address) {
+	node := EnsNode(label + ".tokencard.eth")
+	c.CommitTx(ENSRegistry.SetSubnodeOwner(BankAccount.TransactOpts(), EnsNode("tokencard.eth"), LabelHash(label), BankAccount.Address()))
+	c.CommitTx(ENSRegistry.SetResolver(BankAccount.TransactOpts(), node, ENSResolverAddress))
+	c.CommitTx(ENSResolver.SetAddr(BankAccount.TransactOpts(), node, address))
+}
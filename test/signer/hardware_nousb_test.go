@@ -0,0 +1,19 @@
+//go:build !cgo || nousb
+// +build !cgo nousb
+
+package signer_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+var _ = Describe("OpenHardwareWallet without USB support", func() {
+
+	It("should report that hardware wallets are not supported", func() {
+		w, err := signer.OpenHardwareWallet(signer.HardwareConfig{Kind: "ledger"})
+		Expect(err).To(Equal(signer.ErrHardwareWalletUnsupported))
+		Expect(w).To(BeNil())
+	})
+})
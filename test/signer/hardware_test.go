@@ -0,0 +1,45 @@
+package signer_test
+
+import (
+	"github.com/ethereum/go-ethereum/accounts"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+const hardened = 0x80000000
+
+var _ = Describe("ParseDerivationPath", func() {
+
+	DescribeTable("should parse the valid paths",
+		func(path string, expected accounts.DerivationPath) {
+			p, err := signer.ParseDerivationPath(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).To(Equal(expected))
+		},
+		Entry("the default path when empty", "", signer.DefaultDerivationPath),
+		Entry("an absolute path", "m/44'/60'/0'/0/1", accounts.DerivationPath{hardened + 44, hardened + 60, hardened, 0, 1}),
+		Entry("a hardened account", "m/44'/60'/3'/0/0", accounts.DerivationPath{hardened + 44, hardened + 60, hardened + 3, 0, 0}),
+		Entry("the hardened components only", "m/44'/60'/0'", accounts.DerivationPath{hardened + 44, hardened + 60, hardened}),
+		Entry("the largest hardened component", "m/44'/60'/2147483647'", accounts.DerivationPath{hardened + 44, hardened + 60, hardened + 2147483647}),
+		Entry("a relative path, from m/44'/60'/0'/0", "2", accounts.DerivationPath{hardened + 44, hardened + 60, hardened, 0, 2}),
+		Entry("spaces around the components", "m / 44' / 60' / 0' / 0 / 0", signer.DefaultDerivationPath),
+	)
+
+	DescribeTable("should reject the invalid paths",
+		func(path, message string) {
+			_, err := signer.ParseDerivationPath(path)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+			Expect(err).To(MatchError(ContainSubstring(path)))
+		},
+		Entry("a path without components", "m", "empty derivation path"),
+		Entry("an ambiguous leading slash", "/44'/60'", "ambiguous path"),
+		Entry("a component which is not a number", "m/44'/60'/x", "invalid component"),
+		Entry("an empty component", "m/44'//0", "invalid component"),
+		Entry("a doubly hardened component", "m/44''/60'", "invalid component"),
+		Entry("a negative component", "m/44'/-1", "out of allowed range"),
+		Entry("a component above 2^32-1", "m/44'/4294967296", "out of allowed range"),
+		Entry("a hardened component above 2^31-1", "m/44'/2147483648'", "out of allowed hardened range"),
+	)
+})
@@ -0,0 +1,298 @@
+package signer_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+)
+
+// localKMS signs with a local key, encoding the public key and signatures as
+// the KMS APIs do.
+type localKMS struct {
+	key *ecdsa.PrivateKey
+	// highS returns the signatures with S in the upper half of the curve order.
+	highS bool
+	// ctx is the context of the last signature.
+	ctx context.Context
+}
+
+func (k *localKMS) PublicKey(ctx context.Context) ([]byte, error) {
+	type algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.ObjectIdentifier
+	}
+	pub := crypto.FromECDSAPub(&k.key.PublicKey)
+	return asn1.Marshal(struct {
+		Algorithm algorithm
+		PublicKey asn1.BitString
+	}{
+		algorithm{asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, asn1.ObjectIdentifier{1, 3, 132, 0, 10}},
+		asn1.BitString{Bytes: pub, BitLength: len(pub) * 8},
+	})
+}
+
+func (k *localKMS) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	k.ctx = ctx
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sig, err := crypto.Sign(digest, k.key)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if k.highS {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+func newLocalKMS() *localKMS {
+	key, err := crypto.GenerateKey()
+	Expect(err).ToNot(HaveOccurred())
+	return &localKMS{key: key}
+}
+
+var chainID = big.NewInt(1337)
+
+func newTransaction() *types.Transaction {
+	return types.NewTransaction(3, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), nil)
+}
+
+func expectSignedBy(tx *types.Transaction, address common.Address) {
+	Expect(tx.Protected()).To(BeTrue())
+	from, err := types.Sender(types.NewEIP155Signer(chainID), tx)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(from).To(Equal(address))
+}
+
+var _ = Describe("NewKMSTransactOpts", func() {
+
+	var kms *localKMS
+
+	BeforeEach(func() {
+		kms = newLocalKMS()
+	})
+
+	It("should use the address of the KMS key", func() {
+		opts, err := signer.NewKMSTransactOpts(context.Background(), kms, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.From).To(Equal(crypto.PubkeyToAddress(kms.key.PublicKey)))
+	})
+
+	It("should sign replay protected transactions", func() {
+		opts, err := signer.NewKMSTransactOpts(context.Background(), kms, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := opts.Signer(types.NewEIP155Signer(chainID), opts.From, newTransaction())
+		Expect(err).ToNot(HaveOccurred())
+		expectSignedBy(tx, opts.From)
+	})
+
+	It("should normalize signatures with a high S", func() {
+		kms.highS = true
+		opts, err := signer.NewKMSTransactOpts(context.Background(), kms, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 8; i++ {
+			tx, err := opts.Signer(types.NewEIP155Signer(chainID), opts.From, newTransaction())
+			Expect(err).ToNot(HaveOccurred())
+			expectSignedBy(tx, opts.From)
+		}
+	})
+
+	It("should not sign for another address", func() {
+		opts, err := signer.NewKMSTransactOpts(context.Background(), kms, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = opts.Signer(types.NewEIP155Signer(chainID), common.HexToAddress("0x2"), newTransaction())
+		Expect(err).To(Equal(signer.ErrNotAuthorized))
+	})
+
+	It("should reject a key on another curve", func() {
+		type algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.ObjectIdentifier
+		}
+		p256 := asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+		der, err := asn1.Marshal(struct {
+			Algorithm algorithm
+			PublicKey asn1.BitString
+		}{
+			algorithm{asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, p256},
+			asn1.BitString{Bytes: []byte{4}, BitLength: 8},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = signer.NewKMSTransactOpts(context.Background(), fixedPublicKey(der), chainID)
+		Expect(err).To(MatchError(ContainSubstring("not secp256k1")))
+	})
+})
+
+var _ = Describe("KMSSigner", func() {
+
+	var kms *localKMS
+	var s *signer.KMSSigner
+
+	BeforeEach(func() {
+		kms = newLocalKMS()
+		var err error
+		s, err = signer.NewKMSSigner(context.Background(), kms, chainID)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should sign with the context of each transaction", func() {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "first")
+		opts := s.TransactOpts(ctx)
+		Expect(opts.From).To(Equal(crypto.PubkeyToAddress(kms.key.PublicKey)))
+		Expect(opts.Context).To(Equal(ctx))
+		tx, err := opts.Signer(types.NewEIP155Signer(chainID), opts.From, newTransaction())
+		Expect(err).ToNot(HaveOccurred())
+		expectSignedBy(tx, s.Address())
+		Expect(kms.ctx.Value(key{})).To(Equal("first"))
+
+		ctx = context.WithValue(context.Background(), key{}, "second")
+		_, err = s.SignerFn(ctx)(types.NewEIP155Signer(chainID), s.Address(), newTransaction())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kms.ctx.Value(key{})).To(Equal("second"))
+	})
+
+	It("should not sign once the context of the transaction is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.SignerFn(ctx)(types.NewEIP155Signer(chainID), s.Address(), newTransaction())
+		Expect(errors.Cause(err)).To(Equal(context.Canceled))
+	})
+})
+
+type fixedPublicKey []byte
+
+func (f fixedPublicKey) PublicKey(ctx context.Context) ([]byte, error) { return f, nil }
+func (f fixedPublicKey) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	return nil, nil
+}
+
+var _ = Describe("AWSKMS", func() {
+
+	var kms *localKMS
+	var srv *httptest.Server
+	var targets []string
+
+	BeforeEach(func() {
+		kms = newLocalKMS()
+		targets = nil
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/kms/aws4_request") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			target := r.Header.Get("X-Amz-Target")
+			targets = append(targets, target)
+
+			var req struct {
+				KeyId       string
+				Message     []byte
+				MessageType string
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			Expect(req.KeyId).To(Equal("alias/operator"))
+
+			switch target {
+			case "TrentService.GetPublicKey":
+				der, _ := kms.PublicKey(r.Context())
+				json.NewEncoder(w).Encode(map[string]interface{}{"PublicKey": der})
+			case "TrentService.Sign":
+				Expect(req.MessageType).To(Equal("DIGEST"))
+				sig, _ := kms.Sign(r.Context(), req.Message)
+				json.NewEncoder(w).Encode(map[string]interface{}{"Signature": sig})
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("should sign with signed API requests", func() {
+		aws := &signer.AWSKMS{
+			Region:      "eu-west-1",
+			KeyID:       "alias/operator",
+			Credentials: signer.AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+			Endpoint:    srv.URL,
+		}
+		opts, err := signer.NewKMSTransactOpts(context.Background(), aws, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := opts.Signer(types.NewEIP155Signer(chainID), opts.From, newTransaction())
+		Expect(err).ToNot(HaveOccurred())
+		expectSignedBy(tx, crypto.PubkeyToAddress(kms.key.PublicKey))
+		Expect(targets).To(Equal([]string{"TrentService.GetPublicKey", "TrentService.Sign"}))
+	})
+
+	It("should report the API errors", func() {
+		aws := &signer.AWSKMS{Region: "us-east-1", KeyID: "alias/operator", Endpoint: srv.URL}
+		_, err := signer.NewKMSTransactOpts(context.Background(), aws, chainID)
+		Expect(err).To(MatchError(ContainSubstring("403")))
+	})
+})
+
+var _ = Describe("GCPKMS", func() {
+
+	It("should sign through the REST API", func() {
+		kms := newLocalKMS()
+		const version = "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/"+version+"/publicKey":
+				der, _ := kms.PublicKey(r.Context())
+				json.NewEncoder(w).Encode(map[string]string{
+					"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				})
+			case r.Method == http.MethodPost && r.URL.Path == "/"+version+":asymmetricSign":
+				var req struct {
+					Digest struct {
+						SHA256 string `json:"sha256"`
+					} `json:"digest"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+				digest, err := base64.StdEncoding.DecodeString(req.Digest.SHA256)
+				Expect(err).ToNot(HaveOccurred())
+				sig, _ := kms.Sign(r.Context(), digest)
+				json.NewEncoder(w).Encode(map[string][]byte{"signature": sig})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+
+		gcp := &signer.GCPKMS{
+			KeyVersion: version,
+			Endpoint:   srv.URL + "/",
+			Token:      func(context.Context) (string, error) { return "token", nil },
+		}
+		opts, err := signer.NewKMSTransactOpts(context.Background(), gcp, chainID)
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := opts.Signer(types.NewEIP155Signer(chainID), opts.From, newTransaction())
+		Expect(err).ToNot(HaveOccurred())
+		expectSignedBy(tx, crypto.PubkeyToAddress(kms.key.PublicKey))
+	})
+})
@@ -0,0 +1,118 @@
+package signing_test
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tokencard/contracts/v2/pkg/signing"
+)
+
+var _ = Describe("TypedData", func() {
+
+	// mail is the example message of the EIP-712 specification.
+	var mail signing.TypedData
+
+	BeforeEach(func() {
+		contract := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+		mail = signing.TypedData{
+			Types: signing.Types{
+				"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+				"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+			},
+			PrimaryType: "Mail",
+			Domain: signing.Domain{
+				Name:              "Ether Mail",
+				Version:           "1",
+				ChainID:           big.NewInt(1),
+				VerifyingContract: &contract,
+			},
+			Message: map[string]interface{}{
+				"from":     map[string]interface{}{"name": "Cow", "wallet": common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+				"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+				"contents": "Hello, Bob!",
+			},
+		}
+	})
+
+	It("should encode the referenced types", func() {
+		encoded, err := mail.Types.EncodeType("Mail")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoded).To(Equal("Mail(Person from,Person to,string contents)Person(string name,address wallet)"))
+	})
+
+	It("should hash the domain and the message as specified", func() {
+		domain, err := mail.DomainSeparator()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(domain).To(Equal(common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f")))
+
+		message, err := mail.Types.HashStruct("Mail", mail.Message)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message).To(Equal(common.HexToHash("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")))
+
+		hash, err := mail.Hash()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash).To(Equal(common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")))
+	})
+
+	It("should produce the signature of the specification", func() {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+		Expect(err).ToNot(HaveOccurred())
+
+		sig, err := mail.Sign(signing.KeySigner(key))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(common.Bytes2Hex(sig[:32])).To(Equal("4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"))
+		Expect(common.Bytes2Hex(sig[32:64])).To(Equal("07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"))
+		Expect(sig[64]).To(Equal(byte(28)))
+
+		Expect(mail.Verify(sig, crypto.PubkeyToAddress(key.PublicKey))).To(Succeed())
+	})
+
+	It("should reject the signature of another message", func() {
+		key, err := crypto.GenerateKey()
+		Expect(err).ToNot(HaveOccurred())
+		sig, err := mail.Sign(signing.KeySigner(key))
+		Expect(err).ToNot(HaveOccurred())
+
+		mail.Message["contents"] = "Hello, Alice!"
+		err = mail.Verify(sig, crypto.PubkeyToAddress(key.PublicKey))
+		Expect(err).To(MatchError(ContainSubstring(signing.ErrInvalidSignature.Error())))
+	})
+
+	It("should hash arrays of structs", func() {
+		mail.Types["Group"] = []signing.Field{{Name: "members", Type: "Person[]"}, {Name: "ids", Type: "uint32[2]"}}
+		mail.PrimaryType = "Group"
+		mail.Message = map[string]interface{}{
+			"members": []interface{}{mail.Message["from"], mail.Message["to"]},
+			"ids":     []uint32{1, 2},
+		}
+		encoded, err := mail.Types.EncodeType("Group")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoded).To(Equal("Group(Person[] members,uint32[2] ids)Person(string name,address wallet)"))
+		_, err = mail.Hash()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject missing fields", func() {
+		delete(mail.Message, "contents")
+		_, err := mail.Hash()
+		Expect(err).To(MatchError(ContainSubstring("Mail.contents is not set")))
+	})
+
+	It("should reject values overflowing their type", func() {
+		mail.Types["Amount"] = []signing.Field{{Name: "value", Type: "uint8"}}
+		_, err := mail.Types.HashStruct("Amount", map[string]interface{}{"value": 256})
+		Expect(err).To(MatchError(ContainSubstring("overflows uint8")))
+		_, err = mail.Types.HashStruct("Amount", map[string]interface{}{"value": 255})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject a declared EIP712Domain type", func() {
+		mail.Types["EIP712Domain"] = []signing.Field{{Name: "name", Type: "string"}}
+		_, err := mail.Hash()
+		Expect(err).To(HaveOccurred())
+	})
+})
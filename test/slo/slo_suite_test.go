@@ -0,0 +1,18 @@
+package slo_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSLOSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SLO Suite")
+}
+
+var _ = BeforeSuite(func() {
+	metrics.Enabled = true
+})
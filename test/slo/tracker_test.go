@@ -0,0 +1,125 @@
+package slo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tokencard/contracts/v2/pkg/slo"
+)
+
+var _ = Describe("Tracker", func() {
+
+	var tracker *slo.Tracker
+
+	BeforeEach(func() {
+		tracker = slo.NewTracker(slo.Objective{Name: "indexer_lag", Target: 0.99, Window: 24 * time.Hour})
+	})
+
+	When("no events were recorded", func() {
+		It("should be compliant", func() {
+			Expect(tracker.Compliance(24 * time.Hour)).To(Equal(1.0))
+			Expect(tracker.ErrorBudgetRemaining()).To(BeNumerically("~", 1))
+			Expect(tracker.FastBurn()).To(BeFalse())
+		})
+	})
+
+	When("events were recorded over the last hours", func() {
+		BeforeEach(func() {
+			now := time.Now()
+			for i := 0; i < 99; i++ {
+				tracker.RecordAt(now.Add(-3*time.Hour), true)
+			}
+			tracker.RecordAt(now.Add(-3*time.Hour), false)
+		})
+
+		It("should compute the compliance over the window", func() {
+			Expect(tracker.Compliance(24 * time.Hour)).To(BeNumerically("~", 0.99))
+			Expect(tracker.Compliance(time.Hour)).To(Equal(1.0))
+		})
+
+		It("should have consumed the whole error budget", func() {
+			Expect(tracker.ErrorBudgetRemaining()).To(BeNumerically("~", 0, 1e-9))
+		})
+
+		It("should ignore events older than the window", func() {
+			tracker.RecordAt(time.Now().Add(-25*time.Hour), false)
+			Expect(tracker.Status().Total).To(Equal(uint64(100)))
+		})
+	})
+
+	When("the recent events are bad", func() {
+		BeforeEach(func() {
+			for i := 0; i < 10; i++ {
+				tracker.Record(false)
+			}
+		})
+
+		It("should burn the error budget fast", func() {
+			Expect(tracker.BurnRate(5 * time.Minute)).To(BeNumerically("~", 100))
+			Expect(tracker.FastBurn()).To(BeTrue())
+			Expect(tracker.SlowBurn()).To(BeTrue())
+		})
+
+		It("should report the status", func() {
+			s := tracker.Status()
+			Expect(s.Objective).To(Equal("indexer_lag"))
+			Expect(s.Total).To(Equal(uint64(10)))
+			Expect(s.BurnRates).To(HaveKey("5m"))
+			Expect(s.BurnRates).To(HaveKey("6h"))
+			Expect(s.FastBurn).To(BeTrue())
+		})
+
+		It("should publish the burn rate alerts as metrics", func() {
+			registry := metrics.NewRegistry()
+			tracker.Register(registry)
+			Expect(registry.Get("slo/indexer_lag/fast_burn").(metrics.Gauge).Value()).To(Equal(int64(1)))
+			Expect(registry.Get("slo/indexer_lag/compliance").(metrics.GaugeFloat64).Value()).To(Equal(0.0))
+		})
+
+		It("should serve the status", func() {
+			w := httptest.NewRecorder()
+			slo.Handler(tracker).ServeHTTP(w, httptest.NewRequest("GET", "/slo", nil))
+			var statuses []slo.Status
+			Expect(json.Unmarshal(w.Body.Bytes(), &statuses)).To(Succeed())
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].Total).To(Equal(uint64(10)))
+		})
+	})
+
+	When("latencies are recorded", func() {
+		It("should count the latencies above the threshold as bad", func() {
+			tracker.RecordLatency(30*time.Minute, time.Hour)
+			tracker.RecordLatency(2*time.Hour, time.Hour)
+			Expect(tracker.Compliance(time.Hour)).To(Equal(0.5))
+		})
+	})
+})
+
+var _ = Describe("Probe", func() {
+
+	var tracker *slo.Tracker
+
+	BeforeEach(func() {
+		tracker = slo.NewTracker(slo.Objective{Name: "probe", Target: 0.9, Window: time.Hour})
+	})
+
+	It("should record the result of the check", func() {
+		p := &slo.Probe{Tracker: tracker, Check: func(context.Context) (bool, error) { return true, nil }}
+		p.Sample(context.Background())
+		Expect(tracker.Status().Good).To(Equal(uint64(1)))
+	})
+
+	It("should count a failing check as a bad event", func() {
+		p := &slo.Probe{Tracker: tracker, Check: func(context.Context) (bool, error) { return true, errors.New("node down") }}
+		p.Sample(context.Background())
+		Expect(tracker.Status().Total).To(Equal(uint64(1)))
+		Expect(tracker.Status().Good).To(BeZero())
+	})
+})
@@ -0,0 +1,87 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Compare", func() {
+
+	ctx := context.Background()
+	var contracts snapshot.Contracts
+
+	BeforeEach(func() {
+		contracts = snapshot.Contracts{Licence: LicenceAddress, WalletCache: WalletCacheAddress, WalletDeployer: WalletDeployerAddress}
+		Chain.CommitTx(WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address()))
+	})
+
+	It("reports the wallets deployed and activated and the parameters changed between two blocks", func() {
+		before, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+
+		first, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		w, err := bindings.NewWallet(first, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		Chain.CommitTx(w.SetWhitelist(Owner.TransactOpts(), []common.Address{RandomAccount.Address()}))
+		Chain.CommitTx(WalletDeployer.DeployWallet(Controller.TransactOpts(), RandomAccount.Address()))
+		Chain.CommitTx(Licence.UpdateFloat(ControllerAdmin.TransactOpts(), TokenHolderAddress))
+
+		after, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		d, err := snapshot.Compare(before, after)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.FromBlock).To(Equal(before.Block))
+		Expect(d.ToBlock).To(Equal(after.Block))
+		Expect(d.Deployed).To(HaveLen(1))
+		Expect(d.Deployed[0].FirstOwner).To(Equal(RandomAccount.Address()))
+		Expect(d.Activated).To(HaveLen(1))
+		Expect(d.Activated[0].Address).To(Equal(first))
+		Expect(d.OwnerChanges).To(BeEmpty())
+		Expect(d.Removed).To(BeEmpty())
+		Expect(d.Parameters).To(Equal([]snapshot.ParameterChange{{Contract: "licence", Name: "crypto_float", From: CryptoFloatAddress.Hex(), To: TokenHolderAddress.Hex()}}))
+
+		var table bytes.Buffer
+		Expect(snapshot.WriteDiffCSV(&table, d)).To(Succeed())
+		rows, err := csv.NewReader(&table).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(4))
+		Expect(rows[1][0]).To(Equal("deployed"))
+		Expect(rows[2]).To(Equal([]string{"activated", first.Hex(), "whitelist_set", "false", "true"}))
+		Expect(rows[3]).To(Equal([]string{"parameter", "licence", "crypto_float", CryptoFloatAddress.Hex(), TokenHolderAddress.Hex()}))
+	})
+
+	It("compares the snapshots read from their JSON documents", func() {
+		s, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		var doc bytes.Buffer
+		Expect(snapshot.WriteJSON(&doc, s)).To(Succeed())
+		read, err := snapshot.ReadJSON(&doc)
+		Expect(err).ToNot(HaveOccurred())
+
+		d, err := snapshot.Compare(read, s)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.Empty()).To(BeTrue())
+	})
+
+	It("reports the owner changes and the wallets removed", func() {
+		a, b, c := common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xc")
+		from := &snapshot.Snapshot{Block: 1, Wallets: []snapshot.Wallet{{Address: a, Owner: b}, {Address: c, Owner: b}}}
+		to := &snapshot.Snapshot{Block: 2, Wallets: []snapshot.Wallet{{Address: a, Owner: c}}}
+		d, err := snapshot.Compare(from, to)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.OwnerChanges).To(Equal([]snapshot.OwnerChange{{Wallet: a, From: b, To: c}}))
+		Expect(d.Removed).To(Equal([]snapshot.Wallet{{Address: c, Owner: b}}))
+
+		_, err = snapshot.Compare(to, from)
+		Expect(err).To(MatchError(ContainSubstring("after snapshot of block 1")))
+	})
+})
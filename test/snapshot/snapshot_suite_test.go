@@ -0,0 +1,44 @@
+package snapshot_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestSnapshotSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Snapshot Suite")
+}
+
+var Chain *TestChain
+
+var WalletCacheAddress common.Address
+var WalletCache *bindings.WalletCache
+
+var WalletDeployerAddress common.Address
+var WalletDeployer *bindings.WalletDeployer
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Chain = NewTestChain(Backend, MineManually)
+
+	WalletCacheAddress, _, WalletCache, err = bindings.DeployWalletCache(BankAccount.TransactOpts(), Backend, ENSRegistryAddress, EthToWei(1), [32]byte{}, [32]byte{}, [32]byte{}, [32]byte{})
+	Expect(err).ToNot(HaveOccurred())
+	WalletDeployerAddress, _, WalletDeployer, err = bindings.DeployWalletDeployer(BankAccount.TransactOpts(), Backend, ENSRegistryAddress, [32]byte{}, [32]byte{})
+	Expect(err).ToNot(HaveOccurred())
+	Backend.Commit()
+
+	Chain.RegisterName("wallet-deployer", WalletDeployerAddress)
+	Chain.RegisterName("wallet-cache", WalletCacheAddress)
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
@@ -0,0 +1,103 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/snapshot"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Take", func() {
+
+	ctx := context.Background()
+	var contracts snapshot.Contracts
+	var first, second common.Address
+
+	BeforeEach(func() {
+		contracts = snapshot.Contracts{
+			Licence:        LicenceAddress,
+			TokenWhitelist: TokenWhitelistAddress,
+			WalletCache:    WalletCacheAddress,
+			WalletDeployer: WalletDeployerAddress,
+		}
+
+		Chain.CommitTx(WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address()))
+		Chain.CommitTx(WalletDeployer.DeployWallet(Controller.TransactOpts(), RandomAccount.Address()))
+		Chain.CommitTx(WalletCache.CacheWallet(RandomAccount.TransactOpts()))
+
+		var err error
+		first, err = WalletDeployer.DeployedWallets(nil, Owner.Address())
+		Expect(err).ToNot(HaveOccurred())
+		second, err = WalletDeployer.DeployedWallets(nil, RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+
+		w, err := bindings.NewWallet(second, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		Chain.CommitTx(w.SetWhitelist(RandomAccount.TransactOpts(), []common.Address{Owner.Address()}))
+	})
+
+	It("reads the wallets and the parameters of the contracts at the block", func() {
+		s, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Block).To(Equal(Chain.Head().Uint64()))
+
+		Expect(s.Wallets).To(HaveLen(2))
+		Expect(s.Wallets[0].Address).To(Equal(first))
+		Expect(s.Wallets[0].FirstOwner).To(Equal(Owner.Address()))
+		Expect(s.Wallets[0].Owner).To(Equal(Owner.Address()))
+		Expect(s.Wallets[0].Transferable).To(BeFalse())
+		Expect(s.Wallets[0].WhitelistSet).To(BeFalse())
+		Expect(s.Wallets[1].Address).To(Equal(second))
+		Expect(s.Wallets[1].Owner).To(Equal(RandomAccount.Address()))
+		Expect(s.Wallets[1].WhitelistSet).To(BeTrue())
+		Expect(s.Wallets[1].SpendLimit).To(Equal(EthToWei(1).String()))
+		Expect(s.Totals).To(Equal(snapshot.Totals{Deployed: 2, Activated: 1, Cached: 1, Tokens: len(s.Tokens)}))
+
+		Expect(s.WalletCache.Cached).To(HaveLen(1))
+		Expect(s.WalletCache.DefaultSpendLimit).To(Equal(EthToWei(1).String()))
+		Expect(s.Licence.CryptoFloat).To(Equal(CryptoFloatAddress))
+		Expect(s.Licence.TokenHolder).To(Equal(TokenHolderAddress))
+		Expect(s.Licence.AmountScaled).To(Equal("10"))
+		Expect(s.Tokens).ToNot(BeEmpty())
+	})
+
+	It("leaves out the wallets deployed before the start block", func() {
+		s, err := snapshot.Take(ctx, Chain, snapshot.Contracts{WalletDeployer: WalletDeployerAddress}, snapshot.Options{StartBlock: Chain.Head().Uint64()})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Wallets).To(BeEmpty())
+		Expect(s.Licence).To(BeNil())
+		Expect(s.WalletCache).To(BeNil())
+	})
+
+	It("rejects a block before the start block", func() {
+		_, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{StartBlock: 100, Block: 99})
+		Expect(err).To(MatchError(ContainSubstring("before start block")))
+	})
+
+	It("writes the snapshot as JSON and the wallets as CSV", func() {
+		s, err := snapshot.Take(ctx, Chain, contracts, snapshot.Options{})
+		Expect(err).ToNot(HaveOccurred())
+
+		var doc bytes.Buffer
+		Expect(snapshot.WriteJSON(&doc, s)).To(Succeed())
+		var decoded snapshot.Snapshot
+		Expect(json.Unmarshal(doc.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded.Wallets).To(Equal(s.Wallets))
+		Expect(decoded.Totals).To(Equal(s.Totals))
+
+		var table bytes.Buffer
+		Expect(snapshot.WriteCSV(&table, s)).To(Succeed())
+		rows, err := csv.NewReader(&table).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(3))
+		Expect(rows[0][:3]).To(Equal([]string{"wallet", "owner", "first_owner"}))
+		Expect(rows[1][:3]).To(Equal([]string{first.Hex(), Owner.Address().Hex(), Owner.Address().Hex()}))
+	})
+})
@@ -0,0 +1,52 @@
+package status_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tokencard/ethertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestStatusSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Status Suite")
+}
+
+// node adds the head and the chain ID of the status to the test backend, or
+// fails them when it is down.
+type node struct {
+	ethertest.TestBackend
+	head uint64
+	down bool
+}
+
+func (n *node) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if n.down {
+		return nil, errors.New("connection refused")
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n.head), Time: 1600000000}, nil
+}
+
+func (n *node) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+var Node *node
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Node = &node{TestBackend: Backend, head: 10}
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
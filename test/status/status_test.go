@@ -0,0 +1,171 @@
+package status_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/signer"
+	"github.com/tokencard/contracts/v2/pkg/status"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Status", func() {
+
+	var store *indexer.MemoryStore
+	var generator *status.Generator
+	ctx := context.Background()
+	payout := common.HexToHash("0x2")
+
+	BeforeEach(func() {
+		store = indexer.NewMemoryStore()
+		Expect(store.Append(7, []indexer.Event{
+			{Contract: "licence", Name: status.PayoutEvent, BlockNumber: 3, TxHash: common.HexToHash("0x1"), Args: map[string]interface{}{"_asset": common.Address{}, "_amount": big.NewInt(5)}},
+			{Contract: "licence", Name: status.PayoutEvent, BlockNumber: 6, TxHash: payout, Args: map[string]interface{}{"_asset": common.Address{}, "_amount": big.NewInt(8), indexer.TransferArg: big.NewInt(3)}},
+		})).To(Succeed())
+		generator = status.NewGenerator(Node, store, LicenceAddress)
+		generator.Now = func() time.Time { return time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC) }
+	})
+
+	It("reads the status of the node, the indexer and the Licence", func() {
+		s := generator.Generate(ctx)
+		Expect(s.Healthy()).To(BeTrue())
+		Expect(s.Chain).To(Equal(status.Chain{Connected: true, ChainID: "1337", HeadBlock: 10, HeadTime: time.Unix(1600000000, 0).UTC()}))
+		Expect(s.Indexer).To(Equal(&status.Indexer{Head: 7, Lag: 3}))
+		index := uint(3)
+		Expect(s.LastPayout).To(Equal(&status.Payout{BlockNumber: 6, TxHash: payout, Asset: common.Address{}.Hex(), Amount: "8", TransferLogIndex: &index}))
+		Expect(s.Licence.Error).To(BeEmpty())
+		Expect(s.Licence.FeePercent).To(Equal("1.0"))
+		Expect(s.Licence.CryptoFloat).To(Equal(CryptoFloatAddress))
+		Expect(s.Licence.TokenHolder).To(Equal(TokenHolderAddress))
+	})
+
+	It("reports the node down without failing", func() {
+		Node.down = true
+		s := generator.Generate(ctx)
+		Expect(s.Healthy()).To(BeFalse())
+		Expect(s.Chain.Connected).To(BeFalse())
+		Expect(s.Chain.Error).To(ContainSubstring("connection refused"))
+		Expect(s.Indexer.Lag).To(BeZero())
+
+		var html bytes.Buffer
+		Expect(status.WriteHTML(&html, s)).To(Succeed())
+		Expect(html.String()).To(ContainSubstring("degraded"))
+		Expect(html.String()).To(ContainSubstring("connection refused"))
+	})
+
+	It("leaves out the sections of the subsystems not configured", func() {
+		s := status.NewGenerator(Node, nil, common.Address{}).Generate(ctx)
+		Expect(s.Indexer).To(BeNil())
+		Expect(s.LastPayout).To(BeNil())
+		Expect(s.Licence).To(BeNil())
+	})
+
+	It("publishes the page to a directory", func() {
+		dir, err := ioutil.TempDir("", "status")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		s, err := status.NewPage(generator, status.DirPublisher{Dir: dir}).Publish(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		doc, err := ioutil.ReadFile(filepath.Join(dir, status.JSONFile))
+		Expect(err).ToNot(HaveOccurred())
+		var published status.Status
+		Expect(json.Unmarshal(doc, &published)).To(Succeed())
+		Expect(published.GeneratedAt).To(Equal(s.GeneratedAt))
+		Expect(published.LastPayout.TxHash).To(Equal(payout))
+
+		html, err := ioutil.ReadFile(filepath.Join(dir, status.HTMLFile))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(html)).To(ContainSubstring("operational"))
+		Expect(string(html)).To(ContainSubstring(payout.Hex() + ", Transfer log 3"))
+	})
+
+	It("uploads the page to S3 with signed requests", func() {
+		var requests []*http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests = append(requests, req)
+		}))
+		defer server.Close()
+
+		pub := &status.S3Publisher{
+			Bucket:      "status",
+			Region:      "eu-west-1",
+			Prefix:      "monolith/",
+			Endpoint:    server.URL,
+			Credentials: signer.AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"},
+		}
+		_, err := status.NewPage(generator, pub).Publish(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Method).To(Equal(http.MethodPut))
+		Expect(requests[0].URL.Path).To(Equal("/status/monolith/status.json"))
+		Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(requests[0].Header.Get("X-Amz-Content-Sha256")).To(HaveLen(64))
+		Expect(requests[0].Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=id/"))
+		Expect(requests[1].URL.Path).To(Equal("/status/monolith/index.html"))
+		Expect(pub.String()).ToNot(ContainSubstring("secret"))
+	})
+
+	It("commits the page to the branch of a GitHub repository", func() {
+		var updates []map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Header.Get("Authorization")).To(Equal("token t"))
+			if req.Method == http.MethodGet {
+				Expect(req.URL.Query().Get("ref")).To(Equal("gh-pages"))
+				if strings.HasSuffix(req.URL.Path, status.JSONFile) {
+					json.NewEncoder(w).Encode(map[string]string{"sha": "abc"})
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			Expect(req.URL.Path).To(HavePrefix("/repos/example/status/contents/monolith/"))
+			var update map[string]string
+			Expect(json.NewDecoder(req.Body).Decode(&update)).To(Succeed())
+			updates = append(updates, update)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		pub := &status.GitHubPublisher{Repo: "example/status", Branch: "gh-pages", Dir: "monolith", Token: "t", Endpoint: server.URL}
+		_, err := status.NewPage(generator, pub).Publish(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updates).To(HaveLen(2))
+		Expect(updates[0]["sha"]).To(Equal("abc"))
+		Expect(updates[0]["branch"]).To(Equal("gh-pages"))
+		doc, err := base64.StdEncoding.DecodeString(updates[0]["content"])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(doc)).To(ContainSubstring(payout.Hex()))
+		Expect(updates[1]).ToNot(HaveKey("sha"))
+	})
+
+	It("tries every publisher and reports the first failure", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		dir, err := ioutil.TempDir("", "status")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		failing := &status.S3Publisher{Bucket: "status", Region: "eu-west-1", Endpoint: server.URL}
+		_, err = status.NewPage(generator, failing, status.DirPublisher{Dir: dir}).Publish(ctx)
+		Expect(err).To(MatchError(ContainSubstring("403")))
+		Expect(filepath.Join(dir, status.HTMLFile)).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,103 @@
+package sweep_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/sweep"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Planner", func() {
+
+	var planner *sweep.Planner
+	var contracts []sweep.Contract
+	coldStorage := common.HexToAddress("0xC01D")
+
+	BeforeEach(func() {
+		planner = &sweep.Planner{
+			Token:       TKNBurnerAddress,
+			ColdStorage: coldStorage,
+			Reserves:    map[string]*big.Int{"oracle": big.NewInt(400)},
+		}
+		contracts = []sweep.Contract{
+			{Name: "oracle", Address: OracleAddress},
+			{Name: "token_whitelist", Address: TokenWhitelistAddress},
+		}
+		for _, c := range contracts {
+			tx, err := TKNBurner.Mint(BankAccount.TransactOpts(), c.Address, big.NewInt(1000))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		}
+	})
+
+	It("Should sweep the balances above the reserves", func() {
+		sweeps, err := planner.Plan(context.Background(), Backend, contracts...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sweeps).To(HaveLen(2))
+		Expect(sweeps[0].Contract).To(Equal("oracle"))
+		Expect(sweeps[0].Balance.String()).To(Equal("1000"))
+		Expect(sweeps[0].Reserve.String()).To(Equal("400"))
+		Expect(sweeps[0].Amount.String()).To(Equal("600"))
+		Expect(sweeps[1].Contract).To(Equal("token_whitelist"))
+		Expect(sweeps[1].Reserve.String()).To(Equal("0"))
+		Expect(sweeps[1].Amount.String()).To(Equal("1000"))
+	})
+
+	It("Should skip the sweeps below the minimum", func() {
+		planner.Minimum = big.NewInt(700)
+		sweeps, err := planner.Plan(context.Background(), Backend, contracts...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sweeps).To(HaveLen(1))
+		Expect(sweeps[0].Contract).To(Equal("token_whitelist"))
+	})
+
+	It("Should skip the contracts whose balance is within their reserve", func() {
+		planner.Reserves["token_whitelist"] = big.NewInt(5000)
+		sweeps, err := planner.Plan(context.Background(), Backend, contracts...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sweeps).To(HaveLen(1))
+		Expect(sweeps[0].Contract).To(Equal("oracle"))
+	})
+
+	It("Should refuse to plan without a cold storage address", func() {
+		planner.ColdStorage = common.Address{}
+		_, err := planner.Plan(context.Background(), Backend, contracts...)
+		Expect(err).To(MatchError("cold storage address is not set"))
+	})
+
+	When("the planned claims are sent by an admin", func() {
+		BeforeEach(func() {
+			sweeps, err := planner.Plan(context.Background(), Backend, contracts...)
+			Expect(err).ToNot(HaveOccurred())
+			for _, s := range sweeps {
+				opts := ControllerAdmin.TransactOpts()
+				nonce, err := Backend.PendingNonceAt(context.Background(), opts.From)
+				Expect(err).ToNot(HaveOccurred())
+				gasPrice, err := Backend.SuggestGasPrice(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				tx, err := opts.Signer(types.HomesteadSigner{}, opts.From, types.NewTransaction(nonce, s.Address, new(big.Int), 200000, gasPrice, s.Data))
+				Expect(err).ToNot(HaveOccurred())
+				err = Backend.SendTransaction(context.Background(), tx)
+				Expect(err).ToNot(HaveOccurred())
+				Backend.Commit()
+				Expect(isSuccessful(tx)).To(BeTrue())
+			}
+		})
+
+		It("Should move the excess to cold storage", func() {
+			b, err := TKNBurner.BalanceOf(nil, coldStorage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.String()).To(Equal("1600"))
+			b, err = TKNBurner.BalanceOf(nil, OracleAddress)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.String()).To(Equal("400"))
+		})
+	})
+})
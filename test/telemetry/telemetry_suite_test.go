@@ -0,0 +1,39 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func TestTelemetrySuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Telemetry Suite")
+}
+
+var Registry metrics.Registry
+var Instrumented *telemetry.Backend
+
+var _ = BeforeSuite(func() {
+	metrics.Enabled = true
+})
+
+var _ = BeforeEach(func() {
+	err := InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+
+	Registry = metrics.NewRegistry()
+	Instrumented, err = telemetry.NewBackend(Backend, Registry, bindings.ContractABIs)
+	Expect(err).ToNot(HaveOccurred())
+})
+
+var _ = AfterEach(func() {
+	err := Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
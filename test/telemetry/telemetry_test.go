@@ -0,0 +1,154 @@
+package telemetry_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+func counter(name string) int64 {
+	c, ok := Registry.Get(name).(metrics.Counter)
+	if !ok {
+		return 0
+	}
+	return c.Count()
+}
+
+var _ = Describe("Backend", func() {
+
+	var licence *bindings.Licence
+
+	BeforeEach(func() {
+		var err error
+		licence, err = bindings.NewLicence(LicenceAddress, Instrumented)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("records the latency of contract calls", func() {
+		_, err := licence.LicenceDAO(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Registry.Get("rpc/call").(metrics.Timer).Count()).To(Equal(int64(1)))
+	})
+
+	When("a transaction is mined", func() {
+		var r *types.Receipt
+
+		BeforeEach(func() {
+			tx, err := licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			r, err = Instrumented.TransactionReceipt(context.Background(), tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+		})
+
+		It("counts the transaction per method", func() {
+			Expect(counter("tx/licence/updateLicenceDAO/sent")).To(Equal(int64(1)))
+			Expect(counter("tx/licence/updateLicenceDAO/reverted")).To(BeZero())
+		})
+
+		It("records the gas used", func() {
+			h := Registry.Get("tx/licence/updateLicenceDAO/gas_used").(metrics.Histogram)
+			Expect(h.Count()).To(Equal(int64(1)))
+			Expect(h.Max()).To(Equal(int64(r.GasUsed)))
+		})
+
+		It("records a receipt once", func() {
+			_, err := Instrumented.TransactionReceipt(context.Background(), r.TxHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(Registry.Get("tx/licence/updateLicenceDAO/gas_used").(metrics.Histogram).Count()).To(Equal(int64(1)))
+		})
+
+		It("serves the metrics in the Prometheus format", func() {
+			w := httptest.NewRecorder()
+			telemetry.Handler(Registry).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+			body, err := ioutil.ReadAll(w.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("tx_licence_updateLicenceDAO_sent 1"))
+		})
+	})
+
+	When("the receipts are watched", func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			_, err := licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(Instrumented.Pending()).To(Equal(1))
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("records the outcome of the mined transactions", func() {
+			Backend.Commit()
+			go Instrumented.Watch(ctx, Backend, 10*time.Millisecond)
+			Eventually(Instrumented.Pending).Should(BeZero())
+			Expect(Registry.Get("tx/licence/updateLicenceDAO/gas_used").(metrics.Histogram).Count()).To(Equal(int64(1)))
+		})
+
+		It("forgets the transactions not mined in time", func() {
+			Instrumented.PendingTTL = time.Nanosecond
+			go Instrumented.Watch(ctx, Backend, 10*time.Millisecond)
+			Eventually(Instrumented.Pending).Should(BeZero())
+			Expect(counter("tx/licence/updateLicenceDAO/unobserved")).To(Equal(int64(1)))
+			Expect(Registry.Get("tx/licence/updateLicenceDAO/gas_used")).To(BeNil())
+		})
+	})
+
+	When("the gas estimation fails", func() {
+		BeforeEach(func() {
+			_, err := licence.UpdateLicenceDAO(RandomAccount.TransactOpts(), RandomAccount.Address())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("counts the estimation error per method", func() {
+			Expect(counter("tx/licence/updateLicenceDAO/estimate_errors")).To(Equal(int64(1)))
+			Expect(counter("rpc/estimate_gas/errors")).To(Equal(int64(1)))
+			Expect(counter("tx/licence/updateLicenceDAO/sent")).To(BeZero())
+		})
+	})
+
+	When("a transaction reverts", func() {
+		BeforeEach(func() {
+			tx, err := licence.UpdateLicenceDAO(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			r, err := Instrumented.TransactionReceipt(context.Background(), tx.Hash())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Status).To(Equal(types.ReceiptStatusFailed))
+		})
+
+		It("counts the revert per method", func() {
+			Expect(counter("tx/licence/updateLicenceDAO/reverted")).To(Equal(int64(1)))
+		})
+	})
+
+	It("tracks the active subscriptions", func() {
+		sub, err := Instrumented.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{Addresses: []common.Address{LicenceAddress}}, make(chan types.Log))
+		Expect(err).ToNot(HaveOccurred())
+		gauge := Registry.Get("subscriptions/active").(metrics.Gauge)
+		Expect(gauge.Value()).To(Equal(int64(1)))
+
+		sub.Unsubscribe()
+		Expect(gauge.Value()).To(BeZero())
+		sub.Unsubscribe()
+		Expect(gauge.Value()).To(BeZero())
+	})
+})
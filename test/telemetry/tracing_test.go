@@ -0,0 +1,106 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/telemetry"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+func spans(buf *bytes.Buffer) []telemetry.SpanRecord {
+	var records []telemetry.SpanRecord
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r telemetry.SpanRecord
+		Expect(dec.Decode(&r)).To(Succeed())
+		records = append(records, r)
+	}
+	return records
+}
+
+var _ = Describe("TracingBackend", func() {
+
+	var buf *bytes.Buffer
+	var tracer *telemetry.WriterTracer
+	var licence *bindings.Licence
+
+	BeforeEach(func() {
+		buf = new(bytes.Buffer)
+		tracer = telemetry.NewWriterTracer(buf)
+		traced, err := telemetry.NewTracingBackend(Backend, tracer, bindings.ContractABIs)
+		Expect(err).ToNot(HaveOccurred())
+		licence, err = bindings.NewLicence(LicenceAddress, traced)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("traces contract calls as children of the caller's span", func() {
+		ctx, parent := tracer.Start(context.Background(), "parent")
+		_, err := licence.LicenceDAO(&bind.CallOpts{Context: ctx})
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+
+		records := spans(buf)
+		Expect(records).To(HaveLen(2))
+		call, p := records[0], records[1]
+		Expect(call.Name).To(Equal("eth.call"))
+		Expect(call.TraceID).To(Equal(p.TraceID))
+		Expect(call.ParentID).To(Equal(p.SpanID))
+		Expect(call.Attributes).To(HaveKeyWithValue("eth.contract", "licence"))
+		Expect(call.Attributes).To(HaveKeyWithValue("eth.method", "licenceDAO"))
+		Expect(call.Attributes).To(HaveKeyWithValue("eth.to", LicenceAddress.Hex()))
+	})
+
+	It("traces transactions with their hash", func() {
+		tx, err := licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+
+		var names []string
+		var sent telemetry.SpanRecord
+		for _, r := range spans(buf) {
+			names = append(names, r.Name)
+			if r.Name == "eth.sendTransaction" {
+				sent = r
+			}
+		}
+		Expect(names).To(Equal([]string{"eth.estimateGas", "eth.sendTransaction"}))
+		Expect(sent.Attributes).To(HaveKeyWithValue("eth.tx_hash", tx.Hash().Hex()))
+		Expect(sent.Attributes).To(HaveKeyWithValue("eth.method", "updateLicenceDAO"))
+	})
+
+	It("records failed estimations", func() {
+		_, err := licence.UpdateLicenceDAO(RandomAccount.TransactOpts(), RandomAccount.Address())
+		Expect(err).To(HaveOccurred())
+		records := spans(buf)
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Error).ToNot(BeEmpty())
+	})
+
+	It("continues the trace of incoming requests", func() {
+		h := telemetry.TraceHandler(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := licence.LicenceDAO(&bind.CallOpts{Context: r.Context()})
+			Expect(err).ToNot(HaveOccurred())
+			w.WriteHeader(http.StatusTeapot)
+		}))
+		req := httptest.NewRequest("GET", "/licence", nil)
+		req.Header.Set(telemetry.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		records := spans(buf)
+		Expect(records).To(HaveLen(2))
+		call, server := records[0], records[1]
+		Expect(server.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(server.ParentID).To(Equal("00f067aa0ba902b7"))
+		Expect(server.Attributes).To(HaveKeyWithValue("http.status_code", BeNumerically("==", http.StatusTeapot)))
+		Expect(call.ParentID).To(Equal(server.SpanID))
+	})
+})
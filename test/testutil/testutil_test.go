@@ -0,0 +1,170 @@
+package testutil_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/names"
+	"github.com/tokencard/contracts/v2/pkg/testutil"
+)
+
+var _ = Describe("Chain", func() {
+
+	var chain *testutil.Chain
+
+	BeforeEach(func() {
+		var err error
+		chain, err = testutil.New(testutil.Config{Accounts: 2})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(chain.Close()).To(Succeed())
+	})
+
+	It("should fund the accounts", func() {
+		Expect(chain.Accounts).To(HaveLen(2))
+		for _, a := range chain.Accounts {
+			balance, err := chain.BalanceAt(context.Background(), a.Address, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(balance).To(Equal(testutil.DefaultBalance))
+		}
+	})
+
+	It("should mint TKN to every account", func() {
+		for _, a := range append(chain.Accounts, chain.Deployer) {
+			balance, err := chain.TKN.BalanceOf(nil, a.Address)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(balance).To(Equal(testutil.DefaultTKN))
+		}
+	})
+
+	It("should send transactions from the sessions of the accounts", func() {
+		alice, bob := chain.Accounts[0], chain.Accounts[1]
+		tx, err := chain.TKNSession(alice).Transfer(bob.Address, big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		receipts, err := chain.Mined(tx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(receipts).To(HaveLen(1))
+
+		balance, err := chain.TKN.BalanceOf(nil, bob.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(balance).To(Equal(new(big.Int).Add(testutil.DefaultTKN, big.NewInt(1))))
+	})
+
+	It("should grant the roles of the controller", func() {
+		isAdmin, err := chain.ControllerContract.IsAdmin(nil, chain.Admin.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isAdmin).To(BeTrue())
+		isController, err := chain.ControllerContract.IsController(nil, chain.Controller.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isController).To(BeTrue())
+	})
+
+	It("should register the contracts with ENS", func() {
+		contracts := map[string]common.Address{
+			testutil.ControllerName:     chain.ControllerContractAddress,
+			testutil.TokenWhitelistName: chain.TokenWhitelistAddress,
+			testutil.LicenceName:        chain.LicenceAddress,
+			testutil.WalletCacheName:    chain.WalletCacheAddress,
+			testutil.WalletDeployerName: chain.WalletDeployerAddress,
+		}
+		for name, address := range contracts {
+			resolved, err := chain.ENSResolver.Addr(nil, names.Namehash(name))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resolved).To(Equal(address), name)
+		}
+	})
+
+	It("should whitelist TKN and the stablecoin", func() {
+		symbol, _, _, available, _, _, _, err := chain.TokenWhitelist.GetTokenInfo(nil, chain.TKNAddress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(available).To(BeTrue())
+		Expect(symbol).To(Equal("TKN"))
+
+		symbol, _, _, available, _, _, _, err = chain.TokenWhitelist.GetStablecoinInfo(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(available).To(BeTrue())
+		Expect(symbol).To(Equal("DAI"))
+	})
+
+	It("should deploy a wallet owned by every account", func() {
+		for _, a := range chain.Accounts {
+			owner, err := chain.WalletSession(a).Owner()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal(a.Address))
+		}
+		Expect(chain.Accounts[0].WalletAddress).ToNot(Equal(chain.Accounts[1].WalletAddress))
+	})
+
+	It("should report the block of the last transaction mined as the head", func() {
+		head, err := chain.HeaderByNumber(context.Background(), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(head.Number).To(Equal(chain.Simulated.Blockchain().CurrentBlock().Number()))
+
+		chain.Advance(2)
+		Expect(chain.Head()).To(Equal(new(big.Int).Add(head.Number, big.NewInt(2))))
+	})
+
+	It("should report reverted transactions", func() {
+		opts := chain.Accounts[0].Session.TransactOpts()
+		opts.GasLimit = 22000
+		tx, err := chain.TKN.Transfer(opts, chain.Accounts[1].Address, big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = chain.Mined(tx)
+		Expect(errors.Cause(err)).To(Equal(testutil.ErrReverted))
+	})
+})
+
+var _ = Describe("NewChain", func() {
+
+	var deployed, chain *testutil.Chain
+
+	BeforeEach(func() {
+		var err error
+		deployed, err = testutil.New(testutil.Config{Accounts: 2})
+		Expect(err).ToNot(HaveOccurred())
+		chain = testutil.NewChain(deployed.Simulated, testutil.MineOnSend)
+	})
+
+	AfterEach(func() {
+		Expect(chain.Close()).To(Succeed())
+	})
+
+	It("should start at block 0", func() {
+		Expect(chain.Head().Sign()).To(Equal(0))
+	})
+
+	It("should mine the transactions when sent", func() {
+		tkn, err := mocks.NewBurnerToken(deployed.TKNAddress, chain)
+		Expect(err).ToNot(HaveOccurred())
+		tx, err := tkn.Transfer(deployed.Accounts[0].Session.TransactOpts(), deployed.Accounts[1].Address, big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		r, err := chain.Backend.TransactionReceipt(context.Background(), tx.Hash())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+		Expect(chain.Head()).To(Equal(r.BlockNumber))
+	})
+})
+
+var _ = Describe("Config", func() {
+
+	It("should set the initial balances", func() {
+		chain, err := testutil.New(testutil.Config{Accounts: 1, Balance: big.NewInt(1e18), TKN: big.NewInt(0)})
+		Expect(err).ToNot(HaveOccurred())
+		defer chain.Close()
+
+		balance, err := chain.BalanceAt(context.Background(), chain.Accounts[0].Address, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(balance.String()).To(Equal("1000000000000000000"))
+		tkn, err := chain.TKN.BalanceOf(nil, chain.Accounts[0].Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tkn.Sign()).To(Equal(0))
+	})
+})
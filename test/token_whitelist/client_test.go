@@ -0,0 +1,132 @@
+package token_whitelist_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+var _ = Describe("TokenWhitelistClient", func() {
+
+	var client *bindings.TokenWhitelistClient
+	var ctx context.Context
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewTokenWhitelistClient(TokenWhitelistAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	It("Should return its address", func() {
+		Expect(client.Address()).To(Equal(TokenWhitelistAddress))
+	})
+
+	It("Should fail to return a token which is not whitelisted", func() {
+		_, err := client.Token(ctx, common.HexToAddress("0x1"))
+		Expect(errors.Cause(err)).To(Equal(bindings.ErrTokenNotAvailable))
+	})
+
+	It("Should tell that a token which is not whitelisted is neither loadable nor redeemable", func() {
+		loadable, err := client.IsLoadable(ctx, common.HexToAddress("0x1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadable).To(BeFalse())
+		redeemable, err := client.IsRedeemable(ctx, common.HexToAddress("0x1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(redeemable).To(BeFalse())
+	})
+
+	It("Should refuse to remove a token which is not whitelisted", func() {
+		_, err := client.RemoveTokens(ControllerAdmin.TransactOpts(), common.HexToAddress("0x1"))
+		Expect(errors.Cause(err)).To(Equal(bindings.ErrTokenNotAvailable))
+	})
+
+	It("Should refuse a symbol longer than 32 bytes", func() {
+		_, err := client.AddTokens(ControllerAdmin.TransactOpts(), bindings.NewToken{
+			Address: common.HexToAddress("0x1"),
+			Symbol:  "ABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFG",
+		})
+		Expect(err).To(MatchError(ContainSubstring("longer than 32 bytes")))
+	})
+
+	When("tokens are added", func() {
+
+		var tx *types.Transaction
+
+		BeforeEach(func() {
+			var err error
+			tx, err = client.AddTokens(ControllerAdmin.TransactOpts(),
+				bindings.NewToken{Address: common.HexToAddress("0x1"), Symbol: "BNT", Decimals: 18, Redeemable: true},
+				bindings.NewToken{Address: common.HexToAddress("0x2"), Symbol: "TKN", Decimals: 8, Loadable: true, Redeemable: true},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("Should return the tokens", func() {
+			t, err := client.Token(ctx, common.HexToAddress("0x2"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t.Symbol).To(Equal("TKN"))
+			Expect(t.Magnitude.String()).To(Equal("100000000"))
+			Expect(t.Rate.String()).To(Equal("0"))
+			Expect(t.Loadable).To(BeTrue())
+			Expect(t.Redeemable).To(BeTrue())
+			Expect(t.LastUpdate.Unix()).To(BeZero())
+		})
+
+		It("Should list them along with the other whitelisted tokens", func() {
+			tokens, err := client.Tokens(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			var addresses []common.Address
+			for _, t := range tokens {
+				addresses = append(addresses, t.Token)
+			}
+			Expect(addresses).To(ContainElement(common.HexToAddress("0x1")))
+			Expect(addresses).To(ContainElement(common.HexToAddress("0x2")))
+		})
+
+		It("Should report their flags", func() {
+			loadable, err := client.IsLoadable(ctx, common.HexToAddress("0x1"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadable).To(BeFalse())
+			redeemable, err := client.IsRedeemable(ctx, common.HexToAddress("0x1"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(redeemable).To(BeTrue())
+		})
+
+		It("Should refuse to add them again", func() {
+			_, err := client.AddTokens(ControllerAdmin.TransactOpts(), bindings.NewToken{Address: common.HexToAddress("0x2"), Symbol: "TKN"})
+			Expect(err).To(MatchError(ContainSubstring("already whitelisted")))
+		})
+
+		When("a token is removed", func() {
+			BeforeEach(func() {
+				var err error
+				tx, err = client.RemoveTokens(ControllerAdmin.TransactOpts(), common.HexToAddress("0x1"))
+				Expect(err).ToNot(HaveOccurred())
+				Backend.Commit()
+				Expect(isSuccessful(tx)).To(BeTrue())
+			})
+
+			It("Should not be available anymore", func() {
+				_, err := client.Token(ctx, common.HexToAddress("0x1"))
+				Expect(errors.Cause(err)).To(Equal(bindings.ErrTokenNotAvailable))
+			})
+		})
+	})
+
+	It("Should fail to add tokens when called by a random account", func() {
+		tx, err := client.AddTokens(RandomAccount.TransactOpts(ethertest.WithGasLimit(500000)), bindings.NewToken{Address: common.HexToAddress("0x1"), Symbol: "BNT", Decimals: 18})
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(isSuccessful(tx)).To(BeFalse())
+	})
+})
@@ -0,0 +1,22 @@
+package txmgr_test
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+)
+
+func tokenABI() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(mocks.TokenABI))
+	Expect(err).ToNot(HaveOccurred())
+	return parsed
+}
+
+func licenceABI() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+	Expect(err).ToNot(HaveOccurred())
+	return parsed
+}
@@ -0,0 +1,92 @@
+package txmgr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+// recordingLogger records the messages logged with their level.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) log(level, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf("%s %s", level, msg))
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) { l.log("DEBUG", msg) }
+func (l *recordingLogger) Info(msg string, keyvals ...interface{})  { l.log("INFO", msg) }
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{})  { l.log("WARN", msg) }
+func (l *recordingLogger) Error(msg string, keyvals ...interface{}) { l.log("ERROR", msg) }
+
+// chain provides the test backend as a txmgr.Chain, waiting for more than one
+// confirmation is not supported.
+type chain struct {
+	ethertest.TestBackend
+}
+
+func (c chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, errors.New("not supported")
+}
+
+var _ = Describe("Logging", func() {
+
+	var logger *recordingLogger
+	var manager *txmgr.Manager
+	var licence *bindings.Licence
+
+	BeforeEach(func() {
+		logger = &recordingLogger{}
+		manager = txmgr.New(Backend)
+		manager.SetLogger(logger)
+
+		var err error
+		licence, err = bindings.NewLicence(LicenceAddress, manager)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should log submitted and mined transactions", func() {
+		tx, err := licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+
+		_, err = manager.Wait(context.Background(), chain{Backend}, tx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logger.messages).To(Equal([]string{"INFO Submitted transaction", "INFO Transaction mined"}))
+	})
+
+	It("should log reverted transactions", func() {
+		tx, err := licence.UpdateLicenceDAO(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+
+		r, err := manager.Wait(context.Background(), chain{Backend}, tx)
+		Expect(err).To(HaveOccurred())
+		Expect(r.Status).To(Equal(types.ReceiptStatusFailed))
+		Expect(logger.messages).To(ContainElement("ERROR Transaction reverted"))
+	})
+
+	It("should log transactions rejected by the dry run", func() {
+		err := manager.Registry().Set(bindings.LicenceABI, "updateLicenceDAO", txmgr.Defaults{DryRun: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = licence.UpdateLicenceDAO(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), RandomAccount.Address())
+		Expect(err).To(HaveOccurred())
+		Expect(logger.messages).To(Equal([]string{"WARN Transaction rejected by dry run"}))
+	})
+})
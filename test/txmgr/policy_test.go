@@ -0,0 +1,104 @@
+package txmgr_test
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Policy", func() {
+
+	It("should pad the estimate by the percentage", func() {
+		Expect(txmgr.Policy{Percentage: 20}.Apply(100000)).To(Equal(uint64(120000)))
+	})
+
+	It("should pad the estimate by at least the floor", func() {
+		Expect(txmgr.Policy{Percentage: 20, Floor: 50000}.Apply(100000)).To(Equal(uint64(150000)))
+	})
+
+	It("should cap the padded estimate at the ceiling", func() {
+		Expect(txmgr.Policy{Percentage: 50, Ceiling: 130000}.Apply(100000)).To(Equal(uint64(130000)))
+	})
+
+	It("should never return less than the estimate", func() {
+		Expect(txmgr.Policy{Percentage: 50, Ceiling: 90000}.Apply(100000)).To(Equal(uint64(100000)))
+	})
+})
+
+var _ = Describe("Manager", func() {
+
+	var manager *txmgr.Manager
+
+	BeforeEach(func() {
+		manager = txmgr.New(Backend)
+		err := manager.SetPolicy(mocks.TokenABI, "credit", txmgr.Policy{Floor: 40000})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to set a policy for an unknown method", func() {
+		err := manager.SetPolicy(mocks.TokenABI, "transferBonus", txmgr.Policy{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should pad the gas limit of a method with a policy", func() {
+		token, err := mocks.NewToken(StablecoinAddress, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := tokenABI().Pack("credit", RandomAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		estimate, err := Backend.EstimateGas(context.Background(), ethereum.CallMsg{From: BankAccount.Address(), To: &StablecoinAddress, Data: data})
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := token.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		Expect(tx.Gas()).To(Equal(estimate + 40000))
+	})
+
+	It("should estimate a method with its policy and projected cost", func() {
+		token, err := mocks.NewToken(StablecoinAddress, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := tokenABI().Pack("credit", RandomAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		estimate, err := Backend.EstimateGas(context.Background(), ethereum.CallMsg{From: BankAccount.Address(), To: &StablecoinAddress, Data: data})
+		Expect(err).ToNot(HaveOccurred())
+
+		nonce, err := Backend.PendingNonceAt(context.Background(), BankAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		opts := BankAccount.TransactOpts()
+		opts.GasPrice = big.NewInt(1000000000)
+		e, err := manager.EstimateMethod(context.Background(), opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return token.Credit(opts, RandomAccount.Address(), big.NewInt(100))
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(e.Gas).To(Equal(estimate))
+		Expect(e.Limit).To(Equal(estimate + 40000))
+		Expect(e.Policy).To(Equal(txmgr.Policy{Floor: 40000}))
+		Expect(e.Cost).To(Equal(new(big.Int).Mul(big.NewInt(int64(e.Limit)), opts.GasPrice)))
+
+		// Nothing was sent.
+		Expect(Backend.PendingNonceAt(context.Background(), BankAccount.Address())).To(Equal(nonce))
+	})
+
+	It("should report the projected cost in ether", func() {
+		e := txmgr.Estimate{Cost: big.NewInt(2100000000000000)}
+		Expect(e.CostEther()).To(Equal("0.0021"))
+		e.Cost = big.NewInt(3000000000000000000)
+		Expect(e.CostEther()).To(Equal("3"))
+	})
+
+	It("should use the default policy for other methods", func() {
+		data, err := tokenABI().Pack("transfer", RandomAccount.Address(), big.NewInt(0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manager.Policy(data)).To(Equal(txmgr.DefaultPolicy))
+	})
+})
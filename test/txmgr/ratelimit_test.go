@@ -0,0 +1,92 @@
+package txmgr_test
+
+import (
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+var _ = Describe("RateLimiter", func() {
+
+	It("should allow a burst of the rate per minute", func() {
+		l := txmgr.NewRateLimiter(3)
+		for i := 0; i < 3; i++ {
+			Expect(l.Allow(BankAccount.Address())).To(BeTrue())
+		}
+		Expect(l.Allow(BankAccount.Address())).To(BeFalse())
+	})
+
+	It("should limit each signer separately", func() {
+		l := txmgr.NewRateLimiter(1)
+		Expect(l.Allow(BankAccount.Address())).To(BeTrue())
+		Expect(l.Allow(BankAccount.Address())).To(BeFalse())
+		Expect(l.Allow(RandomAccount.Address())).To(BeTrue())
+	})
+
+	It("should refill the bucket over time", func() {
+		l := txmgr.NewRateLimiter(600)
+		for l.Allow(BankAccount.Address()) {
+		}
+		Eventually(func() bool {
+			return l.Allow(BankAccount.Address())
+		}, time.Second, 10*time.Millisecond).Should(BeTrue())
+	})
+
+	When("the manager has a rate limiter", func() {
+		var token *mocks.Token
+
+		BeforeEach(func() {
+			manager := txmgr.New(Backend)
+			manager.SetRateLimiter(txmgr.NewRateLimiter(2))
+			var err error
+			token, err = mocks.NewToken(StablecoinAddress, manager)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not send the transactions over the limit", func() {
+			for i := 0; i < 2; i++ {
+				_, err := token.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+				Expect(err).ToNot(HaveOccurred())
+			}
+			_, err := token.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+			Expect(errors.Cause(err)).To(Equal(txmgr.ErrRateLimited))
+			Backend.Commit()
+
+			balance, err := token.BalanceOf(nil, RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(balance.String()).To(Equal("200"))
+		})
+	})
+	When("the manager simulates the transactions", func() {
+		var token *mocks.Token
+
+		BeforeEach(func() {
+			manager := txmgr.New(Backend)
+			manager.SetDryRun(true)
+			manager.SetRateLimiter(txmgr.NewRateLimiter(1))
+			var err error
+			token, err = mocks.NewToken(StablecoinAddress, manager)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not count the transactions rejected by their dry run", func() {
+			overdraft := new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+			for i := 0; i < 2; i++ {
+				_, err := token.Transfer(BankAccount.TransactOpts(ethertest.WithGasLimit(100000)), RandomAccount.Address(), overdraft)
+				Expect(err).To(MatchError(ContainSubstring("dry run failed")))
+			}
+
+			_, err := token.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = token.Credit(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(100))
+			Expect(errors.Cause(err)).To(Equal(txmgr.ErrRateLimited))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package txmgr_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+const registryJSON = `{
+	"default": {"confirmations": 2},
+	"methods": {
+		"licence.updateLicenceAmount": {
+			"gas_padding": {"percentage": 50},
+			"confirmations": 6,
+			"retry": {"attempts": 3, "backoff": "1s"},
+			"dry_run": true
+		}
+	}
+}`
+
+var _ = Describe("Registry", func() {
+
+	var registry *txmgr.Registry
+
+	BeforeEach(func() {
+		var err error
+		registry, err = txmgr.LoadRegistry(strings.NewReader(registryJSON))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to load an unknown contract", func() {
+		_, err := txmgr.LoadRegistry(strings.NewReader(`{"methods": {"referral.issue": {}}}`))
+		Expect(err).To(MatchError(ContainSubstring(`unknown contract "referral"`)))
+	})
+
+	It("should fail to load a method name without a contract", func() {
+		_, err := txmgr.LoadRegistry(strings.NewReader(`{"methods": {"issue": {}}}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the defaults of a configured method", func() {
+		data, err := licenceABI().Pack("updateLicenceAmount", big.NewInt(1))
+		Expect(err).ToNot(HaveOccurred())
+		d := registry.Lookup(data)
+		Expect(d.Confirmations).To(Equal(uint64(6)))
+		Expect(d.DryRun).To(BeTrue())
+		Expect(d.Retry.Attempts).To(Equal(3))
+		Expect(time.Duration(d.Retry.Backoff)).To(Equal(time.Second))
+		Expect(d.Padding()).To(Equal(txmgr.Policy{Percentage: 50}))
+	})
+
+	It("should return the fallback defaults of other methods", func() {
+		data, err := licenceABI().Pack("updateFloat", RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		d := registry.Lookup(data)
+		Expect(d.Confirmations).To(Equal(uint64(2)))
+		Expect(d.Padding()).To(Equal(txmgr.DefaultPolicy))
+	})
+
+	When("a method requiring a dry run would fail", func() {
+
+		var licence *bindings.Licence
+
+		BeforeEach(func() {
+			var err error
+			licence, err = bindings.NewLicence(LicenceAddress, txmgr.NewWithRegistry(Backend, registry))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not send the transaction", func() {
+			_, err := licence.UpdateLicenceAmount(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), big.NewInt(20))
+			Expect(err).To(MatchError(ContainSubstring("dry run failed")))
+
+			nonce, err := Backend.PendingNonceAt(context.Background(), RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nonce).To(BeZero())
+		})
+
+		It("should return the revert reason", func() {
+			_, err := licence.UpdateLicenceAmount(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), big.NewInt(20))
+			Expect(err).To(MatchError(ContainSubstring("execution reverted: the sender isn't the DAO")))
+			revert, ok := errors.Cause(err).(*txmgr.RevertError)
+			Expect(ok).To(BeTrue())
+			Expect(revert.Reason).To(Equal("the sender isn't the DAO"))
+		})
+	})
+
+	When("the manager simulates every transaction", func() {
+
+		var licence *bindings.Licence
+
+		BeforeEach(func() {
+			manager := txmgr.New(Backend)
+			manager.SetDryRun(true)
+			var err error
+			licence, err = bindings.NewLicence(LicenceAddress, manager)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should not send a failing transaction of a method without a dry run", func() {
+			_, err := licence.UpdateFloat(RandomAccount.TransactOpts(ethertest.WithGasLimit(100000)), RandomAccount.Address())
+			Expect(err).To(MatchError(ContainSubstring("dry run failed")))
+
+			nonce, err := Backend.PendingNonceAt(context.Background(), RandomAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nonce).To(BeZero())
+		})
+	})
+
+	It("should not decode the output of a successful call as a revert reason", func() {
+		_, ok := txmgr.RevertReason(common.LeftPadBytes([]byte{1}, 32))
+		Expect(ok).To(BeFalse())
+	})
+})
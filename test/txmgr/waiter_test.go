@@ -0,0 +1,172 @@
+package txmgr_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/txmgr"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+// waitChain adds the HeaderByNumber and NonceAt methods required by the waiter
+// to the test backend, reporting the blocks mined with commit.
+type waitChain struct {
+	ethertest.TestBackend
+	head  *big.Int
+	nonce uint64
+}
+
+func (c *waitChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).Set(c.head)}, nil
+}
+
+func (c *waitChain) NonceAt(ctx context.Context, account common.Address, number *big.Int) (uint64, error) {
+	return c.nonce, nil
+}
+
+// commit mines a block, moving the head and the nonce of the bank account.
+func (c *waitChain) commit() {
+	c.Commit()
+	c.head = new(big.Int).Add(c.head, big.NewInt(1))
+	nonce, err := c.PendingNonceAt(context.Background(), BankAccount.Address())
+	Expect(err).ToNot(HaveOccurred())
+	c.nonce = nonce
+}
+
+var _ = Describe("Waiter", func() {
+
+	var waiter *txmgr.Waiter
+	var c *waitChain
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		tx, err := Licence.UpdateLicenceDAO(ControllerAdmin.TransactOpts(), RandomAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		Backend.Commit()
+		r, err := Backend.TransactionReceipt(ctx, tx.Hash())
+		Expect(err).ToNot(HaveOccurred())
+		nonce, err := Backend.PendingNonceAt(ctx, BankAccount.Address())
+		Expect(err).ToNot(HaveOccurred())
+		c = &waitChain{TestBackend: Backend, head: r.BlockNumber, nonce: nonce}
+
+		waiter = txmgr.NewWaiter(c, 3)
+		waiter.Interval = 10 * time.Millisecond
+	})
+
+	// wait waits for tx in the background.
+	wait := func(tx *types.Transaction) (chan *txmgr.Mined, chan error) {
+		mined := make(chan *txmgr.Mined, 1)
+		errs := make(chan error, 1)
+		go func() {
+			m, err := waiter.Wait(ctx, tx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			mined <- m
+		}()
+		return mined, errs
+	}
+
+	// transfer signs a transfer of the bank account with the given nonce.
+	transfer := func(nonce uint64, gasPrice int64) *types.Transaction {
+		opts := BankAccount.TransactOpts()
+		tx := types.NewTransaction(nonce, RandomAccount.Address(), big.NewInt(1), 21000, big.NewInt(gasPrice), nil)
+		signed, err := opts.Signer(types.HomesteadSigner{}, opts.From, tx)
+		Expect(err).ToNot(HaveOccurred())
+		return signed
+	}
+
+	When("a licence load is sent", func() {
+
+		var tx *types.Transaction
+
+		BeforeEach(func() {
+			opts := BankAccount.TransactOpts()
+			opts.Value = EthToWei(1)
+			var err error
+			tx, err = Licence.Load(opts, common.Address{}, EthToWei(1))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should wait for the confirmations and decode the events of the receipt", func() {
+			mined, errs := wait(tx)
+			c.commit()
+			Consistently(mined, 100*time.Millisecond).ShouldNot(Receive())
+			c.commit()
+			c.commit()
+
+			var m *txmgr.Mined
+			Eventually(mined, time.Second).Should(Receive(&m))
+			Expect(errs).ToNot(Receive())
+			Expect(m.Tx.Hash()).To(Equal(tx.Hash()))
+			Expect(m.Succeeded()).To(BeTrue())
+
+			parsed, err := abi.JSON(strings.NewReader(bindings.LicenceABI))
+			Expect(err).ToNot(HaveOccurred())
+			events, err := indexer.ReceiptEvents(indexer.Contract{Name: "licence", Address: LicenceAddress, ABI: parsed}, m.Receipt)
+			Expect(err).ToNot(HaveOccurred())
+			var names []string
+			for _, e := range events {
+				names = append(names, e.Name)
+			}
+			Expect(names).To(ConsistOf("TransferredToTokenHolder", "TransferredToCryptoFloat"))
+		})
+	})
+
+	When("a transaction is replaced", func() {
+
+		var original, replacement *types.Transaction
+
+		BeforeEach(func() {
+			nonce, err := Backend.PendingNonceAt(ctx, BankAccount.Address())
+			Expect(err).ToNot(HaveOccurred())
+			original = transfer(nonce, 1)
+			replacement = transfer(nonce, 2)
+			Expect(Backend.SendTransaction(ctx, replacement)).To(Succeed())
+			waiter.Confirmations = 1
+		})
+
+		It("should return the replacement it was told about", func() {
+			waiter.Replaced(original, replacement)
+			mined, _ := wait(original)
+			c.commit()
+
+			var m *txmgr.Mined
+			Eventually(mined, time.Second).Should(Receive(&m))
+			Expect(m.Tx.Hash()).To(Equal(replacement.Hash()))
+		})
+
+		It("should forget the replacements once the wait returned", func() {
+			waiter.Replaced(original, replacement)
+			mined, _ := wait(original)
+			c.commit()
+			Eventually(mined, time.Second).Should(Receive())
+
+			_, errs := wait(original)
+			var err error
+			Eventually(errs, time.Second).Should(Receive(&err))
+			Expect(errors.Cause(err)).To(Equal(txmgr.ErrReplaced))
+		})
+
+		It("should fail once the nonce is used by another transaction", func() {
+			_, errs := wait(original)
+			c.commit()
+
+			var err error
+			Eventually(errs, time.Second).Should(Receive(&err))
+			Expect(errors.Cause(err)).To(Equal(txmgr.ErrReplaced))
+		})
+	})
+})
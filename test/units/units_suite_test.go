@@ -0,0 +1,13 @@
+package units_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnitsSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Units Suite")
+}
@@ -0,0 +1,131 @@
+package units_test
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/units"
+)
+
+var _ = Describe("units", func() {
+
+	amount := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 10)
+		Expect(ok).To(BeTrue())
+		return n
+	}
+
+	Describe("ParseInt", func() {
+		It("should parse decimal integers", func() {
+			for s, want := range map[string]string{
+				"0":   "0",
+				"42":  "42",
+				"-42": "-42",
+				"007": "7",
+				"115792089237316195423570985008687907853269984665640564039457584007913129639935": units.MaxUint256.String(),
+			} {
+				n, err := units.ParseInt(s)
+				Expect(err).ToNot(HaveOccurred(), s)
+				Expect(n.String()).To(Equal(want))
+			}
+		})
+
+		It("should reject what big.Int accepts besides the digits", func() {
+			for _, s := range []string{"", "-", "+1", " 1", "1 ", "1_000", "0x10", "1.0", "1e18", "--1"} {
+				_, err := units.ParseInt(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+
+		It("should reject the integers beyond a uint256", func() {
+			_, err := units.ParseInt("115792089237316195423570985008687907853269984665640564039457584007913129639936")
+			Expect(err).To(MatchError(ContainSubstring("out of the range")))
+		})
+	})
+
+	Describe("ParseUint", func() {
+		It("should reject the negative integers", func() {
+			_, err := units.ParseUint("-1")
+			Expect(err).To(MatchError(ContainSubstring("negative")))
+			n, err := units.ParseUint("1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.String()).To(Equal("1"))
+		})
+	})
+
+	Describe("ParseDecimal", func() {
+		It("should parse the amounts in their base unit", func() {
+			for s, want := range map[string]string{
+				"1":           "1000000000000000000",
+				"1.5":         "1500000000000000000",
+				"0.000000001": "1000000000",
+				"1.500":       "1500000000000000000",
+				"0":           "0",
+			} {
+				n, err := units.ParseEther(s)
+				Expect(err).ToNot(HaveOccurred(), s)
+				Expect(n.String()).To(Equal(want), s)
+			}
+			n, err := units.ParseGwei("20.5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.String()).To(Equal("20500000000"))
+			n, err = units.ParseTKN("1.5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.String()).To(Equal("150000000"))
+		})
+
+		It("should reject the amounts it cannot parse exactly", func() {
+			for _, s := range []string{"", ".", "1.", ".5", "-1", "-1.5", "1.2.3", "1,5", "1e3", " 1"} {
+				_, err := units.ParseEther(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+			_, err := units.ParseTKN("0.000000001")
+			Expect(err).To(MatchError(ContainSubstring("more than 8 decimals")))
+			_, err = units.ParseTKN("0.000000010")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = units.ParseEther("1000000000000000000000000000000000000000000000000000000000000")
+			Expect(err).To(MatchError(ContainSubstring("out of the range")))
+		})
+	})
+
+	Describe("Format", func() {
+		It("should format the amounts without trailing zeros", func() {
+			Expect(units.FormatEther(amount("1500000000000000000"))).To(Equal("1.5"))
+			Expect(units.FormatEther(amount("1000000000000000000"))).To(Equal("1"))
+			Expect(units.FormatEther(amount("1"))).To(Equal("0.000000000000000001"))
+			Expect(units.FormatEther(amount("0"))).To(Equal("0"))
+			Expect(units.FormatEther(amount("-1500000000000000000"))).To(Equal("-1.5"))
+			Expect(units.FormatGwei(amount("20500000000"))).To(Equal("20.5"))
+			Expect(units.FormatTKN(amount("150000000"))).To(Equal("1.5"))
+			Expect(units.Format(amount("42"), 0)).To(Equal("42"))
+			Expect(units.Format(nil, 18)).To(Equal("0"))
+		})
+
+		It("should be parsed back to the same amount", func() {
+			for _, s := range []string{"0", "1", "123456789012345678901234567890", units.MaxUint256.String()} {
+				n, err := units.ParseEther(units.FormatEther(amount(s)))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n.String()).To(Equal(s))
+			}
+		})
+	})
+
+	Describe("Convert", func() {
+		It("should convert the amounts between units", func() {
+			n, err := units.Convert(amount("20"), units.GweiDecimals, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.String()).To(Equal("20000000000"))
+			n, err = units.Convert(amount("20000000000"), 0, units.GweiDecimals)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.String()).To(Equal("20"))
+		})
+
+		It("should reject the conversions losing precision", func() {
+			_, err := units.Convert(amount("1"), 0, units.GweiDecimals)
+			Expect(err).To(MatchError(ContainSubstring("exactly")))
+			_, err = units.Convert(amount("1"), -1, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -1,7 +1,10 @@
 package wallet_deployer_test
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/tokencard/contracts/v2/pkg/bindings"
@@ -26,13 +29,40 @@ var _ = Describe("Wallet Deployer", func() {
 	When("no Wallets are cached", func() {
 
 		When("a controller deploys a Wallet", func() {
+			var tx *types.Transaction
+
 			BeforeEach(func() {
-				tx, err := WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address())
+				var err error
+				tx, err = WalletDeployer.DeployWallet(Controller.TransactOpts(), Owner.Address())
 				Expect(err).ToNot(HaveOccurred())
 				Backend.Commit()
 				Expect(isSuccessful(tx)).To(BeTrue())
 			})
 
+			It("should decode the events from the receipt", func() {
+				addr, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
+				Expect(err).ToNot(HaveOccurred())
+				r, err := Backend.TransactionReceipt(context.Background(), tx.Hash())
+				Expect(err).ToNot(HaveOccurred())
+
+				deployed, err := WalletDeployer.ParseDeployedWalletFromReceipt(WalletDeployerAddress, r)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deployed).To(HaveLen(1))
+				Expect(deployed[0].Wallet).To(Equal(addr))
+				Expect(deployed[0].Owner).To(Equal(Owner.Address()))
+				Expect(deployed[0].Raw.TxHash).To(Equal(tx.Hash()))
+
+				cached, err := WalletCache.ParseCachedWalletFromReceipt(WalletCacheAddress, r)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cached).To(HaveLen(1))
+				Expect(cached[0].Wallet).To(Equal(addr))
+
+				// The events of other contracts are left out.
+				cached, err = WalletCache.ParseCachedWalletFromReceipt(WalletDeployerAddress, r)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cached).To(BeEmpty())
+			})
+
 			It("should return a new Wallet address", func() {
 				addr, err := WalletDeployer.DeployedWallets(nil, Owner.Address())
 				Expect(err).ToNot(HaveOccurred())
@@ -0,0 +1,108 @@
+package wallet_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	. "github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+var _ = Describe("WalletClient", func() {
+
+	var client *bindings.WalletClient
+	var ctx context.Context
+
+	BeforeEach(func() {
+		var err error
+		client, err = bindings.NewWalletClient(WalletAddress, Backend)
+		Expect(err).ToNot(HaveOccurred())
+		ctx = context.Background()
+		BankAccount.MustTransfer(Backend, WalletAddress, EthToWei(101))
+	})
+
+	It("should return the spend limit", func() {
+		l, err := client.SpendLimit(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Value.String()).To(Equal(EthToWei(100).String()))
+		Expect(l.Available.String()).To(Equal(EthToWei(100).String()))
+		Expect(l.Pending.String()).To(Equal("0"))
+		Expect(l.Updateable).To(BeFalse())
+	})
+
+	It("should return the gas top up limit", func() {
+		l, err := client.GasTopUpLimit(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Value.String()).To(Equal(FinneyToWei(500).String()))
+		Expect(l.Available.String()).To(Equal(FinneyToWei(500).String()))
+	})
+
+	It("should return the load limit", func() {
+		l, err := client.LoadLimit(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Value.Sign()).To(Equal(1))
+		Expect(l.Available.String()).To(Equal(l.Value.String()))
+	})
+
+	When("I transfer 1 ETH to a random person", func() {
+
+		BeforeEach(func() {
+			tx, err := client.Transfer(Owner.TransactOpts(ethertest.WithGasLimit(81000)), RandomAccount.Address(), bindings.ETH, EthToWei(1))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should reduce the available spend limit by 1 ETH", func() {
+			l, err := client.SpendLimit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l.Available.String()).To(Equal(EthToWei(99).String()))
+		})
+
+		It("should refuse to exceed the spend limit without sending the transaction", func() {
+			_, err := client.Transfer(Owner.TransactOpts(ethertest.WithGasLimit(81000)), RandomAccount.Address(), bindings.ETH, EthToWei(100))
+			Expect(err).To(MatchError(ContainSubstring(bindings.ErrSpendLimitExceeded.Error())))
+			Expect(client.CheckTransfer(ctx, RandomAccount.Address(), bindings.ETH, EthToWei(99))).To(Succeed())
+		})
+	})
+
+	When("the destination is whitelisted", func() {
+
+		BeforeEach(func() {
+			tx, err := client.SetWhitelist(Owner.TransactOpts(), []common.Address{RandomAccount.Address()})
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+		})
+
+		It("should be whitelisted", func() {
+			Expect(client.IsWhitelisted(ctx, RandomAccount.Address())).To(BeTrue())
+			Expect(client.IsWhitelisted(ctx, Owner.Address())).To(BeFalse())
+		})
+
+		It("should transfer over the spend limit", func() {
+			tx, err := client.Transfer(Owner.TransactOpts(ethertest.WithGasLimit(81000)), RandomAccount.Address(), bindings.ETH, EthToWei(101))
+			Expect(err).ToNot(HaveOccurred())
+			Backend.Commit()
+			Expect(isSuccessful(tx)).To(BeTrue())
+
+			l, err := client.SpendLimit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l.Available.String()).To(Equal(EthToWei(100).String()))
+		})
+	})
+
+	It("should refuse a transfer to the zero address", func() {
+		err := client.CheckTransfer(ctx, common.Address{}, bindings.ETH, EthToWei(1))
+		Expect(err).To(Equal(bindings.ErrZeroDestination))
+	})
+
+	It("should refuse a zero amount", func() {
+		err := client.CheckTransfer(ctx, RandomAccount.Address(), bindings.ETH, big.NewInt(0))
+		Expect(err).To(Equal(bindings.ErrZeroAmount))
+	})
+})
@@ -1,8 +1,13 @@
 package wallet_test
 
 import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings"
+	"github.com/tokencard/contracts/v2/pkg/bindings/externals/ens"
+	. "github.com/tokencard/contracts/v2/test/shared"
 )
 
 var _ = Describe("ERC165", func() {
@@ -24,4 +29,48 @@ var _ = Describe("ERC165", func() {
 		})
 
 	})
+
+	Context("When probing the capabilities of an address", func() {
+		var opts = &bind.CallOpts{}
+
+		probe := func(address common.Address, ids ...[4]byte) bindings.Capabilities {
+			c, err := bindings.NewWalletCaller(address, Backend)
+			Expect(err).ToNot(HaveOccurred())
+			capabilities, err := bindings.SupportsInterfaces(opts, c, ids...)
+			Expect(err).ToNot(HaveOccurred())
+			return capabilities
+		}
+
+		It("should report the interfaces of the wallet", func() {
+			capabilities := probe(WalletAddress, bindings.InterfaceERC165, bindings.InterfaceERC721)
+			Expect(capabilities).To(Equal(bindings.Capabilities{
+				bindings.InterfaceERC165: true,
+				bindings.InterfaceERC721: false,
+			}))
+			Expect(capabilities.Supports(bindings.InterfaceERC165)).To(BeTrue())
+		})
+
+		It("should report the interfaces of the ENS resolver", func() {
+			r, err := ens.NewPublicResolverCaller(ENSResolverAddress, Backend)
+			Expect(err).ToNot(HaveOccurred())
+			addr := [4]byte{0x3b, 0x3b, 0x57, 0xde}
+			capabilities, err := bindings.SupportsInterfaces(opts, r, addr, bindings.InterfaceERC721)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(capabilities.Supports(addr)).To(BeTrue())
+			Expect(capabilities.Supports(bindings.InterfaceERC721)).To(BeFalse())
+		})
+
+		It("should report no interface for a contract without ERC165", func() {
+			Expect(probe(TokenWhitelistAddress, bindings.InterfaceERC165, bindings.InterfaceERC721)).To(Equal(bindings.Capabilities{
+				bindings.InterfaceERC165: false,
+				bindings.InterfaceERC721: false,
+			}))
+		})
+
+		It("should report no interface for an account without code", func() {
+			Expect(probe(Owner.Address(), bindings.InterfaceERC165)).To(Equal(bindings.Capabilities{
+				bindings.InterfaceERC165: false,
+			}))
+		})
+	})
 })
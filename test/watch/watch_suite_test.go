@@ -0,0 +1,151 @@
+package watch_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/pkg/watch"
+	"github.com/tokencard/contracts/v2/test/shared"
+	"github.com/tokencard/ethertest"
+)
+
+func TestWatchSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Watch Suite")
+}
+
+// network dials connections to the test backend which can be dropped, and
+// refuses to dial while it is down. The logs of the blocks it orphaned are no
+// longer filtered, like once the node switched to another branch.
+type network struct {
+	mu       sync.Mutex
+	head     *big.Int
+	down     bool
+	dials    int
+	subs     []*droppable
+	orphaned map[common.Hash]bool
+}
+
+func (n *network) dial(ctx context.Context) (watch.Backend, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.down {
+		return nil, errors.New("connection refused")
+	}
+	n.dials++
+	return &conn{TestBackend: shared.Backend, network: n}, nil
+}
+
+// drop fails the subscriptions of all the connections.
+func (n *network) drop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, s := range n.subs {
+		s.Subscription.Unsubscribe()
+		s.err <- errors.New("connection reset by peer")
+	}
+	n.subs = nil
+}
+
+func (n *network) setDown(down bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down = down
+}
+
+func (n *network) dialed() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.dials
+}
+
+// commit mines the transaction and moves the head of the chain to its block.
+func (n *network) commit(tx *types.Transaction) {
+	shared.Backend.Commit()
+	r, err := shared.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(r.Status).To(Equal(types.ReceiptStatusSuccessful))
+	n.mu.Lock()
+	n.head = r.BlockNumber
+	n.mu.Unlock()
+}
+
+// reorg orphans the block of the transaction.
+func (n *network) reorg(tx *types.Transaction) {
+	r, err := shared.Backend.TransactionReceipt(context.Background(), tx.Hash())
+	Expect(err).ToNot(HaveOccurred())
+	n.mu.Lock()
+	n.orphaned[r.BlockHash] = true
+	n.mu.Unlock()
+}
+
+// conn is a connection of the network.
+type conn struct {
+	ethertest.TestBackend
+	network *network
+}
+
+func (c *conn) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+	return &types.Header{Number: c.network.head}, nil
+}
+
+func (c *conn) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := c.TestBackend.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+	var canonical []types.Log
+	for _, l := range logs {
+		if !c.network.orphaned[l.BlockHash] {
+			canonical = append(canonical, l)
+		}
+	}
+	return canonical, nil
+}
+
+func (c *conn) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	sub, err := c.TestBackend.SubscribeFilterLogs(ctx, q, ch)
+	if err != nil {
+		return nil, err
+	}
+	s := &droppable{Subscription: sub, err: make(chan error, 1)}
+	c.network.mu.Lock()
+	c.network.subs = append(c.network.subs, s)
+	c.network.mu.Unlock()
+	return s, nil
+}
+
+// droppable is a subscription failing when the network drops it.
+type droppable struct {
+	ethereum.Subscription
+	err chan error
+}
+
+func (d *droppable) Err() <-chan error {
+	return d.err
+}
+
+var Network *network
+
+var _ = BeforeEach(func() {
+	err := shared.InitializeBackend()
+	Expect(err).ToNot(HaveOccurred())
+	Network = &network{head: big.NewInt(0), orphaned: make(map[common.Hash]bool)}
+})
+
+var _ = AfterEach(func() {
+	err := shared.Backend.Close()
+	Expect(err).ToNot(HaveOccurred())
+})
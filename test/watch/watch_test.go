@@ -0,0 +1,151 @@
+package watch_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/bindings/mocks"
+	"github.com/tokencard/contracts/v2/pkg/watch"
+	. "github.com/tokencard/contracts/v2/test/shared"
+)
+
+var _ = Describe("Filterer", func() {
+
+	var filterer *watch.Filterer
+	var token *mocks.TokenFilterer
+
+	transfer := func(amount int64) *types.Transaction {
+		tx, err := ERC20Contract1.Transfer(BankAccount.TransactOpts(), RandomAccount.Address(), big.NewInt(amount))
+		Expect(err).ToNot(HaveOccurred())
+		Network.commit(tx)
+		return tx
+	}
+
+	watchTransfers := func() (chan *mocks.TokenTransfer, event.Subscription) {
+		sink := make(chan *mocks.TokenTransfer, 16)
+		sub, err := token.WatchTransfer(nil, sink, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		return sink, sub
+	}
+
+	amounts := func(sink chan *mocks.TokenTransfer) func() []int64 {
+		var received []int64
+		return func() []int64 {
+			for {
+				select {
+				case t := <-sink:
+					received = append(received, t.Amount.Int64())
+				default:
+					return received
+				}
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		tx, err := ERC20Contract1.Credit(BankAccount.TransactOpts(), BankAccount.Address(), big.NewInt(100))
+		Expect(err).ToNot(HaveOccurred())
+		Network.commit(tx)
+
+		filterer, err = watch.New(context.Background(), Network.dial)
+		Expect(err).ToNot(HaveOccurred())
+		filterer.Backoff = 10 * time.Millisecond
+		filterer.MaxBackoff = 50 * time.Millisecond
+		token, err = mocks.NewTokenFilterer(ERC20Contract1Address, filterer)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should deliver the logs of the subscription", func() {
+		sink, sub := watchTransfers()
+		defer sub.Unsubscribe()
+
+		transfer(1)
+		Eventually(amounts(sink)).Should(Equal([]int64{1}))
+	})
+
+	It("should deliver the logs emitted while disconnected once", func() {
+		sink, sub := watchTransfers()
+		defer sub.Unsubscribe()
+		received := amounts(sink)
+
+		transfer(1)
+		Eventually(received).Should(Equal([]int64{1}))
+
+		Network.setDown(true)
+		Network.drop()
+		transfer(2)
+		transfer(3)
+		Network.setDown(false)
+
+		Eventually(received).Should(Equal([]int64{1, 2, 3}))
+		Consistently(received, 200*time.Millisecond).Should(Equal([]int64{1, 2, 3}))
+		Expect(Network.dialed()).To(Equal(2))
+
+		transfer(4)
+		Eventually(received).Should(Equal([]int64{1, 2, 3, 4}))
+	})
+
+	It("should deliver the logs emitted before the first one was delivered", func() {
+		sink, sub := watchTransfers()
+		defer sub.Unsubscribe()
+
+		Network.setDown(true)
+		Network.drop()
+		transfer(1)
+		Network.setDown(false)
+
+		Eventually(amounts(sink)).Should(Equal([]int64{1}))
+	})
+
+	It("should deliver the logs lost while disconnected as removed", func() {
+		sink, sub := watchTransfers()
+		defer sub.Unsubscribe()
+
+		tx := transfer(1)
+		var t *mocks.TokenTransfer
+		Eventually(sink).Should(Receive(&t))
+		Expect(t.Raw.Removed).To(BeFalse())
+
+		Network.setDown(true)
+		Network.drop()
+		Network.reorg(tx)
+		Network.setDown(false)
+
+		Eventually(sink).Should(Receive(&t))
+		Expect(t.Raw.Removed).To(BeTrue())
+		Expect(t.Raw.TxHash).To(Equal(tx.Hash()))
+		Expect(t.Amount.Int64()).To(Equal(int64(1)))
+		Consistently(sink, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should share the new connection between the subscriptions", func() {
+		a, subA := watchTransfers()
+		defer subA.Unsubscribe()
+		b, subB := watchTransfers()
+		defer subB.Unsubscribe()
+
+		Network.drop()
+		transfer(1)
+
+		Eventually(amounts(a)).Should(Equal([]int64{1}))
+		Eventually(amounts(b)).Should(Equal([]int64{1}))
+		Expect(Network.dialed()).To(Equal(2))
+	})
+
+	It("should stop reconnecting once unsubscribed", func() {
+		_, sub := watchTransfers()
+
+		Network.setDown(true)
+		Network.drop()
+		sub.Unsubscribe()
+		Eventually(sub.Err()).Should(BeClosed())
+
+		Network.setDown(false)
+		Consistently(Network.dialed, 200*time.Millisecond).Should(Equal(1))
+	})
+})
@@ -0,0 +1,243 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tokencard/contracts/v2/pkg/indexer"
+	"github.com/tokencard/contracts/v2/pkg/webhook"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const secret = "secret"
+
+// receiver records the deliveries, failing the first failures requests.
+type receiver struct {
+	mu        sync.Mutex
+	failures  int
+	requests  int
+	payloads  []webhook.Payload
+	signature bool
+	// public and private decrypt the encrypted deliveries.
+	public    *webhook.PublicKey
+	private   *[32]byte
+	encrypted bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.requests <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	Expect(err).ToNot(HaveOccurred())
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhook.TimestampHeader), 10, 64)
+	Expect(err).ToNot(HaveOccurred())
+	r.signature = webhook.Verify(secret, timestamp, body, req.Header.Get(webhook.SignatureHeader))
+
+	r.encrypted = req.Header.Get(webhook.EncryptionHeader) == webhook.SealedBox
+	if r.encrypted {
+		body, err = webhook.Open(body, r.public, r.private)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	var p webhook.Payload
+	Expect(json.Unmarshal(body, &p)).To(Succeed())
+	r.payloads = append(r.payloads, p)
+}
+
+func (r *receiver) delivered() []webhook.Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhook.Payload(nil), r.payloads...)
+}
+
+var _ = Describe("Webhook", func() {
+
+	var rcv *receiver
+	var server *httptest.Server
+	var notifier *webhook.Notifier
+	var failures chan webhook.Failure
+	var cancel context.CancelFunc
+
+	ownership := indexer.Event{
+		Contract:    "controller",
+		Name:        "TransferredOwnership",
+		BlockNumber: 10,
+		TxHash:      common.HexToHash("0xa"),
+		LogIndex:    2,
+		Args:        map[string]interface{}{"_from": common.HexToAddress("0x1"), "_to": common.HexToAddress("0x2")},
+	}
+	admin := indexer.Event{
+		Contract:    "controller",
+		Name:        "AddedAdmin",
+		BlockNumber: 11,
+		Args:        map[string]interface{}{"_admin": common.HexToAddress("0x3")},
+	}
+
+	BeforeEach(func() {
+		rcv = &receiver{}
+		server = httptest.NewServer(rcv)
+		failures = make(chan webhook.Failure, 1)
+
+		notifier = webhook.NewNotifier(10, webhook.Endpoint{
+			URL:    server.URL,
+			Secret: secret,
+			Events: []string{"controller.TransferredOwnership"},
+		})
+		notifier.Attempts = 3
+		notifier.Backoff = time.Millisecond
+		notifier.DeadLetter = webhook.DeadLetterFunc(func(f webhook.Failure) error {
+			failures <- f
+			return nil
+		})
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go notifier.Run(ctx)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("delivers the subscribed events with a valid signature", func() {
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{admin, ownership})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(1))
+		p := rcv.delivered()[0]
+		Expect(p.ID).To(Equal(common.HexToHash("0xa").Hex() + "-2"))
+		Expect(p.Event.Name).To(Equal("TransferredOwnership"))
+		Expect(p.Event.Args["_to"]).To(Equal(common.HexToAddress("0x2").Hex()))
+		Expect(rcv.signature).To(BeTrue())
+		Consistently(rcv.delivered, 50*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("delivers the events removed by a reorganization under their own ID", func() {
+		removed := ownership
+		removed.Removed = true
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{ownership, removed})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(2))
+		Expect(rcv.delivered()[1].ID).To(Equal(common.HexToHash("0xa").Hex() + "-2-removed"))
+		Expect(rcv.delivered()[1].Event.Removed).To(BeTrue())
+	})
+
+	It("delivers to the replaced endpoints", func() {
+		notifier.SetEndpoints(webhook.Endpoint{URL: server.URL, Secret: secret, Events: []string{"AddedAdmin"}})
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{admin, ownership})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(1))
+		Expect(rcv.delivered()[0].Event.Name).To(Equal("AddedAdmin"))
+	})
+
+	It("delivers to each endpoint the events of its finality level", func() {
+		notifier.SetEndpoints(
+			webhook.Endpoint{URL: server.URL, Secret: secret, Events: []string{"AddedAdmin"}},
+			webhook.Endpoint{URL: server.URL, Secret: secret, Events: []string{"AddedAdmin"}, Confirmations: 12},
+		)
+		Expect(notifier.Confirmations()).To(Equal([]uint64{0, 12}))
+
+		confirmed := admin
+		confirmed.Confirmations = 12
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{admin, confirmed})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(2))
+		Expect(rcv.delivered()[0].Event.Confirmations).To(BeZero())
+		Expect(rcv.delivered()[1].Event.Confirmations).To(Equal(uint64(12)))
+		Consistently(rcv.delivered, 50*time.Millisecond).Should(HaveLen(2))
+	})
+
+	It("retries failed deliveries", func() {
+		rcv.failures = 2
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{ownership})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(1))
+		Expect(failures).ToNot(Receive())
+	})
+
+	It("dead letters deliveries failing all the attempts", func() {
+		rcv.failures = 3
+		err := notifier.HandleEvents(context.Background(), []indexer.Event{ownership})
+		Expect(err).ToNot(HaveOccurred())
+
+		var f webhook.Failure
+		Eventually(failures).Should(Receive(&f))
+		Expect(f.URL).To(Equal(server.URL))
+		Expect(f.Attempts).To(Equal(3))
+		Expect(f.Payload.Event.Name).To(Equal("TransferredOwnership"))
+		Expect(f.Error).To(ContainSubstring("503"))
+		Expect(rcv.delivered()).To(BeEmpty())
+	})
+
+	It("encrypts the deliveries to endpoints with a recipient key", func() {
+		public, private, err := box.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		rcv.public, rcv.private = (*webhook.PublicKey)(public), private
+		notifier.SetEndpoints(webhook.Endpoint{URL: server.URL, Secret: secret, RecipientKey: rcv.public})
+
+		err = notifier.HandleEvents(context.Background(), []indexer.Event{ownership})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rcv.delivered).Should(HaveLen(1))
+		Expect(rcv.delivered()[0].Event.Name).To(Equal("TransferredOwnership"))
+		Expect(rcv.encrypted).To(BeTrue())
+		Expect(rcv.signature).To(BeTrue())
+	})
+
+	It("only opens sealed payloads with the recipient key", func() {
+		public, private, err := box.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		sealed, err := webhook.Seal([]byte("payload"), (*webhook.PublicKey)(public))
+		Expect(err).ToNot(HaveOccurred())
+
+		opened, err := webhook.Open(sealed, (*webhook.PublicKey)(public), private)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opened).To(Equal([]byte("payload")))
+
+		otherPublic, otherPrivate, err := box.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = webhook.Open(sealed, (*webhook.PublicKey)(otherPublic), otherPrivate)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses base64 recipient keys", func() {
+		key, err := webhook.ParsePublicKey(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key.String()).To(Equal(base64.StdEncoding.EncodeToString(make([]byte, 32))))
+
+		_, err = webhook.ParsePublicKey(base64.StdEncoding.EncodeToString(make([]byte, 16)))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects tampered payloads", func() {
+		body := []byte(`{"id":"1"}`)
+		signature := webhook.Sign(secret, 1, body)
+		Expect(webhook.Verify(secret, 1, body, signature)).To(BeTrue())
+		Expect(webhook.Verify(secret, 2, body, signature)).To(BeFalse())
+		Expect(webhook.Verify("other", 1, body, signature)).To(BeFalse())
+		Expect(webhook.Verify(secret, 1, []byte(`{"id":"2"}`), signature)).To(BeFalse())
+	})
+})
@@ -0,0 +1,236 @@
+// Package generate generates the bindings of the contracts from their
+// compiled ABI and bytecode, with the abigen of the go-ethereum version
+// pinned in go.mod, so that the bindings are the same whoever regenerates
+// them.
+package generate
+
+import (
+	"bytes"
+	"go/format"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+)
+
+// SolcImage is the docker image of the solc compiling the contracts.
+const SolcImage = "ethereum/solc:0.5.15"
+
+// Sources are the contracts compiled, relative to the contracts directory
+// and without their .sol extension.
+var Sources = []string{
+	"wallet",
+	"oracle",
+	"licence",
+	"holder",
+	"controller",
+	"tokenWhitelist",
+	"walletDeployer",
+	"walletCache",
+	"mocks/token",
+	"mocks/burnerToken",
+	"mocks/nonCompliantToken",
+	"mocks/base64Exporter",
+	"mocks/oraclize",
+	"mocks/bytesUtilsExporter",
+	"mocks/isValidSignatureExporter",
+	"mocks/parseIntScientificExporter",
+	"mocks/tokenWhitelistableExporter",
+	"internals/tokenWhitelistable",
+	"internals/parseIntScientific",
+	"externals/ens/PublicResolver",
+	"externals/ens/ENSRegistry",
+}
+
+// Target is a binding to generate.
+type Target struct {
+	// Contract is the path of the compiled contract in the build directory,
+	// without the .abi and .bin extensions.
+	Contract string
+	// Out is the path of the binding, relative to pkg/bindings.
+	Out     string
+	Type    string
+	Package string
+}
+
+// Targets are the bindings of pkg/bindings.
+var Targets = []Target{
+	{"wallet/Wallet", "wallet.go", "Wallet", "bindings"},
+	{"oracle/Oracle", "oracle.go", "Oracle", "bindings"},
+	{"licence/Licence", "licence.go", "Licence", "bindings"},
+	{"holder/Holder", "holder.go", "Holder", "bindings"},
+	{"controller/Controller", "controller.go", "Controller", "bindings"},
+	{"tokenWhitelist/TokenWhitelist", "tokenWhitelist.go", "TokenWhitelist", "bindings"},
+	{"walletDeployer/WalletDeployer", "walletDeployer.go", "WalletDeployer", "bindings"},
+	{"walletCache/WalletCache", "walletCache.go", "WalletCache", "bindings"},
+	{"mocks/token/Token", "mocks/token.go", "Token", "mocks"},
+	{"mocks/burnerToken/BurnerToken", "mocks/burnerToken.go", "BurnerToken", "mocks"},
+	{"mocks/nonCompliantToken/NonCompliantToken", "mocks/nonCompliantToken.go", "NonCompliantToken", "mocks"},
+	{"mocks/base64Exporter/Base64Exporter", "mocks/base64Exporter.go", "Base64Exporter", "mocks"},
+	{"mocks/oraclize/OraclizeConnector", "mocks/oraclizeConnector.go", "OraclizeConnector", "mocks"},
+	{"mocks/oraclize/OraclizeAddrResolver", "mocks/oraclizeAddrResolver.go", "OraclizeAddrResolver", "mocks"},
+	{"mocks/bytesUtilsExporter/BytesUtilsExporter", "mocks/bytesUtilsExporter.go", "BytesUtilsExporter", "mocks"},
+	{"mocks/isValidSignatureExporter/IsValidSignatureExporter", "mocks/isValidSignatureExporter.go", "IsValidSignatureExporter", "mocks"},
+	{"mocks/parseIntScientificExporter/ParseIntScientificExporter", "mocks/parseIntScientificExporter.go", "ParseIntScientificExporter", "mocks"},
+	{"mocks/tokenWhitelistableExporter/TokenWhitelistableExporter", "mocks/tokenWhitelistableExporter.go", "TokenWhitelistableExporter", "mocks"},
+	{"internals/tokenWhitelistable/TokenWhitelistable", "internals/tokenWhitelistable.go", "TokenWhitelistable", "internals"},
+	{"internals/parseIntScientific/ParseIntScientific", "internals/parseIntScientific.go", "ParseIntScientific", "internals"},
+	{"externals/ens/ENSRegistry/ENSRegistry", "externals/ens/ENSRegistry.go", "ENSRegistry", "ens"},
+	{"externals/ens/PublicResolver/PublicResolver", "externals/ens/PublicResolver.go", "PublicResolver", "ens"},
+}
+
+// Generate returns the source of the binding of t, reading the compiled
+// contract from artifacts.
+func Generate(artifacts fs.FS, t Target) ([]byte, error) {
+	abiJSON, err := fs.ReadFile(artifacts, path.Clean(t.Contract+".abi"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the ABI of %s", t.Type)
+	}
+	bin, err := fs.ReadFile(artifacts, path.Clean(t.Contract+".bin"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the bytecode of %s", t.Type)
+	}
+	code, err := bind.Bind([]string{t.Type}, []string{string(abiJSON)}, []string{string(bin)}, nil, t.Package, bind.LangGo, nil, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "generating the binding of %s", t.Type)
+	}
+	accessors, err := eventAccessors(t, abiJSON)
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(append([]byte(code), accessors...))
+	if err != nil {
+		return nil, errors.Wrapf(err, "formatting the binding of %s", t.Type)
+	}
+	return src, nil
+}
+
+// accessorEvent is an event of the contract whose topic and receipt parser
+// are generated.
+type accessorEvent struct {
+	Name      string
+	Signature string
+	ID        string
+}
+
+// accessorTemplate declares the parsed ABI of a contract and the topics of
+// its events, and decodes its events from the logs of a transaction receipt
+// with the Parse methods generated by abigen.
+var accessorTemplate = template.Must(template.New("accessors").Parse(`
+{{$type := .Type}}
+// {{$type}}ParsedABI is the parsed {{$type}}ABI.
+var {{$type}}ParsedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader({{$type}}ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+{{if .Events}}
+// The topics of the events of {{$type}}, the hash of their signature.
+var (
+{{- range .Events}}
+	// {{$type}}{{.Name}}Topic is the topic of {{.Signature}}.
+	{{$type}}{{.Name}}Topic = common.HexToHash("{{.ID}}")
+{{- end}}
+)
+{{end}}{{range .Events}}
+// Parse{{.Name}}FromReceipt parses the {{.Name}} events emitted by the contract at address in the receipt, in the order of the logs.
+//
+// Solidity: {{.Signature}}
+func (_{{$type}} *{{$type}}Filterer) Parse{{.Name}}FromReceipt(address common.Address, receipt *types.Receipt) ([]*{{$type}}{{.Name}}, error) {
+	var events []*{{$type}}{{.Name}}
+	for _, log := range receipt.Logs {
+		if log.Address != address || len(log.Topics) == 0 || log.Topics[0] != {{$type}}{{.Name}}Topic {
+			continue
+		}
+		event, err := _{{$type}}.Parse{{.Name}}(*log)
+		if err != nil {
+			return nil, err
+		}
+		event.Raw = *log
+		events = append(events, event)
+	}
+	return events, nil
+}
+{{end}}`))
+
+// eventAccessors returns the source of the parsed ABI of the binding of t,
+// and of the topics and the ParseXFromReceipt methods of its events, named as
+// abigen names their Parse methods.
+func eventAccessors(t Target, abiJSON []byte) ([]byte, error) {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the ABI of %s", t.Type)
+	}
+	var events []accessorEvent
+	for _, e := range parsed.Events {
+		if e.Anonymous {
+			continue
+		}
+		events = append(events, accessorEvent{
+			Name:      abi.ToCamelCase(e.Name),
+			Signature: strings.TrimSpace(e.String()),
+			ID:        e.ID().Hex(),
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	var buf bytes.Buffer
+	err = accessorTemplate.Execute(&buf, struct {
+		Type   string
+		Events []accessorEvent
+	}{t.Type, events})
+	if err != nil {
+		return nil, errors.Wrapf(err, "generating the event accessors of %s", t.Type)
+	}
+	return buf.Bytes(), nil
+}
+
+// Write generates the bindings of the targets into dir.
+func Write(artifacts fs.FS, dir string, targets []Target) error {
+	for _, t := range targets {
+		code, err := Generate(artifacts, t)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(dir, filepath.FromSlash(t.Out))
+		err = os.MkdirAll(filepath.Dir(out), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(out, code, 0644)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", out)
+		}
+	}
+	return nil
+}
+
+// Verify returns the paths of the bindings in dir which differ from the ones
+// generated from artifacts, or are missing.
+func Verify(artifacts fs.FS, dir string, targets []Target) ([]string, error) {
+	var drifted []string
+	for _, t := range targets {
+		code, err := Generate(artifacts, t)
+		if err != nil {
+			return nil, err
+		}
+		out := filepath.Join(dir, filepath.FromSlash(t.Out))
+		committed, err := ioutil.ReadFile(out)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "reading %s", out)
+		}
+		if !bytes.Equal(code, committed) {
+			drifted = append(drifted, out)
+		}
+	}
+	return drifted, nil
+}
@@ -0,0 +1,88 @@
+// Command abigen regenerates the bindings of pkg/bindings reproducibly: the
+// contracts are compiled by the solc of generate.SolcImage and the bindings
+// generated with the abigen of the go-ethereum version in go.mod, from the
+// ABIs embedded by the build package.
+//
+// Usage:
+//
+//	abigen [-compile] [-verify] [-out dir]
+//
+// It runs from pkg/bindings with go generate, build.sh compiles the
+// contracts first. With -verify, nothing is written and it exits with status
+// 1 when the committed bindings differ from the ones generated from the
+// ABIs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/tokencard/contracts/v2/build"
+	"github.com/tokencard/contracts/v2/tools/abigen/generate"
+)
+
+func main() {
+	out := flag.String("out", "pkg/bindings", "directory of the bindings")
+	verify := flag.Bool("verify", false, "check the bindings instead of writing them")
+	compile := flag.Bool("compile", false, "compile the contracts with docker into the build directory, from the repository root")
+	flag.Parse()
+
+	if *compile {
+		err := compileContracts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "abigen: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if !*verify {
+		err := generate.Write(build.Artifacts, *out, generate.Targets)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "abigen: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	drifted, err := generate.Verify(build.Artifacts, *out, generate.Targets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "abigen: %v\n", err)
+		os.Exit(2)
+	}
+	if len(drifted) > 0 {
+		for _, path := range drifted {
+			fmt.Println(path)
+		}
+		fmt.Fprintf(os.Stderr, "abigen: %d bindings differ from the ABIs with go-ethereum %s, run go generate ./pkg/bindings\n", len(drifted), params.Version)
+		os.Exit(1)
+	}
+}
+
+// compileContracts compiles the sources as build.sh used to.
+func compileContracts() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	for _, src := range generate.Sources {
+		fmt.Printf("compiling %s\n", src)
+		cmd := exec.Command("docker", "run", "--rm", "-u", fmt.Sprint(os.Getuid()),
+			"-v", wd+":/solidity", "--workdir", "/solidity/contracts", generate.SolcImage,
+			"--optimize", "/=/", "--overwrite", "--bin", "--abi", src+".sol",
+			"-o", filepath.Join("/solidity/build", src),
+			"--combined-json", "bin-runtime,srcmap-runtime,ast,srcmap,bin")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+		if err != nil {
+			return errors.Wrapf(err, "compiling %s", src)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package dist builds the release archives of the commands for each target
+// platform.
+//
+// The commands are built without cgo, and so without hardware wallet
+// support, for the targets other than the host. The darwin targets require
+// cgo, for the metrics of go-ethereum, and are only built on a darwin host.
+package dist
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Module is the import path of the module the commands are built from.
+const Module = "github.com/tokencard/contracts/v2"
+
+// Commands are the commands of the release archives.
+var Commands = []string{"monolithctl", "monolithd"}
+
+// Targets are the platforms released by default.
+var Targets = []Target{
+	{OS: "linux", Arch: "amd64"},
+	{OS: "linux", Arch: "arm64"},
+	{OS: "darwin", Arch: "amd64"},
+	{OS: "darwin", Arch: "arm64"},
+	{OS: "windows", Arch: "amd64"},
+}
+
+// ErrHostRequired is returned when a target can only be built on a host of
+// its operating system.
+var ErrHostRequired = errors.New("target requires cgo and must be built on a host of its operating system")
+
+// Target is a platform the commands are built for.
+type Target struct {
+	OS   string
+	Arch string
+}
+
+// ParseTargets parses a comma separated list of os/arch targets.
+func ParseTargets(s string) ([]Target, error) {
+	var targets []Target
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		parts := strings.Split(t, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid target %q, expected os/arch", t)
+		}
+		targets = append(targets, Target{OS: parts[0], Arch: parts[1]})
+	}
+	return targets, nil
+}
+
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// Native reports whether t is the platform of the host.
+func (t Target) Native() bool {
+	return t.OS == runtime.GOOS && t.Arch == runtime.GOARCH
+}
+
+// Cgo reports whether the commands are built with cgo for t: on the host
+// platform, for hardware wallet support, and always for darwin.
+func (t Target) Cgo() bool {
+	return t.Native() || t.OS == "darwin"
+}
+
+// Buildable reports whether t can be built on the host.
+func (t Target) Buildable() bool {
+	return !t.Cgo() || t.OS == runtime.GOOS
+}
+
+// Executable returns the file name of the command on t.
+func (t Target) Executable(command string) string {
+	if t.OS == "windows" {
+		return command + ".exe"
+	}
+	return command
+}
+
+// Archive returns the file name of the release archive of version for t.
+func (t Target) Archive(version string) string {
+	ext := ".tar.gz"
+	if t.OS == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("monolith_%s_%s_%s%s", version, t.OS, t.Arch, ext)
+}
+
+// Build builds the commands for t into dir and returns the paths of the
+// executables.
+func Build(dir string, t Target, commands ...string) ([]string, error) {
+	if !t.Buildable() {
+		return nil, errors.Wrap(ErrHostRequired, t.String())
+	}
+	cgo := "0"
+	if t.Cgo() {
+		cgo = "1"
+	}
+	env := append(os.Environ(), "GOOS="+t.OS, "GOARCH="+t.Arch, "CGO_ENABLED="+cgo)
+
+	var paths []string
+	for _, c := range commands {
+		out := filepath.Join(dir, t.Executable(c))
+		cmd := exec.Command("go", "build", "-trimpath", "-ldflags", "-s -w", "-o", out, Module+"/cmd/"+c)
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, errors.Wrapf(err, "building %s for %s: %s", c, t, output)
+		}
+		paths = append(paths, out)
+	}
+	return paths, nil
+}
+
+// Package writes the release archive of version for t into dir, holding the
+// files under a directory of the name of the archive, and returns its path.
+// The windows archives are zip files, the others gzipped tar files.
+func Package(dir, version string, t Target, files ...string) (string, error) {
+	name := t.Archive(version)
+	path := filepath.Join(dir, name)
+	root := strings.TrimSuffix(strings.TrimSuffix(name, ".zip"), ".tar.gz")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrap(err, "creating archive")
+	}
+	if t.OS == "windows" {
+		err = writeZip(f, root, files)
+	} else {
+		err = writeTarGz(f, root, files)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", errors.Wrapf(err, "writing %s", name)
+	}
+	return path, nil
+}
+
+func writeZip(w io.Writer, root string, files []string) error {
+	z := zip.NewWriter(w)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		h, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		h.Name = root + "/" + filepath.Base(file)
+		h.Method = zip.Deflate
+		fw, err := z.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		err = copyFile(fw, file)
+		if err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func writeTarGz(w io.Writer, root string, files []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		h.Name = root + "/" + filepath.Base(file)
+		h.Uid, h.Gid, h.Uname, h.Gname = 0, 0, "", ""
+		err = tw.WriteHeader(h)
+		if err != nil {
+			return err
+		}
+		err = copyFile(tw, file)
+		if err != nil {
+			return err
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// WriteChecksums writes the SHA-256 sums of the archives to the SHA256SUMS
+// file of dir, in the format of sha256sum.
+func WriteChecksums(dir string, archives ...string) error {
+	names := make([]string, len(archives))
+	sums := make(map[string]string, len(archives))
+	for i, a := range archives {
+		data, err := ioutil.ReadFile(a)
+		if err != nil {
+			return errors.Wrap(err, "reading archive")
+		}
+		names[i] = filepath.Base(a)
+		sums[names[i]] = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[n], n)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(b.String()), 0644)
+}
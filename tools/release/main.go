@@ -0,0 +1,90 @@
+// Command release builds the release archives of monolithctl and monolithd
+// for linux, darwin and windows, with a SHA256SUMS file of the archives.
+//
+// Usage:
+//
+//	release -version v1.2.0 [-out dist] [-targets linux/amd64,windows/amd64]
+//
+// It runs from the repository root, whose LICENSE is added to the archives.
+// The darwin targets require cgo and are skipped on other hosts: their
+// archives are built by running the command on macOS.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tokencard/contracts/v2/tools/release/dist"
+)
+
+func main() {
+	version := flag.String("version", "", "version of the release, in the names of the archives")
+	out := flag.String("out", "dist", "directory of the archives")
+	targets := flag.String("targets", "", "comma separated os/arch targets, all the released platforms when empty")
+	flag.Parse()
+
+	if *version == "" {
+		fmt.Fprintln(os.Stderr, "release: -version is required")
+		os.Exit(2)
+	}
+	err := release(*version, *out, *targets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "release: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+func release(version, out, targetList string) error {
+	targets := dist.Targets
+	if targetList != "" {
+		var err error
+		targets, err = dist.ParseTargets(targetList)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.MkdirAll(out, 0755)
+	if err != nil {
+		return err
+	}
+	work, err := ioutil.TempDir("", "release")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	var archives, skipped []string
+	for _, t := range targets {
+		if !t.Buildable() {
+			skipped = append(skipped, t.String())
+			continue
+		}
+		fmt.Printf("building %s\n", t)
+		dir := filepath.Join(work, t.OS+"_"+t.Arch)
+		files, err := dist.Build(dir, t, dist.Commands...)
+		if err != nil {
+			return err
+		}
+		archive, err := dist.Package(out, version, t, append(files, "LICENSE")...)
+		if err != nil {
+			return err
+		}
+		archives = append(archives, archive)
+	}
+
+	if len(archives) > 0 {
+		err = dist.WriteChecksums(out, archives...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "release: skipped %s, build them on a host of their operating system\n", strings.Join(skipped, ", "))
+	}
+	return nil
+}